@@ -0,0 +1,136 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package app
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/ghodss/yaml"
+
+	"github.com/sigstore/rekor/pkg/admission"
+	"github.com/sigstore/rekor/pkg/pki"
+	"github.com/sigstore/rekor/pkg/pki/x509"
+)
+
+// signerPolicy lists the signers trusted to vouch for the images run in the cluster. An image is
+// admitted if the log holds a verified entry for its digest made by any of them.
+type signerPolicy struct {
+	Signers []trustedSigner `json:"signers"`
+	// ExemptNamespaces lists namespaces whose pods are admitted without any checks, e.g. kube-system
+	ExemptNamespaces []string `json:"exemptNamespaces,omitempty"`
+}
+
+// trustedSigner identifies a signer by its key or, for keys bound to an identity by a certificate, by
+// that identity; all of the fields that are set must match. Identities are only as trustworthy as the
+// certificate chains accepted by the log.
+type trustedSigner struct {
+	// PublicKey is a PEM encoded public key or certificate
+	PublicKey string `json:"publicKey,omitempty"`
+	// Fingerprint is the SHA256 hash of the canonical encoding of the key, as used by admission.Fingerprint
+	Fingerprint string `json:"fingerprint,omitempty"`
+	// Email is an email address the signing certificate was issued to
+	Email string `json:"email,omitempty"`
+	// Subject is a subject the signing certificate was issued to, e.g. a URI of a workload identity
+	Subject string `json:"subject,omitempty"`
+}
+
+// loadPolicy reads a signer policy from a YAML or JSON file
+func loadPolicy(path string) (*signerPolicy, error) {
+	b, err := ioutil.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, err
+	}
+	p := &signerPolicy{}
+	if err := yaml.Unmarshal(b, p); err != nil {
+		return nil, fmt.Errorf("parsing policy %v: %w", path, err)
+	}
+	if err := p.init(); err != nil {
+		return nil, fmt.Errorf("invalid policy %v: %w", path, err)
+	}
+	return p, nil
+}
+
+// init validates the policy and resolves the public keys of signers to their fingerprints
+func (p *signerPolicy) init() error {
+	if len(p.Signers) == 0 {
+		return errors.New("no signers are trusted")
+	}
+	for i, s := range p.Signers {
+		if s.PublicKey == "" && s.Fingerprint == "" && s.Email == "" && s.Subject == "" {
+			return fmt.Errorf("signer %d has neither a public key, a fingerprint, an email nor a subject", i)
+		}
+		if s.PublicKey == "" {
+			continue
+		}
+		key, err := x509.NewPublicKey(strings.NewReader(s.PublicKey))
+		if err != nil {
+			return fmt.Errorf("parsing public key of signer %d: %w", i, err)
+		}
+		fp, err := admission.Fingerprint(key)
+		if err != nil {
+			return err
+		}
+		if s.Fingerprint != "" && !strings.EqualFold(s.Fingerprint, fp) {
+			return fmt.Errorf("fingerprint of signer %d does not match its public key", i)
+		}
+		p.Signers[i].Fingerprint = fp
+	}
+	return nil
+}
+
+// exempt returns whether pods in namespace are admitted without checks
+func (p *signerPolicy) exempt(namespace string) bool {
+	for _, ns := range p.ExemptNamespaces {
+		if ns == namespace {
+			return true
+		}
+	}
+	return false
+}
+
+// trusts returns whether key belongs to one of the trusted signers
+func (p *signerPolicy) trusts(key pki.PublicKey) (bool, error) {
+	fp, err := admission.Fingerprint(key)
+	if err != nil {
+		return false, err
+	}
+	for _, s := range p.Signers {
+		if s.Fingerprint != "" && !strings.EqualFold(s.Fingerprint, fp) {
+			continue
+		}
+		if s.Email != "" && !containsFold(key.EmailAddresses(), s.Email) {
+			continue
+		}
+		if s.Subject != "" && !containsFold(key.Subjects(), s.Subject) {
+			continue
+		}
+		return true, nil
+	}
+	return false, nil
+}
+
+func containsFold(list []string, s string) bool {
+	for _, l := range list {
+		if strings.EqualFold(l, s) {
+			return true
+		}
+	}
+	return false
+}
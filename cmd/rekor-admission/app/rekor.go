@@ -0,0 +1,102 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/sigstore/sigstore/pkg/signature"
+
+	"github.com/sigstore/rekor/pkg/client"
+	genclient "github.com/sigstore/rekor/pkg/generated/client"
+	"github.com/sigstore/rekor/pkg/generated/client/entries"
+	"github.com/sigstore/rekor/pkg/generated/client/index"
+	"github.com/sigstore/rekor/pkg/generated/models"
+	"github.com/sigstore/rekor/pkg/log"
+	"github.com/sigstore/rekor/pkg/pki"
+	"github.com/sigstore/rekor/pkg/types"
+	"github.com/sigstore/rekor/pkg/verify"
+)
+
+// logLookup finds the entries of an artifact in the log. The search index of the log is not trusted:
+// every entry it returns is verified against the log's public key and checked to be about the artifact.
+type logLookup struct {
+	client   *genclient.Rekor
+	verifier signature.Verifier
+	// maxEntries bounds the number of entries fetched for an artifact
+	maxEntries int
+}
+
+// signers returns the signers of the entries in the log for digest; entries which fail verification
+// are logged and skipped
+func (l *logLookup) signers(ctx context.Context, digest string) ([]pki.PublicKey, error) {
+	searchParams := index.NewSearchIndexParamsWithContext(ctx)
+	searchParams.Query = &models.SearchIndex{Hash: digest}
+	// one more entry than is checked is fetched to tell whether any were left out
+	uuids, err := client.SearchIndex(l.client, searchParams, l.maxEntries+1)
+	if err != nil {
+		return nil, err
+	}
+	if len(uuids) > l.maxEntries {
+		log.Logger.Warnf("more than %d entries found for %v, only checking %d of them", l.maxEntries, digest, l.maxEntries)
+		uuids = uuids[:l.maxEntries]
+	}
+
+	var signers []pki.PublicKey
+	for _, uuid := range uuids {
+		params := entries.NewGetLogEntryByUUIDParamsWithContext(ctx)
+		params.EntryUUID = uuid
+		entry, err := l.client.Entries.GetLogEntryByUUID(params)
+		if err != nil {
+			return nil, fmt.Errorf("getting entry %v: %w", uuid, err)
+		}
+		for uuid, e := range entry.Payload {
+			s, err := entrySigners(l.verifier, digest, uuid, e)
+			if err != nil {
+				log.Logger.Warnf("skipping entry %v for %v: %v", uuid, digest, err)
+				continue
+			}
+			signers = append(signers, s...)
+		}
+	}
+	return signers, nil
+}
+
+// entrySigners verifies an entry returned by the log and returns its signers if it is about digest
+func entrySigners(verifier signature.Verifier, digest, uuid string, e models.LogEntryAnon) ([]pki.PublicKey, error) {
+	body, err := verify.Entry(verifier, uuid, e)
+	if err != nil {
+		return nil, err
+	}
+	entry, err := verify.ParseBody(body)
+	if err != nil {
+		return nil, err
+	}
+	keys, err := entry.IndexKeys()
+	if err != nil {
+		return nil, err
+	}
+	if !containsFold(keys, digest) {
+		return nil, fmt.Errorf("entry is not about %v", digest)
+	}
+	sp, ok := entry.(types.SignerProvider)
+	if !ok {
+		return nil, errors.New("the type of the entry does not identify its signers")
+	}
+	return sp.Signers()
+}
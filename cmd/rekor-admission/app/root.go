@@ -0,0 +1,221 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package app
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	homedir "github.com/mitchellh/go-homedir"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/sigstore/rekor/pkg/client"
+	genclient "github.com/sigstore/rekor/pkg/generated/client"
+	"github.com/sigstore/rekor/pkg/log"
+	"github.com/sigstore/rekor/pkg/verify"
+	"github.com/sigstore/sigstore/pkg/signature"
+
+	// these imports register the supported entry types so that the signers of entries can be found
+	_ "github.com/sigstore/rekor/pkg/types/alpine/v0.0.1"
+	_ "github.com/sigstore/rekor/pkg/types/checksums/v0.0.1"
+	_ "github.com/sigstore/rekor/pkg/types/hashedrekord/v0.0.1"
+	_ "github.com/sigstore/rekor/pkg/types/helm/v0.0.1"
+	_ "github.com/sigstore/rekor/pkg/types/intoto/v0.0.1"
+	_ "github.com/sigstore/rekor/pkg/types/jar/v0.0.1"
+	_ "github.com/sigstore/rekor/pkg/types/oci/v0.0.1"
+	_ "github.com/sigstore/rekor/pkg/types/rekord/v0.0.1"
+	_ "github.com/sigstore/rekor/pkg/types/rekord/v0.0.2"
+	_ "github.com/sigstore/rekor/pkg/types/rfc3161/v0.0.1"
+	_ "github.com/sigstore/rekor/pkg/types/rpm/v0.0.1"
+	_ "github.com/sigstore/rekor/pkg/types/sbom/v0.0.1"
+	_ "github.com/sigstore/rekor/pkg/types/tuf/v0.0.1"
+)
+
+var cfgFile string
+
+// rootCmd represents the base command when called without any subcommands
+var rootCmd = &cobra.Command{
+	Use:   "rekor-admission",
+	Short: "Kubernetes admission webhook backed by a Rekor transparency log",
+	Long: `Serves a validating admission webhook which only admits pods, and workloads creating pods,
+	whose images are pinned to digests for which the Rekor log holds an entry made by a signer trusted
+	by the policy. Entries are verified against the log's public key; the log's search index is only
+	used to find them.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// Setup the logger to dev/prod
+		log.ConfigureLogger(viper.GetString("log_type"))
+
+		// workaround for https://github.com/sigstore/rekor/issues/68
+		// from https://github.com/golang/glog/commit/fca8c8854093a154ff1eb580aae10276ad6b1b5f
+		_ = flag.CommandLine.Parse([]string{})
+
+		wh, err := newWebhookFromViper()
+		if err != nil {
+			return err
+		}
+
+		mux := http.NewServeMux()
+		mux.Handle("/validate", wh)
+		mux.HandleFunc("/healthz", healthz)
+		srv := &http.Server{
+			Addr:         viper.GetString("address"),
+			Handler:      mux,
+			ReadTimeout:  30 * time.Second,
+			WriteTimeout: wh.timeout + 10*time.Second,
+		}
+
+		ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer cancel()
+		go func() {
+			<-ctx.Done()
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			_ = srv.Shutdown(shutdownCtx)
+		}()
+
+		certFile, keyFile := viper.GetString("tls_cert_file"), viper.GetString("tls_key_file")
+		log.Logger.Infof("serving admission webhook on %v", srv.Addr)
+		if certFile == "" {
+			log.Logger.Warn("no TLS certificate specified, serving plain HTTP; the API server only calls webhooks over HTTPS")
+			err = srv.ListenAndServe()
+		} else {
+			err = srv.ListenAndServeTLS(certFile, keyFile)
+		}
+		if err != nil && err != http.ErrServerClosed {
+			return err
+		}
+		return nil
+	},
+}
+
+// Execute adds all child commands to the root command and sets flags appropriately.
+// This is called by main.main(). It only needs to happen once to the rootCmd.
+func Execute() {
+	if err := rootCmd.Execute(); err != nil {
+		log.Logger.Error(err)
+		os.Exit(1)
+	}
+}
+
+func init() {
+	cobra.OnInitialize(initConfig)
+
+	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.rekor-admission.yaml)")
+	rootCmd.PersistentFlags().String("log_type", "dev", "logger type to use (dev/prod)")
+
+	rootCmd.Flags().String("rekor_server", "https://rekor.sigstore.dev", "URL of the Rekor server to look entries up in")
+	rootCmd.Flags().String("public_key", "", "path to the PEM encoded public key of the log; fetched from the server if unset")
+	rootCmd.Flags().String("policy", "", "path to the YAML file listing the trusted signers")
+	rootCmd.Flags().String("address", ":8443", "address on which the webhook is served")
+	rootCmd.Flags().String("tls_cert_file", "", "path to the PEM encoded TLS certificate of the webhook")
+	rootCmd.Flags().String("tls_key_file", "", "path to the PEM encoded private key of the TLS certificate")
+	rootCmd.Flags().Duration("timeout", 10*time.Second, "time allowed for looking up the images of a review in the log")
+	rootCmd.Flags().Int("max_entries", 20, "maximum number of entries checked for each image digest")
+
+	if err := viper.BindPFlags(rootCmd.PersistentFlags()); err != nil {
+		log.Logger.Fatal(err)
+	}
+	if err := viper.BindPFlags(rootCmd.Flags()); err != nil {
+		log.Logger.Fatal(err)
+	}
+}
+
+// initConfig reads in config file and ENV variables if set.
+func initConfig() {
+	if cfgFile != "" {
+		// Use config file from the flag.
+		viper.SetConfigFile(cfgFile)
+	} else {
+		// Find home directory.
+		home, err := homedir.Dir()
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		viper.AddConfigPath(home)
+		viper.AddConfigPath(".")
+		viper.SetConfigName("rekor-admission")
+		viper.SetConfigType("yaml")
+	}
+
+	viper.AutomaticEnv() // read in environment variables that match
+
+	// If a config file is found, read it in.
+	if err := viper.ReadInConfig(); err == nil {
+		log.Logger.Infof("Using config file: %s", viper.ConfigFileUsed())
+	}
+}
+
+func newWebhookFromViper() (*webhook, error) {
+	policyPath := viper.GetString("policy")
+	if policyPath == "" {
+		return nil, errors.New("--policy must be set")
+	}
+	policy, err := loadPolicy(policyPath)
+	if err != nil {
+		return nil, err
+	}
+	if certFile, keyFile := viper.GetString("tls_cert_file"), viper.GetString("tls_key_file"); (certFile == "") != (keyFile == "") {
+		return nil, errors.New("--tls_cert_file and --tls_key_file must be set together")
+	}
+
+	c, err := client.GetRekorClient(viper.GetString("rekor_server"), client.WithUserAgent("rekor-admission"))
+	if err != nil {
+		return nil, err
+	}
+	verifier, err := loadLogVerifier(c, viper.GetString("public_key"))
+	if err != nil {
+		return nil, err
+	}
+
+	lookup := &logLookup{client: c, verifier: verifier, maxEntries: viper.GetInt("max_entries")}
+	return &webhook{
+		policy:  policy,
+		signers: lookup.signers,
+		timeout: viper.GetDuration("timeout"),
+	}, nil
+}
+
+// loadLogVerifier loads the log's public key from keyPath, falling back to fetching it from the server
+func loadLogVerifier(c *genclient.Rekor, keyPath string) (signature.Verifier, error) {
+	var keyPEM []byte
+	if keyPath != "" {
+		b, err := ioutil.ReadFile(filepath.Clean(keyPath))
+		if err != nil {
+			return nil, err
+		}
+		keyPEM = b
+	} else {
+		log.Logger.Warn("no public key specified, trusting the key served by the log")
+		keyResp, err := c.Pubkey.GetPublicKey(nil)
+		if err != nil {
+			return nil, err
+		}
+		keyPEM = []byte(keyResp.Payload)
+	}
+	return verify.LoadLogVerifier(keyPEM)
+}
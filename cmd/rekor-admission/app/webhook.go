@@ -0,0 +1,213 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/sigstore/rekor/pkg/log"
+	"github.com/sigstore/rekor/pkg/pki"
+)
+
+// the subset of the admission.k8s.io AdmissionReview API used by the webhook; the same fields are
+// used by v1 and v1beta1
+type admissionReview struct {
+	APIVersion string             `json:"apiVersion"`
+	Kind       string             `json:"kind"`
+	Request    *admissionRequest  `json:"request,omitempty"`
+	Response   *admissionResponse `json:"response,omitempty"`
+}
+
+type admissionRequest struct {
+	UID       string          `json:"uid"`
+	Namespace string          `json:"namespace,omitempty"`
+	Operation string          `json:"operation,omitempty"`
+	Object    json.RawMessage `json:"object,omitempty"`
+}
+
+type admissionResponse struct {
+	UID     string           `json:"uid"`
+	Allowed bool             `json:"allowed"`
+	Result  *admissionStatus `json:"status,omitempty"`
+}
+
+type admissionStatus struct {
+	Code    int    `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// podSpec holds the containers of a pod spec
+type podSpec struct {
+	Containers          []container `json:"containers"`
+	InitContainers      []container `json:"initContainers"`
+	EphemeralContainers []container `json:"ephemeralContainers"`
+}
+
+type container struct {
+	Image string `json:"image"`
+}
+
+type podTemplate struct {
+	Spec podSpec `json:"spec"`
+}
+
+// workload holds the pod spec of a pod, of the pod template of a Deployment, ReplicaSet, StatefulSet,
+// DaemonSet or Job, or of the job template of a CronJob
+type workload struct {
+	Spec struct {
+		podSpec
+		Template    *podTemplate `json:"template"`
+		JobTemplate *struct {
+			Spec struct {
+				Template *podTemplate `json:"template"`
+			} `json:"spec"`
+		} `json:"jobTemplate"`
+	} `json:"spec"`
+}
+
+// images returns the images of all containers of the workload
+func (w *workload) images() []string {
+	specs := []podSpec{w.Spec.podSpec}
+	if w.Spec.Template != nil {
+		specs = append(specs, w.Spec.Template.Spec)
+	}
+	if w.Spec.JobTemplate != nil && w.Spec.JobTemplate.Spec.Template != nil {
+		specs = append(specs, w.Spec.JobTemplate.Spec.Template.Spec)
+	}
+	var images []string
+	for _, s := range specs {
+		for _, containers := range [][]container{s.InitContainers, s.Containers, s.EphemeralContainers} {
+			for _, c := range containers {
+				images = append(images, c.Image)
+			}
+		}
+	}
+	return images
+}
+
+var imageDigestRE = regexp.MustCompile(`@(sha256:[0-9a-f]{64})$`)
+
+// imageDigest returns the digest an image reference is pinned to, as in registry/repo@sha256:<hex>
+func imageDigest(image string) (string, bool) {
+	m := imageDigestRE.FindStringSubmatch(image)
+	if m == nil {
+		return "", false
+	}
+	return m[1], true
+}
+
+// webhook validates that every image run in the cluster is pinned to a digest for which the log holds
+// an entry made by a trusted signer
+type webhook struct {
+	policy *signerPolicy
+	// signers returns the signers of the verified entries in the log for an artifact digest
+	signers func(ctx context.Context, digest string) ([]pki.PublicKey, error)
+	timeout time.Duration
+}
+
+func (wh *webhook) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "admission reviews must be POSTed", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, 10<<20))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	review := admissionReview{}
+	if err := json.Unmarshal(body, &review); err != nil || review.Request == nil {
+		http.Error(w, "request body is not an AdmissionReview", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), wh.timeout)
+	defer cancel()
+	resp := &admissionResponse{UID: review.Request.UID, Allowed: true}
+	if err := wh.review(ctx, review.Request); err != nil {
+		log.Logger.Infof("denying %v of %v in namespace %v: %v", review.Request.Operation, review.Request.UID, review.Request.Namespace, err)
+		resp.Allowed = false
+		resp.Result = &admissionStatus{Code: http.StatusForbidden, Message: err.Error()}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(admissionReview{
+		APIVersion: review.APIVersion,
+		Kind:       review.Kind,
+		Response:   resp,
+	})
+}
+
+// review returns an error describing why the request is denied, if it is
+func (wh *webhook) review(ctx context.Context, req *admissionRequest) error {
+	if wh.policy.exempt(req.Namespace) || len(req.Object) == 0 {
+		return nil
+	}
+	w := workload{}
+	if err := json.Unmarshal(req.Object, &w); err != nil {
+		return fmt.Errorf("parsing object: %w", err)
+	}
+
+	checked := map[string]bool{}
+	for _, image := range w.images() {
+		digest, ok := imageDigest(image)
+		if !ok {
+			return fmt.Errorf("image %v is not pinned to a sha256 digest", image)
+		}
+		if checked[digest] {
+			continue
+		}
+		if err := wh.checkDigest(ctx, digest); err != nil {
+			return fmt.Errorf("image %v: %w", image, err)
+		}
+		checked[digest] = true
+	}
+	return nil
+}
+
+// checkDigest returns an error unless a trusted signer made an entry for digest
+func (wh *webhook) checkDigest(ctx context.Context, digest string) error {
+	signers, err := wh.signers(ctx, digest)
+	if err != nil {
+		return fmt.Errorf("looking up %v in the log: %w", digest, err)
+	}
+	for _, s := range signers {
+		trusted, err := wh.policy.trusts(s)
+		if err != nil {
+			return err
+		}
+		if trusted {
+			return nil
+		}
+	}
+	if len(signers) == 0 {
+		return fmt.Errorf("no entry for %v was found in the log", digest)
+	}
+	return errors.New("no entry in the log was made by a trusted signer")
+}
+
+// healthz reports the webhook as ready to serve
+func healthz(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	_, _ = w.Write([]byte("ok\n"))
+}
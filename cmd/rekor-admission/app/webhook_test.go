@@ -0,0 +1,333 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package app
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cyberphone/json-canonicalization/go/src/webpki.org/jsoncanonicalizer"
+	"github.com/go-openapi/swag"
+	"github.com/sigstore/sigstore/pkg/cryptoutils"
+	"github.com/sigstore/sigstore/pkg/signature"
+
+	"github.com/sigstore/rekor/pkg/generated/models"
+	"github.com/sigstore/rekor/pkg/pki"
+	pkix509 "github.com/sigstore/rekor/pkg/pki/x509"
+	"github.com/sigstore/rekor/pkg/util"
+	"github.com/sigstore/rekor/pkg/verify"
+)
+
+func digestOf(s string) string {
+	h := sha256.Sum256([]byte(s))
+	return "sha256:" + hex.EncodeToString(h[:])
+}
+
+func TestWorkloadImages(t *testing.T) {
+	tests := []struct {
+		desc   string
+		object string
+		want   []string
+	}{
+		{
+			desc:   "pod",
+			object: `{"kind":"Pod","spec":{"initContainers":[{"image":"init"}],"containers":[{"image":"a"},{"image":"b"}]}}`,
+			want:   []string{"init", "a", "b"},
+		},
+		{
+			desc:   "deployment",
+			object: `{"kind":"Deployment","spec":{"replicas":3,"template":{"spec":{"containers":[{"image":"a"}]}}}}`,
+			want:   []string{"a"},
+		},
+		{
+			desc:   "cronjob",
+			object: `{"kind":"CronJob","spec":{"jobTemplate":{"spec":{"template":{"spec":{"containers":[{"image":"a"}],"ephemeralContainers":[{"image":"debug"}]}}}}}}`,
+			want:   []string{"a", "debug"},
+		},
+		{
+			desc:   "other object",
+			object: `{"kind":"ConfigMap","data":{"a":"b"}}`,
+		},
+	}
+	for _, tt := range tests {
+		w := workload{}
+		if err := json.Unmarshal([]byte(tt.object), &w); err != nil {
+			t.Fatalf("%v: %v", tt.desc, err)
+		}
+		if got := w.images(); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%v: got images %v, want %v", tt.desc, got, tt.want)
+		}
+	}
+}
+
+func TestImageDigest(t *testing.T) {
+	d := digestOf("image")
+	for image, want := range map[string]string{
+		"registry.example.com/app@" + d:           d,
+		"registry.example.com:5000/app:v1@" + d:   d,
+		"registry.example.com/app:v1":             "",
+		"registry.example.com/app@sha256:abc":     "",
+		"registry.example.com/app@" + d + "extra": "",
+	} {
+		got, ok := imageDigest(image)
+		if got != want || ok != (want != "") {
+			t.Errorf("imageDigest(%q) = %q, %v, want %q", image, got, ok, want)
+		}
+	}
+}
+
+// certificateKey returns a public key in a certificate issued to email
+func certificateKey(t *testing.T, email string) pki.PublicKey {
+	t.Helper()
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	tmpl := &x509.Certificate{
+		SerialNumber:   big.NewInt(1),
+		Subject:        pkix.Name{CommonName: "signer"},
+		EmailAddresses: []string{email},
+		NotBefore:      time.Now().Add(-time.Hour),
+		NotAfter:       time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, key.Public(), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub, err := pkix509.NewPublicKey(bytes.NewReader(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pub
+}
+
+func publicKey(t *testing.T, key *ecdsa.PrivateKey) (string, pki.PublicKey) {
+	t.Helper()
+	pemBytes, err := cryptoutils.MarshalPublicKeyToPEM(key.Public())
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub, err := pkix509.NewPublicKey(bytes.NewReader(pemBytes))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(pemBytes), pub
+}
+
+func TestPolicyTrusts(t *testing.T) {
+	trustedKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	trustedPEM, trusted := publicKey(t, trustedKey)
+	otherKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	_, other := publicKey(t, otherKey)
+	releaser := certificateKey(t, "release@example.com")
+	developer := certificateKey(t, "dev@example.com")
+
+	p := &signerPolicy{Signers: []trustedSigner{{PublicKey: trustedPEM}, {Email: "Release@example.com"}}}
+	if err := p.init(); err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct {
+		desc string
+		key  pki.PublicKey
+		want bool
+	}{
+		{"trusted key", trusted, true},
+		{"other key", other, false},
+		{"trusted identity", releaser, true},
+		{"other identity", developer, false},
+	} {
+		got, err := p.trusts(tt.key)
+		if err != nil {
+			t.Fatalf("%v: %v", tt.desc, err)
+		}
+		if got != tt.want {
+			t.Errorf("%v: trusted %v, want %v", tt.desc, got, tt.want)
+		}
+	}
+
+	for _, invalid := range []signerPolicy{
+		{},
+		{Signers: []trustedSigner{{}}},
+		{Signers: []trustedSigner{{PublicKey: "not a key"}}},
+		{Signers: []trustedSigner{{PublicKey: trustedPEM, Fingerprint: strings.Repeat("0", 64)}}},
+	} {
+		invalid := invalid
+		if err := invalid.init(); err == nil {
+			t.Errorf("expected policy %+v to be invalid", invalid)
+		}
+	}
+}
+
+func review(t *testing.T, wh *webhook, namespace, object string) *admissionResponse {
+	t.Helper()
+	body, _ := json.Marshal(admissionReview{
+		APIVersion: "admission.k8s.io/v1",
+		Kind:       "AdmissionReview",
+		Request:    &admissionRequest{UID: "uid", Namespace: namespace, Operation: "CREATE", Object: json.RawMessage(object)},
+	})
+	rec := httptest.NewRecorder()
+	wh.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/validate", bytes.NewReader(body)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("unexpected status %d: %s", rec.Code, rec.Body)
+	}
+	resp := admissionReview{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.APIVersion != "admission.k8s.io/v1" || resp.Response == nil || resp.Response.UID != "uid" {
+		t.Fatalf("unexpected review returned: %s", rec.Body)
+	}
+	return resp.Response
+}
+
+func TestWebhook(t *testing.T) {
+	trustedKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	trustedPEM, trusted := publicKey(t, trustedKey)
+	otherKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	_, other := publicKey(t, otherKey)
+
+	p := &signerPolicy{Signers: []trustedSigner{{PublicKey: trustedPEM}}, ExemptNamespaces: []string{"kube-system"}}
+	if err := p.init(); err != nil {
+		t.Fatal(err)
+	}
+	signed, signedByOther, unknown := digestOf("signed"), digestOf("other"), digestOf("unknown")
+	wh := &webhook{
+		policy: p,
+		signers: func(ctx context.Context, digest string) ([]pki.PublicKey, error) {
+			switch digest {
+			case signed:
+				return []pki.PublicKey{other, trusted}, nil
+			case signedByOther:
+				return []pki.PublicKey{other}, nil
+			}
+			return nil, nil
+		},
+		timeout: time.Second,
+	}
+
+	pod := func(images ...string) string {
+		var containers []string
+		for _, image := range images {
+			containers = append(containers, fmt.Sprintf(`{"image":%q}`, image))
+		}
+		return `{"kind":"Pod","spec":{"containers":[` + strings.Join(containers, ",") + `]}}`
+	}
+	for _, tt := range []struct {
+		desc      string
+		namespace string
+		object    string
+		allowed   bool
+	}{
+		{"signed image", "default", pod("app@" + signed), true},
+		{"unpinned image", "default", pod("app:latest"), false},
+		{"image signed by other", "default", pod("app@"+signed, "sidecar@"+signedByOther), false},
+		{"image not in log", "default", pod("app@" + unknown), false},
+		{"exempt namespace", "kube-system", pod("app:latest"), true},
+		{"deletion", "default", "", true},
+	} {
+		resp := review(t, wh, tt.namespace, tt.object)
+		if resp.Allowed != tt.allowed {
+			t.Errorf("%v: allowed %v, want %v (%+v)", tt.desc, resp.Allowed, tt.allowed, resp.Result)
+		}
+		if !resp.Allowed && (resp.Result == nil || resp.Result.Message == "") {
+			t.Errorf("%v: denied without a message", tt.desc)
+		}
+	}
+}
+
+// hashedrekordEntry returns an entry signed by logSigner for a hashedrekord of digest signed by key
+func hashedrekordEntry(t *testing.T, logSigner signature.Signer, logID string, key *ecdsa.PrivateKey, digest string) (string, models.LogEntryAnon) {
+	t.Helper()
+	pemBytes, _ := cryptoutils.MarshalPublicKeyToPEM(key.Public())
+	hashBytes, _ := hex.DecodeString(strings.TrimPrefix(digest, "sha256:"))
+	sig, err := ecdsa.SignASN1(rand.Reader, key, hashBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body := []byte(fmt.Sprintf(`{"apiVersion":"0.0.1","kind":"hashedrekord","spec":{"data":{"hash":{"algorithm":"sha256","value":%q}},"signature":{"content":%q,"publicKey":{"content":%q}}}}`,
+		strings.TrimPrefix(digest, "sha256:"), base64.StdEncoding.EncodeToString(sig), base64.StdEncoding.EncodeToString(pemBytes)))
+
+	e := models.LogEntryAnon{
+		LogID:          swag.String(logID),
+		LogIndex:       swag.Int64(0),
+		IntegratedTime: swag.Int64(time.Now().Unix()),
+		Body:           base64.StdEncoding.EncodeToString(body),
+	}
+	payload, _ := e.MarshalBinary()
+	canonicalized, err := jsoncanonicalizer.Transform(payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	set, err := logSigner.SignMessage(bytes.NewReader(canonicalized))
+	if err != nil {
+		t.Fatal(err)
+	}
+	e.Verification = &models.LogEntryAnonVerification{SignedEntryTimestamp: set}
+	return util.EntryUUID(body), e
+}
+
+func TestEntrySigners(t *testing.T) {
+	logKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	logSigner, _ := signature.LoadSigner(logKey, crypto.SHA256)
+	logVerifier, _ := signature.LoadVerifier(logKey.Public(), crypto.SHA256)
+	logID, err := verify.LogID(logVerifier)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	_, want := publicKey(t, key)
+
+	digest := digestOf("image")
+	uuid, e := hashedrekordEntry(t, logSigner, logID, key, digest)
+	signers, err := entrySigners(logVerifier, digest, uuid, e)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(signers) != 1 {
+		t.Fatalf("got %d signers, want 1", len(signers))
+	}
+	got, _ := signers[0].CanonicalValue()
+	wantValue, _ := want.CanonicalValue()
+	if !bytes.Equal(got, wantValue) {
+		t.Errorf("unexpected signer %s", got)
+	}
+
+	// the search index may return entries for other artifacts
+	if _, err := entrySigners(logVerifier, digestOf("other"), uuid, e); err == nil {
+		t.Error("expected error for entry of another digest")
+	}
+	otherLogKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	otherLogVerifier, _ := signature.LoadVerifier(otherLogKey.Public(), crypto.SHA256)
+	if _, err := entrySigners(otherLogVerifier, digest, uuid, e); err == nil {
+		t.Error("expected error for entry of another log")
+	}
+}
@@ -0,0 +1,514 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package app
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"os"
+	"os/signal"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/sigstore/rekor/cmd/rekor-cli/app/format"
+	"github.com/sigstore/rekor/pkg/client"
+	genclient "github.com/sigstore/rekor/pkg/generated/client"
+	"github.com/sigstore/rekor/pkg/generated/client/entries"
+	"github.com/sigstore/rekor/pkg/generated/client/tlog"
+	"github.com/sigstore/rekor/pkg/log"
+	"github.com/sigstore/rekor/pkg/types"
+)
+
+const (
+	benchAdd   = "add"
+	benchGet   = "get"
+	benchProof = "proof"
+)
+
+// benchOps lists the operations of the bench command in the order they are reported
+var benchOps = []string{benchAdd, benchGet, benchProof}
+
+// benchMix holds the relative weights with which the bench command picks operations
+type benchMix map[string]int
+
+// parseBenchMix parses weights given as e.g. add=1,get=8,proof=1; operations left out are not sent
+func parseBenchMix(s string) (benchMix, error) {
+	mix := benchMix{}
+	total := 0
+	for _, field := range strings.Split(s, ",") {
+		kv := strings.SplitN(strings.TrimSpace(field), "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("invalid weight %q, expected <operation>=<weight>", field)
+		}
+		op := strings.TrimSpace(kv[0])
+		known := false
+		for _, o := range benchOps {
+			known = known || o == op
+		}
+		if !known {
+			return nil, fmt.Errorf("unknown operation %q, expected one of %s", op, strings.Join(benchOps, ", "))
+		}
+		if _, ok := mix[op]; ok {
+			return nil, fmt.Errorf("duplicate weight for %s", op)
+		}
+		weight, err := strconv.Atoi(strings.TrimSpace(kv[1]))
+		if err != nil || weight < 0 {
+			return nil, fmt.Errorf("invalid weight %q for %s", kv[1], op)
+		}
+		mix[op] = weight
+		total += weight
+	}
+	if total == 0 {
+		return nil, errors.New("at least one operation must have a weight greater than zero")
+	}
+	return mix, nil
+}
+
+// pick returns the operation selected by n, a number in [0, total weight)
+func (m benchMix) pick(n int) string {
+	for _, op := range benchOps {
+		if n < m[op] {
+			return op
+		}
+		n -= m[op]
+	}
+	return ""
+}
+
+func (m benchMix) total() int {
+	total := 0
+	for _, w := range m {
+		total += w
+	}
+	return total
+}
+
+// percentile returns the latency below which p percent of the sorted latencies fall
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+// benchRecorder collects the latencies and errors of the requests sent by the bench command
+type benchRecorder struct {
+	mu        sync.Mutex
+	latencies map[string][]time.Duration
+	errors    map[string]int
+	firstErr  map[string]string
+}
+
+func newBenchRecorder() *benchRecorder {
+	return &benchRecorder{
+		latencies: map[string][]time.Duration{},
+		errors:    map[string]int{},
+		firstErr:  map[string]string{},
+	}
+}
+
+func (r *benchRecorder) record(op string, latency time.Duration, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err != nil {
+		r.errors[op]++
+		if _, ok := r.firstErr[op]; !ok {
+			r.firstErr[op] = err.Error()
+		}
+		return
+	}
+	r.latencies[op] = append(r.latencies[op], latency)
+}
+
+// results summarizes the requests recorded over the given duration
+func (r *benchRecorder) results(elapsed time.Duration) []benchOpResult {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var results []benchOpResult
+	for _, op := range benchOps {
+		latencies := r.latencies[op]
+		requests := len(latencies) + r.errors[op]
+		if requests == 0 {
+			continue
+		}
+		sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+		results = append(results, benchOpResult{
+			Operation:  op,
+			Requests:   requests,
+			Errors:     r.errors[op],
+			ErrorRate:  float64(r.errors[op]) / float64(requests),
+			Throughput: float64(len(latencies)) / elapsed.Seconds(),
+			P50:        percentile(latencies, 50),
+			P90:        percentile(latencies, 90),
+			P99:        percentile(latencies, 99),
+			Max:        percentile(latencies, 100),
+			FirstError: r.firstErr[op],
+		})
+	}
+	return results
+}
+
+type benchOpResult struct {
+	Operation string
+	Requests  int
+	Errors    int
+	ErrorRate float64
+	// Throughput is the number of successful requests per second
+	Throughput float64
+	// P50, P90, P99 and Max are the latencies of successful requests
+	P50        time.Duration
+	P90        time.Duration
+	P99        time.Duration
+	Max        time.Duration
+	FirstError string `json:",omitempty"`
+}
+
+type benchCmdOutput struct {
+	Server     string
+	Duration   time.Duration
+	TargetRate int
+	// Dropped counts the requests not sent because every worker was still busy
+	Dropped    int64
+	Operations []benchOpResult
+}
+
+func (b *benchCmdOutput) String() string {
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, "Benchmarked %s for %s at a target of %d requests/s\n", b.Server, b.Duration.Round(time.Millisecond), b.TargetRate)
+	if b.Dropped > 0 {
+		fmt.Fprintf(buf, "%d requests dropped because all workers were busy; raise --concurrency to reach the target rate\n", b.Dropped)
+	}
+	w := tabwriter.NewWriter(buf, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "OPERATION\tREQUESTS\tERRORS\tERROR RATE\tREQ/S\tP50\tP90\tP99\tMAX")
+	for _, r := range b.Operations {
+		fmt.Fprintf(w, "%s\t%d\t%d\t%.2f%%\t%.1f\t%s\t%s\t%s\t%s\n", r.Operation, r.Requests, r.Errors, 100*r.ErrorRate, r.Throughput,
+			r.P50.Round(time.Microsecond), r.P90.Round(time.Microsecond), r.P99.Round(time.Microsecond), r.Max.Round(time.Microsecond))
+	}
+	_ = w.Flush()
+	for _, r := range b.Operations {
+		if r.FirstError != "" {
+			fmt.Fprintf(buf, "first %s error: %s\n", r.Operation, r.FirstError)
+		}
+	}
+	return buf.String()
+}
+
+// benchClient sends the requests of the bench command, signing synthetic artifacts with a key
+// generated for the run
+type benchClient struct {
+	rekorClient  *genclient.Rekor
+	key          *ecdsa.PrivateKey
+	publicKey    []byte
+	artifactSize int
+	// treeSize is the largest log size known to the client, which bounds the indexes and sizes requested
+	treeSize int64
+}
+
+func newBenchClient(rekorClient *genclient.Rekor, artifactSize int) (*benchClient, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	return &benchClient{
+		rekorClient:  rekorClient,
+		key:          key,
+		publicKey:    pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}),
+		artifactSize: artifactSize,
+	}, nil
+}
+
+func (b *benchClient) grow(size int64) {
+	for {
+		current := atomic.LoadInt64(&b.treeSize)
+		if size <= current || atomic.CompareAndSwapInt64(&b.treeSize, current, size) {
+			return
+		}
+	}
+}
+
+// randInt64 returns a uniformly random number in [0, n)
+func randInt64(n int64) (int64, error) {
+	i, err := rand.Int(rand.Reader, big.NewInt(n))
+	if err != nil {
+		return 0, err
+	}
+	return i.Int64(), nil
+}
+
+func (b *benchClient) logInfo(ctx context.Context) error {
+	params := tlog.NewGetLogInfoParamsWithContext(ctx)
+	params.SetTimeout(viper.GetDuration("timeout"))
+	resp, err := b.rekorClient.Tlog.GetLogInfo(params)
+	if err != nil {
+		return err
+	}
+	b.grow(*resp.Payload.TreeSize)
+	return nil
+}
+
+func (b *benchClient) run(ctx context.Context, op string) error {
+	switch op {
+	case benchAdd:
+		return b.add(ctx)
+	case benchGet:
+		return b.get(ctx)
+	case benchProof:
+		return b.proof(ctx)
+	}
+	return fmt.Errorf("unknown operation %s", op)
+}
+
+// add uploads a hashedrekord entry for a random artifact
+func (b *benchClient) add(ctx context.Context) error {
+	artifact := make([]byte, b.artifactSize)
+	if _, err := rand.Read(artifact); err != nil {
+		return err
+	}
+	digest := sha256.Sum256(artifact)
+	sig, err := ecdsa.SignASN1(rand.Reader, b.key, digest[:])
+	if err != nil {
+		return err
+	}
+	entry, err := types.NewProposedEntry(ctx, "hashedrekord", "", types.ArtifactProperties{
+		ArtifactHash:   hex.EncodeToString(digest[:]),
+		SignatureBytes: sig,
+		PublicKeyBytes: b.publicKey,
+		PKIFormat:      "x509",
+	})
+	if err != nil {
+		return err
+	}
+
+	params := entries.NewCreateLogEntryParamsWithContext(ctx)
+	params.SetTimeout(viper.GetDuration("timeout"))
+	params.SetProposedEntry(entry)
+	resp, err := b.rekorClient.Entries.CreateLogEntry(params)
+	if err != nil {
+		return err
+	}
+	for _, e := range resp.Payload {
+		if e.LogIndex != nil {
+			b.grow(*e.LogIndex + 1)
+		}
+	}
+	return nil
+}
+
+// get fetches the entry at a random index of the log
+func (b *benchClient) get(ctx context.Context) error {
+	size := atomic.LoadInt64(&b.treeSize)
+	if size == 0 {
+		return errors.New("the log has no entries to get")
+	}
+	index, err := randInt64(size)
+	if err != nil {
+		return err
+	}
+	params := entries.NewGetLogEntryByIndexParamsWithContext(ctx)
+	params.SetTimeout(viper.GetDuration("timeout"))
+	params.SetLogIndex(index)
+	_, err = b.rekorClient.Entries.GetLogEntryByIndex(params)
+	return err
+}
+
+// proof fetches the consistency proof from a random earlier size of the log to the largest known one
+func (b *benchClient) proof(ctx context.Context) error {
+	size := atomic.LoadInt64(&b.treeSize)
+	if size == 0 {
+		return errors.New("the log has no entries to prove consistency of")
+	}
+	first, err := randInt64(size)
+	if err != nil {
+		return err
+	}
+	first++
+	params := tlog.NewGetLogProofParamsWithContext(ctx)
+	params.SetTimeout(viper.GetDuration("timeout"))
+	params.FirstSize = &first
+	params.LastSize = size
+	_, err = b.rekorClient.Tlog.GetLogProof(params)
+	return err
+}
+
+// maxBenchRate is the highest rate the ticker of runBench can keep, with one request every nanosecond
+const maxBenchRate = int(time.Second)
+
+// validateBenchRate checks that requests can be sent at rate per second
+func validateBenchRate(rate int) error {
+	if rate <= 0 || rate > maxBenchRate {
+		return fmt.Errorf("--rate must be between 1 and %d requests per second", maxBenchRate)
+	}
+	return nil
+}
+
+// runBench sends requests picked from mix at the given rate until duration has passed or ctx is
+// cancelled, using at most concurrency requests at a time
+func runBench(ctx context.Context, b *benchClient, mix benchMix, rate, concurrency int, duration time.Duration) (*benchCmdOutput, error) {
+	if err := validateBenchRate(rate); err != nil {
+		return nil, err
+	}
+	if err := b.logInfo(ctx); err != nil {
+		return nil, fmt.Errorf("error fetching the size of the log: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, duration)
+	defer cancel()
+
+	recorder := newBenchRecorder()
+	jobs := make(chan string, concurrency)
+	wg := sync.WaitGroup{}
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for op := range jobs {
+				start := time.Now()
+				err := b.run(ctx, op)
+				if ctx.Err() != nil {
+					// requests cut short by the end of the run say nothing about the server
+					continue
+				}
+				recorder.record(op, time.Since(start), err)
+			}
+		}()
+	}
+
+	var dropped int64
+	total := mix.total()
+	ticker := time.NewTicker(time.Second / time.Duration(rate))
+	start := time.Now()
+loop:
+	for {
+		select {
+		case <-ctx.Done():
+			break loop
+		case <-ticker.C:
+			n, err := randInt64(int64(total))
+			if err != nil {
+				ticker.Stop()
+				close(jobs)
+				return nil, err
+			}
+			select {
+			case jobs <- mix.pick(int(n)):
+			default:
+				dropped++
+			}
+		}
+	}
+	ticker.Stop()
+	close(jobs)
+	wg.Wait()
+	elapsed := time.Since(start)
+
+	return &benchCmdOutput{
+		Server:     viper.GetString("rekor_server"),
+		Duration:   elapsed,
+		TargetRate: rate,
+		Dropped:    dropped,
+		Operations: recorder.results(elapsed),
+	}, nil
+}
+
+// benchCmd represents the bench command
+var benchCmd = &cobra.Command{
+	Use:   "bench",
+	Short: "Rekor load testing command",
+	Long: `Sends a steady rate of requests to the server for a while and reports their latency percentiles
+and error rates, to size the Trillian and MySQL backends of a log.
+
+Requests are picked at random by the weights of --mix: add uploads a hashedrekord entry for a random
+artifact signed with a key generated for the run, get fetches the entry at a random index and proof
+fetches the consistency proof from a random size to the current size of the log. Every added entry
+stays in the log, so only point it at a log meant for testing.`,
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		// these are bound here so that they are not overwritten by other commands
+		if err := viper.BindPFlags(cmd.Flags()); err != nil {
+			return err
+		}
+		if err := validateBenchRate(viper.GetInt("rate")); err != nil {
+			return err
+		}
+		if viper.GetInt("concurrency") <= 0 {
+			return errors.New("--concurrency must be greater than zero")
+		}
+		if viper.GetDuration("duration") <= 0 {
+			return errors.New("--duration must be greater than zero")
+		}
+		if viper.GetInt("artifact-size") <= 0 {
+			return errors.New("--artifact-size must be greater than zero")
+		}
+		_, err := parseBenchMix(viper.GetString("mix"))
+		return err
+	},
+	Run: format.WrapCmd(func(args []string) (interface{}, error) {
+		ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer cancel()
+
+		mix, err := parseBenchMix(viper.GetString("mix"))
+		if err != nil {
+			return nil, err
+		}
+		rekorClient, err := client.GetRekorClient(viper.GetString("rekor_server"))
+		if err != nil {
+			return nil, err
+		}
+		b, err := newBenchClient(rekorClient, viper.GetInt("artifact-size"))
+		if err != nil {
+			return nil, err
+		}
+		log.CliLogger.Infof("Sending %d requests/s to %s for %s", viper.GetInt("rate"), viper.GetString("rekor_server"), viper.GetDuration("duration"))
+		return runBench(ctx, b, mix, viper.GetInt("rate"), viper.GetInt("concurrency"), viper.GetDuration("duration"))
+	}),
+}
+
+func init() {
+	initializePFlagMap()
+	benchCmd.Flags().Duration("duration", 30*time.Second, "how long to send requests for")
+	benchCmd.Flags().Int("rate", 10, "requests per second to send")
+	benchCmd.Flags().Int("concurrency", 10, "maximum number of requests in flight; requests due while all are busy are dropped and counted")
+	benchCmd.Flags().String("mix", "add=1,get=8,proof=1", "relative weights of the add, get and proof operations")
+	benchCmd.Flags().Int("artifact-size", 1024, "size in bytes of the random artifacts signed for add requests")
+	rootCmd.AddCommand(benchCmd)
+}
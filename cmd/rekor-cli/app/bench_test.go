@@ -0,0 +1,82 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package app
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseBenchMix(t *testing.T) {
+	mix, err := parseBenchMix("add=1, get=8,proof=0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mix.total() != 9 {
+		t.Errorf("total weight %d, want 9", mix.total())
+	}
+	for n, want := range map[int]string{0: benchAdd, 1: benchGet, 8: benchGet} {
+		if got := mix.pick(n); got != want {
+			t.Errorf("pick(%d) = %s, want %s", n, got, want)
+		}
+	}
+
+	for _, invalid := range []string{"", "add", "put=1", "add=-1", "add=x", "add=1,add=2", "add=0,get=0"} {
+		if _, err := parseBenchMix(invalid); err == nil {
+			t.Errorf("expected error for %q", invalid)
+		}
+	}
+}
+
+func TestValidateBenchRate(t *testing.T) {
+	for rate, valid := range map[int]bool{1: true, 10: true, 1e9: true, 0: false, -1: false, 1e9 + 1: false} {
+		if err := validateBenchRate(rate); (err == nil) != valid {
+			t.Errorf("rate %d: got error %v, want valid %v", rate, err, valid)
+		}
+		// every valid rate gives the ticker a positive interval
+		if valid && time.Second/time.Duration(rate) <= 0 {
+			t.Errorf("rate %d accepted without a positive interval", rate)
+		}
+	}
+}
+
+func TestBenchRecorder(t *testing.T) {
+	r := newBenchRecorder()
+	for i := 1; i <= 100; i++ {
+		r.record(benchGet, time.Duration(i)*time.Millisecond, nil)
+	}
+	r.record(benchGet, time.Second, errors.New("503 Service Unavailable"))
+	r.record(benchGet, time.Second, errors.New("timeout"))
+
+	results := r.results(10 * time.Second)
+	if len(results) != 1 {
+		t.Fatalf("got results for %d operations, want 1", len(results))
+	}
+	got := results[0]
+	if got.Requests != 102 || got.Errors != 2 || got.FirstError != "503 Service Unavailable" {
+		t.Errorf("unexpected counts %+v", got)
+	}
+	if got.Throughput != 10 {
+		t.Errorf("throughput %v, want 10", got.Throughput)
+	}
+	if got.P50 != 50*time.Millisecond || got.P90 != 90*time.Millisecond || got.P99 != 99*time.Millisecond || got.Max != 100*time.Millisecond {
+		t.Errorf("unexpected percentiles %+v", got)
+	}
+	if percentile(nil, 50) != 0 {
+		t.Error("expected no latency without requests")
+	}
+}
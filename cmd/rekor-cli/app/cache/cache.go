@@ -0,0 +1,120 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"github.com/mitchellh/go-homedir"
+)
+
+// Cache stores responses of a rekor server on disk: entries and proofs, which do not change once
+// fetched, and tree heads, which are only used for a limited time. Everything read from the cache
+// is verified just like a response from the server. A nil *Cache stores nothing.
+type Cache struct {
+	dir string
+}
+
+// keys are slash separated paths within the directory of the cache, e.g. entries/<uuid>
+var keyRegex = regexp.MustCompile(`^[a-z0-9-]+(/[a-z0-9-]+)*$`)
+
+// New returns the cache for the server at serverURL, kept under ~/.rekor/cache
+func New(serverURL string) (*Cache, error) {
+	home, err := homedir.Dir()
+	if err != nil {
+		return nil, err
+	}
+	return newCache(filepath.Join(home, ".rekor", "cache", url.QueryEscape(serverURL))), nil
+}
+
+func newCache(dir string) *Cache {
+	return &Cache{dir: dir}
+}
+
+func (c *Cache) path(key string) (string, error) {
+	if !keyRegex.MatchString(key) {
+		return "", fmt.Errorf("invalid cache key %q", key)
+	}
+	return filepath.Join(c.dir, filepath.FromSlash(key)+".json"), nil
+}
+
+// Get decodes the value stored under key into v, reporting whether there was one
+func (c *Cache) Get(key string, v interface{}) bool {
+	return c.GetFresh(key, 0, v)
+}
+
+// GetFresh is like Get, but ignores values which were stored more than ttl ago; a ttl of zero
+// accepts values of any age
+func (c *Cache) GetFresh(key string, ttl time.Duration, v interface{}) bool {
+	if c == nil {
+		return false
+	}
+	p, err := c.path(key)
+	if err != nil {
+		return false
+	}
+	if ttl > 0 {
+		fi, err := os.Stat(p)
+		if err != nil || time.Since(fi.ModTime()) > ttl {
+			return false
+		}
+	}
+	b, err := ioutil.ReadFile(filepath.Clean(p))
+	if err != nil {
+		return false
+	}
+	return json.Unmarshal(b, v) == nil
+}
+
+// Put stores v under key, replacing any previous value
+func (c *Cache) Put(key string, v interface{}) error {
+	if c == nil {
+		return nil
+	}
+	p, err := c.path(key)
+	if err != nil {
+		return err
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0750); err != nil {
+		return err
+	}
+
+	// write to a temporary file and rename it so that concurrent invocations never read a partially written value
+	tmp, err := ioutil.TempFile(filepath.Dir(p), filepath.Base(p)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), p)
+}
@@ -0,0 +1,66 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCache(t *testing.T) {
+	c := newCache(filepath.Join(t.TempDir(), "cache"))
+
+	var got string
+	if c.Get("entries/abc", &got) {
+		t.Fatal("unexpected value in empty cache")
+	}
+	if err := c.Put("entries/abc", "value"); err != nil {
+		t.Fatal(err)
+	}
+	if !c.Get("entries/abc", &got) || got != "value" {
+		t.Errorf("expected stored value, got %q", got)
+	}
+
+	if err := c.Put("treehead", "head"); err != nil {
+		t.Fatal(err)
+	}
+	if !c.GetFresh("treehead", time.Minute, &got) || got != "head" {
+		t.Errorf("expected fresh value, got %q", got)
+	}
+	old := time.Now().Add(-2 * time.Minute)
+	if err := os.Chtimes(filepath.Join(c.dir, "treehead.json"), old, old); err != nil {
+		t.Fatal(err)
+	}
+	if c.GetFresh("treehead", time.Minute, &got) {
+		t.Error("expected value older than the ttl to be ignored")
+	}
+	if !c.Get("treehead", &got) {
+		t.Error("expected value without ttl regardless of its age")
+	}
+
+	for _, key := range []string{"../state", "entries/../../x", "Entries", ""} {
+		if err := c.Put(key, "x"); err == nil {
+			t.Errorf("expected invalid key %q to be rejected", key)
+		}
+	}
+
+	var nilCache *Cache
+	if err := nilCache.Put("entries/abc", "value"); err != nil || nilCache.Get("entries/abc", &got) {
+		t.Error("expected nil cache to store nothing")
+	}
+}
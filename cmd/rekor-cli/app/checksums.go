@@ -0,0 +1,145 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package app
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/viper"
+
+	"github.com/sigstore/rekor/pkg/pki"
+	"github.com/sigstore/rekor/pkg/types"
+	"github.com/sigstore/rekor/pkg/types/checksums"
+)
+
+type uploadedArtifact struct {
+	Name   string
+	Result interface{}
+}
+
+type uploadChecksumsOutput struct {
+	Artifacts []uploadedArtifact
+}
+
+func (u *uploadChecksumsOutput) String() string {
+	var b strings.Builder
+	for _, a := range u.Artifacts {
+		fmt.Fprintf(&b, "%v: %v", a.Name, a.Result)
+	}
+	return b.String()
+}
+
+// validateChecksumsPFlags checks the flags given along with --checksums, which replaces the artifact
+func validateChecksumsPFlags() error {
+	for _, flag := range []string{"entry", "artifact", "artifact-hash", "image", "bundle"} {
+		if viper.GetString(flag) != "" {
+			return fmt.Errorf("--%v cannot be used with --checksums", flag)
+		}
+	}
+	if len(viper.GetStringSlice("signature")) != 1 || len(viper.GetStringSlice("public-key")) != 1 {
+		return errors.New("--checksums requires a single --signature over the checksums file and the --public-key to verify it")
+	}
+	return nil
+}
+
+// readFileOrURL reads a local file or downloads one given by URL
+func readFileOrURL(ctx context.Context, u *url.URL) ([]byte, error) {
+	if !u.IsAbs() {
+		if err := checkFileSize(u.Path); err != nil {
+			return nil, err
+		}
+		return ioutil.ReadFile(filepath.Clean(u.Path))
+	}
+	c, err := downloadURL(ctx, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer removeDownload(c)
+	return c.Bytes()
+}
+
+// readChecksums reads the checksums file, its signature and public key once and verifies the signature,
+// so that the same properties can be used for the entry of each artifact listed in the file
+func readChecksums(ctx context.Context, props *types.ArtifactProperties) ([]checksums.Artifact, error) {
+	var err error
+	if props.ArtifactBytes, err = readFileOrURL(ctx, props.ArtifactPath); err != nil {
+		return nil, fmt.Errorf("error reading checksums file: %w", err)
+	}
+	if props.SignatureBytes, err = readFileOrURL(ctx, props.SignaturePath); err != nil {
+		return nil, fmt.Errorf("error reading signature file: %w", err)
+	}
+	if props.PublicKeyBytes, err = readFileOrURL(ctx, props.PublicKeyPath); err != nil {
+		return nil, fmt.Errorf("error reading public key file: %w", err)
+	}
+
+	af, err := pki.NewArtifactFactory(pki.Format(props.PKIFormat))
+	if err != nil {
+		return nil, err
+	}
+	sig, err := af.NewSignature(bytes.NewReader(props.SignatureBytes))
+	if err != nil {
+		return nil, err
+	}
+	key, err := af.NewPublicKey(bytes.NewReader(props.PublicKeyBytes))
+	if err != nil {
+		return nil, err
+	}
+	if err := sig.Verify(bytes.NewReader(props.ArtifactBytes), key); err != nil {
+		return nil, fmt.Errorf("error verifying signature over checksums file: %w", err)
+	}
+
+	return checksums.Parse(props.ArtifactBytes)
+}
+
+// uploadChecksums verifies the signed checksums file given by --checksums and uploads an entry for each
+// artifact it lists
+func uploadChecksums(ctx context.Context) (interface{}, error) {
+	props := CreatePropsFromPflags()
+	props.ArtifactPath = fileOrURL(viper.GetString("checksums"))
+	cleanup, err := resolveRemotePublicKeys(ctx, props)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+
+	artifacts, err := readChecksums(ctx, props)
+	if err != nil {
+		return nil, err
+	}
+
+	output := &uploadChecksumsOutput{}
+	for _, artifact := range artifacts {
+		props.ArtifactName = artifact.Name
+		entry, err := types.NewProposedEntry(ctx, checksums.KIND, "", *props)
+		if err != nil {
+			return nil, fmt.Errorf("error creating entry for %v: %w", artifact.Name, err)
+		}
+
+		result, err := publishEntry(entry)
+		if err != nil {
+			return nil, fmt.Errorf("error uploading entry for %v: %w", artifact.Name, err)
+		}
+		output.Artifacts = append(output.Artifacts, uploadedArtifact{Name: artifact.Name, Result: result})
+	}
+	return output, nil
+}
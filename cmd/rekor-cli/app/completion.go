@@ -0,0 +1,96 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package app
+
+import (
+	"sort"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+
+	"github.com/sigstore/rekor/pkg/pki"
+	"github.com/sigstore/rekor/pkg/types"
+	"github.com/sigstore/rekor/pkg/util"
+)
+
+type completionFunc func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective)
+
+func completeValues(values func() []string) completionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return values(), cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// completeProfile completes the names of the profiles in the config file; as completions are generated
+// without running the commands, the config file is read here
+func completeProfile(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if _, err := readConfig(); err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	return profileNames(viper.GetViper()), cobra.ShellCompDirectiveNoFileComp
+}
+
+func entryTypes() []string {
+	var names []string
+	types.TypeMap.Range(func(k interface{}, v interface{}) bool {
+		names = append(names, k.(string))
+		return true
+	})
+	names = append(names, types.ListImplementedTypes()...)
+	sort.Strings(names)
+	return names
+}
+
+func pkiFormats() []string {
+	formats := pki.SupportedFormats()
+	sort.Strings(formats)
+	return formats
+}
+
+// flagCompletions are the completions of the values of flags by their type
+var flagCompletions = map[FlagType]completionFunc{
+	formatFlag:    completeValues(func() []string { return []string{"default", "json"} }),
+	pkiFormatFlag: completeValues(pkiFormats),
+	typeFlag:      completeValues(entryTypes),
+	hashAlgFlag:   completeValues(func() []string { return []string{util.SHA256, util.SHA384, util.SHA512} }),
+}
+
+// registerCompletions registers the completions of the values of the flags of cmd and its subcommands,
+// which are used by the scripts of the completion command for bash, zsh, fish and powershell
+func registerCompletions(cmd *cobra.Command) error {
+	var err error
+	register := func(f *pflag.Flag) {
+		complete, ok := flagCompletions[FlagType(f.Value.Type())]
+		if f.Name == "profile" {
+			complete, ok = completeProfile, true
+		}
+		if ok && err == nil {
+			err = cmd.RegisterFlagCompletionFunc(f.Name, complete)
+		}
+	}
+	cmd.LocalNonPersistentFlags().VisitAll(register)
+	cmd.PersistentFlags().VisitAll(register)
+	if err != nil {
+		return err
+	}
+	for _, sub := range cmd.Commands() {
+		if err := registerCompletions(sub); err != nil {
+			return err
+		}
+	}
+	return nil
+}
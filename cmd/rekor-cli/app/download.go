@@ -0,0 +1,149 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package app
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+
+	"github.com/mitchellh/go-homedir"
+	"github.com/spf13/viper"
+
+	"github.com/sigstore/rekor/cmd/rekor-cli/app/download"
+	"github.com/sigstore/rekor/pkg/client"
+	"github.com/sigstore/rekor/pkg/log"
+	"github.com/sigstore/rekor/pkg/types"
+)
+
+// downloaded tracks the files holding content downloaded by the command, which are removed if it is
+// interrupted; completed downloads are otherwise removed by their callers, while partial ones are kept
+// so that they can be resumed
+var downloaded = struct {
+	sync.Mutex
+	once  sync.Once
+	paths map[string]bool
+}{paths: map[string]bool{}}
+
+// trackDownload records content held in a file until it is removed, removing it if the command is
+// interrupted first
+func trackDownload(c *download.Content) {
+	if c.Path() == "" {
+		return
+	}
+	downloaded.once.Do(func() {
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+		go func() {
+			<-signals
+			downloaded.Lock()
+			for path := range downloaded.paths {
+				_ = os.Remove(path)
+			}
+			os.Exit(1)
+		}()
+	})
+	downloaded.Lock()
+	defer downloaded.Unlock()
+	downloaded.paths[c.Path()] = true
+}
+
+// removeDownload removes content returned by downloadURL once it is no longer needed
+func removeDownload(c *download.Content) {
+	if err := c.Remove(); err != nil {
+		log.CliLogger.Warnf("unable to remove downloaded content: %v", err)
+	}
+	downloaded.Lock()
+	defer downloaded.Unlock()
+	delete(downloaded.paths, c.Path())
+}
+
+// downloadURL fetches rawURL, keeping content of at most --memory-threshold bytes in memory and spooling
+// larger content to ~/.rekor/downloads, where an interrupted download of the same URL is resumed from.
+// Content larger than --max-artifact-size is rejected. The caller removes the content with
+// removeDownload once it is no longer needed.
+func downloadURL(ctx context.Context, rawURL string, digest []byte) (*download.Content, error) {
+	home, err := homedir.Dir()
+	if err != nil {
+		return nil, err
+	}
+	dir := filepath.Join(home, ".rekor", "downloads")
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	name := sha256.Sum256([]byte(rawURL))
+	path := filepath.Join(dir, hex.EncodeToString(name[:]))
+
+	c, err := download.Fetch(ctx, rawURL, path, download.Options{
+		Connections:     viper.GetInt("download-connections"),
+		Digest:          digest,
+		Client:          client.NewFetchClient(),
+		MaxSize:         viper.GetInt64("max-artifact-size"),
+		MemoryThreshold: viper.GetInt64("memory-threshold"),
+	})
+	if err != nil {
+		return nil, err
+	}
+	trackDownload(c)
+	return c, nil
+}
+
+// checkFileSize rejects a local file larger than --max-artifact-size before it is read into memory
+func checkFileSize(path string) error {
+	limit := viper.GetInt64("max-artifact-size")
+	if limit <= 0 {
+		return nil
+	}
+	fi, err := os.Stat(filepath.Clean(path))
+	if err != nil {
+		return err
+	}
+	if fi.Size() > limit {
+		return fmt.Errorf("%v is %d bytes, larger than --max-artifact-size %d", path, fi.Size(), limit)
+	}
+	return nil
+}
+
+// hashArtifactURL downloads an artifact given by URL to compute the digest recorded in the entry, which
+// has to match the --artifact-hash if one was given
+func hashArtifactURL(ctx context.Context, props *types.ArtifactProperties) error {
+	var pin []byte
+	if props.ArtifactHash != "" {
+		hash := strings.TrimPrefix(props.ArtifactHash, "sha256:")
+		if len(hash) != 2*sha256.Size {
+			return fmt.Errorf("the hash of an artifact fetched from a URL must be a SHA256 digest, got %v", props.ArtifactHash)
+		}
+		var err error
+		if pin, err = hex.DecodeString(hash); err != nil {
+			return fmt.Errorf("invalid artifact hash: %w", err)
+		}
+	}
+
+	c, err := downloadURL(ctx, props.ArtifactPath.String(), pin)
+	if err != nil {
+		return fmt.Errorf("error fetching artifact: %w", err)
+	}
+	removeDownload(c)
+	props.ArtifactHash = hex.EncodeToString(c.Digest)
+	return nil
+}
@@ -0,0 +1,551 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package download
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// DefaultChunkSize is the size of the ranges fetched by each connection unless configured otherwise
+const DefaultChunkSize = 8 << 20
+
+// attempts made to fetch each chunk before the download fails
+const maxAttempts = 3
+
+// ErrDigestMismatch is returned when the content does not have the expected digest
+var ErrDigestMismatch = errors.New("downloaded content does not match the expected SHA256 digest")
+
+// ErrTooLarge is returned when the content is larger than Options.MaxSize
+var ErrTooLarge = errors.New("downloaded content is larger than the maximum size")
+
+// Options configures a download
+type Options struct {
+	// Connections is the number of ranges fetched in parallel; values below one mean a single connection
+	Connections int
+	// ChunkSize is the size of each range; zero means DefaultChunkSize
+	ChunkSize int64
+	// Digest is the expected SHA256 digest of the content, if known
+	Digest []byte
+	// Client is used for every request; nil means http.DefaultClient
+	Client *http.Client
+	// MaxSize is the largest content accepted; zero means no limit
+	MaxSize int64
+	// MemoryThreshold is the largest content Fetch holds in memory rather than writing it to a file
+	MemoryThreshold int64
+}
+
+// state records which chunks of a download have been written, so that it can be resumed
+type state struct {
+	URL       string `json:"url"`
+	Size      int64  `json:"size"`
+	Validator string `json:"validator"`
+	ChunkSize int64  `json:"chunkSize"`
+	Done      []bool `json:"done"`
+}
+
+// resource describes the content at a URL as reported in response to a HEAD request
+type resource struct {
+	size   int64
+	ranges bool
+	// strong ETag or Last-Modified date used in If-Range, so that content which changes is not mixed up
+	validator string
+	// SHA256 digest from a Digest header (RFC 3230), if the server sent one
+	digest []byte
+}
+
+type downloader struct {
+	url  string
+	path string
+	opts Options
+}
+
+// File downloads the content at url to path and returns its SHA256 digest.
+//
+// If the server supports range requests the content is fetched in chunks over parallel connections.
+// Progress is kept in path.part and path.part.json, so a download which is interrupted is resumed by
+// the next call for the same url and path, as long as the server identifies the content with an ETag
+// or Last-Modified date which has not changed.
+//
+// If opts.Digest is set the download is aborted as soon as the content is known not to match it: before
+// anything is fetched if the server advertises the digest of the content, and otherwise once the last
+// byte has been received, in which case the partial download is discarded.
+func File(ctx context.Context, url, path string, opts Options) ([]byte, error) {
+	d, res, err := newDownloader(ctx, url, path, opts)
+	if err != nil {
+		return nil, err
+	}
+	return d.toFile(ctx, res)
+}
+
+// Content is downloaded content, held either in memory or in a file
+type Content struct {
+	// Digest is the SHA256 digest of the content
+	Digest []byte
+	// Size is the length of the content
+	Size int64
+	data []byte
+	path string
+}
+
+// Open returns a reader for the content
+func (c *Content) Open() (io.ReadCloser, error) {
+	if c.path == "" {
+		return ioutil.NopCloser(bytes.NewReader(c.data)), nil
+	}
+	return os.Open(c.path)
+}
+
+// Bytes returns the content, reading it from its file if it is not held in memory
+func (c *Content) Bytes() ([]byte, error) {
+	if c.path == "" {
+		return c.data, nil
+	}
+	return ioutil.ReadFile(c.path)
+}
+
+// Path returns the file holding the content, or "" if it is held in memory
+func (c *Content) Path() string {
+	return c.path
+}
+
+// Remove removes the file holding the content, if there is one
+func (c *Content) Remove() error {
+	if c.path == "" {
+		return nil
+	}
+	if err := os.Remove(c.path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// Fetch downloads the content at url like File, except that content of at most opts.MemoryThreshold
+// bytes is held in memory and nothing is written to path. Content whose size the server does not
+// report is read into memory until it exceeds the threshold, and is then spooled to path instead.
+func Fetch(ctx context.Context, url, path string, opts Options) (*Content, error) {
+	d, res, err := newDownloader(ctx, url, path, opts)
+	if err != nil {
+		return nil, err
+	}
+	if res.size > d.opts.MemoryThreshold {
+		digest, err := d.toFile(ctx, res)
+		if err != nil {
+			return nil, err
+		}
+		return d.fileContent(digest)
+	}
+	return d.memory(ctx)
+}
+
+// fileContent describes the content downloaded into path
+func (d *downloader) fileContent(digest []byte) (*Content, error) {
+	fi, err := os.Stat(d.path)
+	if err != nil {
+		return nil, err
+	}
+	return &Content{Digest: digest, Size: fi.Size(), path: d.path}, nil
+}
+
+// newDownloader applies the defaults to opts and checks what the server reports about the content
+// against them
+func newDownloader(ctx context.Context, url, path string, opts Options) (*downloader, *resource, error) {
+	if opts.Connections < 1 {
+		opts.Connections = 1
+	}
+	if opts.ChunkSize <= 0 {
+		opts.ChunkSize = DefaultChunkSize
+	}
+	if opts.Client == nil {
+		opts.Client = http.DefaultClient
+	}
+	d := &downloader{url: url, path: path, opts: opts}
+
+	res, err := d.probe(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	if res.digest != nil && opts.Digest != nil && !bytes.Equal(res.digest, opts.Digest) {
+		return nil, nil, fmt.Errorf("%w: server reports %x, expected %x", ErrDigestMismatch, res.digest, opts.Digest)
+	}
+	if opts.MaxSize > 0 && res.size > opts.MaxSize {
+		return nil, nil, fmt.Errorf("%w: %v is %d bytes, the limit is %d", ErrTooLarge, url, res.size, opts.MaxSize)
+	}
+	return d, res, nil
+}
+
+// toFile downloads the content into path
+func (d *downloader) toFile(ctx context.Context, res *resource) ([]byte, error) {
+	if !res.ranges || res.size <= 0 || d.opts.Connections == 1 && res.size <= d.opts.ChunkSize {
+		return d.whole(ctx)
+	}
+	return d.chunked(ctx, res)
+}
+
+func (d *downloader) partPath() string {
+	return d.path + ".part"
+}
+
+func (d *downloader) statePath() string {
+	return d.path + ".part.json"
+}
+
+func (d *downloader) probe(ctx context.Context) (*resource, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, d.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := d.opts.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	_ = resp.Body.Close()
+	// some servers do not implement HEAD; the content is then fetched with a single request
+	if resp.StatusCode != http.StatusOK {
+		return &resource{}, nil
+	}
+
+	res := &resource{
+		size:   resp.ContentLength,
+		ranges: resp.Header.Get("Accept-Ranges") == "bytes" && resp.Header.Get("Content-Encoding") == "",
+		digest: digestFromHeader(resp.Header.Get("Digest")),
+	}
+	if etag := resp.Header.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+		res.validator = etag
+	} else {
+		res.validator = resp.Header.Get("Last-Modified")
+	}
+	return res, nil
+}
+
+// digestFromHeader returns the SHA256 digest listed in the value of a Digest header
+func digestFromHeader(v string) []byte {
+	for _, part := range strings.Split(v, ",") {
+		kv := strings.SplitN(strings.TrimSpace(part), "=", 2)
+		if len(kv) != 2 || !strings.EqualFold(kv[0], "sha-256") {
+			continue
+		}
+		if b, err := base64.StdEncoding.DecodeString(kv[1]); err == nil && len(b) == sha256.Size {
+			return b
+		}
+	}
+	return nil
+}
+
+// get requests the whole content
+func (d *downloader) get(ctx context.Context) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, d.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := d.opts.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		_ = resp.Body.Close()
+		return nil, fmt.Errorf("error received while fetching %v: %v", d.url, resp.Status)
+	}
+	return resp, nil
+}
+
+// whole fetches the content with a single request, hashing it as it is written
+func (d *downloader) whole(ctx context.Context) ([]byte, error) {
+	_ = os.Remove(d.statePath())
+
+	resp, err := d.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	return d.writeWhole(resp.Body)
+}
+
+// writeWhole writes the content read from r to the part file and moves it into place
+func (d *downloader) writeWhole(r io.Reader) ([]byte, error) {
+	f, err := os.OpenFile(d.partPath(), os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return nil, err
+	}
+	h := sha256.New()
+	if _, err := d.copyLimited(io.MultiWriter(f, h), r); err != nil {
+		_ = f.Close()
+		_ = os.Remove(d.partPath())
+		return nil, err
+	}
+	if err := f.Close(); err != nil {
+		return nil, err
+	}
+	return d.finish(h.Sum(nil))
+}
+
+// copyLimited copies the content from r to w, failing once it exceeds opts.MaxSize
+func (d *downloader) copyLimited(w io.Writer, r io.Reader) (int64, error) {
+	if d.opts.MaxSize > 0 {
+		r = io.LimitReader(r, d.opts.MaxSize+1)
+	}
+	n, err := io.Copy(w, r)
+	if err != nil {
+		return n, fmt.Errorf("error fetching %v: %w", d.url, err)
+	}
+	if d.opts.MaxSize > 0 && n > d.opts.MaxSize {
+		return n, fmt.Errorf("%w: %v is larger than %d bytes", ErrTooLarge, d.url, d.opts.MaxSize)
+	}
+	return n, nil
+}
+
+// memory fetches the content with a single request into memory, spooling it to the part file once
+// it exceeds opts.MemoryThreshold
+func (d *downloader) memory(ctx context.Context) (*Content, error) {
+	resp, err := d.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var buf bytes.Buffer
+	n, err := io.Copy(&buf, io.LimitReader(resp.Body, d.opts.MemoryThreshold+1))
+	if err != nil {
+		return nil, fmt.Errorf("error fetching %v: %w", d.url, err)
+	}
+	if n > d.opts.MemoryThreshold {
+		_ = os.Remove(d.statePath())
+		digest, err := d.writeWhole(io.MultiReader(&buf, resp.Body))
+		if err != nil {
+			return nil, err
+		}
+		return d.fileContent(digest)
+	}
+
+	if d.opts.MaxSize > 0 && n > d.opts.MaxSize {
+		return nil, fmt.Errorf("%w: %v is larger than %d bytes", ErrTooLarge, d.url, d.opts.MaxSize)
+	}
+	sum := sha256.Sum256(buf.Bytes())
+	if d.opts.Digest != nil && !bytes.Equal(sum[:], d.opts.Digest) {
+		return nil, fmt.Errorf("%w: downloaded %x, expected %x", ErrDigestMismatch, sum, d.opts.Digest)
+	}
+	return &Content{Digest: sum[:], Size: n, data: buf.Bytes()}, nil
+}
+
+func (d *downloader) chunked(ctx context.Context, res *resource) ([]byte, error) {
+	chunks := int((res.size + d.opts.ChunkSize - 1) / d.opts.ChunkSize)
+	st := d.loadState()
+	// without a validator a resumed download could mix two versions of the content
+	if st == nil || st.URL != d.url || st.Size != res.size || st.Validator == "" || st.Validator != res.validator ||
+		st.ChunkSize != d.opts.ChunkSize || len(st.Done) != chunks {
+		st = &state{
+			URL:       d.url,
+			Size:      res.size,
+			Validator: res.validator,
+			ChunkSize: d.opts.ChunkSize,
+			Done:      make([]bool, chunks),
+		}
+		if err := os.Remove(d.partPath()); err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+	}
+
+	f, err := os.OpenFile(d.partPath(), os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, err
+	}
+	if err := f.Truncate(res.size); err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+
+	var mu sync.Mutex
+	indexes := make(chan int)
+	g, gctx := errgroup.WithContext(ctx)
+	g.Go(func() error {
+		defer close(indexes)
+		for i, done := range st.Done {
+			if done {
+				continue
+			}
+			select {
+			case indexes <- i:
+			case <-gctx.Done():
+				return gctx.Err()
+			}
+		}
+		return nil
+	})
+	for c := 0; c < d.opts.Connections; c++ {
+		g.Go(func() error {
+			for i := range indexes {
+				if err := d.fetchChunk(gctx, f, st, i); err != nil {
+					return err
+				}
+				mu.Lock()
+				st.Done[i] = true
+				err := d.saveState(st)
+				mu.Unlock()
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	if err := f.Close(); err != nil {
+		return nil, err
+	}
+	return d.finish(h.Sum(nil))
+}
+
+// fetchChunk writes chunk i of the content to f, retrying transient failures
+func (d *downloader) fetchChunk(ctx context.Context, f *os.File, st *state, i int) error {
+	start := int64(i) * st.ChunkSize
+	end := start + st.ChunkSize - 1
+	if end >= st.Size {
+		end = st.Size - 1
+	}
+
+	var err error
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		var retry bool
+		if retry, err = d.fetchRange(ctx, f, st, start, end); err == nil || !retry {
+			return err
+		}
+		select {
+		case <-time.After(time.Duration(attempt) * 500 * time.Millisecond):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return err
+}
+
+// fetchRange writes the bytes from start to end (inclusive) of the content to f, reporting whether
+// a failure may be retried
+func (d *downloader) fetchRange(ctx context.Context, f *os.File, st *state, start, end int64) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, d.url, nil)
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", start, end))
+	if st.Validator != "" {
+		req.Header.Set("If-Range", st.Validator)
+	}
+	resp, err := d.opts.Client.Do(req)
+	if err != nil {
+		return ctx.Err() == nil, fmt.Errorf("error fetching %v: %w", d.url, err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusPartialContent:
+	case resp.StatusCode == http.StatusOK:
+		// the server ignored the range because If-Range did not match
+		_ = os.Remove(d.statePath())
+		return false, fmt.Errorf("content at %v changed during the download", d.url)
+	case resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests:
+		return true, fmt.Errorf("error received while fetching %v: %v", d.url, resp.Status)
+	default:
+		return false, fmt.Errorf("error received while fetching %v: %v", d.url, resp.Status)
+	}
+	if want := fmt.Sprintf("bytes %d-%d/%d", start, end, st.Size); resp.Header.Get("Content-Range") != want {
+		return false, fmt.Errorf("unexpected range %q received from %v, requested %q", resp.Header.Get("Content-Range"), d.url, want)
+	}
+
+	// a failed attempt leaves partial data behind, which is overwritten when the range is fetched again
+	if _, err := io.CopyN(&offsetWriter{f: f, off: start}, resp.Body, end-start+1); err != nil {
+		return ctx.Err() == nil, fmt.Errorf("error fetching %v: %w", d.url, err)
+	}
+	return false, nil
+}
+
+// finish moves a complete download into place once its digest has been checked
+func (d *downloader) finish(digest []byte) ([]byte, error) {
+	_ = os.Remove(d.statePath())
+	if d.opts.Digest != nil && !bytes.Equal(digest, d.opts.Digest) {
+		_ = os.Remove(d.partPath())
+		return nil, fmt.Errorf("%w: downloaded %x, expected %x", ErrDigestMismatch, digest, d.opts.Digest)
+	}
+	if err := os.Rename(d.partPath(), d.path); err != nil {
+		return nil, err
+	}
+	return digest, nil
+}
+
+func (d *downloader) loadState() *state {
+	b, err := ioutil.ReadFile(d.statePath())
+	if err != nil {
+		return nil
+	}
+	var st state
+	if err := json.Unmarshal(b, &st); err != nil {
+		return nil
+	}
+	return &st
+}
+
+// saveState records the progress of a download which can be resumed
+func (d *downloader) saveState(st *state) error {
+	if st.Validator == "" {
+		return nil
+	}
+	b, err := json.Marshal(st)
+	if err != nil {
+		return err
+	}
+	tmp := d.statePath() + ".tmp"
+	if err := ioutil.WriteFile(tmp, b, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, d.statePath())
+}
+
+// offsetWriter writes sequentially to f starting at off
+type offsetWriter struct {
+	f   *os.File
+	off int64
+}
+
+func (w *offsetWriter) Write(p []byte) (int, error) {
+	n, err := w.f.WriteAt(p, w.off)
+	w.off += int64(n)
+	return n, err
+}
@@ -0,0 +1,343 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package download
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+type testServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	ranges   []string
+	requests int
+}
+
+// newTestServer serves content with support for range requests; if ranges is false the
+// server neither advertises nor honors them
+func newTestServer(t *testing.T, content []byte, ranges bool, header http.Header) *testServer {
+	t.Helper()
+	s := &testServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		if r.Method == http.MethodGet {
+			s.requests++
+			if rng := r.Header.Get("Range"); rng != "" {
+				s.ranges = append(s.ranges, rng)
+			}
+		}
+		s.mu.Unlock()
+		for k, v := range header {
+			w.Header()[k] = v
+		}
+		if !ranges {
+			if r.Method == http.MethodGet {
+				_, _ = w.Write(content)
+			}
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(content))
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func randomContent(t *testing.T, n int) []byte {
+	t.Helper()
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func checkDownload(t *testing.T, path string, digest, content []byte) {
+	t.Helper()
+	sum := sha256.Sum256(content)
+	if !bytes.Equal(digest, sum[:]) {
+		t.Errorf("digest = %x, want %x", digest, sum)
+	}
+	got, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, content) {
+		t.Errorf("downloaded %d bytes which differ from the content", len(got))
+	}
+	for _, p := range []string{path + ".part", path + ".part.json"} {
+		if _, err := os.Stat(p); !os.IsNotExist(err) {
+			t.Errorf("%s was not removed", p)
+		}
+	}
+}
+
+func TestFile(t *testing.T) {
+	content := randomContent(t, 10000)
+	sum := sha256.Sum256(content)
+
+	tests := []struct {
+		name       string
+		ranges     bool
+		opts       Options
+		wantRanges int
+	}{
+		{
+			name:       "chunked",
+			ranges:     true,
+			opts:       Options{Connections: 4, ChunkSize: 1024, Digest: sum[:]},
+			wantRanges: 10,
+		},
+		{
+			name:       "single chunk",
+			ranges:     true,
+			opts:       Options{Connections: 1, ChunkSize: 1 << 20},
+			wantRanges: 0,
+		},
+		{
+			name:       "ranges not supported",
+			ranges:     false,
+			opts:       Options{Connections: 4, ChunkSize: 1024, Digest: sum[:]},
+			wantRanges: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestServer(t, content, tt.ranges, nil)
+			path := filepath.Join(t.TempDir(), "artifact")
+			digest, err := File(context.Background(), s.URL, path, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkDownload(t, path, digest, content)
+			if len(s.ranges) != tt.wantRanges {
+				t.Errorf("%d ranges requested, want %d", len(s.ranges), tt.wantRanges)
+			}
+		})
+	}
+}
+
+func TestFileResume(t *testing.T) {
+	content := randomContent(t, 4096)
+	s := newTestServer(t, content, true, nil)
+	path := filepath.Join(t.TempDir(), "artifact")
+
+	// the first two chunks were written by an earlier, interrupted download
+	part := make([]byte, len(content))
+	copy(part, content[:2048])
+	if err := ioutil.WriteFile(path+".part", part, 0600); err != nil {
+		t.Fatal(err)
+	}
+	st, _ := json.Marshal(state{
+		URL:       s.URL,
+		Size:      int64(len(content)),
+		Validator: `"v1"`,
+		ChunkSize: 1024,
+		Done:      []bool{true, true, false, false},
+	})
+	if err := ioutil.WriteFile(path+".part.json", st, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	digest, err := File(context.Background(), s.URL, path, Options{Connections: 2, ChunkSize: 1024})
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkDownload(t, path, digest, content)
+	if len(s.ranges) != 2 {
+		t.Errorf("requested ranges %v, want only the two missing chunks", s.ranges)
+	}
+}
+
+func TestFileResumeChangedContent(t *testing.T) {
+	content := randomContent(t, 4096)
+	s := newTestServer(t, content, true, nil)
+	path := filepath.Join(t.TempDir(), "artifact")
+
+	// progress recorded for another version of the content is discarded
+	if err := ioutil.WriteFile(path+".part", make([]byte, len(content)), 0600); err != nil {
+		t.Fatal(err)
+	}
+	st, _ := json.Marshal(state{
+		URL:       s.URL,
+		Size:      int64(len(content)),
+		Validator: `"v0"`,
+		ChunkSize: 1024,
+		Done:      []bool{true, true, false, false},
+	})
+	if err := ioutil.WriteFile(path+".part.json", st, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	digest, err := File(context.Background(), s.URL, path, Options{Connections: 2, ChunkSize: 1024})
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkDownload(t, path, digest, content)
+	if len(s.ranges) != 4 {
+		t.Errorf("requested ranges %v, want all four chunks", s.ranges)
+	}
+}
+
+func TestFileDigestMismatch(t *testing.T) {
+	content := randomContent(t, 4096)
+	sum := sha256.Sum256(content)
+	other := sha256.Sum256([]byte("other"))
+
+	t.Run("advertised by server", func(t *testing.T) {
+		header := http.Header{"Digest": {"sha-256=" + base64.StdEncoding.EncodeToString(sum[:])}}
+		s := newTestServer(t, content, true, header)
+		path := filepath.Join(t.TempDir(), "artifact")
+		if _, err := File(context.Background(), s.URL, path, Options{Connections: 2, ChunkSize: 1024, Digest: other[:]}); !errors.Is(err, ErrDigestMismatch) {
+			t.Fatalf("File() error = %v, want %v", err, ErrDigestMismatch)
+		}
+		if s.requests != 0 {
+			t.Errorf("content was fetched %d times despite the digest advertised by the server", s.requests)
+		}
+	})
+
+	t.Run("computed", func(t *testing.T) {
+		s := newTestServer(t, content, true, nil)
+		path := filepath.Join(t.TempDir(), "artifact")
+		if _, err := File(context.Background(), s.URL, path, Options{Connections: 2, ChunkSize: 1024, Digest: other[:]}); !errors.Is(err, ErrDigestMismatch) {
+			t.Fatalf("File() error = %v, want %v", err, ErrDigestMismatch)
+		}
+		for _, p := range []string{path, path + ".part", path + ".part.json"} {
+			if _, err := os.Stat(p); !os.IsNotExist(err) {
+				t.Errorf("%s was not removed", p)
+			}
+		}
+	})
+}
+
+func TestFileTooLarge(t *testing.T) {
+	content := randomContent(t, 4096)
+	for _, ranges := range []bool{true, false} {
+		s := newTestServer(t, content, ranges, nil)
+		path := filepath.Join(t.TempDir(), "artifact")
+		if _, err := File(context.Background(), s.URL, path, Options{Connections: 2, ChunkSize: 1024, MaxSize: 4095}); !errors.Is(err, ErrTooLarge) {
+			t.Fatalf("File() error = %v, want %v", err, ErrTooLarge)
+		}
+		// the size is checked before anything is fetched if the server reports it
+		if ranges && s.requests != 0 {
+			t.Errorf("content was fetched %d times despite its size reported by the server", s.requests)
+		}
+		for _, p := range []string{path, path + ".part", path + ".part.json"} {
+			if _, err := os.Stat(p); !os.IsNotExist(err) {
+				t.Errorf("%s was not removed", p)
+			}
+		}
+	}
+}
+
+func TestFetch(t *testing.T) {
+	content := randomContent(t, 4096)
+	sum := sha256.Sum256(content)
+
+	tests := []struct {
+		name      string
+		ranges    bool
+		threshold int64
+		inMemory  bool
+	}{
+		{name: "in memory", ranges: true, threshold: 4096, inMemory: true},
+		{name: "larger than the threshold", ranges: true, threshold: 1024},
+		{name: "size not reported, in memory", ranges: false, threshold: 4096, inMemory: true},
+		{name: "size not reported, spooled", ranges: false, threshold: 1024},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestServer(t, content, tt.ranges, nil)
+			path := filepath.Join(t.TempDir(), "artifact")
+			c, err := Fetch(context.Background(), s.URL, path, Options{Connections: 2, ChunkSize: 1024, Digest: sum[:], MemoryThreshold: tt.threshold})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if (c.Path() == "") != tt.inMemory {
+				t.Fatalf("content held in memory: %v, want %v", c.Path() == "", tt.inMemory)
+			}
+			if !tt.inMemory {
+				checkDownload(t, path, c.Digest, content)
+			} else if _, err := os.Stat(path); !os.IsNotExist(err) {
+				t.Errorf("content held in memory was written to %s", path)
+			}
+			got, err := c.Bytes()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, content) || c.Size != int64(len(content)) || !bytes.Equal(c.Digest, sum[:]) {
+				t.Errorf("fetched %d bytes which differ from the content", c.Size)
+			}
+			if err := c.Remove(); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := os.Stat(path); !os.IsNotExist(err) {
+				t.Errorf("%s was not removed", path)
+			}
+		})
+	}
+
+	other := sha256.Sum256([]byte("other"))
+	s := newTestServer(t, content, false, nil)
+	if _, err := Fetch(context.Background(), s.URL, filepath.Join(t.TempDir(), "artifact"), Options{Digest: other[:], MemoryThreshold: 1 << 20}); !errors.Is(err, ErrDigestMismatch) {
+		t.Errorf("Fetch() error = %v, want %v", err, ErrDigestMismatch)
+	}
+	if _, err := Fetch(context.Background(), s.URL, filepath.Join(t.TempDir(), "artifact"), Options{MaxSize: 1024, MemoryThreshold: 1 << 20}); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("Fetch() error = %v, want %v", err, ErrTooLarge)
+	}
+}
+
+func TestFileNotFound(t *testing.T) {
+	s := httptest.NewServer(http.NotFoundHandler())
+	defer s.Close()
+	if _, err := File(context.Background(), s.URL, filepath.Join(t.TempDir(), "artifact"), Options{}); err == nil {
+		t.Fatal("expected an error")
+	}
+}
+
+func TestDigestFromHeader(t *testing.T) {
+	sum := sha256.Sum256([]byte("content"))
+	b64 := base64.StdEncoding.EncodeToString(sum[:])
+	tests := []struct {
+		header string
+		want   []byte
+	}{
+		{"", nil},
+		{"sha-256=" + b64, sum[:]},
+		{"md5=HUXZLQLMuI/KZ5KDcJPcOA==, SHA-256=" + b64, sum[:]},
+		{"sha-256=invalid", nil},
+		{"sha-512=" + b64, nil},
+	}
+	for _, tt := range tests {
+		if got := digestFromHeader(tt.header); !bytes.Equal(got, tt.want) {
+			t.Errorf("digestFromHeader(%q) = %x, want %x", tt.header, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,478 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package app
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-openapi/runtime"
+	"github.com/go-openapi/swag"
+	"github.com/sigstore/sigstore/pkg/signature/options"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/sigstore/rekor/cmd/rekor-cli/app/format"
+	"github.com/sigstore/rekor/pkg/bundle"
+	"github.com/sigstore/rekor/pkg/client"
+	genclient "github.com/sigstore/rekor/pkg/generated/client"
+	"github.com/sigstore/rekor/pkg/generated/client/entries"
+	"github.com/sigstore/rekor/pkg/generated/models"
+	"github.com/sigstore/rekor/pkg/log"
+	"github.com/sigstore/rekor/pkg/pki"
+	"github.com/sigstore/rekor/pkg/types"
+	"github.com/sigstore/rekor/pkg/util"
+	"github.com/sigstore/rekor/pkg/verify"
+)
+
+type decodedSigner struct {
+	// Fingerprint is the hex encoded SHA256 digest of the canonical encoding of the key
+	Fingerprint    string
+	Subjects       []string `json:",omitempty"`
+	EmailAddresses []string `json:",omitempty"`
+}
+
+type entryDecodeOutput struct {
+	UUID           string
+	Kind           string
+	APIVersion     string
+	LogIndex       *int64 `json:",omitempty"`
+	IntegratedTime *int64 `json:",omitempty"`
+	// Hashes maps the path of each hash recorded in the body to its algorithm and value
+	Hashes      map[string]string  `json:",omitempty"`
+	Signers     []decodedSigner    `json:",omitempty"`
+	Annotations models.Annotations `json:",omitempty"`
+	// SignatureVerified is set when the signature in the body was verified with --verify
+	SignatureVerified bool `json:",omitempty"`
+	Body              interface{}
+}
+
+func (e *entryDecodeOutput) String() string {
+	s := fmt.Sprintf("UUID: %v\n", e.UUID)
+	s += fmt.Sprintf("Kind: %v %v\n", e.Kind, e.APIVersion)
+	if e.LogIndex != nil {
+		s += fmt.Sprintf("Index: %d\n", *e.LogIndex)
+	}
+	if e.IntegratedTime != nil {
+		s += fmt.Sprintf("IntegratedTime: %s\n", time.Unix(*e.IntegratedTime, 0).UTC().Format(time.RFC3339))
+	}
+	paths := make([]string, 0, len(e.Hashes))
+	for p := range e.Hashes {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	for _, p := range paths {
+		s += fmt.Sprintf("Hash: %s %s\n", p, e.Hashes[p])
+	}
+	for _, signer := range e.Signers {
+		s += fmt.Sprintf("Signer: %s", signer.Fingerprint)
+		if ids := append(append([]string{}, signer.Subjects...), signer.EmailAddresses...); len(ids) > 0 {
+			s += fmt.Sprintf(" (%s)", strings.Join(ids, ", "))
+		}
+		s += "\n"
+	}
+	for _, k := range types.AnnotationKeys(e.Annotations) {
+		s += fmt.Sprintf("Annotation: %s=%s\n", k, e.Annotations[k])
+	}
+	if e.SignatureVerified {
+		s += "Signature: verified locally\n"
+	}
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(e.Body)
+	s += fmt.Sprintf("Body: %s", b.Bytes())
+	return s
+}
+
+// readLeaf reads a leaf given as the canonicalized body of an entry, either as JSON or base64 encoded
+// as in the responses of the log; "-" reads it from stdin
+func readLeaf(path string) ([]byte, error) {
+	var raw []byte
+	var err error
+	if path == "-" {
+		raw, err = ioutil.ReadAll(os.Stdin)
+	} else {
+		raw, err = ioutil.ReadFile(filepath.Clean(path))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading leaf: %w", err)
+	}
+	raw = bytes.TrimSpace(raw)
+	if bytes.HasPrefix(raw, []byte("{")) {
+		return raw, nil
+	}
+	body, err := base64.StdEncoding.DecodeString(string(raw))
+	if err != nil {
+		return nil, errors.New("leaf is neither JSON nor base64 encoded JSON")
+	}
+	return body, nil
+}
+
+// recordedHashes returns the hashes found in the spec of a body, keyed by their path in the spec
+func recordedHashes(spec interface{}) map[string]string {
+	hashes := map[string]string{}
+	var walk func(path string, v interface{})
+	walk = func(path string, v interface{}) {
+		switch v := v.(type) {
+		case map[string]interface{}:
+			alg, algOK := v["algorithm"].(string)
+			value, valueOK := v["value"].(string)
+			if algOK && valueOK {
+				hashes[path] = alg + ":" + value
+				return
+			}
+			for k, child := range v {
+				walk(strings.TrimPrefix(path+"."+k, "."), child)
+			}
+		case []interface{}:
+			for i, child := range v {
+				walk(fmt.Sprintf("%s[%d]", path, i), child)
+			}
+		}
+	}
+	walk("", spec)
+	return hashes
+}
+
+// decodeLeaf decodes the canonicalized body of an entry
+func decodeLeaf(body []byte) (*entryDecodeOutput, models.ProposedEntry, error) {
+	pe, err := models.UnmarshalProposedEntry(bytes.NewReader(body), runtime.JSONConsumer())
+	if err != nil {
+		return nil, nil, fmt.Errorf("error parsing entry body: %w", err)
+	}
+	eimpl, err := types.NewEntry(pe)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error parsing entry body: %w", err)
+	}
+	var raw struct {
+		Spec interface{} `json:"spec"`
+	}
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return nil, nil, err
+	}
+
+	o := &entryDecodeOutput{
+		UUID:        util.EntryUUID(body),
+		Kind:        pe.Kind(),
+		APIVersion:  eimpl.APIVersion(),
+		Hashes:      recordedHashes(raw.Spec),
+		Annotations: pe.Annotations(),
+		Body:        raw.Spec,
+	}
+	if sp, ok := eimpl.(types.SignerProvider); ok {
+		signers, err := sp.Signers()
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, signer := range signers {
+			canonical, err := signer.CanonicalValue()
+			if err != nil {
+				return nil, nil, err
+			}
+			fingerprint := sha256.Sum256(canonical)
+			o.Signers = append(o.Signers, decodedSigner{
+				Fingerprint:    hex.EncodeToString(fingerprint[:]),
+				Subjects:       signer.Subjects(),
+				EmailAddresses: signer.EmailAddresses(),
+			})
+		}
+	}
+	return o, pe, nil
+}
+
+// embeddedSignature is a signature carried in the body of an entry with the key to verify it
+type embeddedSignature struct {
+	format    pki.Format
+	signature []byte
+	publicKey []byte
+}
+
+// signedContent describes what the signatures in the body of an entry cover: the artifact with the
+// recorded digest or, for hashedrekord entries, the digest itself
+type signedContent struct {
+	signatures []embeddedSignature
+	algorithm  string
+	digest     string
+	overDigest bool
+}
+
+// embeddedSignatures returns the signatures of the entry types whose signatures can be checked
+// without fetching anything besides the artifact
+func embeddedSignatures(pe models.ProposedEntry) (*signedContent, error) {
+	switch e := pe.(type) {
+	case *models.Hashedrekord:
+		var spec models.HashedrekordV001Schema
+		if err := types.DecodeEntry(e.Spec, &spec); err != nil {
+			return nil, err
+		}
+		if spec.Data == nil || spec.Data.Hash == nil || spec.Signature == nil || spec.Signature.PublicKey == nil {
+			return nil, errors.New("entry is missing its digest or signature")
+		}
+		return &signedContent{
+			signatures: []embeddedSignature{{format: pki.X509, signature: spec.Signature.Content, publicKey: spec.Signature.PublicKey.Content}},
+			algorithm:  swag.StringValue(spec.Data.Hash.Algorithm),
+			digest:     swag.StringValue(spec.Data.Hash.Value),
+			overDigest: true,
+		}, nil
+	case *models.Rekord:
+		switch swag.StringValue(e.APIVersion) {
+		case "0.0.1":
+			var spec models.RekordV001Schema
+			if err := types.DecodeEntry(e.Spec, &spec); err != nil {
+				return nil, err
+			}
+			if spec.Data == nil || spec.Data.Hash == nil || spec.Signature == nil || spec.Signature.PublicKey == nil {
+				return nil, errors.New("entry is missing its digest or signature")
+			}
+			return &signedContent{
+				signatures: []embeddedSignature{{format: pki.Format(spec.Signature.Format), signature: spec.Signature.Content, publicKey: spec.Signature.PublicKey.Content}},
+				algorithm:  swag.StringValue(spec.Data.Hash.Algorithm),
+				digest:     swag.StringValue(spec.Data.Hash.Value),
+			}, nil
+		case "0.0.2":
+			var spec models.RekordV002Schema
+			if err := types.DecodeEntry(e.Spec, &spec); err != nil {
+				return nil, err
+			}
+			if spec.Data == nil || spec.Data.Hash == nil || len(spec.Signatures) == 0 {
+				return nil, errors.New("entry is missing its digest or signatures")
+			}
+			c := &signedContent{
+				algorithm: swag.StringValue(spec.Data.Hash.Algorithm),
+				digest:    swag.StringValue(spec.Data.Hash.Value),
+			}
+			for _, s := range spec.Signatures {
+				if s == nil || s.PublicKey == nil {
+					return nil, errors.New("entry is missing the key of a signature")
+				}
+				c.signatures = append(c.signatures, embeddedSignature{format: pki.Format(s.Format), signature: s.Content, publicKey: s.PublicKey.Content})
+			}
+			return c, nil
+		}
+	}
+	return nil, fmt.Errorf("verifying the signature of %s entries locally is not supported", pe.Kind())
+}
+
+// verifyEmbeddedSignatures verifies the signatures in the body of an entry, over the artifact read
+// from artifact if it is not nil. The artifact is required unless the signatures are over its digest.
+func verifyEmbeddedSignatures(pe models.ProposedEntry, artifact io.Reader) error {
+	c, err := embeddedSignatures(pe)
+	if err != nil {
+		return err
+	}
+	hashFunc, err := util.HashFunc(c.algorithm)
+	if err != nil {
+		return err
+	}
+	digest, err := hex.DecodeString(c.digest)
+	if err != nil {
+		return fmt.Errorf("invalid digest in entry: %w", err)
+	}
+
+	var artifactBytes []byte
+	if artifact != nil {
+		if artifactBytes, err = ioutil.ReadAll(artifact); err != nil {
+			return fmt.Errorf("error reading artifact: %w", err)
+		}
+		h := hashFunc.New()
+		_, _ = h.Write(artifactBytes)
+		if !bytes.Equal(h.Sum(nil), digest) {
+			return errors.New("artifact does not match the digest recorded in the entry")
+		}
+	} else if !c.overDigest {
+		return fmt.Errorf("the artifact is needed to verify the signature of a %s entry", pe.Kind())
+	}
+
+	for i, s := range c.signatures {
+		af, err := pki.NewArtifactFactory(s.format)
+		if err != nil {
+			return err
+		}
+		sig, err := af.NewSignature(bytes.NewReader(s.signature))
+		if err != nil {
+			return fmt.Errorf("signature %d: %w", i, err)
+		}
+		key, err := af.NewPublicKey(bytes.NewReader(s.publicKey))
+		if err != nil {
+			return fmt.Errorf("public key %d: %w", i, err)
+		}
+		if c.overDigest {
+			err = sig.Verify(nil, key, options.WithDigest(digest), options.WithCryptoSignerOpts(hashFunc))
+		} else {
+			err = sig.Verify(bytes.NewReader(artifactBytes), key)
+		}
+		if err != nil {
+			return fmt.Errorf("signature %d does not verify: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// fetchLeaf returns the body of the entry with the given UUID, after verifying that it was added to the log
+func fetchLeaf(ctx context.Context, rekorClient *genclient.Rekor, uuid string) ([]byte, models.LogEntryAnon, error) {
+	c := responseCache()
+	e, ok := cachedEntryByUUID(c, uuid)
+	if !ok {
+		params := entries.NewGetLogEntryByUUIDParamsWithContext(ctx)
+		params.SetTimeout(viper.GetDuration("timeout"))
+		params.EntryUUID = uuid
+		resp, err := rekorClient.Entries.GetLogEntryByUUID(params)
+		if err != nil {
+			return nil, e, err
+		}
+		for k, entry := range resp.Payload {
+			if strings.EqualFold(k, uuid) {
+				e, ok = entry, true
+			}
+		}
+		if !ok {
+			return nil, e, fmt.Errorf("entry %v was not returned by the server", uuid)
+		}
+	}
+	if verified, err := verifyLogEntry(ctx, rekorClient, e); err != nil || !verified {
+		return nil, e, fmt.Errorf("unable to verify entry was added to log %w", err)
+	}
+	body, err := verify.Body(uuid, e)
+	if err != nil {
+		return nil, e, err
+	}
+	cacheEntry(c, uuid, e)
+	if err := updateTreeStateFromEntry(ctx, rekorClient, viper.GetString("rekor_server"), e); err != nil {
+		return nil, e, err
+	}
+	return body, e, nil
+}
+
+// entryCmd groups the commands which operate on single entries
+var entryCmd = &cobra.Command{
+	Use:   "entry",
+	Short: "Inspect entries of the transparency log",
+}
+
+// entryDecodeCmd represents the entry decode command
+var entryDecodeCmd = &cobra.Command{
+	Use:   "decode",
+	Short: "Decode and print the body of an entry",
+	Long: `Decodes the body of an entry given as a raw leaf with --leaf, in a Sigstore bundle with --bundle or
+by its --uuid in the log, and prints its type, the hashes it records, its signers and annotations.
+
+With --verify the signature in the body is verified locally against the key it carries; this needs the
+signed artifact as --artifact unless the signature is over the recorded digest, as in hashedrekord entries.`,
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		// these are bound here so that they are not overwritten by other commands
+		if err := viper.BindPFlags(cmd.Flags()); err != nil {
+			return fmt.Errorf("error initializing cmd line args: %s", err)
+		}
+		given := 0
+		for _, flag := range []string{"leaf", "bundle", "uuid"} {
+			if viper.GetString(flag) != "" {
+				given++
+			}
+		}
+		if given != 1 {
+			return errors.New("exactly one of --leaf, --bundle and --uuid must be specified")
+		}
+		if viper.GetString("artifact") != "" && !viper.GetBool("verify") {
+			return errors.New("--artifact is only used with --verify")
+		}
+		return nil
+	},
+	Run: format.WrapCmd(func(args []string) (interface{}, error) {
+		var body []byte
+		var logIndex, integratedTime *int64
+		switch {
+		case viper.GetString("leaf") != "":
+			var err error
+			if body, err = readLeaf(viper.GetString("leaf")); err != nil {
+				return nil, err
+			}
+		case viper.GetString("bundle") != "":
+			raw, err := ioutil.ReadFile(filepath.Clean(viper.GetString("bundle")))
+			if err != nil {
+				return nil, fmt.Errorf("error reading bundle: %w", err)
+			}
+			b, err := bundle.Parse(raw)
+			if err != nil {
+				return nil, err
+			}
+			tle := b.VerificationMaterial.TlogEntries[0]
+			body, logIndex, integratedTime = tle.CanonicalizedBody, &tle.LogIndex, &tle.IntegratedTime
+		default:
+			rekorClient, err := client.GetRekorClient(viper.GetString("rekor_server"))
+			if err != nil {
+				return nil, err
+			}
+			ctx, cancel := commandContext()
+			defer cancel()
+			var e models.LogEntryAnon
+			if body, e, err = fetchLeaf(ctx, rekorClient, viper.GetString("uuid")); err != nil {
+				return nil, err
+			}
+			logIndex, integratedTime = e.LogIndex, e.IntegratedTime
+		}
+
+		o, pe, err := decodeLeaf(body)
+		if err != nil {
+			return nil, err
+		}
+		o.LogIndex, o.IntegratedTime = logIndex, integratedTime
+
+		if viper.GetBool("verify") {
+			var artifact io.Reader
+			if path := viper.GetString("artifact"); path != "" {
+				f, err := os.Open(filepath.Clean(path))
+				if err != nil {
+					return nil, fmt.Errorf("error reading artifact: %w", err)
+				}
+				defer f.Close()
+				artifact = f
+			}
+			if err := verifyEmbeddedSignatures(pe, artifact); err != nil {
+				return nil, err
+			}
+			o.SignatureVerified = true
+		}
+		return o, nil
+	}),
+}
+
+func init() {
+	initializePFlagMap()
+	entryDecodeCmd.Flags().String("leaf", "", "path to a raw leaf of the log, i.e. the canonicalized body of an entry as JSON or base64; - reads it from stdin")
+	entryDecodeCmd.Flags().Var(NewFlagValue(fileFlag, ""), "bundle", "path to a Sigstore bundle whose entry is decoded")
+	if err := addUUIDPFlags(entryDecodeCmd, false); err != nil {
+		log.CliLogger.Fatal("Error parsing cmd line args:", err)
+	}
+	entryDecodeCmd.Flags().Bool("verify", false, "verify the signature in the body of the entry locally")
+	entryDecodeCmd.Flags().Var(NewFlagValue(fileFlag, ""), "artifact", "path to the signed artifact, needed by --verify unless the signature is over the recorded digest")
+
+	entryCmd.AddCommand(entryDecodeCmd)
+	rootCmd.AddCommand(entryCmd)
+}
@@ -0,0 +1,123 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package app
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/sigstore/sigstore/pkg/signature"
+
+	"github.com/sigstore/rekor/pkg/types"
+	"github.com/sigstore/rekor/pkg/util"
+)
+
+func TestRecordedHashes(t *testing.T) {
+	spec := map[string]interface{}{
+		"data": map[string]interface{}{
+			"hash": map[string]interface{}{"algorithm": "sha256", "value": "aa"},
+		},
+		"signatures": []interface{}{
+			map[string]interface{}{"content": "c2ln", "hash": map[string]interface{}{"algorithm": "sha512", "value": "bb"}},
+		},
+		"other": map[string]interface{}{"algorithm": "sha256"},
+	}
+	want := map[string]string{
+		"data.hash":          "sha256:aa",
+		"signatures[0].hash": "sha512:bb",
+	}
+	if got := recordedHashes(spec); !reflect.DeepEqual(got, want) {
+		t.Errorf("recordedHashes() = %v, want %v", got, want)
+	}
+}
+
+func TestDecodeLeaf(t *testing.T) {
+	ctx := context.Background()
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	s, _ := signature.LoadSigner(key, crypto.SHA256)
+
+	artifact := []byte("an artifact")
+	digest := sha256.Sum256(artifact)
+	pe, err := selfEntry(ctx, s, bytes.NewReader(artifact), digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	eimpl, err := types.NewEntry(pe)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := types.CanonicalizeEntry(ctx, eimpl, pe.Annotations())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// leaves are accepted as JSON or base64 encoded like in responses of the log
+	dir := t.TempDir()
+	for name, content := range map[string][]byte{
+		"leaf.json": body,
+		"leaf.b64":  []byte(base64.StdEncoding.EncodeToString(body) + "\n"),
+	} {
+		path := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(path, content, 0600); err != nil {
+			t.Fatal(err)
+		}
+		leaf, err := readLeaf(path)
+		if err != nil {
+			t.Fatalf("readLeaf(%v) = %v", name, err)
+		}
+		if !bytes.Equal(leaf, body) {
+			t.Errorf("readLeaf(%v) did not return the leaf", name)
+		}
+	}
+
+	o, decoded, err := decodeLeaf(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if o.UUID != util.EntryUUID(body) || o.Kind != "hashedrekord" || o.APIVersion != "0.0.1" {
+		t.Errorf("unexpected entry %v %v %v", o.UUID, o.Kind, o.APIVersion)
+	}
+	if got, want := o.Hashes["data.hash"], "sha256:"+hex.EncodeToString(digest[:]); got != want {
+		t.Errorf("data.hash = %v, want %v", got, want)
+	}
+	if len(o.Signers) != 1 || o.Signers[0].Fingerprint == "" {
+		t.Errorf("unexpected signers %+v", o.Signers)
+	}
+	if o.Annotations[selfVersionAnnotation] == "" {
+		t.Errorf("annotations were not decoded: %v", o.Annotations)
+	}
+
+	if err := verifyEmbeddedSignatures(decoded, nil); err != nil {
+		t.Errorf("verifyEmbeddedSignatures() = %v", err)
+	}
+	if err := verifyEmbeddedSignatures(decoded, bytes.NewReader(artifact)); err != nil {
+		t.Errorf("verifyEmbeddedSignatures() with artifact = %v", err)
+	}
+	if err := verifyEmbeddedSignatures(decoded, bytes.NewReader([]byte("another artifact"))); err == nil {
+		t.Error("expected another artifact to be rejected")
+	}
+}
@@ -19,6 +19,7 @@ import (
 	"encoding/json"
 	"fmt"
 
+	"github.com/sigstore/rekor/pkg/client"
 	"github.com/sigstore/rekor/pkg/log"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -32,21 +33,26 @@ func WrapCmd(f formatCmd) CobraCmd {
 	return func(cmd *cobra.Command, args []string) {
 		obj, err := f(args)
 		if err != nil {
-			log.CliLogger.Fatal(err)
+			log.CliLogger.Fatal(client.DescribeError(err))
 		}
 
-		// TODO: add flags to control output formatting (JSON, plaintext, etc.)
-		format := viper.GetString("format")
-		switch format {
-		case "default":
-			if s, ok := obj.(fmt.Stringer); ok {
-				fmt.Print(s.String())
-			} else {
-				fmt.Println(toJSON(s))
-			}
-		case "json":
+		Print(obj)
+	}
+}
+
+// Print writes obj to stdout in the format selected by the --format flag
+func Print(obj interface{}) {
+	// TODO: add flags to control output formatting (JSON, plaintext, etc.)
+	format := viper.GetString("format")
+	switch format {
+	case "default":
+		if s, ok := obj.(fmt.Stringer); ok {
+			fmt.Print(s.String())
+		} else {
 			fmt.Println(toJSON(obj))
 		}
+	case "json":
+		fmt.Println(toJSON(obj))
 	}
 }
 
@@ -23,14 +23,17 @@ import (
 	"errors"
 	"fmt"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/go-openapi/runtime"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 
+	"github.com/sigstore/rekor/cmd/rekor-cli/app/cache"
 	"github.com/sigstore/rekor/cmd/rekor-cli/app/format"
 	"github.com/sigstore/rekor/pkg/client"
+	genclient "github.com/sigstore/rekor/pkg/generated/client"
 	"github.com/sigstore/rekor/pkg/generated/client/entries"
 	"github.com/sigstore/rekor/pkg/generated/models"
 	"github.com/sigstore/rekor/pkg/log"
@@ -40,6 +43,7 @@ import (
 type getCmdOutput struct {
 	Attestation     string
 	AttestationType string
+	Annotations     models.Annotations `json:",omitempty"`
 	Body            interface{}
 	LogIndex        int
 	IntegratedTime  int64
@@ -58,6 +62,9 @@ func (g *getCmdOutput) String() string {
 	dt := time.Unix(g.IntegratedTime, 0).UTC().Format(time.RFC3339)
 	s += fmt.Sprintf("IntegratedTime: %s\n", dt)
 	s += fmt.Sprintf("UUID: %s\n", g.UUID)
+	for _, k := range types.AnnotationKeys(g.Annotations) {
+		s += fmt.Sprintf("Annotation: %s=%s\n", k, g.Annotations[k])
+	}
 	var b bytes.Buffer
 	e := json.NewEncoder(&b)
 	e.SetIndent("", "  ")
@@ -78,19 +85,26 @@ var getCmd = &cobra.Command{
 		}
 	},
 	Run: format.WrapCmd(func(args []string) (interface{}, error) {
+		ctx, cancel := commandContext()
+		defer cancel()
 		rekorClient, err := client.GetRekorClient(viper.GetString("rekor_server"))
 		if err != nil {
 			return nil, err
 		}
 
+		c := responseCache()
 		logIndex := viper.GetString("log-index")
 		if logIndex != "" {
-			params := entries.NewGetLogEntryByIndexParams()
-			params.SetTimeout(viper.GetDuration("timeout"))
 			logIndexInt, err := strconv.ParseInt(logIndex, 10, 0)
 			if err != nil {
 				return nil, fmt.Errorf("error parsing --log-index: %w", err)
 			}
+			if uuid, entry, ok := cachedEntryByIndex(c, logIndexInt); ok {
+				return verifiedEntry(ctx, rekorClient, c, uuid, entry)
+			}
+
+			params := entries.NewGetLogEntryByIndexParamsWithContext(ctx)
+			params.SetTimeout(viper.GetDuration("timeout"))
 			params.LogIndex = logIndexInt
 
 			resp, err := rekorClient.Entries.GetLogEntryByIndex(params)
@@ -98,17 +112,17 @@ var getCmd = &cobra.Command{
 				return nil, err
 			}
 			for ix, entry := range resp.Payload {
-				if verified, err := verifyLogEntry(context.Background(), rekorClient, entry); err != nil || !verified {
-					return nil, fmt.Errorf("unable to verify entry was added to log %w", err)
-				}
-
-				return parseEntry(ix, entry)
+				return verifiedEntry(ctx, rekorClient, c, ix, entry)
 			}
 		}
 
 		uuid := viper.GetString("uuid")
 		if uuid != "" {
-			params := entries.NewGetLogEntryByUUIDParams()
+			if entry, ok := cachedEntryByUUID(c, uuid); ok {
+				return verifiedEntry(ctx, rekorClient, c, uuid, entry)
+			}
+
+			params := entries.NewGetLogEntryByUUIDParamsWithContext(ctx)
 			params.SetTimeout(viper.GetDuration("timeout"))
 			params.EntryUUID = uuid
 
@@ -118,15 +132,10 @@ var getCmd = &cobra.Command{
 			}
 
 			for k, entry := range resp.Payload {
-				if k != uuid {
+				if !strings.EqualFold(k, uuid) {
 					continue
 				}
-
-				if verified, err := verifyLogEntry(context.Background(), rekorClient, entry); err != nil || !verified {
-					return nil, fmt.Errorf("unable to verify entry was added to log %w", err)
-				}
-
-				return parseEntry(k, entry)
+				return verifiedEntry(ctx, rekorClient, c, k, entry)
 			}
 		}
 
@@ -134,22 +143,49 @@ var getCmd = &cobra.Command{
 	}),
 }
 
-func parseEntry(uuid string, e models.LogEntryAnon) (interface{}, error) {
+// verifiedEntry verifies an entry returned by the server or read from the cache, stores it in the
+// cache and checks the consistency of the log
+func verifiedEntry(ctx context.Context, rekorClient *genclient.Rekor, c *cache.Cache, uuid string, entry models.LogEntryAnon) (interface{}, error) {
+	if verified, err := verifyLogEntry(ctx, rekorClient, entry); err != nil || !verified {
+		return nil, fmt.Errorf("unable to verify entry was added to log %w", err)
+	}
+	cacheEntry(c, uuid, entry)
+	if err := updateTreeStateFromEntry(ctx, rekorClient, viper.GetString("rekor_server"), entry); err != nil {
+		return nil, err
+	}
+	return parseEntry(uuid, entry)
+}
+
+// decodeEntryBody unmarshals the body of a log entry into its type's implementation, returning it along
+// with the annotations of the entry
+func decodeEntryBody(e models.LogEntryAnon) (types.EntryImpl, models.Annotations, error) {
 	b, err := base64.StdEncoding.DecodeString(e.Body.(string))
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	pe, err := models.UnmarshalProposedEntry(bytes.NewReader(b), runtime.JSONConsumer())
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	eimpl, err := types.NewEntry(pe)
+	if err != nil {
+		return nil, nil, err
+	}
+	return eimpl, pe.Annotations(), nil
+}
+
+func parseEntry(uuid string, e models.LogEntryAnon) (interface{}, error) {
+	eimpl, annotations, err := decodeEntryBody(e)
 	if err != nil {
 		return nil, err
 	}
+	return newGetCmdOutput(uuid, e, eimpl, annotations), nil
+}
 
+func newGetCmdOutput(uuid string, e models.LogEntryAnon, eimpl types.EntryImpl, annotations models.Annotations) *getCmdOutput {
 	obj := getCmdOutput{
+		Annotations:    annotations,
 		Body:           eimpl,
 		UUID:           uuid,
 		IntegratedTime: *e.IntegratedTime,
@@ -161,7 +197,7 @@ func parseEntry(uuid string, e models.LogEntryAnon) (interface{}, error) {
 		obj.Attestation = string(e.Attestation.Data)
 	}
 
-	return &obj, nil
+	return &obj
 }
 
 func init() {
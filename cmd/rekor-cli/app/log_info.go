@@ -16,33 +16,23 @@
 package app
 
 import (
-	"bytes"
-	"crypto"
-	"crypto/x509"
+	"crypto/sha256"
 	"encoding/hex"
-	"encoding/pem"
-	"errors"
 	"fmt"
 	"time"
 
-	"github.com/google/trillian/merkle/logverifier"
-	"github.com/google/trillian/merkle/rfc6962"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 
 	"github.com/sigstore/rekor/cmd/rekor-cli/app/format"
-	"github.com/sigstore/rekor/cmd/rekor-cli/app/state"
 	"github.com/sigstore/rekor/pkg/client"
-	"github.com/sigstore/rekor/pkg/generated/client/tlog"
-	"github.com/sigstore/rekor/pkg/log"
-	"github.com/sigstore/rekor/pkg/util"
-	"github.com/sigstore/sigstore/pkg/signature"
 )
 
 type logInfoCmdOutput struct {
 	TreeSize       int64
 	RootHash       string
 	TimestampNanos uint64
+	LogID          string
 }
 
 func (l *logInfoCmdOutput) String() string {
@@ -52,7 +42,8 @@ func (l *logInfoCmdOutput) String() string {
 Tree Size: %v
 Root Hash: %s
 Timestamp: %s
-`, l.TreeSize, l.RootHash, ts)
+Log ID: %s
+`, l.TreeSize, l.RootHash, ts, l.LogID)
 }
 
 // logInfoCmd represents the current information about the transparency log
@@ -60,102 +51,41 @@ var logInfoCmd = &cobra.Command{
 	Use:   "loginfo",
 	Short: "Rekor loginfo command",
 	Long:  `Prints info about the transparency log`,
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		// these are bound here so that they are not overwritten by other commands
+		return viper.BindPFlags(cmd.Flags())
+	},
 	Run: format.WrapCmd(func(args []string) (interface{}, error) {
+		ctx, cancel := commandContext()
+		defer cancel()
 		serverURL := viper.GetString("rekor_server")
 		rekorClient, err := client.GetRekorClient(serverURL)
 		if err != nil {
 			return nil, err
 		}
 
-		params := tlog.GetLogInfoParams{}
-		params.SetTimeout(viper.GetDuration("timeout"))
-		result, err := rekorClient.Tlog.GetLogInfo(&params)
+		vth, err := fetchVerifiedTreeHead(ctx, rekorClient)
 		if err != nil {
 			return nil, err
 		}
+		sth := vth.sth
 
-		logInfo := result.GetPayload()
-
-		sth := util.SignedCheckpoint{}
-		if err := sth.UnmarshalText([]byte(*logInfo.SignedTreeHead)); err != nil {
-			return nil, err
-		}
-
-		publicKey := viper.GetString("rekor_server_public_key")
-		if publicKey == "" {
-			// fetch key from server
-			keyResp, err := rekorClient.Pubkey.GetPublicKey(nil)
-			if err != nil {
-				return nil, err
-			}
-			publicKey = keyResp.Payload
-		}
-
-		block, _ := pem.Decode([]byte(publicKey))
-		if block == nil {
-			return nil, errors.New("failed to decode public key of server")
-		}
-
-		pub, err := x509.ParsePKIXPublicKey(block.Bytes)
-		if err != nil {
-			return nil, err
-		}
-
-		verifier, err := signature.LoadVerifier(pub, crypto.SHA256)
-		if err != nil {
-			return nil, err
-		}
-
-		if !sth.Verify(verifier) {
-			return nil, errors.New("signature on tree head did not verify")
-		}
+		// the log ID is the SHA256 hash of the DER-encoded public key of the log
+		logID := sha256.Sum256(vth.publicKeyDER)
 
 		cmdOutput := &logInfoCmdOutput{
-			TreeSize:       *logInfo.TreeSize,
-			RootHash:       *logInfo.RootHash,
+			TreeSize:       int64(sth.Size),
+			RootHash:       hex.EncodeToString(sth.Hash),
 			TimestampNanos: sth.GetTimestamp(),
+			LogID:          hex.EncodeToString(logID[:]),
 		}
 
-		oldState := state.Load(serverURL)
-		if oldState != nil {
-			persistedSize := oldState.Size
-			if persistedSize < sth.Size {
-				log.CliLogger.Infof("Found previous log state, proving consistency between %d and %d", oldState.Size, sth.Size)
-				params := tlog.NewGetLogProofParams()
-				firstSize := int64(persistedSize)
-				params.FirstSize = &firstSize
-				params.LastSize = int64(sth.Size)
-				proof, err := rekorClient.Tlog.GetLogProof(params)
-				if err != nil {
-					return nil, err
-				}
-				hashes := [][]byte{}
-				for _, h := range proof.Payload.Hashes {
-					b, _ := hex.DecodeString(h)
-					hashes = append(hashes, b)
-				}
-				v := logverifier.New(rfc6962.DefaultHasher)
-				if err := v.VerifyConsistencyProof(firstSize, int64(sth.Size), oldState.Hash,
-					sth.Hash, hashes); err != nil {
-					return nil, err
-				}
-				log.CliLogger.Infof("Consistency proof valid!")
-			} else if persistedSize == sth.Size {
-				if !bytes.Equal(oldState.Hash, sth.Hash) {
-					return nil, errors.New("root hash returned from server does not match previously persisted state")
-				}
-				log.CliLogger.Infof("Persisted log state matches the current state of the log")
-			} else if persistedSize > sth.Size {
-				return nil, fmt.Errorf("current size of tree reported from server %d is less than previously persisted state %d", sth.Size, persistedSize)
-			}
-		} else {
-			log.CliLogger.Infof("No previous log state stored, unable to prove consistency")
+		if err := checkTreeStateConsistency(ctx, rekorClient, serverURL, sth); err != nil {
+			return nil, err
 		}
 
-		if viper.GetBool("store_tree_state") {
-			if err := state.Dump(serverURL, &sth); err != nil {
-				log.CliLogger.Infof("Unable to store previous state: %v", err)
-			}
+		if viper.GetBool("store") {
+			persistTreeState(serverURL, sth)
 		}
 		return cmdOutput, nil
 	}),
@@ -163,5 +93,6 @@ var logInfoCmd = &cobra.Command{
 
 func init() {
 	initializePFlagMap()
+	logInfoCmd.Flags().Bool("store", false, "persist the verified tree head to the local state file for later consistency checks")
 	rootCmd.AddCommand(logInfoCmd)
 }
@@ -72,6 +72,8 @@ var logProofCmd = &cobra.Command{
 		return nil
 	},
 	Run: format.WrapCmd(func(args []string) (interface{}, error) {
+		ctx, cancel := commandContext()
+		defer cancel()
 		rekorClient, err := client.GetRekorClient(viper.GetString("rekor_server"))
 		if err != nil {
 			return nil, err
@@ -80,7 +82,7 @@ var logProofCmd = &cobra.Command{
 		firstSize := int64(viper.GetUint64("first-size"))
 		lastSize := int64(viper.GetUint64("last-size"))
 
-		params := tlog.NewGetLogProofParams()
+		params := tlog.NewGetLogProofParamsWithContext(ctx)
 		params.FirstSize = &firstSize
 		params.LastSize = lastSize
 		params.SetTimeout(viper.GetDuration("timeout"))
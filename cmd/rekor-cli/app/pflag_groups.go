@@ -51,45 +51,69 @@ func addArtifactPFlags(cmd *cobra.Command) error {
 		flagType FlagType
 		desc     string
 		required bool
+		multiple bool
 	}{
 		"signature": {
 			fileOrURLFlag,
-			"path or URL to detached signature file",
+			"path or URL to detached signature file; may be repeated, each signature being verified by the --public-key given in the same position",
 			false,
+			true,
 		},
 		"type": {
 			typeFlag,
 			fmt.Sprintf("type of entry expressed as type(:version)?; supported types = %v", types.ListImplementedTypes()),
 			false,
+			false,
 		},
 		"pki-format": {
 			pkiFormatFlag,
 			fmt.Sprintf("format of the signature and/or public key; options = %v", pki.SupportedFormats()),
 			false,
+			false,
 		},
 		"public-key": {
-			fileOrURLFlag,
-			"path or URL to public key file",
+			publicKeyFlag,
+			"path or URL to public key file, hkps://keyserver[/query] or email address to fetch the key from a keyserver or Web Key Directory (requires --key-fingerprint); may be repeated to match each --signature",
 			false,
+			true,
+		},
+		"key-fingerprint": {
+			fprFlag,
+			"fingerprint of a PGP key fetched from a keyserver or Web Key Directory, which must match its primary key; may be repeated",
+			false,
+			true,
 		},
 		"artifact": {
 			fileOrURLFlag,
 			"path or URL to artifact file",
 			false,
+			false,
 		},
 		"artifact-hash": {
-			shaFlag,
-			"hex encoded SHA256 hash of artifact (when using URL)",
+			digestFlag,
+			"hex encoded hash of artifact, optionally prefixed by its algorithm as in sha512:<hex> (SHA256, SHA384 and SHA512 are supported by hashedrekord, other types and artifacts given by URL require SHA256)",
+			false,
+			false,
+		},
+		"image": {
+			imageFlag,
+			"container image reference whose manifest digest is resolved from the registry (with --type oci); if --artifact is not given, the cosign signature payload for the image is used",
+			false,
 			false,
 		},
 		"entry": {
 			fileOrURLFlag,
 			"path or URL to pre-formatted entry file",
 			false,
+			false,
 		},
 	}
 
 	for flag, flagVal := range flags {
+		if flagVal.multiple {
+			cmd.Flags().Var(NewFlagSliceValue(flagVal.flagType), flag, flagVal.desc)
+			continue
+		}
 		if err := addFlagToCmd(cmd, flagVal.required, flagVal.flagType, flag, flagVal.desc); err != nil {
 			return err
 		}
@@ -108,12 +132,12 @@ func validateArtifactPFlags(uuidValid, indexValid bool) error {
 		indexGiven = true
 	}
 
-	// if neither --entry or --artifact were given, then a reference to a uuid or index is needed
-	if viper.GetString("entry") == "" && viper.GetString("artifact") == "" && viper.GetString("artifact-hash") == "" {
+	// if neither --entry, --artifact or --image were given, then a reference to a uuid or index is needed
+	if viper.GetString("entry") == "" && viper.GetString("artifact") == "" && viper.GetString("artifact-hash") == "" && viper.GetString("image") == "" {
 		if (uuidGiven && uuidValid) || (indexGiven && indexValid) {
 			return nil
 		}
-		return errors.New("either 'entry' or 'artifact' or 'artifact-hash' must be specified")
+		return errors.New("either 'entry' or 'artifact' or 'artifact-hash' or 'image' must be specified")
 	}
 
 	return nil
@@ -132,23 +156,20 @@ func CreatePropsFromPflags() *types.ArtifactProperties {
 	}
 
 	props.ArtifactHash = viper.GetString("artifact-hash")
+	props.ImageReference = viper.GetString("image")
 
-	signatureString := viper.GetString("signature")
-	if signatureString != "" {
-		if isURL(signatureString) {
-			props.SignaturePath, _ = url.Parse(signatureString)
-		} else {
-			props.SignaturePath = &url.URL{Path: signatureString}
-		}
+	for _, signatureString := range viper.GetStringSlice("signature") {
+		props.SignaturePaths = append(props.SignaturePaths, fileOrURL(signatureString))
+	}
+	if len(props.SignaturePaths) > 0 {
+		props.SignaturePath = props.SignaturePaths[0]
 	}
 
-	publicKeyString := viper.GetString("public-key")
-	if publicKeyString != "" {
-		if isURL(publicKeyString) {
-			props.PublicKeyPath, _ = url.Parse(publicKeyString)
-		} else {
-			props.PublicKeyPath = &url.URL{Path: publicKeyString}
-		}
+	for _, publicKeyString := range viper.GetStringSlice("public-key") {
+		props.PublicKeyPaths = append(props.PublicKeyPaths, fileOrURL(publicKeyString))
+	}
+	if len(props.PublicKeyPaths) > 0 {
+		props.PublicKeyPath = props.PublicKeyPaths[0]
 	}
 
 	props.PKIFormat = viper.GetString("pki-format")
@@ -156,7 +177,16 @@ func CreatePropsFromPflags() *types.ArtifactProperties {
 	return props
 }
 
-//TODO: add tests for this
+// fileOrURL returns a URL for a value given to a fileOrURL flag; local files are represented by their path
+func fileOrURL(v string) *url.URL {
+	if isURL(v) {
+		u, _ := url.Parse(v)
+		return u
+	}
+	return &url.URL{Path: v}
+}
+
+// TODO: add tests for this
 func ParseTypeFlag(typeStr string) (string, string, error) {
 	// typeStr can come in as:
 	// type -> use default version for this kind
@@ -16,13 +16,19 @@
 package app
 
 import (
+	"bytes"
+	"encoding/csv"
 	"fmt"
 	"log"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/sigstore/rekor/pkg/generated/models"
 	"github.com/sigstore/rekor/pkg/pki"
+	"github.com/sigstore/rekor/pkg/pki/pgp"
+	"github.com/sigstore/rekor/pkg/types"
+	"github.com/sigstore/rekor/pkg/types/oci"
 	"github.com/sigstore/rekor/pkg/util"
 
 	"github.com/spf13/pflag"
@@ -46,6 +52,17 @@ const (
 	oidFlag       FlagType = "oid"
 	formatFlag    FlagType = "format"
 	timeoutFlag   FlagType = "timeout"
+	imageFlag     FlagType = "image"
+	publicKeyFlag FlagType = "publicKey"
+	fprFlag       FlagType = "fingerprint"
+	digestFlag    FlagType = "digest"
+	hashAlgFlag   FlagType = "hashAlgorithm"
+	annotFlag     FlagType = "annotation"
+	commitFlag    FlagType = "commit"
+	repoFlag      FlagType = "repository"
+	artURLFlag    FlagType = "artifactURL"
+	witnessFlag   FlagType = "witness"
+	timeFlag      FlagType = "time"
 )
 
 type newPFlagValueFunc func() pflag.Value
@@ -101,7 +118,51 @@ func initializePFlagMap() {
 		},
 		timeoutFlag: func() pflag.Value {
 			// this validates the timeout is >= 0
-			return valueFactory(formatFlag, validateTimeout, "")
+			return valueFactory(timeoutFlag, validateTimeout, "")
+		},
+		imageFlag: func() pflag.Value {
+			// this validates a container image reference of the form [registry/]repository[:tag][@digest]
+			return valueFactory(imageFlag, validateImageReference, "")
+		},
+		publicKeyFlag: func() pflag.Value {
+			// applies logic of fileOrURLFlag, or accepts a keyserver reference or email address to fetch the key for
+			return valueFactory(publicKeyFlag, validatePublicKey, "")
+		},
+		digestFlag: func() pflag.Value {
+			// this validates a SHA1, SHA256, SHA384 or SHA512 digest which is optionally prefixed with its algorithm, e.g. 'sha512:'
+			return valueFactory(digestFlag, validateDigest, "")
+		},
+		hashAlgFlag: func() pflag.Value {
+			// this ensures the hash algorithm is one that digests of artifacts may be computed with
+			return valueFactory(hashAlgFlag, validateString(fmt.Sprintf("required,oneof=%v %v %v", util.SHA256, util.SHA384, util.SHA512)), util.SHA256)
+		},
+		fprFlag: func() pflag.Value {
+			// this validates a hex encoded V4 PGP key fingerprint
+			return valueFactory(fprFlag, validateFingerprint, "")
+		},
+		annotFlag: func() pflag.Value {
+			// this validates an annotation of the form key=value
+			return valueFactory(annotFlag, validateAnnotation, "")
+		},
+		commitFlag: func() pflag.Value {
+			// this validates a SHA1 or SHA256 git commit ID
+			return valueFactory(commitFlag, validateCommit, "")
+		},
+		repoFlag: func() pflag.Value {
+			// this validates the absolute URL of a source repository
+			return valueFactory(repoFlag, validateRepository, "")
+		},
+		artURLFlag: func() pflag.Value {
+			// this validates the http or https URL an artifact was downloaded from
+			return valueFactory(artURLFlag, validateArtifactURL, "")
+		},
+		witnessFlag: func() pflag.Value {
+			// this validates a witness of the form name=path of its public key
+			return valueFactory(witnessFlag, validateWitness, "")
+		},
+		timeFlag: func() pflag.Value {
+			// this validates a point in time given as an RFC 3339 timestamp or @<Unix time>
+			return valueFactory(timeFlag, validateTime, "")
 		},
 	}
 }
@@ -157,6 +218,69 @@ func (b *baseValue) Set(s string) error {
 	return nil
 }
 
+// NewFlagSliceValue creates a new pflag.Value for the specified type which may be given multiple times;
+// each value is validated as it would be by the Value returned from NewFlagValue.
+func NewFlagSliceValue(flagType FlagType) pflag.Value {
+	return &sliceValue{
+		newValue: pflagValueFuncMap[flagType],
+	}
+}
+
+// sliceValue implements pflag.Value for flags which may be repeated
+type sliceValue struct {
+	newValue newPFlagValueFunc
+	values   []string
+}
+
+// Type returns "stringSlice", which causes viper to return all values given from GetStringSlice
+func (s sliceValue) Type() string {
+	return "stringSlice"
+}
+
+// String returns the values in the bracketed CSV representation expected by viper
+func (s sliceValue) String() string {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.Write(s.values); err != nil {
+		return "[]"
+	}
+	w.Flush()
+	return "[" + strings.TrimSuffix(buf.String(), "\n") + "]"
+}
+
+// Set validates the provided string and appends it to the values of this flag; unlike pflag's
+// string slices, a single value is never split on commas
+func (s *sliceValue) Set(v string) error {
+	val := s.newValue()
+	if err := val.Set(v); err != nil {
+		return err
+	}
+	s.values = append(s.values, val.String())
+	return nil
+}
+
+// Append validates and appends a value, as Set does
+func (s *sliceValue) Append(v string) error {
+	return s.Set(v)
+}
+
+// Replace validates the provided strings and replaces the values of this flag with them
+func (s *sliceValue) Replace(values []string) error {
+	replaced := &sliceValue{newValue: s.newValue}
+	for _, v := range values {
+		if err := replaced.Set(v); err != nil {
+			return err
+		}
+	}
+	s.values = replaced.values
+	return nil
+}
+
+// GetSlice returns the values of this flag
+func (s *sliceValue) GetSlice() []string {
+	return append([]string{}, s.values...)
+}
+
 // isURL returns true if the supplied value is a valid URL and false otherwise
 func isURL(v string) bool {
 	valGen := pflagValueFuncMap[urlFlag]
@@ -180,6 +304,15 @@ func validateSHAValue(v string) error {
 	return nil
 }
 
+// validateDigest ensures that the supplied string is a hex encoded digest of a supported hash algorithm,
+// which is optionally tagged as in sha512:<128 hexadecimal characters>
+func validateDigest(v string) error {
+	if _, _, err := util.ParseDigest(v); err != nil {
+		return fmt.Errorf("error parsing %v flag: %w", digestFlag, err)
+	}
+	return nil
+}
+
 // validateFileOrURL ensures the provided string is either a valid file path that can be opened or a valid URL
 func validateFileOrURL(v string) error {
 	valGen := pflagValueFuncMap[fileFlag]
@@ -190,6 +323,76 @@ func validateFileOrURL(v string) error {
 	return valGen().Set(v)
 }
 
+// validatePublicKey ensures the provided string is either a file path or URL, or a reference to a key
+// on a HKPS keyserver (hkps://host[/query]) or an email address to look up in the Web Key Directory
+func validatePublicKey(v string) error {
+	if pgp.IsRemoteKeyReference(v) {
+		return nil
+	}
+	return validateFileOrURL(v)
+}
+
+// validateFingerprint ensures the provided string is a V4 PGP key fingerprint, optionally prefixed with '0x'
+func validateFingerprint(v string) error {
+	_, err := pgp.ParseFingerprints([]string{v})
+	return err
+}
+
+// validateAnnotation ensures that the supplied string is an annotation of the form key=value
+func validateAnnotation(v string) error {
+	_, err := parseAnnotations([]string{v})
+	return err
+}
+
+// parseAnnotations parses annotations of the form key=value; values may contain '='
+func parseAnnotations(values []string) (models.Annotations, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+	annotations := models.Annotations{}
+	for _, v := range values {
+		i := strings.Index(v, "=")
+		if i < 0 {
+			return nil, fmt.Errorf("annotation %q must be of the form key=value", v)
+		}
+		annotations[v[:i]] = v[i+1:]
+	}
+	if err := types.ValidateAnnotations(annotations); err != nil {
+		return nil, err
+	}
+	return annotations, nil
+}
+
+// validateCommit ensures that the supplied string is a full SHA1 or SHA256 git commit ID
+func validateCommit(v string) error {
+	_, err := util.NormalizeGitCommit(v)
+	return err
+}
+
+// validateRepository ensures that the supplied string is the absolute URL of a source repository
+func validateRepository(v string) error {
+	_, err := util.NormalizeRepositoryURL(v)
+	return err
+}
+
+// validateArtifactURL ensures that the supplied string is an absolute http or https URL
+func validateArtifactURL(v string) error {
+	_, err := util.NormalizeArtifactURL(v)
+	return err
+}
+
+// validateWitness ensures that the supplied string names a witness and the file holding its PEM encoded public key
+func validateWitness(v string) error {
+	_, err := parseWitness(v)
+	return err
+}
+
+// validateTime ensures that the supplied string is an RFC 3339 timestamp or @ followed by a Unix time
+func validateTime(v string) error {
+	_, err := parseTime(v)
+	return err
+}
+
 // validateLogIndex ensures that the supplied string is a valid log index (integer >= 0)
 func validateLogIndex(v string) error {
 	i, err := strconv.Atoi(v)
@@ -224,6 +427,12 @@ func validateTimeout(v string) error {
 	return useValidator(timeoutFlag, d)
 }
 
+// validateImageReference ensures that the string can be parsed as a container image reference
+func validateImageReference(v string) error {
+	_, err := oci.ParseReference(v)
+	return err
+}
+
 // validateTypeFlag ensures that the string is in the format type(\.version)? and
 // that one of the types requested is implemented
 func validateTypeFlag(v string) error {
@@ -21,6 +21,7 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/spf13/cobra"
@@ -412,6 +413,125 @@ func TestArtifactPFlags(t *testing.T) {
 	}
 }
 
+func TestMultipleSignaturePFlags(t *testing.T) {
+	initializePFlagMap()
+	var blankCmd = &cobra.Command{}
+	if err := addArtifactPFlags(blankCmd); err != nil {
+		t.Fatalf("unexpected error adding flags: %v", err)
+	}
+
+	args := []string{
+		"--artifact", "../../../tests/test_file.txt",
+		"--signature", "../../../tests/test_file.sig",
+		"--public-key", "../../../tests/test_public_key.key",
+		"--signature", "../../../tests/test_file.sig",
+		"--public-key", "../../../tests/test_public_key.key",
+	}
+	if err := blankCmd.ParseFlags(args); err != nil {
+		t.Fatalf("unexpected error parsing repeated flags: %v", err)
+	}
+	if err := viper.BindPFlags(blankCmd.Flags()); err != nil {
+		t.Fatalf("unexpected result initializing viper: %v", err)
+	}
+
+	props := CreatePropsFromPflags()
+	if len(props.SignaturePaths) != 2 || len(props.PublicKeyPaths) != 2 {
+		t.Fatalf("expected two signatures and public keys, got %v and %v", props.SignaturePaths, props.PublicKeyPaths)
+	}
+	if props.SignaturePath == nil || props.SignaturePath.String() != props.SignaturePaths[0].String() {
+		t.Errorf("expected first signature to be used as the single signature path, got %v", props.SignaturePath)
+	}
+
+	initializePFlagMap()
+	blankCmd = &cobra.Command{}
+	if err := addArtifactPFlags(blankCmd); err != nil {
+		t.Fatalf("unexpected error adding flags: %v", err)
+	}
+	args = []string{
+		"--signature", "../../../tests/test_file.sig",
+		"--signature", "../../../tests/not_a_file",
+	}
+	if err := blankCmd.ParseFlags(args); err == nil {
+		t.Error("expected error parsing an invalid repeated signature flag")
+	}
+}
+
+func TestImagePFlags(t *testing.T) {
+	initializePFlagMap()
+	var blankCmd = &cobra.Command{}
+	if err := addArtifactPFlags(blankCmd); err != nil {
+		t.Fatalf("unexpected error adding flags: %v", err)
+	}
+
+	args := []string{
+		"--type", "oci",
+		"--image", "ghcr.io/org/app:v1",
+		"--signature", "../../../tests/test_file.sig",
+		"--public-key", "../../../tests/test_public_key.key",
+	}
+	if err := blankCmd.ParseFlags(args); err != nil {
+		t.Fatalf("unexpected error parsing image flags: %v", err)
+	}
+	if err := viper.BindPFlags(blankCmd.Flags()); err != nil {
+		t.Fatalf("unexpected result initializing viper: %v", err)
+	}
+	if err := validateArtifactPFlags(false, false); err != nil {
+		t.Errorf("expected an image reference to be accepted in place of an artifact: %v", err)
+	}
+	if props := CreatePropsFromPflags(); props.ImageReference != "ghcr.io/org/app:v1" {
+		t.Errorf("unexpected image reference %q", props.ImageReference)
+	}
+
+	initializePFlagMap()
+	blankCmd = &cobra.Command{}
+	if err := addArtifactPFlags(blankCmd); err != nil {
+		t.Fatalf("unexpected error adding flags: %v", err)
+	}
+	if err := blankCmd.ParseFlags([]string{"--image", "ghcr.io/Org/App"}); err == nil {
+		t.Error("expected error parsing an invalid image reference")
+	}
+}
+
+func TestRemotePublicKeyPFlags(t *testing.T) {
+	initializePFlagMap()
+	var blankCmd = &cobra.Command{}
+	if err := addArtifactPFlags(blankCmd); err != nil {
+		t.Fatalf("unexpected error adding flags: %v", err)
+	}
+
+	args := []string{
+		"--artifact", "../../../tests/test_file.txt",
+		"--signature", "../../../tests/test_file.sig",
+		"--public-key", "maintainer@example.com",
+		"--signature", "../../../tests/test_file.sig",
+		"--public-key", "../../../tests/test_public_key.key",
+	}
+	if err := blankCmd.ParseFlags(args); err != nil {
+		t.Fatalf("unexpected error parsing remote public key flags: %v", err)
+	}
+	if err := viper.BindPFlags(blankCmd.Flags()); err != nil {
+		t.Fatalf("unexpected result initializing viper: %v", err)
+	}
+
+	props := CreatePropsFromPflags()
+	if _, err := resolveRemotePublicKeys(context.Background(), props); err == nil {
+		t.Error("expected an error fetching a public key without --key-fingerprint")
+	}
+
+	initializePFlagMap()
+	blankCmd = &cobra.Command{}
+	if err := addArtifactPFlags(blankCmd); err != nil {
+		t.Fatalf("unexpected error adding flags: %v", err)
+	}
+	args = []string{
+		"--public-key", "hkps://keys.openpgp.org",
+		"--key-fingerprint", "17B1B3672BD1CBC4",
+	}
+	if err := blankCmd.ParseFlags(args); err == nil {
+		t.Error("expected error parsing a key ID in place of a fingerprint")
+	}
+}
+
 func TestValidateRekorServerURL(t *testing.T) {
 	type test struct {
 		caseDesc      string
@@ -461,6 +581,12 @@ func TestSearchPFlags(t *testing.T) {
 		sha                   string
 		email                 string
 		pkiFormat             string
+		annotation            string
+		commit                string
+		repository            string
+		artifactURL           string
+		since                 string
+		until                 string
 		expectParseSuccess    bool
 		expectValidateSuccess bool
 	}
@@ -549,6 +675,18 @@ func TestSearchPFlags(t *testing.T) {
 			expectParseSuccess:    true,
 			expectValidateSuccess: true,
 		},
+		{
+			caseDesc:              "valid tagged SHA512",
+			sha:                   "sha512:" + strings.Repeat("45c7b11fcbf07dec", 8),
+			expectParseSuccess:    true,
+			expectValidateSuccess: true,
+		},
+		{
+			caseDesc:              "SHA256 tagged as SHA512",
+			sha:                   "sha512:45c7b11fcbf07dec1694adecd8c5b85770a12a6c8dfdcf2580a2db0c47c31779",
+			expectParseSuccess:    false,
+			expectValidateSuccess: false,
+		},
 		{
 			caseDesc:              "invalid SHA",
 			sha:                   "45c7b11fcbf",
@@ -567,6 +705,112 @@ func TestSearchPFlags(t *testing.T) {
 			expectParseSuccess:    false,
 			expectValidateSuccess: false,
 		},
+		{
+			caseDesc:              "valid annotation",
+			annotation:            "git.commit=4b825dc642cb6eb9a060e54bf8d69288fbee4904",
+			expectParseSuccess:    true,
+			expectValidateSuccess: true,
+		},
+		{
+			caseDesc:              "annotation without value",
+			annotation:            "git.commit",
+			expectParseSuccess:    false,
+			expectValidateSuccess: false,
+		},
+		{
+			caseDesc:              "annotation with invalid key",
+			annotation:            "git commit=4b825dc6",
+			expectParseSuccess:    false,
+			expectValidateSuccess: false,
+		},
+		{
+			caseDesc:              "valid commit",
+			commit:                "4b825dc642cb6eb9a060e54bf8d69288fbee4904",
+			expectParseSuccess:    true,
+			expectValidateSuccess: true,
+		},
+		{
+			caseDesc:              "abbreviated commit",
+			commit:                "4b825dc",
+			expectParseSuccess:    false,
+			expectValidateSuccess: false,
+		},
+		{
+			caseDesc:              "valid repository",
+			repository:            "git+https://github.com/sigstore/rekor@refs/heads/main",
+			expectParseSuccess:    true,
+			expectValidateSuccess: true,
+		},
+		{
+			caseDesc:              "relative repository",
+			repository:            "sigstore/rekor",
+			expectParseSuccess:    false,
+			expectValidateSuccess: false,
+		},
+		{
+			caseDesc:              "valid artifact URL",
+			artifactURL:           "HTTPS://Example.com:443/releases/app.tar.gz",
+			expectParseSuccess:    true,
+			expectValidateSuccess: true,
+		},
+		{
+			caseDesc:              "artifact URL of a file",
+			artifactURL:           "file:///tmp/app.tar.gz",
+			expectParseSuccess:    false,
+			expectValidateSuccess: false,
+		},
+		{
+			caseDesc:              "time range of timestamps",
+			since:                 "2024-01-01T00:00:00Z",
+			until:                 "2024-02-01T00:00:00Z",
+			expectParseSuccess:    true,
+			expectValidateSuccess: true,
+		},
+		{
+			caseDesc:              "time range since a timestamp with a public key",
+			publicKey:             testServer.URL + "/publicKey",
+			pkiFormat:             "pgp",
+			since:                 "2024-01-01T12:00:00+01:00",
+			expectParseSuccess:    true,
+			expectValidateSuccess: true,
+		},
+		{
+			caseDesc:              "time range until a Unix time",
+			until:                 "@1706745600",
+			expectParseSuccess:    true,
+			expectValidateSuccess: true,
+		},
+		{
+			caseDesc:              "invalid time",
+			since:                 "last tuesday",
+			expectParseSuccess:    false,
+			expectValidateSuccess: false,
+		},
+		{
+			caseDesc:              "bare year, which could be taken for a Unix time",
+			since:                 "2024",
+			expectParseSuccess:    false,
+			expectValidateSuccess: false,
+		},
+		{
+			caseDesc:              "Unix time without @",
+			until:                 "1706745600",
+			expectParseSuccess:    false,
+			expectValidateSuccess: false,
+		},
+		{
+			caseDesc:              "date without a time and time zone",
+			since:                 "2024-01-01",
+			expectParseSuccess:    false,
+			expectValidateSuccess: false,
+		},
+		{
+			caseDesc:              "time range ending before it starts",
+			since:                 "2024-02-01T00:00:00Z",
+			until:                 "@1704067200",
+			expectParseSuccess:    true,
+			expectValidateSuccess: false,
+		},
 		{
 			caseDesc:              "no flags when either artifact, sha, public key, or email are needed",
 			expectParseSuccess:    true,
@@ -598,6 +842,24 @@ func TestSearchPFlags(t *testing.T) {
 		if tc.email != "" {
 			args = append(args, "--email", tc.email)
 		}
+		if tc.annotation != "" {
+			args = append(args, "--annotation", tc.annotation)
+		}
+		if tc.commit != "" {
+			args = append(args, "--commit", tc.commit)
+		}
+		if tc.repository != "" {
+			args = append(args, "--repository", tc.repository)
+		}
+		if tc.artifactURL != "" {
+			args = append(args, "--artifact-url", tc.artifactURL)
+		}
+		if tc.since != "" {
+			args = append(args, "--since", tc.since)
+		}
+		if tc.until != "" {
+			args = append(args, "--until", tc.until)
+		}
 
 		if err := blankCmd.ParseFlags(args); (err == nil) != tc.expectParseSuccess {
 			t.Errorf("unexpected result parsing '%v': %v", tc.caseDesc, err)
@@ -0,0 +1,100 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package app
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+// profilesKey is the key of the config file holding the named server profiles, e.g.
+//
+//	profiles:
+//	  staging:
+//	    rekor_server: https://rekor.staging.example.com
+//	    cacert: /etc/ssl/staging-ca.pem
+//	    auth-token: ...
+//	    format: json
+const profilesKey = "profiles"
+
+// profileNames returns the names of the profiles defined in config
+func profileNames(config *viper.Viper) []string {
+	var names []string
+	for name := range config.GetStringMap(profilesKey) {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// applyProfile sets the flags of the profile called name in config which were not given on the command
+// line or through the environment. Profiles may hold the global flags of rekor-cli, which are given by
+// globals, except for --config and --profile.
+func applyProfile(config *viper.Viper, globals, flags *pflag.FlagSet, name string) error {
+	if name == "" {
+		return nil
+	}
+	profile := config.Sub(profilesKey + "." + name)
+	if profile == nil {
+		if config.ConfigFileUsed() == "" {
+			return fmt.Errorf("profile %q requested but no config file was found", name)
+		}
+		return fmt.Errorf("profile %q is not defined in %v", name, config.ConfigFileUsed())
+	}
+
+	for _, key := range profile.AllKeys() {
+		if key == "config" || key == "profile" || globals.Lookup(key) == nil {
+			return fmt.Errorf("profile %q: unknown setting %q", name, key)
+		}
+		f := flags.Lookup(key)
+		// initConfig marks the flags given through the environment as changed, too
+		if f == nil || f.Changed {
+			continue
+		}
+		if err := setProfileValue(flags, f, profile.Get(key)); err != nil {
+			return fmt.Errorf("profile %q: invalid %v: %w", name, key, err)
+		}
+	}
+	return nil
+}
+
+// setProfileValue sets a flag to the value of a profile setting; each element of a list is set as a
+// value of its own, rather than the formatted list as a single one
+func setProfileValue(flags *pflag.FlagSet, f *pflag.Flag, value interface{}) error {
+	var values []string
+	switch v := value.(type) {
+	case []interface{}:
+		for _, e := range v {
+			values = append(values, fmt.Sprintf("%v", e))
+		}
+	case []string:
+		values = v
+	default:
+		return flags.Set(f.Name, fmt.Sprintf("%v", value))
+	}
+	sv, ok := f.Value.(pflag.SliceValue)
+	if !ok {
+		return fmt.Errorf("%v takes a single value, not a list", f.Name)
+	}
+	if err := sv.Replace(values); err != nil {
+		return err
+	}
+	f.Changed = true
+	return nil
+}
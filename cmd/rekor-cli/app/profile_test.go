@@ -0,0 +1,125 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package app
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+const testProfiles = `
+profiles:
+  staging:
+    rekor_server: https://rekor.staging.example.com
+    auth-token: staging-token
+    format: json
+  broken:
+    format: yaml
+  typo:
+    rekor-server: https://rekor.example.com
+  local:
+    type: rekord
+`
+
+func TestApplyProfile(t *testing.T) {
+	initializePFlagMap()
+	config := viper.New()
+	config.SetConfigType("yaml")
+	if err := config.ReadConfig(bytes.NewBufferString(testProfiles)); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := profileNames(config), []string{"broken", "local", "staging", "typo"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("profileNames() = %v, want %v", got, want)
+	}
+
+	newFlags := func() *pflag.FlagSet {
+		flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+		flags.Var(NewFlagValue(urlFlag, "https://rekor.sigstore.dev"), "rekor_server", "")
+		flags.Var(NewFlagValue(formatFlag, "default"), "format", "")
+		flags.String("auth-token", "", "")
+		flags.Var(NewFlagValue(typeFlag, ""), "type", "")
+		return flags
+	}
+
+	flags := newFlags()
+	if err := flags.Parse([]string{"--format", "default"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := applyProfile(config, rootCmd.PersistentFlags(), flags, "staging"); err != nil {
+		t.Fatal(err)
+	}
+	for name, want := range map[string]string{
+		"rekor_server": "https://rekor.staging.example.com",
+		"auth-token":   "staging-token",
+		// flags given on the command line take precedence over the profile
+		"format": "default",
+	} {
+		if got := flags.Lookup(name).Value.String(); got != want {
+			t.Errorf("%v = %v, want %v", name, got, want)
+		}
+	}
+
+	if err := applyProfile(config, rootCmd.PersistentFlags(), newFlags(), ""); err != nil {
+		t.Errorf("applyProfile() without a profile = %v", err)
+	}
+	for _, name := range []string{"missing", "broken", "typo", "local"} {
+		if err := applyProfile(config, rootCmd.PersistentFlags(), newFlags(), name); err == nil {
+			t.Errorf("expected error applying profile %v", name)
+		}
+	}
+}
+
+func TestSetProfileValue(t *testing.T) {
+	initializePFlagMap()
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	flags.Var(NewFlagValue(formatFlag, "default"), "format", "")
+	flags.StringSlice("names", nil, "")
+	flags.Var(NewFlagSliceValue(urlFlag), "servers", "")
+
+	for _, tt := range []struct {
+		name    string
+		value   interface{}
+		want    string
+		wantErr bool
+	}{
+		{name: "format", value: "json", want: "json"},
+		{name: "format", value: "xml", wantErr: true},
+		{name: "format", value: []interface{}{"json"}, wantErr: true},
+		// each element of a list is a value of its own, rather than "[a b]" being a single one
+		{name: "names", value: []interface{}{"a", "b"}, want: "[a,b]"},
+		{name: "names", value: []string{"c"}, want: "[c]"},
+		{name: "servers", value: []interface{}{"https://a.example.com", "https://b.example.com"}, want: "[https://a.example.com,https://b.example.com]"},
+		{name: "servers", value: []interface{}{"https://a.example.com", "not a url"}, wantErr: true},
+	} {
+		f := flags.Lookup(tt.name)
+		err := setProfileValue(flags, f, tt.value)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%v = %v: got error %v, want error %v", tt.name, tt.value, err, tt.wantErr)
+			continue
+		}
+		if err == nil && f.Value.String() != tt.want {
+			t.Errorf("%v = %v: got %v, want %v", tt.name, tt.value, f.Value.String(), tt.want)
+		}
+	}
+	// a list which is rejected leaves the values of the flag unchanged
+	if got, want := flags.Lookup("servers").Value.String(), "[https://a.example.com,https://b.example.com]"; got != want {
+		t.Errorf("servers = %v, want %v", got, want)
+	}
+}
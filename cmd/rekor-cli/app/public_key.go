@@ -0,0 +1,98 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package app
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/spf13/viper"
+
+	"github.com/sigstore/rekor/pkg/client"
+	"github.com/sigstore/rekor/pkg/pki/pgp"
+	"github.com/sigstore/rekor/pkg/types"
+)
+
+// isRemotePublicKey reports whether a --public-key value is a keyserver reference or an email address
+// to be resolved through the Web Key Directory, rather than a local file or URL
+func isRemotePublicKey(v string) bool {
+	if _, err := os.Stat(v); err == nil {
+		return false
+	}
+	return pgp.IsRemoteKeyReference(v)
+}
+
+// fetchRemotePublicKey fetches the PGP key for a keyserver reference or email address, keeping only
+// keys with a fingerprint given by --key-fingerprint
+func fetchRemotePublicKey(ctx context.Context, ref string) ([]byte, error) {
+	if format := viper.GetString("pki-format"); format != "pgp" {
+		return nil, fmt.Errorf("public key %v can only be fetched from a keyserver or Web Key Directory with --pki-format=pgp, not %v", ref, format)
+	}
+	fingerprints := viper.GetStringSlice("key-fingerprint")
+	if len(fingerprints) == 0 {
+		return nil, fmt.Errorf("--key-fingerprint is required to fetch public key %v", ref)
+	}
+	key, err := pgp.FetchPinnedPublicKey(ctx, client.NewFetchClient(), ref, fingerprints)
+	if err != nil {
+		return nil, err
+	}
+	return key.CanonicalValue()
+}
+
+// resolveRemotePublicKeys replaces each public key given as a keyserver reference or email address by
+// a temporary file holding the fetched key, which cleanup removes
+func resolveRemotePublicKeys(ctx context.Context, props *types.ArtifactProperties) (_ func(), err error) {
+	var files []string
+	cleanup := func() {
+		for _, f := range files {
+			_ = os.Remove(f)
+		}
+	}
+	defer func() {
+		if err != nil {
+			cleanup()
+		}
+	}()
+
+	for i, keyPath := range props.PublicKeyPaths {
+		if keyPath.IsAbs() || !isRemotePublicKey(keyPath.Path) {
+			continue
+		}
+		keyBytes, err := fetchRemotePublicKey(ctx, keyPath.Path)
+		if err != nil {
+			return nil, err
+		}
+		f, err := ioutil.TempFile("", "rekor-public-key-*.asc")
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, f.Name())
+		_, err = f.Write(keyBytes)
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return nil, err
+		}
+		props.PublicKeyPaths[i] = fileOrURL(f.Name())
+	}
+	if len(props.PublicKeyPaths) > 0 {
+		props.PublicKeyPath = props.PublicKeyPaths[0]
+	}
+	return cleanup, nil
+}
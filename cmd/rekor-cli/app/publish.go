@@ -0,0 +1,195 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/spf13/viper"
+
+	"github.com/sigstore/rekor/pkg/bundle"
+	"github.com/sigstore/rekor/pkg/client"
+	genclient "github.com/sigstore/rekor/pkg/generated/client"
+	"github.com/sigstore/rekor/pkg/generated/models"
+	"github.com/sigstore/rekor/pkg/log"
+)
+
+type serverResult struct {
+	Server string
+	Result interface{} `json:",omitempty"`
+	Error  string      `json:",omitempty"`
+}
+
+type publishOutput struct {
+	Servers []serverResult
+}
+
+func (p *publishOutput) String() string {
+	var b strings.Builder
+	for _, s := range p.Servers {
+		if s.Error != "" {
+			fmt.Fprintf(&b, "%v: %v\n", s.Server, s.Error)
+		} else {
+			fmt.Fprintf(&b, "%v: %v", s.Server, s.Result)
+		}
+	}
+	return b.String()
+}
+
+// failures summarizes the servers which returned an error, or returns nil if none did
+func (p *publishOutput) failures(action string) error {
+	var failed []string
+	for _, s := range p.Servers {
+		if s.Error != "" {
+			failed = append(failed, fmt.Sprintf("%v: %v", s.Server, s.Error))
+		}
+	}
+	if len(failed) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%v failed on %d of %d servers:\n  %v", action, len(failed), len(p.Servers), strings.Join(failed, "\n  "))
+}
+
+// addAnnotations adds annotations of the form key=value to the entry, replacing any it already has
+// with the same keys
+func addAnnotations(entry models.ProposedEntry, values []string) error {
+	annotations, err := parseAnnotations(values)
+	if err != nil || len(annotations) == 0 {
+		return err
+	}
+	merged := models.Annotations{}
+	for k, v := range entry.Annotations() {
+		merged[k] = v
+	}
+	for k, v := range annotations {
+		merged[k] = v
+	}
+	entry.SetAnnotations(merged)
+	return nil
+}
+
+// publishServers returns rekor_server followed by the servers given by --publish-to
+func publishServers() []string {
+	servers := []string{viper.GetString("rekor_server")}
+	for _, s := range viper.GetStringSlice("publish-to") {
+		if s != servers[0] {
+			servers = append(servers, s)
+		}
+	}
+	return servers
+}
+
+// publishEntry uploads the entry to rekor_server and to every server given by --publish-to, adding
+// the annotations given by --annotation to it. The upload to each server is limited by --timeout.
+func publishEntry(entry models.ProposedEntry) (interface{}, error) {
+	if err := addAnnotations(entry, viper.GetStringSlice("annotation")); err != nil {
+		return nil, err
+	}
+	servers := publishServers()
+	clients := make([]*genclient.Rekor, len(servers))
+	for i, s := range servers {
+		var err error
+		if clients[i], err = client.GetRekorClient(s); err != nil {
+			return nil, err
+		}
+	}
+
+	ctx, cancel := commandContext()
+	defer cancel()
+	if len(servers) == 1 {
+		return uploadEntry(ctx, clients[0], entry)
+	}
+
+	// every server checks the entry before it is added to any of them, so that an entry which one of
+	// them rejects is not published to the others
+	validated := forEachServer(ctx, servers, func(ctx context.Context, i int) (interface{}, error) {
+		return validateEntry(ctx, clients[i], entry)
+	})
+	if err := validated.failures("validating the entry"); err != nil {
+		return nil, err
+	}
+	if viper.GetBool("dry-run") {
+		return validated, nil
+	}
+
+	logEntries := make([]*models.LogEntryAnon, len(servers))
+	created := forEachServer(ctx, servers, func(ctx context.Context, i int) (interface{}, error) {
+		o, logEntry, err := createEntry(ctx, clients[i], entry)
+		logEntries[i] = logEntry
+		return o, err
+	})
+
+	if bundlePath := viper.GetString("bundle"); bundlePath != "" {
+		if err := writeCombinedBundle(ctx, bundlePath, servers, clients, created, logEntries); err != nil {
+			return nil, err
+		}
+	}
+	if err := created.failures("uploading the entry"); err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// forEachServer calls f for each server concurrently, with a context for requests to that server
+func forEachServer(ctx context.Context, servers []string, f func(ctx context.Context, i int) (interface{}, error)) *publishOutput {
+	output := &publishOutput{Servers: make([]serverResult, len(servers))}
+	var wg sync.WaitGroup
+	for i, s := range servers {
+		wg.Add(1)
+		go func(i int, s string) {
+			defer wg.Done()
+			output.Servers[i].Server = s
+			result, err := f(withRekorServer(ctx, s), i)
+			if err != nil {
+				output.Servers[i].Error = err.Error()
+				return
+			}
+			output.Servers[i].Result = result
+		}(i, s)
+	}
+	wg.Wait()
+	return output
+}
+
+// writeCombinedBundle writes a single bundle holding the entry of each server it was added to
+func writeCombinedBundle(ctx context.Context, path string, servers []string, clients []*genclient.Rekor, created *publishOutput, logEntries []*models.LogEntryAnon) error {
+	var combined *bundle.Bundle
+	for i, logEntry := range logEntries {
+		if logEntry == nil {
+			if created.Servers[i].Error == "" {
+				log.CliLogger.Warnf("WARNING: entry already existed on %v and is not included in the bundle", servers[i])
+			}
+			continue
+		}
+		o := created.Servers[i].Result.(*uploadCmdOutput)
+		b, err := entryBundle(withRekorServer(ctx, servers[i]), clients[i], o.UUID, *logEntry)
+		if err != nil {
+			return fmt.Errorf("%v: %w", servers[i], err)
+		}
+		if combined == nil {
+			combined = b
+		} else if err := combined.Merge(b); err != nil {
+			return fmt.Errorf("%v: %w", servers[i], err)
+		}
+	}
+	if combined == nil {
+		return nil
+	}
+	return writeBundle(path, combined)
+}
@@ -0,0 +1,113 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/viper"
+
+	"github.com/sigstore/rekor/cmd/rekor-cli/app/cache"
+	"github.com/sigstore/rekor/pkg/generated/models"
+	"github.com/sigstore/rekor/pkg/log"
+	"github.com/sigstore/rekor/pkg/util"
+)
+
+type rekorServerKey struct{}
+
+// withRekorServer returns a context for requests to a server other than rekor_server, such as those
+// given by upload --publish-to, so that its responses are cached apart from those of rekor_server
+func withRekorServer(ctx context.Context, serverURL string) context.Context {
+	return context.WithValue(ctx, rekorServerKey{}, serverURL)
+}
+
+// rekorServer returns the server that requests made with ctx are sent to
+func rekorServer(ctx context.Context) string {
+	if serverURL, ok := ctx.Value(rekorServerKey{}).(string); ok {
+		return serverURL
+	}
+	return viper.GetString("rekor_server")
+}
+
+// responseCache returns the cache of responses from the configured server, or nil if caching is
+// disabled or the cache can not be used
+func responseCache() *cache.Cache {
+	return serverResponseCache(viper.GetString("rekor_server"))
+}
+
+// responseCacheFor returns the cache of responses from the server that requests made with ctx are sent to
+func responseCacheFor(ctx context.Context) *cache.Cache {
+	return serverResponseCache(rekorServer(ctx))
+}
+
+func serverResponseCache(serverURL string) *cache.Cache {
+	if viper.GetBool("no-cache") {
+		return nil
+	}
+	c, err := cache.New(serverURL)
+	if err != nil {
+		log.CliLogger.Infof("Unable to use cache: %v", err)
+		return nil
+	}
+	return c
+}
+
+// storeInCache stores a value in the cache, which is merely an optimization, so failures are only logged
+func storeInCache(c *cache.Cache, key string, v interface{}) {
+	if err := c.Put(key, v); err != nil {
+		log.CliLogger.Infof("Unable to store %s in cache: %v", key, err)
+	}
+}
+
+func entryCacheKey(uuid string) string {
+	return "entries/" + strings.ToLower(uuid)
+}
+
+func logIndexCacheKey(logIndex int64) string {
+	return fmt.Sprintf("indexes/%d", logIndex)
+}
+
+// cachedEntryByUUID returns a previously verified entry from the cache
+func cachedEntryByUUID(c *cache.Cache, uuid string) (models.LogEntryAnon, bool) {
+	var e models.LogEntryAnon
+	if _, err := util.LeafHashFromEntryUUID(uuid); err != nil {
+		return e, false
+	}
+	return e, c.Get(entryCacheKey(uuid), &e)
+}
+
+// cachedEntryByIndex returns the UUID and a previously verified entry at a log index from the cache
+func cachedEntryByIndex(c *cache.Cache, logIndex int64) (string, models.LogEntryAnon, bool) {
+	var uuid string
+	if !c.Get(logIndexCacheKey(logIndex), &uuid) {
+		return "", models.LogEntryAnon{}, false
+	}
+	e, ok := cachedEntryByUUID(c, uuid)
+	if !ok || e.LogIndex == nil || *e.LogIndex != logIndex {
+		return "", models.LogEntryAnon{}, false
+	}
+	return uuid, e, true
+}
+
+// cacheEntry stores a verified entry by its UUID and log index
+func cacheEntry(c *cache.Cache, uuid string, e models.LogEntryAnon) {
+	storeInCache(c, entryCacheKey(uuid), e)
+	if e.LogIndex != nil {
+		storeInCache(c, logIndexCacheKey(*e.LogIndex), strings.ToLower(uuid))
+	}
+}
@@ -16,9 +16,11 @@
 package app
 
 import (
+	"context"
 	"fmt"
 	"runtime/debug"
 	"strings"
+	"time"
 
 	homedir "github.com/mitchellh/go-homedir"
 	"github.com/spf13/cobra"
@@ -26,17 +28,24 @@ import (
 	"github.com/spf13/viper"
 
 	"github.com/sigstore/rekor/pkg/api"
+	"github.com/sigstore/rekor/pkg/client"
 	"github.com/sigstore/rekor/pkg/log"
+	"github.com/sigstore/rekor/pkg/pki/pgp"
+	"github.com/sigstore/rekor/pkg/util"
 
 	// these imports are to call the packages' init methods
 	_ "github.com/sigstore/rekor/pkg/types/alpine/v0.0.1"
+	_ "github.com/sigstore/rekor/pkg/types/checksums/v0.0.1"
 	_ "github.com/sigstore/rekor/pkg/types/hashedrekord/v0.0.1"
 	_ "github.com/sigstore/rekor/pkg/types/helm/v0.0.1"
 	_ "github.com/sigstore/rekor/pkg/types/intoto/v0.0.1"
 	_ "github.com/sigstore/rekor/pkg/types/jar/v0.0.1"
+	_ "github.com/sigstore/rekor/pkg/types/oci/v0.0.1"
 	_ "github.com/sigstore/rekor/pkg/types/rekord/v0.0.1"
+	_ "github.com/sigstore/rekor/pkg/types/rekord/v0.0.2"
 	_ "github.com/sigstore/rekor/pkg/types/rfc3161/v0.0.1"
 	_ "github.com/sigstore/rekor/pkg/types/rpm/v0.0.1"
+	_ "github.com/sigstore/rekor/pkg/types/sbom/v0.0.1"
 	_ "github.com/sigstore/rekor/pkg/types/tuf/v0.0.1"
 )
 
@@ -45,27 +54,68 @@ var rootCmd = &cobra.Command{
 	Short: "Rekor CLI",
 	Long:  `Rekor command line interface tool`,
 	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
-		return initConfig(cmd)
+		if err := initConfig(cmd); err != nil {
+			return err
+		}
+		pgp.SetKeyPolicy(pgp.KeyPolicy{
+			AllowExpired: viper.GetBool("allow-expired-key"),
+			AllowRetired: viper.GetBool("allow-retired-key"),
+		})
+		// content given by URL is fetched without the TLS settings meant for the server
+		util.FetchClient = client.NewFetchClient()
+		return nil
 	},
 }
 
 // Execute runs the base CLI
 func Execute() {
+	if err := registerCompletions(rootCmd); err != nil {
+		log.CliLogger.Fatal(err)
+	}
 	if err := rootCmd.Execute(); err != nil {
 		log.CliLogger.Fatal(err)
 	}
 }
 
+// commandContext returns the context for the requests a command sends to the server, which is
+// cancelled once --timeout has passed
+func commandContext() (context.Context, context.CancelFunc) {
+	return contextWithTimeout(viper.GetDuration("timeout"))
+}
+
+// downloadContext returns the context for fetching content given by URL, which is limited by
+// --download-timeout instead so that large artifacts do not count towards --timeout
+func downloadContext() (context.Context, context.CancelFunc) {
+	return contextWithTimeout(viper.GetDuration("download-timeout"))
+}
+
+func contextWithTimeout(timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout > 0 {
+		return context.WithTimeout(context.Background(), timeout)
+	}
+	return context.WithCancel(context.Background())
+}
+
 func init() {
 	initializePFlagMap()
 	rootCmd.PersistentFlags().String("config", "", "config file (default is $HOME/.rekor.yaml)")
+	rootCmd.PersistentFlags().String("profile", "", "named profile of the config file whose server, CA certificate, token and format are used unless given as flags")
 	rootCmd.PersistentFlags().Bool("store_tree_state", true, "whether to store tree state in between invocations for additional verification")
+	rootCmd.PersistentFlags().Bool("no-cache", false, "do not read or store entries, proofs and tree heads in the cache under $HOME/.rekor/cache")
+	rootCmd.PersistentFlags().Duration("cache-ttl", time.Minute, "how long cached tree heads and public keys of the log are used before they are fetched again")
 
 	rootCmd.PersistentFlags().Var(NewFlagValue(urlFlag, "https://rekor.sigstore.dev"), "rekor_server", "Server address:port")
 	rootCmd.PersistentFlags().Var(NewFlagValue(formatFlag, "default"), "format", "Command output format")
-	rootCmd.PersistentFlags().Var(NewFlagValue(timeoutFlag, "30s"), "timeout", "HTTP timeout")
+	rootCmd.PersistentFlags().Var(NewFlagValue(timeoutFlag, "2m"), "timeout", "time limit for all requests a command sends to the server, or for each request of watch, which runs until interrupted; 0 for no limit")
+	rootCmd.PersistentFlags().Var(NewFlagValue(timeoutFlag, "10m"), "download-timeout", "time limit for fetching the artifacts, entries, signatures and keys given by URL; 0 for no limit")
 
 	rootCmd.PersistentFlags().String("api-key", "", "API key for rekor.sigstore.dev")
+	rootCmd.PersistentFlags().String("auth-token", "", "API token or OIDC identity token sent as a bearer token, for servers which require authentication to add entries")
+	rootCmd.PersistentFlags().String("request-compression", "", "compress request bodies with gzip or zstd; the server must support compressed requests")
+	rootCmd.PersistentFlags().String("cacert", "", "PEM file of CA certificates to trust for the rekor server in addition to those of the system, e.g. for a server with an internally signed certificate; not used for content fetched by URL")
+	rootCmd.PersistentFlags().Bool("allow-expired-key", false, "accept PGP signatures made after the signing key expired")
+	rootCmd.PersistentFlags().Bool("allow-retired-key", false, "accept PGP signatures made before the signing key was revoked as superseded or no longer used; keys revoked as compromised are always rejected")
+	rootCmd.PersistentFlags().Bool("insecure-skip-verify", false, "do not verify the TLS certificate of the rekor server; this is insecure and only meant for testing, and does not apply to content fetched by URL")
 
 	// these are bound here and not in PreRun so that all child commands can use them
 	if err := viper.BindPFlags(rootCmd.PersistentFlags()); err != nil {
@@ -107,13 +157,29 @@ func initConfig(cmd *cobra.Command) error {
 		}
 	}
 
+	found, err := readConfig()
+	if err != nil {
+		return err
+	}
+	if err := applyProfile(viper.GetViper(), cmd.Root().PersistentFlags(), cmd.Flags(), viper.GetString("profile")); err != nil {
+		return err
+	}
+	if found && viper.GetString("format") == "default" {
+		log.CliLogger.Infof("Using config file:", viper.ConfigFileUsed())
+	}
+
+	return nil
+}
+
+// readConfig reads the config file given by --config, or $HOME/.rekor.yaml if it exists
+func readConfig() (bool, error) {
 	if viper.GetString("config") != "" {
 		viper.SetConfigFile(viper.GetString("config"))
 	} else {
 		// Find home directory.
 		home, err := homedir.Dir()
 		if err != nil {
-			return err
+			return false, err
 		}
 
 		viper.AddConfigPath(home)
@@ -123,12 +189,10 @@ func initConfig(cmd *cobra.Command) error {
 	if err := viper.ReadInConfig(); err != nil {
 		switch err.(type) {
 		case viper.ConfigFileNotFoundError:
+			return false, nil
 		default:
-			return err
+			return false, err
 		}
-	} else if viper.GetString("format") == "default" {
-		log.CliLogger.Infof("Using config file:", viper.ConfigFileUsed())
 	}
-
-	return nil
+	return true, nil
 }
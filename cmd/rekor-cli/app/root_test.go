@@ -0,0 +1,80 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package app
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func TestTimeoutFlags(t *testing.T) {
+	for _, tt := range []struct {
+		name          string
+		args          []string
+		expectSuccess bool
+		// the time limits of commandContext and downloadContext, or 0 for none
+		timeout, downloadTimeout time.Duration
+	}{
+		{name: "defaults", expectSuccess: true, timeout: 2 * time.Minute, downloadTimeout: 10 * time.Minute},
+		{name: "separate limits", args: []string{"--timeout", "5s", "--download-timeout", "1h"}, expectSuccess: true, timeout: 5 * time.Second, downloadTimeout: time.Hour},
+		{name: "no limits", args: []string{"--timeout", "0", "--download-timeout", "0s"}, expectSuccess: true},
+		{name: "negative timeout", args: []string{"--timeout", "-1s"}},
+		{name: "negative download timeout", args: []string{"--download-timeout", "-5m"}},
+		{name: "invalid timeout", args: []string{"--timeout", "soon"}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			blankCmd := &cobra.Command{}
+			blankCmd.Flags().Var(NewFlagValue(timeoutFlag, "2m"), "timeout", "")
+			blankCmd.Flags().Var(NewFlagValue(timeoutFlag, "10m"), "download-timeout", "")
+			if err := blankCmd.ParseFlags(tt.args); (err == nil) != tt.expectSuccess {
+				t.Fatalf("unexpected result parsing %v: %v", tt.args, err)
+			}
+			if !tt.expectSuccess {
+				return
+			}
+			if err := viper.BindPFlags(blankCmd.Flags()); err != nil {
+				t.Fatal(err)
+			}
+
+			for _, c := range []struct {
+				name    string
+				context func() (context.Context, context.CancelFunc)
+				limit   time.Duration
+			}{
+				{name: "command", context: commandContext, limit: tt.timeout},
+				{name: "download", context: downloadContext, limit: tt.downloadTimeout},
+			} {
+				start := time.Now()
+				ctx, cancel := c.context()
+				deadline, ok := ctx.Deadline()
+				if ok != (c.limit > 0) {
+					t.Errorf("%s context has a deadline: %v, want %v", c.name, ok, c.limit > 0)
+				} else if ok && (deadline.Before(start.Add(c.limit)) || deadline.After(time.Now().Add(c.limit))) {
+					t.Errorf("%s context deadline %v is not %v from now", c.name, deadline, c.limit)
+				}
+				// contexts without a deadline can still be cancelled
+				cancel()
+				if ctx.Err() != context.Canceled {
+					t.Errorf("%s context not cancelled: %v", c.name, ctx.Err())
+				}
+			}
+		})
+	}
+}
@@ -16,7 +16,6 @@
 package app
 
 import (
-	"crypto/sha256"
 	"encoding/hex"
 	"errors"
 	"fmt"
@@ -25,7 +24,9 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/go-openapi/strfmt"
 	"github.com/go-openapi/swag"
@@ -37,6 +38,7 @@ import (
 	"github.com/sigstore/rekor/pkg/generated/client/index"
 	"github.com/sigstore/rekor/pkg/generated/models"
 	"github.com/sigstore/rekor/pkg/log"
+	"github.com/sigstore/rekor/pkg/util"
 )
 
 type searchCmdOutput struct {
@@ -50,13 +52,31 @@ func (s *searchCmdOutput) String() string {
 func addSearchPFlags(cmd *cobra.Command) error {
 	cmd.Flags().Var(NewFlagValue(pkiFormatFlag, ""), "pki-format", "format of the signature and/or public key")
 
-	cmd.Flags().Var(NewFlagValue(fileOrURLFlag, ""), "public-key", "path or URL to public key file")
+	cmd.Flags().Var(NewFlagValue(publicKeyFlag, ""), "public-key", "path or URL to public key file, or hkps://keyserver[/query] or email address to fetch the key from a keyserver or Web Key Directory (requires --key-fingerprint)")
+
+	cmd.Flags().Var(NewFlagSliceValue(fprFlag), "key-fingerprint", "fingerprint of a PGP key fetched from a keyserver or Web Key Directory, which must match its primary key; may be repeated")
 
 	cmd.Flags().Var(NewFlagValue(fileOrURLFlag, ""), "artifact", "path or URL to artifact file")
 
-	cmd.Flags().Var(NewFlagValue(shaFlag, ""), "sha", "the SHA256 or SHA1 sum of the artifact")
+	cmd.Flags().Var(NewFlagValue(digestFlag, ""), "sha", "the SHA256, SHA384, SHA512 or SHA1 sum of the artifact, optionally prefixed by its algorithm as in sha512:<hex>")
+
+	cmd.Flags().Var(NewFlagValue(hashAlgFlag, ""), "hash-algorithm", "hash algorithm used to compute the digest of --artifact, which has to match the algorithm of the entries searched for")
 
 	cmd.Flags().Var(NewFlagValue(emailFlag, ""), "email", "email associated with the public key's subject")
+
+	cmd.Flags().Var(NewFlagValue(commitFlag, ""), "commit", "ID of a git commit, to find the artifacts built from it according to their git.commit annotation or SLSA provenance")
+
+	cmd.Flags().Var(NewFlagValue(repoFlag, ""), "repository", "URL of a source repository, to find the artifacts built from it according to their git.repository annotation or SLSA provenance")
+
+	cmd.Flags().Var(NewFlagValue(artURLFlag, ""), "artifact-url", "URL an artifact was uploaded from, to find its entries without downloading it; the scheme and host are matched case insensitively and default ports are ignored")
+
+	cmd.Flags().Var(NewFlagSliceValue(annotFlag), "annotation", "annotation of the form key=value that entries were uploaded with; only keys indexed by the server can be searched for; may be repeated")
+
+	cmd.Flags().Var(NewFlagValue(timeFlag, ""), "since", "only list entries integrated into the log at or after this RFC 3339 timestamp or @<Unix time>; combined with other criteria, only their matches in the time range are listed")
+
+	cmd.Flags().Var(NewFlagValue(timeFlag, ""), "until", "only list entries integrated into the log at or before this RFC 3339 timestamp or @<Unix time>")
+
+	cmd.Flags().Int("limit", 0, "maximum number of entries to list; results are fetched page by page until the limit is reached, or all of them if 0")
 	return nil
 }
 
@@ -66,9 +86,28 @@ func validateSearchPFlags() error {
 	publicKey := viper.GetString("public-key")
 	sha := viper.GetString("sha")
 	email := viper.GetString("email")
+	annotations := viper.GetStringSlice("annotation")
+	commit := viper.GetString("commit")
+	repo := viper.GetString("repository")
+	artifactURL := viper.GetString("artifact-url")
+	since := viper.GetString("since")
+	until := viper.GetString("until")
 
-	if artifactStr == "" && publicKey == "" && sha == "" && email == "" {
-		return errors.New("either 'sha' or 'artifact' or 'public-key' or 'email' must be specified")
+	if artifactStr == "" && publicKey == "" && sha == "" && email == "" && len(annotations) == 0 && commit == "" && repo == "" && artifactURL == "" && since == "" && until == "" {
+		return errors.New("either 'sha' or 'artifact' or 'artifact-url' or 'public-key' or 'email' or 'annotation' or 'commit' or 'repository' or 'since' or 'until' must be specified")
+	}
+	if since != "" && until != "" {
+		sinceTime, err := parseTime(since)
+		if err != nil {
+			return err
+		}
+		untilTime, err := parseTime(until)
+		if err != nil {
+			return err
+		}
+		if untilTime.Before(sinceTime) {
+			return errors.New("'until' must not be before 'since'")
+		}
 	}
 	if publicKey != "" {
 		if viper.GetString("pki-format") == "" {
@@ -82,7 +121,12 @@ func validateSearchPFlags() error {
 var searchCmd = &cobra.Command{
 	Use:   "search",
 	Short: "Rekor search command",
-	Long:  `Searches the Rekor index to find entries by sha, artifact,  public key, or e-mail`,
+	Long: `Searches the Rekor index to find entries by sha, artifact, the URL an artifact was uploaded from, public key, e-mail, annotation, or the commit or repository artifacts were built from.
+
+With --since and --until, only entries integrated into the log in that time range are listed, e.g. everything
+a key signed during the window in which it was compromised:
+
+  rekor-cli search --public-key key.pem --pki-format x509 --since 2024-01-01 --until 2024-02-01`,
 	PreRun: func(cmd *cobra.Command, args []string) {
 		// these are bound here so that they are not overwritten by other commands
 		if err := viper.BindPFlags(cmd.Flags()); err != nil {
@@ -108,21 +152,23 @@ var searchCmd = &cobra.Command{
 		artifactStr := viper.GetString("artifact")
 		sha := viper.GetString("sha")
 		if sha != "" {
-			var prefix string
-			if !strings.HasPrefix(sha, "sha256:") && !strings.HasPrefix(sha, "sha1:") {
-				if len(sha) == 40 {
-					prefix = "sha1:"
-				} else {
-					prefix = "sha256:"
-				}
-			}
-			params.Query.Hash = fmt.Sprintf("%v%v", prefix, sha)
+			params.Query.Hash = qualifySHA(sha)
 		} else if artifactStr != "" {
-			hasher := sha256.New()
+			hashFunc, err := util.HashFunc(viper.GetString("hash-algorithm"))
+			if err != nil {
+				return nil, err
+			}
+			hasher := hashFunc.New()
 			var tee io.Reader
 			if isURL(artifactStr) {
-				/* #nosec G107 */
-				resp, err := http.Get(artifactStr)
+				httpClient := client.NewFetchClient()
+				downloadCtx, cancel := downloadContext()
+				defer cancel()
+				req, err := http.NewRequestWithContext(downloadCtx, http.MethodGet, artifactStr, nil)
+				if err != nil {
+					return nil, err
+				}
+				resp, err := httpClient.Do(req)
 				if err != nil {
 					return nil, fmt.Errorf("error fetching '%v': %w", artifactStr, err)
 				}
@@ -146,7 +192,7 @@ var searchCmd = &cobra.Command{
 			}
 
 			hashVal := strings.ToLower(hex.EncodeToString(hasher.Sum(nil)))
-			params.Query.Hash = "sha256:" + hashVal
+			params.Query.Hash = strings.ToLower(viper.GetString("hash-algorithm")) + ":" + hashVal
 		}
 
 		publicKeyStr := viper.GetString("public-key")
@@ -170,6 +216,14 @@ var searchCmd = &cobra.Command{
 			publicKeyStr := viper.GetString("public-key")
 			if isURL(publicKeyStr) {
 				params.Query.PublicKey.URL = strfmt.URI(publicKeyStr)
+			} else if isRemotePublicKey(publicKeyStr) {
+				downloadCtx, cancel := downloadContext()
+				defer cancel()
+				keyBytes, err := fetchRemotePublicKey(downloadCtx, publicKeyStr)
+				if err != nil {
+					return nil, err
+				}
+				params.Query.PublicKey.Content = strfmt.Base64(keyBytes)
 			} else {
 				keyBytes, err := ioutil.ReadFile(filepath.Clean(publicKeyStr))
 				if err != nil {
@@ -183,7 +237,30 @@ var searchCmd = &cobra.Command{
 		if emailStr != "" {
 			params.Query.Email = strfmt.Email(emailStr)
 		}
-		resp, err := rekorClient.Index.SearchIndex(params)
+
+		annotations, err := parseAnnotations(viper.GetStringSlice("annotation"))
+		if err != nil {
+			return nil, err
+		}
+		params.Query.Annotations = annotations
+		params.Query.Commit = viper.GetString("commit")
+		params.Query.Repository = viper.GetString("repository")
+		params.Query.ArtifactURL = viper.GetString("artifact-url")
+		for flag, field := range map[string]*int64{"since": &params.Query.IntegratedSince, "until": &params.Query.IntegratedUntil} {
+			if v := viper.GetString(flag); v != "" {
+				t, err := parseTime(v)
+				if err != nil {
+					return nil, err
+				}
+				*field = t.Unix()
+			}
+		}
+
+		// the artifact is hashed first so that fetching it does not count towards --timeout
+		ctx, cancel := commandContext()
+		defer cancel()
+		params.SetContext(ctx)
+		uuids, err := client.SearchIndex(rekorClient, params, viper.GetInt("limit"))
 		if err != nil {
 			switch t := err.(type) {
 			case *index.SearchIndexDefault:
@@ -196,14 +273,14 @@ var searchCmd = &cobra.Command{
 			}
 		}
 
-		if len(resp.Payload) == 0 {
+		if len(uuids) == 0 {
 			return nil, fmt.Errorf("no matching entries found")
 		}
 
 		fmt.Fprintln(os.Stderr, "Found matching entries (listed by UUID):")
 
 		return &searchCmdOutput{
-			UUIDs: resp.GetPayload(),
+			UUIDs: uuids,
 		}, nil
 	}),
 }
@@ -216,3 +293,29 @@ func init() {
 
 	rootCmd.AddCommand(searchCmd)
 }
+
+// parseTime parses a point in time given as an RFC 3339 timestamp or as @ followed by a Unix time in
+// seconds; other forms are rejected, so that e.g. a bare year is not taken for seconds since 1970
+func parseTime(v string) (time.Time, error) {
+	if strings.HasPrefix(v, "@") {
+		secs, err := strconv.ParseInt(strings.TrimPrefix(v, "@"), 10, 64)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("%q is not @ followed by a Unix time in seconds", v)
+		}
+		return time.Unix(secs, 0), nil
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%q is neither an RFC 3339 timestamp such as 2024-01-01T00:00:00Z nor a Unix time such as @1704067200", v)
+	}
+	return t, nil
+}
+
+// qualifySHA prefixes a bare digest with its algorithm, as stored in the index
+func qualifySHA(sha string) string {
+	qualified, err := util.NormalizeDigest(sha)
+	if err != nil {
+		return sha
+	}
+	return qualified
+}
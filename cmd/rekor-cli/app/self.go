@@ -0,0 +1,418 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package app
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/go-openapi/swag"
+	"github.com/sigstore/sigstore/pkg/cryptoutils"
+	"github.com/sigstore/sigstore/pkg/signature"
+	"github.com/sigstore/sigstore/pkg/signature/options"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/sigstore/rekor/cmd/rekor-cli/app/format"
+	"github.com/sigstore/rekor/pkg/api"
+	"github.com/sigstore/rekor/pkg/client"
+	genclient "github.com/sigstore/rekor/pkg/generated/client"
+	"github.com/sigstore/rekor/pkg/generated/client/entries"
+	"github.com/sigstore/rekor/pkg/generated/client/index"
+	"github.com/sigstore/rekor/pkg/generated/models"
+	"github.com/sigstore/rekor/pkg/log"
+	"github.com/sigstore/rekor/pkg/signer"
+	"github.com/sigstore/rekor/pkg/types"
+	hashedrekord_v001 "github.com/sigstore/rekor/pkg/types/hashedrekord/v0.0.1"
+	"github.com/sigstore/rekor/pkg/util"
+	"github.com/sigstore/rekor/pkg/verify"
+)
+
+const (
+	// selfVersionAnnotation and selfPlatformAnnotation record the build of rekor-cli an entry
+	// uploaded by publish-self is for
+	selfVersionAnnotation  = "rekor-cli.version"
+	selfPlatformAnnotation = "rekor-cli.platform"
+	// keyPasswordEnv holds the password of an encrypted private key given by --key
+	keyPasswordEnv = "REKOR_KEY_PASSWORD"
+)
+
+// selfDigest returns the path and the SHA256 digest of the running rekor-cli binary
+func selfDigest() (string, []byte, error) {
+	path, err := os.Executable()
+	if err != nil {
+		return "", nil, fmt.Errorf("locating the rekor-cli binary: %w", err)
+	}
+	if path, err = filepath.EvalSymlinks(path); err != nil {
+		return "", nil, fmt.Errorf("locating the rekor-cli binary: %w", err)
+	}
+	f, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return "", nil, err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", nil, fmt.Errorf("hashing %v: %w", path, err)
+	}
+	return path, h.Sum(nil), nil
+}
+
+// loadSigner loads the signer given by --key, either a KMS reference such as gcpkms://... or the path
+// of a PEM encoded private key, which is decrypted with $REKOR_KEY_PASSWORD if needed
+func loadSigner(ctx context.Context, key string) (signature.Signer, error) {
+	if strings.Contains(key, "://") {
+		return signer.New(ctx, key)
+	}
+	pemBytes, err := ioutil.ReadFile(filepath.Clean(key))
+	if err != nil {
+		return nil, fmt.Errorf("reading private key: %w", err)
+	}
+	priv, err := cryptoutils.UnmarshalPEMToPrivateKey(pemBytes, func(bool) ([]byte, error) {
+		return []byte(os.Getenv(keyPasswordEnv)), nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("parsing private key: %w", err)
+	}
+	return signature.LoadSigner(priv, crypto.SHA256)
+}
+
+// loadReleaseSigner loads the signer given by --key as loadSigner does, rejecting keys which can not
+// sign hashedrekord entries
+func loadReleaseSigner(ctx context.Context, key string) (signature.Signer, error) {
+	s, err := loadSigner(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	pub, err := s.PublicKey()
+	if err != nil {
+		return nil, err
+	}
+	// hashedrekord entries are signatures over the digest of the artifact, which ed25519 can not make
+	if _, ok := pub.(ed25519.PublicKey); ok {
+		return nil, errors.New("ed25519 keys can not sign hashedrekord entries; use an ECDSA or RSA key")
+	}
+	return s, nil
+}
+
+// selfEntry returns the hashedrekord entry signing the binary with digest, annotated with the build of
+// rekor-cli
+func selfEntry(ctx context.Context, s signature.Signer, binary io.Reader, digest []byte) (models.ProposedEntry, error) {
+	sig, err := s.SignMessage(binary, options.WithContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("signing the rekor-cli binary: %w", err)
+	}
+	pub, err := s.PublicKey()
+	if err != nil {
+		return nil, err
+	}
+	pubPEM, err := cryptoutils.MarshalPublicKeyToPEM(pub)
+	if err != nil {
+		return nil, err
+	}
+	entry, err := types.NewProposedEntry(ctx, "hashedrekord", "", types.ArtifactProperties{
+		ArtifactHash:   hex.EncodeToString(digest),
+		SignatureBytes: sig,
+		PublicKeyBytes: pubPEM,
+		PKIFormat:      "x509",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	v := VersionInfo()
+	annotations := models.Annotations{
+		selfVersionAnnotation:  v.GitVersion,
+		selfPlatformAnnotation: v.Platform,
+	}
+	if _, err := util.NormalizeGitCommit(api.GitCommit); err == nil {
+		annotations[types.CommitAnnotation] = api.GitCommit
+	}
+	entry.SetAnnotations(annotations)
+	return entry, nil
+}
+
+// publishSelfCmd represents the publish-self command
+var publishSelfCmd = &cobra.Command{
+	Use:   "publish-self",
+	Short: "Sign the running rekor-cli binary and upload the signature to the log",
+	Long: `Signs the SHA256 digest of the running rekor-cli binary with --key and uploads it to the log as
+a hashedrekord entry annotated with the version and platform of the build, so that the binary can
+later be checked with verify-self.`,
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		// these are bound here so that they are not overwritten by other commands
+		if err := viper.BindPFlags(cmd.Flags()); err != nil {
+			return fmt.Errorf("error initializing cmd line args: %s", err)
+		}
+		if viper.GetString("key") == "" {
+			return errors.New("--key must be specified")
+		}
+		return nil
+	},
+	Run: format.WrapCmd(func(args []string) (interface{}, error) {
+		ctx, cancel := commandContext()
+		defer cancel()
+		s, err := loadReleaseSigner(ctx, viper.GetString("key"))
+		if err != nil {
+			return nil, err
+		}
+		path, digest, err := selfDigest()
+		if err != nil {
+			return nil, err
+		}
+		binary, err := os.Open(filepath.Clean(path))
+		if err != nil {
+			return nil, err
+		}
+		defer binary.Close()
+		entry, err := selfEntry(ctx, s, binary, digest)
+		if err != nil {
+			return nil, err
+		}
+		log.CliLogger.Infof("Publishing signature of %v with digest sha256:%x", path, digest)
+		return publishEntry(entry)
+	}),
+}
+
+type verifySelfCmdOutput struct {
+	Path           string
+	Digest         string
+	UUID           string
+	Index          int64
+	IntegratedTime int64
+	Annotations    models.Annotations `json:",omitempty"`
+}
+
+func (v *verifySelfCmdOutput) String() string {
+	s := fmt.Sprintf("Verified %v with digest %v\n", v.Path, v.Digest)
+	s += fmt.Sprintf("Signed by the release key in entry %v at index %d, integrated at %v\n",
+		v.UUID, v.Index, time.Unix(v.IntegratedTime, 0).UTC().Format(time.RFC3339))
+	for _, k := range types.AnnotationKeys(v.Annotations) {
+		s += fmt.Sprintf("Annotation: %s=%s\n", k, v.Annotations[k])
+	}
+	return s
+}
+
+// checkSelfEntry checks that a log entry is a hashedrekord signature over digest made with the release key
+func checkSelfEntry(eimpl types.EntryImpl, digest []byte, releaseKey crypto.PublicKey) error {
+	e, ok := eimpl.(*hashedrekord_v001.V001Entry)
+	if !ok {
+		return fmt.Errorf("entry is not a hashedrekord v%v entry", hashedrekord_v001.APIVERSION)
+	}
+	obj := e.HashedRekordObj
+	if obj.Data == nil || obj.Data.Hash == nil || obj.Signature == nil || obj.Signature.PublicKey == nil {
+		return errors.New("entry is missing its digest or signature")
+	}
+	if swag.StringValue(obj.Data.Hash.Algorithm) != models.HashedrekordV001SchemaDataHashAlgorithmSha256 ||
+		!strings.EqualFold(swag.StringValue(obj.Data.Hash.Value), hex.EncodeToString(digest)) {
+		return errors.New("entry is for another artifact")
+	}
+	pub, err := cryptoutils.UnmarshalPEMToPublicKey(obj.Signature.PublicKey.Content)
+	if err != nil {
+		return fmt.Errorf("parsing public key of entry: %w", err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return err
+	}
+	releaseDER, err := x509.MarshalPKIXPublicKey(releaseKey)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(der, releaseDER) {
+		return errors.New("entry is not signed by the release key")
+	}
+	// the signature is checked here rather than relying on the server having checked it, so that the
+	// binary is not trusted on the word of the log alone
+	verifier, err := signature.LoadVerifier(pub, crypto.SHA256)
+	if err != nil {
+		return err
+	}
+	if err := verifier.VerifySignature(bytes.NewReader(obj.Signature.Content), nil, options.WithDigest(digest)); err != nil {
+		return fmt.Errorf("invalid signature in entry: %w", err)
+	}
+	return nil
+}
+
+// findSelfEntry returns the first entry of the log signing digest with the release key, verifying the
+// signed entry timestamp and inclusion proof of every candidate
+func findSelfEntry(ctx context.Context, rekorClient *genclient.Rekor, digest []byte, releaseKey crypto.PublicKey) (string, models.LogEntryAnon, models.Annotations, error) {
+	releasePEM, err := cryptoutils.MarshalPublicKeyToPEM(releaseKey)
+	if err != nil {
+		return "", models.LogEntryAnon{}, nil, err
+	}
+	params := index.NewSearchIndexParamsWithContext(ctx)
+	params.SetTimeout(viper.GetDuration("timeout"))
+	params.Query = &models.SearchIndex{
+		Hash: "sha256:" + hex.EncodeToString(digest),
+		PublicKey: &models.SearchIndexPublicKey{
+			Format:  swag.String(models.SearchIndexPublicKeyFormatX509),
+			Content: strfmt.Base64(releasePEM),
+		},
+	}
+	uuids, err := client.SearchIndex(rekorClient, params, 0)
+	if err != nil {
+		return "", models.LogEntryAnon{}, nil, err
+	}
+
+	var found struct {
+		uuid        string
+		entry       models.LogEntryAnon
+		annotations models.Annotations
+	}
+	var lastErr error
+	c := responseCache()
+	for _, uuid := range uuids {
+		e, ok := cachedEntryByUUID(c, uuid)
+		if !ok {
+			p := entries.NewGetLogEntryByUUIDParamsWithContext(ctx)
+			p.SetTimeout(viper.GetDuration("timeout"))
+			p.EntryUUID = uuid
+			resp, err := rekorClient.Entries.GetLogEntryByUUID(p)
+			if err != nil {
+				return "", models.LogEntryAnon{}, nil, err
+			}
+			for k, v := range resp.Payload {
+				if strings.EqualFold(k, uuid) {
+					e, ok = v, true
+				}
+			}
+			if !ok {
+				lastErr = fmt.Errorf("entry %v was not returned by the server", uuid)
+				continue
+			}
+		}
+		if err := verifySelfCandidate(ctx, rekorClient, uuid, e, digest, releaseKey); err != nil {
+			lastErr = fmt.Errorf("entry %v: %w", uuid, err)
+			continue
+		}
+		cacheEntry(c, uuid, e)
+		if found.uuid == "" || swag.Int64Value(e.LogIndex) < swag.Int64Value(found.entry.LogIndex) {
+			_, annotations, _ := decodeEntryBody(e)
+			found.uuid, found.entry, found.annotations = uuid, e, annotations
+		}
+	}
+	if found.uuid == "" {
+		if lastErr != nil {
+			return "", models.LogEntryAnon{}, nil, fmt.Errorf("no valid entry signs this binary with the release key: %w", lastErr)
+		}
+		return "", models.LogEntryAnon{}, nil, errors.New("no entry of the log signs this binary with the release key")
+	}
+	return found.uuid, found.entry, found.annotations, nil
+}
+
+// verifySelfCandidate verifies that an entry returned when searching for the binary was added to the log
+// and signs digest with the release key
+func verifySelfCandidate(ctx context.Context, rekorClient *genclient.Rekor, uuid string, e models.LogEntryAnon, digest []byte, releaseKey crypto.PublicKey) error {
+	if verified, err := verifyLogEntry(ctx, rekorClient, e); err != nil || !verified {
+		return fmt.Errorf("unable to verify entry was added to log %w", err)
+	}
+	body, err := verify.Body(uuid, e)
+	if err != nil {
+		return err
+	}
+	if e.Verification.InclusionProof != nil {
+		if err := verifyInclusionProof(body, e.Verification.InclusionProof); err != nil {
+			return err
+		}
+	}
+	eimpl, _, err := decodeEntryBody(e)
+	if err != nil {
+		return err
+	}
+	if err := checkSelfEntry(eimpl, digest, releaseKey); err != nil {
+		return err
+	}
+	return updateTreeStateFromEntry(ctx, rekorClient, viper.GetString("rekor_server"), e)
+}
+
+// verifySelfCmd represents the verify-self command
+var verifySelfCmd = &cobra.Command{
+	Use:   "verify-self",
+	Short: "Verify that the running rekor-cli binary was signed with the release key",
+	Long: `Looks up the SHA256 digest of the running rekor-cli binary in the log and verifies that an entry
+uploaded with publish-self signs it with the release key given by --public-key.`,
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		// these are bound here so that they are not overwritten by other commands
+		if err := viper.BindPFlags(cmd.Flags()); err != nil {
+			return fmt.Errorf("error initializing cmd line args: %s", err)
+		}
+		if viper.GetString("public-key") == "" {
+			return errors.New("--public-key must be specified")
+		}
+		return nil
+	},
+	Run: format.WrapCmd(func(args []string) (interface{}, error) {
+		rekorClient, err := client.GetRekorClient(viper.GetString("rekor_server"))
+		if err != nil {
+			return nil, err
+		}
+		pemBytes, err := ioutil.ReadFile(filepath.Clean(viper.GetString("public-key")))
+		if err != nil {
+			return nil, fmt.Errorf("reading release key: %w", err)
+		}
+		releaseKey, err := cryptoutils.UnmarshalPEMToPublicKey(pemBytes)
+		if err != nil {
+			return nil, fmt.Errorf("parsing release key: %w", err)
+		}
+		path, digest, err := selfDigest()
+		if err != nil {
+			return nil, err
+		}
+
+		ctx, cancel := commandContext()
+		defer cancel()
+		uuid, e, annotations, err := findSelfEntry(ctx, rekorClient, digest, releaseKey)
+		if err != nil {
+			return nil, err
+		}
+		return &verifySelfCmdOutput{
+			Path:           path,
+			Digest:         fmt.Sprintf("sha256:%x", digest),
+			UUID:           uuid,
+			Index:          swag.Int64Value(e.LogIndex),
+			IntegratedTime: swag.Int64Value(e.IntegratedTime),
+			Annotations:    annotations,
+		}, nil
+	}),
+}
+
+func init() {
+	initializePFlagMap()
+	publishSelfCmd.Flags().String("key", "", "path to the PEM encoded ECDSA or RSA private key to sign the binary with, or a KMS reference such as gcpkms://...; an encrypted key is decrypted with $"+keyPasswordEnv)
+	publishSelfCmd.Flags().Var(NewFlagSliceValue(urlFlag), "publish-to", "additional rekor server to publish the entry to, after every server has validated it; may be repeated")
+	publishSelfCmd.Flags().Var(NewFlagSliceValue(annotFlag), "annotation", "additional annotation of the form key=value to attach to the entry; may be repeated")
+	publishSelfCmd.Flags().String("bundle", "", "path to a file to write the new entry to in the Sigstore bundle format")
+	publishSelfCmd.Flags().Bool("dry-run", false, "sign the binary and check that the server would accept the entry, without uploading it")
+	rootCmd.AddCommand(publishSelfCmd)
+
+	verifySelfCmd.Flags().Var(NewFlagValue(fileFlag, ""), "public-key", "path to the PEM encoded public key the binary must have been signed with")
+	rootCmd.AddCommand(verifySelfCmd)
+}
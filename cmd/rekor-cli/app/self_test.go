@@ -0,0 +1,75 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package app
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"testing"
+
+	"github.com/sigstore/sigstore/pkg/signature"
+
+	"github.com/sigstore/rekor/pkg/types"
+	hashedrekord_v001 "github.com/sigstore/rekor/pkg/types/hashedrekord/v0.0.1"
+)
+
+func TestSelfEntry(t *testing.T) {
+	ctx := context.Background()
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	s, _ := signature.LoadSigner(key, crypto.SHA256)
+	otherKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	binary := []byte("rekor-cli binary")
+	digest := sha256.Sum256(binary)
+	pe, err := selfEntry(ctx, s, bytes.NewReader(binary), digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a := pe.Annotations(); a[selfVersionAnnotation] == "" || a[selfPlatformAnnotation] == "" {
+		t.Fatalf("entry is missing the build of rekor-cli: %v", a)
+	}
+	eimpl, err := types.NewEntry(pe)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := checkSelfEntry(eimpl, digest[:], key.Public()); err != nil {
+		t.Fatalf("checkSelfEntry() = %v", err)
+	}
+
+	otherDigest := sha256.Sum256([]byte("another binary"))
+	if err := checkSelfEntry(eimpl, otherDigest[:], key.Public()); err == nil {
+		t.Error("expected entry for another binary to be rejected")
+	}
+	if err := checkSelfEntry(eimpl, digest[:], otherKey.Public()); err == nil {
+		t.Error("expected entry signed with another key to be rejected")
+	}
+
+	// an entry claiming the digest of the binary with a signature over something else is rejected
+	forged := *eimpl.(*hashedrekord_v001.V001Entry)
+	forgedSig := *forged.HashedRekordObj.Signature
+	if forgedSig.Content, err = s.SignMessage(bytes.NewReader([]byte("something else"))); err != nil {
+		t.Fatal(err)
+	}
+	forged.HashedRekordObj.Signature = &forgedSig
+	if err := checkSelfEntry(&forged, digest[:], key.Public()); err == nil {
+		t.Error("expected entry with an invalid signature to be rejected")
+	}
+}
@@ -0,0 +1,241 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package app
+
+import (
+	"bytes"
+	"crypto"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/in-toto/in-toto-golang/in_toto"
+	"github.com/secure-systems-lab/go-securesystemslib/dsse"
+	"github.com/sigstore/sigstore/pkg/cryptoutils"
+	"github.com/sigstore/sigstore/pkg/signature"
+	"github.com/sigstore/sigstore/pkg/signature/options"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/sigstore/rekor/cmd/rekor-cli/app/format"
+)
+
+type signCmdOutput struct {
+	Output    string
+	PublicKey string `json:",omitempty"`
+	// Upload is the upload command which logs the signature
+	Upload string
+}
+
+func (s *signCmdOutput) String() string {
+	str := fmt.Sprintf("Wrote %v\n", s.Output)
+	if s.PublicKey != "" {
+		str += fmt.Sprintf("Wrote public key to %v\n", s.PublicKey)
+	}
+	str += fmt.Sprintf("Upload it with:\n\t%v\n", s.Upload)
+	return str
+}
+
+// dsseSigner signs and verifies the pre-authentication encoding of DSSE envelopes the way the intoto
+// type verifies them
+type dsseSigner struct {
+	s signature.Signer
+	v signature.Verifier
+}
+
+func (d *dsseSigner) KeyID() (string, error) {
+	return "", nil
+}
+
+func (d *dsseSigner) Public() crypto.PublicKey {
+	pub, _ := d.s.PublicKey()
+	return pub
+}
+
+func (d *dsseSigner) Sign(data []byte) ([]byte, error) {
+	return d.s.SignMessage(bytes.NewReader(data), options.WithCryptoSignerOpts(crypto.SHA256))
+}
+
+func (d *dsseSigner) Verify(data, sig []byte) error {
+	return d.v.VerifySignature(bytes.NewReader(sig), bytes.NewReader(data))
+}
+
+// signEnvelope wraps payload in a DSSE envelope signed by s, checking the signature as the log will
+func signEnvelope(s signature.Signer, payloadType string, payload []byte) ([]byte, error) {
+	pub, err := s.PublicKey()
+	if err != nil {
+		return nil, err
+	}
+	v, err := signature.LoadVerifier(pub, crypto.SHA256)
+	if err != nil {
+		return nil, err
+	}
+	es, err := dsse.NewEnvelopeSigner(&dsseSigner{s: s, v: v})
+	if err != nil {
+		return nil, err
+	}
+	env, err := es.SignPayload(payloadType, payload)
+	if err != nil {
+		return nil, fmt.Errorf("signing envelope: %w", err)
+	}
+	if _, err := es.Verify(env); err != nil {
+		return nil, fmt.Errorf("verifying envelope: %w", err)
+	}
+	return json.Marshal(env)
+}
+
+// signPayload returns the detached signature of the payload, as raw bytes the way upload reads
+// signatures of the x509 PKI format
+func signPayload(s signature.Signer, payload io.Reader) ([]byte, error) {
+	sig, err := s.SignMessage(payload, options.WithCryptoSignerOpts(crypto.SHA256))
+	if err != nil {
+		return nil, fmt.Errorf("signing payload: %w", err)
+	}
+	return sig, nil
+}
+
+// signOutputPath returns the path given by --output, or the path of the payload with the extension of
+// the kind of signature
+func signOutputPath(payloadPath string, envelope bool) (string, error) {
+	if out := viper.GetString("output"); out != "" {
+		return out, nil
+	}
+	if payloadPath == "-" {
+		return "", errors.New("--output must be specified when signing stdin")
+	}
+	if envelope {
+		return payloadPath + ".dsse.json", nil
+	}
+	return payloadPath + ".sig", nil
+}
+
+// signCmd represents the sign command
+var signCmd = &cobra.Command{
+	Use:   "sign",
+	Short: "Sign a payload for upload to the log",
+	Long: `Signs the payload given by --artifact, or stdin with --artifact -, with --key, writing either a
+detached signature or, with --dsse, a DSSE envelope wrapping the payload. The result can be uploaded
+as it is: a detached signature as a rekord with the x509 PKI format, an envelope as an intoto entry.
+
+The signature is written to --output, which defaults to the path of the artifact with .sig or
+.dsse.json appended. With --public-key-output, the public key of --key is written as well, as needed
+by upload when signing with a KMS key.`,
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		// these are bound here so that they are not overwritten by other commands
+		if err := viper.BindPFlags(cmd.Flags()); err != nil {
+			return fmt.Errorf("error initializing cmd line args: %s", err)
+		}
+		if viper.GetString("key") == "" {
+			return errors.New("--key must be specified")
+		}
+		if viper.GetString("artifact") == "" {
+			return errors.New("--artifact must be specified")
+		}
+		if cmd.Flags().Changed("payload-type") && !viper.GetBool("dsse") {
+			return errors.New("--payload-type requires --dsse")
+		}
+		return nil
+	},
+	Run: format.WrapCmd(func(args []string) (interface{}, error) {
+		ctx, cancel := commandContext()
+		defer cancel()
+		s, err := loadSigner(ctx, viper.GetString("key"))
+		if err != nil {
+			return nil, err
+		}
+
+		payloadPath := viper.GetString("artifact")
+		envelope := viper.GetBool("dsse")
+		outPath, err := signOutputPath(payloadPath, envelope)
+		if err != nil {
+			return nil, err
+		}
+		payload := io.Reader(os.Stdin)
+		if payloadPath != "-" {
+			f, err := os.Open(filepath.Clean(payloadPath))
+			if err != nil {
+				return nil, err
+			}
+			defer f.Close()
+			payload = f
+		}
+
+		var out []byte
+		if envelope {
+			b, err := ioutil.ReadAll(payload)
+			if err != nil {
+				return nil, fmt.Errorf("reading payload: %w", err)
+			}
+			if out, err = signEnvelope(s, viper.GetString("payload-type"), b); err != nil {
+				return nil, err
+			}
+		} else if out, err = signPayload(s, payload); err != nil {
+			return nil, err
+		}
+		if err := ioutil.WriteFile(outPath, out, 0600); err != nil {
+			return nil, err
+		}
+
+		o := &signCmdOutput{Output: outPath}
+		publicKey := "<public key>"
+		if pubPath := viper.GetString("public-key-output"); pubPath != "" {
+			pub, err := s.PublicKey()
+			if err != nil {
+				return nil, err
+			}
+			pubPEM, err := cryptoutils.MarshalPublicKeyToPEM(pub)
+			if err != nil {
+				return nil, err
+			}
+			if err := ioutil.WriteFile(pubPath, pubPEM, 0600); err != nil {
+				return nil, err
+			}
+			o.PublicKey = pubPath
+			publicKey = pubPath
+		}
+		artifact := payloadPath
+		if artifact == "-" {
+			artifact = "<artifact>"
+		}
+		o.Upload = uploadCommand(envelope, artifact, outPath, publicKey)
+		return o, nil
+	}),
+}
+
+// uploadCommand returns the rekor-cli command which uploads what sign wrote to output, given the
+// paths of the artifact and of the public key of the signature
+func uploadCommand(envelope bool, artifact, output, publicKey string) string {
+	if envelope {
+		return fmt.Sprintf("rekor-cli upload --type intoto --artifact %v --public-key %v", output, publicKey)
+	}
+	// the keys sign takes are PEM encoded, rather than the PGP keys upload expects by default
+	return fmt.Sprintf("rekor-cli upload --artifact %v --signature %v --pki-format x509 --public-key %v", artifact, output, publicKey)
+}
+
+func init() {
+	initializePFlagMap()
+	signCmd.Flags().String("key", "", "path to the PEM encoded private key to sign with, or a KMS reference such as gcpkms://...; an encrypted key is decrypted with $"+keyPasswordEnv)
+	signCmd.Flags().String("artifact", "", "path to the payload to sign; - reads it from stdin")
+	signCmd.Flags().Bool("dsse", false, "write a DSSE envelope wrapping the payload instead of a detached signature")
+	signCmd.Flags().String("payload-type", in_toto.PayloadType, "with --dsse, the payload type of the envelope")
+	signCmd.Flags().String("output", "", "path to write the signature or envelope to")
+	signCmd.Flags().String("public-key-output", "", "path to write the PEM encoded public key of --key to")
+	rootCmd.AddCommand(signCmd)
+}
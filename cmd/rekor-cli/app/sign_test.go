@@ -0,0 +1,172 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package app
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/in-toto/in-toto-golang/in_toto"
+	"github.com/sigstore/sigstore/pkg/cryptoutils"
+	"github.com/sigstore/sigstore/pkg/signature"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/sigstore/rekor/pkg/pki/x509"
+	"github.com/sigstore/rekor/pkg/types"
+)
+
+func TestSign(t *testing.T) {
+	ctx := context.Background()
+	ecdsaKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	_, ed25519Key, _ := ed25519.GenerateKey(rand.Reader)
+
+	payload := []byte(`{"_type":"https://in-toto.io/Statement/v0.1"}`)
+	for name, key := range map[string]crypto.Signer{"ecdsa": ecdsaKey, "ed25519": ed25519Key} {
+		s, err := signature.LoadSigner(key, crypto.SHA256)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pubPEM, err := cryptoutils.MarshalPublicKeyToPEM(key.Public())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// the envelope is accepted by the intoto type, which verifies it
+		env, err := signEnvelope(s, in_toto.PayloadType, payload)
+		if err != nil {
+			t.Fatalf("%v: signEnvelope() = %v", name, err)
+		}
+		pe, err := types.NewProposedEntry(ctx, "intoto", "", types.ArtifactProperties{ArtifactBytes: env, PublicKeyBytes: pubPEM})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := types.NewEntry(pe); err != nil {
+			t.Errorf("%v: envelope rejected: %v", name, err)
+		}
+
+		// the detached signature is verified as upload sends it, as raw bytes
+		sig, err := signPayload(s, bytes.NewReader(payload))
+		if err != nil {
+			t.Fatalf("%v: signPayload() = %v", name, err)
+		}
+		pki, err := x509.NewSignature(bytes.NewReader(sig))
+		if err != nil {
+			t.Fatal(err)
+		}
+		pub, err := x509.NewPublicKey(bytes.NewReader(pubPEM))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := pki.Verify(bytes.NewReader(payload), pub); err != nil {
+			t.Errorf("%v: signature rejected: %v", name, err)
+		}
+		if err := pki.Verify(bytes.NewReader([]byte("other")), pub); err == nil {
+			t.Errorf("%v: expected signature over another payload to be rejected", name)
+		}
+	}
+}
+
+func TestUploadCommand(t *testing.T) {
+	ctx := context.Background()
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	s, err := signature.LoadSigner(key, crypto.SHA256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pubPEM, err := cryptoutils.MarshalPublicKeyToPEM(key.Public())
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload := []byte(`{"_type":"https://in-toto.io/Statement/v0.1"}`)
+	sig, err := signPayload(s, bytes.NewReader(payload))
+	if err != nil {
+		t.Fatal(err)
+	}
+	env, err := signEnvelope(s, in_toto.PayloadType, payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	write := func(name string, b []byte) string {
+		path := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(path, b, 0600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	artifactPath, pubPath := write("artifact", payload), write("key.pub", pubPEM)
+	sigPath, envPath := write("artifact.sig", sig), write("artifact.intoto", env)
+
+	// upload parses the printed command and accepts the entry it creates
+	upload := func(command string) error {
+		fields := strings.Fields(command)
+		if len(fields) < 2 || fields[0] != "rekor-cli" || fields[1] != "upload" {
+			t.Fatalf("not an upload command: %s", command)
+		}
+		initializePFlagMap()
+		blankCmd := &cobra.Command{}
+		if err := addArtifactPFlags(blankCmd); err != nil {
+			t.Fatal(err)
+		}
+		if err := blankCmd.ParseFlags(fields[2:]); err != nil {
+			return err
+		}
+		if err := viper.BindPFlags(blankCmd.Flags()); err != nil {
+			t.Fatal(err)
+		}
+		if err := validateArtifactPFlags(false, false); err != nil {
+			return err
+		}
+		typeStr, versionStr, err := ParseTypeFlag(viper.GetString("type"))
+		if err != nil {
+			return err
+		}
+		pe, err := types.NewProposedEntry(ctx, typeStr, versionStr, *CreatePropsFromPflags())
+		if err != nil {
+			return err
+		}
+		entry, err := types.NewEntry(pe)
+		if err != nil {
+			return err
+		}
+		// canonicalizing verifies the signature with the public key
+		_, err = entry.Canonicalize(ctx)
+		return err
+	}
+
+	detached := uploadCommand(false, artifactPath, sigPath, pubPath)
+	if err := upload(detached); err != nil {
+		t.Errorf("%s: %v", detached, err)
+	}
+	if err := upload(strings.Replace(detached, " --pki-format x509", "", 1)); err == nil {
+		t.Error("expected the key to be rejected as a PGP key without --pki-format x509")
+	}
+	envelope := uploadCommand(true, artifactPath, envPath, pubPath)
+	if err := upload(envelope); err != nil {
+		t.Errorf("%s: %v", envelope, err)
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"sync"
 
 	"github.com/mitchellh/go-homedir"
 	"github.com/sigstore/rekor/pkg/util"
@@ -27,7 +28,13 @@ import (
 
 type persistedState map[string]*util.SignedCheckpoint
 
+// dumpMu serializes updates of the state file by goroutines storing the state of different servers
+var dumpMu sync.Mutex
+
 func Dump(url string, sth *util.SignedCheckpoint) error {
+	dumpMu.Lock()
+	defer dumpMu.Unlock()
+
 	rekorDir, err := getRekorDir()
 	if err != nil {
 		return err
@@ -44,10 +51,21 @@ func Dump(url string, sth *util.SignedCheckpoint) error {
 	if err != nil {
 		return err
 	}
-	if err := ioutil.WriteFile(statePath, b, 0600); err != nil {
+
+	// write to a temporary file and rename it so that concurrent invocations never observe a partially written state file
+	tmp, err := ioutil.TempFile(rekorDir, "state.json.*")
+	if err != nil {
 		return err
 	}
-	return nil
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), statePath)
 }
 
 func loadStateFile() persistedState {
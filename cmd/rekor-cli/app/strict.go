@@ -0,0 +1,203 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package app
+
+import (
+	"context"
+	"crypto"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/sigstore/sigstore/pkg/cryptoutils"
+	"github.com/sigstore/sigstore/pkg/signature"
+	"github.com/spf13/viper"
+
+	"github.com/sigstore/rekor/cmd/rekor-cli/app/state"
+	genclient "github.com/sigstore/rekor/pkg/generated/client"
+	"github.com/sigstore/rekor/pkg/log"
+	"github.com/sigstore/rekor/pkg/util"
+)
+
+// checkpointClockSkew is how far in the future a checkpoint may be timestamped, to allow for the
+// clocks of the client and the server to differ
+const checkpointClockSkew = time.Minute
+
+// witness is a party which cosigns the checkpoints of the log after checking their consistency
+type witness struct {
+	name     string
+	verifier signature.Verifier
+}
+
+// parseWitness parses a witness given as name=path of its PEM encoded public key
+func parseWitness(v string) (witness, error) {
+	split := strings.SplitN(v, "=", 2)
+	if len(split) != 2 || split[0] == "" || split[1] == "" {
+		return witness{}, fmt.Errorf("invalid witness %q, expected name=path of its public key", v)
+	}
+	pemBytes, err := ioutil.ReadFile(filepath.Clean(split[1]))
+	if err != nil {
+		return witness{}, fmt.Errorf("reading key of witness %s: %w", split[0], err)
+	}
+	pub, err := cryptoutils.UnmarshalPEMToPublicKey(pemBytes)
+	if err != nil {
+		return witness{}, fmt.Errorf("parsing key of witness %s: %w", split[0], err)
+	}
+	verifier, err := signature.LoadVerifier(pub, crypto.SHA256)
+	if err != nil {
+		return witness{}, fmt.Errorf("loading key of witness %s: %w", split[0], err)
+	}
+	return witness{name: split[0], verifier: verifier}, nil
+}
+
+// strictPolicy holds the requirements of verify --strict on the checkpoint an entry is checked against
+type strictPolicy struct {
+	// how long ago the checkpoint may have been signed
+	maxAge time.Duration
+	// witnesses which must all have cosigned the checkpoint
+	witnesses []witness
+	// path or URL of a cosigned checkpoint to use instead of the one served by the log
+	checkpoint string
+}
+
+// strictPolicyFromFlags returns the policy given by the flags of verify --strict
+func strictPolicyFromFlags() (*strictPolicy, error) {
+	if !viper.GetBool("store_tree_state") {
+		return nil, errors.New("--strict requires store_tree_state, as checkpoints are checked against the persisted state of the log")
+	}
+	if viper.GetString("bundle") != "" {
+		return nil, errors.New("--strict can not be used with --bundle, which is verified offline")
+	}
+	p := &strictPolicy{
+		maxAge:     viper.GetDuration("max-checkpoint-age"),
+		checkpoint: viper.GetString("witness-checkpoint"),
+	}
+	if p.maxAge <= 0 {
+		return nil, errors.New("--max-checkpoint-age must be positive")
+	}
+	for _, w := range viper.GetStringSlice("witness") {
+		parsed, err := parseWitness(w)
+		if err != nil {
+			return nil, err
+		}
+		p.witnesses = append(p.witnesses, parsed)
+	}
+	return p, nil
+}
+
+// check checks that a checkpoint whose signature by the log has been verified is fresh at now and is
+// cosigned by every witness
+func (p *strictPolicy) check(sth *util.SignedCheckpoint, now time.Time) error {
+	ts := sth.GetTimestamp()
+	if ts == 0 {
+		return errors.New("checkpoint carries no timestamp, so its freshness can not be checked")
+	}
+	signed := time.Unix(0, int64(ts))
+	if age := now.Sub(signed); age > p.maxAge {
+		return fmt.Errorf("checkpoint was signed %v ago, longer than --max-checkpoint-age %v", age.Round(time.Second), p.maxAge)
+	} else if age < -checkpointClockSkew {
+		return fmt.Errorf("checkpoint is timestamped %v in the future", (-age).Round(time.Second))
+	}
+	for _, w := range p.witnesses {
+		if !sth.SignedNote.VerifyCosignature(w.name, w.verifier) {
+			return fmt.Errorf("checkpoint is not cosigned by witness %s", w.name)
+		}
+	}
+	return nil
+}
+
+// strictCheckpoint returns the checkpoint the entry is checked against: the cosigned checkpoint given
+// by --witness-checkpoint or the one currently served by the log, which is never read from the cache
+func (p *strictPolicy) strictCheckpoint(ctx context.Context, rekorClient *genclient.Rekor) (*verifiedTreeHead, error) {
+	if p.checkpoint == "" {
+		return fetchVerifiedTreeHead(ctx, rekorClient)
+	}
+	var text []byte
+	var err error
+	if isURL(p.checkpoint) {
+		downloadCtx, cancel := downloadContext()
+		defer cancel()
+		r, err := util.FileOrURLReadCloser(downloadCtx, p.checkpoint, nil)
+		if err != nil {
+			return nil, err
+		}
+		defer r.Close()
+		text, err = ioutil.ReadAll(r)
+		if err != nil {
+			return nil, err
+		}
+	} else if text, err = ioutil.ReadFile(filepath.Clean(p.checkpoint)); err != nil {
+		return nil, err
+	}
+	return verifySignedTreeHead(ctx, rekorClient, string(text))
+}
+
+// strictCheckpointOutput describes the checkpoint an entry was verified against in strict mode
+type strictCheckpointOutput struct {
+	Size      uint64
+	RootHash  string
+	Timestamp time.Time
+	Witnesses []string
+}
+
+// verifyStrict checks the entry at index, whose inclusion proof was computed against the tree of
+// proofSize leaves with root proofRoot, against a checkpoint satisfying p. Unlike updateTreeState, it
+// fails if no checkpoint can be obtained, if the checkpoint does not cover the entry, or if the
+// persisted state of the log can not be updated.
+func (p *strictPolicy) verifyStrict(ctx context.Context, rekorClient *genclient.Rekor, serverURL string, index int64, proofSize uint64, proofRoot []byte) (*strictCheckpointOutput, error) {
+	vth, err := p.strictCheckpoint(ctx, rekorClient)
+	if err != nil {
+		return nil, fmt.Errorf("unable to obtain a verified checkpoint: %w", err)
+	}
+	sth := vth.sth
+	if err := p.check(sth, time.Now()); err != nil {
+		return nil, err
+	}
+	if index < 0 || uint64(index) >= sth.Size {
+		return nil, fmt.Errorf("entry %d is not covered by the checkpoint of size %d", index, sth.Size)
+	}
+	if err := checkProofRoot(ctx, rekorClient, sth, proofSize, proofRoot); err != nil {
+		return nil, err
+	}
+
+	// a cosigned checkpoint may lag behind the state persisted from the log itself, which is kept
+	if oldState := state.Load(serverURL); oldState != nil && oldState.Size > sth.Size {
+		if err := proveConsistency(ctx, rekorClient, sth.Size, sth.Hash, oldState.Size, oldState.Hash); err != nil {
+			return nil, fmt.Errorf("checkpoint is inconsistent with previously persisted state (possible split view): %w", err)
+		}
+	} else {
+		if err := checkTreeStateConsistency(ctx, rekorClient, serverURL, sth); err != nil {
+			return nil, err
+		}
+		if err := state.Dump(serverURL, sth); err != nil {
+			return nil, fmt.Errorf("unable to persist the state of the log: %w", err)
+		}
+	}
+
+	o := &strictCheckpointOutput{
+		Size:      sth.Size,
+		RootHash:  fmt.Sprintf("%x", sth.Hash),
+		Timestamp: time.Unix(0, int64(sth.GetTimestamp())).UTC(),
+	}
+	for _, w := range p.witnesses {
+		o.Witnesses = append(o.Witnesses, w.name)
+	}
+	log.CliLogger.Infof("Verified checkpoint of size %d signed at %v", o.Size, o.Timestamp.Format(time.RFC3339))
+	return o, nil
+}
@@ -0,0 +1,116 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package app
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sigstore/sigstore/pkg/cryptoutils"
+	"github.com/sigstore/sigstore/pkg/signature"
+	"github.com/sigstore/sigstore/pkg/signature/options"
+
+	"github.com/sigstore/rekor/pkg/util"
+)
+
+func TestParseWitness(t *testing.T) {
+	pub, _, _ := ed25519.GenerateKey(rand.Reader)
+	pemBytes, err := cryptoutils.MarshalPublicKeyToPEM(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "witness.pub")
+	if err := ioutil.WriteFile(path, pemBytes, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	w, err := parseWitness("witness.example.com=" + path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w.name != "witness.example.com" || w.verifier == nil {
+		t.Fatalf("unexpected witness %+v", w)
+	}
+	for _, v := range []string{"witness.example.com", "=" + path, "witness.example.com=", "witness.example.com=" + path + ".missing"} {
+		if _, err := parseWitness(v); err == nil {
+			t.Errorf("expected error parsing witness %q", v)
+		}
+	}
+}
+
+func TestStrictPolicyCheck(t *testing.T) {
+	logKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	logSigner, _ := signature.LoadSigner(logKey, crypto.SHA256)
+	_, witnessKey, _ := ed25519.GenerateKey(rand.Reader)
+	witnessSigner, _ := signature.LoadSigner(witnessKey, crypto.Hash(0))
+	witnessVerifier, _ := signature.LoadVerifier(witnessKey.Public(), crypto.Hash(0))
+	otherKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	otherVerifier, _ := signature.LoadVerifier(otherKey.Public(), crypto.SHA256)
+
+	now := time.Now()
+	checkpoint := func(signed time.Time, cosigned bool) *util.SignedCheckpoint {
+		sth, err := util.CreateSignedCheckpoint(util.Checkpoint{Origin: "Rekor", Size: 10, Hash: []byte("bananas")})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !signed.IsZero() {
+			sth.SetTimestamp(uint64(signed.UnixNano()))
+		}
+		if _, err := sth.Sign("rekor.example.com", logSigner, options.WithContext(context.Background())); err != nil {
+			t.Fatal(err)
+		}
+		if cosigned {
+			if _, err := sth.Cosign("witness.example.com", witnessSigner, options.WithContext(context.Background())); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return sth
+	}
+
+	witnessed := []witness{{name: "witness.example.com", verifier: witnessVerifier}}
+	tests := []struct {
+		desc      string
+		sth       *util.SignedCheckpoint
+		witnesses []witness
+		wantErr   bool
+	}{
+		{desc: "fresh", sth: checkpoint(now.Add(-time.Minute), false)},
+		{desc: "stale", sth: checkpoint(now.Add(-time.Hour), false), wantErr: true},
+		{desc: "no timestamp", sth: checkpoint(time.Time{}, false), wantErr: true},
+		{desc: "within clock skew", sth: checkpoint(now.Add(checkpointClockSkew/2), false)},
+		{desc: "in the future", sth: checkpoint(now.Add(time.Hour), false), wantErr: true},
+		{desc: "cosigned", sth: checkpoint(now, true), witnesses: witnessed},
+		{desc: "not cosigned", sth: checkpoint(now, false), witnesses: witnessed, wantErr: true},
+		{desc: "cosigned by another witness", sth: checkpoint(now, true), witnesses: append(witnessed, witness{name: "other", verifier: otherVerifier}), wantErr: true},
+		{desc: "cosigned under another name", sth: checkpoint(now, true), witnesses: []witness{{name: "other", verifier: witnessVerifier}}, wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			p := &strictPolicy{maxAge: 10 * time.Minute, witnesses: test.witnesses}
+			if err := p.check(test.sth, now); (err != nil) != test.wantErr {
+				t.Fatalf("check() = %v, wantErr %v", err, test.wantErr)
+			}
+		})
+	}
+}
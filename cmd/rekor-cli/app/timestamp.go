@@ -127,7 +127,7 @@ func (t *timestampCmdOutput) String() string {
 var timestampCmd = &cobra.Command{
 	Use:   "timestamp",
 	Short: "Rekor timestamp command",
-	Long:  "Generates and uploads (WIP) an RFC 3161 timestamp response to the log. The timestamp response can be verified locally using Rekor's timestamping cert chain.",
+	Long:  "Generates an RFC 3161 timestamp response, which the server records in the log. The timestamp response can be verified with 'timestamp verify' using Rekor's timestamping cert chain.",
 	PreRunE: func(cmd *cobra.Command, args []string) error {
 		if err := viper.BindPFlags(cmd.Flags()); err != nil {
 			log.CliLogger.Fatal("Error initializing cmd line args: ", err)
@@ -139,6 +139,8 @@ var timestampCmd = &cobra.Command{
 		return nil
 	},
 	Run: format.WrapCmd(func(args []string) (interface{}, error) {
+		ctx, cancel := commandContext()
+		defer cancel()
 		rekorClient, err := client.GetRekorClient(viper.GetString("rekor_server"))
 		if err != nil {
 			return nil, err
@@ -153,7 +155,7 @@ var timestampCmd = &cobra.Command{
 			return nil, err
 		}
 
-		params := timestamp.NewGetTimestampResponseParams()
+		params := timestamp.NewGetTimestampResponseParamsWithContext(ctx)
 		params.SetTimeout(viper.GetDuration("timeout"))
 		params.Request = ioutil.NopCloser(bytes.NewReader(requestBytes))
 
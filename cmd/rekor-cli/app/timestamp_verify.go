@@ -0,0 +1,173 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package app
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/sigstore/rekor/cmd/rekor-cli/app/format"
+	"github.com/sigstore/rekor/pkg/client"
+	genclient "github.com/sigstore/rekor/pkg/generated/client"
+	"github.com/sigstore/rekor/pkg/generated/client/entries"
+	"github.com/sigstore/rekor/pkg/generated/client/timestamp"
+	"github.com/sigstore/rekor/pkg/log"
+	pki "github.com/sigstore/rekor/pkg/pki/x509"
+	"github.com/sigstore/rekor/pkg/types"
+	rfc3161_v001 "github.com/sigstore/rekor/pkg/types/rfc3161/v0.0.1"
+	"github.com/sigstore/rekor/pkg/util"
+)
+
+type timestampVerifyCmdOutput struct {
+	Timestamp time.Time
+	UUID      string
+	Index     int64
+}
+
+func (t *timestampVerifyCmdOutput) String() string {
+	return fmt.Sprintf("Timestamp verified, artifact existed at %s\nTimestamp response is recorded in the log at index %d with UUID %s\n",
+		t.Timestamp, t.Index, t.UUID)
+}
+
+// artifactDigestFromFlags returns the SHA256 digest given by --artifact-hash or computed over --artifact
+func artifactDigestFromFlags() ([]byte, error) {
+	if digestStr := viper.GetString("artifact-hash"); digestStr != "" {
+		return hex.DecodeString(digestStr)
+	}
+	artifactBytes, err := ioutil.ReadFile(filepath.Clean(viper.GetString("artifact")))
+	if err != nil {
+		return nil, fmt.Errorf("error reading artifact: %w", err)
+	}
+	digest := sha256.Sum256(artifactBytes)
+	return digest[:], nil
+}
+
+// timestampCertChain loads the TSA certificate chain from --cert-chain, falling back to the server's
+func timestampCertChain(ctx context.Context, rekorClient *genclient.Rekor) ([]byte, error) {
+	if path := viper.GetString("cert-chain"); path != "" {
+		return ioutil.ReadFile(filepath.Clean(path))
+	}
+	log.CliLogger.Infof("No certificate chain specified, using the chain served by %s", viper.GetString("rekor_server"))
+	params := timestamp.NewGetTimestampCertChainParamsWithContext(ctx)
+	params.SetTimeout(viper.GetDuration("timeout"))
+	resp, err := rekorClient.Timestamp.GetTimestampCertChain(params)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(resp.Payload), nil
+}
+
+var timestampVerifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Rekor timestamp verify command",
+	Long: `Verifies an RFC 3161 timestamp response against an artifact and the TSA certificate chain, and checks
+that the response has been recorded in the transparency log.`,
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		if err := viper.BindPFlags(cmd.Flags()); err != nil {
+			log.CliLogger.Fatal("Error initializing cmd line args: ", err)
+		}
+		return validateTimestampFlags()
+	},
+	Run: format.WrapCmd(func(args []string) (interface{}, error) {
+		ctx, cancel := commandContext()
+		defer cancel()
+		rekorClient, err := client.GetRekorClient(viper.GetString("rekor_server"))
+		if err != nil {
+			return nil, err
+		}
+
+		tsrBytes, err := ioutil.ReadFile(filepath.Clean(viper.GetString("timestamp")))
+		if err != nil {
+			return nil, fmt.Errorf("error reading timestamp response: %w", err)
+		}
+		digest, err := artifactDigestFromFlags()
+		if err != nil {
+			return nil, err
+		}
+		chainPEM, err := timestampCertChain(ctx, rekorClient)
+		if err != nil {
+			return nil, err
+		}
+		certChain, err := pki.ParseTimestampCertChain(chainPEM)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing certificate chain: %w", err)
+		}
+
+		signingTime, err := util.VerifyTimestampResponse(tsrBytes, digest, certChain)
+		if err != nil {
+			return nil, err
+		}
+
+		// the server records each response it issues as an rfc3161 entry, so its UUID can be computed locally
+		entry, err := types.NewEntry(rfc3161_v001.NewEntryFromBytes(tsrBytes))
+		if err != nil {
+			return nil, err
+		}
+		leaf, err := types.CanonicalizeEntry(ctx, entry, nil)
+		if err != nil {
+			return nil, err
+		}
+		uuid := util.EntryUUID(leaf)
+
+		params := entries.NewGetLogEntryByUUIDParamsWithContext(ctx)
+		params.SetTimeout(viper.GetDuration("timeout"))
+		params.EntryUUID = uuid
+		resp, err := rekorClient.Entries.GetLogEntryByUUID(params)
+		if err != nil {
+			return nil, fmt.Errorf("timestamp response is not recorded in the log: %w", err)
+		}
+		for k, logEntry := range resp.Payload {
+			if !strings.EqualFold(k, uuid) {
+				continue
+			}
+			if verified, err := verifyLogEntry(ctx, rekorClient, logEntry); err != nil || !verified {
+				return nil, fmt.Errorf("unable to verify entry was added to log %w", err)
+			}
+			if err := updateTreeStateFromEntry(ctx, rekorClient, viper.GetString("rekor_server"), logEntry); err != nil {
+				return nil, err
+			}
+			return &timestampVerifyCmdOutput{
+				Timestamp: signingTime.Round(time.Second),
+				UUID:      k,
+				Index:     *logEntry.LogIndex,
+			}, nil
+		}
+		return nil, errors.New("timestamp response is not recorded in the log")
+	}),
+}
+
+func init() {
+	initializePFlagMap()
+	timestampVerifyCmd.Flags().Var(NewFlagValue(fileFlag, ""), "timestamp", "path to the DER encoded timestamp response to verify")
+	timestampVerifyCmd.Flags().Var(NewFlagValue(fileFlag, ""), "artifact", "path to the artifact that was timestamped")
+	timestampVerifyCmd.Flags().Var(NewFlagValue(shaFlag, ""), "artifact-hash", "hex encoded SHA256 hash of the artifact that was timestamped")
+	timestampVerifyCmd.Flags().Var(NewFlagValue(fileFlag, ""), "cert-chain", "path to the PEM encoded TSA certificate chain; if unset the chain is fetched from the server, which only verifies responses issued since the server started")
+	if err := timestampVerifyCmd.MarkFlagRequired("timestamp"); err != nil {
+		log.CliLogger.Fatal("Error parsing cmd line args: ", err)
+	}
+
+	timestampCmd.AddCommand(timestampVerifyCmd)
+}
@@ -0,0 +1,293 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package app
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/go-openapi/swag"
+	"github.com/spf13/viper"
+
+	"github.com/sigstore/rekor/cmd/rekor-cli/app/state"
+	"github.com/sigstore/rekor/pkg/client"
+	genclient "github.com/sigstore/rekor/pkg/generated/client"
+	"github.com/sigstore/rekor/pkg/generated/client/pubkey"
+	"github.com/sigstore/rekor/pkg/generated/client/tlog"
+	"github.com/sigstore/rekor/pkg/generated/models"
+	"github.com/sigstore/rekor/pkg/log"
+	"github.com/sigstore/rekor/pkg/util"
+	"github.com/sigstore/rekor/pkg/verify"
+)
+
+// verifiedTreeHead is a signed tree head whose signature has been checked against the log's public key
+type verifiedTreeHead struct {
+	sth *util.SignedCheckpoint
+	// DER encoding of the public key that verified the tree head
+	publicKeyDER []byte
+}
+
+// fetchVerifiedTreeHead retrieves the current signed tree head from the server and verifies its signature
+func fetchVerifiedTreeHead(ctx context.Context, rekorClient *genclient.Rekor) (*verifiedTreeHead, error) {
+	params := tlog.NewGetLogInfoParamsWithContext(ctx)
+	params.SetTimeout(viper.GetDuration("timeout"))
+	result, err := rekorClient.Tlog.GetLogInfo(params)
+	if err != nil {
+		return nil, err
+	}
+
+	return verifySignedTreeHead(ctx, rekorClient, *result.GetPayload().SignedTreeHead)
+}
+
+// verifySignedTreeHead parses a signed tree head and verifies its signature against the keys of the log
+func verifySignedTreeHead(ctx context.Context, rekorClient *genclient.Rekor, signedTreeHead string) (*verifiedTreeHead, error) {
+	keySet, err := logKeySet(ctx, rekorClient)
+	if err != nil {
+		return nil, err
+	}
+
+	sth, verifier, err := keySet.Checkpoint(signedTreeHead)
+	if err != nil {
+		return nil, err
+	}
+	pub, err := verifier.PublicKey()
+	if err != nil {
+		return nil, err
+	}
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return nil, err
+	}
+
+	return &verifiedTreeHead{
+		sth:          sth,
+		publicKeyDER: der,
+	}, nil
+}
+
+const (
+	treeHeadCacheKey  = "treehead"
+	publicKeyCacheKey = "publickey"
+	keySetCacheKey    = "keyset"
+)
+
+// logKeySet returns the keys of the log configured as rekor_server_public_key for rekor_server, one or
+// more PEM encoded keys, or else those published by the server, which are cached for --cache-ttl
+func logKeySet(ctx context.Context, rekorClient *genclient.Rekor) (*verify.KeySet, error) {
+	if publicKey := viper.GetString("rekor_server_public_key"); publicKey != "" && rekorServer(ctx) == viper.GetString("rekor_server") {
+		return verify.KeySetFromPEM([]byte(publicKey))
+	}
+	c := responseCacheFor(ctx)
+	logKeys := verify.LogKeys{}
+	if !c.GetFresh(keySetCacheKey, viper.GetDuration("cache-ttl"), &logKeys) {
+		fetched, err := client.GetLogKeys(ctx, rekorServer(ctx))
+		if errors.Is(err, client.ErrNoKeySet) {
+			// servers which predate the rotation of keys only publish their current key
+			publicKey, err := logPublicKeyPEM(ctx, rekorClient)
+			if err != nil {
+				return nil, err
+			}
+			return verify.KeySetFromPEM([]byte(publicKey))
+		}
+		if err != nil {
+			return nil, err
+		}
+		logKeys = *fetched
+		storeInCache(c, keySetCacheKey, logKeys)
+	}
+	return verify.NewKeySet(logKeys)
+}
+
+// logPublicKeyPEM returns the current public key published by the server, which is cached for --cache-ttl
+func logPublicKeyPEM(ctx context.Context, rekorClient *genclient.Rekor) (string, error) {
+	c := responseCacheFor(ctx)
+	var publicKey string
+	if c.GetFresh(publicKeyCacheKey, viper.GetDuration("cache-ttl"), &publicKey) {
+		return publicKey, nil
+	}
+	keyResp, err := rekorClient.Pubkey.GetPublicKey(&pubkey.GetPublicKeyParams{Context: ctx})
+	if err != nil {
+		return "", err
+	}
+	storeInCache(c, publicKeyCacheKey, keyResp.Payload)
+	return keyResp.Payload, nil
+}
+
+// cachedVerifiedTreeHead is like fetchVerifiedTreeHead, but reuses a tree head fetched less than
+// --cache-ttl ago
+func cachedVerifiedTreeHead(ctx context.Context, rekorClient *genclient.Rekor) (*verifiedTreeHead, error) {
+	c := responseCacheFor(ctx)
+	var signedTreeHead string
+	if c.GetFresh(treeHeadCacheKey, viper.GetDuration("cache-ttl"), &signedTreeHead) {
+		if vth, err := verifySignedTreeHead(ctx, rekorClient, signedTreeHead); err == nil {
+			return vth, nil
+		}
+	}
+	vth, err := fetchVerifiedTreeHead(ctx, rekorClient)
+	if err != nil {
+		return nil, err
+	}
+	if text, err := vth.sth.MarshalText(); err == nil {
+		storeInCache(c, treeHeadCacheKey, string(text))
+	}
+	return vth, nil
+}
+
+// proveConsistency fetches and verifies a consistency proof between two states of the log
+func proveConsistency(ctx context.Context, rekorClient *genclient.Rekor, oldSize uint64, oldHash []byte, newSize uint64, newHash []byte) error {
+	switch {
+	case oldSize == newSize:
+		if !bytes.Equal(oldHash, newHash) {
+			return fmt.Errorf("root hashes differ for the same tree size %d", oldSize)
+		}
+		return nil
+	case oldSize > newSize:
+		return fmt.Errorf("tree size %d is less than previously observed tree size %d", newSize, oldSize)
+	case oldSize == 0:
+		// every tree is consistent with the empty tree
+		return nil
+	}
+
+	firstSize := int64(oldSize)
+	proofHashes, err := consistencyProofHashes(ctx, rekorClient, firstSize, int64(newSize))
+	if err != nil {
+		return err
+	}
+	if err := verify.ConsistencyProof(oldSize, oldHash, newSize, newHash, proofHashes); err != nil {
+		return err
+	}
+	storeInCache(responseCacheFor(ctx), consistencyProofCacheKey(firstSize, int64(newSize)), proofHashes)
+	return nil
+}
+
+func consistencyProofCacheKey(firstSize, lastSize int64) string {
+	return fmt.Sprintf("consistency/%d-%d", firstSize, lastSize)
+}
+
+// consistencyProofHashes returns the hashes of a consistency proof from the cache or the server;
+// proofs between two tree sizes never change
+func consistencyProofHashes(ctx context.Context, rekorClient *genclient.Rekor, firstSize, lastSize int64) ([]string, error) {
+	var hashes []string
+	if responseCacheFor(ctx).Get(consistencyProofCacheKey(firstSize, lastSize), &hashes) {
+		return hashes, nil
+	}
+
+	params := tlog.NewGetLogProofParamsWithContext(ctx)
+	params.SetTimeout(viper.GetDuration("timeout"))
+	params.FirstSize = &firstSize
+	params.LastSize = lastSize
+	proof, err := rekorClient.Tlog.GetLogProof(params)
+	if err != nil {
+		return nil, err
+	}
+	return proof.Payload.Hashes, nil
+}
+
+// checkTreeStateConsistency verifies that a freshly verified tree head is consistent with the one
+// persisted for this server by a previous invocation, if any
+func checkTreeStateConsistency(ctx context.Context, rekorClient *genclient.Rekor, serverURL string, sth *util.SignedCheckpoint) error {
+	oldState := state.Load(serverURL)
+	if oldState == nil {
+		log.CliLogger.Infof("No previous log state stored, unable to prove consistency")
+		return nil
+	}
+
+	if oldState.Size < sth.Size {
+		log.CliLogger.Infof("Found previous log state, proving consistency between %d and %d", oldState.Size, sth.Size)
+	}
+	if err := proveConsistency(ctx, rekorClient, oldState.Size, oldState.Hash, sth.Size, sth.Hash); err != nil {
+		return fmt.Errorf("log state returned from server is inconsistent with previously persisted state (possible split view or rollback): %w", err)
+	}
+	if oldState.Size == sth.Size {
+		log.CliLogger.Infof("Persisted log state matches the current state of the log")
+	} else {
+		log.CliLogger.Infof("Consistency proof valid!")
+	}
+	return nil
+}
+
+// persistTreeState stores the tree head for later consistency checks if tree state storage is enabled
+func persistTreeState(serverURL string, sth *util.SignedCheckpoint) {
+	if !viper.GetBool("store_tree_state") {
+		return
+	}
+	if err := state.Dump(serverURL, sth); err != nil {
+		log.CliLogger.Infof("Unable to store previous state: %v", err)
+	}
+}
+
+// updateTreeState is called by commands which interact with the log: it fetches and verifies the
+// current signed tree head, checks it against the persisted state, proves that the root returned
+// alongside an inclusion proof (if any) is consistent with it, and then persists the new tree head.
+// Failures to reach the server are reported as warnings; inconsistencies are returned as errors.
+func updateTreeState(ctx context.Context, rekorClient *genclient.Rekor, serverURL string, proofSize uint64, proofRoot []byte) error {
+	if !viper.GetBool("store_tree_state") {
+		return nil
+	}
+
+	vth, err := cachedVerifiedTreeHead(ctx, rekorClient)
+	if err != nil {
+		log.CliLogger.Warnf("WARNING: unable to fetch signed tree head to check for log consistency: %v", err)
+		return nil
+	}
+	sth := vth.sth
+
+	if err := checkTreeStateConsistency(ctx, rekorClient, serverURL, sth); err != nil {
+		return err
+	}
+
+	if proofRoot != nil {
+		if err := checkProofRoot(ctx, rekorClient, sth, proofSize, proofRoot); err != nil {
+			return err
+		}
+	}
+
+	persistTreeState(serverURL, sth)
+	return nil
+}
+
+// checkProofRoot proves that the root an inclusion proof was computed against is consistent with a
+// verified tree head
+func checkProofRoot(ctx context.Context, rekorClient *genclient.Rekor, sth *util.SignedCheckpoint, proofSize uint64, proofRoot []byte) error {
+	var err error
+	// the inclusion proof may have been computed against a more recent tree than the tree head (e.g. due to skew between server instances)
+	if proofSize <= sth.Size {
+		err = proveConsistency(ctx, rekorClient, proofSize, proofRoot, sth.Size, sth.Hash)
+	} else {
+		err = proveConsistency(ctx, rekorClient, sth.Size, sth.Hash, proofSize, proofRoot)
+	}
+	if err != nil {
+		return fmt.Errorf("root hash of inclusion proof is inconsistent with signed tree head: %w", err)
+	}
+	return nil
+}
+
+// updateTreeStateFromEntry performs updateTreeState using the inclusion proof of a retrieved entry, if present
+func updateTreeStateFromEntry(ctx context.Context, rekorClient *genclient.Rekor, serverURL string, e models.LogEntryAnon) error {
+	if e.Verification == nil || e.Verification.InclusionProof == nil {
+		return updateTreeState(ctx, rekorClient, serverURL, 0, nil)
+	}
+	proof := e.Verification.InclusionProof
+	rootHash, err := hex.DecodeString(swag.StringValue(proof.RootHash))
+	if err != nil {
+		return fmt.Errorf("invalid root hash in inclusion proof: %w", err)
+	}
+	return updateTreeState(ctx, rekorClient, serverURL, uint64(swag.Int64Value(proof.TreeSize)), rootHash)
+}
@@ -0,0 +1,109 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package app
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/trillian/merkle/rfc6962"
+	"github.com/spf13/viper"
+
+	"github.com/sigstore/rekor/pkg/client"
+	"github.com/sigstore/rekor/pkg/util"
+)
+
+func TestProveConsistency(t *testing.T) {
+	h := rfc6962.DefaultHasher
+	leaf1, leaf2 := h.HashLeaf([]byte("1")), h.HashLeaf([]byte("2"))
+	root2 := h.HashChildren(leaf1, leaf2)
+	forked := append([]byte{}, root2...)
+	forked[0] ^= 0xff
+
+	// the server proves that the tree of size 1 is a prefix of the tree of size 2
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path != "/api/v1/log/proof" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"rootHash": hex.EncodeToString(root2),
+			"hashes":   []string{hex.EncodeToString(leaf2)},
+		})
+	}))
+	t.Cleanup(server.Close)
+
+	noCache := viper.GetBool("no-cache")
+	viper.Set("no-cache", true)
+	t.Cleanup(func() {
+		viper.Set("no-cache", noCache)
+	})
+	rekorClient, err := client.GetRekorClient(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		name             string
+		oldSize, newSize uint64
+		oldHash, newHash []byte
+		wantErr          string
+		wantRequest      bool
+	}{
+		{name: "same tree", oldSize: 2, oldHash: root2, newSize: 2, newHash: root2},
+		{name: "same size, different root", oldSize: 2, oldHash: root2, newSize: 2, newHash: forked, wantErr: "root hashes differ"},
+		{name: "rollback", oldSize: 2, oldHash: root2, newSize: 1, newHash: leaf1, wantErr: "less than previously observed"},
+		{name: "empty tree", oldSize: 0, newSize: 2, newHash: root2},
+		{name: "appended", oldSize: 1, oldHash: leaf1, newSize: 2, newHash: root2, wantRequest: true},
+		{name: "forked", oldSize: 1, oldHash: leaf1, newSize: 2, newHash: forked, wantErr: "root", wantRequest: true},
+		{name: "rewritten", oldSize: 1, oldHash: leaf2, newSize: 2, newHash: root2, wantErr: "root", wantRequest: true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			requests = 0
+			err := proveConsistency(context.Background(), rekorClient, tt.oldSize, tt.oldHash, tt.newSize, tt.newHash)
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+			if got := requests > 0; got != tt.wantRequest {
+				t.Errorf("proof requested: %v, want %v", got, tt.wantRequest)
+			}
+		})
+	}
+
+	// the root of an inclusion proof may be older or newer than the tree head it is checked against
+	sth := &util.SignedCheckpoint{Checkpoint: util.Checkpoint{Size: 2, Hash: root2}}
+	if err := checkProofRoot(context.Background(), rekorClient, sth, 1, leaf1); err != nil {
+		t.Errorf("older proof root: %v", err)
+	}
+	sth = &util.SignedCheckpoint{Checkpoint: util.Checkpoint{Size: 1, Hash: leaf1}}
+	if err := checkProofRoot(context.Background(), rekorClient, sth, 2, root2); err != nil {
+		t.Errorf("newer proof root: %v", err)
+	}
+	if err := checkProofRoot(context.Background(), rekorClient, sth, 2, forked); err == nil || !strings.Contains(err.Error(), "inconsistent with signed tree head") {
+		t.Errorf("expected forked proof root to be rejected, got %v", err)
+	}
+}
@@ -17,16 +17,17 @@ package app
 
 import (
 	"context"
-	"crypto/ecdsa"
-	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"io"
-	"net/http"
+	"io/ioutil"
 	"net/url"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
-	"github.com/cyberphone/json-canonicalization/go/src/webpki.org/jsoncanonicalizer"
 	"github.com/go-openapi/runtime"
 	"github.com/go-openapi/swag"
 	"github.com/spf13/cobra"
@@ -34,26 +35,40 @@ import (
 
 	"github.com/pkg/errors"
 	"github.com/sigstore/rekor/cmd/rekor-cli/app/format"
-	"github.com/sigstore/rekor/pkg/client"
+	"github.com/sigstore/rekor/pkg/bundle"
 	genclient "github.com/sigstore/rekor/pkg/generated/client"
 	"github.com/sigstore/rekor/pkg/generated/client/entries"
 	"github.com/sigstore/rekor/pkg/generated/models"
 	"github.com/sigstore/rekor/pkg/log"
 	"github.com/sigstore/rekor/pkg/types"
 	"github.com/sigstore/rekor/pkg/util"
+	"github.com/sigstore/rekor/pkg/verify"
 )
 
 type uploadCmdOutput struct {
-	AlreadyExists bool
-	Location      string
-	Index         int64
+	AlreadyExists  bool
+	Location       string
+	Index          int64
+	UUID           string
+	IntegratedTime int64
+	// server is the server the entry was uploaded to, which Location is relative to
+	server string
 }
 
 func (u *uploadCmdOutput) String() string {
 	if u.AlreadyExists {
-		return fmt.Sprintf("Entry already exists; available at: %v%v\n", viper.GetString("rekor_server"), u.Location)
+		return fmt.Sprintf("Entry already exists; available at: %v%v\n", u.server, u.Location)
 	}
-	return fmt.Sprintf("Created entry at index %d, available at: %v%v\n", u.Index, viper.GetString("rekor_server"), u.Location)
+	return fmt.Sprintf("Created entry at index %d, available at: %v%v\nUUID: %v\nIntegratedTime: %v\n", u.Index, u.server, u.Location,
+		u.UUID, time.Unix(u.IntegratedTime, 0).UTC().Format(time.RFC3339))
+}
+
+type uploadDryRunOutput struct {
+	UUID string
+}
+
+func (u *uploadDryRunOutput) String() string {
+	return fmt.Sprintf("Entry is valid and was not uploaded; it would be added with UUID: %v\n", u.UUID)
 }
 
 // uploadCmd represents the upload command
@@ -65,6 +80,9 @@ var uploadCmd = &cobra.Command{
 		if err := viper.BindPFlags(cmd.Flags()); err != nil {
 			return err
 		}
+		if viper.GetString("checksums") != "" {
+			return validateChecksumsPFlags()
+		}
 		if err := validateArtifactPFlags(false, false); err != nil {
 			return err
 		}
@@ -72,28 +90,37 @@ var uploadCmd = &cobra.Command{
 	},
 	Long: `This command takes the public key, signature and URL of the release artifact and uploads it to the rekor server.`,
 	Run: format.WrapCmd(func(args []string) (interface{}, error) {
-		ctx := context.Background()
-		rekorClient, err := client.GetRekorClient(viper.GetString("rekor_server"))
-		if err != nil {
-			return nil, err
-		}
 		var entry models.ProposedEntry
-		params := entries.NewCreateLogEntryParams()
-		params.SetTimeout(viper.GetDuration("timeout"))
+
+		// preparing the entry may fetch it, or the artifact, signature and key it refers to, which is
+		// limited by --download-timeout rather than --timeout
+		downloadCtx, cancelDownload := downloadContext()
+		defer cancelDownload()
+
+		if viper.GetString("checksums") != "" {
+			return uploadChecksums(downloadCtx)
+		}
 
 		entryStr := viper.GetString("entry")
 		if entryStr != "" {
 			var entryReader io.Reader
 			entryURL, err := url.Parse(entryStr)
 			if err == nil && entryURL.IsAbs() {
-				/* #nosec G107 */
-				entryResp, err := http.Get(entryStr)
+				c, err := downloadURL(downloadCtx, entryStr, nil)
 				if err != nil {
 					return nil, fmt.Errorf("error fetching entry: %w", err)
 				}
-				defer entryResp.Body.Close()
-				entryReader = entryResp.Body
+				defer removeDownload(c)
+				entryFile, err := c.Open()
+				if err != nil {
+					return nil, fmt.Errorf("error processing entry file: %w", err)
+				}
+				defer entryFile.Close()
+				entryReader = entryFile
 			} else {
+				if err := checkFileSize(entryStr); err != nil {
+					return nil, err
+				}
 				entryReader, err = os.Open(filepath.Clean(entryStr))
 				if err != nil {
 					return nil, fmt.Errorf("error processing entry file: %w", err)
@@ -110,90 +137,241 @@ var uploadCmd = &cobra.Command{
 			}
 
 			props := CreatePropsFromPflags()
+			cleanup, err := resolveRemotePublicKeys(downloadCtx, props)
+			if err != nil {
+				return nil, err
+			}
+			defer cleanup()
+			// only the digest of the artifact is uploaded, so it is computed here rather than by the server
+			if typeStr == "hashedrekord" && props.ArtifactPath != nil && props.ArtifactPath.IsAbs() {
+				if err := hashArtifactURL(downloadCtx, props); err != nil {
+					return nil, err
+				}
+			} else if props.ArtifactPath != nil && !props.ArtifactPath.IsAbs() {
+				if err := checkFileSize(props.ArtifactPath.Path); err != nil {
+					return nil, err
+				}
+			}
 
-			entry, err = types.NewProposedEntry(context.Background(), typeStr, versionStr, *props)
+			entry, err = types.NewProposedEntry(downloadCtx, typeStr, versionStr, *props)
 			if err != nil {
 				return nil, err
 			}
 		}
-		params.SetProposedEntry(entry)
 
-		resp, err := rekorClient.Entries.CreateLogEntry(params)
+		return publishEntry(entry)
+	}),
+}
+
+// uploadEntry adds the proposed entry to the log and verifies the response, or only validates the entry
+// with --dry-run
+func uploadEntry(ctx context.Context, rekorClient *genclient.Rekor, entry models.ProposedEntry) (interface{}, error) {
+	if viper.GetBool("dry-run") {
+		return validateEntry(ctx, rekorClient, entry)
+	}
+
+	o, logEntry, err := createEntry(ctx, rekorClient, entry)
+	if err != nil {
+		return nil, err
+	}
+	if bundlePath := viper.GetString("bundle"); bundlePath != "" && logEntry != nil {
+		b, err := entryBundle(ctx, rekorClient, o.UUID, *logEntry)
 		if err != nil {
-			switch e := err.(type) {
-			case *entries.CreateLogEntryConflict:
-				return &uploadCmdOutput{
-					Location:      e.Location.String(),
-					AlreadyExists: true,
-				}, nil
-			default:
-				return nil, err
-			}
+			return nil, err
+		}
+		if err := writeBundle(bundlePath, b); err != nil {
+			return nil, err
 		}
+	}
+	return o, nil
+}
 
-		var newIndex int64
-		var logEntry models.LogEntryAnon
-		for _, entry := range resp.Payload {
-			newIndex = swag.Int64Value(entry.LogIndex)
-			logEntry = entry
+// createEntry adds the proposed entry to the log and verifies the entry returned by the log, which is
+// nil if the entry already existed
+func createEntry(ctx context.Context, rekorClient *genclient.Rekor, entry models.ProposedEntry) (*uploadCmdOutput, *models.LogEntryAnon, error) {
+	params := entries.NewCreateLogEntryParamsWithContext(ctx)
+	params.SetTimeout(viper.GetDuration("timeout"))
+	params.SetProposedEntry(entry)
+	resp, err := rekorClient.Entries.CreateLogEntry(params)
+	if err != nil {
+		switch e := err.(type) {
+		case *entries.CreateLogEntryConflict:
+			return &uploadCmdOutput{
+				Location:      e.Location.String(),
+				AlreadyExists: true,
+				server:        rekorServer(ctx),
+			}, nil, nil
+		case *entries.CreateLogEntryUnprocessableEntity:
+			return nil, nil, admissionError(e.Payload)
+		default:
+			return nil, nil, err
 		}
+	}
+
+	var newIndex int64
+	var uuid string
+	var logEntry models.LogEntryAnon
+	for k, entry := range resp.Payload {
+		newIndex = swag.Int64Value(entry.LogIndex)
+		uuid = k
+		logEntry = entry
+	}
+
+	// verify log entry
+	if verified, err := verifyLogEntry(ctx, rekorClient, logEntry); err != nil || !verified {
+		return nil, nil, errors.Wrap(err, "unable to verify entry was added to log")
+	}
+	if err := verifyEntryUUID(uuid, logEntry); err != nil {
+		return nil, nil, err
+	}
+	if err := updateTreeStateFromEntry(ctx, rekorClient, rekorServer(ctx), logEntry); err != nil {
+		return nil, nil, err
+	}
 
-		// verify log entry
-		if verified, err := verifyLogEntry(ctx, rekorClient, logEntry); err != nil || !verified {
-			return nil, errors.Wrap(err, "unable to verify entry was added to log")
+	return &uploadCmdOutput{
+		Location:       string(resp.Location),
+		Index:          newIndex,
+		UUID:           uuid,
+		IntegratedTime: swag.Int64Value(logEntry.IntegratedTime),
+		server:         rekorServer(ctx),
+	}, &logEntry, nil
+}
+
+// validateEntry canonicalizes and verifies the proposed entry locally, then asks the server to check it
+// against its admission policy without adding it to the log
+func validateEntry(ctx context.Context, rekorClient *genclient.Rekor, proposed models.ProposedEntry) (interface{}, error) {
+	entry, err := types.NewEntry(proposed)
+	if err != nil {
+		return nil, fmt.Errorf("error processing entry: %w", err)
+	}
+	leaf, err := types.CanonicalizeEntry(ctx, entry, proposed.Annotations())
+	if err != nil {
+		return nil, fmt.Errorf("error canonicalizing entry: %w", err)
+	}
+	expectedUUID := util.EntryUUID(leaf)
+
+	params := entries.NewValidateLogEntryParamsWithContext(ctx)
+	params.SetTimeout(viper.GetDuration("timeout"))
+	params.SetProposedEntry(proposed)
+	resp, err := rekorClient.Entries.ValidateLogEntry(params)
+	if err != nil {
+		switch e := err.(type) {
+		case *entries.ValidateLogEntryConflict:
+			return &uploadCmdOutput{
+				Location:      e.Location.String(),
+				AlreadyExists: true,
+				server:        rekorServer(ctx),
+			}, nil
+		case *entries.ValidateLogEntryUnprocessableEntity:
+			return nil, admissionError(e.Payload)
+		default:
+			return nil, err
 		}
+	}
 
-		return &uploadCmdOutput{
-			Location: string(resp.Location),
-			Index:    newIndex,
-		}, nil
-	}),
+	for uuid, logEntry := range resp.Payload {
+		if err := verifyEntryUUID(uuid, logEntry); err != nil {
+			return nil, err
+		}
+		if uuid != expectedUUID {
+			return nil, fmt.Errorf("server canonicalized the entry with UUID %v, expected %v", uuid, expectedUUID)
+		}
+		return &uploadDryRunOutput{UUID: uuid}, nil
+	}
+	return nil, errors.New("no entry returned from rekor server")
 }
 
-func verifyLogEntry(ctx context.Context, rekorClient *genclient.Rekor, logEntry models.LogEntryAnon) (bool, error) {
-	if logEntry.Verification == nil {
-		return false, nil
+// admissionError describes each admission policy that the server rejected the entry for
+func admissionError(payload *models.AdmissionError) error {
+	if payload == nil {
+		return errors.New("entry rejected by admission policy of server")
 	}
-	// verify the entry
-	if logEntry.Verification.SignedEntryTimestamp == nil {
-		return false, fmt.Errorf("signature missing")
+	var violations []string
+	for _, v := range payload.Violations {
+		if v != nil {
+			violations = append(violations, fmt.Sprintf("%s: %s", v.Policy, v.Message))
+		}
 	}
+	return fmt.Errorf("%s:\n  %s", payload.Message, strings.Join(violations, "\n  "))
+}
 
-	le := &models.LogEntryAnon{
-		IntegratedTime: logEntry.IntegratedTime,
-		LogIndex:       logEntry.LogIndex,
-		Body:           logEntry.Body,
-		LogID:          logEntry.LogID,
+// entryBundle returns the new entry as a Sigstore bundle. The response to an upload only carries the
+// signed entry timestamp, so the entry is fetched again to include its inclusion proof when possible.
+func entryBundle(ctx context.Context, rekorClient *genclient.Rekor, uuid string, logEntry models.LogEntryAnon) (*bundle.Bundle, error) {
+	params := entries.NewGetLogEntryByUUIDParamsWithContext(ctx)
+	params.SetTimeout(viper.GetDuration("timeout"))
+	params.EntryUUID = uuid
+	resp, err := rekorClient.Entries.GetLogEntryByUUID(params)
+	if err != nil {
+		log.CliLogger.Warnf("WARNING: unable to fetch inclusion proof for bundle: %v", err)
+	} else if fetched, ok := resp.Payload[uuid]; ok && fetched.Verification != nil && fetched.Verification.InclusionProof != nil {
+		bodyStr, _ := logEntry.Body.(string)
+		entryBytes, err := base64.StdEncoding.DecodeString(bodyStr)
+		if err != nil {
+			return nil, err
+		}
+		if err := verifyInclusionProof(entryBytes, fetched.Verification.InclusionProof); err != nil {
+			return nil, err
+		}
+		logEntry.Verification.InclusionProof = fetched.Verification.InclusionProof
 	}
 
-	payload, err := le.MarshalBinary()
+	b, err := bundle.FromLogEntry(logEntry)
 	if err != nil {
-		return false, err
+		return nil, errors.Wrap(err, "creating bundle")
 	}
-	canonicalized, err := jsoncanonicalizer.Transform(payload)
+	return b, nil
+}
+
+// writeBundle stores a Sigstore bundle in the file given by --bundle
+func writeBundle(path string, b *bundle.Bundle) error {
+	bundleBytes, err := json.MarshalIndent(b, "", "  ")
 	if err != nil {
-		return false, err
+		return err
 	}
+	if err := ioutil.WriteFile(path, bundleBytes, 0600); err != nil {
+		return fmt.Errorf("error writing bundle: %w", err)
+	}
+	return nil
+}
 
-	// get rekor's public key
-	rekorPubKey, err := util.PublicKey(ctx, rekorClient)
+func verifyLogEntry(ctx context.Context, rekorClient *genclient.Rekor, logEntry models.LogEntryAnon) (bool, error) {
+	if logEntry.Verification == nil {
+		return false, nil
+	}
+
+	keySet, err := logKeySet(ctx, rekorClient)
 	if err != nil {
 		return false, err
 	}
 
-	// verify the SET against the public key
-	hash := sha256.Sum256(canonicalized)
-	if !ecdsa.VerifyASN1(rekorPubKey, hash[:], []byte(logEntry.Verification.SignedEntryTimestamp)) {
-		return false, fmt.Errorf("unable to verify")
+	if err := keySet.SignedEntryTimestamp(logEntry); err != nil {
+		return false, err
 	}
 	return true, nil
 }
 
+// verifyEntryUUID checks that the UUID returned by the server is the leaf hash of the returned entry body
+func verifyEntryUUID(uuid string, logEntry models.LogEntryAnon) error {
+	_, err := verify.Body(uuid, logEntry)
+	return err
+}
+
 func init() {
 	initializePFlagMap()
 	if err := addArtifactPFlags(uploadCmd); err != nil {
 		log.CliLogger.Fatal("Error parsing cmd line args:", err)
 	}
+	if err := addFlagToCmd(uploadCmd, false, fileOrURLFlag, "checksums", "path or URL to a checksums file such as SHA256SUMS, whose --signature is verified once before an entry is uploaded for each artifact it lists"); err != nil {
+		log.CliLogger.Fatal("Error parsing cmd line args:", err)
+	}
+	uploadCmd.Flags().String("bundle", "", "path to a file to write the new entry to in the Sigstore bundle format; with --publish-to, the bundle holds the entry of every server")
+	uploadCmd.Flags().Var(NewFlagSliceValue(urlFlag), "publish-to", "additional rekor server to publish the entry to, after every server has validated it; may be repeated")
+	uploadCmd.Flags().Int64("max-artifact-size", 0, "largest artifact or entry in bytes to read from a file or download; 0 for no limit")
+	uploadCmd.Flags().Int64("memory-threshold", 32<<20, "largest artifact or entry in bytes to hold in memory when downloading it; larger ones are spooled to a file under $HOME/.rekor/downloads, which is removed when the command exits")
+	uploadCmd.Flags().Int("download-connections", 4, "number of parallel connections used to download an artifact or entry given by URL, if the server supports range requests")
+	uploadCmd.Flags().Var(NewFlagSliceValue(annotFlag), "annotation", "annotation of the form key=value to attach to the entry, such as the build the artifact came from; the source of the artifact is recorded with git.commit=<commit ID> and git.repository=<URL>; may be repeated")
+	uploadCmd.Flags().Bool("dry-run", false, "verify and canonicalize the entry and check that the server would accept it, without uploading it")
 
 	rootCmd.AddCommand(uploadCmd)
 }
@@ -16,25 +16,33 @@
 package app
 
 import (
-	"bytes"
 	"context"
-	"encoding/base64"
+	"crypto/sha256"
 	"encoding/hex"
+	"errors"
 	"fmt"
+	"io/ioutil"
 	"math/bits"
+	"path/filepath"
 	"strconv"
+	"strings"
+	"time"
 
-	"github.com/google/trillian/merkle/logverifier"
+	"github.com/go-openapi/swag"
 	"github.com/google/trillian/merkle/rfc6962"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 
 	"github.com/sigstore/rekor/cmd/rekor-cli/app/format"
+	"github.com/sigstore/rekor/pkg/bundle"
 	"github.com/sigstore/rekor/pkg/client"
+	genclient "github.com/sigstore/rekor/pkg/generated/client"
 	"github.com/sigstore/rekor/pkg/generated/client/entries"
 	"github.com/sigstore/rekor/pkg/generated/models"
 	"github.com/sigstore/rekor/pkg/log"
 	"github.com/sigstore/rekor/pkg/types"
+	"github.com/sigstore/rekor/pkg/util"
+	"github.com/sigstore/rekor/pkg/verify"
 )
 
 type verifyCmdOutput struct {
@@ -43,6 +51,8 @@ type verifyCmdOutput struct {
 	Index     int64
 	Size      int64
 	Hashes    []string
+	// Checkpoint is the checkpoint the entry was verified against with --strict
+	Checkpoint *strictCheckpointOutput `json:",omitempty"`
 }
 
 func (v *verifyCmdOutput) String() string {
@@ -68,6 +78,39 @@ func (v *verifyCmdOutput) String() string {
 		s += fmt.Sprintf("SHA256(0x01 | %v | %v) =\n\t%v\n\n",
 			hex.EncodeToString(left), hex.EncodeToString(right), hex.EncodeToString(result))
 	}
+	if c := v.Checkpoint; c != nil {
+		s += fmt.Sprintf("Verified checkpoint of size %v with root hash %v signed at %v", c.Size, c.RootHash, c.Timestamp.Format(time.RFC3339))
+		if len(c.Witnesses) > 0 {
+			s += fmt.Sprintf(", cosigned by %v", strings.Join(c.Witnesses, ", "))
+		}
+		s += "\n"
+	}
+	return s
+}
+
+type verifyBundleCmdOutput struct {
+	EntryUUID      string
+	Index          int64
+	IntegratedTime int64
+	// InclusionProofVerified is false when the bundle only carries a signed entry timestamp
+	InclusionProofVerified bool
+	// CurrentTreeSize is the size of the tree of the log proven to be consistent with the tree of the
+	// inclusion proof with --check-log
+	CurrentTreeSize uint64 `json:",omitempty"`
+}
+
+func (v *verifyBundleCmdOutput) String() string {
+	s := fmt.Sprintf("Entry Hash: %v\n", v.EntryUUID)
+	s += fmt.Sprintf("Entry Index: %v\n", v.Index)
+	s += fmt.Sprintf("IntegratedTime: %v\n", time.Unix(v.IntegratedTime, 0).UTC().Format(time.RFC3339))
+	if v.InclusionProofVerified {
+		s += "Verified signed entry timestamp and inclusion proof from bundle\n"
+	} else {
+		s += "Verified signed entry timestamp from bundle; bundle contains no inclusion proof\n"
+	}
+	if v.CurrentTreeSize != 0 {
+		s += fmt.Sprintf("Verified the log has only grown since, to tree size %v\n", v.CurrentTreeSize)
+	}
 	return s
 }
 
@@ -81,6 +124,9 @@ var verifyCmd = &cobra.Command{
 		if err := viper.BindPFlags(cmd.Flags()); err != nil {
 			return fmt.Errorf("error initializing cmd line args: %s", err)
 		}
+		if viper.GetString("bundle") != "" {
+			return nil
+		}
 		if err := validateArtifactPFlags(true, true); err != nil {
 			return err
 		}
@@ -92,54 +138,41 @@ var verifyCmd = &cobra.Command{
 			return nil, err
 		}
 
-		searchParams := entries.NewSearchLogQueryParams()
-		searchParams.SetTimeout(viper.GetDuration("timeout"))
-		searchLogQuery := models.SearchLogQuery{}
-
-		uuid := viper.GetString("uuid")
-		logIndex := viper.GetString("log-index")
-
-		if uuid != "" {
-			searchLogQuery.EntryUUIDs = append(searchLogQuery.EntryUUIDs, uuid)
-		} else if logIndex != "" {
-			logIndexInt, err := strconv.ParseInt(logIndex, 10, 0)
-			if err != nil {
-				return nil, fmt.Errorf("error parsing --log-index: %w", err)
-			}
-			searchLogQuery.LogIndexes = []*int64{&logIndexInt}
-		} else {
-			typeStr, versionStr, err := ParseTypeFlag(viper.GetString("type"))
-			if err != nil {
-				return nil, err
-			}
-
-			props := CreatePropsFromPflags()
-
-			entry, err := types.NewProposedEntry(context.Background(), typeStr, versionStr, *props)
-			if err != nil {
+		var policy *strictPolicy
+		if viper.GetBool("strict") {
+			if policy, err = strictPolicyFromFlags(); err != nil {
 				return nil, err
 			}
+		}
 
-			entries := []models.ProposedEntry{entry}
-			searchLogQuery.SetEntries(entries)
+		if bundlePath := viper.GetString("bundle"); bundlePath != "" {
+			ctx, cancel := commandContext()
+			defer cancel()
+			return verifyBundle(ctx, rekorClient, bundlePath)
 		}
-		searchParams.SetEntry(&searchLogQuery)
 
-		resp, err := rekorClient.Entries.SearchLogQuery(searchParams)
+		// the entry given by the artifact flags is prepared before --timeout starts, as doing so may
+		// fetch the artifact
+		cached, query, err := verifyQueryFromFlags()
 		if err != nil {
 			return nil, err
 		}
 
-		if len(resp.Payload) == 0 {
-			return nil, fmt.Errorf("entry in log cannot be located")
-		} else if len(resp.Payload) > 1 {
-			return nil, fmt.Errorf("multiple entries returned; this should not happen")
+		ctx, cancel := commandContext()
+		defer cancel()
+		logEntry := cached
+		if logEntry == nil {
+			if logEntry, err = findEntryToVerify(ctx, rekorClient, query); err != nil {
+				return nil, err
+			}
 		}
-		logEntry := resp.Payload[0]
 
 		var o *verifyCmdOutput
 		var entryBytes []byte
 		for k, v := range logEntry {
+			if v.Verification == nil || v.Verification.InclusionProof == nil {
+				return nil, fmt.Errorf("no inclusion proof returned for entry %v", k)
+			}
 			o = &verifyCmdOutput{
 				RootHash:  *v.Verification.InclusionProof.RootHash,
 				EntryUUID: k,
@@ -147,37 +180,194 @@ var verifyCmd = &cobra.Command{
 				Size:      *v.Verification.InclusionProof.TreeSize,
 				Hashes:    v.Verification.InclusionProof.Hashes,
 			}
-			entryBytes, err = base64.StdEncoding.DecodeString(v.Body.(string))
-			if err != nil {
+			if entryBytes, err = verify.Body(k, v); err != nil {
 				return nil, err
 			}
 		}
 
-		if viper.IsSet("uuid") && (viper.GetString("uuid") != o.EntryUUID) {
+		if viper.IsSet("uuid") && !strings.EqualFold(viper.GetString("uuid"), o.EntryUUID) {
 			return nil, fmt.Errorf("unexpected entry returned from rekor server")
 		}
 
-		leafHash, _ := hex.DecodeString(o.EntryUUID)
-		if !bytes.Equal(rfc6962.DefaultHasher.HashLeaf(entryBytes), leafHash) {
-			return nil, fmt.Errorf("computed leaf hash did not match entry UUID")
-		}
-
-		hashes := [][]byte{}
-		for _, h := range o.Hashes {
-			hb, _ := hex.DecodeString(h)
-			hashes = append(hashes, hb)
+		if err := verify.InclusionProof(entryBytes, logEntry[o.EntryUUID].Verification.InclusionProof); err != nil {
+			return nil, err
 		}
-
 		rootHash, _ := hex.DecodeString(o.RootHash)
 
-		v := logverifier.New(rfc6962.DefaultHasher)
-		if err := v.VerifyInclusionProof(o.Index, o.Size, hashes, rootHash, leafHash); err != nil {
+		cacheEntry(responseCache(), o.EntryUUID, logEntry[o.EntryUUID])
+		if policy != nil {
+			o.Checkpoint, err = policy.verifyStrict(ctx, rekorClient, viper.GetString("rekor_server"), o.Index, uint64(o.Size), rootHash)
+			if err != nil {
+				return nil, fmt.Errorf("strict verification failed: %w", err)
+			}
+			return o, nil
+		}
+		if err := updateTreeState(ctx, rekorClient, viper.GetString("rekor_server"), uint64(o.Size), rootHash); err != nil {
 			return nil, err
 		}
 		return o, err
 	}),
 }
 
+// verifyQueryFromFlags returns the entry given by --uuid or --log-index if it is cached, or else the
+// query to find it or the entry given by the artifact flags in the log
+func verifyQueryFromFlags() (models.LogEntry, *models.SearchLogQuery, error) {
+	searchLogQuery := &models.SearchLogQuery{}
+
+	c := responseCache()
+	uuid := viper.GetString("uuid")
+	logIndex := viper.GetString("log-index")
+
+	if uuid != "" {
+		if e, ok := cachedEntryByUUID(c, uuid); ok {
+			return models.LogEntry{uuid: e}, nil, nil
+		}
+		searchLogQuery.EntryUUIDs = append(searchLogQuery.EntryUUIDs, uuid)
+	} else if logIndex != "" {
+		logIndexInt, err := strconv.ParseInt(logIndex, 10, 0)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error parsing --log-index: %w", err)
+		}
+		if cachedUUID, e, ok := cachedEntryByIndex(c, logIndexInt); ok {
+			return models.LogEntry{cachedUUID: e}, nil, nil
+		}
+		searchLogQuery.LogIndexes = []*int64{&logIndexInt}
+	} else {
+		typeStr, versionStr, err := ParseTypeFlag(viper.GetString("type"))
+		if err != nil {
+			return nil, nil, err
+		}
+
+		props := CreatePropsFromPflags()
+
+		ctx, cancel := downloadContext()
+		defer cancel()
+		cleanup, err := resolveRemotePublicKeys(ctx, props)
+		if err != nil {
+			return nil, nil, err
+		}
+		defer cleanup()
+		entry, err := types.NewProposedEntry(ctx, typeStr, versionStr, *props)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		entries := []models.ProposedEntry{entry}
+		searchLogQuery.SetEntries(entries)
+	}
+	return nil, searchLogQuery, nil
+}
+
+// findEntryToVerify looks up the entry matching the query in the log
+func findEntryToVerify(ctx context.Context, rekorClient *genclient.Rekor, query *models.SearchLogQuery) (models.LogEntry, error) {
+	searchParams := entries.NewSearchLogQueryParamsWithContext(ctx)
+	searchParams.SetTimeout(viper.GetDuration("timeout"))
+	searchParams.SetEntry(query)
+
+	logEntries, err := client.SearchLogQuery(rekorClient, searchParams, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(logEntries) == 0 {
+		return nil, fmt.Errorf("entry in log cannot be located")
+	} else if len(logEntries) > 1 {
+		return nil, fmt.Errorf("multiple entries returned; this should not happen")
+	}
+	return logEntries[0], nil
+}
+
+// verifyBundle checks the log entries in a Sigstore bundle against the log's public key without
+// querying the log for them, and checks that the signature in the bundle is the one that was logged.
+// Entries of other logs, as in bundles written by upload --publish-to, are skipped.
+func verifyBundle(ctx context.Context, rekorClient *genclient.Rekor, path string) (*verifyBundleCmdOutput, error) {
+	bundleBytes, err := ioutil.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, fmt.Errorf("error reading bundle: %w", err)
+	}
+	b, err := bundle.Parse(bundleBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	var artifactDigest []byte
+	if artifactHash := viper.GetString("artifact-hash"); artifactHash != "" {
+		_, value, err := util.ParseDigest(artifactHash)
+		if err != nil {
+			return nil, err
+		}
+		artifactDigest, err = hex.DecodeString(value)
+		if err != nil {
+			return nil, err
+		}
+	} else if artifact := viper.GetString("artifact"); artifact != "" {
+		if isURL(artifact) {
+			return nil, errors.New("--artifact must be a local file when verifying a bundle")
+		}
+		artifactBytes, err := ioutil.ReadFile(filepath.Clean(artifact))
+		if err != nil {
+			return nil, fmt.Errorf("error reading artifact: %w", err)
+		}
+		h := sha256.Sum256(artifactBytes)
+		artifactDigest = h[:]
+	}
+
+	keySet, err := logKeySet(ctx, rekorClient)
+	if err != nil {
+		return nil, err
+	}
+	tle, err := keySet.Bundle(b, artifactDigest)
+	if err != nil {
+		return nil, err
+	}
+	o := &verifyBundleCmdOutput{
+		EntryUUID:              util.EntryUUID(tle.CanonicalizedBody),
+		Index:                  tle.LogIndex,
+		IntegratedTime:         tle.IntegratedTime,
+		InclusionProofVerified: tle.InclusionProof != nil,
+	}
+	if viper.GetBool("check-log") {
+		if tle.InclusionProof == nil {
+			return nil, errors.New("--check-log requires a bundle containing an inclusion proof")
+		}
+		sth, err := checkBundleInLog(ctx, rekorClient, keySet, tle)
+		if err != nil {
+			return nil, err
+		}
+		o.CurrentTreeSize = sth.Size
+	}
+	return o, nil
+}
+
+// checkBundleInLog asks the log to prove the inclusion of the entry of a bundle in the tree its inclusion
+// proof was computed for once more, along with the consistency of that tree with the current tree
+func checkBundleInLog(ctx context.Context, rekorClient *genclient.Rekor, keySet *verify.KeySet, tle *bundle.TransparencyLogEntry) (*util.SignedCheckpoint, error) {
+	params := entries.NewGetLogEntryProofParamsWithContext(ctx)
+	params.SetTimeout(viper.GetDuration("timeout"))
+	params.SetEntryUUID(util.EntryUUID(tle.CanonicalizedBody))
+	params.SetTreeSize(tle.InclusionProof.TreeSize)
+
+	resp, err := rekorClient.Entries.GetLogEntryProof(params)
+	if err != nil {
+		return nil, err
+	}
+	proof := resp.GetPayload()
+	sth, err := keySet.HistoricalProof(tle.CanonicalizedBody, proof)
+	if err != nil {
+		return nil, err
+	}
+	// the proofs only vouch for the bundle if they are for the same tree as its inclusion proof
+	if !strings.EqualFold(swag.StringValue(proof.InclusionProof.RootHash), hex.EncodeToString(tle.InclusionProof.RootHash)) {
+		return nil, fmt.Errorf("the log proved inclusion in a tree of size %d with another root hash than the bundle", tle.InclusionProof.TreeSize)
+	}
+	return sth, nil
+}
+
+// verifyInclusionProof checks an inclusion proof for an entry body against the root hash it carries
+func verifyInclusionProof(body []byte, proof *models.InclusionProof) error {
+	return verify.InclusionProof(body, proof)
+}
+
 func init() {
 	initializePFlagMap()
 	if err := addArtifactPFlags(verifyCmd); err != nil {
@@ -189,6 +379,12 @@ func init() {
 	if err := addLogIndexFlag(verifyCmd, false); err != nil {
 		log.CliLogger.Fatal("Error parsing cmd line args:", err)
 	}
+	verifyCmd.Flags().Bool("strict", false, "fail unless the entry is covered by a fresh checkpoint of the log which is consistent with the persisted state and cosigned by every --witness")
+	verifyCmd.Flags().Duration("max-checkpoint-age", 10*time.Minute, "with --strict, how long ago the checkpoint may have been signed")
+	verifyCmd.Flags().Var(NewFlagSliceValue(witnessFlag), "witness", "with --strict, witness of the form name=path to its PEM encoded public key which must have cosigned the checkpoint; may be repeated")
+	verifyCmd.Flags().Var(NewFlagValue(fileOrURLFlag, ""), "witness-checkpoint", "with --strict, path or URL of a cosigned checkpoint to verify against instead of the one served by the log")
+	verifyCmd.Flags().Var(NewFlagValue(fileFlag, ""), "bundle", "path to a Sigstore bundle to verify offline instead of looking the entry up in the log")
+	verifyCmd.Flags().Bool("check-log", false, "with --bundle, also have the log prove the inclusion proof of the bundle again and that the log has only grown since")
 
 	rootCmd.AddCommand(verifyCmd)
 }
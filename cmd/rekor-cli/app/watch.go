@@ -0,0 +1,206 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package app
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/go-openapi/swag"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/sigstore/rekor/cmd/rekor-cli/app/format"
+	"github.com/sigstore/rekor/pkg/client"
+	genclient "github.com/sigstore/rekor/pkg/generated/client"
+	"github.com/sigstore/rekor/pkg/generated/client/entries"
+	"github.com/sigstore/rekor/pkg/log"
+	"github.com/sigstore/rekor/pkg/types"
+	"github.com/sigstore/rekor/pkg/util"
+	"github.com/sigstore/rekor/pkg/verify"
+)
+
+// watchFilter selects the entries printed by the watch command
+type watchFilter struct {
+	email string
+	sha   string
+}
+
+// matches reports whether the index keys of an entry satisfy every criterion of the filter
+func (f watchFilter) matches(eimpl types.EntryImpl) (bool, error) {
+	if f.email == "" && f.sha == "" {
+		return true, nil
+	}
+	keys, err := eimpl.IndexKeys()
+	if err != nil {
+		return false, err
+	}
+	emailFound, shaFound := f.email == "", f.sha == ""
+	for _, key := range keys {
+		if f.email != "" && strings.EqualFold(key, f.email) {
+			emailFound = true
+		}
+		if f.sha != "" && strings.EqualFold(key, f.sha) {
+			shaFound = true
+		}
+	}
+	return emailFound && shaFound, nil
+}
+
+// watchCmd represents the watch command
+var watchCmd = &cobra.Command{
+	Use:   "watch",
+	Short: "Rekor watch command",
+	Long: `Prints entries as they are added to the transparency log, starting from the given log index
+(or the current end of the log). Entries can be restricted to those signed by a given e-mail address
+or referring to a given artifact hash. Each entry is verified before it is printed, including its
+inclusion proof in a tree consistent with the signed tree head.`,
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		// these are bound here so that they are not overwritten by other commands
+		if err := viper.BindPFlags(cmd.Flags()); err != nil {
+			return err
+		}
+		if viper.GetDuration("interval") <= 0 {
+			return errors.New("--interval must be greater than zero")
+		}
+		return nil
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer cancel()
+		if err := runWatch(ctx); err != nil && !errors.Is(err, context.Canceled) {
+			log.CliLogger.Fatal(err)
+		}
+	},
+}
+
+func runWatch(ctx context.Context) error {
+	rekorClient, err := client.GetRekorClient(viper.GetString("rekor_server"))
+	if err != nil {
+		return err
+	}
+	keySet, err := logKeySet(ctx, rekorClient)
+	if err != nil {
+		return err
+	}
+
+	filter := watchFilter{email: viper.GetString("email")}
+	if sha := viper.GetString("sha"); sha != "" {
+		filter.sha = qualifySHA(sha)
+	}
+
+	vth, err := fetchVerifiedTreeHead(ctx, rekorClient)
+	if err != nil {
+		return err
+	}
+	next := int64(vth.sth.Size)
+	if logIndex := viper.GetString("log-index"); logIndex != "" {
+		if next, err = strconv.ParseInt(logIndex, 10, 0); err != nil {
+			return fmt.Errorf("error parsing --log-index: %w", err)
+		}
+	}
+	log.CliLogger.Infof("Watching for entries from index %d", next)
+
+	interval := viper.GetDuration("interval")
+	for {
+		for ; next < int64(vth.sth.Size); next++ {
+			if err := printWatchedEntry(ctx, rekorClient, keySet, vth.sth, filter, next); err != nil {
+				return err
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(interval):
+		}
+
+		newVth, err := fetchVerifiedTreeHead(ctx, rekorClient)
+		if err != nil {
+			log.CliLogger.Warnf("unable to fetch signed tree head: %v", err)
+			continue
+		}
+		// never trust a new tree head without proving the log only grew since the last one
+		if err := proveConsistency(ctx, rekorClient, vth.sth.Size, vth.sth.Hash, newVth.sth.Size, newVth.sth.Hash); err != nil {
+			return fmt.Errorf("log state returned from server is inconsistent with previously observed state (possible split view or rollback): %w", err)
+		}
+		vth = newVth
+	}
+}
+
+// printWatchedEntry fetches and verifies the entry at index, printing it if it matches the filter. The
+// entry must come with an inclusion proof whose root is consistent with the verified tree head sth.
+func printWatchedEntry(ctx context.Context, rekorClient *genclient.Rekor, keySet *verify.KeySet, sth *util.SignedCheckpoint, filter watchFilter, index int64) error {
+	params := entries.NewGetLogEntryByIndexParamsWithContext(ctx)
+	params.SetTimeout(viper.GetDuration("timeout"))
+	params.LogIndex = index
+	resp, err := rekorClient.Entries.GetLogEntryByIndex(params)
+	if err != nil {
+		return fmt.Errorf("retrieving entry at index %d: %w", index, err)
+	}
+	for uuid, e := range resp.Payload {
+		if e.Verification == nil || e.Verification.InclusionProof == nil {
+			return fmt.Errorf("entry at index %d was returned without an inclusion proof", index)
+		}
+		// checks the signed entry timestamp, the UUID and the inclusion proof of the entry
+		if _, err := keySet.Entry(uuid, e); err != nil {
+			return fmt.Errorf("unable to verify entry at index %d was added to log: %w", index, err)
+		}
+		proof := e.Verification.InclusionProof
+		rootHash, err := hex.DecodeString(swag.StringValue(proof.RootHash))
+		if err != nil {
+			return fmt.Errorf("invalid root hash in inclusion proof of entry at index %d: %w", index, err)
+		}
+		if err := checkProofRoot(ctx, rekorClient, sth, uint64(swag.Int64Value(proof.TreeSize)), rootHash); err != nil {
+			return fmt.Errorf("entry at index %d: %w", index, err)
+		}
+		eimpl, annotations, err := decodeEntryBody(e)
+		if err != nil {
+			// entries of types unknown to this client can't be filtered or printed
+			log.CliLogger.Warnf("unable to parse entry at index %d: %v", index, err)
+			continue
+		}
+		match, err := filter.matches(eimpl)
+		if err != nil {
+			log.CliLogger.Warnf("unable to compute index keys of entry at index %d: %v", index, err)
+			continue
+		}
+		if match {
+			format.Print(newGetCmdOutput(uuid, e, eimpl, annotations))
+		}
+	}
+	return nil
+}
+
+func init() {
+	initializePFlagMap()
+	if err := addLogIndexFlag(watchCmd, false); err != nil {
+		log.CliLogger.Fatal("Error parsing cmd line args: ", err)
+	}
+	watchCmd.Flags().Var(NewFlagValue(emailFlag, ""), "email", "only print entries signed by this e-mail address")
+	watchCmd.Flags().Var(NewFlagValue(digestFlag, ""), "sha", "only print entries for the artifact with this SHA256, SHA384, SHA512 or SHA1 sum")
+	watchCmd.Flags().Duration("interval", 10*time.Second, "interval between polls for new entries")
+
+	rootCmd.AddCommand(watchCmd)
+}
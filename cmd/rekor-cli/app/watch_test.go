@@ -0,0 +1,72 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package app
+
+import (
+	"testing"
+
+	"github.com/sigstore/rekor/pkg/types"
+)
+
+type keysEntry struct {
+	types.EntryImpl
+	keys []string
+}
+
+func (k keysEntry) IndexKeys() ([]string, error) {
+	return k.keys, nil
+}
+
+func TestWatchFilter(t *testing.T) {
+	entry := keysEntry{keys: []string{
+		"9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08",
+		"jdoe@example.com",
+		"sha256:e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+	}}
+	for _, tt := range []struct {
+		name   string
+		filter watchFilter
+		want   bool
+	}{
+		{name: "no filter", filter: watchFilter{}, want: true},
+		{name: "email", filter: watchFilter{email: "JDoe@example.com"}, want: true},
+		{name: "other email", filter: watchFilter{email: "other@example.com"}, want: false},
+		{name: "sha", filter: watchFilter{sha: qualifySHA("e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855")}, want: true},
+		{name: "email and other sha", filter: watchFilter{email: "jdoe@example.com", sha: "sha1:da39a3ee5e6b4b0d3255bfef95601890afd80709"}, want: false},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.filter.matches(entry)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQualifySHA(t *testing.T) {
+	for in, want := range map[string]string{
+		"da39a3ee5e6b4b0d3255bfef95601890afd80709":                                "sha1:da39a3ee5e6b4b0d3255bfef95601890afd80709",
+		"e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855":        "sha256:e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+		"sha256:e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855": "sha256:e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+	} {
+		if got := qualifySHA(in); got != want {
+			t.Errorf("qualifySHA(%s) = %s, want %s", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package app
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/sigstore/rekor/pkg/log"
+)
+
+// kinds of anomalies detected by the monitor
+const (
+	anomalyCheckpointSignature = "checkpoint_signature"
+	anomalyInconsistentLog     = "inconsistent_log"
+	anomalyEntrySET            = "entry_signed_entry_timestamp"
+	anomalyEntryInclusion      = "entry_inclusion_proof"
+	anomalyEntryUUID           = "entry_uuid"
+	anomalyEntryBody           = "entry_body"
+)
+
+var (
+	metricAnomalies = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "rekor_monitor_anomalies",
+		Help: "The total number of anomalies detected in the log, by kind",
+	}, []string{"kind"})
+
+	metricCheckErrors = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "rekor_monitor_check_errors",
+		Help: "The total number of checks that could not be completed, e.g. because the log was unreachable",
+	})
+
+	metricEntriesVerified = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "rekor_monitor_entries_verified",
+		Help: "The total number of sampled entries that were successfully verified",
+	})
+
+	metricVerifiedTreeSize = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "rekor_monitor_verified_tree_size",
+		Help: "The size of the most recent checkpoint verified by the monitor",
+	})
+
+	metricLastSuccess = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "rekor_monitor_last_success_timestamp_seconds",
+		Help: "Unix time of the last check that completed without errors",
+	})
+)
+
+// Alert describes an anomaly detected while monitoring the log
+type Alert struct {
+	Kind     string    `json:"kind"`
+	Message  string    `json:"message"`
+	Server   string    `json:"server"`
+	TreeSize uint64    `json:"treeSize"`
+	LogIndex *int64    `json:"logIndex,omitempty"`
+	Time     time.Time `json:"time"`
+}
+
+// anomalyError is returned by checks which found the log misbehaving, as opposed to being unreachable
+type anomalyError struct {
+	kind     string
+	logIndex *int64
+	err      error
+}
+
+func (a *anomalyError) Error() string {
+	return a.err.Error()
+}
+
+func (a *anomalyError) Unwrap() error {
+	return a.err
+}
+
+func newAnomaly(kind string, err error) error {
+	return &anomalyError{kind: kind, err: err}
+}
+
+func newEntryAnomaly(kind string, logIndex int64, err error) error {
+	return &anomalyError{kind: kind, logIndex: &logIndex, err: err}
+}
+
+// alerter delivers alerts to an operator
+type alerter interface {
+	Alert(ctx context.Context, a Alert) error
+}
+
+// logAlerter writes alerts to the monitor's log
+type logAlerter struct{}
+
+func (logAlerter) Alert(_ context.Context, a Alert) error {
+	if a.LogIndex != nil {
+		log.Logger.Errorw("ALERT: "+a.Message, "kind", a.Kind, "server", a.Server, "treeSize", a.TreeSize, "logIndex", *a.LogIndex)
+	} else {
+		log.Logger.Errorw("ALERT: "+a.Message, "kind", a.Kind, "server", a.Server, "treeSize", a.TreeSize)
+	}
+	return nil
+}
+
+// webhookAlerter POSTs alerts as JSON to a URL
+type webhookAlerter struct {
+	url    string
+	client *http.Client
+}
+
+func newWebhookAlerter(url string, timeout time.Duration) *webhookAlerter {
+	return &webhookAlerter{
+		url:    url,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+func (w *webhookAlerter) Alert(ctx context.Context, a Alert) error {
+	b, err := json.Marshal(a)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook %s returned status %d", w.url, resp.StatusCode)
+	}
+	return nil
+}
+
+// multiAlerter fans an alert out to every configured alerter
+type multiAlerter []alerter
+
+func (m multiAlerter) Alert(ctx context.Context, a Alert) error {
+	var firstErr error
+	for _, al := range m {
+		if err := al.Alert(ctx, a); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
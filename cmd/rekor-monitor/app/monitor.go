@@ -0,0 +1,344 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package app
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/go-openapi/swag"
+
+	genclient "github.com/sigstore/rekor/pkg/generated/client"
+	"github.com/sigstore/rekor/pkg/generated/client/entries"
+	"github.com/sigstore/rekor/pkg/generated/client/tlog"
+	"github.com/sigstore/rekor/pkg/generated/models"
+	"github.com/sigstore/rekor/pkg/log"
+	"github.com/sigstore/rekor/pkg/util"
+	"github.com/sigstore/rekor/pkg/verify"
+	"github.com/sigstore/sigstore/pkg/signature"
+)
+
+// monitor tails a Rekor log, checking that every checkpoint it observes is signed by the log and
+// consistent with the previous one, and re-verifying a random sample of the entries added in between
+type monitor struct {
+	client    *genclient.Rekor
+	serverURL string
+	verifier  signature.Verifier
+
+	sampleSize int
+	rand       *rand.Rand
+	timeout    time.Duration
+
+	alerter   alerter
+	stateFile string
+
+	// last checkpoint that was verified to be consistent with all of its predecessors
+	last *util.SignedCheckpoint
+}
+
+// run performs a check every interval until the context is cancelled
+func (m *monitor) run(ctx context.Context, interval time.Duration) {
+	tick := time.NewTicker(interval)
+	defer tick.Stop()
+	for {
+		m.checkAndAlert(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-tick.C:
+		}
+	}
+}
+
+// checkAndAlert performs a single check, raising an alert for every anomaly that is found
+func (m *monitor) checkAndAlert(ctx context.Context) {
+	anomalies, err := m.check(ctx)
+	if err != nil {
+		metricCheckErrors.Inc()
+		log.Logger.Warnf("error checking log: %v", err)
+	}
+	for _, a := range anomalies {
+		metricAnomalies.WithLabelValues(a.Kind).Inc()
+		if err := m.alerter.Alert(ctx, a); err != nil {
+			log.Logger.Errorf("error delivering alert: %v", err)
+		}
+	}
+	if err == nil && len(anomalies) == 0 {
+		metricLastSuccess.SetToCurrentTime()
+	}
+}
+
+// check fetches the current checkpoint, proves it consistent with the last verified checkpoint and
+// verifies the sampled entries. Anomalies are returned as alerts; any other error means the check
+// could not be completed and will be retried on the next round.
+func (m *monitor) check(ctx context.Context) ([]Alert, error) {
+	var alerts []Alert
+	sth, err := m.fetchCheckpoint(ctx)
+	if err != nil {
+		return m.collect(alerts, nil, err)
+	}
+	log.Logger.Infof("Found and verified checkpoint at size %d", sth.Size)
+
+	start := uint64(0)
+	if m.last != nil {
+		if err := m.proveConsistency(ctx, m.last.Size, m.last.Hash, sth.Size, sth.Hash); err != nil {
+			// keep the last good checkpoint so that later rounds are checked against it too
+			return m.collect(alerts, sth, err)
+		}
+		start = m.last.Size
+	}
+
+	for _, index := range sampleIndices(m.rand, start, sth.Size, m.sampleSize) {
+		err := m.verifyEntry(ctx, index, sth)
+		if err != nil {
+			var a *anomalyError
+			if !errors.As(err, &a) {
+				// the sample can be retried later, so don't advance past it
+				return m.collect(alerts, sth, err)
+			}
+			alerts = append(alerts, m.alert(sth, a))
+			continue
+		}
+		metricEntriesVerified.Inc()
+	}
+
+	m.last = sth
+	metricVerifiedTreeSize.Set(float64(sth.Size))
+	if err := m.persist(); err != nil {
+		log.Logger.Warnf("unable to store monitor state: %v", err)
+	}
+	return alerts, nil
+}
+
+// collect converts err into an alert if it describes an anomaly, or returns it otherwise
+func (m *monitor) collect(alerts []Alert, sth *util.SignedCheckpoint, err error) ([]Alert, error) {
+	var a *anomalyError
+	if errors.As(err, &a) {
+		return append(alerts, m.alert(sth, a)), nil
+	}
+	return alerts, err
+}
+
+func (m *monitor) alert(sth *util.SignedCheckpoint, a *anomalyError) Alert {
+	alert := Alert{
+		Kind:     a.kind,
+		Message:  a.Error(),
+		Server:   m.serverURL,
+		LogIndex: a.logIndex,
+		Time:     time.Now().UTC(),
+	}
+	if sth != nil {
+		alert.TreeSize = sth.Size
+	}
+	return alert
+}
+
+// fetchCheckpoint retrieves the current signed tree head and verifies it against the log's public key
+func (m *monitor) fetchCheckpoint(ctx context.Context) (*util.SignedCheckpoint, error) {
+	params := tlog.NewGetLogInfoParamsWithContext(ctx)
+	params.SetTimeout(m.timeout)
+	li, err := m.client.Tlog.GetLogInfo(params)
+	if err != nil {
+		return nil, fmt.Errorf("getting log info: %w", err)
+	}
+	sth := util.SignedCheckpoint{}
+	if err := sth.UnmarshalText([]byte(swag.StringValue(li.Payload.SignedTreeHead))); err != nil {
+		return nil, newAnomaly(anomalyCheckpointSignature, fmt.Errorf("unmarshalling signed tree head: %w", err))
+	}
+	if !sth.Verify(m.verifier) {
+		return nil, newAnomaly(anomalyCheckpointSignature, fmt.Errorf("signature on tree head of size %d did not verify", sth.Size))
+	}
+	if swag.Int64Value(li.Payload.TreeSize) != int64(sth.Size) || !strings.EqualFold(swag.StringValue(li.Payload.RootHash), hex.EncodeToString(sth.Hash)) {
+		return nil, newAnomaly(anomalyCheckpointSignature, errors.New("unsigned tree size and root hash differ from the signed tree head"))
+	}
+	return &sth, nil
+}
+
+// proveConsistency fetches and verifies a consistency proof between two states of the log
+func (m *monitor) proveConsistency(ctx context.Context, oldSize uint64, oldHash []byte, newSize uint64, newHash []byte) error {
+	switch {
+	case oldSize == newSize:
+		if !bytes.Equal(oldHash, newHash) {
+			return newAnomaly(anomalyInconsistentLog, fmt.Errorf("root hashes differ for the same tree size %d", oldSize))
+		}
+		return nil
+	case oldSize > newSize:
+		return newAnomaly(anomalyInconsistentLog, fmt.Errorf("tree size %d is less than previously observed tree size %d", newSize, oldSize))
+	case oldSize == 0:
+		// every tree is consistent with the empty tree
+		return nil
+	}
+
+	params := tlog.NewGetLogProofParamsWithContext(ctx)
+	params.SetTimeout(m.timeout)
+	firstSize := int64(oldSize)
+	params.FirstSize = &firstSize
+	params.LastSize = int64(newSize)
+	proof, err := m.client.Tlog.GetLogProof(params)
+	if err != nil {
+		return fmt.Errorf("getting consistency proof between %d and %d: %w", oldSize, newSize, err)
+	}
+	if err := verify.ConsistencyProof(oldSize, oldHash, newSize, newHash, proof.Payload.Hashes); err != nil {
+		return newAnomaly(anomalyInconsistentLog, fmt.Errorf("consistency proof between %d and %d did not verify: %w", oldSize, newSize, err))
+	}
+	return nil
+}
+
+// verifyEntry fetches the entry at index and checks its signed entry timestamp, its UUID, its
+// inclusion in the log at the checkpoint and that its body is still accepted by its type
+func (m *monitor) verifyEntry(ctx context.Context, index int64, sth *util.SignedCheckpoint) error {
+	params := entries.NewGetLogEntryByIndexParamsWithContext(ctx)
+	params.SetTimeout(m.timeout)
+	params.LogIndex = index
+	resp, err := m.client.Entries.GetLogEntryByIndex(params)
+	if err != nil {
+		return fmt.Errorf("getting entry %d: %w", index, err)
+	}
+	if len(resp.Payload) != 1 {
+		return newEntryAnomaly(anomalyEntryBody, index, fmt.Errorf("expected one entry at index %d, got %d", index, len(resp.Payload)))
+	}
+	for uuid, e := range resp.Payload {
+		if err := verify.SignedEntryTimestamp(m.verifier, e); err != nil {
+			return newEntryAnomaly(anomalyEntrySET, index, err)
+		}
+
+		body, err := base64.StdEncoding.DecodeString(fmt.Sprint(e.Body))
+		if err != nil {
+			return newEntryAnomaly(anomalyEntryBody, index, fmt.Errorf("decoding body of entry %d: %w", index, err))
+		}
+		if !strings.EqualFold(uuid, util.EntryUUID(body)) {
+			return newEntryAnomaly(anomalyEntryUUID, index, fmt.Errorf("UUID %s of entry %d is not the leaf hash of its body", uuid, index))
+		}
+
+		if err := m.verifyEntryInclusion(ctx, body, e, sth); err != nil {
+			var a *anomalyError
+			if errors.As(err, &a) {
+				a.logIndex = &index
+			}
+			return err
+		}
+
+		if _, err := verify.ParseBody(body); err != nil {
+			return newEntryAnomaly(anomalyEntryBody, index, fmt.Errorf("entry %d: %w", index, err))
+		}
+	}
+	return nil
+}
+
+// verifyEntryInclusion checks the inclusion proof returned with the entry, and that the tree it
+// was computed against is consistent with the checkpoint
+func (m *monitor) verifyEntryInclusion(ctx context.Context, body []byte, e models.LogEntryAnon, sth *util.SignedCheckpoint) error {
+	if e.Verification.InclusionProof == nil {
+		return newAnomaly(anomalyEntryInclusion, errors.New("inclusion proof missing"))
+	}
+	proof := e.Verification.InclusionProof
+	if err := verify.InclusionProof(body, proof); err != nil {
+		return newAnomaly(anomalyEntryInclusion, err)
+	}
+	rootHash, _ := hex.DecodeString(swag.StringValue(proof.RootHash))
+	treeSize := swag.Int64Value(proof.TreeSize)
+
+	// the proof may have been computed against a more recent tree than the checkpoint
+	if uint64(treeSize) <= sth.Size {
+		return m.proveConsistency(ctx, uint64(treeSize), rootHash, sth.Size, sth.Hash)
+	}
+	return m.proveConsistency(ctx, sth.Size, sth.Hash, uint64(treeSize), rootHash)
+}
+
+// sampleIndices returns up to n distinct indices chosen uniformly from [start, end), in ascending
+// order of selection. All indices are returned if the range holds no more than n of them.
+func sampleIndices(r *rand.Rand, start, end uint64, n int) []int64 {
+	if end <= start || n <= 0 {
+		return nil
+	}
+	count := end - start
+	if count <= uint64(n) {
+		indices := make([]int64, 0, count)
+		for i := start; i < end; i++ {
+			indices = append(indices, int64(i))
+		}
+		return indices
+	}
+	seen := make(map[int64]struct{}, n)
+	indices := make([]int64, 0, n)
+	for len(indices) < n {
+		i := int64(start) + r.Int63n(int64(count))
+		if _, ok := seen[i]; ok {
+			continue
+		}
+		seen[i] = struct{}{}
+		indices = append(indices, i)
+	}
+	return indices
+}
+
+// loadState reads the last verified checkpoint persisted by a previous run, if any
+func (m *monitor) loadState() error {
+	if m.stateFile == "" {
+		return nil
+	}
+	b, err := ioutil.ReadFile(filepath.Clean(m.stateFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	sth := util.SignedCheckpoint{}
+	if err := sth.UnmarshalText(b); err != nil {
+		return fmt.Errorf("parsing monitor state %s: %w", m.stateFile, err)
+	}
+	if !sth.Verify(m.verifier) {
+		return fmt.Errorf("signature on checkpoint in monitor state %s did not verify", m.stateFile)
+	}
+	m.last = &sth
+	metricVerifiedTreeSize.Set(float64(sth.Size))
+	return nil
+}
+
+// persist atomically writes the last verified checkpoint to the state file
+func (m *monitor) persist() error {
+	if m.stateFile == "" || m.last == nil {
+		return nil
+	}
+	b, err := m.last.MarshalText()
+	if err != nil {
+		return err
+	}
+	f, err := ioutil.TempFile(filepath.Dir(m.stateFile), filepath.Base(m.stateFile)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), m.stateFile)
+}
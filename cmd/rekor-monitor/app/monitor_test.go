@@ -0,0 +1,115 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package app
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	crand "crypto/rand"
+	"io/ioutil"
+	"math/rand"
+	"path/filepath"
+	"testing"
+
+	"github.com/sigstore/rekor/pkg/util"
+	"github.com/sigstore/sigstore/pkg/signature"
+	"github.com/sigstore/sigstore/pkg/signature/options"
+)
+
+func TestSampleIndices(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for _, tt := range []struct {
+		name       string
+		start, end uint64
+		n          int
+		wantLen    int
+	}{
+		{name: "empty range", start: 5, end: 5, n: 3, wantLen: 0},
+		{name: "sampling disabled", start: 0, end: 100, n: 0, wantLen: 0},
+		{name: "small range", start: 10, end: 13, n: 5, wantLen: 3},
+		{name: "large range", start: 100, end: 1000000, n: 20, wantLen: 20},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			got := sampleIndices(r, tt.start, tt.end, tt.n)
+			if len(got) != tt.wantLen {
+				t.Fatalf("got %d indices, want %d", len(got), tt.wantLen)
+			}
+			seen := map[int64]bool{}
+			for _, i := range got {
+				if i < int64(tt.start) || i >= int64(tt.end) {
+					t.Errorf("index %d outside of [%d, %d)", i, tt.start, tt.end)
+				}
+				if seen[i] {
+					t.Errorf("index %d sampled twice", i)
+				}
+				seen[i] = true
+			}
+		})
+	}
+}
+
+func TestMonitorState(t *testing.T) {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), crand.Reader)
+	signer, _ := signature.LoadSigner(key, crypto.SHA256)
+	verifier, _ := signature.LoadVerifier(key.Public(), crypto.SHA256)
+
+	sth, err := util.CreateSignedCheckpoint(util.Checkpoint{
+		Origin: "Rekor",
+		Size:   42,
+		Hash:   []byte("0123456789abcdef0123456789abcdef"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sth.Sign("rekor", signer, options.WithCryptoSignerOpts(crypto.SHA256)); err != nil {
+		t.Fatal(err)
+	}
+
+	stateFile := filepath.Join(t.TempDir(), "state")
+	m := &monitor{verifier: verifier, stateFile: stateFile, last: sth}
+	if err := m.persist(); err != nil {
+		t.Fatalf("persist: %v", err)
+	}
+
+	loaded := &monitor{verifier: verifier, stateFile: stateFile}
+	if err := loaded.loadState(); err != nil {
+		t.Fatalf("loadState: %v", err)
+	}
+	if loaded.last == nil || loaded.last.Size != 42 {
+		t.Fatalf("unexpected state loaded: %+v", loaded.last)
+	}
+
+	// a checkpoint signed by another key must be rejected
+	otherKey, _ := ecdsa.GenerateKey(elliptic.P256(), crand.Reader)
+	otherVerifier, _ := signature.LoadVerifier(otherKey.Public(), crypto.SHA256)
+	if err := (&monitor{verifier: otherVerifier, stateFile: stateFile}).loadState(); err == nil {
+		t.Error("expected error loading state signed by a different key")
+	}
+
+	// a missing state file is not an error
+	missing := &monitor{verifier: verifier, stateFile: filepath.Join(t.TempDir(), "missing")}
+	if err := missing.loadState(); err != nil || missing.last != nil {
+		t.Errorf("unexpected result for missing state file: %v %v", missing.last, err)
+	}
+
+	if err := ioutil.WriteFile(stateFile, []byte("garbage"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := (&monitor{verifier: verifier, stateFile: stateFile}).loadState(); err == nil {
+		t.Error("expected error loading malformed state")
+	}
+}
@@ -0,0 +1,237 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package app
+
+import (
+	"context"
+	"crypto"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	homedir "github.com/mitchellh/go-homedir"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/sigstore/rekor/pkg/client"
+	genclient "github.com/sigstore/rekor/pkg/generated/client"
+	"github.com/sigstore/rekor/pkg/log"
+	"github.com/sigstore/sigstore/pkg/signature"
+
+	// these imports register the supported entry types so that sampled entries can be re-validated
+	_ "github.com/sigstore/rekor/pkg/types/alpine/v0.0.1"
+	_ "github.com/sigstore/rekor/pkg/types/checksums/v0.0.1"
+	_ "github.com/sigstore/rekor/pkg/types/hashedrekord/v0.0.1"
+	_ "github.com/sigstore/rekor/pkg/types/helm/v0.0.1"
+	_ "github.com/sigstore/rekor/pkg/types/intoto/v0.0.1"
+	_ "github.com/sigstore/rekor/pkg/types/jar/v0.0.1"
+	_ "github.com/sigstore/rekor/pkg/types/oci/v0.0.1"
+	_ "github.com/sigstore/rekor/pkg/types/rekord/v0.0.1"
+	_ "github.com/sigstore/rekor/pkg/types/rekord/v0.0.2"
+	_ "github.com/sigstore/rekor/pkg/types/rfc3161/v0.0.1"
+	_ "github.com/sigstore/rekor/pkg/types/rpm/v0.0.1"
+	_ "github.com/sigstore/rekor/pkg/types/sbom/v0.0.1"
+	_ "github.com/sigstore/rekor/pkg/types/tuf/v0.0.1"
+)
+
+var cfgFile string
+
+// rootCmd represents the base command when called without any subcommands
+var rootCmd = &cobra.Command{
+	Use:   "rekor-monitor",
+	Short: "Rekor transparency log monitor",
+	Long: `Monitors a Rekor transparency log: every checkpoint published by the log is verified
+	and proven consistent with the previously observed checkpoint, and a sample of the entries
+	added in between is re-verified. Anomalies are reported on stdout, to an optional webhook and
+	as Prometheus metrics.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// Setup the logger to dev/prod
+		log.ConfigureLogger(viper.GetString("log_type"))
+
+		// workaround for https://github.com/sigstore/rekor/issues/68
+		// from https://github.com/golang/glog/commit/fca8c8854093a154ff1eb580aae10276ad6b1b5f
+		_ = flag.CommandLine.Parse([]string{})
+
+		m, err := newMonitorFromViper()
+		if err != nil {
+			return err
+		}
+
+		ctx := context.Background()
+		if viper.GetBool("once") {
+			anomalies, err := m.check(ctx)
+			for _, a := range anomalies {
+				_ = m.alerter.Alert(ctx, a)
+			}
+			if err != nil {
+				return err
+			}
+			if len(anomalies) > 0 {
+				return fmt.Errorf("%d anomalies detected", len(anomalies))
+			}
+			return nil
+		}
+
+		if addr := viper.GetString("metrics_address"); addr != "" {
+			go func() {
+				mux := http.NewServeMux()
+				mux.Handle("/metrics", promhttp.Handler())
+				if err := http.ListenAndServe(addr, mux); err != nil && err != http.ErrServerClosed {
+					log.Logger.Fatalf("Error when starting or running metrics server: %v", err)
+				}
+			}()
+		}
+
+		ctx, cancel := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+		defer cancel()
+		m.run(ctx, viper.GetDuration("interval"))
+		return nil
+	},
+}
+
+// Execute adds all child commands to the root command and sets flags appropriately.
+// This is called by main.main(). It only needs to happen once to the rootCmd.
+func Execute() {
+	if err := rootCmd.Execute(); err != nil {
+		log.Logger.Error(err)
+		os.Exit(1)
+	}
+}
+
+func init() {
+	cobra.OnInitialize(initConfig)
+
+	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.rekor-monitor.yaml)")
+	rootCmd.PersistentFlags().String("log_type", "dev", "logger type to use (dev/prod)")
+
+	rootCmd.Flags().String("rekor_server", "https://rekor.sigstore.dev", "URL of the Rekor server to monitor")
+	rootCmd.Flags().String("public_key", "", "path to the PEM encoded public key of the log; fetched from the server if unset")
+	rootCmd.Flags().Duration("interval", 5*time.Minute, "polling interval")
+	rootCmd.Flags().Duration("timeout", 30*time.Second, "timeout for each request made to the Rekor server")
+	rootCmd.Flags().Int("sample_size", 10, "number of new entries re-verified after each checkpoint (0 to disable)")
+	rootCmd.Flags().String("state_file", "", "file in which the last verified checkpoint is stored across restarts")
+	rootCmd.Flags().String("webhook_url", "", "URL to which alerts are POSTed as JSON")
+	rootCmd.Flags().Duration("webhook_timeout", 10*time.Second, "timeout for delivering an alert to the webhook")
+	rootCmd.Flags().String("metrics_address", ":2112", "address on which Prometheus metrics are served (empty to disable)")
+	rootCmd.Flags().Bool("once", false, "perform a single check and exit with an error if any anomaly was found")
+
+	if err := viper.BindPFlags(rootCmd.PersistentFlags()); err != nil {
+		log.Logger.Fatal(err)
+	}
+	if err := viper.BindPFlags(rootCmd.Flags()); err != nil {
+		log.Logger.Fatal(err)
+	}
+}
+
+// initConfig reads in config file and ENV variables if set.
+func initConfig() {
+	if cfgFile != "" {
+		// Use config file from the flag.
+		viper.SetConfigFile(cfgFile)
+	} else {
+		// Find home directory.
+		home, err := homedir.Dir()
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		viper.AddConfigPath(home)
+		viper.AddConfigPath(".")
+		viper.SetConfigName("rekor-monitor")
+		viper.SetConfigType("yaml")
+	}
+
+	viper.AutomaticEnv() // read in environment variables that match
+
+	// If a config file is found, read it in.
+	if err := viper.ReadInConfig(); err == nil {
+		log.Logger.Infof("Using config file: %s", viper.ConfigFileUsed())
+	}
+}
+
+func newMonitorFromViper() (*monitor, error) {
+	serverURL := viper.GetString("rekor_server")
+	c, err := client.GetRekorClient(serverURL, client.WithUserAgent("rekor-monitor"))
+	if err != nil {
+		return nil, err
+	}
+
+	verifier, err := loadLogVerifier(c, viper.GetString("public_key"))
+	if err != nil {
+		return nil, err
+	}
+
+	alerters := multiAlerter{logAlerter{}}
+	if url := viper.GetString("webhook_url"); url != "" {
+		alerters = append(alerters, newWebhookAlerter(url, viper.GetDuration("webhook_timeout")))
+	}
+
+	m := &monitor{
+		client:     c,
+		serverURL:  serverURL,
+		verifier:   verifier,
+		sampleSize: viper.GetInt("sample_size"),
+		rand:       rand.New(rand.NewSource(time.Now().UnixNano())), // nolint: gosec
+		timeout:    viper.GetDuration("timeout"),
+		alerter:    alerters,
+		stateFile:  viper.GetString("state_file"),
+	}
+	if err := m.loadState(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// loadLogVerifier loads the log's public key from keyPath, falling back to fetching it from the server
+func loadLogVerifier(c *genclient.Rekor, keyPath string) (signature.Verifier, error) {
+	var keyPEM []byte
+	if keyPath != "" {
+		b, err := ioutil.ReadFile(filepath.Clean(keyPath))
+		if err != nil {
+			return nil, err
+		}
+		keyPEM = b
+	} else {
+		log.Logger.Warn("no public key specified, trusting the key served by the log")
+		keyResp, err := c.Pubkey.GetPublicKey(nil)
+		if err != nil {
+			return nil, err
+		}
+		keyPEM = []byte(keyResp.Payload)
+	}
+
+	block, _ := pem.Decode(keyPEM)
+	if block == nil {
+		return nil, errors.New("failed to decode public key of server")
+	}
+	pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	return signature.LoadVerifier(pub, crypto.SHA256)
+}
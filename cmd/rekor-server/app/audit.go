@@ -0,0 +1,62 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package app
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/sigstore/rekor/pkg/audit"
+)
+
+// verifyAuditLogCmd checks the hash chain of an audit log written by the server
+var verifyAuditLogCmd = &cobra.Command{
+	Use:   "verify-audit-log [path]",
+	Short: "Verify the hash chain of an audit log",
+	Long: `Checks that every record of an audit log written with --audit.file is linked to the record before it
+by its hash, so that records which were modified, removed or reordered after they were written are
+detected. Records appended at the end of the log by someone able to write to it are not detected.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path := viper.GetString("audit.file")
+		if len(args) == 1 {
+			path = args[0]
+		}
+		if path == "" {
+			return errors.New("the path of the audit log must be given as an argument or with --audit.file")
+		}
+		f, err := os.Open(filepath.Clean(path))
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		n, err := audit.Verify(f)
+		if err != nil {
+			return fmt.Errorf("%v: %w", path, err)
+		}
+		fmt.Printf("%v: %d records verified\n", path, n)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(verifyAuditLogCmd)
+}
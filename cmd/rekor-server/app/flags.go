@@ -80,11 +80,7 @@ func (l *LogRangesFlag) Set(s string) error {
 }
 
 func (l *LogRangesFlag) String() string {
-	ranges := []string{}
-	for _, r := range l.Ranges.Ranges {
-		ranges = append(ranges, fmt.Sprintf("%d=%d", r.TreeID, r.TreeLength))
-	}
-	return strings.Join(ranges, ",")
+	return l.Ranges.String()
 }
 
 func (l *LogRangesFlag) Type() string {
@@ -0,0 +1,311 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/go-openapi/swag"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/sigstore/rekor/pkg/api"
+	"github.com/sigstore/rekor/pkg/client"
+	"github.com/sigstore/rekor/pkg/generated/client/tlog"
+	"github.com/sigstore/rekor/pkg/generated/models"
+	"github.com/sigstore/rekor/pkg/log"
+	"github.com/sigstore/rekor/pkg/verify"
+	"github.com/sigstore/sigstore/pkg/signature"
+)
+
+// importCmd represents the import command
+var importCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Import the entries of another Rekor log",
+	Long: `Replays the entries of another Rekor log into the log of this instance, reading them from the
+API of its server or from a dump of its entries as newline-delimited JSON, as served by
+` + api.StreamPath + `. The signed entry timestamp and any inclusion proof of each
+entry are verified against the public key of the source log, and its body is checked as if it were
+proposed to this log, before it is appended along with where it was originally logged. Entries which
+are already in the log are skipped, so an interrupted import can be run again.`,
+	PreRun: func(cmd *cobra.Command, args []string) {
+		// these are bound here so that they are not overwritten by other commands
+		if err := viper.BindPFlags(cmd.Flags()); err != nil {
+			log.Logger.Fatal("Error initializing cmd line args: ", err)
+		}
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		log.ConfigureLogger(viper.GetString("log_type"))
+		// workaround for https://github.com/sigstore/rekor/issues/68
+		_ = flag.CommandLine.Parse([]string{})
+
+		from := viper.GetString("from")
+		if from == "" {
+			return errors.New("--from must be set to the URL of a Rekor server or the path of a dump of its entries")
+		}
+		start, end := viper.GetInt64("start"), viper.GetInt64("end")
+		if start < 0 || (end >= 0 && end < start) {
+			return fmt.Errorf("invalid range of entries from %d to %d", start, end)
+		}
+
+		ctx := context.Background()
+		src, err := newImportSource(ctx, from, viper.GetString("from_public_key"))
+		if err != nil {
+			return err
+		}
+		cfg, err := api.LoadConfig(viper.GetViper())
+		if err != nil {
+			return err
+		}
+		api.ConfigureAPI(cfg, logRangeMap.Ranges)
+
+		imp, err := newImporter(from, src.verifier, func(body []byte, p api.Provenance) (int64, error) {
+			return api.ImportEntry(ctx, body, p)
+		})
+		if err != nil {
+			return err
+		}
+		err = src.forEach(ctx, start, end, imp.importEntry)
+		log.Logger.Infof("imported %d entries, skipped %d already in the log", imp.imported, imp.skipped)
+		return err
+	},
+}
+
+func init() {
+	importCmd.Flags().String("from", "", "URL of the Rekor server to import from, or path to a dump of its entries as newline-delimited JSON")
+	importCmd.Flags().String("from_public_key", "", "path to the PEM encoded public key of the source log; fetched from the server if unset and --from is a URL")
+	importCmd.Flags().Int64("start", 0, "index of the first entry of the source log to import")
+	importCmd.Flags().Int64("end", -1, "index after the last entry of the source log to import; defaults to the size of the source log")
+	rootCmd.AddCommand(importCmd)
+}
+
+// importSource reads the entries of the source log
+type importSource struct {
+	server   string // URL of the server, if the source is not a local dump
+	path     string
+	verifier signature.Verifier
+}
+
+func newImportSource(ctx context.Context, from, keyPath string) (*importSource, error) {
+	src := &importSource{}
+	if u, err := url.Parse(from); err == nil && (u.Scheme == "http" || u.Scheme == "https") {
+		src.server = strings.TrimSuffix(from, "/")
+	} else {
+		src.path = from
+	}
+
+	var keyPEM []byte
+	switch {
+	case keyPath != "":
+		b, err := ioutil.ReadFile(filepath.Clean(keyPath))
+		if err != nil {
+			return nil, err
+		}
+		keyPEM = b
+	case src.server != "":
+		log.Logger.Warnf("no public key specified, trusting the key served by %v", src.server)
+		c, err := client.GetRekorClient(src.server)
+		if err != nil {
+			return nil, err
+		}
+		keyResp, err := c.Pubkey.GetPublicKey(nil)
+		if err != nil {
+			return nil, fmt.Errorf("fetching public key of %v: %w", src.server, err)
+		}
+		keyPEM = []byte(keyResp.Payload)
+	default:
+		return nil, errors.New("--from_public_key must be set when importing from a dump")
+	}
+	var err error
+	if src.verifier, err = verify.LoadLogVerifier(keyPEM); err != nil {
+		return nil, err
+	}
+	return src, nil
+}
+
+// forEach calls f for the entries from index start up to, but excluding, end, or the end of the log
+// if end is negative, in order of their index
+func (s *importSource) forEach(ctx context.Context, start, end int64, f func(uuid string, e models.LogEntryAnon) error) error {
+	inRange := func(uuid string, e models.LogEntryAnon) error {
+		index := swag.Int64Value(e.LogIndex)
+		if index < start || (end >= 0 && index >= end) {
+			return nil
+		}
+		return f(uuid, e)
+	}
+	if s.server == "" {
+		file, err := os.Open(filepath.Clean(s.path))
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		return readEntries(file, inRange)
+	}
+
+	c, err := client.GetRekorClient(s.server)
+	if err != nil {
+		return err
+	}
+	info, err := c.Tlog.GetLogInfo(tlog.NewGetLogInfoParamsWithContext(ctx))
+	if err != nil {
+		return fmt.Errorf("getting log info of %v: %w", s.server, err)
+	}
+	if size := swag.Int64Value(info.Payload.TreeSize); end < 0 || end > size {
+		end = size
+	}
+
+	// streams are cut off by the write timeout of the server, so they are resumed after the last
+	// entry received until the whole range has been read
+	next := start
+	for next < end {
+		resumeAt := next
+		err := s.stream(ctx, next, end, func(uuid string, e models.LogEntryAnon) error {
+			if index := swag.Int64Value(e.LogIndex); index != next {
+				return fmt.Errorf("%v returned entry %d instead of %d", s.server, index, next)
+			}
+			if err := f(uuid, e); err != nil {
+				return err
+			}
+			next++
+			return nil
+		})
+		if next == resumeAt {
+			if err == nil {
+				err = errors.New("stream ended without any entries")
+			}
+			return fmt.Errorf("reading entries from %d of %v: %w", next, s.server, err)
+		}
+		if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+			return err
+		}
+	}
+	return nil
+}
+
+// stream requests the entries from index start up to end with their inclusion proofs
+func (s *importSource) stream(ctx context.Context, start, end int64, f func(uuid string, e models.LogEntryAnon) error) error {
+	u := fmt.Sprintf("%v%v?start=%d&end=%d&proofs=true", s.server, api.StreamPath, start, end)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %v", resp.Status)
+	}
+	return readEntries(resp.Body, f)
+}
+
+// readEntries decodes newline-delimited LogEntry objects, stopping at an Error object
+func readEntries(r io.Reader, f func(uuid string, e models.LogEntryAnon) error) error {
+	dec := json.NewDecoder(r)
+	for {
+		var raw map[string]json.RawMessage
+		if err := dec.Decode(&raw); err == io.EOF {
+			return nil
+		} else if err != nil {
+			if err == io.ErrUnexpectedEOF {
+				return err
+			}
+			return fmt.Errorf("decoding entry: %w", err)
+		}
+		if _, ok := raw["code"]; ok {
+			if msg, ok := raw["message"]; ok {
+				return fmt.Errorf("source log returned an error: %s", msg)
+			}
+		}
+		for uuid, v := range raw {
+			var e models.LogEntryAnon
+			if err := json.Unmarshal(v, &e); err != nil {
+				return fmt.Errorf("decoding entry %v: %w", uuid, err)
+			}
+			if err := f(uuid, e); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// importer verifies the entries of the source log and adds them to this log
+type importer struct {
+	source   string
+	verifier signature.Verifier
+	logID    string // SHA256 hash of the public key of the source log
+	add      func(body []byte, p api.Provenance) (int64, error)
+
+	imported, skipped int
+}
+
+func newImporter(source string, verifier signature.Verifier, add func(body []byte, p api.Provenance) (int64, error)) (*importer, error) {
+	logID, err := verify.LogID(verifier)
+	if err != nil {
+		return nil, err
+	}
+	return &importer{source: source, verifier: verifier, logID: logID, add: add}, nil
+}
+
+func (i *importer) importEntry(uuid string, e models.LogEntryAnon) error {
+	body, err := i.verify(uuid, e)
+	if err != nil {
+		return err
+	}
+	index := swag.Int64Value(e.LogIndex)
+	p := api.Provenance{
+		Source:         i.source,
+		LogID:          i.logID,
+		LogIndex:       index,
+		UUID:           strings.ToLower(uuid),
+		IntegratedTime: swag.Int64Value(e.IntegratedTime),
+		ImportedTime:   time.Now().Unix(),
+	}
+	newIndex, err := i.add(body, p)
+	if errors.Is(err, api.ErrEntryExists) {
+		log.Logger.Infof("skipping entry %d (%v), which is already in the log", index, uuid)
+		i.skipped++
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("importing entry %d (%v): %w", index, uuid, err)
+	}
+	log.Logger.Infof("imported entry %d (%v) as entry %d", index, uuid, newIndex)
+	i.imported++
+	return nil
+}
+
+// verify checks that an entry was logged by the source log and returns its body
+func (i *importer) verify(uuid string, e models.LogEntryAnon) ([]byte, error) {
+	body, err := verify.Entry(i.verifier, uuid, e)
+	if err != nil {
+		return nil, fmt.Errorf("entry %v was not logged by the source log: %w", uuid, err)
+	}
+	return body, nil
+}
@@ -0,0 +1,163 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package app
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	crand "crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/cyberphone/json-canonicalization/go/src/webpki.org/jsoncanonicalizer"
+	"github.com/go-openapi/swag"
+	"github.com/sigstore/sigstore/pkg/signature"
+
+	"github.com/sigstore/rekor/pkg/api"
+	"github.com/sigstore/rekor/pkg/generated/models"
+	"github.com/sigstore/rekor/pkg/util"
+)
+
+// sourceEntry creates an entry as it would be served by a log signing with signer
+func sourceEntry(t *testing.T, signer signature.Signer, logID string, index int64, body []byte) (string, models.LogEntryAnon) {
+	t.Helper()
+	e := models.LogEntryAnon{
+		LogID:          swag.String(logID),
+		LogIndex:       swag.Int64(index),
+		IntegratedTime: swag.Int64(1600000000 + index),
+		Body:           base64.StdEncoding.EncodeToString(body),
+	}
+	payload, err := e.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	canonicalized, err := jsoncanonicalizer.Transform(payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := signer.SignMessage(bytes.NewReader(canonicalized))
+	if err != nil {
+		t.Fatal(err)
+	}
+	e.Verification = &models.LogEntryAnonVerification{SignedEntryTimestamp: sig}
+	return util.EntryUUID(body), e
+}
+
+func TestImporter(t *testing.T) {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), crand.Reader)
+	signer, _ := signature.LoadSigner(key, crypto.SHA256)
+	verifier, _ := signature.LoadVerifier(key.Public(), crypto.SHA256)
+
+	var added []api.Provenance
+	imp, err := newImporter("https://rekor.example.com", verifier, func(body []byte, p api.Provenance) (int64, error) {
+		if p.LogIndex == 1 {
+			return 0, api.ErrEntryExists
+		}
+		added = append(added, p)
+		return int64(len(added) - 1), nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var dump bytes.Buffer
+	enc := json.NewEncoder(&dump)
+	for i := int64(0); i < 3; i++ {
+		uuid, e := sourceEntry(t, signer, imp.logID, i, []byte(fmt.Sprintf(`{"entry":%d}`, i)))
+		if err := enc.Encode(models.LogEntry{uuid: e}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := readEntries(&dump, imp.importEntry); err != nil {
+		t.Fatalf("unexpected error importing entries: %v", err)
+	}
+	if imp.imported != 2 || imp.skipped != 1 || len(added) != 2 {
+		t.Fatalf("imported %d and skipped %d entries, want 2 and 1", imp.imported, imp.skipped)
+	}
+	if p := added[1]; p.LogIndex != 2 || p.LogID != imp.logID || p.UUID != util.EntryUUID([]byte(`{"entry":2}`)) || p.IntegratedTime != 1600000002 {
+		t.Errorf("unexpected provenance %+v", p)
+	}
+
+	body := []byte(`{"entry":0}`)
+	otherKey, _ := ecdsa.GenerateKey(elliptic.P256(), crand.Reader)
+	otherSigner, _ := signature.LoadSigner(otherKey, crypto.SHA256)
+	tests := []struct {
+		desc  string
+		entry func() (string, models.LogEntryAnon)
+	}{
+		{
+			desc: "signed by another log",
+			entry: func() (string, models.LogEntryAnon) {
+				return sourceEntry(t, otherSigner, imp.logID, 0, body)
+			},
+		},
+		{
+			desc: "logged by another log",
+			entry: func() (string, models.LogEntryAnon) {
+				return sourceEntry(t, signer, strings.Repeat("0", 64), 0, body)
+			},
+		},
+		{
+			desc: "UUID of another body",
+			entry: func() (string, models.LogEntryAnon) {
+				_, e := sourceEntry(t, signer, imp.logID, 0, body)
+				return util.EntryUUID([]byte("other")), e
+			},
+		},
+		{
+			desc: "index changed",
+			entry: func() (string, models.LogEntryAnon) {
+				uuid, e := sourceEntry(t, signer, imp.logID, 0, body)
+				e.LogIndex = swag.Int64(1)
+				return uuid, e
+			},
+		},
+		{
+			desc: "invalid inclusion proof",
+			entry: func() (string, models.LogEntryAnon) {
+				uuid, e := sourceEntry(t, signer, imp.logID, 0, body)
+				e.Verification.InclusionProof = &models.InclusionProof{
+					LogIndex: swag.Int64(0),
+					TreeSize: swag.Int64(1),
+					RootHash: swag.String(strings.Repeat("0", 64)),
+					Hashes:   []string{},
+				}
+				return uuid, e
+			},
+		},
+	}
+	for _, tt := range tests {
+		if _, err := imp.verify(tt.entry()); err == nil {
+			t.Errorf("%v: expected error", tt.desc)
+		}
+	}
+}
+
+func TestReadEntriesError(t *testing.T) {
+	stream := `{"code":500,"message":"error communicating with backend"}` + "\n"
+	err := readEntries(strings.NewReader(stream), func(uuid string, e models.LogEntryAnon) error {
+		t.Errorf("unexpected entry %v", uuid)
+		return nil
+	})
+	if err == nil {
+		t.Error("expected error reading a stream which ended with an error")
+	}
+}
@@ -0,0 +1,143 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package app
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"github.com/sigstore/rekor/pkg/api"
+	"github.com/sigstore/rekor/pkg/generated/restapi"
+	"github.com/sigstore/rekor/pkg/log"
+)
+
+// assignListeners hands the first TCP and the first unix listener to the generated server, which
+// only supports one of each, and returns the remaining ones to be served by serveAdditional
+func assignListeners(server *restapi.Server, listeners []api.Listener) ([]api.Listener, error) {
+	var tcp, unix bool
+	var rest []api.Listener
+	server.EnabledListeners = nil
+	server.SocketPath = ""
+	for _, l := range listeners {
+		switch {
+		case l.Network == "tcp" && !tcp:
+			host, port, err := net.SplitHostPort(l.Address)
+			if err != nil {
+				return nil, err
+			}
+			if server.Port, err = strconv.Atoi(port); err != nil {
+				return nil, errors.Wrapf(err, "invalid port in %s", l)
+			}
+			server.Host = host
+			server.EnabledListeners = append(server.EnabledListeners, "http")
+			tcp = true
+		case l.Network == "unix" && !unix:
+			server.SocketPath = l.Address
+			server.EnabledListeners = append(server.EnabledListeners, "unix")
+			unix = true
+		default:
+			rest = append(rest, l)
+		}
+	}
+	return rest, nil
+}
+
+// removeStaleSocket removes a unix socket left behind by a previous process, which would otherwise
+// prevent listening on the same path; other kinds of files are left alone
+func removeStaleSocket(path string) error {
+	fi, err := os.Lstat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	if fi.Mode()&os.ModeSocket == 0 {
+		return errors.Errorf("%s exists and is not a unix socket", path)
+	}
+	return os.Remove(path)
+}
+
+// listen opens a listener, applying mode to unix sockets so that e.g. a reverse proxy running as
+// another user in the same group is able to connect
+func listen(l api.Listener, mode os.FileMode) (net.Listener, error) {
+	if l.Network == "unix" {
+		if err := removeStaleSocket(l.Address); err != nil {
+			return nil, err
+		}
+	}
+	ln, err := net.Listen(l.Network, l.Address)
+	if err != nil {
+		return nil, err
+	}
+	if l.Network == "unix" {
+		if err := os.Chmod(l.Address, mode); err != nil {
+			_ = ln.Close()
+			return nil, err
+		}
+	}
+	return ln, nil
+}
+
+// serveAdditional serves the handler of the generated server on further listeners, with the same
+// limits and timeouts; the returned servers must be shut down along with it
+func serveAdditional(server *restapi.Server, listeners []api.Listener, mode os.FileMode) ([]*http.Server, error) {
+	var servers []*http.Server
+	for _, l := range listeners {
+		ln, err := listen(l, mode)
+		if err != nil {
+			shutdownServers(context.Background(), servers)
+			return nil, errors.Wrapf(err, "listening on %s", l)
+		}
+		srv := &http.Server{
+			Handler:        server.GetHandler(),
+			MaxHeaderBytes: int(server.MaxHeaderSize),
+			ReadTimeout:    server.ReadTimeout,
+			WriteTimeout:   server.WriteTimeout,
+			IdleTimeout:    server.CleanupTimeout,
+		}
+		servers = append(servers, srv)
+		log.Logger.Infof("Serving rekor server at %s", l)
+		go func(l api.Listener) {
+			if err := srv.Serve(ln); err != nil && err != http.ErrServerClosed {
+				log.Logger.Fatal(err)
+			}
+			log.Logger.Infof("Stopped serving rekor server at %s", l)
+		}(l)
+	}
+	return servers, nil
+}
+
+// shutdownServers gracefully shuts down the servers concurrently, waiting at most until ctx is done
+func shutdownServers(ctx context.Context, servers []*http.Server) {
+	var wg sync.WaitGroup
+	for _, srv := range servers {
+		wg.Add(1)
+		go func(srv *http.Server) {
+			defer wg.Done()
+			if err := srv.Shutdown(ctx); err != nil {
+				log.Logger.Errorf("HTTP server Shutdown: %v", err)
+			}
+		}(srv)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,99 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package app
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/sigstore/rekor/pkg/api"
+	"github.com/sigstore/rekor/pkg/generated/restapi"
+)
+
+func TestAssignListeners(t *testing.T) {
+	server := restapi.NewServer(nil)
+	rest, err := assignListeners(server, []api.Listener{
+		{Network: "tcp", Address: "127.0.0.1:3000"},
+		{Network: "unix", Address: "/run/rekor/rekor.sock"},
+		{Network: "tcp", Address: "[::1]:3001"},
+		{Network: "unix", Address: "/run/rekor/admin.sock"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if server.Host != "127.0.0.1" || server.Port != 3000 || server.SocketPath != "/run/rekor/rekor.sock" {
+		t.Errorf("unexpected server listeners %s:%d, %s", server.Host, server.Port, server.SocketPath)
+	}
+	if diff := cmp.Diff([]string{"http", "unix"}, server.EnabledListeners); diff != "" {
+		t.Errorf("enabled listeners: %s", diff)
+	}
+	want := []api.Listener{
+		{Network: "tcp", Address: "[::1]:3001"},
+		{Network: "unix", Address: "/run/rekor/admin.sock"},
+	}
+	if diff := cmp.Diff(want, rest); diff != "" {
+		t.Errorf("additional listeners: %s", diff)
+	}
+
+	// only a unix socket
+	server = restapi.NewServer(nil)
+	rest, err = assignListeners(server, []api.Listener{{Network: "unix", Address: "/run/rekor/rekor.sock"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rest) != 0 || len(server.EnabledListeners) != 1 || server.EnabledListeners[0] != "unix" {
+		t.Errorf("unexpected listeners %v, additional %v", server.EnabledListeners, rest)
+	}
+}
+
+func TestListenUnix(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "rekor.sock")
+	l := api.Listener{Network: "unix", Address: path}
+
+	ln, err := listen(l, 0660)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fi, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fi.Mode().Perm() != 0660 {
+		t.Errorf("socket mode = %v, want 0660", fi.Mode().Perm())
+	}
+
+	// a socket left behind by a previous process is replaced
+	ln2, err := listen(l, 0600)
+	if err != nil {
+		t.Fatalf("listening on stale socket: %v", err)
+	}
+	ln2.Close()
+	ln.Close()
+
+	// other files are not removed
+	file := filepath.Join(dir, "file")
+	if err := ioutil.WriteFile(file, []byte("data"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := listen(api.Listener{Network: "unix", Address: file}, 0660); err == nil {
+		t.Error("expected error listening on a regular file")
+	}
+}
@@ -0,0 +1,66 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package app
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/viper"
+
+	"github.com/sigstore/rekor/pkg/api"
+	"github.com/sigstore/rekor/pkg/log"
+)
+
+// reloadOnSIGHUP re-reads the configuration whenever the process receives SIGHUP and applies the
+// settings which can change while the server is running
+func reloadOnSIGHUP(ctx context.Context) {
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, syscall.SIGHUP)
+	go func() {
+		for range c {
+			if err := reloadConfig(ctx); err != nil {
+				log.Logger.Errorf("configuration not reloaded: %v", err)
+				continue
+			}
+			log.Logger.Info("configuration reloaded")
+		}
+	}()
+}
+
+// reloadConfig reads the config file into a new viper instance, as the global one is read by
+// request handlers concurrently, and applies it
+func reloadConfig(ctx context.Context) error {
+	v := viper.New()
+	if err := v.BindPFlags(rootCmd.PersistentFlags()); err != nil {
+		return err
+	}
+	v.AutomaticEnv()
+	if path := viper.ConfigFileUsed(); path != "" {
+		v.SetConfigFile(path)
+		if err := v.ReadInConfig(); err != nil {
+			return errors.Wrap(err, "reading config file")
+		}
+	}
+	cfg, err := api.LoadConfig(v)
+	if err != nil {
+		return err
+	}
+	return api.Reload(ctx, cfg)
+}
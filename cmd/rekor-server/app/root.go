@@ -21,6 +21,7 @@ import (
 	"net/http/pprof"
 	"os"
 	"runtime/debug"
+	"time"
 
 	homedir "github.com/mitchellh/go-homedir"
 	"github.com/spf13/cobra"
@@ -61,30 +62,105 @@ func Execute() {
 func init() {
 	cobra.OnInitialize(initConfig)
 
-	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.rekor-server.yaml)")
+	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "YAML, TOML or JSON config file (default is $HOME/rekor-server.yaml); trusted roots, policies, API tokens and rate limits are reloaded from it on SIGHUP")
 	rootCmd.PersistentFlags().StringVar(&logType, "log_type", "dev", "logger type to use (dev/prod)")
 	rootCmd.PersistentFlags().BoolVar(&enablePprof, "enable_pprof", false, "enable pprof for profiling on port 6060")
 
 	rootCmd.PersistentFlags().String("trillian_log_server.address", "127.0.0.1", "Trillian log server address")
 	rootCmd.PersistentFlags().Uint16("trillian_log_server.port", 8090, "Trillian log server port")
 	rootCmd.PersistentFlags().Uint("trillian_log_server.tlog_id", 0, "Trillian tree id")
-	rootCmd.PersistentFlags().Var(&logRangeMap, "trillian_log_server.log_id_ranges", "ordered list of tree ids and ranges")
+	rootCmd.PersistentFlags().Var(&logRangeMap, "trillian_log_server.log_id_ranges", "ordered list of the shards of the log, as tree_id=length for each frozen shard followed by the tree id of the active shard, as printed by rekor-server shard freeze")
+	rootCmd.PersistentFlags().Bool("trillian_log_server.tls", false, "use TLS when connecting to the Trillian log server")
+	rootCmd.PersistentFlags().String("trillian_log_server.tls_ca_cert", "", "path to PEM encoded CA certificate(s) used to verify the Trillian log server; system roots are used if unset")
+	rootCmd.PersistentFlags().String("trillian_log_server.tls_client_cert", "", "path to PEM encoded client certificate for mutual TLS with the Trillian log server")
+	rootCmd.PersistentFlags().String("trillian_log_server.tls_client_key", "", "path to PEM encoded client key for mutual TLS with the Trillian log server")
+	rootCmd.PersistentFlags().String("trillian_log_server.tls_server_name", "", "server name to verify in the Trillian log server's certificate, if different from the address")
+	rootCmd.PersistentFlags().Duration("trillian_log_server.rpc_timeout", 20*time.Second, "deadline applied to each request made to the Trillian log server (0 to disable)")
+	rootCmd.PersistentFlags().Uint("trillian_log_server.max_retries", 0, "number of times a failed request to the Trillian log server is retried (at most 4)")
+	rootCmd.PersistentFlags().Duration("trillian_log_server.retry_initial_backoff", 100*time.Millisecond, "initial backoff between retries of requests to the Trillian log server")
+	rootCmd.PersistentFlags().Duration("trillian_log_server.retry_max_backoff", 2*time.Second, "maximum backoff between retries of requests to the Trillian log server")
+	rootCmd.PersistentFlags().Duration("trillian_log_server.keepalive_time", 0, "interval between keepalive pings sent on idle connections to the Trillian log server (0 to disable)")
+	rootCmd.PersistentFlags().Duration("trillian_log_server.keepalive_timeout", 20*time.Second, "time to wait for a keepalive ping acknowledgement before closing the connection")
+	rootCmd.PersistentFlags().String("trillian_log_server.load_balancing_policy", "pick_first", "gRPC load balancing policy used across resolved Trillian log server addresses: [pick_first, round_robin]")
+	rootCmd.PersistentFlags().String("backend.type", "trillian", "where the Merkle trees of the log are kept: [trillian, embedded]; embedded keeps them in files under backend.path, so that a single rekor-server runs without Trillian and MySQL")
+	rootCmd.PersistentFlags().String("backend.path", "", "directory holding the trees of the embedded backend; it must not be shared with another rekor-server")
 
 	rootCmd.PersistentFlags().String("rekor_server.hostname", "rekor.sigstore.dev", "public hostname of instance")
 	rootCmd.PersistentFlags().String("rekor_server.address", "127.0.0.1", "Address to bind to")
 	rootCmd.PersistentFlags().String("rekor_server.signer", "memory", "Rekor signer to use. Current valid options include: [gcpkms, memory]")
 	rootCmd.PersistentFlags().String("rekor_server.timestamp_chain", "", "PEM encoded cert chain signing authorizing the signer to be a CA to sign a timestamping cert")
+	rootCmd.PersistentFlags().String("rekor_server.key_set", "", "path to a YAML file listing the keys the log signed with before rekor_server.signer and when each was in use, published at /api/v1/log/publicKeys")
+	rootCmd.PersistentFlags().StringSlice("rekor_server.listen", []string{}, "addresses to serve on instead of rekor_server.address and port, as host:port, tcp://host:port or unix:///path/to/socket")
+	rootCmd.PersistentFlags().String("rekor_server.socket_mode", "0660", "permissions of unix sockets listed in rekor_server.listen")
 
 	rootCmd.PersistentFlags().Uint16("port", 3000, "Port to bind to")
+	rootCmd.PersistentFlags().Bool("dev", false, "development mode: keep the log and search index in memory instead of using Trillian and Redis; everything is lost when the server exits (set attestation_storage_bucket to mem:// to keep attestations in memory as well)")
 
 	rootCmd.PersistentFlags().Bool("enable_retrieve_api", true, "enables Redis-based index API endpoint")
 	rootCmd.PersistentFlags().String("redis_server.address", "127.0.0.1", "Redis server address")
 	rootCmd.PersistentFlags().Uint16("redis_server.port", 6379, "Redis server port")
+	rootCmd.PersistentFlags().StringSlice("annotations.indexed_keys", []string{}, "keys of the annotations attached to entries which are added to the search index, so that entries can be searched for by them")
+
+	rootCmd.PersistentFlags().String("audit.file", "", "path of a file every attempt to add an entry and every administrative action is recorded in, as hash chained JSON records")
+	rootCmd.PersistentFlags().String("audit.syslog", "", "syslog daemon audit records are sent to instead of a file: local, udp://host:port or tcp://host:port")
+	rootCmd.PersistentFlags().StringSlice("shards.archives", []string{}, "frozen shards of trillian_log_server.log_id_ranges to serve from the archives written by rekor-server shard export, as tree_id=bucket URL; archives are loaded into memory at startup")
+	rootCmd.PersistentFlags().Int("pagination.max_page_size", 1000, "largest number of results served per page of a search; clients follow the token in the X-Rekor-Next-Page-Token header for the rest")
+	rootCmd.PersistentFlags().StringArray("faults.rules", []string{}, "for testing only: faults to inject into calls to the log backend, as method=fault followed by comma separated options, e.g. QueueLeaf=unavailable,probability=0.1; faults are timeout, unavailable, not_found, and stale_root or forked_root for GetLatestSignedLogRoot, and options are probability, after, count and delay. May be repeated")
+	rootCmd.PersistentFlags().Int64("faults.seed", 0, "seed of the choice of calls faults.rules are injected into, so that runs can be repeated")
+
+	rootCmd.PersistentFlags().Bool("enable_timestamp_api", true, "enables the RFC 3161 timestamp authority endpoint")
+	rootCmd.PersistentFlags().String("keyless.fulcio_roots", "", "path to a PEM bundle of Fulcio root and intermediate certificates; certificates they issue are only accepted within their validity window")
+	rootCmd.PersistentFlags().String("x509.roots", "", "path to a PEM bundle of trusted root certificates; if set, entries signed with certificates that do not chain to one of them are rejected")
+	rootCmd.PersistentFlags().String("x509.intermediates", "", "path to a PEM bundle of intermediate certificates used to build chains to the trusted roots")
+	rootCmd.PersistentFlags().StringSlice("x509.ekus", []string{"codesigning"}, "extended key usages of which signing certificates must carry at least one: [any, codesigning, emailprotection, timestamping, serverauth, clientauth]")
+	rootCmd.PersistentFlags().StringSlice("x509.crls", []string{}, "paths to PEM or DER encoded certificate revocation lists for the trusted roots and intermediates")
+	rootCmd.PersistentFlags().String("x509.revocation_policy", "soft", "how CRLs are applied: none, soft (reject revoked certificates where a CRL is available), hard (also require a current CRL for every issuer)")
+	rootCmd.PersistentFlags().Bool("pgp.allow_expired_keys", false, "accept PGP signatures made after the signing key expired")
+	rootCmd.PersistentFlags().Bool("pgp.allow_retired_keys", false, "accept PGP signatures made before the signing key was revoked as superseded or no longer used; keys revoked as compromised are always rejected")
+
+	rootCmd.PersistentFlags().String("auth.tokens_file", "", "path to a file of \"<identity> <token>\" lines; if set, adding entries requires one of these API tokens (or an OIDC identity token)")
+	rootCmd.PersistentFlags().String("auth.oidc.issuer", "", "URL of an OIDC issuer; if set, adding entries requires an ID token from this issuer (or an API token)")
+	rootCmd.PersistentFlags().String("auth.oidc.client_id", "", "audience that OIDC ID tokens must be issued for")
+	rootCmd.PersistentFlags().Float64("auth.rate_limit", 0, "number of entries each authenticated identity may add per minute; 0 for no limit")
+	rootCmd.PersistentFlags().Int("auth.burst", 10, "number of entries an authenticated identity may add at once before the rate limit applies")
+	rootCmd.PersistentFlags().StringToString("auth.identity_rate_limits", map[string]string{}, "per-identity overrides of auth.rate_limit, as identity=entries per minute (0 for no limit)")
+
+	rootCmd.PersistentFlags().StringSlice("policy.allowed_types", []string{}, "entry types accepted by the log; all types are accepted if empty")
+	rootCmd.PersistentFlags().StringSlice("policy.allowed_pki_formats", []string{}, "formats of signing keys accepted by the log; all formats are accepted if empty")
+	rootCmd.PersistentFlags().Int("policy.max_entry_size", 0, "maximum size in bytes of a proposed entry, including inline content; 0 for no limit")
+	rootCmd.PersistentFlags().Int("policy.min_rsa_key_bits", 0, "minimum size in bits of RSA and DSA signing keys; 0 for no minimum")
+	rootCmd.PersistentFlags().Int("policy.min_ec_key_bits", 0, "minimum size in bits of elliptic curve signing keys; 0 for no minimum")
+	rootCmd.PersistentFlags().String("policy.blocked_keys_file", "", "path to a file of SHA256 fingerprints (one per line) of public keys whose entries are rejected")
+	rootCmd.PersistentFlags().String("logs_config", "", "path to a YAML file describing additional logs to host, each served under /logs/<name>/ with its own tree, signer and policy")
+
+	rootCmd.PersistentFlags().StringSlice("cors.allowed_origins", []string{"*"}, "origins from which browser-based clients may query the log; cross-origin requests are not allowed if empty")
+	rootCmd.PersistentFlags().StringSlice("cors.allowed_headers", []string{"Accept", "Authorization", "Content-Type", "If-None-Match", "X-Requested-With"}, "request headers browser-based clients may send in cross-origin requests")
+	rootCmd.PersistentFlags().StringSlice("cors.exposed_headers", []string{"ETag", "Location", "Retry-After", "X-Rekor-Next-Page-Token"}, "response headers browser-based clients may read from cross-origin requests")
+	rootCmd.PersistentFlags().Duration("cors.max_age", 0, "how long browsers may cache the result of a CORS preflight request")
+	rootCmd.PersistentFlags().Bool("cors.allow_credentials", false, "allow cross-origin requests to carry credentials such as cookies; requires explicit cors.allowed_origins")
+
+	rootCmd.PersistentFlags().Bool("compression.enabled", true, "accept gzip and zstd compressed request bodies and compress responses for clients which accept either")
+	rootCmd.PersistentFlags().Int64("compression.max_request_size", 32<<20, "max size of compressed request bodies once decompressed, in bytes")
+
+	rootCmd.PersistentFlags().Bool("enable_web_ui", false, "serve a web interface under /ui/ for looking up entries and verifying their inclusion proofs in the browser")
 
 	rootCmd.PersistentFlags().Bool("enable_attestation_storage", false, "enables rich attestation storage")
-	rootCmd.PersistentFlags().String("attestation_storage_bucket", "", "url for attestation storage bucket")
+	rootCmd.PersistentFlags().String("attestation_storage_bucket", "", "url for attestation storage bucket (file://, gs:// or s3://); attestations are stored by their SHA256 digest and served under /api/v1/log/attachments/")
 	rootCmd.PersistentFlags().Int("max_attestation_size", 100*1024, "max size for attestation storage, in bytes")
 
+	rootCmd.PersistentFlags().Bool("enable_webhooks", false, "enables webhook notifications for new log entries")
+	rootCmd.PersistentFlags().StringSlice("webhook.url", []string{}, "URL(s) POSTed to after an entry is added to the log")
+	rootCmd.PersistentFlags().String("webhook.secret_file", "", "path to a file holding the secret used to sign webhook deliveries with HMAC-SHA256")
+	rootCmd.PersistentFlags().Duration("webhook.timeout", 10*time.Second, "timeout for each webhook delivery attempt")
+	rootCmd.PersistentFlags().Uint("webhook.max_retries", 3, "number of times a failed webhook delivery is retried")
+	rootCmd.PersistentFlags().Duration("webhook.retry_backoff", time.Second, "initial backoff between retries of webhook deliveries")
+	rootCmd.PersistentFlags().Int("webhook.queue_size", 1000, "number of events buffered for delivery before new events are dropped")
+
+	rootCmd.PersistentFlags().Bool("enable_event_stream", false, "enables publishing of new log entries to a message bus as CloudEvents")
+	rootCmd.PersistentFlags().StringSlice("event_stream.url", []string{}, "URL(s) of the topics new entries are published to, e.g. mem://topic")
+	rootCmd.PersistentFlags().String("event_stream.source", "", "CloudEvents source attribute of published events (defaults to https://<rekor_server.hostname>)")
+	rootCmd.PersistentFlags().Duration("event_stream.timeout", 10*time.Second, "timeout for publishing each event")
+
 	if err := viper.BindPFlags(rootCmd.PersistentFlags()); err != nil {
 		log.Logger.Fatal(err)
 	}
@@ -142,7 +218,12 @@ func initConfig() {
 	viper.AutomaticEnv() // read in environment variables that match
 
 	// If a config file is found, read it in.
-	if err := viper.ReadInConfig(); err == nil {
+	err := viper.ReadInConfig()
+	switch {
+	case err == nil:
 		log.Logger.Infof("Using config file: %s", viper.ConfigFileUsed())
+	case cfgFile != "":
+		// a config file which was asked for explicitly must be usable
+		log.Logger.Fatalf("Error reading config file %s: %v", cfgFile, err)
 	}
 }
@@ -16,8 +16,10 @@
 package app
 
 import (
+	"context"
 	"flag"
 	"net/http"
+	"os"
 
 	"github.com/go-openapi/loads"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
@@ -30,6 +32,8 @@ import (
 	"github.com/sigstore/rekor/pkg/log"
 	"github.com/sigstore/rekor/pkg/types/alpine"
 	alpine_v001 "github.com/sigstore/rekor/pkg/types/alpine/v0.0.1"
+	"github.com/sigstore/rekor/pkg/types/checksums"
+	checksums_v001 "github.com/sigstore/rekor/pkg/types/checksums/v0.0.1"
 	hashedrekord "github.com/sigstore/rekor/pkg/types/hashedrekord"
 	hashedrekord_v001 "github.com/sigstore/rekor/pkg/types/hashedrekord/v0.0.1"
 	"github.com/sigstore/rekor/pkg/types/helm"
@@ -38,12 +42,17 @@ import (
 	intoto_v001 "github.com/sigstore/rekor/pkg/types/intoto/v0.0.1"
 	"github.com/sigstore/rekor/pkg/types/jar"
 	jar_v001 "github.com/sigstore/rekor/pkg/types/jar/v0.0.1"
+	"github.com/sigstore/rekor/pkg/types/oci"
+	oci_v001 "github.com/sigstore/rekor/pkg/types/oci/v0.0.1"
 	"github.com/sigstore/rekor/pkg/types/rekord"
 	rekord_v001 "github.com/sigstore/rekor/pkg/types/rekord/v0.0.1"
+	rekord_v002 "github.com/sigstore/rekor/pkg/types/rekord/v0.0.2"
 	"github.com/sigstore/rekor/pkg/types/rfc3161"
 	rfc3161_v001 "github.com/sigstore/rekor/pkg/types/rfc3161/v0.0.1"
 	"github.com/sigstore/rekor/pkg/types/rpm"
 	rpm_v001 "github.com/sigstore/rekor/pkg/types/rpm/v0.0.1"
+	"github.com/sigstore/rekor/pkg/types/sbom"
+	sbom_v001 "github.com/sigstore/rekor/pkg/types/sbom/v0.0.1"
 	"github.com/sigstore/rekor/pkg/types/tuf"
 	tuf_v001 "github.com/sigstore/rekor/pkg/types/tuf/v0.0.1"
 )
@@ -70,7 +79,8 @@ var serveCmd = &cobra.Command{
 		log.Logger.Infof("starting rekor-server @ %v", viStr)
 
 		doc, _ := loads.Embedded(restapi.SwaggerJSON, restapi.FlatSwaggerJSON)
-		server := restapi.NewServer(operations.NewRekorServerAPI(doc))
+		rekorAPI := operations.NewRekorServerAPI(doc)
+		server := restapi.NewServer(rekorAPI)
 		defer func() {
 			if err := server.Shutdown(); err != nil {
 				log.Logger.Error(err)
@@ -81,29 +91,72 @@ var serveCmd = &cobra.Command{
 		//TODO: add command line option to print versions supported in binary
 
 		// these trigger loading of package and therefore init() methods to run
-		pluggableTypeMap := map[string]string{
-			rekord.KIND:       rekord_v001.APIVERSION,
-			rpm.KIND:          rpm_v001.APIVERSION,
-			jar.KIND:          jar_v001.APIVERSION,
-			intoto.KIND:       intoto_v001.APIVERSION,
-			rfc3161.KIND:      rfc3161_v001.APIVERSION,
-			alpine.KIND:       alpine_v001.APIVERSION,
-			helm.KIND:         helm_v001.APIVERSION,
-			tuf.KIND:          tuf_v001.APIVERSION,
-			hashedrekord.KIND: hashedrekord_v001.APIVERSION,
+		pluggableTypeMap := map[string][]string{
+			rekord.KIND:       {rekord_v001.APIVERSION, rekord_v002.APIVERSION},
+			rpm.KIND:          {rpm_v001.APIVERSION},
+			jar.KIND:          {jar_v001.APIVERSION},
+			intoto.KIND:       {intoto_v001.APIVERSION},
+			rfc3161.KIND:      {rfc3161_v001.APIVERSION},
+			alpine.KIND:       {alpine_v001.APIVERSION},
+			helm.KIND:         {helm_v001.APIVERSION},
+			tuf.KIND:          {tuf_v001.APIVERSION},
+			hashedrekord.KIND: {hashedrekord_v001.APIVERSION},
+			sbom.KIND:         {sbom_v001.APIVERSION},
+			checksums.KIND:    {checksums_v001.APIVERSION},
+			oci.KIND:          {oci_v001.APIVERSION},
 		}
 
-		for k, v := range pluggableTypeMap {
+		for k, versions := range pluggableTypeMap {
 			log.Logger.Infof("Loading support for pluggable type '%v'", k)
-			log.Logger.Infof("Loading version '%v' for pluggable type '%v'", v, k)
+			for _, v := range versions {
+				log.Logger.Infof("Loading version '%v' for pluggable type '%v'", v, k)
+			}
 		}
 
-		server.Host = viper.GetString("rekor_server.address")
-		server.Port = int(viper.GetUint("port"))
-		server.EnabledListeners = []string{"http"}
+		cfg, err := api.LoadConfig(viper.GetViper())
+		if err != nil {
+			log.Logger.Fatal(err)
+		}
 
-		api.ConfigureAPI(logRangeMap.Ranges)
+		listeners, err := cfg.Listeners()
+		if err != nil {
+			log.Logger.Fatal(err)
+		}
+		socketMode, err := cfg.UnixSocketMode()
+		if err != nil {
+			log.Logger.Fatal(err)
+		}
+		additional, err := assignListeners(server, listeners)
+		if err != nil {
+			log.Logger.Fatal(err)
+		}
+
+		api.ConfigureAPI(cfg, logRangeMap.Ranges)
 		server.ConfigureAPI()
+		reloadOnSIGHUP(context.Background())
+
+		if server.SocketPath != "" {
+			if err := removeStaleSocket(server.SocketPath); err != nil {
+				log.Logger.Fatal(err)
+			}
+		}
+		if err := server.Listen(); err != nil {
+			log.Logger.Fatal(err)
+		}
+		if server.SocketPath != "" {
+			if err := os.Chmod(server.SocketPath, socketMode); err != nil {
+				log.Logger.Fatal(err)
+			}
+		}
+		additionalServers, err := serveAdditional(server, additional, socketMode)
+		if err != nil {
+			log.Logger.Fatal(err)
+		}
+		rekorAPI.PreServerShutdown = func() {
+			ctx, cancel := context.WithTimeout(context.Background(), server.GracefulTimeout)
+			defer cancel()
+			shutdownServers(ctx, additionalServers)
+		}
 
 		http.Handle("/metrics", promhttp.Handler())
 		go func() {
@@ -0,0 +1,120 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package app
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/sigstore/rekor/pkg/api"
+	"github.com/sigstore/rekor/pkg/log"
+)
+
+// shardCmd groups the commands managing the shards of the log
+var shardCmd = &cobra.Command{
+	Use:   "shard",
+	Short: "Manage the shards of the log",
+	Long: `The log is split into shards, each a Trillian tree, listed by --trillian_log_server.log_id_ranges.
+Only the last shard accepts entries; the server serves entries of every shard by their index in the
+log as a whole, with inclusion proofs against the tree of their shard, and lists the shards at
+` + api.ShardsPath + `.`,
+}
+
+var shardFreezeCmd = &cobra.Command{
+	Use:   "freeze",
+	Short: "Freeze the active shard of the log",
+	Long: `Stops writes to the active shard of the log: its tree is drained until the entries already queued
+are integrated, then frozen, and its final signed tree head is printed and recorded in the audit log.
+A tree is created for the next shard unless --next_tree_id is given. The server must then be
+restarted with the printed --trillian_log_server.log_id_ranges; until then, entries are rejected.`,
+	PreRun: func(cmd *cobra.Command, args []string) {
+		// these are bound here so that they are not overwritten by other commands
+		if err := viper.BindPFlags(cmd.Flags()); err != nil {
+			log.Logger.Fatal("Error initializing cmd line args: ", err)
+		}
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		log.ConfigureLogger(viper.GetString("log_type"))
+		// workaround for https://github.com/sigstore/rekor/issues/68
+		_ = flag.CommandLine.Parse([]string{})
+
+		cfg, err := api.LoadConfig(viper.GetViper())
+		if err != nil {
+			return err
+		}
+		shard, ranges, err := api.FreezeShard(context.Background(), cfg, logRangeMap.Ranges, api.FreezeOptions{
+			Settle:     viper.GetDuration("settle"),
+			NextTreeID: viper.GetInt64("next_tree_id"),
+		})
+		if err != nil {
+			return err
+		}
+		fmt.Printf("froze tree %d with %d entries, from index %d, and root hash %s\n", shard.TreeID, shard.TreeSize, shard.FirstIndex, shard.RootHash)
+		fmt.Printf("final signed tree head:\n%s\n", shard.SignedTreeHead)
+		fmt.Printf("restart the server with --trillian_log_server.log_id_ranges=%s\n", ranges.String())
+		return nil
+	},
+}
+
+var shardExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export a frozen shard to a storage bucket",
+	Long: `Writes the leaves of the tree of a frozen shard to a storage bucket (file://, gs://, s3:// or mem://),
+checking that they hash to the root of the tree. Once the archive is listed in --shards.archives, the
+server serves the shard read-only from it, and the tree can be deleted from Trillian.`,
+	PreRun: func(cmd *cobra.Command, args []string) {
+		// these are bound here so that they are not overwritten by other commands
+		if err := viper.BindPFlags(cmd.Flags()); err != nil {
+			log.Logger.Fatal("Error initializing cmd line args: ", err)
+		}
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		log.ConfigureLogger(viper.GetString("log_type"))
+		// workaround for https://github.com/sigstore/rekor/issues/68
+		_ = flag.CommandLine.Parse([]string{})
+
+		treeID, to := viper.GetInt64("tree_id"), viper.GetString("to")
+		if treeID <= 0 || to == "" {
+			return errors.New("--tree_id and --to must be set")
+		}
+		cfg, err := api.LoadConfig(viper.GetViper())
+		if err != nil {
+			return err
+		}
+		header, err := api.ExportShard(context.Background(), cfg, treeID, to)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("exported %d entries of tree %d with root hash %s\n", header.TreeSize, header.TreeID, header.RootHash)
+		fmt.Printf("serve the shard from the archive with --shards.archives=%d=%s\n", treeID, to)
+		return nil
+	},
+}
+
+func init() {
+	shardFreezeCmd.Flags().Duration("settle", 10*time.Second, "how long the tree must stay the same size while it is drained before it is frozen")
+	shardFreezeCmd.Flags().Int64("next_tree_id", 0, "existing tree to use for the next shard; a tree is created if unset")
+	shardExportCmd.Flags().Int64("tree_id", 0, "tree of the frozen shard to export")
+	shardExportCmd.Flags().String("to", "", "URL of the bucket to write the archive of the shard to")
+	shardCmd.AddCommand(shardFreezeCmd, shardExportCmd)
+	rootCmd.AddCommand(shardCmd)
+}
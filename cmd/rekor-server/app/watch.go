@@ -29,6 +29,7 @@ import (
 	_ "gocloud.dev/blob/fileblob" // fileblob
 	_ "gocloud.dev/blob/gcsblob"
 
+	"github.com/go-openapi/swag"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -38,6 +39,7 @@ import (
 	genclient "github.com/sigstore/rekor/pkg/generated/client"
 	"github.com/sigstore/rekor/pkg/log"
 	"github.com/sigstore/rekor/pkg/util"
+	"github.com/sigstore/rekor/pkg/verify"
 	"github.com/sigstore/sigstore/pkg/signature"
 )
 
@@ -135,22 +137,17 @@ func doCheck(c *genclient.Rekor, pub crypto.PublicKey) (*SignedAndUnsignedLogRoo
 	if err != nil {
 		return nil, errors.Wrap(err, "getting log info")
 	}
-	sth := util.SignedCheckpoint{}
-	if err := sth.UnmarshalText([]byte(*li.Payload.SignedTreeHead)); err != nil {
-		return nil, errors.Wrap(err, "unmarshalling tree head")
-	}
-
 	verifier, err := signature.LoadVerifier(pub, crypto.SHA256)
 	if err != nil {
 		return nil, err
 	}
-
-	if !sth.Verify(verifier) {
-		return nil, errors.Wrap(err, "signed tree head failed verification")
+	sth, err := verify.Checkpoint(verifier, swag.StringValue(li.Payload.SignedTreeHead))
+	if err != nil {
+		return nil, errors.Wrap(err, "verifying signed tree head")
 	}
 
 	return &SignedAndUnsignedLogRoot{
-		VerifiedLogRoot: &sth,
+		VerifiedLogRoot: sth,
 	}, nil
 }
 
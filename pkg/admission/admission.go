@@ -0,0 +1,177 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package admission
+
+import (
+	"crypto"
+	"crypto/dsa" // nolint: staticcheck
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/sigstore/rekor/pkg/pki"
+)
+
+// Names of the individual policies, as reported in violations
+const (
+	AllowedTypes      = "allowed_types"
+	AllowedPKIFormats = "allowed_pki_formats"
+	MaxEntrySize      = "max_entry_size"
+	MinKeySize        = "min_key_size"
+	BlockedKeys       = "blocked_keys"
+)
+
+// Policy is evaluated against every proposed entry before it is added to the log. The zero value
+// admits every entry.
+type Policy struct {
+	// AllowedTypes lists the entry kinds which are accepted; all kinds are accepted if empty
+	AllowedTypes []string
+	// AllowedPKIFormats lists the formats of signing keys which are accepted; all formats are accepted if empty
+	AllowedPKIFormats []pki.Format
+	// MaxEntrySize is the maximum size in bytes of a proposed entry, including any inline content
+	MaxEntrySize int
+	// MinRSAKeyBits is the minimum modulus size of RSA and DSA signing keys
+	MinRSAKeyBits int
+	// MinECKeyBits is the minimum curve size of elliptic curve signing keys
+	MinECKeyBits int
+	// BlockedKeys holds the fingerprints of keys which may not sign entries, see Fingerprint
+	BlockedKeys map[string]struct{}
+}
+
+// Entry holds the properties of a proposed entry that policies are evaluated against
+type Entry struct {
+	Kind    string
+	Size    int
+	Signers []pki.PublicKey
+}
+
+// Violation describes why an entry was rejected
+type Violation struct {
+	Policy  string
+	Message string
+}
+
+func (v Violation) String() string {
+	return fmt.Sprintf("%s: %s", v.Policy, v.Message)
+}
+
+// Fingerprint returns the lowercase hex encoded SHA256 digest of the canonical value of a key, which
+// is also the value under which entries signed by the key are indexed
+func Fingerprint(k pki.PublicKey) (string, error) {
+	canonical, err := k.CanonicalValue()
+	if err != nil {
+		return "", err
+	}
+	h := sha256.Sum256(canonical)
+	return hex.EncodeToString(h[:]), nil
+}
+
+// Evaluate returns the violations of the policy by the entry; the entry is admitted if there are none
+func (p *Policy) Evaluate(e Entry) ([]Violation, error) {
+	var violations []Violation
+
+	if len(p.AllowedTypes) > 0 && !contains(p.AllowedTypes, e.Kind) {
+		violations = append(violations, Violation{
+			Policy:  AllowedTypes,
+			Message: fmt.Sprintf("entries of type %q are not accepted, allowed types are: %s", e.Kind, strings.Join(p.AllowedTypes, ", ")),
+		})
+	}
+	if p.MaxEntrySize > 0 && e.Size > p.MaxEntrySize {
+		violations = append(violations, Violation{
+			Policy:  MaxEntrySize,
+			Message: fmt.Sprintf("entry of %d bytes exceeds the maximum size of %d bytes", e.Size, p.MaxEntrySize),
+		})
+	}
+
+	for _, signer := range e.Signers {
+		if len(p.AllowedPKIFormats) > 0 {
+			format, ok := pki.FormatOf(signer)
+			if !ok {
+				format = "unknown"
+			}
+			if !containsFormat(p.AllowedPKIFormats, format) {
+				violations = append(violations, Violation{
+					Policy:  AllowedPKIFormats,
+					Message: fmt.Sprintf("keys of format %q are not accepted", format),
+				})
+			}
+		}
+		if ckp, ok := signer.(pki.CryptoKeyProvider); ok {
+			for _, key := range ckp.CryptoPublicKeys() {
+				if msg := p.checkKeySize(key); msg != "" {
+					violations = append(violations, Violation{Policy: MinKeySize, Message: msg})
+				}
+			}
+		}
+		if len(p.BlockedKeys) > 0 {
+			fp, err := Fingerprint(signer)
+			if err != nil {
+				return nil, err
+			}
+			if _, blocked := p.BlockedKeys[fp]; blocked {
+				violations = append(violations, Violation{
+					Policy:  BlockedKeys,
+					Message: fmt.Sprintf("key with fingerprint %s has been blocked", fp),
+				})
+			}
+		}
+	}
+	return violations, nil
+}
+
+// checkKeySize returns a description of why the key is too small, or an empty string if it is not
+func (p *Policy) checkKeySize(key crypto.PublicKey) string {
+	var bits, min int
+	var kind string
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		kind, bits, min = "RSA", k.N.BitLen(), p.MinRSAKeyBits
+	case *dsa.PublicKey:
+		kind, bits, min = "DSA", k.P.BitLen(), p.MinRSAKeyBits
+	case *ecdsa.PublicKey:
+		kind, bits, min = "ECDSA", k.Curve.Params().BitSize, p.MinECKeyBits
+	case ed25519.PublicKey:
+		kind, bits, min = "Ed25519", 256, p.MinECKeyBits
+	default:
+		return ""
+	}
+	if bits < min {
+		return fmt.Sprintf("%s key of %d bits is smaller than the minimum of %d bits", kind, bits, min)
+	}
+	return ""
+}
+
+func contains(list []string, s string) bool {
+	for _, l := range list {
+		if l == s {
+			return true
+		}
+	}
+	return false
+}
+
+func containsFormat(list []pki.Format, f pki.Format) bool {
+	for _, l := range list {
+		if l == f {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,150 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package admission
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"reflect"
+	"testing"
+
+	"github.com/sigstore/sigstore/pkg/cryptoutils"
+
+	"github.com/sigstore/rekor/pkg/pki"
+	"github.com/sigstore/rekor/pkg/pki/x509"
+)
+
+func x509Key(t *testing.T, pub interface{}) pki.PublicKey {
+	t.Helper()
+	pemBytes, err := cryptoutils.MarshalPublicKeyToPEM(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	k, err := x509.NewPublicKey(bytes.NewReader(pemBytes))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return k
+}
+
+func policies(violations []Violation) []string {
+	var names []string
+	for _, v := range violations {
+		names = append(names, v.Policy)
+	}
+	return names
+}
+
+func TestEvaluate(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaSigner := x509Key(t, rsaKey.Public())
+	ecSigner := x509Key(t, ecKey.Public())
+	ecFingerprint, err := Fingerprint(ecSigner)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		name   string
+		policy Policy
+		entry  Entry
+		want   []string
+	}{
+		{
+			name:   "empty policy admits everything",
+			policy: Policy{},
+			entry:  Entry{Kind: "rekord", Size: 1 << 20, Signers: []pki.PublicKey{rsaSigner}},
+		},
+		{
+			name:   "allowed type",
+			policy: Policy{AllowedTypes: []string{"hashedrekord", "intoto"}},
+			entry:  Entry{Kind: "intoto"},
+		},
+		{
+			name:   "disallowed type",
+			policy: Policy{AllowedTypes: []string{"hashedrekord"}},
+			entry:  Entry{Kind: "rekord"},
+			want:   []string{AllowedTypes},
+		},
+		{
+			name:   "entry too large",
+			policy: Policy{MaxEntrySize: 1024},
+			entry:  Entry{Kind: "rekord", Size: 1025},
+			want:   []string{MaxEntrySize},
+		},
+		{
+			name:   "allowed pki format",
+			policy: Policy{AllowedPKIFormats: []pki.Format{pki.X509}},
+			entry:  Entry{Kind: "rekord", Signers: []pki.PublicKey{ecSigner}},
+		},
+		{
+			name:   "disallowed pki format",
+			policy: Policy{AllowedPKIFormats: []pki.Format{pki.PGP, pki.SSH}},
+			entry:  Entry{Kind: "rekord", Signers: []pki.PublicKey{ecSigner}},
+			want:   []string{AllowedPKIFormats},
+		},
+		{
+			name:   "rsa key too small",
+			policy: Policy{MinRSAKeyBits: 2048, MinECKeyBits: 256},
+			entry:  Entry{Kind: "rekord", Signers: []pki.PublicKey{rsaSigner, ecSigner}},
+			want:   []string{MinKeySize},
+		},
+		{
+			name:   "ec key too small",
+			policy: Policy{MinRSAKeyBits: 1024, MinECKeyBits: 384},
+			entry:  Entry{Kind: "rekord", Signers: []pki.PublicKey{rsaSigner, ecSigner}},
+			want:   []string{MinKeySize},
+		},
+		{
+			name:   "blocked key",
+			policy: Policy{BlockedKeys: map[string]struct{}{ecFingerprint: {}}},
+			entry:  Entry{Kind: "rekord", Signers: []pki.PublicKey{rsaSigner, ecSigner}},
+			want:   []string{BlockedKeys},
+		},
+		{
+			name: "all violations are reported",
+			policy: Policy{
+				AllowedTypes:      []string{"hashedrekord"},
+				AllowedPKIFormats: []pki.Format{pki.PGP},
+				MaxEntrySize:      10,
+				MinECKeyBits:      384,
+				BlockedKeys:       map[string]struct{}{ecFingerprint: {}},
+			},
+			entry: Entry{Kind: "rekord", Size: 11, Signers: []pki.PublicKey{ecSigner}},
+			want:  []string{AllowedTypes, MaxEntrySize, AllowedPKIFormats, MinKeySize, BlockedKeys},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			violations, err := tt.policy.Evaluate(tt.entry)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := policies(violations); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Evaluate() = %v, want violations of %v", violations, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,148 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"bufio"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/sigstore/rekor/pkg/admission"
+	"github.com/sigstore/rekor/pkg/generated/models"
+	"github.com/sigstore/rekor/pkg/pki"
+	"github.com/sigstore/rekor/pkg/types"
+)
+
+// admissionPolicyConfig describes an admission policy, either from the policy.* settings or from the
+// configuration of an additional log
+type admissionPolicyConfig struct {
+	AllowedTypes      []string `json:"allowed_types" mapstructure:"allowed_types"`
+	AllowedPKIFormats []string `json:"allowed_pki_formats" mapstructure:"allowed_pki_formats"`
+	MaxEntrySize      int      `json:"max_entry_size" mapstructure:"max_entry_size"`
+	MinRSAKeyBits     int      `json:"min_rsa_key_bits" mapstructure:"min_rsa_key_bits"`
+	MinECKeyBits      int      `json:"min_ec_key_bits" mapstructure:"min_ec_key_bits"`
+	BlockedKeysFile   string   `json:"blocked_keys_file" mapstructure:"blocked_keys_file"`
+}
+
+func (c admissionPolicyConfig) validate() error {
+	for _, kind := range c.AllowedTypes {
+		if _, ok := types.TypeMap.Load(kind); !ok {
+			return errors.Errorf("unknown entry type %q in allowed_types", kind)
+		}
+	}
+	for _, f := range c.AllowedPKIFormats {
+		if !containsString(pki.SupportedFormats(), f) {
+			return errors.Errorf("unknown PKI format %q in allowed_pki_formats", f)
+		}
+	}
+	if c.MaxEntrySize < 0 || c.MinRSAKeyBits < 0 || c.MinECKeyBits < 0 {
+		return errors.New("max_entry_size, min_rsa_key_bits and min_ec_key_bits must not be negative")
+	}
+	return nil
+}
+
+// policy validates the configuration and builds the admission policy; nil is returned if no policy is configured
+func (c admissionPolicyConfig) policy() (*admission.Policy, error) {
+	if err := c.validate(); err != nil {
+		return nil, err
+	}
+	policy := &admission.Policy{
+		AllowedTypes:  c.AllowedTypes,
+		MaxEntrySize:  c.MaxEntrySize,
+		MinRSAKeyBits: c.MinRSAKeyBits,
+		MinECKeyBits:  c.MinECKeyBits,
+	}
+	for _, f := range c.AllowedPKIFormats {
+		policy.AllowedPKIFormats = append(policy.AllowedPKIFormats, pki.Format(f))
+	}
+	if c.BlockedKeysFile != "" {
+		blocked, err := readBlockedKeys(c.BlockedKeysFile)
+		if err != nil {
+			return nil, errors.Wrap(err, "reading blocked keys")
+		}
+		policy.BlockedKeys = blocked
+	}
+
+	if len(policy.AllowedTypes) == 0 && len(policy.AllowedPKIFormats) == 0 && len(policy.BlockedKeys) == 0 &&
+		policy.MaxEntrySize <= 0 && policy.MinRSAKeyBits <= 0 && policy.MinECKeyBits <= 0 {
+		return nil, nil
+	}
+	return policy, nil
+}
+
+// readBlockedKeys reads SHA256 key fingerprints, one per line; empty lines and lines starting with # are ignored
+func readBlockedKeys(path string) (map[string]struct{}, error) {
+	f, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	blocked := map[string]struct{}{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fp := strings.ToLower(strings.TrimPrefix(line, "sha256:"))
+		if b, err := hex.DecodeString(fp); err != nil || len(b) != 32 {
+			return nil, errors.Errorf("invalid key fingerprint %q, expected a hex encoded SHA256 digest", line)
+		}
+		blocked[fp] = struct{}{}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return blocked, nil
+}
+
+// checkAdmission evaluates an admission policy against a proposed entry and the canonicalized entry created from it
+func checkAdmission(policy *admission.Policy, pe models.ProposedEntry, entry types.EntryImpl) ([]admission.Violation, error) {
+	if policy == nil {
+		return nil, nil
+	}
+	// the entry is only sized, so it is not kept in memory; Encode terminates it with a newline
+	size := &countingWriter{}
+	if err := json.NewEncoder(size).Encode(pe); err != nil {
+		return nil, err
+	}
+	e := admission.Entry{
+		Kind: pe.Kind(),
+		Size: size.n - 1,
+	}
+	var err error
+	if sp, ok := entry.(types.SignerProvider); ok {
+		if e.Signers, err = sp.Signers(); err != nil {
+			return nil, err
+		}
+	}
+	return policy.Evaluate(e)
+}
+
+func containsString(list []string, s string) bool {
+	for _, l := range list {
+		if l == s {
+			return true
+		}
+	}
+	return false
+}
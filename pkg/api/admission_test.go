@@ -0,0 +1,79 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadBlockedKeys(t *testing.T) {
+	dir := t.TempDir()
+	fp := "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"
+
+	valid := filepath.Join(dir, "blocked")
+	if err := ioutil.WriteFile(valid, []byte("# compromised release key\n\n"+fp+"\nsha256:E3B0C44298FC1C149AFBF4C8996FB92427AE41E4649B934CA495991B7852B855\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	blocked, err := readBlockedKeys(valid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(blocked) != 2 {
+		t.Errorf("expected 2 blocked keys, got %v", blocked)
+	}
+	for _, k := range []string{fp, "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"} {
+		if _, ok := blocked[k]; !ok {
+			t.Errorf("expected %s to be blocked", k)
+		}
+	}
+
+	invalid := filepath.Join(dir, "invalid")
+	if err := ioutil.WriteFile(invalid, []byte("not-a-fingerprint\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readBlockedKeys(invalid); err == nil {
+		t.Error("expected error for invalid fingerprint")
+	}
+}
+
+func TestAdmissionPolicyConfig(t *testing.T) {
+	policy, err := admissionPolicyConfig{}.policy()
+	if err != nil || policy != nil {
+		t.Fatalf("expected no policy without configuration, got %v, %v", policy, err)
+	}
+
+	cfg := admissionPolicyConfig{
+		MinRSAKeyBits:     2048,
+		AllowedPKIFormats: []string{"x509", "pgp"},
+	}
+	policy, err = cfg.policy()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if policy == nil || policy.MinRSAKeyBits != 2048 || len(policy.AllowedPKIFormats) != 2 {
+		t.Errorf("unexpected policy %+v", policy)
+	}
+
+	cfg.AllowedPKIFormats = []string{"bogus"}
+	if _, err := cfg.policy(); err == nil {
+		t.Error("expected error for unknown PKI format")
+	}
+	if _, err := (admissionPolicyConfig{MaxEntrySize: -1}).policy(); err == nil {
+		t.Error("expected error for negative entry size")
+	}
+}
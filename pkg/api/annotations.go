@@ -0,0 +1,59 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"fmt"
+
+	"github.com/sigstore/rekor/pkg/generated/models"
+	"github.com/sigstore/rekor/pkg/types"
+)
+
+// annotationsConfig holds the annotations.* settings
+type annotationsConfig struct {
+	// keys of the annotations entries are indexed by, so that they can be searched for
+	IndexedKeys []string `mapstructure:"indexed_keys"`
+}
+
+func (c annotationsConfig) validate() error {
+	for _, k := range c.IndexedKeys {
+		if err := types.ValidateAnnotations(models.Annotations{k: ""}); err != nil {
+			return fmt.Errorf("indexed_keys: %w", err)
+		}
+	}
+	return nil
+}
+
+// indexedKeys returns the set of annotation keys which are indexed
+func (c annotationsConfig) indexedKeys() map[string]bool {
+	keys := map[string]bool{}
+	for _, k := range c.IndexedKeys {
+		keys[k] = true
+	}
+	return keys
+}
+
+// annotationIndexKeys returns the index keys of the annotations of an entry which the log indexes,
+// which include those recording the source of the artifact
+func (a *API) annotationIndexKeys(annotations models.Annotations) []string {
+	keys := types.SourceIndexKeys(annotations)
+	for _, k := range types.AnnotationKeys(annotations) {
+		if a.indexedAnnotations[k] {
+			keys = append(keys, types.AnnotationIndexKey(k, annotations[k]))
+		}
+	}
+	return keys
+}
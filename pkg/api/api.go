@@ -17,20 +17,21 @@ package api
 
 import (
 	"context"
+	"crypto"
 	"crypto/sha256"
 	"crypto/x509"
 	"encoding/base64"
 	"encoding/hex"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/trillian"
 	radix "github.com/mediocregopher/radix/v4"
 	"github.com/pkg/errors"
-	"github.com/spf13/viper"
-	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
 
+	"github.com/sigstore/rekor/pkg/admission"
+	"github.com/sigstore/rekor/pkg/events"
 	"github.com/sigstore/rekor/pkg/log"
 	pki "github.com/sigstore/rekor/pkg/pki/x509"
 	"github.com/sigstore/rekor/pkg/signer"
@@ -40,22 +41,10 @@ import (
 	"github.com/sigstore/sigstore/pkg/signature/options"
 )
 
-func dial(ctx context.Context, rpcServer string) (*grpc.ClientConn, error) {
-	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
-	defer cancel()
-
-	// Set up and test connection to rpc server
-	creds := insecure.NewCredentials()
-	conn, err := grpc.DialContext(ctx, rpcServer, grpc.WithTransportCredentials(creds))
-	if err != nil {
-		log.Logger.Fatalf("Failed to connect to RPC server:", err)
-	}
-	return conn, nil
-}
-
 type API struct {
 	logClient    trillian.TrillianLogClient
 	logID        int64
+	rpcTimeout   time.Duration // deadline applied to each request made to trillian
 	logRanges    *LogRanges
 	pubkey       string // PEM encoded public key
 	pubkeyHash   string // SHA256 hash of DER-encoded public key
@@ -63,44 +52,99 @@ type API struct {
 	tsaSigner    signature.Signer    // the signer to use for timestamping
 	certChain    []*x509.Certificate // timestamping cert chain
 	certChainPem string              // PEM encoded timestamping cert chain
+	// identify the checkpoints of the log: their origin line and the key name of their signatures
+	checkpointOrigin string
+	signerName       string
+	// prepended to index keys so that logs hosted by the same instance do not share search results
+	indexPrefix     string
+	admissionPolicy *admission.Policy // evaluated against proposed entries; nil if no policy is configured
+	// keys of the annotations entries are indexed by
+	indexedAnnotations map[string]bool
+	// when signer was first used, if known, and the keys the log signed with before it
+	signerValidFrom time.Time
+	previousKeys    []previousKey
+	// largest number of results served per page of a search
+	maxPageSize int
 }
 
-func NewAPI(ranges LogRanges) (*API, error) {
-	logRPCServer := fmt.Sprintf("%s:%d",
-		viper.GetString("trillian_log_server.address"),
-		viper.GetUint("trillian_log_server.port"))
+func NewAPI(cfg *Config, ranges LogRanges) (*API, error) {
 	ctx := context.Background()
-	tConn, err := dial(ctx, logRPCServer)
-	if err != nil {
-		return nil, errors.Wrap(err, "dial")
+	var logClient trillian.TrillianLogClient
+	tLogID := cfg.Trillian.TreeID
+	if len(ranges.Ranges) > 0 {
+		// the last of the ranges is the active shard
+		if active := int64(ranges.ActiveIndex()); tLogID == 0 {
+			tLogID = active
+		} else if tLogID != active {
+			return nil, errors.Errorf("trillian_log_server.tlog_id %d is not the active shard %d of trillian_log_server.log_id_ranges", tLogID, active)
+		}
 	}
-	logAdminClient := trillian.NewTrillianAdminClient(tConn)
-	logClient := trillian.NewTrillianLogClient(tConn)
-
-	tLogID := viper.GetInt64("trillian_log_server.tlog_id")
-	if tLogID == 0 {
-		t, err := createAndInitTree(ctx, logAdminClient, logClient)
+	switch {
+	case cfg.Dev:
+		log.Logger.Warn("running in development mode: entries are kept in memory and lost when the server exits")
+		logClient = logClientFor(newTrillianBackend(newMemoryLogClient()))
+		if tLogID == 0 {
+			tLogID = memoryTreeID
+		}
+	case cfg.Backend.embedded():
+		backend, err := openEmbeddedBackend(cfg.Backend.Path)
+		if err != nil {
+			return nil, errors.Wrap(err, "backend.path")
+		}
+		logClient = logClientFor(backend)
+		if tLogID == 0 {
+			tLogID = embeddedTreeID
+		}
+	default:
+		tConn, err := dial(ctx, cfg.Trillian)
 		if err != nil {
-			return nil, errors.Wrap(err, "create and init tree")
+			return nil, errors.Wrap(err, "dial")
+		}
+		tClient := trillian.NewTrillianLogClient(tConn)
+		logClient = logClientFor(newTrillianBackend(tClient))
+		if tLogID == 0 {
+			t, err := createAndInitTree(ctx, trillian.NewTrillianAdminClient(tConn), tClient)
+			if err != nil {
+				return nil, errors.Wrap(err, "create and init tree")
+			}
+			tLogID = t.TreeId
 		}
-		tLogID = t.TreeId
+	}
+	if len(ranges.Ranges) == 0 {
+		ranges = LogRanges{Ranges: []LogRange{{TreeID: uint64(tLogID)}}}
+	}
+	if len(cfg.Shards.Archives) > 0 {
+		archived, err := newShardLogClient(ctx, logClient, cfg.Shards, ranges)
+		if err != nil {
+			return nil, errors.Wrap(err, "shards.archives")
+		}
+		logClient = archived
+	}
+	if len(cfg.Faults.Rules) > 0 {
+		rules, err := cfg.Faults.rules()
+		if err != nil {
+			return nil, errors.Wrap(err, "faults.rules")
+		}
+		log.Logger.Warnf("injecting faults into calls to the log backend: %s", strings.Join(cfg.Faults.Rules, "; "))
+		logClient = newFaultLogClient(logClient, rules, cfg.Faults.Seed)
 	}
 
-	rekorSigner, err := signer.New(ctx, viper.GetString("rekor_server.signer"))
+	rekorSigner, err := signer.New(ctx, cfg.Server.Signer)
 	if err != nil {
 		return nil, errors.Wrap(err, "getting new signer")
 	}
-	pk, err := rekorSigner.PublicKey(options.WithContext(ctx))
-	if err != nil {
-		return nil, errors.Wrap(err, "getting public key")
-	}
-	b, err := x509.MarshalPKIXPublicKey(pk)
+	pubkey, pubkeyHash, err := signerPublicKey(ctx, rekorSigner)
 	if err != nil {
-		return nil, errors.Wrap(err, "marshalling public key")
+		return nil, err
 	}
-	pubkeyHashBytes := sha256.Sum256(b)
 
-	pubkey := cryptoutils.PEMEncode(cryptoutils.PublicKeyPEMType, b)
+	var previousKeys []previousKey
+	var signerValidFrom time.Time
+	if cfg.Server.KeySet != "" {
+		if previousKeys, signerValidFrom, err = loadKeySet(ctx, cfg.Server.KeySet, pubkeyHash); err != nil {
+			return nil, errors.Wrap(err, "rekor_server.key_set")
+		}
+	}
 
 	// Use an in-memory key for timestamping
 	tsaSigner, err := signer.New(ctx, signer.MemoryScheme)
@@ -113,7 +157,7 @@ func NewAPI(ranges LogRanges) (*API, error) {
 	}
 
 	var certChain []*x509.Certificate
-	b64CertChainStr := viper.GetString("rekor_server.timestamp_chain")
+	b64CertChainStr := cfg.Server.TimestampChain
 	if b64CertChainStr != "" {
 		certChainStr, err := base64.StdEncoding.DecodeString(b64CertChainStr)
 		if err != nil {
@@ -134,47 +178,112 @@ func NewAPI(ranges LogRanges) (*API, error) {
 		return nil, errors.Wrap(err, "timestamping cert chain")
 	}
 
-	return &API{
+	a := &API{
 		// Transparency Log Stuff
-		logClient: logClient,
-		logID:     tLogID,
-		logRanges: &ranges,
+		logClient:  logClient,
+		logID:      tLogID,
+		rpcTimeout: cfg.Trillian.RPCTimeout,
+		logRanges:  &ranges,
 		// Signing/verifying fields
-		pubkey:     string(pubkey),
-		pubkeyHash: hex.EncodeToString(pubkeyHashBytes[:]),
+		pubkey:     pubkey,
+		pubkeyHash: pubkeyHash,
 		signer:     rekorSigner,
+		// Checkpoints
+		checkpointOrigin: "Rekor",
+		signerName:       cfg.Server.Hostname,
+		// Key rotation
+		signerValidFrom: signerValidFrom,
+		previousKeys:    previousKeys,
 		// TSA signing stuff
 		tsaSigner:    tsaSigner,
 		certChain:    certChain,
 		certChainPem: string(certChainPem),
-	}, nil
+		// Search index
+		indexedAnnotations: cfg.Annotations.indexedKeys(),
+		maxPageSize:        cfg.Pagination.maxPageSize(),
+	}
+	if err := a.checkShards(ctx); err != nil {
+		return nil, errors.Wrap(err, "trillian_log_server.log_id_ranges")
+	}
+	return a, nil
+}
+
+// signerPublicKey returns the PEM encoded public key of a signer and the hex encoded SHA256 hash of its DER encoding
+func signerPublicKey(ctx context.Context, s signature.Signer) (string, string, error) {
+	pk, err := s.PublicKey(options.WithContext(ctx))
+	if err != nil {
+		return "", "", errors.Wrap(err, "getting public key")
+	}
+	return encodePublicKey(pk)
+}
+
+// encodePublicKey returns the PEM encoding of a public key and the hex encoded SHA256 hash of its DER encoding
+func encodePublicKey(pk crypto.PublicKey) (string, string, error) {
+	b, err := x509.MarshalPKIXPublicKey(pk)
+	if err != nil {
+		return "", "", errors.Wrap(err, "marshalling public key")
+	}
+	pubkeyHash := sha256.Sum256(b)
+	return string(cryptoutils.PEMEncode(cryptoutils.PublicKeyPEMType, b)), hex.EncodeToString(pubkeyHash[:]), nil
 }
 
 var (
 	api           *API
-	redisClient   radix.Client
+	indexClient   indexStorage
 	storageClient storage.AttestationStorage
+	eventSinks    []events.Sink
+	// deadline for publishing an event to each of eventSinks
+	eventTimeout time.Duration
 )
 
-func ConfigureAPI(ranges LogRanges) {
-	cfg := radix.PoolConfig{}
+func ConfigureAPI(cfg *Config, ranges LogRanges) {
+	poolCfg := radix.PoolConfig{}
 	var err error
 
-	api, err = NewAPI(ranges)
+	startupConfig = cfg
+	// opened first so that the creation of the tree is recorded
+	auditLog, err = newAuditLogger(cfg.Audit)
 	if err != nil {
 		log.Logger.Panic(err)
 	}
-	if viper.GetBool("enable_retrieve_api") {
-		redisClient, err = cfg.New(context.Background(), "tcp", fmt.Sprintf("%v:%v", viper.GetString("redis_server.address"), viper.GetUint64("redis_server.port")))
+	api, err = NewAPI(cfg, ranges)
+	if err != nil {
+		log.Logger.Panic(err)
+	}
+	api.auditKeyRotation()
+	if cfg.EnableRetrieveAPI {
+		if cfg.Dev {
+			indexClient = newMemoryIndex()
+		} else {
+			redisClient, err := poolCfg.New(context.Background(), "tcp", fmt.Sprintf("%v:%v", cfg.Redis.Address, cfg.Redis.Port))
+			if err != nil {
+				log.Logger.Panic("failure connecting to redis instance: ", err)
+			}
+			indexClient = &redisIndex{client: redisClient}
+		}
+	}
+
+	if cfg.Attestation.Enabled {
+		storageClient, err = storage.NewAttestationStorage(cfg.Attestation.Bucket)
 		if err != nil {
-			log.Logger.Panic("failure connecting to redis instance: ", err)
+			log.Logger.Panic(err)
 		}
 	}
 
-	if viper.GetBool("enable_attestation_storage") {
-		storageClient, err = storage.NewAttestationStorage()
+	eventSinks, err = newEventSinks(context.Background(), cfg)
+	eventTimeout = cfg.EventStream.Timeout
+	if err != nil {
+		log.Logger.Panic(err)
+	}
+
+	if cfg.LogsConfig != "" {
+		logs, err = newLogs(context.Background(), api, cfg.LogsConfig)
 		if err != nil {
 			log.Logger.Panic(err)
 		}
 	}
+	// the remaining settings can be changed while the server is running
+	if err := Reload(context.Background(), cfg); err != nil {
+		log.Logger.Panic(err)
+	}
 }
@@ -0,0 +1,96 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/sigstore/rekor/pkg/log"
+	"github.com/sigstore/rekor/pkg/storage"
+)
+
+// AttachmentsPath is the prefix under which stored attachments are served by digest
+const AttachmentsPath = "/api/v1/log/attachments/"
+
+const attachmentNotFound = "No attachment is stored with the requested digest"
+
+// attestationConfig holds the settings of attestation storage
+type attestationConfig struct {
+	Enabled bool   `mapstructure:"enable_attestation_storage"`
+	Bucket  string `mapstructure:"attestation_storage_bucket"`
+}
+
+func (c attestationConfig) validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	if c.Bucket == "" {
+		return errors.New("attestation_storage_bucket must be set when enable_attestation_storage is set")
+	}
+	if u, err := url.Parse(c.Bucket); err != nil || u.Scheme == "" {
+		return fmt.Errorf("invalid attestation_storage_bucket %q, expected a file://, gs://, s3:// or mem:// URL", c.Bucket)
+	}
+	return nil
+}
+
+// ServeAttachments serves the payloads kept in attestation storage (e.g. in-toto statements or SBOMs)
+// by their digest, as recorded in the body of the corresponding log entries; other requests are
+// passed on to handler
+func ServeAttachments(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.URL.Path, AttachmentsPath) {
+			handler.ServeHTTP(w, r)
+			return
+		}
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			writeErrorResponse(w, http.StatusMethodNotAllowed, "")
+			return
+		}
+		if storageClient == nil {
+			writeErrorResponse(w, http.StatusNotImplemented, "Attestation storage is not enabled on this server")
+			return
+		}
+
+		digest := strings.TrimPrefix(r.URL.Path, AttachmentsPath)
+		data, err := storageClient.FetchAttachment(r.Context(), digest)
+		if err != nil {
+			if errors.Is(err, storage.ErrInvalidDigest) {
+				writeErrorResponse(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			log.RequestIDLogger(r).Errorf("error fetching attachment %s: %v", digest, err)
+			writeErrorResponse(w, http.StatusInternalServerError, "")
+			return
+		}
+		if data == nil {
+			writeErrorResponse(w, http.StatusNotFound, attachmentNotFound)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/octet-stream")
+		// attachments are addressed by their content and therefore never change
+		w.Header().Set("Cache-Control", "s-maxage=31536000, max-age=31536000, immutable")
+		w.WriteHeader(http.StatusOK)
+		if r.Method == http.MethodGet {
+			_, _ = w.Write(data)
+		}
+	})
+}
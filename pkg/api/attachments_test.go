@@ -0,0 +1,148 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sigstore/rekor/pkg/storage"
+)
+
+type fakeAttachmentStorage struct {
+	storage.AttestationStorage
+	attachments map[string][]byte
+	err         error
+}
+
+func (f *fakeAttachmentStorage) FetchAttachment(_ context.Context, digest string) ([]byte, error) {
+	if digest == "sha256:invalid" {
+		return nil, storage.ErrInvalidDigest
+	}
+	if f.err != nil {
+		return nil, f.err
+	}
+	return f.attachments[digest], nil
+}
+
+func TestServeAttachments(t *testing.T) {
+	payload := []byte("sbom document")
+	digest := storage.Digest(payload)
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+	handler := ServeAttachments(next)
+
+	oldClient := storageClient
+	t.Cleanup(func() {
+		storageClient = oldClient
+	})
+
+	for _, tt := range []struct {
+		name     string
+		enabled  bool
+		storage  *fakeAttachmentStorage
+		method   string
+		path     string
+		wantCode int
+		wantBody string
+	}{
+		{
+			name:     "other paths are passed through",
+			method:   http.MethodGet,
+			path:     "/api/v1/log",
+			wantCode: http.StatusTeapot,
+		},
+		{
+			name:     "storage disabled",
+			method:   http.MethodGet,
+			path:     AttachmentsPath + digest,
+			wantCode: http.StatusNotImplemented,
+		},
+		{
+			name:     "found",
+			enabled:  true,
+			storage:  &fakeAttachmentStorage{attachments: map[string][]byte{digest: payload}},
+			method:   http.MethodGet,
+			path:     AttachmentsPath + digest,
+			wantCode: http.StatusOK,
+			wantBody: string(payload),
+		},
+		{
+			name:     "head",
+			enabled:  true,
+			storage:  &fakeAttachmentStorage{attachments: map[string][]byte{digest: payload}},
+			method:   http.MethodHead,
+			path:     AttachmentsPath + digest,
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "not found",
+			enabled:  true,
+			storage:  &fakeAttachmentStorage{},
+			method:   http.MethodGet,
+			path:     AttachmentsPath + digest,
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:     "invalid digest",
+			enabled:  true,
+			storage:  &fakeAttachmentStorage{},
+			method:   http.MethodGet,
+			path:     AttachmentsPath + "sha256:invalid",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "storage error",
+			enabled:  true,
+			storage:  &fakeAttachmentStorage{err: errors.New("bucket unavailable")},
+			method:   http.MethodGet,
+			path:     AttachmentsPath + digest,
+			wantCode: http.StatusInternalServerError,
+		},
+		{
+			name:     "method not allowed",
+			enabled:  true,
+			storage:  &fakeAttachmentStorage{},
+			method:   http.MethodPost,
+			path:     AttachmentsPath + digest,
+			wantCode: http.StatusMethodNotAllowed,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			storageClient = nil
+			if tt.storage != nil {
+				storageClient = tt.storage
+			}
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.path, nil))
+			if rec.Code != tt.wantCode {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantCode)
+			}
+			if tt.wantBody != "" && rec.Body.String() != tt.wantBody {
+				t.Errorf("body = %q, want %q", rec.Body.String(), tt.wantBody)
+			}
+			if tt.wantCode == http.StatusOK && rec.Header().Get("Content-Type") != "application/octet-stream" {
+				t.Errorf("unexpected Content-Type %q", rec.Header().Get("Content-Type"))
+			}
+		})
+	}
+}
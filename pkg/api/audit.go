@@ -0,0 +1,170 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"path"
+
+	"github.com/pkg/errors"
+
+	"github.com/sigstore/rekor/pkg/audit"
+	"github.com/sigstore/rekor/pkg/log"
+)
+
+// auditLog records writes to the log and administrative actions; nil if auditing is disabled
+var auditLog *audit.Logger
+
+// auditConfig holds the audit.* settings
+type auditConfig struct {
+	// path of the file audit records are appended to
+	File string `mapstructure:"file"`
+	// syslog daemon audit records are sent to: local, udp://host:port or tcp://host:port
+	Syslog string `mapstructure:"syslog"`
+}
+
+func (c auditConfig) validate() error {
+	if c.File != "" && c.Syslog != "" {
+		return errors.New("file and syslog can not both be set")
+	}
+	_, _, err := c.syslogAddress()
+	return err
+}
+
+// syslogAddress returns the network and address of the syslog daemon; both are empty for the local daemon
+func (c auditConfig) syslogAddress() (string, string, error) {
+	if c.Syslog == "" || c.Syslog == "local" {
+		return "", "", nil
+	}
+	u, err := url.Parse(c.Syslog)
+	if err != nil || (u.Scheme != "udp" && u.Scheme != "tcp") || u.Host == "" {
+		return "", "", errors.Errorf("invalid syslog address %q, expected local, udp://host:port or tcp://host:port", c.Syslog)
+	}
+	return u.Scheme, u.Host, nil
+}
+
+// newAuditLogger opens the audit log described by c; nil is returned if auditing is disabled
+func newAuditLogger(c auditConfig) (*audit.Logger, error) {
+	switch {
+	case c.File != "":
+		return audit.OpenFile(c.File)
+	case c.Syslog != "":
+		network, addr, err := c.syslogAddress()
+		if err != nil {
+			return nil, err
+		}
+		return audit.DialSyslog(network, addr)
+	}
+	return nil, nil
+}
+
+// writeAudit records an action in the audit log; failures are logged as the action has already been taken
+func writeAudit(r audit.Record) {
+	if err := auditLog.Log(r); err != nil {
+		log.Logger.Errorf("error writing audit record of %s: %v", r.Action, err)
+	}
+}
+
+type auditContextKey struct{}
+
+// auditedRequest collects what is learnt about an audited request while it is handled
+type auditedRequest struct {
+	identity string
+}
+
+// setAuditIdentity records the identity a request was authenticated as if the request is audited
+func setAuditIdentity(r *http.Request, identity string) {
+	if ar, ok := r.Context().Value(auditContextKey{}).(*auditedRequest); ok {
+		ar.identity = identity
+	}
+}
+
+// AuditWrites records every attempt to add an entry to the log in the audit log, along with the
+// identity and address of the client, the UUID of the entry and the outcome
+func AuditWrites(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if auditLog == nil {
+			handler.ServeHTTP(w, r)
+			return
+		}
+		ar := &auditedRequest{}
+		r = r.WithContext(context.WithValue(r.Context(), auditContextKey{}, ar))
+		sw := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		handler.ServeHTTP(sw, r)
+
+		rec := audit.Record{
+			Action:       audit.ActionAddEntry,
+			Outcome:      auditOutcome(sw.status),
+			Identity:     ar.identity,
+			SourceIP:     remoteIP(r),
+			ForwardedFor: r.Header.Get("X-Forwarded-For"),
+		}
+		if a := logAPI(r.Context()); a != nil {
+			rec.TreeID = a.logID
+		}
+		// created and already existing entries are both located by their UUID
+		if sw.status == http.StatusCreated || sw.status == http.StatusConflict {
+			if u, err := url.Parse(w.Header().Get("Location")); err == nil && u.Path != "" {
+				rec.EntryUUID = path.Base(u.Path)
+			}
+		}
+		if sw.status >= http.StatusBadRequest {
+			rec.Detail = fmt.Sprintf("%d %s", sw.status, http.StatusText(sw.status))
+		}
+		writeAudit(rec)
+	})
+}
+
+// auditOutcome classifies the response to a write by its status code
+func auditOutcome(status int) string {
+	switch {
+	case status == http.StatusConflict:
+		return audit.OutcomeDuplicate
+	case status >= http.StatusInternalServerError:
+		return audit.OutcomeError
+	case status >= http.StatusBadRequest:
+		return audit.OutcomeRejected
+	default:
+		return audit.OutcomeSuccess
+	}
+}
+
+// remoteIP returns the address of the client, or of the proxy it connected through
+func remoteIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// statusRecorder remembers the status code of a response
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (sr *statusRecorder) WriteHeader(code int) {
+	if !sr.wroteHeader && code >= http.StatusOK {
+		sr.status, sr.wroteHeader = code, true
+	}
+	sr.ResponseWriter.WriteHeader(code)
+}
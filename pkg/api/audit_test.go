@@ -0,0 +1,92 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/sigstore/rekor/pkg/audit"
+)
+
+func TestAuditWrites(t *testing.T) {
+	oldAuditLog, oldAPI := auditLog, api
+	t.Cleanup(func() {
+		auditLog, api = oldAuditLog, oldAPI
+	})
+	buf := &bytes.Buffer{}
+	auditLog = audit.New(buf)
+	api = &API{logID: 42}
+
+	const uuid = "c7ead87fa5c82d2b17feece1c2ee1bda8e94788f4b208de5057b3617a42b7413"
+	handler := AuditWrites(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		setAuditIdentity(r, "ci@example.com")
+		switch r.URL.Query().Get("status") {
+		case "created":
+			w.Header().Set("Location", "http://rekor.example.com/api/v1/log/entries/"+uuid)
+			w.WriteHeader(http.StatusCreated)
+		case "conflict":
+			w.Header().Set("Location", "http://rekor.example.com/api/v1/log/entries/"+uuid)
+			writeErrorResponse(w, http.StatusConflict, "exists")
+		case "invalid":
+			writeErrorResponse(w, http.StatusBadRequest, "invalid")
+		default:
+			writeErrorResponse(w, http.StatusInternalServerError, trillianUnexpectedResult)
+		}
+	}))
+
+	tests := []struct {
+		status string
+		want   audit.Record
+	}{
+		{"created", audit.Record{Outcome: audit.OutcomeSuccess, EntryUUID: uuid}},
+		{"conflict", audit.Record{Outcome: audit.OutcomeDuplicate, EntryUUID: uuid, Detail: "409 Conflict"}},
+		{"invalid", audit.Record{Outcome: audit.OutcomeRejected, Detail: "400 Bad Request"}},
+		{"error", audit.Record{Outcome: audit.OutcomeError, Detail: "500 Internal Server Error"}},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/log/entries?status="+tt.status, nil)
+		req.RemoteAddr = "192.0.2.1:4242"
+		req.Header.Set("X-Forwarded-For", "198.51.100.7")
+		handler.ServeHTTP(httptest.NewRecorder(), req.WithContext(context.Background()))
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != len(tests) {
+		t.Fatalf("got %d audit records, want %d", len(lines), len(tests))
+	}
+	for i, tt := range tests {
+		got := audit.Record{}
+		if err := json.Unmarshal([]byte(lines[i]), &got); err != nil {
+			t.Fatal(err)
+		}
+		if got.Action != audit.ActionAddEntry || got.Identity != "ci@example.com" || got.SourceIP != "192.0.2.1" ||
+			got.ForwardedFor != "198.51.100.7" || got.TreeID != 42 {
+			t.Errorf("%s: unexpected audit record %+v", tt.status, got)
+		}
+		if got.Outcome != tt.want.Outcome || got.EntryUUID != tt.want.EntryUUID || got.Detail != tt.want.Detail {
+			t.Errorf("%s: got outcome %q, UUID %q, detail %q; want %+v", tt.status, got.Outcome, got.EntryUUID, got.Detail, tt.want)
+		}
+	}
+	if n, err := audit.Verify(bytes.NewReader(buf.Bytes())); err != nil || n != len(tests) {
+		t.Errorf("Verify() = %d, %v", n, err)
+	}
+}
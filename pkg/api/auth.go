@@ -0,0 +1,173 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	pkgerrors "github.com/pkg/errors"
+
+	"github.com/sigstore/rekor/pkg/auth"
+	"github.com/sigstore/rekor/pkg/log"
+)
+
+var (
+	// writeAuthenticator identifies the callers of write endpoints; nil if writes are not authenticated
+	writeAuthenticator auth.Authenticator
+	writeLimiter       *auth.Limiter
+)
+
+// authConfig holds the auth.* settings
+type authConfig struct {
+	TokensFile string `mapstructure:"tokens_file"`
+	OIDC       struct {
+		Issuer   string `mapstructure:"issuer"`
+		ClientID string `mapstructure:"client_id"`
+	} `mapstructure:"oidc"`
+	RateLimit          float64           `mapstructure:"rate_limit"`
+	Burst              int               `mapstructure:"burst"`
+	IdentityRateLimits map[string]string `mapstructure:"identity_rate_limits"`
+}
+
+func (c authConfig) validate() error {
+	if c.OIDC.Issuer != "" && c.OIDC.ClientID == "" {
+		return errors.New("auth.oidc.client_id must be set along with auth.oidc.issuer")
+	}
+	if c.RateLimit < 0 {
+		return errors.New("auth.rate_limit must not be negative")
+	}
+	_, err := c.overrides()
+	return err
+}
+
+func (c authConfig) overrides() (map[string]float64, error) {
+	overrides := map[string]float64{}
+	for identity, limit := range c.IdentityRateLimits {
+		l, err := strconv.ParseFloat(limit, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid rate limit %q for %s", limit, identity)
+		}
+		overrides[identity] = l
+	}
+	return overrides, nil
+}
+
+// writeAuthFromConfig sets up authentication of writes from the auth.* settings; writes are only
+// authenticated if API tokens or an OIDC issuer have been configured, otherwise nil is returned
+func writeAuthFromConfig(ctx context.Context, c authConfig) (auth.Authenticator, *auth.Limiter, error) {
+	if err := c.validate(); err != nil {
+		return nil, nil, err
+	}
+	var chain auth.Chain
+	if c.TokensFile != "" {
+		tokens, err := auth.LoadStaticTokens(c.TokensFile)
+		if err != nil {
+			return nil, nil, pkgerrors.Wrap(err, "loading API tokens")
+		}
+		chain = append(chain, tokens)
+	}
+	if c.OIDC.Issuer != "" {
+		client := &http.Client{Timeout: 30 * time.Second}
+		verifier, err := auth.NewOIDCVerifier(ctx, client, c.OIDC.Issuer, c.OIDC.ClientID)
+		if err != nil {
+			return nil, nil, err
+		}
+		chain = append(chain, verifier)
+	}
+	if len(chain) == 0 {
+		return nil, nil, nil
+	}
+
+	overrides, err := c.overrides()
+	if err != nil {
+		return nil, nil, err
+	}
+	return chain, &auth.Limiter{
+		PerMinute: c.RateLimit,
+		Burst:     c.Burst,
+		Overrides: overrides,
+	}, nil
+}
+
+// configureWriteAuth replaces the authentication of writes with the one described by c
+func configureWriteAuth(ctx context.Context, c authConfig) error {
+	authenticator, limiter, err := writeAuthFromConfig(ctx, c)
+	if err != nil {
+		return err
+	}
+	policyMu.Lock()
+	defer policyMu.Unlock()
+	writeAuthenticator, writeLimiter = authenticator, keepLimiter(limiter)
+	return nil
+}
+
+// keepLimiter returns the current write limiter in place of l if it enforces the same limits, so that
+// reloading the configuration does not reset the budget of every identity. policyMu must be held.
+func keepLimiter(l *auth.Limiter) *auth.Limiter {
+	if l != nil && writeLimiter != nil && writeLimiter.SameLimits(l) {
+		return writeLimiter
+	}
+	return l
+}
+
+// AuthenticateWrites requires requests to carry an API token or OIDC ID token accepted by the server
+// and enforces the write rate limit of the identity it belongs to; requests pass through unchanged if
+// writes are not authenticated
+func AuthenticateWrites(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		policyMu.RLock()
+		authenticator, limiter := writeAuthenticator, writeLimiter
+		policyMu.RUnlock()
+		if authenticator == nil {
+			handler.ServeHTTP(w, r)
+			return
+		}
+
+		token, err := auth.TokenFromRequest(r)
+		if err == nil {
+			var identity string
+			identity, err = authenticator.Authenticate(r.Context(), token)
+			if err == nil {
+				setAuditIdentity(r, identity)
+				if ok, wait := limiter.Allow(identity); !ok {
+					log.RequestIDLogger(r).Warnf("write rate limit exceeded by %s", identity)
+					w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+					writeErrorResponse(w, http.StatusTooManyRequests, fmt.Sprintf("Write rate limit exceeded for %s", identity))
+					return
+				}
+				log.RequestIDLogger(r).Infof("write authenticated as %s", identity)
+				handler.ServeHTTP(w, r)
+				return
+			}
+		}
+
+		switch {
+		case errors.Is(err, auth.ErrNoCredentials), errors.Is(err, auth.ErrInvalidCredentials):
+			log.RequestIDLogger(r).Warnf("rejected unauthenticated write: %v", err)
+			w.Header().Set("WWW-Authenticate", `Bearer realm="rekor"`)
+			writeErrorResponse(w, http.StatusUnauthorized, "A valid API token or identity token is required to add entries to this log")
+		default:
+			log.RequestIDLogger(r).Errorf("error authenticating write: %v", err)
+			writeErrorResponse(w, http.StatusServiceUnavailable, "Unable to verify credentials")
+		}
+	})
+}
@@ -0,0 +1,108 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+)
+
+func TestAuthenticateWrites(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	})
+	handler := AuthenticateWrites(next)
+	post := func(header string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, "/api/v1/log/entries", nil)
+		if header != "" {
+			r.Header.Set("Authorization", header)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, r)
+		return rec
+	}
+
+	t.Cleanup(func() {
+		writeAuthenticator, writeLimiter = nil, nil
+	})
+
+	// without configuration writes are not authenticated
+	if err := configureWriteAuth(context.Background(), authConfig{}); err != nil {
+		t.Fatal(err)
+	}
+	if rec := post(""); rec.Code != http.StatusCreated {
+		t.Fatalf("unauthenticated write without auth configured: status %d", rec.Code)
+	}
+
+	tokens := filepath.Join(t.TempDir(), "tokens")
+	if err := ioutil.WriteFile(tokens, []byte("ci s3cr3t\nbot 0th3r\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	cfg := authConfig{
+		TokensFile:         tokens,
+		RateLimit:          1,
+		Burst:              1,
+		IdentityRateLimits: map[string]string{"bot": "0"},
+	}
+	if err := configureWriteAuth(context.Background(), cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	rec := post("")
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("write without token: status %d, want 401", rec.Code)
+	}
+	if rec.Header().Get("WWW-Authenticate") == "" {
+		t.Error("expected WWW-Authenticate header")
+	}
+	if rec := post("Bearer wrong"); rec.Code != http.StatusUnauthorized {
+		t.Errorf("write with invalid token: status %d, want 401", rec.Code)
+	}
+	if rec := post("Bearer s3cr3t"); rec.Code != http.StatusCreated {
+		t.Errorf("write with valid token: status %d, want 201", rec.Code)
+	}
+	rec = post("Bearer s3cr3t")
+	if rec.Code != http.StatusTooManyRequests {
+		t.Errorf("write beyond rate limit: status %d, want 429", rec.Code)
+	}
+	if rec.Header().Get("Retry-After") != "60" {
+		t.Errorf("Retry-After = %q, want 60", rec.Header().Get("Retry-After"))
+	}
+	for i := 0; i < 3; i++ {
+		if rec := post("Bearer 0th3r"); rec.Code != http.StatusCreated {
+			t.Errorf("write by unlimited identity: status %d, want 201", rec.Code)
+		}
+	}
+
+	// reloading the same limits keeps the budgets used up, while new limits start afresh
+	if err := configureWriteAuth(context.Background(), cfg); err != nil {
+		t.Fatal(err)
+	}
+	if rec := post("Bearer s3cr3t"); rec.Code != http.StatusTooManyRequests {
+		t.Errorf("write beyond rate limit after reload: status %d, want 429", rec.Code)
+	}
+	cfg.Burst = 2
+	if err := configureWriteAuth(context.Background(), cfg); err != nil {
+		t.Fatal(err)
+	}
+	if rec := post("Bearer s3cr3t"); rec.Code != http.StatusCreated {
+		t.Errorf("write after the limit was raised: status %d, want 201", rec.Code)
+	}
+}
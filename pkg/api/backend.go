@@ -0,0 +1,372 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/types"
+	"github.com/pkg/errors"
+	"google.golang.org/genproto/googleapis/rpc/code"
+	rpcstatus "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	backendTrillian = "trillian"
+	backendEmbedded = "embedded"
+)
+
+// backendConfig holds the backend.* settings
+type backendConfig struct {
+	// where the Merkle trees of the log are kept: in Trillian, or embedded in rekor-server
+	Type string `mapstructure:"type"`
+	// directory holding the trees of the embedded backend
+	Path string `mapstructure:"path"`
+}
+
+func (c backendConfig) validate() error {
+	switch c.Type {
+	case "", backendTrillian:
+		return nil
+	case backendEmbedded:
+		if c.Path == "" {
+			return errors.New("path must be set for the embedded backend")
+		}
+		return nil
+	default:
+		return errors.Errorf("unknown type %q, expected trillian or embedded", c.Type)
+	}
+}
+
+func (c backendConfig) embedded() bool {
+	return c.Type == backendEmbedded
+}
+
+// LogBackend keeps the Merkle trees of a log, each identified by its tree ID. Errors carry gRPC
+// status codes as Trillian's do: NotFound for a leaf which is not in a tree and OutOfRange for an
+// index or a tree size beyond the end of a tree.
+type LogBackend interface {
+	// AppendLeaf adds a leaf to the end of a tree and returns it, with its index set, once it is
+	// integrated. If a leaf with the same value is already in the tree, that leaf is returned along
+	// with an AlreadyExists error.
+	AppendLeaf(ctx context.Context, treeID int64, value, extraData []byte) (*trillian.LogLeaf, error)
+	// GetLeaf returns the leaf at index
+	GetLeaf(ctx context.Context, treeID, index int64) (*trillian.LogLeaf, error)
+	// GetLeaves returns at most count leaves from index start; fewer are returned at the end of the tree
+	GetLeaves(ctx context.Context, treeID, start, count int64) ([]*trillian.LogLeaf, error)
+	// FindLeaf returns the index of the first leaf with the given Merkle leaf hash among the first
+	// treeSize leaves of a tree
+	FindLeaf(ctx context.Context, treeID int64, leafHash []byte, treeSize int64) (int64, error)
+	// InclusionProof returns the audit path of the leaf at index in the tree of the first treeSize leaves
+	InclusionProof(ctx context.Context, treeID, index, treeSize int64) ([][]byte, error)
+	// ConsistencyProof returns the proof that the tree of the first firstSize leaves is a prefix of
+	// the tree of the first secondSize leaves
+	ConsistencyProof(ctx context.Context, treeID, firstSize, secondSize int64) ([][]byte, error)
+	// TreeHead returns the root of the whole tree
+	TreeHead(ctx context.Context, treeID int64) (*types.LogRootV1, error)
+}
+
+// logClientFor returns the client the API reads and writes the trees of b with. The API speaks the
+// Trillian log protocol, to which every backend, Trillian included, is adapted.
+func logClientFor(b LogBackend) trillian.TrillianLogClient {
+	return &backendLogClient{backend: b}
+}
+
+// trillianBackend keeps the trees in Trillian
+type trillianBackend struct {
+	client trillian.TrillianLogClient
+	// interval between checks for the integration of an appended leaf
+	pollInterval time.Duration
+}
+
+func newTrillianBackend(client trillian.TrillianLogClient) *trillianBackend {
+	return &trillianBackend{client: client, pollInterval: 100 * time.Millisecond}
+}
+
+func (b *trillianBackend) AppendLeaf(ctx context.Context, treeID int64, value, extraData []byte) (*trillian.LogLeaf, error) {
+	resp, err := b.client.QueueLeaf(ctx, &trillian.QueueLeafRequest{
+		LogId: treeID,
+		Leaf:  &trillian.LogLeaf{LeafValue: value, ExtraData: extraData},
+	})
+	if err != nil {
+		return nil, err
+	}
+	exists := false
+	if s := resp.QueuedLeaf.GetStatus(); s != nil && s.Code != int32(code.Code_OK) {
+		if s.Code != int32(code.Code_ALREADY_EXISTS) {
+			return nil, status.ErrorProto(s)
+		}
+		exists = true
+	}
+
+	// the leaf, or the one with the same value, may not be integrated yet
+	for {
+		head, err := b.TreeHead(ctx, treeID)
+		if err != nil {
+			return nil, err
+		}
+		if head.TreeSize > 0 {
+			index, err := b.FindLeaf(ctx, treeID, resp.QueuedLeaf.Leaf.MerkleLeafHash, int64(head.TreeSize))
+			if err == nil {
+				leaf, err := b.GetLeaf(ctx, treeID, index)
+				if err != nil {
+					return nil, err
+				}
+				if exists {
+					return leaf, status.Error(codes.AlreadyExists, "leaf already exists")
+				}
+				return leaf, nil
+			}
+			if status.Code(err) != codes.NotFound {
+				return nil, err
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return nil, status.Error(codes.DeadlineExceeded, ctx.Err().Error())
+		case <-time.After(b.pollInterval):
+		}
+	}
+}
+
+func (b *trillianBackend) GetLeaf(ctx context.Context, treeID, index int64) (*trillian.LogLeaf, error) {
+	leaves, err := b.GetLeaves(ctx, treeID, index, 1)
+	if err != nil {
+		return nil, err
+	}
+	return leaves[0], nil
+}
+
+func (b *trillianBackend) GetLeaves(ctx context.Context, treeID, start, count int64) ([]*trillian.LogLeaf, error) {
+	resp, err := b.client.GetLeavesByRange(ctx, &trillian.GetLeavesByRangeRequest{LogId: treeID, StartIndex: start, Count: count})
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Leaves) == 0 {
+		return nil, status.Errorf(codes.OutOfRange, "leaf index %d is beyond the end of the tree", start)
+	}
+	return resp.Leaves, nil
+}
+
+func (b *trillianBackend) FindLeaf(ctx context.Context, treeID int64, leafHash []byte, treeSize int64) (int64, error) {
+	resp, err := b.client.GetInclusionProofByHash(ctx, &trillian.GetInclusionProofByHashRequest{LogId: treeID, LeafHash: leafHash, TreeSize: treeSize})
+	if err != nil {
+		return 0, err
+	}
+	if len(resp.Proof) == 0 {
+		return 0, status.Error(codes.NotFound, "leaf not found")
+	}
+	return resp.Proof[0].LeafIndex, nil
+}
+
+func (b *trillianBackend) InclusionProof(ctx context.Context, treeID, index, treeSize int64) ([][]byte, error) {
+	resp, err := b.client.GetInclusionProof(ctx, &trillian.GetInclusionProofRequest{LogId: treeID, LeafIndex: index, TreeSize: treeSize})
+	if err != nil {
+		return nil, err
+	}
+	if resp.Proof == nil {
+		return nil, status.Errorf(codes.OutOfRange, "tree size %d is beyond the end of the tree", treeSize)
+	}
+	return resp.Proof.Hashes, nil
+}
+
+func (b *trillianBackend) ConsistencyProof(ctx context.Context, treeID, firstSize, secondSize int64) ([][]byte, error) {
+	resp, err := b.client.GetConsistencyProof(ctx, &trillian.GetConsistencyProofRequest{LogId: treeID, FirstTreeSize: firstSize, SecondTreeSize: secondSize})
+	if err != nil {
+		return nil, err
+	}
+	// trillian returns no proof if the second tree is larger than the log
+	if resp.Proof == nil {
+		return nil, status.Errorf(codes.OutOfRange, "tree size %d is beyond the end of the tree", secondSize)
+	}
+	return resp.Proof.Hashes, nil
+}
+
+func (b *trillianBackend) TreeHead(ctx context.Context, treeID int64) (*types.LogRootV1, error) {
+	resp, err := b.client.GetLatestSignedLogRoot(ctx, &trillian.GetLatestSignedLogRootRequest{LogId: treeID})
+	if err != nil {
+		return nil, err
+	}
+	root := &types.LogRootV1{}
+	if err := root.UnmarshalBinary(resp.SignedLogRoot.GetLogRoot()); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return root, nil
+}
+
+// backendLogClient serves the trees of a LogBackend through the Trillian log protocol. Trees are
+// created by the backend when a leaf is first added to them, and leaves can only be appended, so
+// InitLog and AddSequencedLeaves are not implemented.
+type backendLogClient struct {
+	backend LogBackend
+}
+
+var _ trillian.TrillianLogClient = &backendLogClient{}
+
+// head returns the root of a tree as trillian returns it, and the size of the tree
+func (c *backendLogClient) head(ctx context.Context, treeID int64) (*trillian.SignedLogRoot, int64, error) {
+	root, err := c.backend.TreeHead(ctx, treeID)
+	if err != nil {
+		return nil, 0, err
+	}
+	b, err := root.MarshalBinary()
+	if err != nil {
+		return nil, 0, status.Error(codes.Internal, err.Error())
+	}
+	return &trillian.SignedLogRoot{LogRoot: b}, int64(root.TreeSize), nil
+}
+
+func (c *backendLogClient) QueueLeaf(ctx context.Context, in *trillian.QueueLeafRequest, opts ...grpc.CallOption) (*trillian.QueueLeafResponse, error) {
+	if in.Leaf == nil {
+		return nil, status.Error(codes.InvalidArgument, "missing leaf")
+	}
+	leaf, err := c.backend.AppendLeaf(ctx, in.LogId, in.Leaf.LeafValue, in.Leaf.ExtraData)
+	if status.Code(err) == codes.AlreadyExists && leaf != nil {
+		return &trillian.QueueLeafResponse{QueuedLeaf: &trillian.QueuedLogLeaf{
+			Leaf:   leaf,
+			Status: &rpcstatus.Status{Code: int32(code.Code_ALREADY_EXISTS), Message: "leaf already exists"},
+		}}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &trillian.QueueLeafResponse{QueuedLeaf: &trillian.QueuedLogLeaf{Leaf: leaf}}, nil
+}
+
+func (c *backendLogClient) GetLatestSignedLogRoot(ctx context.Context, in *trillian.GetLatestSignedLogRootRequest, opts ...grpc.CallOption) (*trillian.GetLatestSignedLogRootResponse, error) {
+	root, size, err := c.head(ctx, in.LogId)
+	if err != nil {
+		return nil, err
+	}
+	resp := &trillian.GetLatestSignedLogRootResponse{SignedLogRoot: root}
+	if in.FirstTreeSize > 0 && in.FirstTreeSize < size {
+		hashes, err := c.backend.ConsistencyProof(ctx, in.LogId, in.FirstTreeSize, size)
+		if err != nil {
+			return nil, err
+		}
+		resp.Proof = &trillian.Proof{Hashes: hashes}
+	}
+	return resp, nil
+}
+
+func (c *backendLogClient) GetInclusionProofByHash(ctx context.Context, in *trillian.GetInclusionProofByHashRequest, opts ...grpc.CallOption) (*trillian.GetInclusionProofByHashResponse, error) {
+	index, err := c.backend.FindLeaf(ctx, in.LogId, in.LeafHash, in.TreeSize)
+	if err != nil {
+		return nil, err
+	}
+	hashes, err := c.backend.InclusionProof(ctx, in.LogId, index, in.TreeSize)
+	if err != nil {
+		return nil, err
+	}
+	root, _, err := c.head(ctx, in.LogId)
+	if err != nil {
+		return nil, err
+	}
+	return &trillian.GetInclusionProofByHashResponse{
+		Proof:         []*trillian.Proof{{LeafIndex: index, Hashes: hashes}},
+		SignedLogRoot: root,
+	}, nil
+}
+
+func (c *backendLogClient) GetInclusionProof(ctx context.Context, in *trillian.GetInclusionProofRequest, opts ...grpc.CallOption) (*trillian.GetInclusionProofResponse, error) {
+	hashes, err := c.backend.InclusionProof(ctx, in.LogId, in.LeafIndex, in.TreeSize)
+	if err != nil {
+		return nil, err
+	}
+	root, _, err := c.head(ctx, in.LogId)
+	if err != nil {
+		return nil, err
+	}
+	return &trillian.GetInclusionProofResponse{
+		Proof:         &trillian.Proof{LeafIndex: in.LeafIndex, Hashes: hashes},
+		SignedLogRoot: root,
+	}, nil
+}
+
+func (c *backendLogClient) GetEntryAndProof(ctx context.Context, in *trillian.GetEntryAndProofRequest, opts ...grpc.CallOption) (*trillian.GetEntryAndProofResponse, error) {
+	hashes, err := c.backend.InclusionProof(ctx, in.LogId, in.LeafIndex, in.TreeSize)
+	if err != nil {
+		return nil, err
+	}
+	leaf, err := c.backend.GetLeaf(ctx, in.LogId, in.LeafIndex)
+	if err != nil {
+		return nil, err
+	}
+	root, _, err := c.head(ctx, in.LogId)
+	if err != nil {
+		return nil, err
+	}
+	return &trillian.GetEntryAndProofResponse{
+		Proof:         &trillian.Proof{LeafIndex: in.LeafIndex, Hashes: hashes},
+		Leaf:          leaf,
+		SignedLogRoot: root,
+	}, nil
+}
+
+func (c *backendLogClient) GetConsistencyProof(ctx context.Context, in *trillian.GetConsistencyProofRequest, opts ...grpc.CallOption) (*trillian.GetConsistencyProofResponse, error) {
+	if in.FirstTreeSize <= 0 || in.FirstTreeSize > in.SecondTreeSize {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid tree sizes %d and %d", in.FirstTreeSize, in.SecondTreeSize)
+	}
+	root, size, err := c.head(ctx, in.LogId)
+	if err != nil {
+		return nil, err
+	}
+	// as trillian does, no proof is returned if the second tree is larger than the log
+	resp := &trillian.GetConsistencyProofResponse{SignedLogRoot: root}
+	if in.SecondTreeSize <= size {
+		hashes, err := c.backend.ConsistencyProof(ctx, in.LogId, in.FirstTreeSize, in.SecondTreeSize)
+		if err != nil {
+			return nil, err
+		}
+		resp.Proof = &trillian.Proof{Hashes: hashes}
+	}
+	return resp, nil
+}
+
+func (c *backendLogClient) GetLeavesByRange(ctx context.Context, in *trillian.GetLeavesByRangeRequest, opts ...grpc.CallOption) (*trillian.GetLeavesByRangeResponse, error) {
+	if in.StartIndex < 0 || in.Count <= 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid range of %d leaves from %d", in.Count, in.StartIndex)
+	}
+	root, size, err := c.head(ctx, in.LogId)
+	if err != nil {
+		return nil, err
+	}
+	if in.StartIndex >= size {
+		return nil, status.Errorf(codes.OutOfRange, "start index %d is beyond the tree size %d", in.StartIndex, size)
+	}
+	end := in.StartIndex + in.Count
+	if end > size {
+		end = size
+	}
+	leaves, err := c.backend.GetLeaves(ctx, in.LogId, in.StartIndex, end-in.StartIndex)
+	if err != nil {
+		return nil, err
+	}
+	return &trillian.GetLeavesByRangeResponse{Leaves: leaves, SignedLogRoot: root}, nil
+}
+
+func (c *backendLogClient) InitLog(ctx context.Context, in *trillian.InitLogRequest, opts ...grpc.CallOption) (*trillian.InitLogResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "trees of the log backend do not need to be initialized")
+}
+
+func (c *backendLogClient) AddSequencedLeaves(ctx context.Context, in *trillian.AddSequencedLeavesRequest, opts ...grpc.CallOption) (*trillian.AddSequencedLeavesResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "leaves can only be appended to the trees of the log backend")
+}
@@ -0,0 +1,413 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/bits"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/merkle/rfc6962"
+	"github.com/google/trillian/types"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/sigstore/rekor/pkg/util"
+)
+
+const (
+	// embeddedTreeID is the tree of the log when the embedded backend is used and no tree is configured
+	embeddedTreeID = 1
+	// suffix of the files holding the trees of the embedded backend, named by tree ID
+	embeddedTreeSuffix = ".ndjson"
+	// file of the backend's directory locked by the process using it
+	embeddedLockFile = "lock"
+)
+
+// embeddedBackend keeps the trees of the log in rekor-server, so that a small deployment with a
+// single server needs neither Trillian nor a database. Each tree is held in memory and in a file of
+// the backend's directory, to which every leaf is written and synced before it is integrated; the
+// files are read back when the backend is opened. Only one process may use a directory at a time,
+// which holds a lock on a file of the directory until the backend is closed.
+type embeddedBackend struct {
+	dir   string
+	lock  *os.File
+	mu    sync.RWMutex
+	trees map[int64]*embeddedTree
+}
+
+type embeddedTree struct {
+	leaves  []*trillian.LogLeaf
+	levels  merkleLevels
+	byHash  map[string]int64 // index of each leaf by its Merkle leaf hash
+	byValue map[string]int64 // index of each leaf by the hash of its value, to reject duplicates
+	// when the last leaf was integrated, or the tree was created
+	updated time.Time
+	// nil for the empty trees of IDs to which no leaf has been added
+	file *os.File
+	// size of the file up to the end of the last leaf written
+	offset int64
+}
+
+func newEmbeddedTree(file *os.File) *embeddedTree {
+	return &embeddedTree{byHash: map[string]int64{}, byValue: map[string]int64{}, updated: time.Now(), file: file}
+}
+
+// openEmbeddedBackend opens the backend keeping its trees in dir, which is created if it does not
+// exist. It fails if another process, such as a running rekor-server, has the directory open.
+func openEmbeddedBackend(dir string) (*embeddedBackend, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	lock, err := os.OpenFile(filepath.Join(dir, embeddedLockFile), os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, err
+	}
+	if err := util.TryLockFile(lock); err != nil {
+		lock.Close()
+		if errors.Is(err, util.ErrLocked) {
+			return nil, errors.Errorf("%s is in use by another process", dir)
+		}
+		return nil, errors.Wrap(err, "locking")
+	}
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		lock.Close()
+		return nil, err
+	}
+	b := &embeddedBackend{dir: dir, lock: lock, trees: map[int64]*embeddedTree{}}
+	for _, f := range files {
+		treeID, err := strconv.ParseInt(strings.TrimSuffix(f.Name(), embeddedTreeSuffix), 10, 64)
+		if f.IsDir() || !strings.HasSuffix(f.Name(), embeddedTreeSuffix) || err != nil || treeID <= 0 {
+			continue
+		}
+		t, err := openEmbeddedTree(filepath.Join(dir, f.Name()))
+		if err != nil {
+			b.Close()
+			return nil, errors.Wrapf(err, "tree %d", treeID)
+		}
+		b.trees[treeID] = t
+	}
+	return b, nil
+}
+
+// openEmbeddedTree reads the leaves of a tree from its file, which is then appended to
+func openEmbeddedTree(path string) (*embeddedTree, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, err
+	}
+	t := newEmbeddedTree(f)
+	r := bufio.NewReader(f)
+	for {
+		line, err := r.ReadBytes('\n')
+		if err == io.EOF {
+			// a leaf whose write was interrupted was never integrated, so whatever remains of it is dropped
+			break
+		} else if err != nil {
+			f.Close()
+			return nil, err
+		}
+		l := archivedLeaf{}
+		if err := json.Unmarshal(line, &l); err != nil {
+			f.Close()
+			return nil, errors.Wrapf(err, "reading leaf %d", len(t.leaves))
+		}
+		if l.Index != int64(len(t.leaves)) {
+			f.Close()
+			return nil, errors.Errorf("found leaf %d instead of %d", l.Index, len(t.leaves))
+		}
+		t.add(l.Value, l.ExtraData, l.IntegratedTime)
+		t.offset += int64(len(line))
+	}
+	if err := f.Truncate(t.offset); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return t, nil
+}
+
+// Close closes the files of the trees and releases the lock on the directory
+func (b *embeddedBackend) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var err error
+	for _, t := range b.trees {
+		if cerr := t.file.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}
+	if cerr := b.lock.Close(); cerr != nil && err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// tree returns the tree with the given ID; trees are empty until a leaf is first added to them
+func (b *embeddedBackend) tree(treeID int64) *embeddedTree {
+	if t, ok := b.trees[treeID]; ok {
+		return t
+	}
+	return newEmbeddedTree(nil)
+}
+
+// add integrates a leaf; the caller checks that no leaf with the same value is already in the tree
+func (t *embeddedTree) add(value, extraData []byte, integrated time.Time) *trillian.LogLeaf {
+	identity := sha256.Sum256(value)
+	ts := timestamppb.New(integrated)
+	leaf := &trillian.LogLeaf{
+		MerkleLeafHash:     rfc6962.DefaultHasher.HashLeaf(value),
+		LeafValue:          value,
+		ExtraData:          extraData,
+		LeafIndex:          t.size(),
+		LeafIdentityHash:   identity[:],
+		QueueTimestamp:     ts,
+		IntegrateTimestamp: ts,
+	}
+	t.leaves = append(t.leaves, leaf)
+	t.levels.append(leaf.MerkleLeafHash)
+	t.byValue[string(identity[:])] = leaf.LeafIndex
+	if _, ok := t.byHash[string(leaf.MerkleLeafHash)]; !ok {
+		t.byHash[string(leaf.MerkleLeafHash)] = leaf.LeafIndex
+	}
+	t.updated = integrated
+	return leaf
+}
+
+func (t *embeddedTree) size() int64 {
+	return int64(len(t.leaves))
+}
+
+// checkSize checks that a tree size requested is no larger than the tree
+func (t *embeddedTree) checkSize(size int64) error {
+	if size < 0 || size > t.size() {
+		return status.Errorf(codes.OutOfRange, "tree size %d is out of range [0, %d]", size, t.size())
+	}
+	return nil
+}
+
+// checkIndex checks that a leaf index is within the tree of the first size leaves
+func (t *embeddedTree) checkIndex(index, size int64) error {
+	if err := t.checkSize(size); err != nil {
+		return err
+	}
+	if index < 0 || index >= size {
+		return status.Errorf(codes.OutOfRange, "leaf index %d is out of range for tree size %d", index, size)
+	}
+	return nil
+}
+
+func (b *embeddedBackend) AppendLeaf(ctx context.Context, treeID int64, value, extraData []byte) (*trillian.LogLeaf, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	t, ok := b.trees[treeID]
+	if !ok {
+		if treeID <= 0 {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid tree ID %d", treeID)
+		}
+		f, err := os.OpenFile(filepath.Join(b.dir, fmt.Sprintf("%d%s", treeID, embeddedTreeSuffix)), os.O_RDWR|os.O_CREATE|os.O_APPEND, 0600)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "creating tree %d: %v", treeID, err)
+		}
+		t = newEmbeddedTree(f)
+		b.trees[treeID] = t
+	}
+
+	identity := sha256.Sum256(value)
+	if index, ok := t.byValue[string(identity[:])]; ok {
+		return t.leaves[index], status.Error(codes.AlreadyExists, "leaf already exists")
+	}
+	integrated := time.Now()
+	line, err := json.Marshal(archivedLeaf{Index: t.size(), Value: value, ExtraData: extraData, IntegratedTime: integrated})
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	line = append(line, '\n')
+	if _, err := t.file.Write(line); err != nil {
+		// drop what may have been written, so that the next leaf starts on a line of its own
+		_ = t.file.Truncate(t.offset)
+		return nil, status.Errorf(codes.Internal, "writing leaf: %v", err)
+	}
+	if err := t.file.Sync(); err != nil {
+		_ = t.file.Truncate(t.offset)
+		return nil, status.Errorf(codes.Internal, "syncing leaf: %v", err)
+	}
+	t.offset += int64(len(line))
+	return t.add(value, extraData, integrated), nil
+}
+
+func (b *embeddedBackend) GetLeaf(ctx context.Context, treeID, index int64) (*trillian.LogLeaf, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	t := b.tree(treeID)
+
+	if err := t.checkIndex(index, t.size()); err != nil {
+		return nil, err
+	}
+	return t.leaves[index], nil
+}
+
+func (b *embeddedBackend) GetLeaves(ctx context.Context, treeID, start, count int64) ([]*trillian.LogLeaf, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	t := b.tree(treeID)
+
+	size := t.size()
+	if err := t.checkIndex(start, size); err != nil {
+		return nil, err
+	}
+	end := start + count
+	if end > size {
+		end = size
+	}
+	return append([]*trillian.LogLeaf{}, t.leaves[start:end]...), nil
+}
+
+func (b *embeddedBackend) FindLeaf(ctx context.Context, treeID int64, leafHash []byte, treeSize int64) (int64, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	t := b.tree(treeID)
+
+	if err := t.checkSize(treeSize); err != nil {
+		return 0, err
+	}
+	index, ok := t.byHash[string(leafHash)]
+	if !ok || index >= treeSize {
+		return 0, status.Error(codes.NotFound, "leaf not found")
+	}
+	return index, nil
+}
+
+func (b *embeddedBackend) InclusionProof(ctx context.Context, treeID, index, treeSize int64) ([][]byte, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	t := b.tree(treeID)
+
+	if err := t.checkIndex(index, treeSize); err != nil {
+		return nil, err
+	}
+	return t.levels.inclusionProof(index, 0, treeSize), nil
+}
+
+func (b *embeddedBackend) ConsistencyProof(ctx context.Context, treeID, firstSize, secondSize int64) ([][]byte, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	t := b.tree(treeID)
+
+	if err := t.checkSize(secondSize); err != nil {
+		return nil, err
+	}
+	if firstSize <= 0 || firstSize > secondSize {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid tree sizes %d and %d", firstSize, secondSize)
+	}
+	return t.levels.consistencyProof(firstSize, 0, secondSize), nil
+}
+
+func (b *embeddedBackend) TreeHead(ctx context.Context, treeID int64) (*types.LogRootV1, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	t := b.tree(treeID)
+
+	return &types.LogRootV1{
+		TreeSize:       uint64(t.size()),
+		RootHash:       t.levels.root(0, t.size()),
+		TimestampNanos: uint64(t.updated.UnixNano()),
+		Revision:       uint64(t.size()),
+	}, nil
+}
+
+// merkleLevels holds the roots of the complete subtrees of an RFC 6962 Merkle tree: level k holds
+// the root of each successive subtree of 2^k leaves. The root of any range of leaves, and so any
+// proof, then takes a number of hashes logarithmic in the size of the tree.
+type merkleLevels [][][]byte
+
+// append adds a leaf hash and the roots of the subtrees it completes
+func (l *merkleLevels) append(leafHash []byte) {
+	h := leafHash
+	for k := 0; ; k++ {
+		if k == len(*l) {
+			*l = append(*l, nil)
+		}
+		(*l)[k] = append((*l)[k], h)
+		n := len((*l)[k])
+		if n%2 == 1 {
+			return
+		}
+		h = rfc6962.DefaultHasher.HashChildren((*l)[k][n-2], (*l)[k][n-1])
+	}
+}
+
+// root returns the Merkle tree hash of the leaves in [start, end)
+func (l merkleLevels) root(start, end int64) []byte {
+	n := end - start
+	switch {
+	case n == 0:
+		return rfc6962.DefaultHasher.EmptyRoot()
+	case n&(n-1) == 0 && start%n == 0:
+		return l[bits.TrailingZeros64(uint64(n))][start/n]
+	default:
+		k := split(n)
+		return rfc6962.DefaultHasher.HashChildren(l.root(start, start+k), l.root(start+k, end))
+	}
+}
+
+// inclusionProof returns the audit path of leaf m of the tree of the leaves in [start, end), ordered
+// from the leaf to the root
+func (l merkleLevels) inclusionProof(m, start, end int64) [][]byte {
+	n := end - start
+	if n <= 1 {
+		return [][]byte{}
+	}
+	k := split(n)
+	if m < k {
+		return append(l.inclusionProof(m, start, start+k), l.root(start+k, end))
+	}
+	return append(l.inclusionProof(m-k, start+k, end), l.root(start, start+k))
+}
+
+// consistencyProof returns the consistency proof between the first m leaves of [start, end) and all of them
+func (l merkleLevels) consistencyProof(m, start, end int64) [][]byte {
+	return l.subproof(m, start, end, true)
+}
+
+func (l merkleLevels) subproof(m, start, end int64, complete bool) [][]byte {
+	n := end - start
+	if m == n {
+		if complete {
+			return [][]byte{}
+		}
+		return [][]byte{l.root(start, end)}
+	}
+	k := split(n)
+	if m <= k {
+		return append(l.subproof(m, start, start+k, complete), l.root(start+k, end))
+	}
+	return append(l.subproof(m-k, start+k, end, false), l.root(start, start+k))
+}
@@ -0,0 +1,289 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/merkle/logverifier"
+	"github.com/google/trillian/merkle/rfc6962"
+	"google.golang.org/genproto/googleapis/rpc/code"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestMerkleLevels(t *testing.T) {
+	var levels merkleLevels
+	var hashes [][]byte
+	for n := int64(0); n <= 40; n++ {
+		if got, want := levels.root(0, n), merkleRoot(hashes); !bytes.Equal(got, want) {
+			t.Fatalf("root of %d leaves differs", n)
+		}
+		for m := int64(0); m < n; m++ {
+			if got, want := levels.inclusionProof(m, 0, n), inclusionProof(m, hashes); !reflect.DeepEqual(got, want) {
+				t.Fatalf("inclusion proof of leaf %d of %d differs", m, n)
+			}
+			if m > 0 {
+				if got, want := levels.consistencyProof(m, 0, n), consistencyProof(m, hashes); !reflect.DeepEqual(got, want) {
+					t.Fatalf("consistency proof between %d and %d leaves differs", m, n)
+				}
+			}
+		}
+		leaf := rfc6962.DefaultHasher.HashLeaf([]byte(fmt.Sprintf("leaf %d", n)))
+		levels.append(leaf)
+		hashes = append(hashes, leaf)
+	}
+}
+
+func openTestBackend(t *testing.T, dir string) *embeddedBackend {
+	t.Helper()
+	b, err := openEmbeddedBackend(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { b.Close() })
+	return b
+}
+
+func TestLogBackends(t *testing.T) {
+	for name, b := range map[string]LogBackend{
+		"trillian": newTrillianBackend(newMemoryLogClient()),
+		"embedded": openTestBackend(t, t.TempDir()),
+	} {
+		t.Run(name, func(t *testing.T) {
+			testLogBackend(t, b)
+		})
+	}
+}
+
+func testLogBackend(t *testing.T, b LogBackend) {
+	ctx := context.Background()
+	const treeID, n = 7, 11
+	for i := int64(0); i < n; i++ {
+		leaf, err := b.AppendLeaf(ctx, treeID, []byte(fmt.Sprintf("leaf %d", i)), []byte("extra"))
+		if err != nil {
+			t.Fatalf("adding leaf %d: %v", i, err)
+		}
+		if leaf.LeafIndex != i {
+			t.Fatalf("leaf %d was added at index %d", i, leaf.LeafIndex)
+		}
+	}
+	leaf, err := b.AppendLeaf(ctx, treeID, []byte("leaf 4"), nil)
+	if status.Code(err) != codes.AlreadyExists || leaf.GetLeafIndex() != 4 {
+		t.Fatalf("expected duplicate leaf 4 to be returned, got %v %v", leaf, err)
+	}
+
+	head, err := b.TreeHead(ctx, treeID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if head.TreeSize != n {
+		t.Fatalf("tree size %d, want %d", head.TreeSize, n)
+	}
+	if empty, err := b.TreeHead(ctx, treeID+1); err != nil || empty.TreeSize != 0 {
+		t.Fatalf("expected other trees to be empty, got %v %v", empty, err)
+	}
+
+	v := logverifier.New(rfc6962.DefaultHasher)
+	for i := int64(0); i < n; i++ {
+		leaf, err := b.GetLeaf(ctx, treeID, i)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(leaf.LeafValue) != fmt.Sprintf("leaf %d", i) || string(leaf.ExtraData) != "extra" || leaf.IntegrateTimestamp == nil {
+			t.Fatalf("unexpected leaf %d: %v", i, leaf)
+		}
+		index, err := b.FindLeaf(ctx, treeID, leaf.MerkleLeafHash, n)
+		if err != nil || index != i {
+			t.Fatalf("leaf %d found at %d: %v", i, index, err)
+		}
+		proof, err := b.InclusionProof(ctx, treeID, i, n)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := v.VerifyInclusionProof(i, n, proof, head.RootHash, leaf.MerkleLeafHash); err != nil {
+			t.Fatalf("inclusion proof of leaf %d: %v", i, err)
+		}
+	}
+
+	if _, err := b.GetLeaf(ctx, treeID, n); status.Code(err) != codes.OutOfRange {
+		t.Errorf("expected leaf beyond the tree to be out of range, got %v", err)
+	}
+	leaves, err := b.GetLeaves(ctx, treeID, 8, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(leaves) != 3 || leaves[0].LeafIndex != 8 || leaves[2].LeafIndex != 10 {
+		t.Errorf("unexpected leaves from 8: %v", leaves)
+	}
+	if _, err := b.GetLeaves(ctx, treeID, n, 1); status.Code(err) != codes.OutOfRange {
+		t.Errorf("expected leaves beyond the tree to be out of range, got %v", err)
+	}
+	if _, err := b.FindLeaf(ctx, treeID, rfc6962.DefaultHasher.HashLeaf([]byte("missing")), n); status.Code(err) != codes.NotFound {
+		t.Errorf("expected missing leaf not to be found, got %v", err)
+	}
+	// leaves beyond the tree size requested are not found
+	last := rfc6962.DefaultHasher.HashLeaf([]byte(fmt.Sprintf("leaf %d", n-1)))
+	if _, err := b.FindLeaf(ctx, treeID, last, n-1); status.Code(err) != codes.NotFound {
+		t.Errorf("expected leaf beyond the tree size not to be found, got %v", err)
+	}
+
+	for first := int64(1); first < n; first++ {
+		proof, err := b.ConsistencyProof(ctx, treeID, first, n)
+		if err != nil {
+			t.Fatal(err)
+		}
+		firstProof, err := b.InclusionProof(ctx, treeID, 0, first)
+		if err != nil {
+			t.Fatal(err)
+		}
+		leaf, _ := b.GetLeaf(ctx, treeID, 0)
+		firstRoot, err := v.RootFromInclusionProof(0, first, firstProof, leaf.MerkleLeafHash)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := v.VerifyConsistencyProof(first, n, firstRoot, head.RootHash, proof); err != nil {
+			t.Fatalf("consistency proof from %d: %v", first, err)
+		}
+	}
+}
+
+func TestEmbeddedBackendReopen(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	b, err := openEmbeddedBackend(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 5; i++ {
+		if _, err := b.AppendLeaf(ctx, embeddedTreeID, []byte(fmt.Sprintf("leaf %d", i)), nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	head, _ := b.TreeHead(ctx, embeddedTreeID)
+	if err := b.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// a leaf whose write was interrupted is dropped
+	path := filepath.Join(dir, fmt.Sprintf("%d%s", embeddedTreeID, embeddedTreeSuffix))
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.WriteString(`{"index":5,"val`); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	b = openTestBackend(t, dir)
+	reopened, err := b.TreeHead(ctx, embeddedTreeID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reopened.TreeSize != head.TreeSize || !bytes.Equal(reopened.RootHash, head.RootHash) || reopened.TimestampNanos != head.TimestampNanos {
+		t.Fatalf("reopened tree %v differs from %v", reopened, head)
+	}
+	if _, err := b.AppendLeaf(ctx, embeddedTreeID, []byte("leaf 2"), nil); status.Code(err) != codes.AlreadyExists {
+		t.Fatalf("expected duplicate of a reopened leaf to be rejected, got %v", err)
+	}
+	if leaf, err := b.AppendLeaf(ctx, embeddedTreeID, []byte("leaf 5"), nil); err != nil || leaf.LeafIndex != 5 {
+		t.Fatalf("unexpected leaf %v: %v", leaf, err)
+	}
+	b.Close()
+
+	b = openTestBackend(t, dir)
+	if head, _ := b.TreeHead(ctx, embeddedTreeID); head.TreeSize != 6 {
+		t.Fatalf("tree size %d after reopening, want 6", head.TreeSize)
+	}
+	// such as rekor-server import while the server is running
+	if _, err := openEmbeddedBackend(dir); err == nil || !strings.Contains(err.Error(), "in use by another process") {
+		t.Fatalf("expected a directory in use to be rejected, got %v", err)
+	}
+	b.Close()
+
+	if err := ioutil.WriteFile(path, []byte(`{"index":3}`+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := openEmbeddedBackend(dir); err == nil {
+		t.Fatal("expected leaves out of order to be rejected")
+	}
+}
+
+func TestBackendLogClient(t *testing.T) {
+	for name, b := range map[string]LogBackend{
+		"trillian": newTrillianBackend(newMemoryLogClient()),
+		"embedded": openTestBackend(t, t.TempDir()),
+	} {
+		t.Run(name, func(t *testing.T) {
+			testBackendLogClient(t, b)
+		})
+	}
+}
+
+func testBackendLogClient(t *testing.T, b LogBackend) {
+	tc := TrillianClient{client: logClientFor(b), logID: embeddedTreeID, context: context.Background()}
+
+	for i := 0; i < 6; i++ {
+		resp := tc.addLeaf([]byte(fmt.Sprintf("leaf %d", i)), nil)
+		if resp.err != nil {
+			t.Fatalf("adding leaf %d: %v", i, resp.err)
+		}
+		if got := resp.getAddResult.QueuedLeaf.Leaf.LeafIndex; got != int64(i) {
+			t.Fatalf("leaf %d was added at index %d", i, got)
+		}
+	}
+	resp := tc.addLeaf([]byte("leaf 3"), nil)
+	if resp.status != codes.OK || resp.getAddResult.QueuedLeaf.Status.GetCode() != int32(code.Code_ALREADY_EXISTS) {
+		t.Fatalf("expected duplicate leaf to be rejected, got %v %v", resp.err, resp.getAddResult.QueuedLeaf.Status)
+	}
+
+	// inclusion proofs are checked against the root by the trillian client
+	for i := int64(0); i < 6; i++ {
+		if resp := tc.getLeafAndProofByIndex(i); resp.err != nil || resp.getLeafAndProofResult.Leaf.LeafIndex != i {
+			t.Fatalf("getting leaf %d: %v", i, resp.err)
+		}
+	}
+	if resp := tc.getLeafAndProofByHash(rfc6962.DefaultHasher.HashLeaf([]byte("leaf 4"))); resp.err != nil || resp.getLeafAndProofResult.Leaf.LeafIndex != 4 {
+		t.Fatalf("unexpected result getting leaf by hash: %v", resp.err)
+	}
+	if resp := tc.getLeavesByRange(2, 10); resp.err != nil || len(resp.getLeavesByRangeResult.Leaves) != 4 {
+		t.Fatalf("unexpected leaves from 2: %v", resp.err)
+	}
+	if resp := tc.getConsistencyProof(2, 6); resp.err != nil || resp.getConsistencyProofResult.Proof == nil {
+		t.Fatalf("unexpected consistency proof: %v", resp.err)
+	}
+	if resp := tc.getConsistencyProof(2, 7); resp.err != nil || resp.getConsistencyProofResult.Proof != nil {
+		t.Fatalf("expected no proof for a tree larger than the log, got %v", resp.err)
+	}
+
+	// requests rekor does not make fail rather than being sent to a missing client
+	if _, err := tc.client.InitLog(tc.context, &trillian.InitLogRequest{LogId: embeddedTreeID}); status.Code(err) != codes.Unimplemented {
+		t.Errorf("expected InitLog to be unimplemented, got %v", err)
+	}
+	if _, err := tc.client.AddSequencedLeaves(tc.context, &trillian.AddSequencedLeavesRequest{LogId: embeddedTreeID}); status.Code(err) != codes.Unimplemented {
+		t.Errorf("expected AddSequencedLeaves to be unimplemented, got %v", err)
+	}
+}
@@ -0,0 +1,68 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"bytes"
+	"io"
+	"sync"
+)
+
+// maxPooledBufferSize is the capacity above which buffers are left to the garbage collector rather than
+// returned to the pool, so that a few large requests do not keep their memory alive
+const maxPooledBufferSize = 1 << 20
+
+// buffers holds the buffers request bodies and signed payloads are read and encoded into
+var buffers = sync.Pool{New: func() interface{} {
+	return new(bytes.Buffer)
+}}
+
+// getBuffer returns an empty buffer from the pool; it must be returned with putBuffer once nothing
+// refers to its contents
+func getBuffer() *bytes.Buffer {
+	return buffers.Get().(*bytes.Buffer)
+}
+
+func putBuffer(b *bytes.Buffer) {
+	if b.Cap() > maxPooledBufferSize {
+		return
+	}
+	b.Reset()
+	buffers.Put(b)
+}
+
+// readBuffer reads r into a buffer from the pool, growing it once to sizeHint if that is known, e.g.
+// from the Content-Length of a request. The buffer is returned even if reading fails, and must be
+// returned with putBuffer.
+func readBuffer(r io.Reader, sizeHint int64) (*bytes.Buffer, error) {
+	b := getBuffer()
+	if sizeHint > 0 && sizeHint <= maxPooledBufferSize {
+		// ReadFrom grows buffers with less than MinRead bytes free, including to read the end of the body
+		b.Grow(int(sizeHint) + bytes.MinRead)
+	}
+	_, err := b.ReadFrom(r)
+	return b, err
+}
+
+// countingWriter counts the bytes written to it, e.g. to size an encoding without keeping it
+type countingWriter struct {
+	n int
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	c.n += len(p)
+	return len(p), nil
+}
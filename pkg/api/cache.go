@@ -0,0 +1,77 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/go-openapi/runtime"
+	"github.com/go-openapi/runtime/middleware"
+	"github.com/go-openapi/swag"
+
+	"github.com/sigstore/rekor/pkg/generated/models"
+)
+
+const (
+	// for responses which never change, e.g. a consistency proof between two fixed tree sizes
+	cacheImmutable = "s-maxage=31536000, max-age=31536000, immutable"
+	// for responses which may be stored but have to be revalidated, e.g. an entry whose inclusion proof changes as the log grows
+	cacheRevalidate = "no-cache"
+)
+
+// withCacheHeaders sets the Cache-Control and ETag headers of a successful response, responding
+// with 304 Not Modified instead if the client already holds a representation with the same ETag
+func withCacheHeaders(r *http.Request, etag, cacheControl string, responder middleware.Responder) middleware.Responder {
+	return middleware.ResponderFunc(func(w http.ResponseWriter, p runtime.Producer) {
+		w.Header().Set("Cache-Control", cacheControl)
+		w.Header().Set("ETag", etag)
+		if etagMatches(r.Header.Get("If-None-Match"), etag) {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		responder.WriteResponse(w, p)
+	})
+}
+
+// etagMatches performs the weak comparison of an If-None-Match header against an ETag
+func etagMatches(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// entryETag identifies an entry along with the size of the tree its inclusion proof was computed
+// for. The ETag is weak since the signed entry timestamp may differ between equivalent responses.
+func entryETag(entry models.LogEntry) string {
+	for uuid, e := range entry {
+		var treeSize int64
+		if e.Verification != nil && e.Verification.InclusionProof != nil {
+			treeSize = swag.Int64Value(e.Verification.InclusionProof.TreeSize)
+		}
+		return fmt.Sprintf(`W/"%s-%d"`, uuid, treeSize)
+	}
+	return ""
+}
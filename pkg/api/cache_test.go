@@ -0,0 +1,90 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-openapi/runtime"
+	"github.com/go-openapi/swag"
+
+	"github.com/sigstore/rekor/pkg/generated/models"
+	"github.com/sigstore/rekor/pkg/generated/restapi/operations/entries"
+)
+
+func TestEtagMatches(t *testing.T) {
+	for _, tt := range []struct {
+		ifNoneMatch string
+		etag        string
+		want        bool
+	}{
+		{ifNoneMatch: "", etag: `"a"`, want: false},
+		{ifNoneMatch: `"a"`, etag: `"a"`, want: true},
+		{ifNoneMatch: `"b"`, etag: `"a"`, want: false},
+		{ifNoneMatch: `"b", W/"a"`, etag: `"a"`, want: true},
+		{ifNoneMatch: `"a"`, etag: `W/"a"`, want: true},
+		{ifNoneMatch: "*", etag: `"a"`, want: true},
+	} {
+		if got := etagMatches(tt.ifNoneMatch, tt.etag); got != tt.want {
+			t.Errorf("etagMatches(%q, %q) = %v, want %v", tt.ifNoneMatch, tt.etag, got, tt.want)
+		}
+	}
+}
+
+func TestEntryETag(t *testing.T) {
+	entry := models.LogEntry{
+		"abc": models.LogEntryAnon{
+			Verification: &models.LogEntryAnonVerification{
+				InclusionProof: &models.InclusionProof{TreeSize: swag.Int64(42)},
+			},
+		},
+	}
+	if got := entryETag(entry); got != `W/"abc-42"` {
+		t.Errorf("entryETag() = %s", got)
+	}
+}
+
+func TestWithCacheHeaders(t *testing.T) {
+	entry := models.LogEntry{"abc": models.LogEntryAnon{LogIndex: swag.Int64(1)}}
+	respond := func(ifNoneMatch string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/api/v1/log/entries/abc", nil)
+		if ifNoneMatch != "" {
+			r.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		rec := httptest.NewRecorder()
+		withCacheHeaders(r, entryETag(entry), cacheRevalidate, entries.NewGetLogEntryByUUIDOK().WithPayload(entry)).WriteResponse(rec, runtime.JSONProducer())
+		return rec
+	}
+
+	rec := respond("")
+	if rec.Code != http.StatusOK || rec.Body.Len() == 0 {
+		t.Fatalf("unexpected response %d %q", rec.Code, rec.Body.String())
+	}
+	etag := rec.Header().Get("ETag")
+	if etag != `W/"abc-0"` || rec.Header().Get("Cache-Control") != cacheRevalidate {
+		t.Errorf("unexpected headers %v", rec.Header())
+	}
+
+	rec = respond(etag)
+	if rec.Code != http.StatusNotModified || rec.Body.Len() != 0 {
+		t.Errorf("expected empty 304 response, got %d %q", rec.Code, rec.Body.String())
+	}
+	if rec.Header().Get("ETag") != etag {
+		t.Error("expected ETag on 304 response")
+	}
+}
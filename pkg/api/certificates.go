@@ -0,0 +1,149 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"crypto/x509"
+	"io/ioutil"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/sigstore/rekor/pkg/pki"
+	x509r "github.com/sigstore/rekor/pkg/pki/x509"
+	"github.com/sigstore/rekor/pkg/types"
+)
+
+// chainPolicy is applied to certificates of entries at admission time; nil if no trusted roots are configured
+var chainPolicy *x509r.ChainPolicy
+
+// x509Config holds the x509.* settings
+type x509Config struct {
+	Roots            string   `mapstructure:"roots"`
+	Intermediates    string   `mapstructure:"intermediates"`
+	EKUs             []string `mapstructure:"ekus"`
+	CRLs             []string `mapstructure:"crls"`
+	RevocationPolicy string   `mapstructure:"revocation_policy"`
+}
+
+func (c x509Config) validate() error {
+	switch x509r.RevocationPolicy(c.RevocationPolicy) {
+	case x509r.RevocationNone, x509r.RevocationSoft, x509r.RevocationHard:
+	default:
+		return errors.Errorf("unknown revocation policy %q", c.RevocationPolicy)
+	}
+	_, err := x509r.ParseExtKeyUsages(c.EKUs)
+	return err
+}
+
+func readCertificates(path string) ([]*x509.Certificate, error) {
+	pemBytes, err := ioutil.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, err
+	}
+	return x509r.ParseCertificates(pemBytes)
+}
+
+// chainPolicyFromConfig builds the certificate chain policy from the x509.* settings. Certificates
+// configured for keyless signing (at fulcioRootsPath) are trusted as well, so Fulcio-issued
+// certificates are accepted without having to repeat the Fulcio roots.
+func chainPolicyFromConfig(c x509Config, fulcioRootsPath string) (*x509r.ChainPolicy, error) {
+	if c.Roots == "" {
+		return nil, nil
+	}
+	if err := c.validate(); err != nil {
+		return nil, err
+	}
+
+	roots, err := readCertificates(c.Roots)
+	if err != nil {
+		return nil, errors.Wrap(err, "reading trusted roots")
+	}
+	var intermediates []*x509.Certificate
+	if c.Intermediates != "" {
+		if intermediates, err = readCertificates(c.Intermediates); err != nil {
+			return nil, errors.Wrap(err, "reading trusted intermediates")
+		}
+	}
+	if fulcioRootsPath != "" {
+		fulcio, err := readCertificates(fulcioRootsPath)
+		if err != nil {
+			return nil, errors.Wrap(err, "reading fulcio roots")
+		}
+		fulcioRoots, fulcioIntermediates := x509r.SplitCertificates(fulcio)
+		roots = append(roots, fulcioRoots...)
+		intermediates = append(intermediates, fulcioIntermediates...)
+	}
+
+	policy := &x509r.ChainPolicy{
+		Roots:         x509.NewCertPool(),
+		Intermediates: intermediates,
+		Revocation:    x509r.RevocationPolicy(c.RevocationPolicy),
+	}
+	for _, c := range roots {
+		policy.Roots.AddCert(c)
+	}
+	if policy.KeyUsages, err = x509r.ParseExtKeyUsages(c.EKUs); err != nil {
+		return nil, err
+	}
+
+	for _, path := range c.CRLs {
+		crlBytes, err := ioutil.ReadFile(filepath.Clean(path))
+		if err != nil {
+			return nil, errors.Wrap(err, "reading CRL")
+		}
+		crls, err := x509r.ParseCRLs(crlBytes)
+		if err != nil {
+			return nil, errors.Wrapf(err, "parsing CRL %s", path)
+		}
+		policy.CRLs = append(policy.CRLs, crls...)
+	}
+	return policy, nil
+}
+
+// verifyCertificateChains checks the certificates which signed the canonicalized entry against the
+// configured chain policy; keys which are not backed by certificates are not affected
+func verifyCertificateChains(entry types.EntryImpl, admissionTime time.Time) error {
+	policyMu.RLock()
+	policy := chainPolicy
+	policyMu.RUnlock()
+	if policy == nil {
+		return nil
+	}
+	sp, ok := entry.(types.SignerProvider)
+	if !ok {
+		return nil
+	}
+	signers, err := sp.Signers()
+	if err != nil {
+		return err
+	}
+	for _, s := range signers {
+		cp, ok := s.(pki.CertificateProvider)
+		if !ok {
+			continue
+		}
+		certs := cp.Certificates()
+		if len(certs) == 0 {
+			continue
+		}
+		if err := policy.Verify(certs, admissionTime); err != nil {
+			return err
+		}
+	}
+	return nil
+}
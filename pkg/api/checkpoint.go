@@ -0,0 +1,35 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"net/http"
+
+	"github.com/go-openapi/runtime/middleware"
+
+	"github.com/sigstore/rekor/pkg/generated/restapi/operations/tlog"
+)
+
+// GetLogCheckpointHandler returns the latest signed tree head of the log as a signed note, the text
+// format used by checkpoints of other transparency logs and by the Go checksum database, so that
+// witnesses and other tooling for checkpoints can consume it without parsing the JSON of /api/v1/log
+func GetLogCheckpointHandler(params tlog.GetLogCheckpointParams) middleware.Responder {
+	_, sth, message, err := signedTreeHead(params.HTTPRequest.Context())
+	if err != nil {
+		return handleRekorAPIError(params, http.StatusInternalServerError, err, message)
+	}
+	return tlog.NewGetLogCheckpointOK().WithPayload(sth.SignedNote.String())
+}
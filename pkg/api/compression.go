@@ -0,0 +1,179 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/sigstore/rekor/pkg/compression"
+	"github.com/sigstore/rekor/pkg/log"
+)
+
+// compressionConfig holds the compression.* settings
+type compressionConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// limit on the size of compressed request bodies once decompressed, in bytes
+	MaxRequestSize int64 `mapstructure:"max_request_size"`
+}
+
+func (c compressionConfig) validate() error {
+	if c.Enabled && c.MaxRequestSize <= 0 {
+		return errors.New("max_request_size must be positive")
+	}
+	return nil
+}
+
+// defaultCompressionConfig is used if the server has not been configured, e.g. in tests
+var defaultCompressionConfig = compressionConfig{
+	Enabled:        true,
+	MaxRequestSize: 32 << 20,
+}
+
+// Compress decompresses request bodies sent with a gzip or zstd Content-Encoding, rejecting those
+// which exceed the configured size once decompressed, and compresses responses for clients which
+// accept either coding
+func Compress(handler http.Handler) http.Handler {
+	c := defaultCompressionConfig
+	if startupConfig != nil {
+		c = startupConfig.Compression
+	}
+	if !c.Enabled {
+		return handler
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if encoding := r.Header.Get("Content-Encoding"); encoding != "" && !strings.EqualFold(encoding, "identity") {
+			buf, err := decompressRequest(r, encoding, c.MaxRequestSize)
+			defer putBuffer(buf)
+			switch {
+			case errors.Is(err, compression.ErrUnsupported):
+				// RFC 7694: advertise the codings which are supported
+				w.Header().Set("Accept-Encoding", compression.Supported)
+				writeErrorResponse(w, http.StatusUnsupportedMediaType, err.Error())
+				return
+			case errors.Is(err, compression.ErrTooLarge):
+				writeErrorResponse(w, http.StatusRequestEntityTooLarge, fmt.Sprintf("request body exceeds %d bytes once decompressed", c.MaxRequestSize))
+				return
+			case err != nil:
+				log.RequestIDLogger(r).Infof("invalid %s request body: %v", encoding, err)
+				writeErrorResponse(w, http.StatusBadRequest, fmt.Sprintf("request body is not valid %s data", encoding))
+				return
+			}
+			body := buf.Bytes()
+			r.Body = ioutil.NopCloser(bytes.NewReader(body))
+			r.ContentLength = int64(len(body))
+			r.Header.Set("Content-Length", strconv.Itoa(len(body)))
+			r.Header.Del("Content-Encoding")
+		}
+
+		w.Header().Add("Vary", "Accept-Encoding")
+		encoding := compression.Negotiate(r.Header.Get("Accept-Encoding"))
+		if encoding == "" || r.Method == http.MethodHead {
+			handler.ServeHTTP(w, r)
+			return
+		}
+		cw := &compressedResponseWriter{ResponseWriter: w, encoding: encoding}
+		defer func() {
+			if err := cw.Close(); err != nil {
+				log.RequestIDLogger(r).Errorf("error completing %s response: %v", encoding, err)
+			}
+		}()
+		handler.ServeHTTP(cw, r)
+	})
+}
+
+// decompressRequest reads the whole body of a compressed request so that its size is known before
+// it is handled. The buffer holding the body is returned even on errors, and must be returned with
+// putBuffer once the request has been handled.
+func decompressRequest(r *http.Request, encoding string, limit int64) (*bytes.Buffer, error) {
+	defer r.Body.Close()
+	body, err := compression.NewReader(encoding, r.Body, limit)
+	if err != nil {
+		return getBuffer(), err
+	}
+	defer body.Close()
+	return readBuffer(body, 0)
+}
+
+// compressedResponseWriter compresses the body of a response unless it has none or it is already encoded
+type compressedResponseWriter struct {
+	http.ResponseWriter
+	encoding    string
+	w           compression.Writer
+	wroteHeader bool
+}
+
+func (cw *compressedResponseWriter) WriteHeader(code int) {
+	if cw.wroteHeader || code < http.StatusOK {
+		cw.ResponseWriter.WriteHeader(code)
+		return
+	}
+	cw.wroteHeader = true
+
+	h := cw.Header()
+	if code != http.StatusNoContent && code != http.StatusNotModified && h.Get("Content-Encoding") == "" {
+		w, err := compression.NewWriter(cw.encoding, cw.ResponseWriter)
+		if err == nil {
+			cw.w = w
+			h.Set("Content-Encoding", cw.encoding)
+			h.Del("Content-Length")
+			// the compressed representation is no longer byte-for-byte identical
+			if etag := h.Get("ETag"); strings.HasPrefix(etag, `"`) {
+				h.Set("ETag", "W/"+etag)
+			}
+		}
+	}
+	cw.ResponseWriter.WriteHeader(code)
+}
+
+func (cw *compressedResponseWriter) Write(p []byte) (int, error) {
+	if !cw.wroteHeader {
+		// sniff the content type of the uncompressed data, as net/http would otherwise do on the compressed data
+		if cw.Header().Get("Content-Type") == "" {
+			cw.Header().Set("Content-Type", http.DetectContentType(p))
+		}
+		cw.WriteHeader(http.StatusOK)
+	}
+	if cw.w == nil {
+		return cw.ResponseWriter.Write(p)
+	}
+	return cw.w.Write(p)
+}
+
+// Flush sends any data compressed so far to the client, e.g. for streamed responses
+func (cw *compressedResponseWriter) Flush() {
+	if cw.w != nil {
+		if err := cw.w.Flush(); err != nil {
+			return
+		}
+	}
+	if f, ok := cw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Close completes the compressed stream
+func (cw *compressedResponseWriter) Close() error {
+	if cw.w == nil {
+		return nil
+	}
+	return cw.w.Close()
+}
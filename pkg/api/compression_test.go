@@ -0,0 +1,128 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/sigstore/rekor/pkg/compression"
+)
+
+func TestCompress(t *testing.T) {
+	oldConfig := startupConfig
+	t.Cleanup(func() {
+		startupConfig = oldConfig
+	})
+	startupConfig = &Config{Compression: compressionConfig{Enabled: true, MaxRequestSize: 1024}}
+
+	payload := strings.Repeat(`{"kind":"hashedrekord"}`, 20)
+	var gotBody string
+	handler := Compress(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		gotBody = string(b)
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("ETag", `"abc"`)
+		if r.URL.Path == "/empty" {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		_, _ = w.Write([]byte(payload))
+	}))
+	compressed := func(encoding, s string) *bytes.Buffer {
+		buf := &bytes.Buffer{}
+		w, err := compression.NewWriter(encoding, buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, _ = w.Write([]byte(s))
+		_ = w.Close()
+		return buf
+	}
+
+	for _, encoding := range []string{compression.Gzip, compression.Zstd} {
+		t.Run(encoding, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/api/v1/log/entries", compressed(encoding, payload))
+			r.Header.Set("Content-Encoding", encoding)
+			r.Header.Set("Accept-Encoding", encoding)
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, r)
+
+			if gotBody != payload {
+				t.Errorf("handler received %q", gotBody)
+			}
+			if rec.Header().Get("Content-Encoding") != encoding {
+				t.Fatalf("expected %s response, got headers %v", encoding, rec.Header())
+			}
+			if rec.Header().Get("ETag") != `W/"abc"` {
+				t.Errorf("expected weakened ETag, got %q", rec.Header().Get("ETag"))
+			}
+			body, err := compression.NewReader(encoding, rec.Body, 1<<20)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if b, _ := ioutil.ReadAll(body); string(b) != payload {
+				t.Errorf("unexpected response body %q", b)
+			}
+		})
+	}
+
+	t.Run("uncompressed", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/log", nil))
+		if rec.Header().Get("Content-Encoding") != "" || rec.Body.String() != payload {
+			t.Errorf("expected uncompressed response, got headers %v", rec.Header())
+		}
+		if rec.Header().Get("Vary") != "Accept-Encoding" {
+			t.Errorf("expected Vary header, got %v", rec.Header())
+		}
+	})
+
+	t.Run("not modified", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/empty", nil)
+		r.Header.Set("Accept-Encoding", "gzip")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, r)
+		if rec.Code != http.StatusNotModified || rec.Header().Get("Content-Encoding") != "" || rec.Body.Len() != 0 {
+			t.Errorf("unexpected response %d %v", rec.Code, rec.Header())
+		}
+	})
+
+	for _, tt := range []struct {
+		name     string
+		encoding string
+		body     *bytes.Buffer
+		want     int
+	}{
+		{"too large", compression.Gzip, compressed(compression.Gzip, strings.Repeat("a", 1025)), http.StatusRequestEntityTooLarge},
+		{"unsupported", "br", bytes.NewBufferString("x"), http.StatusUnsupportedMediaType},
+		{"invalid", compression.Gzip, bytes.NewBufferString("not gzip"), http.StatusBadRequest},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/api/v1/log/entries", tt.body)
+			r.Header.Set("Content-Encoding", tt.encoding)
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, r)
+			if rec.Code != tt.want {
+				t.Errorf("expected %d, got %d", tt.want, rec.Code)
+			}
+		})
+	}
+}
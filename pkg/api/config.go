@@ -0,0 +1,366 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"context"
+	"encoding/base64"
+	"net"
+	"net/url"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/viper"
+
+	"github.com/sigstore/rekor/pkg/admission"
+	"github.com/sigstore/rekor/pkg/log"
+	"github.com/sigstore/rekor/pkg/pki/pgp"
+	pki "github.com/sigstore/rekor/pkg/pki/x509"
+)
+
+// Config is the configuration of the server, read from flags, environment variables and the config file
+type Config struct {
+	Server            serverConfig          `mapstructure:"rekor_server"`
+	Port              uint16                `mapstructure:"port"`
+	Trillian          trillianConnConfig    `mapstructure:"trillian_log_server"`
+	EnableRetrieveAPI bool                  `mapstructure:"enable_retrieve_api"`
+	Redis             redisConfig           `mapstructure:"redis_server"`
+	Keyless           keylessConfig         `mapstructure:"keyless"`
+	X509              x509Config            `mapstructure:"x509"`
+	PGP               pgpConfig             `mapstructure:"pgp"`
+	Auth              authConfig            `mapstructure:"auth"`
+	Policy            admissionPolicyConfig `mapstructure:"policy"`
+	LogsConfig        string                `mapstructure:"logs_config"`
+	CORS              corsConfig            `mapstructure:"cors"`
+	EnableWebUI       bool                  `mapstructure:"enable_web_ui"`
+	Compression       compressionConfig     `mapstructure:"compression"`
+	Annotations       annotationsConfig     `mapstructure:"annotations"`
+	Audit             auditConfig           `mapstructure:"audit"`
+	Shards            shardsConfig          `mapstructure:"shards"`
+	Pagination        paginationConfig      `mapstructure:"pagination"`
+	Backend           backendConfig         `mapstructure:"backend"`
+	Faults            faultsConfig          `mapstructure:"faults"`
+	// notifications of new entries
+	EnableWebhooks    bool              `mapstructure:"enable_webhooks"`
+	Webhook           webhookConfig     `mapstructure:"webhook"`
+	EnableEventStream bool              `mapstructure:"enable_event_stream"`
+	EventStream       eventStreamConfig `mapstructure:"event_stream"`
+	Attestation       attestationConfig `mapstructure:",squash"`
+	// keep the log and search index in memory rather than in Trillian and Redis
+	Dev bool `mapstructure:"dev"`
+}
+
+// serverConfig holds the rekor_server.* settings
+type serverConfig struct {
+	Hostname       string `mapstructure:"hostname"`
+	Address        string `mapstructure:"address"`
+	Signer         string `mapstructure:"signer"`
+	TimestampChain string `mapstructure:"timestamp_chain"`
+	// path to a YAML file listing the keys the log signed with before Signer
+	KeySet string `mapstructure:"key_set"`
+	// addresses to serve on instead of address and port, as host:port, tcp://host:port or unix:///path
+	Listen     []string `mapstructure:"listen"`
+	SocketMode string   `mapstructure:"socket_mode"`
+}
+
+// Listener is a network address the server accepts connections on
+type Listener struct {
+	Network string // tcp or unix
+	Address string // host:port or the path of the socket
+}
+
+func (l Listener) String() string {
+	return l.Network + "://" + l.Address
+}
+
+// parseListener parses an address from rekor_server.listen
+func parseListener(s string) (Listener, error) {
+	raw := s
+	if !strings.Contains(s, "://") {
+		raw = "tcp://" + s
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return Listener{}, err
+	}
+	switch u.Scheme {
+	case "tcp":
+		if _, _, err := net.SplitHostPort(u.Host); err != nil || u.Path != "" {
+			return Listener{}, errors.Errorf("invalid address %q, expected host:port", s)
+		}
+		return Listener{Network: "tcp", Address: u.Host}, nil
+	case "unix":
+		if u.Host != "" || u.Path == "" {
+			return Listener{}, errors.Errorf("invalid address %q, expected unix:///path/to/socket", s)
+		}
+		return Listener{Network: "unix", Address: u.Path}, nil
+	default:
+		return Listener{}, errors.Errorf("unsupported network %q in %q", u.Scheme, s)
+	}
+}
+
+// Listeners returns the addresses the server accepts connections on: those in rekor_server.listen
+// if set, otherwise rekor_server.address and port
+func (c *Config) Listeners() ([]Listener, error) {
+	if len(c.Server.Listen) == 0 {
+		return []Listener{{Network: "tcp", Address: net.JoinHostPort(c.Server.Address, strconv.Itoa(int(c.Port)))}}, nil
+	}
+	var listeners []Listener
+	seen := map[Listener]bool{}
+	for _, s := range c.Server.Listen {
+		l, err := parseListener(s)
+		if err != nil {
+			return nil, err
+		}
+		if seen[l] {
+			return nil, errors.Errorf("%s is listed more than once", l)
+		}
+		seen[l] = true
+		listeners = append(listeners, l)
+	}
+	return listeners, nil
+}
+
+// UnixSocketMode returns the permissions applied to unix sockets the server listens on
+func (c *Config) UnixSocketMode() (os.FileMode, error) {
+	mode, err := strconv.ParseUint(c.Server.SocketMode, 8, 32)
+	if err != nil || mode > 0777 {
+		return 0, errors.Errorf("invalid socket mode %q, expected octal permissions such as 0660", c.Server.SocketMode)
+	}
+	return os.FileMode(mode), nil
+}
+
+// redisConfig holds the redis_server.* settings
+type redisConfig struct {
+	Address string `mapstructure:"address"`
+	Port    uint16 `mapstructure:"port"`
+}
+
+// pgpConfig holds the pgp.* settings
+type pgpConfig struct {
+	AllowExpiredKeys bool `mapstructure:"allow_expired_keys"`
+	AllowRetiredKeys bool `mapstructure:"allow_retired_keys"`
+}
+
+// keyPolicy returns the policy PGP signing keys are checked against
+func (c pgpConfig) keyPolicy() pgp.KeyPolicy {
+	return pgp.KeyPolicy{AllowExpired: c.AllowExpiredKeys, AllowRetired: c.AllowRetiredKeys}
+}
+
+// LoadConfig decodes the server configuration from v, which combines flags, environment variables
+// and the config file (YAML, TOML or JSON), and validates it
+func LoadConfig(v *viper.Viper) (*Config, error) {
+	cfg := &Config{}
+	if err := v.Unmarshal(cfg); err != nil {
+		return nil, errors.Wrap(err, "decoding configuration")
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// Validate checks the configuration for invalid or inconsistent settings, reporting all of them at once
+func (c *Config) Validate() error {
+	var problems []string
+	check := func(section string, err error) {
+		if err != nil {
+			problems = append(problems, section+": "+err.Error())
+		}
+	}
+
+	if c.Server.Hostname == "" {
+		problems = append(problems, "rekor_server.hostname must be set")
+	}
+	listeners, err := c.Listeners()
+	if err != nil {
+		problems = append(problems, "rekor_server.listen: "+err.Error())
+	}
+	for _, l := range listeners {
+		// the socket mode only applies to unix sockets
+		if l.Network != "unix" {
+			continue
+		}
+		if _, err := c.UnixSocketMode(); err != nil {
+			problems = append(problems, "rekor_server.socket_mode: "+err.Error())
+		}
+		break
+	}
+	if c.Server.Signer == "" {
+		problems = append(problems, "rekor_server.signer must be set")
+	}
+	if c.Server.TimestampChain != "" {
+		if _, err := base64.StdEncoding.DecodeString(c.Server.TimestampChain); err != nil {
+			problems = append(problems, "rekor_server.timestamp_chain must be base64 encoded")
+		}
+	}
+	check("trillian_log_server", c.Trillian.validate())
+	if c.Trillian.TreeID < 0 {
+		problems = append(problems, "trillian_log_server.tlog_id must not be negative")
+	}
+	if c.EnableRetrieveAPI && !c.Dev && (c.Redis.Address == "" || c.Redis.Port == 0) {
+		problems = append(problems, "redis_server.address and redis_server.port must be set when enable_retrieve_api is set")
+	}
+	check("x509", c.X509.validate())
+	check("auth", c.Auth.validate())
+	check("policy", c.Policy.validate())
+	check("cors", c.CORS.validate())
+	check("compression", c.Compression.validate())
+	check("annotations", c.Annotations.validate())
+	check("audit", c.Audit.validate())
+	check("shards", c.Shards.validate())
+	check("pagination", c.Pagination.validate())
+	check("backend", c.Backend.validate())
+	check("faults", c.Faults.validate())
+	if c.EnableWebhooks {
+		check("webhook", c.Webhook.validate())
+	}
+	if c.EnableEventStream {
+		check("event_stream", c.EventStream.validate())
+	}
+	check("attestation_storage", c.Attestation.validate())
+
+	if len(problems) > 0 {
+		return errors.Errorf("invalid configuration: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// restartRequired lists the sections of the configuration which differ from old but can not be
+// changed while the server is running
+func (c *Config) restartRequired(old *Config) []string {
+	var sections []string
+	if !reflect.DeepEqual(c.Server, old.Server) || c.Port != old.Port {
+		sections = append(sections, "rekor_server")
+	}
+	if c.Trillian != old.Trillian || c.Dev != old.Dev {
+		sections = append(sections, "trillian_log_server")
+	}
+	if c.Backend != old.Backend {
+		sections = append(sections, "backend")
+	}
+	if c.EnableRetrieveAPI != old.EnableRetrieveAPI || c.Redis != old.Redis {
+		sections = append(sections, "redis_server")
+	}
+	if c.LogsConfig != old.LogsConfig {
+		sections = append(sections, "logs_config")
+	}
+	if !reflect.DeepEqual(c.CORS, old.CORS) {
+		sections = append(sections, "cors")
+	}
+	if c.EnableWebUI != old.EnableWebUI {
+		sections = append(sections, "enable_web_ui")
+	}
+	if c.Compression != old.Compression {
+		sections = append(sections, "compression")
+	}
+	if !reflect.DeepEqual(c.Annotations, old.Annotations) {
+		sections = append(sections, "annotations")
+	}
+	if c.Audit != old.Audit {
+		sections = append(sections, "audit")
+	}
+	if !reflect.DeepEqual(c.Shards, old.Shards) {
+		sections = append(sections, "shards")
+	}
+	if c.Pagination != old.Pagination {
+		sections = append(sections, "pagination")
+	}
+	if !reflect.DeepEqual(c.Faults, old.Faults) {
+		sections = append(sections, "faults")
+	}
+	if c.EnableWebhooks != old.EnableWebhooks || !reflect.DeepEqual(c.Webhook, old.Webhook) {
+		sections = append(sections, "webhook")
+	}
+	if c.EnableEventStream != old.EnableEventStream || !reflect.DeepEqual(c.EventStream, old.EventStream) {
+		sections = append(sections, "event_stream")
+	}
+	if c.Attestation != old.Attestation {
+		sections = append(sections, "attestation_storage")
+	}
+	return sections
+}
+
+var (
+	// policyMu guards the settings which are replaced by Reload
+	policyMu sync.RWMutex
+	// startupConfig is the configuration the server was started with
+	startupConfig *Config
+)
+
+// currentAdmissionPolicy returns the admission policy of the log, which may be replaced by Reload
+func (a *API) currentAdmissionPolicy() *admission.Policy {
+	policyMu.RLock()
+	defer policyMu.RUnlock()
+	return a.admissionPolicy
+}
+
+// Reload applies the settings which can change while the server is running: trusted roots, keyless
+// roots, the PGP key policy, admission policies, API tokens and write rate limits. They are all built
+// before any of them is replaced, so nothing is changed if any of them is invalid, and are replaced
+// together while policyMu is held. Changes to other settings are logged and only take effect after a
+// restart.
+func Reload(ctx context.Context, cfg *Config) error {
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	fulcioRoots, err := cfg.Keyless.fulcioRoots()
+	if err != nil {
+		return err
+	}
+	chain, err := chainPolicyFromConfig(cfg.X509, cfg.Keyless.FulcioRoots)
+	if err != nil {
+		return err
+	}
+	policy, err := cfg.Policy.policy()
+	if err != nil {
+		return errors.Wrap(err, "policy")
+	}
+	logPolicies, err := logPoliciesFromConfig(cfg.LogsConfig)
+	if err != nil {
+		return err
+	}
+	authenticator, limiter, err := writeAuthFromConfig(ctx, cfg.Auth)
+	if err != nil {
+		return err
+	}
+
+	if startupConfig != nil {
+		for _, section := range cfg.restartRequired(startupConfig) {
+			log.Logger.Warnf("changes to %s require a restart to take effect", section)
+		}
+	}
+
+	keyPolicy := cfg.PGP.keyPolicy()
+
+	policyMu.Lock()
+	defer policyMu.Unlock()
+	pki.SetFulcioRoots(fulcioRoots)
+	pgp.SetKeyPolicy(keyPolicy)
+	chainPolicy = chain
+	api.admissionPolicy = policy
+	for name, l := range logs {
+		if p, ok := logPolicies[name]; ok {
+			l.admissionPolicy = p
+		}
+	}
+	writeAuthenticator, writeLimiter = authenticator, keepLimiter(limiter)
+	return nil
+}
@@ -0,0 +1,298 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+
+	_ "github.com/sigstore/rekor/pkg/types/rekord/v0.0.1"
+)
+
+func validConfig() *Config {
+	return &Config{
+		Server: serverConfig{
+			Hostname:   "rekor.example.com",
+			Address:    "127.0.0.1",
+			Signer:     "memory",
+			SocketMode: "0660",
+		},
+		Port: 3000,
+		Trillian: trillianConnConfig{
+			Address: "127.0.0.1",
+			Port:    8090,
+		},
+		X509: x509Config{
+			EKUs:             []string{"codesigning"},
+			RevocationPolicy: "soft",
+		},
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	v := viper.New()
+	v.Set("rekor_server.hostname", "rekor.example.com")
+	v.Set("rekor_server.signer", "memory")
+	v.Set("port", "3000")
+	v.Set("trillian_log_server.address", "trillian")
+	v.Set("trillian_log_server.port", 8090)
+	v.Set("trillian_log_server.tlog_id", 42)
+	v.Set("trillian_log_server.rpc_timeout", "5s")
+	v.Set("x509.revocation_policy", "hard")
+	v.Set("auth.rate_limit", 10)
+	v.Set("auth.identity_rate_limits", map[string]string{"ci": "100"})
+	v.Set("policy.allowed_types", []string{"rekord", "hashedrekord"})
+	v.Set("enable_webhooks", true)
+	v.Set("webhook.url", []string{"https://hooks.example.com/rekor"})
+	v.Set("webhook.timeout", "3s")
+	v.Set("webhook.queue_size", 100)
+	v.Set("enable_attestation_storage", true)
+	v.Set("attestation_storage_bucket", "mem://")
+
+	cfg, err := LoadConfig(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Trillian.Address != "trillian" || cfg.Trillian.TreeID != 42 || cfg.Trillian.RPCTimeout != 5*time.Second {
+		t.Errorf("unexpected trillian config %+v", cfg.Trillian)
+	}
+	if cfg.Port != 3000 || cfg.X509.RevocationPolicy != "hard" {
+		t.Errorf("unexpected config %+v", cfg)
+	}
+	if cfg.Auth.RateLimit != 10 || cfg.Auth.IdentityRateLimits["ci"] != "100" {
+		t.Errorf("unexpected auth config %+v", cfg.Auth)
+	}
+	if len(cfg.Policy.AllowedTypes) != 2 {
+		t.Errorf("unexpected policy config %+v", cfg.Policy)
+	}
+	if !cfg.EnableWebhooks || len(cfg.Webhook.URLs) != 1 || cfg.Webhook.Timeout != 3*time.Second {
+		t.Errorf("unexpected webhook config %+v", cfg.Webhook)
+	}
+	if !cfg.Attestation.Enabled || cfg.Attestation.Bucket != "mem://" {
+		t.Errorf("unexpected attestation config %+v", cfg.Attestation)
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	if err := validConfig().Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cfg := validConfig()
+	cfg.Trillian.LoadBalancingPolicy = "random"
+	cfg.X509.RevocationPolicy = "sometimes"
+	cfg.Auth.OIDC.Issuer = "https://oauth2.example.com"
+	cfg.Auth.IdentityRateLimits = map[string]string{"ci": "lots"}
+	cfg.Policy.AllowedTypes = []string{"bogus"}
+	cfg.EnableRetrieveAPI = true
+	cfg.Annotations.IndexedKeys = []string{"build id"}
+	cfg.Audit.Syslog = "syslog.example.com:514"
+	cfg.Shards.Archives = []string{"gs://rekor-shards"}
+	cfg.Pagination.MaxPageSize = -1
+	cfg.Backend.Type = backendEmbedded
+	cfg.Faults.Rules = []string{"QueueLeaf=crash"}
+	cfg.EnableWebhooks = true
+	cfg.EnableEventStream = true
+	cfg.Attestation.Enabled = true
+	err := cfg.Validate()
+	if err == nil {
+		t.Fatal("expected validation error")
+	}
+	// all problems are reported at once
+	for _, section := range []string{"trillian_log_server", "x509", "auth", "policy", "redis_server", "annotations", "audit", "shards", "pagination", "backend", "faults", "webhook", "event_stream", "attestation_storage"} {
+		if !strings.Contains(err.Error(), section) {
+			t.Errorf("expected %s to be reported in %v", section, err)
+		}
+	}
+}
+
+func TestConfigNotifications(t *testing.T) {
+	valid := func() *Config {
+		cfg := validConfig()
+		cfg.EnableWebhooks = true
+		cfg.Webhook = webhookConfig{URLs: []string{"https://hooks.example.com/rekor"}, Timeout: time.Second, QueueSize: 10}
+		cfg.EnableEventStream = true
+		cfg.EventStream = eventStreamConfig{URLs: []string{"mem://entries"}, Timeout: time.Second}
+		return cfg
+	}
+	if err := valid().Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		modify func(*Config)
+	}{
+		{"webhook without url", func(c *Config) { c.Webhook.URLs = nil }},
+		{"webhook url without scheme", func(c *Config) { c.Webhook.URLs = []string{"hooks.example.com/rekor"} }},
+		{"webhook url not http", func(c *Config) { c.Webhook.URLs = []string{"ftp://hooks.example.com/rekor"} }},
+		{"webhook zero timeout", func(c *Config) { c.Webhook.Timeout = 0 }},
+		{"webhook negative backoff", func(c *Config) { c.Webhook.RetryBackoff = -time.Second }},
+		{"webhook zero queue", func(c *Config) { c.Webhook.QueueSize = 0 }},
+		{"event stream without url", func(c *Config) { c.EventStream.URLs = nil }},
+		{"event stream url without scheme", func(c *Config) { c.EventStream.URLs = []string{"entries"} }},
+		{"event stream bad source", func(c *Config) { c.EventStream.Source = "%zz" }},
+		{"event stream zero timeout", func(c *Config) { c.EventStream.Timeout = 0 }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := valid()
+			tt.modify(cfg)
+			if err := cfg.Validate(); err == nil {
+				t.Error("expected validation error")
+			}
+		})
+	}
+
+	// settings of disabled notifications are not checked
+	cfg := validConfig()
+	cfg.Webhook.URLs = []string{"not a url"}
+	cfg.EventStream.Timeout = 0
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestConfigAttestation(t *testing.T) {
+	for _, tt := range []struct {
+		cfg   attestationConfig
+		valid bool
+	}{
+		{attestationConfig{}, true},
+		{attestationConfig{Bucket: "bogus"}, true},
+		{attestationConfig{Enabled: true, Bucket: "gs://rekor-attestations"}, true},
+		{attestationConfig{Enabled: true}, false},
+		{attestationConfig{Enabled: true, Bucket: "rekor-attestations"}, false},
+	} {
+		if err := tt.cfg.validate(); (err == nil) != tt.valid {
+			t.Errorf("%+v: unexpected validation result %v", tt.cfg, err)
+		}
+	}
+}
+
+func TestConfigRestartRequired(t *testing.T) {
+	old := validConfig()
+	cfg := validConfig()
+	cfg.Policy.MinRSAKeyBits = 2048
+	cfg.Auth.RateLimit = 5
+	if sections := cfg.restartRequired(old); len(sections) != 0 {
+		t.Errorf("expected no restart for reloadable settings, got %v", sections)
+	}
+
+	cfg.Trillian.TreeID = 1
+	cfg.Port = 3001
+	sections := cfg.restartRequired(old)
+	if len(sections) != 2 || sections[0] != "rekor_server" || sections[1] != "trillian_log_server" {
+		t.Errorf("unexpected sections %v", sections)
+	}
+
+	cfg = validConfig()
+	cfg.Webhook.Timeout = time.Second
+	cfg.EnableEventStream = true
+	cfg.Attestation.Bucket = "mem://"
+	sections = cfg.restartRequired(old)
+	if len(sections) != 3 || sections[0] != "webhook" || sections[1] != "event_stream" || sections[2] != "attestation_storage" {
+		t.Errorf("unexpected sections %v", sections)
+	}
+}
+
+func TestReload(t *testing.T) {
+	oldAPI, oldStartup := api, startupConfig
+	api, startupConfig = &API{}, nil
+	t.Cleanup(func() {
+		api, startupConfig = oldAPI, oldStartup
+		chainPolicy, writeAuthenticator, writeLimiter = nil, nil, nil
+	})
+
+	cfg := validConfig()
+	cfg.Policy.MinRSAKeyBits = 2048
+	if err := Reload(context.Background(), cfg); err != nil {
+		t.Fatal(err)
+	}
+	policy := api.currentAdmissionPolicy()
+	if policy == nil || policy.MinRSAKeyBits != 2048 {
+		t.Fatalf("policy not applied: %+v", policy)
+	}
+
+	// nothing is replaced if part of the new configuration can not be loaded
+	cfg = validConfig()
+	cfg.Policy.BlockedKeysFile = "/does/not/exist"
+	if err := Reload(context.Background(), cfg); err == nil {
+		t.Fatal("expected error for missing blocked keys file")
+	}
+	if api.currentAdmissionPolicy() != policy {
+		t.Error("policy replaced by failed reload")
+	}
+}
+
+func TestConfigListeners(t *testing.T) {
+	cfg := validConfig()
+	listeners, err := cfg.Listeners()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(listeners) != 1 || listeners[0] != (Listener{Network: "tcp", Address: "127.0.0.1:3000"}) {
+		t.Errorf("unexpected default listeners %v", listeners)
+	}
+
+	cfg.Server.Listen = []string{"127.0.0.1:3001", "tcp://[::1]:3002", "unix:///var/run/rekor/rekor.sock"}
+	listeners, err = cfg.Listeners()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Listener{
+		{Network: "tcp", Address: "127.0.0.1:3001"},
+		{Network: "tcp", Address: "[::1]:3002"},
+		{Network: "unix", Address: "/var/run/rekor/rekor.sock"},
+	}
+	if len(listeners) != len(want) {
+		t.Fatalf("got listeners %v, want %v", listeners, want)
+	}
+	for i := range want {
+		if listeners[i] != want[i] {
+			t.Errorf("listener %d = %v, want %v", i, listeners[i], want[i])
+		}
+	}
+
+	for _, invalid := range [][]string{
+		{"localhost"},
+		{"udp://localhost:3000"},
+		{"unix://relative.sock"},
+		{"localhost:3000", "tcp://localhost:3000"},
+	} {
+		cfg.Server.Listen = invalid
+		if _, err := cfg.Listeners(); err == nil {
+			t.Errorf("expected error for %v", invalid)
+		}
+		if err := cfg.Validate(); err == nil {
+			t.Errorf("expected validation error for %v", invalid)
+		}
+	}
+
+	cfg = validConfig()
+	cfg.Server.SocketMode = "0999"
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("socket mode must not matter without unix sockets: %v", err)
+	}
+	cfg.Server.Listen = []string{"unix:///run/rekor.sock"}
+	if err := cfg.Validate(); err == nil {
+		t.Error("expected validation error for invalid socket mode")
+	}
+}
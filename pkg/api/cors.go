@@ -0,0 +1,70 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rs/cors"
+)
+
+// corsConfig holds the cors.* settings
+type corsConfig struct {
+	AllowedOrigins   []string      `mapstructure:"allowed_origins"`
+	AllowedHeaders   []string      `mapstructure:"allowed_headers"`
+	ExposedHeaders   []string      `mapstructure:"exposed_headers"`
+	MaxAge           time.Duration `mapstructure:"max_age"`
+	AllowCredentials bool          `mapstructure:"allow_credentials"`
+}
+
+func (c corsConfig) validate() error {
+	if c.AllowCredentials && containsString(c.AllowedOrigins, "*") {
+		return errors.New("credentials can not be allowed for all origins")
+	}
+	if c.MaxAge < 0 {
+		return errors.New("max_age must not be negative")
+	}
+	return nil
+}
+
+// defaultCORSConfig is used if the server has not been configured, e.g. in tests
+var defaultCORSConfig = corsConfig{
+	AllowedOrigins: []string{"*"},
+	AllowedHeaders: []string{"Accept", "Authorization", "Content-Type", "If-None-Match", "X-Requested-With"},
+	ExposedHeaders: []string{"ETag", "Location", "Retry-After", "X-Rekor-Next-Page-Token"},
+}
+
+// CORS allows browser-based clients served from the configured origins to make requests to the
+// API; cross-origin requests are not allowed if no origins are configured
+func CORS(handler http.Handler) http.Handler {
+	c := defaultCORSConfig
+	if startupConfig != nil {
+		c = startupConfig.CORS
+	}
+	if len(c.AllowedOrigins) == 0 {
+		return handler
+	}
+	return cors.New(cors.Options{
+		AllowedOrigins:   c.AllowedOrigins,
+		AllowedMethods:   []string{http.MethodGet, http.MethodHead, http.MethodPost},
+		AllowedHeaders:   c.AllowedHeaders,
+		ExposedHeaders:   c.ExposedHeaders,
+		MaxAge:           int(c.MaxAge.Seconds()),
+		AllowCredentials: c.AllowCredentials,
+	}).Handler(handler)
+}
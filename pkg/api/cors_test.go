@@ -0,0 +1,78 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCORS(t *testing.T) {
+	oldConfig := startupConfig
+	t.Cleanup(func() {
+		startupConfig = oldConfig
+	})
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"abc"`)
+		w.WriteHeader(http.StatusOK)
+	})
+	request := func(handler http.Handler, method, origin string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, "/api/v1/log/entries", nil)
+		r.Header.Set("Origin", origin)
+		if method == http.MethodOptions {
+			r.Header.Set("Access-Control-Request-Method", http.MethodPost)
+			r.Header.Set("Access-Control-Request-Headers", "Authorization")
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, r)
+		return rec
+	}
+
+	startupConfig = &Config{CORS: corsConfig{
+		AllowedOrigins: []string{"https://verifier.example.com"},
+		AllowedHeaders: []string{"Authorization"},
+		ExposedHeaders: []string{"ETag"},
+	}}
+	handler := CORS(next)
+
+	rec := request(handler, http.MethodGet, "https://verifier.example.com")
+	if rec.Header().Get("Access-Control-Allow-Origin") != "https://verifier.example.com" {
+		t.Errorf("expected allowed origin, got headers %v", rec.Header())
+	}
+	if rec.Header().Get("Access-Control-Expose-Headers") != "Etag" {
+		t.Errorf("expected ETag to be exposed, got %q", rec.Header().Get("Access-Control-Expose-Headers"))
+	}
+	rec = request(handler, http.MethodOptions, "https://verifier.example.com")
+	if rec.Header().Get("Access-Control-Allow-Headers") != "Authorization" {
+		t.Errorf("expected Authorization to be allowed in preflight, got headers %v", rec.Header())
+	}
+	rec = request(handler, http.MethodGet, "https://evil.example.com")
+	if rec.Header().Get("Access-Control-Allow-Origin") != "" {
+		t.Errorf("unexpected allowed origin %q", rec.Header().Get("Access-Control-Allow-Origin"))
+	}
+
+	// without origins cross-origin requests are not allowed
+	startupConfig = &Config{}
+	rec = request(CORS(next), http.MethodGet, "https://verifier.example.com")
+	if rec.Header().Get("Access-Control-Allow-Origin") != "" {
+		t.Errorf("unexpected allowed origin %q", rec.Header().Get("Access-Control-Allow-Origin"))
+	}
+
+	if err := (corsConfig{AllowedOrigins: []string{"*"}, AllowCredentials: true}).validate(); err == nil {
+		t.Error("expected error allowing credentials for all origins")
+	}
+}
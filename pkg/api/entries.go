@@ -19,19 +19,22 @@ import (
 	"bytes"
 	"context"
 	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
+	"path"
+	"time"
 
 	"github.com/cyberphone/json-canonicalization/go/src/webpki.org/jsoncanonicalizer"
 	"github.com/go-openapi/runtime/middleware"
 	"github.com/go-openapi/strfmt"
 	"github.com/go-openapi/swag"
 	"github.com/google/trillian"
+	"github.com/google/trillian/merkle/logverifier"
 	"github.com/google/trillian/merkle/rfc6962"
 	ttypes "github.com/google/trillian/types"
-	"github.com/spf13/viper"
 	"golang.org/x/sync/errgroup"
 	"google.golang.org/genproto/googleapis/rpc/code"
 	"google.golang.org/grpc/codes"
@@ -40,16 +43,19 @@ import (
 	"github.com/sigstore/rekor/pkg/generated/restapi/operations/entries"
 	"github.com/sigstore/rekor/pkg/log"
 	"github.com/sigstore/rekor/pkg/types"
+	"github.com/sigstore/rekor/pkg/util"
 	"github.com/sigstore/sigstore/pkg/signature"
 	"github.com/sigstore/sigstore/pkg/signature/options"
 )
 
 func signEntry(ctx context.Context, signer signature.Signer, entry models.LogEntryAnon) ([]byte, error) {
-	payload, err := entry.MarshalBinary()
-	if err != nil {
+	// the entry is only encoded to be canonicalized, so it is encoded into a reused buffer
+	payload := getBuffer()
+	defer putBuffer(payload)
+	if err := json.NewEncoder(payload).Encode(&entry); err != nil {
 		return nil, fmt.Errorf("marshalling error: %v", err)
 	}
-	canonicalized, err := jsoncanonicalizer.Transform(payload)
+	canonicalized, err := jsoncanonicalizer.Transform(payload.Bytes())
 	if err != nil {
 		return nil, fmt.Errorf("canonicalizing error: %v", err)
 	}
@@ -68,13 +74,21 @@ func logEntryFromLeaf(ctx context.Context, signer signature.Signer, tc TrillianC
 	if err := root.UnmarshalBinary(signedLogRoot.LogRoot); err != nil {
 		return nil, err
 	}
-	hashes := []string{}
-	for _, hash := range proof.Hashes {
-		hashes = append(hashes, hex.EncodeToString(hash))
+
+	uuid, logEntryAnon, err := signedEntryFromLeaf(ctx, signer, leaf)
+	if err != nil {
+		return nil, err
 	}
+	logEntryAnon.Verification.InclusionProof = inclusionProofFromTrillian(root, proof)
 
+	return models.LogEntry{
+		uuid: logEntryAnon}, nil
+}
+
+// signedEntryFromLeaf creates a log entry with its signed entry timestamp, but without an inclusion proof
+func signedEntryFromLeaf(ctx context.Context, signer signature.Signer, leaf *trillian.LogLeaf) (string, models.LogEntryAnon, error) {
 	logEntryAnon := models.LogEntryAnon{
-		LogID:          swag.String(api.pubkeyHash),
+		LogID:          swag.String(logAPI(ctx).pubkeyHash),
 		LogIndex:       &leaf.LeafIndex,
 		Body:           leaf.LeafValue,
 		IntegratedTime: swag.Int64(leaf.IntegrateTimestamp.AsTime().Unix()),
@@ -82,18 +96,11 @@ func logEntryFromLeaf(ctx context.Context, signer signature.Signer, tc TrillianC
 
 	signature, err := signEntry(ctx, signer, logEntryAnon)
 	if err != nil {
-		return nil, fmt.Errorf("signing entry error: %w", err)
-	}
-
-	inclusionProof := models.InclusionProof{
-		TreeSize: swag.Int64(int64(root.TreeSize)),
-		RootHash: swag.String(hex.EncodeToString(root.RootHash)),
-		LogIndex: swag.Int64(proof.GetLeafIndex()),
-		Hashes:   hashes,
+		return "", models.LogEntryAnon{}, fmt.Errorf("signing entry error: %w", err)
 	}
 
-	uuid := hex.EncodeToString(leaf.MerkleLeafHash)
-	if viper.GetBool("enable_attestation_storage") {
+	uuid := util.EntryUUIDFromLeafHash(leaf.MerkleLeafHash)
+	if storageClient != nil {
 		att, err := storageClient.FetchAttestation(ctx, uuid)
 		if err != nil {
 			log.Logger.Errorf("error fetching attestation: %s %s", uuid, err)
@@ -105,12 +112,23 @@ func logEntryFromLeaf(ctx context.Context, signer signature.Signer, tc TrillianC
 	}
 
 	logEntryAnon.Verification = &models.LogEntryAnonVerification{
-		InclusionProof:       &inclusionProof,
 		SignedEntryTimestamp: strfmt.Base64(signature),
 	}
+	return uuid, logEntryAnon, nil
+}
 
-	return models.LogEntry{
-		uuid: logEntryAnon}, nil
+// inclusionProofFromTrillian converts an inclusion proof for the tree with the given root
+func inclusionProofFromTrillian(root *ttypes.LogRootV1, proof *trillian.Proof) *models.InclusionProof {
+	hashes := []string{}
+	for _, hash := range proof.Hashes {
+		hashes = append(hashes, hex.EncodeToString(hash))
+	}
+	return &models.InclusionProof{
+		TreeSize: swag.Int64(int64(root.TreeSize)),
+		RootHash: swag.String(hex.EncodeToString(root.RootHash)),
+		LogIndex: swag.Int64(proof.GetLeafIndex()),
+		Hashes:   hashes,
+	}
 }
 
 // GetLogEntryAndProofByIndexHandler returns the entry and inclusion proof for a specified log index
@@ -118,7 +136,7 @@ func GetLogEntryByIndexHandler(params entries.GetLogEntryByIndexParams) middlewa
 	ctx := params.HTTPRequest.Context()
 	tc := NewTrillianClient(ctx)
 
-	resp := tc.getLeafAndProofByIndex(params.LogIndex)
+	resp := tc.getEntryByIndex(params.LogIndex)
 	switch resp.status {
 	case codes.OK:
 	case codes.NotFound, codes.OutOfRange, codes.InvalidArgument:
@@ -133,32 +151,74 @@ func GetLogEntryByIndexHandler(params entries.GetLogEntryByIndexParams) middlewa
 		return handleRekorAPIError(params, http.StatusNotFound, errors.New("grpc returned 0 leaves with success code"), "")
 	}
 
-	logEntry, err := logEntryFromLeaf(ctx, api.signer, tc, leaf, result.SignedLogRoot, result.Proof)
+	logEntry, err := logEntryFromLeaf(ctx, logAPI(ctx).signer, tc, leaf, result.SignedLogRoot, result.Proof)
 	if err != nil {
 		return handleRekorAPIError(params, http.StatusInternalServerError, err, err.Error())
 	}
 
-	return entries.NewGetLogEntryByIndexOK().WithPayload(logEntry)
+	return withCacheHeaders(params.HTTPRequest, entryETag(logEntry), cacheRevalidate, entries.NewGetLogEntryByIndexOK().WithPayload(logEntry))
 }
 
-func createLogEntry(params entries.CreateLogEntryParams) (models.LogEntry, middleware.Responder) {
+// admitEntry canonicalizes a proposed entry and runs every check required before it may be added to the log,
+// returning the entry and its canonicalized leaf
+func admitEntry(params entries.CreateLogEntryParams) (types.EntryImpl, []byte, middleware.Responder) {
 	ctx := params.HTTPRequest.Context()
 	entry, err := types.NewEntry(params.ProposedEntry)
 	if err != nil {
-		return nil, handleRekorAPIError(params, http.StatusBadRequest, err, fmt.Sprintf(validationError, err))
+		return nil, nil, handleRekorAPIError(params, http.StatusBadRequest, err, fmt.Sprintf(validationError, err))
 	}
-	leaf, err := types.CanonicalizeEntry(ctx, entry)
+	leaf, err := types.CanonicalizeEntry(ctx, entry, params.ProposedEntry.Annotations())
 	if err != nil {
 		if _, ok := (err).(types.ValidationError); ok {
-			return nil, handleRekorAPIError(params, http.StatusBadRequest, err, fmt.Sprintf(validationError, err))
+			return nil, nil, handleRekorAPIError(params, http.StatusBadRequest, err, fmt.Sprintf(validationError, err))
 		}
-		return nil, handleRekorAPIError(params, http.StatusInternalServerError, err, failedToGenerateCanonicalEntry)
+		return nil, nil, handleRekorAPIError(params, http.StatusInternalServerError, err, failedToGenerateCanonicalEntry)
+	}
+	admissionTime := time.Now()
+	if err := verifyKeyless(entry, admissionTime); err != nil {
+		return nil, nil, handleRekorAPIError(params, http.StatusBadRequest, err, fmt.Sprintf(validationError, err))
+	}
+	if err := verifyCertificateChains(entry, admissionTime); err != nil {
+		return nil, nil, handleRekorAPIError(params, http.StatusBadRequest, err, fmt.Sprintf(validationError, err))
+	}
+	violations, err := checkAdmission(logAPI(ctx).currentAdmissionPolicy(), params.ProposedEntry, entry)
+	if err != nil {
+		return nil, nil, handleRekorAPIError(params, http.StatusBadRequest, err, fmt.Sprintf(validationError, err))
+	}
+	if len(violations) > 0 {
+		return nil, nil, handleAdmissionError(params, violations)
+	}
+	return entry, leaf, nil
+}
+
+func createLogEntry(params entries.CreateLogEntryParams) (models.LogEntry, middleware.Responder) {
+	ctx := params.HTTPRequest.Context()
+	a := logAPI(ctx)
+	entry, leaf, errResp := admitEntry(params)
+	if errResp != nil {
+		return nil, errResp
 	}
 
 	tc := NewTrillianClient(ctx)
+	leafHash := rfc6962.DefaultHasher.HashLeaf(leaf)
+	uuid := util.EntryUUIDFromLeafHash(leafHash)
 
-	resp := tc.addLeaf(leaf)
+	// the active shard only rejects entries which are already in it
+	inFrozenShard, err := tc.inFrozenShard(leafHash)
+	if err != nil {
+		return nil, handleRekorAPIError(params, http.StatusInternalServerError, fmt.Errorf("grpc error: %w", err), trillianUnexpectedResult)
+	}
+	if inFrozenShard {
+		err := errors.New("entry is already included in a frozen shard of the log")
+		return nil, handleRekorAPIError(params, http.StatusConflict, err, fmt.Sprintf(entryAlreadyExists, uuid), "entryURL", getEntryURL(requestURL(params.HTTPRequest), uuid))
+	}
+
+	resp := tc.addLeaf(leaf, nil)
 	// this represents overall GRPC response state (not the results of insertion into the log)
+	if resp.status == codes.FailedPrecondition {
+		// the active shard was frozen by rekor-server shard freeze, but the server not yet moved to the next one
+		return nil, handleRekorAPIError(params, http.StatusServiceUnavailable, fmt.Errorf("grpc error: %w", resp.err), activeShardFrozen)
+	}
 	if resp.status != codes.OK {
 		return nil, handleRekorAPIError(params, http.StatusInternalServerError, fmt.Errorf("grpc error: %w", resp.err), trillianUnexpectedResult)
 	}
@@ -169,9 +229,8 @@ func createLogEntry(params entries.CreateLogEntryParams) (models.LogEntry, middl
 		switch insertionStatus.Code {
 		case int32(code.Code_OK):
 		case int32(code.Code_ALREADY_EXISTS), int32(code.Code_FAILED_PRECONDITION):
-			existingUUID := hex.EncodeToString(rfc6962.DefaultHasher.HashLeaf(leaf))
 			err := fmt.Errorf("grpc error: %v", insertionStatus.String())
-			return nil, handleRekorAPIError(params, http.StatusConflict, err, fmt.Sprintf(entryAlreadyExists, existingUUID), "entryURL", getEntryURL(*params.HTTPRequest.URL, existingUUID))
+			return nil, handleRekorAPIError(params, http.StatusConflict, err, fmt.Sprintf(entryAlreadyExists, uuid), "entryURL", getEntryURL(requestURL(params.HTTPRequest), uuid))
 		default:
 			err := fmt.Errorf("grpc error: %v", insertionStatus.String())
 			return nil, handleRekorAPIError(params, http.StatusInternalServerError, err, trillianUnexpectedResult)
@@ -182,31 +241,37 @@ func createLogEntry(params entries.CreateLogEntryParams) (models.LogEntry, middl
 	metricNewEntries.Inc()
 
 	queuedLeaf := resp.getAddResult.QueuedLeaf.Leaf
-	uuid := hex.EncodeToString(queuedLeaf.GetMerkleLeafHash())
+	if !bytes.Equal(queuedLeaf.GetMerkleLeafHash(), leafHash) {
+		return nil, handleRekorAPIError(params, http.StatusInternalServerError, fmt.Errorf("leaf hash %x returned from log does not match computed UUID %v", queuedLeaf.GetMerkleLeafHash(), uuid), trillianUnexpectedResult)
+	}
 
 	logEntryAnon := models.LogEntryAnon{
-		LogID:          swag.String(api.pubkeyHash),
-		LogIndex:       swag.Int64(queuedLeaf.LeafIndex),
+		LogID:          swag.String(a.pubkeyHash),
+		LogIndex:       swag.Int64(tc.activeOffset() + queuedLeaf.LeafIndex),
 		Body:           queuedLeaf.GetLeafValue(),
 		IntegratedTime: swag.Int64(queuedLeaf.IntegrateTimestamp.AsTime().Unix()),
 	}
 
-	if viper.GetBool("enable_retrieve_api") {
+	if indexClient != nil {
 		go func() {
 			keys, err := entry.IndexKeys()
 			if err != nil {
 				log.RequestIDLogger(params.HTTPRequest).Error(err)
 				return
 			}
+			keys = append(keys, a.annotationIndexKeys(params.ProposedEntry.Annotations())...)
 			for _, key := range keys {
-				if err := addToIndex(context.Background(), key, uuid); err != nil {
+				if err := addToIndex(context.Background(), a.indexPrefix+key, uuid); err != nil {
 					log.RequestIDLogger(params.HTTPRequest).Error(err)
 				}
 			}
+			if err := addToTimeIndex(context.Background(), a.indexPrefix, uuid, swag.Int64Value(logEntryAnon.IntegratedTime)); err != nil {
+				log.RequestIDLogger(params.HTTPRequest).Error(err)
+			}
 		}()
 	}
 
-	if viper.GetBool("enable_attestation_storage") {
+	if storageClient != nil {
 
 		go func() {
 			attestation := entry.Attestation()
@@ -220,7 +285,11 @@ func createLogEntry(params entries.CreateLogEntryParams) (models.LogEntry, middl
 		}()
 	}
 
-	signature, err := signEntry(ctx, api.signer, logEntryAnon)
+	if len(eventSinks) > 0 {
+		go publishEntryCreated(entry, params.ProposedEntry.Kind(), uuid, logEntryAnon)
+	}
+
+	signature, err := signEntry(ctx, a.signer, logEntryAnon)
 	if err != nil {
 		return nil, handleRekorAPIError(params, http.StatusInternalServerError, fmt.Errorf("signing entry error: %v", err), signingError)
 	}
@@ -249,7 +318,7 @@ func CreateLogEntryHandler(params entries.CreateLogEntryParams) middleware.Respo
 		uuid = location
 	}
 
-	return entries.NewCreateLogEntryCreated().WithPayload(logEntry).WithLocation(getEntryURL(*httpReq.URL, uuid)).WithETag(uuid)
+	return entries.NewCreateLogEntryCreated().WithPayload(logEntry).WithLocation(getEntryURL(requestURL(httpReq), uuid)).WithETag(uuid)
 }
 
 // getEntryURL returns the absolute path to the log entry in a RESTful style
@@ -263,13 +332,55 @@ func getEntryURL(locationURL url.URL, uuid string) strfmt.URI {
 
 }
 
+// ValidateLogEntryHandler runs every check that creating the proposed entry would without adding it to the log,
+// returning the canonicalized entry keyed by the UUID it would be assigned
+func ValidateLogEntryHandler(params entries.ValidateLogEntryParams) middleware.Responder {
+	ctx := params.HTTPRequest.Context()
+	// errors are reported exactly as they would be when creating the entry
+	createParams := entries.CreateLogEntryParams{
+		HTTPRequest:   params.HTTPRequest,
+		ProposedEntry: params.ProposedEntry,
+	}
+	_, leaf, errResp := admitEntry(createParams)
+	if errResp != nil {
+		return errResp
+	}
+
+	leafHash := rfc6962.DefaultHasher.HashLeaf(leaf)
+	uuid := util.EntryUUIDFromLeafHash(leafHash)
+	tc := NewTrillianClient(ctx)
+	resp := tc.getEntryByHash(leafHash)
+	switch resp.status {
+	case codes.OK:
+		if resp.getLeafAndProofResult.GetLeaf() != nil {
+			entriesURL := requestURL(params.HTTPRequest)
+			entriesURL.Path = path.Dir(entriesURL.Path)
+			err := errors.New("entry is already included in the log")
+			return handleRekorAPIError(createParams, http.StatusConflict, err, fmt.Sprintf(entryAlreadyExists, uuid), "entryURL", getEntryURL(entriesURL, uuid))
+		}
+	case codes.NotFound:
+	default:
+		return handleRekorAPIError(createParams, http.StatusInternalServerError, fmt.Errorf("grpc error: %w", resp.err), trillianUnexpectedResult)
+	}
+
+	return entries.NewValidateLogEntryOK().WithPayload(models.LogEntry{
+		uuid: models.LogEntryAnon{
+			LogID: swag.String(logAPI(ctx).pubkeyHash),
+			Body:  leaf,
+		},
+	})
+}
+
 // GetLogEntryByUUIDHandler gets log entry and inclusion proof for specified UUID aka merkle leaf hash
 func GetLogEntryByUUIDHandler(params entries.GetLogEntryByUUIDParams) middleware.Responder {
 	ctx := params.HTTPRequest.Context()
-	hashValue, _ := hex.DecodeString(params.EntryUUID)
+	hashValue, err := util.LeafHashFromEntryUUID(params.EntryUUID)
+	if err != nil {
+		return handleRekorAPIError(params, http.StatusBadRequest, err, malformedUUID)
+	}
 	tc := NewTrillianClient(params.HTTPRequest.Context())
 
-	resp := tc.getLeafAndProofByHash(hashValue)
+	resp := tc.getEntryByHash(hashValue)
 	switch resp.status {
 	case codes.OK:
 	case codes.NotFound:
@@ -284,12 +395,111 @@ func GetLogEntryByUUIDHandler(params entries.GetLogEntryByUUIDParams) middleware
 		return handleRekorAPIError(params, http.StatusNotFound, errors.New("grpc returned 0 leaves with success code"), "")
 	}
 
-	logEntry, err := logEntryFromLeaf(ctx, api.signer, tc, leaf, result.SignedLogRoot, result.Proof)
+	logEntry, err := logEntryFromLeaf(ctx, logAPI(ctx).signer, tc, leaf, result.SignedLogRoot, result.Proof)
 	if err != nil {
 		return handleRekorAPIError(params, http.StatusInternalServerError, err, "")
 	}
 
-	return entries.NewGetLogEntryByUUIDOK().WithPayload(logEntry)
+	return withCacheHeaders(params.HTTPRequest, entryETag(logEntry), cacheRevalidate, entries.NewGetLogEntryByUUIDOK().WithPayload(logEntry))
+}
+
+// GetLogEntryProofHandler returns the inclusion proof of an entry in the tree of an earlier size of the shard holding
+// it, along with the consistency proof from that tree to the current tree of the shard and its signed tree head
+func GetLogEntryProofHandler(params entries.GetLogEntryProofParams) middleware.Responder {
+	ctx := params.HTTPRequest.Context()
+	hashValue, err := util.LeafHashFromEntryUUID(params.EntryUUID)
+	if err != nil {
+		return handleRekorAPIError(params, http.StatusBadRequest, err, malformedUUID)
+	}
+	tc := NewTrillianClient(ctx)
+
+	shard, resp := tc.findShard(hashValue)
+	switch resp.status {
+	case codes.OK:
+	case codes.NotFound:
+		return handleRekorAPIError(params, http.StatusNotFound, fmt.Errorf("grpc error: %w", resp.err), "")
+	default:
+		return handleRekorAPIError(params, http.StatusInternalServerError, fmt.Errorf("grpc error: %w", resp.err), trillianUnexpectedResult)
+	}
+	result := resp.getProofResult
+	if len(result.Proof) != 1 {
+		return handleRekorAPIError(params, http.StatusInternalServerError, fmt.Errorf("expected 1 proof for %v, found %v", params.EntryUUID, len(result.Proof)), trillianUnexpectedResult)
+	}
+	var root ttypes.LogRootV1
+	if err := root.UnmarshalBinary(result.SignedLogRoot.LogRoot); err != nil {
+		return handleRekorAPIError(params, http.StatusInternalServerError, err, trillianUnexpectedResult)
+	}
+
+	treeSize := params.TreeSize
+	if uint64(treeSize) > root.TreeSize {
+		return handleRekorAPIError(params, http.StatusBadRequest, nil, fmt.Sprintf(lastSizeGreaterThanKnown, treeSize, root.TreeSize))
+	}
+	index := result.Proof[0].LeafIndex
+	if index >= treeSize {
+		return handleRekorAPIError(params, http.StatusNotFound, fmt.Errorf("entry %v was integrated at index %d, after the tree had %d leaves", params.EntryUUID, index, treeSize), "")
+	}
+
+	inclusion := shard.getInclusionProofAtSize(index, treeSize)
+	if inclusion.status != codes.OK {
+		return handleRekorAPIError(params, http.StatusInternalServerError, fmt.Errorf("grpc error: %w", inclusion.err), trillianCommunicationError)
+	}
+	proof := inclusion.getInclusionProofResult.GetProof()
+	if proof == nil {
+		return handleRekorAPIError(params, http.StatusInternalServerError, errors.New("grpc returned no inclusion proof with success code"), trillianUnexpectedResult)
+	}
+	var consistency [][]byte
+	if uint64(treeSize) < root.TreeSize {
+		resp := shard.getConsistencyProof(treeSize, int64(root.TreeSize))
+		if resp.status != codes.OK {
+			return handleRekorAPIError(params, http.StatusInternalServerError, fmt.Errorf("grpc error: %w", resp.err), trillianCommunicationError)
+		}
+		consistency = resp.getConsistencyProofResult.GetProof().GetHashes()
+	}
+	oldRoot, err := verifyHistoricalProof(hashValue, index, treeSize, proof.GetHashes(), &root, consistency)
+	if err != nil {
+		return handleRekorAPIError(params, http.StatusInternalServerError, err, trillianUnexpectedResult)
+	}
+
+	sth, message, err := logAPI(ctx).signCheckpoint(ctx, &root)
+	if err != nil {
+		return handleRekorAPIError(params, http.StatusInternalServerError, err, message)
+	}
+	scBytes, err := sth.SignedNote.MarshalText()
+	if err != nil {
+		return handleRekorAPIError(params, http.StatusInternalServerError, fmt.Errorf("marshalling error: %w", err), sthGenerateError)
+	}
+
+	consistencyHashes := []string{}
+	for _, hash := range consistency {
+		consistencyHashes = append(consistencyHashes, hex.EncodeToString(hash))
+	}
+	rootHash := hex.EncodeToString(root.RootHash)
+	return entries.NewGetLogEntryProofOK().WithPayload(&models.HistoricalProof{
+		InclusionProof: inclusionProofFromTrillian(&ttypes.LogRootV1{TreeSize: uint64(treeSize), RootHash: oldRoot}, proof),
+		ConsistencyProof: &models.ConsistencyProof{
+			RootHash: &rootHash,
+			Hashes:   consistencyHashes,
+		},
+		LogInfo: &models.LogInfo{
+			RootHash:       &rootHash,
+			TreeSize:       swag.Int64(int64(root.TreeSize)),
+			SignedTreeHead: swag.String(string(scBytes)),
+		},
+	})
+}
+
+// verifyHistoricalProof computes the root of the tree of treeSize leaves from the inclusion proof of the leaf at
+// index and checks that the current tree, with the given root, is consistent with it
+func verifyHistoricalProof(leafHash []byte, index, treeSize int64, inclusion [][]byte, root *ttypes.LogRootV1, consistency [][]byte) ([]byte, error) {
+	v := logverifier.New(rfc6962.DefaultHasher)
+	oldRoot, err := v.RootFromInclusionProof(index, treeSize, inclusion, leafHash)
+	if err != nil {
+		return nil, fmt.Errorf("inclusion proof at tree size %d: %w", treeSize, err)
+	}
+	if err := v.VerifyConsistencyProof(treeSize, int64(root.TreeSize), oldRoot, root.RootHash, consistency); err != nil {
+		return nil, fmt.Errorf("consistency proof from tree size %d to %d: %w", treeSize, root.TreeSize, err)
+	}
+	return oldRoot, nil
 }
 
 // SearchLogQueryHandler searches log by index, UUID, or proposed entry and returns array of entries found with inclusion proofs
@@ -298,12 +508,31 @@ func SearchLogQueryHandler(params entries.SearchLogQueryParams) middleware.Respo
 	resultPayload := []models.LogEntry{}
 	tc := NewTrillianClient(httpReqCtx)
 
-	if len(params.Entry.EntryUUIDs) > 0 || len(params.Entry.Entries()) > 0 {
+	digest, err := queryDigest(params.Entry)
+	if err != nil {
+		return handleRekorAPIError(params, http.StatusInternalServerError, err, "")
+	}
+	token, err := decodePageToken(params.PageToken, digest)
+	if err != nil {
+		return handleRekorAPIError(params, http.StatusBadRequest, err, fmt.Sprintf(malformedPageToken, err))
+	}
+	// a page covers a window of the UUIDs, entries and log indexes of the query, in that order
+	entryUUIDs, proposedEntries, logIndexes := params.Entry.EntryUUIDs, params.Entry.Entries(), params.Entry.LogIndexes
+	nUUIDs, nEntries, nIndexes := len(entryUUIDs), len(proposedEntries), len(logIndexes)
+	first, last, next := pageRange(nUUIDs+nEntries+nIndexes, token, logAPI(httpReqCtx).pageSize(params.PageSize))
+	lo, hi := window(first, last, 0, nUUIDs)
+	entryUUIDs = entryUUIDs[lo:hi]
+	lo, hi = window(first, last, nUUIDs, nEntries)
+	proposedEntries = proposedEntries[lo:hi]
+	lo, hi = window(first, last, nUUIDs+nEntries, nIndexes)
+	logIndexes = logIndexes[lo:hi]
+
+	if len(entryUUIDs) > 0 || len(proposedEntries) > 0 {
 		g, _ := errgroup.WithContext(httpReqCtx)
 
-		searchHashes := make([][]byte, len(params.Entry.EntryUUIDs)+len(params.Entry.Entries()))
-		for i, uuid := range params.Entry.EntryUUIDs {
-			hash, err := hex.DecodeString(uuid)
+		searchHashes := make([][]byte, len(entryUUIDs)+len(proposedEntries))
+		for i, uuid := range entryUUIDs {
+			hash, err := util.LeafHashFromEntryUUID(uuid)
 			if err != nil {
 				return handleRekorAPIError(params, http.StatusBadRequest, err, malformedUUID)
 			}
@@ -311,7 +540,7 @@ func SearchLogQueryHandler(params entries.SearchLogQueryParams) middleware.Respo
 		}
 
 		code := http.StatusBadRequest
-		for i, e := range params.Entry.Entries() {
+		for i, e := range proposedEntries {
 			i, e := i, e // https://golang.org/doc/faq#closures_and_goroutines
 			g.Go(func() error {
 				entry, err := types.NewEntry(e)
@@ -319,14 +548,14 @@ func SearchLogQueryHandler(params entries.SearchLogQueryParams) middleware.Respo
 					return err
 				}
 
-				leaf, err := types.CanonicalizeEntry(httpReqCtx, entry)
+				leaf, err := types.CanonicalizeEntry(httpReqCtx, entry, e.Annotations())
 				if err != nil {
 					code = http.StatusInternalServerError
 					return err
 				}
 				hasher := rfc6962.DefaultHasher
 				leafHash := hasher.HashLeaf(leaf)
-				searchHashes[i+len(params.Entry.EntryUUIDs)] = leafHash
+				searchHashes[i+len(entryUUIDs)] = leafHash
 				return nil
 			})
 		}
@@ -340,7 +569,7 @@ func SearchLogQueryHandler(params entries.SearchLogQueryParams) middleware.Respo
 		for i, hash := range searchHashes {
 			i, hash := i, hash // https://golang.org/doc/faq#closures_and_goroutines
 			g.Go(func() error {
-				resp := tc.getLeafAndProofByHash(hash)
+				resp := tc.getEntryByHash(hash)
 				switch resp.status {
 				case codes.OK, codes.NotFound:
 				default:
@@ -360,7 +589,7 @@ func SearchLogQueryHandler(params entries.SearchLogQueryParams) middleware.Respo
 
 		for _, leafResp := range searchByHashResults {
 			if leafResp != nil {
-				logEntry, err := logEntryFromLeaf(httpReqCtx, api.signer, tc, leafResp.Leaf, leafResp.SignedLogRoot, leafResp.Proof)
+				logEntry, err := logEntryFromLeaf(httpReqCtx, logAPI(httpReqCtx).signer, tc, leafResp.Leaf, leafResp.SignedLogRoot, leafResp.Proof)
 				if err != nil {
 					return handleRekorAPIError(params, code, err, err.Error())
 				}
@@ -370,14 +599,14 @@ func SearchLogQueryHandler(params entries.SearchLogQueryParams) middleware.Respo
 		}
 	}
 
-	if len(params.Entry.LogIndexes) > 0 {
+	if len(logIndexes) > 0 {
 		g, _ := errgroup.WithContext(httpReqCtx)
 
-		leafResults := make([]*trillian.GetEntryAndProofResponse, len(params.Entry.LogIndexes))
-		for i, logIndex := range params.Entry.LogIndexes {
+		leafResults := make([]*trillian.GetEntryAndProofResponse, len(logIndexes))
+		for i, logIndex := range logIndexes {
 			i, logIndex := i, logIndex // https://golang.org/doc/faq#closures_and_goroutines
 			g.Go(func() error {
-				resp := tc.getLeafAndProofByIndex(swag.Int64Value(logIndex))
+				resp := tc.getEntryByIndex(swag.Int64Value(logIndex))
 				switch resp.status {
 				case codes.OK, codes.NotFound:
 				default:
@@ -397,7 +626,7 @@ func SearchLogQueryHandler(params entries.SearchLogQueryParams) middleware.Respo
 
 		for _, result := range leafResults {
 			if result != nil {
-				logEntry, err := logEntryFromLeaf(httpReqCtx, api.signer, tc, result.Leaf, result.SignedLogRoot, result.Proof)
+				logEntry, err := logEntryFromLeaf(httpReqCtx, logAPI(httpReqCtx).signer, tc, result.Leaf, result.SignedLogRoot, result.Proof)
 				if err != nil {
 					return handleRekorAPIError(params, http.StatusInternalServerError, err, trillianUnexpectedResult)
 				}
@@ -406,5 +635,5 @@ func SearchLogQueryHandler(params entries.SearchLogQueryParams) middleware.Respo
 		}
 	}
 
-	return entries.NewSearchLogQueryOK().WithPayload(resultPayload)
+	return entries.NewSearchLogQueryOK().WithPayload(resultPayload).WithXRekorNextPageToken(next)
 }
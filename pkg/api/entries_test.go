@@ -0,0 +1,213 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/go-openapi/runtime"
+	"github.com/go-openapi/swag"
+	"github.com/google/trillian"
+	"github.com/sigstore/sigstore/pkg/cryptoutils"
+	"github.com/sigstore/sigstore/pkg/signature"
+
+	"github.com/sigstore/rekor/pkg/generated/models"
+	"github.com/sigstore/rekor/pkg/generated/restapi/operations/entries"
+	"github.com/sigstore/rekor/pkg/signer"
+	"github.com/sigstore/rekor/pkg/types"
+	_ "github.com/sigstore/rekor/pkg/types/hashedrekord/v0.0.1"
+	"github.com/sigstore/rekor/pkg/util"
+	"github.com/sigstore/rekor/pkg/verify"
+)
+
+func TestGetLogEntryProof(t *testing.T) {
+	s, err := signer.NewMemory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pubkey, _, err := signerPublicKey(context.Background(), s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	verifier, err := verify.LoadLogVerifier([]byte(pubkey))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := newMemoryLogClient()
+	tc := shardedClient(t, m)
+	// the active shard, tree 2, grows to 4 leaves
+	for _, value := range []string{`{"tree":2,"entry":2}`, `{"tree":2,"entry":3}`} {
+		if _, err := m.QueueLeaf(context.Background(), &trillian.QueueLeafRequest{LogId: 2, Leaf: &trillian.LogLeaf{LeafValue: []byte(value)}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	oldAPI := api
+	t.Cleanup(func() {
+		api = oldAPI
+	})
+	api = &API{logClient: m, logID: 2, logRanges: tc.ranges, signer: s, checkpointOrigin: "Rekor", signerName: "rekor.example.com", pubkeyHash: "abc"}
+
+	get := func(uuid string, treeSize int64) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		params := entries.GetLogEntryProofParams{
+			HTTPRequest: httptest.NewRequest(http.MethodGet, "/api/v1/log/entries/"+uuid+"/proof", nil),
+			EntryUUID:   uuid,
+			TreeSize:    treeSize,
+		}
+		GetLogEntryProofHandler(params).WriteResponse(rec, runtime.JSONProducer())
+		return rec
+	}
+
+	for _, tt := range []struct {
+		body     string
+		treeSize int64
+		// the size of the current tree of the shard holding the entry
+		currentSize uint64
+	}{
+		{body: `{"tree":2,"entry":0}`, treeSize: 1, currentSize: 4},
+		{body: `{"tree":2,"entry":1}`, treeSize: 3, currentSize: 4},
+		{body: `{"tree":2,"entry":3}`, treeSize: 4, currentSize: 4},
+		{body: `{"tree":1,"entry":1}`, treeSize: 2, currentSize: 3},
+	} {
+		rec := get(util.EntryUUID([]byte(tt.body)), tt.treeSize)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s at tree size %d: unexpected response %d: %s", tt.body, tt.treeSize, rec.Code, rec.Body)
+		}
+		proof := &models.HistoricalProof{}
+		if err := json.Unmarshal(rec.Body.Bytes(), proof); err != nil {
+			t.Fatal(err)
+		}
+		sth, err := verify.HistoricalProof(verifier, []byte(tt.body), proof)
+		if err != nil {
+			t.Fatalf("%s at tree size %d: %v", tt.body, tt.treeSize, err)
+		}
+		if sth.Size != tt.currentSize || swag.Int64Value(proof.InclusionProof.TreeSize) != tt.treeSize {
+			t.Errorf("%s: proven at tree size %d consistent with %d, want %d and %d", tt.body, *proof.InclusionProof.TreeSize, sth.Size, tt.treeSize, tt.currentSize)
+		}
+		treeID := int64(2)
+		if strings.HasPrefix(tt.body, `{"tree":1`) {
+			treeID = 1
+		}
+		if want := hex.EncodeToString(merkleRoot(m.trees[treeID].hashes[:tt.treeSize])); *proof.InclusionProof.RootHash != want {
+			t.Errorf("%s: root hash %v of the tree of size %d, want %v", tt.body, *proof.InclusionProof.RootHash, tt.treeSize, want)
+		}
+	}
+
+	for _, tt := range []struct {
+		desc     string
+		uuid     string
+		treeSize int64
+		want     int
+	}{
+		{desc: "integrated later", uuid: util.EntryUUID([]byte(`{"tree":2,"entry":2}`)), treeSize: 2, want: http.StatusNotFound},
+		{desc: "larger than the tree", uuid: util.EntryUUID([]byte(`{"tree":2,"entry":0}`)), treeSize: 5, want: http.StatusBadRequest},
+		{desc: "missing", uuid: util.EntryUUID([]byte("missing")), treeSize: 1, want: http.StatusNotFound},
+		{desc: "malformed UUID", uuid: "abc", treeSize: 1, want: http.StatusBadRequest},
+	} {
+		if rec := get(tt.uuid, tt.treeSize); rec.Code != tt.want {
+			t.Errorf("%s: got response %d, want %d: %s", tt.desc, rec.Code, tt.want, rec.Body)
+		}
+	}
+}
+
+// hashedrekordBody returns a proposed hashedrekord entry, as sent to the server
+func hashedrekordBody(tb testing.TB) []byte {
+	tb.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	pubPEM, err := cryptoutils.MarshalPublicKeyToPEM(key.Public())
+	if err != nil {
+		tb.Fatal(err)
+	}
+	artifact := []byte("artifact")
+	digest := sha256.Sum256(artifact)
+	artifactSigner, err := signature.LoadSigner(key, crypto.SHA256)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	sig, err := artifactSigner.SignMessage(bytes.NewReader(artifact))
+	if err != nil {
+		tb.Fatal(err)
+	}
+	pe, err := types.NewProposedEntry(context.Background(), "hashedrekord", "", types.ArtifactProperties{
+		ArtifactHash:   hex.EncodeToString(digest[:]),
+		SignatureBytes: sig,
+		PublicKeyBytes: pubPEM,
+	})
+	if err != nil {
+		tb.Fatal(err)
+	}
+	body, err := json.Marshal(pe)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return body
+}
+
+// createParams decodes a proposed entry as the generated server does
+func createParams(tb testing.TB, body []byte) entries.CreateLogEntryParams {
+	tb.Helper()
+	pe, err := models.UnmarshalProposedEntry(bytes.NewReader(body), runtime.JSONConsumer())
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return entries.CreateLogEntryParams{
+		HTTPRequest:   httptest.NewRequest(http.MethodPost, "/api/v1/log/entries", nil),
+		ProposedEntry: pe,
+	}
+}
+
+func BenchmarkCreateLogEntry(b *testing.B) {
+	s, err := signer.NewMemory()
+	if err != nil {
+		b.Fatal(err)
+	}
+	oldAPI := api
+	b.Cleanup(func() {
+		api = oldAPI
+	})
+	api = &API{logClient: newMemoryLogClient(), logID: 1, signer: s, pubkeyHash: "abc"}
+	body := hashedrekordBody(b)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		// entries are made unique by an annotation
+		params := createParams(b, body)
+		params.ProposedEntry.SetAnnotations(models.Annotations{"build/id": strconv.Itoa(i)})
+		if _, errResp := createLogEntry(params); errResp != nil {
+			rec := httptest.NewRecorder()
+			errResp.WriteResponse(rec, runtime.JSONProducer())
+			b.Fatalf("unexpected response %d: %s", rec.Code, rec.Body)
+		}
+	}
+}
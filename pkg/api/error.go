@@ -16,6 +16,7 @@
 package api
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"regexp"
@@ -24,6 +25,7 @@ import (
 	"github.com/go-openapi/strfmt"
 	"github.com/mitchellh/mapstructure"
 
+	"github.com/sigstore/rekor/pkg/admission"
 	"github.com/sigstore/rekor/pkg/generated/models"
 	"github.com/sigstore/rekor/pkg/generated/restapi/operations/entries"
 	"github.com/sigstore/rekor/pkg/generated/restapi/operations/index"
@@ -42,6 +44,7 @@ const (
 	firstSizeLessThanLastSize         = "firstSize(%d) must be less than lastSize(%d)"
 	malformedUUID                     = "UUID must be a 64-character hexadecimal string"
 	malformedPublicKey                = "Public key provided could not be parsed"
+	malformedHash                     = "Hash must be a hex encoded SHA256, SHA384, SHA512 or SHA1 digest, optionally prefixed by its algorithm"
 	failedToGenerateCanonicalKey      = "Error generating canonicalized public key"
 	redisUnexpectedResult             = "Unexpected result from searching index"
 	lastSizeGreaterThanKnown          = "The tree size requested(%d) was greater than what is currently observable(%d)"
@@ -49,15 +52,66 @@ const (
 	failedToGenerateTimestampResponse = "Error generating timestamp response"
 	sthGenerateError                  = "Error generating signed tree head"
 	unsupportedPKIFormat              = "The PKI format requested is not supported by this server"
+	admissionPolicyViolation          = "The entry was rejected by the admission policy of this server"
+	annotationNotIndexed              = "Annotation %q is not indexed by this server"
+	malformedCommit                   = "Commit must be a 40 or 64 character hexadecimal git commit ID"
+	malformedRepository               = "Repository must be an absolute URL"
+	malformedArtifactURL              = "Artifact URL must be an absolute http or https URL"
+	malformedTimeRange                = "Time range must not start before 1970 or end before it starts"
+	schemaValidationError             = "Proposed entry does not match the schema of its kind and version: %v"
+	activeShardFrozen                 = "The log is not accepting entries while its active shard is frozen"
+	malformedPageToken                = "Invalid page token: %v"
 )
 
 func errorMsg(message string, code int) *models.Error {
 	return &models.Error{
-		Code:    int64(code),
-		Message: message,
+		Code:      int64(code),
+		Message:   message,
+		Title:     http.StatusText(code),
+		Status:    int64(code),
+		Detail:    message,
+		ErrorCode: errorCode(code, message),
 	}
 }
 
+// errorCode returns the machine-readable code of an error response
+func errorCode(code int, message string) models.ErrorCode {
+	switch code {
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return models.ErrorCodeUnauthorized
+	case http.StatusNotFound:
+		return models.ErrorCodeNotFound
+	case http.StatusConflict:
+		return models.ErrorCodeDuplicateEntry
+	case http.StatusTooManyRequests:
+		return models.ErrorCodeRateLimited
+	case http.StatusNotImplemented:
+		return models.ErrorCodeNotImplemented
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return models.ErrorCodeBackendUnavailable
+	case http.StatusInternalServerError:
+		switch message {
+		case trillianCommunicationError, trillianUnexpectedResult, redisUnexpectedResult:
+			return models.ErrorCodeBackendUnavailable
+		}
+		return models.ErrorCodeInternalError
+	}
+	if code >= 400 && code < 500 {
+		return models.ErrorCodeValidationFailed
+	}
+	return models.ErrorCodeInternalError
+}
+
+// writeErrorResponse writes an error in the format of the API for handlers outside of the generated server
+func writeErrorResponse(w http.ResponseWriter, code int, message string) {
+	if message == "" {
+		message = http.StatusText(code)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(errorMsg(message, code))
+}
+
 func handleRekorAPIError(params interface{}, code int, err error, message string, fields ...interface{}) middleware.Responder {
 	if message == "" {
 		message = http.StatusText(code)
@@ -92,6 +146,24 @@ func handleRekorAPIError(params interface{}, code int, err error, message string
 		default:
 			return entries.NewGetLogEntryByUUIDDefault(code).WithPayload(errorMsg(message, code))
 		}
+	case entries.GetLogEntryProofParams:
+		logMsg(params.HTTPRequest)
+		switch code {
+		case http.StatusBadRequest:
+			return entries.NewGetLogEntryProofBadRequest().WithPayload(errorMsg(message, code))
+		case http.StatusNotFound:
+			return entries.NewGetLogEntryProofNotFound()
+		default:
+			return entries.NewGetLogEntryProofDefault(code).WithPayload(errorMsg(message, code))
+		}
+	case entries.StreamLogEntriesParams:
+		logMsg(params.HTTPRequest)
+		switch code {
+		case http.StatusBadRequest:
+			return entries.NewStreamLogEntriesBadRequest().WithPayload(errorMsg(message, code))
+		default:
+			return entries.NewStreamLogEntriesDefault(code).WithPayload(errorMsg(message, code))
+		}
 	case entries.CreateLogEntryParams:
 		switch code {
 		// We treat "duplicate entry" as an error, but it's not really an error, so we don't need to log it as one.
@@ -124,6 +196,9 @@ func handleRekorAPIError(params interface{}, code int, err error, message string
 		default:
 			return entries.NewSearchLogQueryDefault(code).WithPayload(errorMsg(message, code))
 		}
+	case tlog.GetLogCheckpointParams:
+		logMsg(params.HTTPRequest)
+		return tlog.NewGetLogCheckpointDefault(code).WithPayload(errorMsg(message, code))
 	case tlog.GetLogInfoParams:
 		logMsg(params.HTTPRequest)
 		return tlog.NewGetLogInfoDefault(code).WithPayload(errorMsg(message, code))
@@ -169,3 +244,23 @@ func handleRekorAPIError(params interface{}, code int, err error, message string
 		return middleware.Error(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
 	}
 }
+
+// handleAdmissionError rejects a proposed entry which violates the admission policy, listing every violation
+func handleAdmissionError(params entries.CreateLogEntryParams, violations []admission.Violation) middleware.Responder {
+	payload := &models.AdmissionError{
+		Code:      http.StatusUnprocessableEntity,
+		Message:   admissionPolicyViolation,
+		Title:     http.StatusText(http.StatusUnprocessableEntity),
+		Status:    http.StatusUnprocessableEntity,
+		Detail:    admissionPolicyViolation,
+		ErrorCode: models.ErrorCodePolicyRejected,
+	}
+	for _, v := range violations {
+		payload.Violations = append(payload.Violations, &models.PolicyViolation{
+			Policy:  v.Policy,
+			Message: v.Message,
+		})
+	}
+	log.RequestIDLogger(params.HTTPRequest).Warnw("entry rejected by admission policy", "violations", violations)
+	return entries.NewCreateLogEntryUnprocessableEntity().WithPayload(payload)
+}
@@ -0,0 +1,192 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/go-openapi/swag"
+	"github.com/pkg/errors"
+
+	"github.com/sigstore/rekor/pkg/events"
+	"github.com/sigstore/rekor/pkg/generated/models"
+	"github.com/sigstore/rekor/pkg/log"
+	"github.com/sigstore/rekor/pkg/types"
+	"github.com/sigstore/rekor/pkg/webhook"
+)
+
+// webhookConfig holds the webhook.* settings, which apply if enable_webhooks is set
+type webhookConfig struct {
+	URLs         []string      `mapstructure:"url"`
+	SecretFile   string        `mapstructure:"secret_file"`
+	Timeout      time.Duration `mapstructure:"timeout"`
+	MaxRetries   uint          `mapstructure:"max_retries"`
+	RetryBackoff time.Duration `mapstructure:"retry_backoff"`
+	QueueSize    int           `mapstructure:"queue_size"`
+}
+
+func (c webhookConfig) validate() error {
+	if len(c.URLs) == 0 {
+		return errors.New("url must be set when enable_webhooks is set")
+	}
+	for _, s := range c.URLs {
+		u, err := url.Parse(s)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return errors.Errorf("invalid URL %q, expected an http or https URL", s)
+		}
+	}
+	if c.Timeout <= 0 {
+		return errors.New("timeout must be positive")
+	}
+	if c.RetryBackoff < 0 {
+		return errors.New("retry_backoff must not be negative")
+	}
+	if c.QueueSize <= 0 {
+		return errors.New("queue_size must be positive")
+	}
+	return nil
+}
+
+// dispatcher creates the dispatcher delivering events to the configured webhooks
+func (c webhookConfig) dispatcher() (*webhook.Dispatcher, error) {
+	var secret []byte
+	if c.SecretFile != "" {
+		b, err := ioutil.ReadFile(filepath.Clean(c.SecretFile))
+		if err != nil {
+			return nil, errors.Wrap(err, "reading webhook secret")
+		}
+		secret = bytes.TrimSpace(b)
+	}
+
+	cfg := webhook.Config{
+		Timeout:      c.Timeout,
+		MaxRetries:   c.MaxRetries,
+		RetryBackoff: c.RetryBackoff,
+		QueueSize:    c.QueueSize,
+	}
+	for _, u := range c.URLs {
+		cfg.Targets = append(cfg.Targets, webhook.Target{URL: u, Secret: secret})
+	}
+	log.Logger.Infof("Configured %d webhook target(s)", len(cfg.Targets))
+	return webhook.NewDispatcher(cfg), nil
+}
+
+// eventStreamConfig holds the event_stream.* settings, which apply if enable_event_stream is set
+type eventStreamConfig struct {
+	URLs []string `mapstructure:"url"`
+	// CloudEvents source attribute of published events; https://<rekor_server.hostname> if empty
+	Source  string        `mapstructure:"source"`
+	Timeout time.Duration `mapstructure:"timeout"`
+}
+
+func (c eventStreamConfig) validate() error {
+	if len(c.URLs) == 0 {
+		return errors.New("url must be set when enable_event_stream is set")
+	}
+	for _, s := range c.URLs {
+		if u, err := url.Parse(s); err != nil || u.Scheme == "" {
+			return errors.Errorf("invalid topic URL %q, expected a URL such as mem://topic", s)
+		}
+	}
+	if _, err := url.Parse(c.Source); err != nil {
+		return errors.Errorf("invalid source %q, expected a URI reference", c.Source)
+	}
+	if c.Timeout <= 0 {
+		return errors.New("timeout must be positive")
+	}
+	return nil
+}
+
+// newEventSinks creates the sinks (webhooks and message bus topics) that new entries are published to
+func newEventSinks(ctx context.Context, cfg *Config) ([]events.Sink, error) {
+	var sinks []events.Sink
+	if cfg.EnableWebhooks {
+		d, err := cfg.Webhook.dispatcher()
+		if err != nil {
+			return nil, err
+		}
+		sinks = append(sinks, d)
+	}
+	if cfg.EnableEventStream {
+		source := cfg.EventStream.Source
+		if source == "" {
+			source = fmt.Sprintf("https://%s", cfg.Server.Hostname)
+		}
+		for _, topic := range cfg.EventStream.URLs {
+			s, err := events.OpenTopicSink(ctx, topic, source)
+			if err != nil {
+				return nil, err
+			}
+			log.Logger.Infof("Publishing new entries to %s", topic)
+			sinks = append(sinks, s)
+		}
+	}
+	return sinks, nil
+}
+
+// publishEntryCreated emits an event describing a newly integrated entry to every configured sink
+func publishEntryCreated(entry types.EntryImpl, kind, uuid string, logEntry models.LogEntryAnon) {
+	ev := events.Entry{
+		UUID:           uuid,
+		LogIndex:       swag.Int64Value(logEntry.LogIndex),
+		LogID:          swag.StringValue(logEntry.LogID),
+		IntegratedTime: swag.Int64Value(logEntry.IntegratedTime),
+		Kind:           kind,
+		APIVersion:     entry.APIVersion(),
+	}
+	keys, err := entry.IndexKeys()
+	if err != nil {
+		log.Logger.Warnf("unable to compute index keys for event for entry %s: %v", uuid, err)
+	}
+	ev.ArtifactHashes, ev.SignerIdentities = classifyIndexKeys(keys)
+
+	for _, sink := range eventSinks {
+		ctx, cancel := context.WithTimeout(context.Background(), eventTimeout)
+		if err := sink.Publish(ctx, ev); err != nil {
+			log.Logger.Errorf("error publishing event for entry %s: %v", uuid, err)
+		}
+		cancel()
+	}
+}
+
+// classifyIndexKeys splits the index keys of an entry into artifact digests (which are stored as
+// algorithm:hex) and signer identities (the e-mail addresses found in keys and certificates)
+func classifyIndexKeys(keys []string) (hashes []string, identities []string) {
+	for _, key := range keys {
+		// the URL an artifact was downloaded from may contain @, as in the paths of scoped npm packages
+		if strings.HasPrefix(key, "url:") {
+			continue
+		}
+		if strings.Contains(key, "@") {
+			identities = append(identities, key)
+			continue
+		}
+		if alg := strings.SplitN(key, ":", 2); len(alg) == 2 {
+			switch alg[0] {
+			case "sha1", "sha256", "sha384", "sha512":
+				hashes = append(hashes, key)
+			}
+		}
+	}
+	return hashes, identities
+}
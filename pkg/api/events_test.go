@@ -0,0 +1,44 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestClassifyIndexKeys(t *testing.T) {
+	hashes, identities := classifyIndexKeys([]string{
+		"9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08",
+		"jdoe@example.com",
+		"sha256:e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+		"sha1:da39a3ee5e6b4b0d3255bfef95601890afd80709",
+		"root",
+		"mailto:x@example.com",
+		"url:https://registry.npmjs.org/@sigstore/sign/-/sign-1.0.0.tgz",
+	})
+	wantHashes := []string{
+		"sha256:e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+		"sha1:da39a3ee5e6b4b0d3255bfef95601890afd80709",
+	}
+	if !reflect.DeepEqual(hashes, wantHashes) {
+		t.Errorf("hashes = %v, want %v", hashes, wantHashes)
+	}
+	wantIdentities := []string{"jdoe@example.com", "mailto:x@example.com"}
+	if !reflect.DeepEqual(identities, wantIdentities) {
+		t.Errorf("identities = %v, want %v", identities, wantIdentities)
+	}
+}
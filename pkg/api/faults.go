@@ -0,0 +1,323 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"context"
+	"math/rand"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/types"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Faults can be injected into the calls rekor-server makes to Trillian, to check how the server copes
+// with an unreliable log backend. They are meant for test deployments only.
+
+// faultsConfig holds the faults.* settings
+type faultsConfig struct {
+	// faults injected into calls to Trillian, as method=fault followed by comma separated options, e.g.
+	// QueueLeaf=unavailable,probability=0.1
+	Rules []string `mapstructure:"rules"`
+	// seed of the choice of calls faults are injected into, so that runs can be repeated
+	Seed int64 `mapstructure:"seed"`
+}
+
+func (c faultsConfig) validate() error {
+	_, err := c.rules()
+	return err
+}
+
+// rules parses the faults to inject
+func (c faultsConfig) rules() ([]faultRule, error) {
+	var rules []faultRule
+	for _, spec := range c.Rules {
+		r, err := parseFaultRule(spec)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, r)
+	}
+	return rules, nil
+}
+
+// the faults which can be injected
+const (
+	// the call does not complete before its deadline
+	faultTimeout = "timeout"
+	// Trillian can not be reached
+	faultUnavailable = "unavailable"
+	// Trillian does not know the tree, leaf or proof
+	faultNotFound = "not_found"
+	// the root of the tree is served by a replica which has not seen any of the leaves added since the
+	// server first asked for it
+	faultStaleRoot = "stale_root"
+	// the root of the tree is served by a replica whose tree has diverged: its size is current, but
+	// not its hash
+	faultForkedRoot = "forked_root"
+)
+
+// the methods of the Trillian log client which faults can be injected into
+var faultMethods = map[string]bool{
+	"QueueLeaf":               true,
+	"GetLatestSignedLogRoot":  true,
+	"GetInclusionProof":       true,
+	"GetInclusionProofByHash": true,
+	"GetConsistencyProof":     true,
+	"GetEntryAndProof":        true,
+	"GetLeavesByRange":        true,
+}
+
+// faultRule injects a fault into calls of a method. The rules are checked in order, and a call is
+// only counted by the rules up to the first which injects a fault into it.
+type faultRule struct {
+	// a method of the Trillian log client, or * for all of them
+	Method string
+	Fault  string
+	// of injecting the fault into each call counted; 0 is taken as 1
+	Probability float64
+	// number of calls which pass before faults are injected
+	After int
+	// largest number of faults injected, or 0 for no limit
+	Count int
+	// waited for before the fault is injected; timeouts wait for the deadline of the call if 0
+	Delay time.Duration
+}
+
+// parseFaultRule parses a rule of faults.rules, e.g. GetEntryAndProof=timeout,delay=2s,after=10,count=1
+func parseFaultRule(spec string) (faultRule, error) {
+	fields := strings.Split(spec, ",")
+	split := strings.SplitN(fields[0], "=", 2)
+	if len(split) != 2 {
+		return faultRule{}, errors.Errorf("invalid fault %q, expected method=fault", spec)
+	}
+	r := faultRule{Method: split[0], Fault: split[1], Probability: 1}
+	if r.Method != "*" && !faultMethods[r.Method] {
+		return faultRule{}, errors.Errorf("can not inject faults into unknown method %q", r.Method)
+	}
+	switch r.Fault {
+	case faultTimeout, faultUnavailable, faultNotFound:
+	case faultStaleRoot, faultForkedRoot:
+		if r.Method != "GetLatestSignedLogRoot" {
+			return faultRule{}, errors.Errorf("%s faults can only be injected into GetLatestSignedLogRoot", r.Fault)
+		}
+	default:
+		return faultRule{}, errors.Errorf("unknown fault %q in %q, expected timeout, unavailable, not_found, stale_root or forked_root", r.Fault, spec)
+	}
+	for _, option := range fields[1:] {
+		kv := strings.SplitN(option, "=", 2)
+		if len(kv) != 2 {
+			return faultRule{}, errors.Errorf("invalid option %q in %q, expected key=value", option, spec)
+		}
+		var err error
+		switch kv[0] {
+		case "probability":
+			r.Probability, err = strconv.ParseFloat(kv[1], 64)
+			if err == nil && (r.Probability <= 0 || r.Probability > 1) {
+				err = errors.New("must be greater than 0 and at most 1")
+			}
+		case "after":
+			r.After, err = strconv.Atoi(kv[1])
+			if err == nil && r.After < 0 {
+				err = errors.New("must not be negative")
+			}
+		case "count":
+			r.Count, err = strconv.Atoi(kv[1])
+			if err == nil && r.Count <= 0 {
+				err = errors.New("must be positive")
+			}
+		case "delay":
+			r.Delay, err = time.ParseDuration(kv[1])
+			if err == nil && r.Delay < 0 {
+				err = errors.New("must not be negative")
+			}
+		default:
+			err = errors.New("unknown option, expected probability, after, count or delay")
+		}
+		if err != nil {
+			return faultRule{}, errors.Wrapf(err, "%s in %q", kv[0], spec)
+		}
+	}
+	return r, nil
+}
+
+// faultLogClient injects faults into the calls of the Trillian log client it wraps
+type faultLogClient struct {
+	trillian.TrillianLogClient
+	rules []faultRule
+
+	mu sync.Mutex
+	// calls counted and faults injected by each rule
+	calls, injected []int
+	rand            *rand.Rand
+	// the first root returned for each tree, which stale_root faults serve
+	firstRoots map[int64]*trillian.SignedLogRoot
+}
+
+func newFaultLogClient(next trillian.TrillianLogClient, rules []faultRule, seed int64) *faultLogClient {
+	return &faultLogClient{
+		TrillianLogClient: next,
+		rules:             rules,
+		calls:             make([]int, len(rules)),
+		injected:          make([]int, len(rules)),
+		rand:              rand.New(rand.NewSource(seed)), // nolint: gosec
+		firstRoots:        map[int64]*trillian.SignedLogRoot{},
+	}
+}
+
+// next returns the rule which injects a fault into a call of method, if any
+func (c *faultLogClient) next(method string) *faultRule {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i := range c.rules {
+		r := &c.rules[i]
+		if r.Method != method && r.Method != "*" {
+			continue
+		}
+		c.calls[i]++
+		if c.calls[i] <= r.After || (r.Count > 0 && c.injected[i] >= r.Count) {
+			continue
+		}
+		if r.Probability > 0 && r.Probability < 1 && c.rand.Float64() >= r.Probability {
+			continue
+		}
+		c.injected[i]++
+		metricInjectedFaults.WithLabelValues(method, r.Fault).Inc()
+		return r
+	}
+	return nil
+}
+
+// inject waits for the delay of the fault injected into a call of method, if any, and returns the error
+// it simulates. Faults of the root of the tree are returned to be applied to the response instead.
+func (c *faultLogClient) inject(ctx context.Context, method string) (*faultRule, error) {
+	r := c.next(method)
+	if r == nil {
+		return nil, nil
+	}
+	if r.Delay > 0 || r.Fault == faultTimeout {
+		var elapsed <-chan time.Time
+		if r.Delay > 0 {
+			timer := time.NewTimer(r.Delay)
+			defer timer.Stop()
+			elapsed = timer.C
+		}
+		select {
+		case <-ctx.Done():
+			return nil, status.Error(codes.DeadlineExceeded, ctx.Err().Error())
+		case <-elapsed:
+		}
+	}
+	switch r.Fault {
+	case faultTimeout:
+		return nil, status.Errorf(codes.DeadlineExceeded, "injected fault: %s timed out", method)
+	case faultUnavailable:
+		return nil, status.Errorf(codes.Unavailable, "injected fault: %s unavailable", method)
+	case faultNotFound:
+		return nil, status.Errorf(codes.NotFound, "injected fault: %s not found", method)
+	}
+	return r, nil
+}
+
+func (c *faultLogClient) QueueLeaf(ctx context.Context, in *trillian.QueueLeafRequest, opts ...grpc.CallOption) (*trillian.QueueLeafResponse, error) {
+	if _, err := c.inject(ctx, "QueueLeaf"); err != nil {
+		return nil, err
+	}
+	return c.TrillianLogClient.QueueLeaf(ctx, in, opts...)
+}
+
+func (c *faultLogClient) GetLatestSignedLogRoot(ctx context.Context, in *trillian.GetLatestSignedLogRootRequest, opts ...grpc.CallOption) (*trillian.GetLatestSignedLogRootResponse, error) {
+	r, err := c.inject(ctx, "GetLatestSignedLogRoot")
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.TrillianLogClient.GetLatestSignedLogRoot(ctx, in, opts...)
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	first, ok := c.firstRoots[in.LogId]
+	if !ok {
+		first = resp.SignedLogRoot
+		c.firstRoots[in.LogId] = first
+	}
+	c.mu.Unlock()
+	if r == nil {
+		return resp, nil
+	}
+	switch r.Fault {
+	case faultStaleRoot:
+		return &trillian.GetLatestSignedLogRootResponse{SignedLogRoot: first}, nil
+	case faultForkedRoot:
+		root := types.LogRootV1{}
+		if err := root.UnmarshalBinary(resp.SignedLogRoot.GetLogRoot()); err != nil {
+			return nil, err
+		}
+		forked := append([]byte{}, root.RootHash...)
+		if len(forked) > 0 {
+			forked[0] ^= 0xff
+		}
+		root.RootHash = forked
+		logRoot, err := root.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		return &trillian.GetLatestSignedLogRootResponse{SignedLogRoot: &trillian.SignedLogRoot{LogRoot: logRoot}}, nil
+	}
+	return resp, nil
+}
+
+func (c *faultLogClient) GetInclusionProof(ctx context.Context, in *trillian.GetInclusionProofRequest, opts ...grpc.CallOption) (*trillian.GetInclusionProofResponse, error) {
+	if _, err := c.inject(ctx, "GetInclusionProof"); err != nil {
+		return nil, err
+	}
+	return c.TrillianLogClient.GetInclusionProof(ctx, in, opts...)
+}
+
+func (c *faultLogClient) GetInclusionProofByHash(ctx context.Context, in *trillian.GetInclusionProofByHashRequest, opts ...grpc.CallOption) (*trillian.GetInclusionProofByHashResponse, error) {
+	if _, err := c.inject(ctx, "GetInclusionProofByHash"); err != nil {
+		return nil, err
+	}
+	return c.TrillianLogClient.GetInclusionProofByHash(ctx, in, opts...)
+}
+
+func (c *faultLogClient) GetConsistencyProof(ctx context.Context, in *trillian.GetConsistencyProofRequest, opts ...grpc.CallOption) (*trillian.GetConsistencyProofResponse, error) {
+	if _, err := c.inject(ctx, "GetConsistencyProof"); err != nil {
+		return nil, err
+	}
+	return c.TrillianLogClient.GetConsistencyProof(ctx, in, opts...)
+}
+
+func (c *faultLogClient) GetEntryAndProof(ctx context.Context, in *trillian.GetEntryAndProofRequest, opts ...grpc.CallOption) (*trillian.GetEntryAndProofResponse, error) {
+	if _, err := c.inject(ctx, "GetEntryAndProof"); err != nil {
+		return nil, err
+	}
+	return c.TrillianLogClient.GetEntryAndProof(ctx, in, opts...)
+}
+
+func (c *faultLogClient) GetLeavesByRange(ctx context.Context, in *trillian.GetLeavesByRangeRequest, opts ...grpc.CallOption) (*trillian.GetLeavesByRangeResponse, error) {
+	if _, err := c.inject(ctx, "GetLeavesByRange"); err != nil {
+		return nil, err
+	}
+	return c.TrillianLogClient.GetLeavesByRange(ctx, in, opts...)
+}
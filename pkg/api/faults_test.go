@@ -0,0 +1,243 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-openapi/runtime"
+	"github.com/go-openapi/swag"
+	"github.com/google/trillian"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/sigstore/rekor/pkg/generated/models"
+	"github.com/sigstore/rekor/pkg/generated/restapi/operations/entries"
+	"github.com/sigstore/rekor/pkg/signer"
+)
+
+func TestParseFaultRule(t *testing.T) {
+	for _, tt := range []struct {
+		spec    string
+		want    faultRule
+		wantErr string
+	}{
+		{spec: "QueueLeaf=unavailable", want: faultRule{Method: "QueueLeaf", Fault: faultUnavailable, Probability: 1}},
+		{
+			spec: "*=timeout,probability=0.25,after=10,count=2,delay=1s",
+			want: faultRule{Method: "*", Fault: faultTimeout, Probability: 0.25, After: 10, Count: 2, Delay: time.Second},
+		},
+		{spec: "GetLatestSignedLogRoot=forked_root", want: faultRule{Method: "GetLatestSignedLogRoot", Fault: faultForkedRoot, Probability: 1}},
+		{spec: "QueueLeaf", wantErr: "expected method=fault"},
+		{spec: "AddSequencedLeaves=unavailable", wantErr: "unknown method"},
+		{spec: "QueueLeaf=crash", wantErr: "unknown fault"},
+		{spec: "GetEntryAndProof=stale_root", wantErr: "only be injected into GetLatestSignedLogRoot"},
+		{spec: "QueueLeaf=unavailable,probability=0", wantErr: "probability"},
+		{spec: "QueueLeaf=unavailable,count=0", wantErr: "count"},
+		{spec: "QueueLeaf=unavailable,delay=soon", wantErr: "delay"},
+		{spec: "QueueLeaf=unavailable,retries=1", wantErr: "unknown option"},
+	} {
+		got, err := parseFaultRule(tt.spec)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("%s: expected error containing %q, got %v", tt.spec, tt.wantErr, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tt.spec, err)
+		} else if got != tt.want {
+			t.Errorf("%s: got %+v, want %+v", tt.spec, got, tt.want)
+		}
+	}
+}
+
+func TestFaultLogClient(t *testing.T) {
+	ctx := context.Background()
+	// which of 10 calls fail
+	failures := func(c *faultLogClient) []bool {
+		var failed []bool
+		for i := 0; i < 10; i++ {
+			_, err := c.GetLatestSignedLogRoot(ctx, &trillian.GetLatestSignedLogRootRequest{LogId: 1})
+			failed = append(failed, err != nil)
+		}
+		return failed
+	}
+
+	c := newFaultLogClient(newMemoryLogClient(), []faultRule{{Method: "GetLatestSignedLogRoot", Fault: faultNotFound, After: 2, Count: 3}}, 0)
+	want := []bool{false, false, true, true, true, false, false, false, false, false}
+	if got := failures(c); !reflect.DeepEqual(got, want) {
+		t.Errorf("failed calls %v, want %v", got, want)
+	}
+	if _, err := c.GetLatestSignedLogRoot(ctx, &trillian.GetLatestSignedLogRootRequest{LogId: 1}); err != nil {
+		t.Error(err)
+	}
+
+	// a later rule only counts the calls the earlier ones let through
+	c = newFaultLogClient(newMemoryLogClient(), []faultRule{
+		{Method: "GetLatestSignedLogRoot", Fault: faultUnavailable, Count: 1},
+		{Method: "*", Fault: faultNotFound, After: 1, Count: 1},
+	}, 0)
+	for i, want := range []codes.Code{codes.Unavailable, codes.OK, codes.NotFound, codes.OK} {
+		if _, err := c.GetLatestSignedLogRoot(ctx, &trillian.GetLatestSignedLogRootRequest{LogId: 1}); status.Code(err) != want {
+			t.Errorf("call %d: got %v, want %v", i, err, want)
+		}
+	}
+
+	// random faults are repeated with the same seed
+	rules := []faultRule{{Method: "*", Fault: faultUnavailable, Probability: 0.5}}
+	first := failures(newFaultLogClient(newMemoryLogClient(), rules, 42))
+	if second := failures(newFaultLogClient(newMemoryLogClient(), rules, 42)); !reflect.DeepEqual(first, second) {
+		t.Errorf("failed calls %v with the same seed as %v", second, first)
+	}
+}
+
+func TestInjectedFaults(t *testing.T) {
+	s, err := signer.NewMemory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := newMemoryLogClient()
+	for i := 0; i < 2; i++ {
+		leaf := &trillian.LogLeaf{LeafValue: []byte(fmt.Sprintf(`{"entry":%d}`, i))}
+		if _, err := m.QueueLeaf(context.Background(), &trillian.QueueLeafRequest{LogId: 1, Leaf: leaf}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	oldAPI := api
+	t.Cleanup(func() {
+		api = oldAPI
+	})
+	withFaults := func(rules ...string) {
+		var parsed []faultRule
+		for _, spec := range rules {
+			r, err := parseFaultRule(spec)
+			if err != nil {
+				t.Fatal(err)
+			}
+			parsed = append(parsed, r)
+		}
+		api = &API{logClient: newFaultLogClient(m, parsed, 0), logID: 1, rpcTimeout: 50 * time.Millisecond, signer: s, pubkeyHash: "abc"}
+	}
+	get := func(index int64) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		params := entries.GetLogEntryByIndexParams{
+			HTTPRequest: httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/v1/log/entries?logIndex=%d", index), nil),
+			LogIndex:    index,
+		}
+		GetLogEntryByIndexHandler(params).WriteResponse(rec, runtime.JSONProducer())
+		return rec
+	}
+	create := func(body []byte) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		CreateLogEntryHandler(createParams(t, body)).WriteResponse(rec, runtime.JSONProducer())
+		return rec
+	}
+	errorCode := func(rec *httptest.ResponseRecorder) models.ErrorCode {
+		e := models.Error{}
+		_ = json.Unmarshal(rec.Body.Bytes(), &e)
+		return e.ErrorCode
+	}
+
+	// reads fail with a status which tells a missing entry from an unavailable log, and no entry is
+	// served with an inclusion proof against a root which does not match it
+	for _, tt := range []struct {
+		rule          string
+		wantCode      int
+		wantErrorCode models.ErrorCode
+	}{
+		{rule: "GetEntryAndProof=unavailable", wantCode: http.StatusInternalServerError, wantErrorCode: models.ErrorCodeBackendUnavailable},
+		{rule: "GetEntryAndProof=not_found", wantCode: http.StatusNotFound},
+		{rule: "GetLatestSignedLogRoot=timeout", wantCode: http.StatusInternalServerError, wantErrorCode: models.ErrorCodeBackendUnavailable},
+		{rule: "GetLatestSignedLogRoot=forked_root", wantCode: http.StatusInternalServerError, wantErrorCode: models.ErrorCodeBackendUnavailable},
+		{rule: "GetLatestSignedLogRoot=unavailable,count=1", wantCode: http.StatusInternalServerError, wantErrorCode: models.ErrorCodeBackendUnavailable},
+	} {
+		withFaults(tt.rule)
+		rec := get(0)
+		if rec.Code != tt.wantCode {
+			t.Fatalf("%s: got response %d, want %d: %s", tt.rule, rec.Code, tt.wantCode, rec.Body)
+		}
+		if tt.wantErrorCode != "" && errorCode(rec) != tt.wantErrorCode {
+			t.Errorf("%s: got error code %q, want %q", tt.rule, errorCode(rec), tt.wantErrorCode)
+		}
+		// the faults are limited to the calls they are injected into
+		if strings.Contains(tt.rule, "count=") {
+			if rec := get(0); rec.Code != http.StatusOK {
+				t.Errorf("%s: got response %d once the fault passed: %s", tt.rule, rec.Code, rec.Body)
+			}
+		}
+	}
+
+	// a replica lagging behind does not serve entries added since, nor proofs against later trees
+	withFaults("GetLatestSignedLogRoot=stale_root")
+	if rec := get(1); rec.Code != http.StatusOK {
+		t.Fatalf("got response %d: %s", rec.Code, rec.Body)
+	}
+	if _, err := m.QueueLeaf(context.Background(), &trillian.QueueLeafRequest{LogId: 1, Leaf: &trillian.LogLeaf{LeafValue: []byte(`{"entry":2}`)}}); err != nil {
+		t.Fatal(err)
+	}
+	if rec := get(2); rec.Code != http.StatusNotFound {
+		t.Errorf("got response %d for an entry the replica has not seen: %s", rec.Code, rec.Body)
+	}
+	rec := get(0)
+	logEntry := models.LogEntry{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &logEntry); err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range logEntry {
+		if size := swag.Int64Value(e.Verification.InclusionProof.TreeSize); size != 2 {
+			t.Errorf("entry proven in a tree of size %d, want the stale size 2", size)
+		}
+	}
+
+	// a failed write leaves nothing queued, so that it can be retried
+	body := hashedrekordBody(t)
+	withFaults("QueueLeaf=unavailable,count=1")
+	for i, want := range []struct {
+		code      int
+		errorCode models.ErrorCode
+	}{
+		{code: http.StatusInternalServerError, errorCode: models.ErrorCodeBackendUnavailable},
+		{code: http.StatusCreated},
+		{code: http.StatusConflict, errorCode: models.ErrorCodeDuplicateEntry},
+	} {
+		rec := create(body)
+		if rec.Code != want.code {
+			t.Fatalf("attempt %d: got response %d, want %d: %s", i, rec.Code, want.code, rec.Body)
+		}
+		if want.errorCode != "" && errorCode(rec) != want.errorCode {
+			t.Errorf("attempt %d: got error code %q, want %q", i, errorCode(rec), want.errorCode)
+		}
+	}
+
+	// writes which time out are reported as failures once the deadline of the call passes
+	withFaults("QueueLeaf=timeout")
+	start := time.Now()
+	if rec := create(hashedrekordBody(t)); rec.Code != http.StatusInternalServerError || errorCode(rec) != models.ErrorCodeBackendUnavailable {
+		t.Errorf("got response %d: %s", rec.Code, rec.Body)
+	}
+	if elapsed := time.Since(start); elapsed < api.rpcTimeout {
+		t.Errorf("write failed after %v, before the deadline of %v", elapsed, api.rpcTimeout)
+	}
+}
@@ -0,0 +1,151 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-openapi/runtime"
+	"github.com/google/trillian/merkle/rfc6962"
+	"google.golang.org/genproto/googleapis/rpc/code"
+	"google.golang.org/grpc/codes"
+
+	"github.com/sigstore/rekor/pkg/audit"
+	"github.com/sigstore/rekor/pkg/generated/models"
+	"github.com/sigstore/rekor/pkg/log"
+	"github.com/sigstore/rekor/pkg/types"
+	"github.com/sigstore/rekor/pkg/util"
+)
+
+// Provenance describes where an imported entry was originally logged. It is stored as JSON in the
+// extra data of the leaf, which is not covered by the Merkle tree.
+type Provenance struct {
+	Source         string `json:"source"` // URL of the log or path of the dump the entry was read from
+	LogID          string `json:"logID"`  // SHA256 hash of the public key of the source log
+	LogIndex       int64  `json:"logIndex"`
+	UUID           string `json:"uuid"`
+	IntegratedTime int64  `json:"integratedTime"`
+	ImportedTime   int64  `json:"importedTime"`
+}
+
+// ErrEntryExists is returned by ImportEntry if the log already contains the entry
+var ErrEntryExists = errors.New("entry already exists in the log")
+
+// ImportEntry appends the body of an entry read from another log. The body is checked as proposed
+// entries are, with its signature verified by canonicalizing it and certificates checked at the time
+// the entry was integrated into the source log; the body rather than its canonical form is logged, so
+// that the entry keeps its UUID. It returns the index of the entry in this log.
+func ImportEntry(ctx context.Context, body []byte, p Provenance) (int64, error) {
+	index, err := importEntry(ctx, body, p)
+	rec := audit.Record{
+		Action:    audit.ActionImportEntry,
+		Outcome:   audit.OutcomeSuccess,
+		EntryUUID: p.UUID,
+		Detail:    "imported from " + p.Source,
+	}
+	if a := logAPI(ctx); a != nil {
+		rec.TreeID = a.logID
+	}
+	switch {
+	case errors.Is(err, ErrEntryExists):
+		rec.Outcome = audit.OutcomeDuplicate
+	case err != nil:
+		rec.Outcome, rec.Detail = audit.OutcomeError, rec.Detail+": "+err.Error()
+	}
+	writeAudit(rec)
+	return index, err
+}
+
+func importEntry(ctx context.Context, body []byte, p Provenance) (int64, error) {
+	a := logAPI(ctx)
+	pe, err := models.UnmarshalProposedEntry(bytes.NewReader(body), runtime.JSONConsumer())
+	if err != nil {
+		return 0, fmt.Errorf("parsing entry: %w", err)
+	}
+	entry, err := types.NewEntry(pe)
+	if err != nil {
+		return 0, fmt.Errorf("validating entry: %w", err)
+	}
+	// canonicalizing verifies the signature of the entry over its artifact
+	if _, err := types.CanonicalizeEntry(ctx, entry, pe.Annotations()); err != nil {
+		return 0, fmt.Errorf("verifying entry: %w", err)
+	}
+	integratedTime := time.Unix(p.IntegratedTime, 0)
+	if err := verifyKeyless(entry, integratedTime); err != nil {
+		return 0, err
+	}
+	if err := verifyCertificateChains(entry, integratedTime); err != nil {
+		return 0, err
+	}
+	violations, err := checkAdmission(a.currentAdmissionPolicy(), pe, entry)
+	if err != nil {
+		return 0, err
+	}
+	if len(violations) > 0 {
+		return 0, fmt.Errorf("entry rejected by admission policy: %v", violations)
+	}
+
+	extraData, err := json.Marshal(p)
+	if err != nil {
+		return 0, err
+	}
+	tc := NewTrillianClient(ctx)
+	inFrozenShard, err := tc.inFrozenShard(rfc6962.DefaultHasher.HashLeaf(body))
+	if err != nil {
+		return 0, fmt.Errorf("grpc error: %w", err)
+	}
+	if inFrozenShard {
+		return 0, ErrEntryExists
+	}
+	resp := tc.addLeaf(body, extraData)
+	if resp.status != codes.OK {
+		return 0, fmt.Errorf("grpc error: %w", resp.err)
+	}
+	if insertionStatus := resp.getAddResult.QueuedLeaf.Status; insertionStatus != nil {
+		switch insertionStatus.Code {
+		case int32(code.Code_OK):
+		case int32(code.Code_ALREADY_EXISTS), int32(code.Code_FAILED_PRECONDITION):
+			return 0, ErrEntryExists
+		default:
+			return 0, fmt.Errorf("grpc error: %v", insertionStatus.String())
+		}
+	}
+	metricNewEntries.Inc()
+
+	leaf := resp.getAddResult.QueuedLeaf.Leaf
+	uuid := util.EntryUUIDFromLeafHash(leaf.GetMerkleLeafHash())
+	if indexClient != nil {
+		keys, err := entry.IndexKeys()
+		if err != nil {
+			log.Logger.Errorf("error computing index keys of %v: %v", uuid, err)
+		}
+		keys = append(keys, a.annotationIndexKeys(pe.Annotations())...)
+		for _, key := range keys {
+			if err := addToIndex(ctx, a.indexPrefix+key, uuid); err != nil {
+				log.Logger.Errorf("error indexing %v: %v", uuid, err)
+			}
+		}
+		if err := addToTimeIndex(ctx, a.indexPrefix, uuid, leaf.IntegrateTimestamp.AsTime().Unix()); err != nil {
+			log.Logger.Errorf("error indexing %v: %v", uuid, err)
+		}
+	}
+	return tc.activeOffset() + leaf.LeafIndex, nil
+}
@@ -19,27 +19,50 @@ import (
 	"context"
 	"crypto/sha256"
 	"encoding/hex"
+	"fmt"
+	"math"
 	"net/http"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/go-openapi/runtime/middleware"
 	"github.com/go-openapi/swag"
 	radix "github.com/mediocregopher/radix/v4"
+	"google.golang.org/grpc/codes"
 
 	"github.com/sigstore/rekor/pkg/generated/models"
 	"github.com/sigstore/rekor/pkg/generated/restapi/operations/index"
+	"github.com/sigstore/rekor/pkg/log"
 	"github.com/sigstore/rekor/pkg/pki"
+	"github.com/sigstore/rekor/pkg/types"
 	"github.com/sigstore/rekor/pkg/util"
 )
 
 func SearchIndexHandler(params index.SearchIndexParams) middleware.Responder {
 	httpReqCtx := params.HTTPRequest.Context()
+	a := logAPI(httpReqCtx)
+	indexPrefix := a.indexPrefix
+
+	digest, err := queryDigest(params.Query)
+	if err != nil {
+		return handleRekorAPIError(params, http.StatusInternalServerError, err, "")
+	}
+	token, err := decodePageToken(params.PageToken, digest)
+	if err != nil {
+		return handleRekorAPIError(params, http.StatusBadRequest, err, fmt.Sprintf(malformedPageToken, err))
+	}
 
 	var result []string
 	if params.Query.Hash != "" {
-		// This must be a valid sha256 hash
-		var resultUUIDs []string
-		if err := redisClient.Do(httpReqCtx, radix.Cmd(&resultUUIDs, "LRANGE", strings.ToLower(params.Query.Hash), "0", "-1")); err != nil {
+		// digests are indexed with their algorithm, which is inferred from the length of untagged ones
+		hashKey, err := util.NormalizeDigest(params.Query.Hash)
+		if err != nil {
+			return handleRekorAPIError(params, http.StatusBadRequest, err, malformedHash)
+		}
+		resultUUIDs, err := indexClient.lookup(httpReqCtx, indexPrefix+hashKey)
+		if err != nil {
 			return handleRekorAPIError(params, http.StatusInternalServerError, err, redisUnexpectedResult)
 		}
 		result = append(result, resultUUIDs...)
@@ -65,21 +88,93 @@ func SearchIndexHandler(params index.SearchIndexParams) middleware.Responder {
 		}
 
 		keyHash := sha256.Sum256(canonicalKey)
-		var resultUUIDs []string
-		if err := redisClient.Do(httpReqCtx, radix.Cmd(&resultUUIDs, "LRANGE", strings.ToLower(hex.EncodeToString(keyHash[:])), "0", "-1")); err != nil {
+		resultUUIDs, err := indexClient.lookup(httpReqCtx, indexPrefix+strings.ToLower(hex.EncodeToString(keyHash[:])))
+		if err != nil {
 			return handleRekorAPIError(params, http.StatusInternalServerError, err, redisUnexpectedResult)
 		}
 		result = append(result, resultUUIDs...)
 	}
 	if params.Query.Email != "" {
-		var resultUUIDs []string
-		if err := redisClient.Do(httpReqCtx, radix.Cmd(&resultUUIDs, "LRANGE", strings.ToLower(params.Query.Email.String()), "0", "-1")); err != nil {
+		resultUUIDs, err := indexClient.lookup(httpReqCtx, indexPrefix+strings.ToLower(params.Query.Email.String()))
+		if err != nil {
+			return handleRekorAPIError(params, http.StatusInternalServerError, err, redisUnexpectedResult)
+		}
+		result = append(result, resultUUIDs...)
+	}
+	if params.Query.Commit != "" {
+		commitKey, err := types.CommitIndexKey(params.Query.Commit)
+		if err != nil {
+			return handleRekorAPIError(params, http.StatusBadRequest, err, malformedCommit)
+		}
+		resultUUIDs, err := indexClient.lookup(httpReqCtx, indexPrefix+commitKey)
+		if err != nil {
+			return handleRekorAPIError(params, http.StatusInternalServerError, err, redisUnexpectedResult)
+		}
+		result = append(result, resultUUIDs...)
+	}
+	if params.Query.Repository != "" {
+		repoKey, err := types.RepositoryIndexKey(params.Query.Repository)
+		if err != nil {
+			return handleRekorAPIError(params, http.StatusBadRequest, err, malformedRepository)
+		}
+		resultUUIDs, err := indexClient.lookup(httpReqCtx, indexPrefix+repoKey)
+		if err != nil {
+			return handleRekorAPIError(params, http.StatusInternalServerError, err, redisUnexpectedResult)
+		}
+		result = append(result, resultUUIDs...)
+	}
+	if params.Query.ArtifactURL != "" {
+		artifactURL, err := util.NormalizeArtifactURL(params.Query.ArtifactURL)
+		if err != nil {
+			return handleRekorAPIError(params, http.StatusBadRequest, err, malformedArtifactURL)
+		}
+		resultUUIDs, err := indexClient.lookup(httpReqCtx, indexPrefix+types.ArtifactURLIndexKey(artifactURL))
+		if err != nil {
+			return handleRekorAPIError(params, http.StatusInternalServerError, err, redisUnexpectedResult)
+		}
+		result = append(result, resultUUIDs...)
+	}
+	for _, k := range types.AnnotationKeys(params.Query.Annotations) {
+		if !a.indexedAnnotations[k] {
+			return handleRekorAPIError(params, http.StatusBadRequest, fmt.Errorf("annotation %q is not indexed", k), fmt.Sprintf(annotationNotIndexed, k))
+		}
+		resultUUIDs, err := indexClient.lookup(httpReqCtx, indexPrefix+types.AnnotationIndexKey(k, params.Query.Annotations[k]))
+		if err != nil {
 			return handleRekorAPIError(params, http.StatusInternalServerError, err, redisUnexpectedResult)
 		}
 		result = append(result, resultUUIDs...)
 	}
 
-	return index.NewSearchIndexOK().WithPayload(result)
+	if params.Query.IntegratedSince != 0 || params.Query.IntegratedUntil != 0 {
+		since, until := params.Query.IntegratedSince, params.Query.IntegratedUntil
+		if until == 0 {
+			until = math.MaxInt64
+		}
+		if since < 0 || until < since {
+			return handleRekorAPIError(params, http.StatusBadRequest, fmt.Errorf("invalid time range [%d, %d]", since, until), malformedTimeRange)
+		}
+		if !hasIndexCriteria(params.Query) {
+			if token.After != "" && (token.AfterTime < since || token.AfterTime > until) {
+				err := fmt.Errorf("page token after %d is outside of the time range [%d, %d]", token.AfterTime, since, until)
+				return handleRekorAPIError(params, http.StatusBadRequest, err, fmt.Sprintf(malformedPageToken, err))
+			}
+			// a search by time alone is served a page at a time from the time index, in the order of time
+			page, next, err := pageTimeRange(httpReqCtx, indexPrefix+integratedTimeKey, since, until, token, a.pageSize(params.PageSize))
+			if err != nil {
+				return handleRekorAPIError(params, http.StatusInternalServerError, err, redisUnexpectedResult)
+			}
+			return index.NewSearchIndexOK().WithPayload(page).WithXRekorNextPageToken(next)
+		}
+		// entries added before the time index was introduced are not in it, so the matches of the other
+		// criteria are filtered by the time each was integrated instead of being intersected with it
+		result, err = integratedBetween(httpReqCtx, a, result, since, until)
+		if err != nil {
+			return handleRekorAPIError(params, http.StatusInternalServerError, err, trillianCommunicationError)
+		}
+	}
+
+	page, next := pageSorted(result, token, a.pageSize(params.PageSize))
+	return index.NewSearchIndexOK().WithPayload(page).WithXRekorNextPageToken(next)
 }
 
 func SearchIndexNotImplementedHandler(params index.SearchIndexParams) middleware.Responder {
@@ -93,7 +188,283 @@ func SearchIndexNotImplementedHandler(params index.SearchIndexParams) middleware
 }
 
 func addToIndex(ctx context.Context, key, value string) error {
-	return redisClient.Do(ctx, radix.Cmd(nil, "LPUSH", key, value))
+	return indexClient.add(ctx, key, value)
+}
+
+// integratedTimeKey is the key under which the UUIDs of entries are indexed by the time they were
+// integrated into the log
+const integratedTimeKey = "integrated_time"
+
+// addToTimeIndex records that the entry with the given UUID was integrated at t, in Unix time
+func addToTimeIndex(ctx context.Context, indexPrefix, uuid string, t int64) error {
+	return indexClient.addTimed(ctx, indexPrefix+integratedTimeKey, uuid, t)
+}
+
+// pageTimeRange returns the page of UUIDs integrated in [since, until] following the one t ended with,
+// ordered by the time of integration and then by UUID, and the token of the next page, which is empty
+// if this is the last one
+func pageTimeRange(ctx context.Context, key string, since, until int64, t pageToken, size int) ([]string, string, error) {
+	after := ""
+	if t.After != "" {
+		since, after = t.AfterTime, t.After
+	}
+	values, err := indexClient.lookupRange(ctx, key, since, until, after, size+1)
+	if err != nil {
+		return nil, "", err
+	}
+	page := make([]string, 0, len(values))
+	for _, v := range values {
+		page = append(page, v.value)
+	}
+	if len(values) <= size {
+		return page, "", nil
+	}
+	last := values[size-1]
+	return page[:size], pageToken{Query: t.Query, After: last.value, AfterTime: last.time}.encode(), nil
+}
+
+// integratedBetween returns the UUIDs of those entries which were integrated into the log in
+// [since, until], without duplicates. The times are read from the time index; entries added before it
+// was introduced are looked up in the log, and added to the index for later searches.
+func integratedBetween(ctx context.Context, a *API, uuids []string, since, until int64) ([]string, error) {
+	var unique []string
+	seen := map[string]bool{}
+	for _, uuid := range uuids {
+		if !seen[uuid] {
+			seen[uuid] = true
+			unique = append(unique, uuid)
+		}
+	}
+	times, err := indexClient.timesOf(ctx, a.indexPrefix+integratedTimeKey, unique)
+	if err != nil {
+		return nil, err
+	}
+	var result []string
+	for _, uuid := range unique {
+		t, ok := times[uuid]
+		if !ok {
+			if t, ok, err = entryIntegratedTime(ctx, uuid); err != nil {
+				return nil, err
+			}
+			if !ok {
+				continue
+			}
+			if err := addToTimeIndex(ctx, a.indexPrefix, uuid, t); err != nil {
+				log.Logger.Errorf("error indexing the integration time of %v: %v", uuid, err)
+			}
+		}
+		if t >= since && t <= until {
+			result = append(result, uuid)
+		}
+	}
+	return result, nil
+}
+
+// entryIntegratedTime looks up the time the entry with the given UUID was integrated into the log, in
+// Unix time; it returns false if the log does not hold the entry
+func entryIntegratedTime(ctx context.Context, uuid string) (int64, bool, error) {
+	hash, err := util.LeafHashFromEntryUUID(uuid)
+	if err != nil {
+		return 0, false, err
+	}
+	tc := NewTrillianClient(ctx)
+	resp := tc.getEntryByHash(hash)
+	switch resp.status {
+	case codes.OK:
+	case codes.NotFound:
+		return 0, false, nil
+	default:
+		return 0, false, fmt.Errorf("grpc error: %w", resp.err)
+	}
+	leaf := resp.getLeafAndProofResult.Leaf
+	if leaf == nil {
+		return 0, false, nil
+	}
+	return leaf.IntegrateTimestamp.AsTime().Unix(), true, nil
+}
+
+// hasIndexCriteria returns whether q searches by any criterion besides the time range of integration
+func hasIndexCriteria(q *models.SearchIndex) bool {
+	return q.Hash != "" || q.PublicKey != nil || q.Email != "" || q.Commit != "" || q.Repository != "" ||
+		q.ArtifactURL != "" || len(q.Annotations) > 0
+}
+
+// indexStorage maps the index keys of entries to their UUIDs
+type indexStorage interface {
+	// lookup returns the values added for key, most recently added first
+	lookup(ctx context.Context, key string) ([]string, error)
+	add(ctx context.Context, key, value string) error
+	// lookupRange returns up to count of the values added for key with addTimed at a time in
+	// [since, until], ordered by time and then by value; if after is set, the values added at since
+	// up to and including after are skipped
+	lookupRange(ctx context.Context, key string, since, until int64, after string, count int) ([]timedValue, error)
+	// timesOf returns the times at which values were added for key with addTimed, leaving out those
+	// which were not
+	timesOf(ctx context.Context, key string, values []string) (map[string]int64, error)
+	addTimed(ctx context.Context, key, value string, t int64) error
+}
+
+// timedValue is a value added to the index with addTimed
+type timedValue struct {
+	value string
+	time  int64
+}
+
+// redisIndex keeps the index in Redis, as a list per key
+type redisIndex struct {
+	client radix.Client
+}
+
+func (r *redisIndex) lookup(ctx context.Context, key string) ([]string, error) {
+	var values []string
+	if err := r.client.Do(ctx, radix.Cmd(&values, "LRANGE", key, "0", "-1")); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+func (r *redisIndex) add(ctx context.Context, key, value string) error {
+	return r.client.Do(ctx, radix.Cmd(nil, "LPUSH", key, value))
+}
+
+// timed values are kept in a sorted set scored by their time, in which values of the same score are
+// ordered by their bytes
+func (r *redisIndex) lookupRange(ctx context.Context, key string, since, until int64, after string, count int) ([]timedValue, error) {
+	var result []timedValue
+	min := strconv.FormatInt(since, 10)
+	if after != "" {
+		var same []string
+		if err := r.client.Do(ctx, radix.Cmd(&same, "ZRANGEBYSCORE", key, min, min)); err != nil {
+			return nil, err
+		}
+		for _, v := range same {
+			if v > after && len(result) < count {
+				result = append(result, timedValue{value: v, time: since})
+			}
+		}
+		min = "(" + min
+	}
+	if len(result) == count {
+		return result, nil
+	}
+	var withScores []string
+	if err := r.client.Do(ctx, radix.Cmd(&withScores, "ZRANGEBYSCORE", key, min, strconv.FormatInt(until, 10),
+		"WITHSCORES", "LIMIT", "0", strconv.Itoa(count-len(result)))); err != nil {
+		return nil, err
+	}
+	for i := 0; i+1 < len(withScores); i += 2 {
+		t, err := parseScore(withScores[i+1])
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, timedValue{value: withScores[i], time: t})
+	}
+	return result, nil
+}
+
+func (r *redisIndex) timesOf(ctx context.Context, key string, values []string) (map[string]int64, error) {
+	times := make(map[string]int64, len(values))
+	for _, v := range values {
+		var score string
+		mb := radix.Maybe{Rcv: &score}
+		if err := r.client.Do(ctx, radix.Cmd(&mb, "ZSCORE", key, v)); err != nil {
+			return nil, err
+		}
+		if mb.Null {
+			continue
+		}
+		t, err := parseScore(score)
+		if err != nil {
+			return nil, err
+		}
+		times[v] = t
+	}
+	return times, nil
+}
+
+// parseScore parses the score of a timed value, which Redis returns formatted as a float
+func parseScore(score string) (int64, error) {
+	f, err := strconv.ParseFloat(score, 64)
+	if err != nil {
+		return 0, fmt.Errorf("unexpected score %q: %w", score, err)
+	}
+	return int64(f), nil
+}
+
+func (r *redisIndex) addTimed(ctx context.Context, key, value string, t int64) error {
+	return r.client.Do(ctx, radix.Cmd(nil, "ZADD", key, strconv.FormatInt(t, 10), value))
+}
+
+// memoryIndex keeps the index in memory for development and testing; it is lost when the process exits
+type memoryIndex struct {
+	mu     sync.RWMutex
+	values map[string][]string
+	times  map[string]map[string]int64
+}
+
+func newMemoryIndex() *memoryIndex {
+	return &memoryIndex{values: map[string][]string{}, times: map[string]map[string]int64{}}
+}
+
+func (m *memoryIndex) lookup(ctx context.Context, key string) ([]string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	values := m.values[key]
+	result := make([]string, len(values))
+	for i, v := range values {
+		result[len(values)-1-i] = v
+	}
+	return result, nil
+}
+
+func (m *memoryIndex) add(ctx context.Context, key, value string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.values[key] = append(m.values[key], value)
+	return nil
+}
+
+func (m *memoryIndex) lookupRange(ctx context.Context, key string, since, until int64, after string, count int) ([]timedValue, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var result []timedValue
+	for v, t := range m.times[key] {
+		if t >= since && t <= until && (after == "" || t > since || v > after) {
+			result = append(result, timedValue{value: v, time: t})
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].time != result[j].time {
+			return result[i].time < result[j].time
+		}
+		return result[i].value < result[j].value
+	})
+	if len(result) > count {
+		result = result[:count]
+	}
+	return result, nil
+}
+
+func (m *memoryIndex) timesOf(ctx context.Context, key string, values []string) (map[string]int64, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	times := make(map[string]int64, len(values))
+	for _, v := range values {
+		if t, ok := m.times[key][v]; ok {
+			times[v] = t
+		}
+	}
+	return times, nil
+}
+
+func (m *memoryIndex) addTimed(ctx context.Context, key, value string, t int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.times[key] == nil {
+		m.times[key] = map[string]int64{}
+	}
+	m.times[key][value] = t
+	return nil
 }
 
 func storeAttestation(ctx context.Context, uuid string, attestation []byte) error {
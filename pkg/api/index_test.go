@@ -0,0 +1,196 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/go-openapi/runtime"
+	"github.com/google/trillian"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/sigstore/rekor/pkg/generated/models"
+	"github.com/sigstore/rekor/pkg/generated/restapi/operations/index"
+	"github.com/sigstore/rekor/pkg/util"
+)
+
+func TestMemoryIndexRange(t *testing.T) {
+	ctx := context.Background()
+	m := newMemoryIndex()
+	for i, uuid := range []string{"a", "b", "c", "d"} {
+		if err := m.addTimed(ctx, integratedTimeKey, uuid, int64(100*(i+1))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// values added at the same time are ordered by value
+	if err := m.addTimed(ctx, integratedTimeKey, "bb", 200); err != nil {
+		t.Fatal(err)
+	}
+	values := func(timed []timedValue) string {
+		var v []string
+		for _, tv := range timed {
+			v = append(v, tv.value)
+		}
+		return fmt.Sprint(v)
+	}
+	for _, test := range []struct {
+		since, until int64
+		after        string
+		count        int
+		want         string
+	}{
+		{since: 0, until: math.MaxInt64, count: 10, want: "[a b bb c d]"},
+		{since: 200, until: 300, count: 10, want: "[b bb c]"},
+		{since: 201, until: 299, count: 10, want: "[]"},
+		{since: 400, until: math.MaxInt64, count: 10, want: "[d]"},
+		{since: 0, until: math.MaxInt64, count: 2, want: "[a b]"},
+		{since: 200, until: math.MaxInt64, after: "b", count: 2, want: "[bb c]"},
+		{since: 200, until: math.MaxInt64, after: "bb", count: 10, want: "[c d]"},
+	} {
+		got, err := m.lookupRange(ctx, integratedTimeKey, test.since, test.until, test.after, test.count)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if values(got) != test.want {
+			t.Errorf("lookupRange(%d, %d, %q, %d) = %v, want %v", test.since, test.until, test.after, test.count, values(got), test.want)
+		}
+	}
+	if got, _ := m.lookupRange(ctx, "other", 0, math.MaxInt64, "", 10); len(got) != 0 {
+		t.Errorf("lookupRange() of another key = %v", got)
+	}
+
+	times, err := m.timesOf(ctx, integratedTimeKey, []string{"a", "bb", "e"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]int64{"a": 100, "bb": 200}; !reflect.DeepEqual(times, want) {
+		t.Errorf("timesOf() = %v, want %v", times, want)
+	}
+}
+
+func TestSearchIndexByTime(t *testing.T) {
+	oldAPI, oldIndex := api, indexClient
+	t.Cleanup(func() {
+		api, indexClient = oldAPI, oldIndex
+	})
+	ctx := context.Background()
+	m := newMemoryLogClient()
+	api = &API{logClient: m, logID: 1, maxPageSize: 2}
+	indexClient = newMemoryIndex()
+
+	// the entries share an artifact; only the last was added once the time index was introduced
+	digest := sha256.Sum256([]byte("artifact"))
+	hashKey := "sha256:" + hex.EncodeToString(digest[:])
+	var uuids []string
+	for i := 0; i < 3; i++ {
+		resp, err := m.QueueLeaf(ctx, &trillian.QueueLeafRequest{LogId: 1, Leaf: &trillian.LogLeaf{LeafValue: []byte(fmt.Sprintf(`{"entry":%d}`, i))}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		leaf := resp.QueuedLeaf.Leaf
+		leaf.IntegrateTimestamp = timestamppb.New(time.Unix(int64(100*(i+1)), 0))
+		uuid := util.EntryUUIDFromLeafHash(leaf.MerkleLeafHash)
+		uuids = append(uuids, uuid)
+		if err := addToIndex(ctx, hashKey, uuid); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := addToTimeIndex(ctx, "", uuids[2], 300); err != nil {
+		t.Fatal(err)
+	}
+
+	search := func(q *models.SearchIndex, token string) (*httptest.ResponseRecorder, []string, string) {
+		rec := httptest.NewRecorder()
+		params := index.SearchIndexParams{HTTPRequest: httptest.NewRequest(http.MethodPost, "/api/v1/index/retrieve", nil), Query: q}
+		if token != "" {
+			params.PageToken = &token
+		}
+		SearchIndexHandler(params).WriteResponse(rec, runtime.JSONProducer())
+		var page []string
+		if rec.Code == http.StatusOK {
+			if err := json.Unmarshal(rec.Body.Bytes(), &page); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return rec, page, rec.Header().Get("X-Rekor-Next-Page-Token")
+	}
+
+	// matches of other criteria are filtered by the time each entry was integrated, including those
+	// missing from the time index, which are added to it
+	rec, page, _ := search(&models.SearchIndex{Hash: hashKey, IntegratedSince: 150}, "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("got response %d: %s", rec.Code, rec.Body)
+	}
+	want := []string{uuids[1], uuids[2]}
+	sort.Strings(want)
+	if !reflect.DeepEqual(page, want) {
+		t.Errorf("got %v, want %v", page, want)
+	}
+	if times, _ := indexClient.timesOf(ctx, integratedTimeKey, uuids[1:2]); times[uuids[1]] != 200 {
+		t.Errorf("integration time of %v not added to the index", uuids[1])
+	}
+	if _, page, _ := search(&models.SearchIndex{Hash: hashKey, IntegratedSince: 150, IntegratedUntil: 250}, ""); !reflect.DeepEqual(page, uuids[1:2]) {
+		t.Errorf("got %v, want %v", page, uuids[1:2])
+	}
+
+	// a search by time alone is paged in the order of time, and then of UUID for entries integrated
+	// in the same second
+	for _, uuid := range []string{"e0", "e2", "e1"} {
+		if err := addToTimeIndex(ctx, "", uuid, 400); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var all []string
+	token := ""
+	for i := 0; ; i++ {
+		rec, page, next := search(&models.SearchIndex{IntegratedSince: 150}, token)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("page %d: got response %d: %s", i, rec.Code, rec.Body)
+		}
+		if len(page) > 2 {
+			t.Errorf("page %d holds %d results", i, len(page))
+		}
+		all = append(all, page...)
+		if next == "" {
+			break
+		}
+		token = next
+	}
+	if want := []string{uuids[1], uuids[2], "e0", "e1", "e2"}; !reflect.DeepEqual(all, want) {
+		t.Errorf("got %v, want %v", all, want)
+	}
+
+	// page tokens of searches by time continue only from within their time range
+	q := &models.SearchIndex{IntegratedSince: 150}
+	qDigest, err := queryDigest(q)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec, _, _ := search(q, pageToken{Query: qDigest, After: "e0", AfterTime: 100}.encode()); rec.Code != http.StatusBadRequest {
+		t.Errorf("got response %d for a token outside of the time range: %s", rec.Code, rec.Body)
+	}
+}
@@ -0,0 +1,70 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"crypto/x509"
+	"io/ioutil"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+
+	pki "github.com/sigstore/rekor/pkg/pki/x509"
+	"github.com/sigstore/rekor/pkg/types"
+)
+
+// keylessConfig holds the keyless.* settings
+type keylessConfig struct {
+	FulcioRoots string `mapstructure:"fulcio_roots"`
+}
+
+// fulcioRoots loads the Fulcio certificates used to recognize keyless signatures; nil if none are configured
+func (c keylessConfig) fulcioRoots() ([]*x509.Certificate, error) {
+	if c.FulcioRoots == "" {
+		return nil, nil
+	}
+	pemBytes, err := ioutil.ReadFile(filepath.Clean(c.FulcioRoots))
+	if err != nil {
+		return nil, errors.Wrap(err, "reading fulcio roots")
+	}
+	certs, err := pki.ParseCertificates(pemBytes)
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing fulcio roots")
+	}
+	return certs, nil
+}
+
+// verifyKeyless checks that any Fulcio-issued certificates which signed the canonicalized entry are
+// valid at admissionTime; the log's integrated time then attests to when the signature was made
+func verifyKeyless(entry types.EntryImpl, admissionTime time.Time) error {
+	sp, ok := entry.(types.SignerProvider)
+	if !ok {
+		return nil
+	}
+	signers, err := sp.Signers()
+	if err != nil {
+		return err
+	}
+	for _, s := range signers {
+		if k, ok := s.(*pki.PublicKey); ok {
+			if err := k.VerifyKeyless(admissionTime); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
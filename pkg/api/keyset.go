@@ -0,0 +1,247 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ghodss/yaml"
+	"github.com/pkg/errors"
+	"github.com/sigstore/sigstore/pkg/cryptoutils"
+	"github.com/sigstore/sigstore/pkg/signature"
+
+	"github.com/sigstore/rekor/pkg/audit"
+	"github.com/sigstore/rekor/pkg/signer"
+	"github.com/sigstore/rekor/pkg/verify"
+)
+
+// keySetConfig describes the keys the log signed with before the key of rekor_server.signer
+type keySetConfig struct {
+	// when the key of rekor_server.signer was first used
+	SignerValidFrom *time.Time          `json:"signer_valid_from"`
+	Previous        []previousKeyConfig `json:"previous"`
+}
+
+type previousKeyConfig struct {
+	// path to the PEM encoded public key, which is taken from the signer if unset
+	PublicKey string `json:"public_key"`
+	// the signer of the key, if checkpoints are still to be signed with it until valid_until
+	Signer     string     `json:"signer"`
+	ValidFrom  *time.Time `json:"valid_from"`
+	ValidUntil *time.Time `json:"valid_until"`
+	// the key is compromised, so that clients reject anything signed with it, whenever it claims to
+	// have been signed; valid_from and valid_until do not protect against a key falling into other hands
+	Revoked bool `json:"revoked"`
+}
+
+// previousKey is a key the log signed with before its current key
+type previousKey struct {
+	pubkey     string // PEM encoded public key
+	pubkeyHash string // SHA256 hash of DER-encoded public key
+	// co-signs checkpoints until validUntil so that clients which only know this key keep verifying
+	// them; nil once the key is no longer used
+	signer     signature.Signer
+	validFrom  time.Time
+	validUntil time.Time
+	revoked    bool
+}
+
+// readKeySetConfig parses the YAML file describing the previous keys of the log
+func readKeySetConfig(path string) (*keySetConfig, error) {
+	contents, err := ioutil.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, err
+	}
+	j, err := yaml.YAMLToJSON(contents)
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing")
+	}
+	dec := json.NewDecoder(bytes.NewReader(j))
+	dec.DisallowUnknownFields()
+	cfg := &keySetConfig{}
+	if err := dec.Decode(cfg); err != nil {
+		return nil, errors.Wrap(err, "parsing")
+	}
+	return cfg, nil
+}
+
+// loadKeySet loads the previous keys of a log whose current key has the hash pubkeyHash from the file
+// at path, returning them along with when the current key was first used
+func loadKeySet(ctx context.Context, path, pubkeyHash string) ([]previousKey, time.Time, error) {
+	cfg, err := readKeySetConfig(path)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	var signerValidFrom time.Time
+	if cfg.SignerValidFrom != nil {
+		signerValidFrom = *cfg.SignerValidFrom
+	}
+
+	seen := map[string]bool{pubkeyHash: true}
+	var keys []previousKey
+	for i, c := range cfg.Previous {
+		k, err := c.load(ctx)
+		if err != nil {
+			return nil, time.Time{}, errors.Wrapf(err, "previous key %d", i)
+		}
+		if seen[k.pubkeyHash] {
+			return nil, time.Time{}, errors.Errorf("previous key %d is listed twice or is the key of rekor_server.signer", i)
+		}
+		seen[k.pubkeyHash] = true
+		keys = append(keys, k)
+	}
+	return keys, signerValidFrom, nil
+}
+
+func (c previousKeyConfig) load(ctx context.Context) (previousKey, error) {
+	if c.ValidUntil == nil {
+		return previousKey{}, errors.New("valid_until must be set")
+	}
+	if c.Revoked && c.Signer != "" {
+		return previousKey{}, errors.New("a revoked key can not sign")
+	}
+	k := previousKey{validUntil: *c.ValidUntil, revoked: c.Revoked}
+	if c.ValidFrom != nil {
+		if !c.ValidFrom.Before(*c.ValidUntil) {
+			return previousKey{}, errors.New("valid_from must be before valid_until")
+		}
+		k.validFrom = *c.ValidFrom
+	}
+
+	var pubkey, signerPubkey string
+	var err error
+	if c.PublicKey != "" {
+		b, err := ioutil.ReadFile(filepath.Clean(c.PublicKey))
+		if err != nil {
+			return previousKey{}, err
+		}
+		pub, err := cryptoutils.UnmarshalPEMToPublicKey(b)
+		if err != nil {
+			return previousKey{}, errors.Wrap(err, "parsing public_key")
+		}
+		if pubkey, k.pubkeyHash, err = encodePublicKey(pub); err != nil {
+			return previousKey{}, err
+		}
+	}
+	if c.Signer != "" {
+		if k.signer, err = signer.New(ctx, c.Signer); err != nil {
+			return previousKey{}, errors.Wrap(err, "getting signer")
+		}
+		var signerHash string
+		if signerPubkey, signerHash, err = signerPublicKey(ctx, k.signer); err != nil {
+			return previousKey{}, err
+		}
+		if pubkey != "" && signerHash != k.pubkeyHash {
+			return previousKey{}, errors.New("public_key is not the key of signer")
+		}
+		k.pubkeyHash = signerHash
+	}
+	switch {
+	case pubkey != "":
+		k.pubkey = pubkey
+	case signerPubkey != "":
+		k.pubkey = signerPubkey
+	default:
+		return previousKey{}, errors.New("public_key or signer must be set")
+	}
+	return k, nil
+}
+
+// cosigners returns the previous keys which still sign checkpoints at t
+func (a *API) cosigners(t time.Time) []signature.Signer {
+	var signers []signature.Signer
+	for _, k := range a.previousKeys {
+		if k.signer != nil && t.Before(k.validUntil) {
+			signers = append(signers, k.signer)
+		}
+	}
+	return signers
+}
+
+// logKeys lists the current key of the log followed by its previous keys
+func (a *API) logKeys() verify.LogKeys {
+	current := verify.LogKey{PublicKey: a.pubkey, LogID: a.pubkeyHash}
+	if !a.signerValidFrom.IsZero() {
+		validFrom := a.signerValidFrom
+		current.ValidFrom = &validFrom
+	}
+	lk := verify.LogKeys{Keys: []verify.LogKey{current}}
+	for _, k := range a.previousKeys {
+		key := verify.LogKey{PublicKey: k.pubkey, LogID: k.pubkeyHash, Revoked: k.revoked}
+		validFrom, validUntil := k.validFrom, k.validUntil
+		if !validFrom.IsZero() {
+			key.ValidFrom = &validFrom
+		}
+		key.ValidUntil = &validUntil
+		lk.Keys = append(lk.Keys, key)
+	}
+	return lk
+}
+
+// auditKeyRotation records the keys the log is configured to sign with, if any previous keys are
+// configured, so that the audit log shows when each rotation took effect
+func (a *API) auditKeyRotation() {
+	if len(a.previousKeys) == 0 {
+		return
+	}
+	var previous []string
+	for _, k := range a.previousKeys {
+		state := "verification only"
+		switch {
+		case k.revoked:
+			state = "revoked"
+		case k.signer != nil:
+			state = "co-signing checkpoints"
+		}
+		previous = append(previous, k.pubkeyHash+" until "+k.validUntil.UTC().Format(time.RFC3339)+" ("+state+")")
+	}
+	writeAudit(audit.Record{
+		Action:  audit.ActionRotateKey,
+		Outcome: audit.OutcomeSuccess,
+		TreeID:  a.logID,
+		Detail:  "signing with " + a.pubkeyHash + "; previous keys " + strings.Join(previous, ", "),
+	})
+}
+
+// ServeKeySet serves the keys the log signs and signed with, and when each of them was in use, at
+// verify.KeySetPath so that clients can verify checkpoints and entries signed before a rotation of the
+// signing key; other requests are passed on to handler
+func ServeKeySet(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != verify.KeySetPath {
+			handler.ServeHTTP(w, r)
+			return
+		}
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			writeErrorResponse(w, http.StatusMethodNotAllowed, "")
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", cacheRevalidate)
+		w.WriteHeader(http.StatusOK)
+		if r.Method == http.MethodGet {
+			_ = json.NewEncoder(w).Encode(logAPI(r.Context()).logKeys())
+		}
+	})
+}
@@ -0,0 +1,199 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sigstore/rekor/pkg/signer"
+	"github.com/sigstore/rekor/pkg/verify"
+)
+
+func TestLoadKeySet(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	retired, err := signer.NewMemory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	retiredPEM, retiredHash, err := signerPublicKey(ctx, retired)
+	if err != nil {
+		t.Fatal(err)
+	}
+	retiredPath := filepath.Join(dir, "retired.pub")
+	if err := ioutil.WriteFile(retiredPath, []byte(retiredPEM), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		name    string
+		config  string
+		wantErr bool
+	}{
+		{
+			name: "valid",
+			config: `signer_valid_from: 2026-10-01T00:00:00Z
+previous:
+- signer: memory
+  valid_from: 2025-10-01T00:00:00Z
+  valid_until: 2026-11-01T00:00:00Z
+- public_key: ` + retiredPath + `
+  valid_until: 2025-10-01T00:00:00Z
+  revoked: true
+`,
+		},
+		{
+			name:    "missing valid_until",
+			config:  "previous:\n- signer: memory\n",
+			wantErr: true,
+		},
+		{
+			name:    "window ends before it starts",
+			config:  "previous:\n- signer: memory\n  valid_from: 2026-01-01T00:00:00Z\n  valid_until: 2025-01-01T00:00:00Z\n",
+			wantErr: true,
+		},
+		{
+			name:    "revoked key signing",
+			config:  "previous:\n- signer: memory\n  valid_until: 2025-01-01T00:00:00Z\n  revoked: true\n",
+			wantErr: true,
+		},
+		{
+			name:    "missing key",
+			config:  "previous:\n- valid_until: 2025-01-01T00:00:00Z\n",
+			wantErr: true,
+		},
+		{
+			name:    "public key of another signer",
+			config:  "previous:\n- signer: memory\n  public_key: " + retiredPath + "\n  valid_until: 2025-01-01T00:00:00Z\n",
+			wantErr: true,
+		},
+		{
+			name:    "listed twice",
+			config:  "previous:\n- public_key: " + retiredPath + "\n  valid_until: 2025-01-01T00:00:00Z\n- public_key: " + retiredPath + "\n  valid_until: 2024-01-01T00:00:00Z\n",
+			wantErr: true,
+		},
+		{
+			name:    "current key",
+			config:  "previous:\n- public_key: " + retiredPath + "\n  valid_until: 2025-01-01T00:00:00Z\n",
+			wantErr: true,
+		},
+		{
+			name:    "unknown field",
+			config:  "previous:\n- signer: memory\n  valid_until: 2025-01-01T00:00:00Z\n  expires: 2025-01-01T00:00:00Z\n",
+			wantErr: true,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "keys.yaml")
+			if err := ioutil.WriteFile(path, []byte(tt.config), 0600); err != nil {
+				t.Fatal(err)
+			}
+			currentHash := "0000"
+			if tt.name == "current key" {
+				currentHash = retiredHash
+			}
+			keys, validFrom, err := loadKeySet(ctx, path, currentHash)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("loadKeySet() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if !validFrom.Equal(time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)) {
+				t.Errorf("unexpected signer_valid_from %v", validFrom)
+			}
+			if len(keys) != 2 || keys[0].signer == nil || keys[1].signer != nil || keys[1].pubkeyHash != retiredHash ||
+				keys[0].revoked || !keys[1].revoked {
+				t.Fatalf("unexpected keys %+v", keys)
+			}
+
+			a := &API{previousKeys: keys}
+			if got := a.cosigners(time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC)); len(got) != 1 {
+				t.Errorf("got %d co-signers during the overlap, want 1", len(got))
+			}
+			if got := a.cosigners(time.Date(2026, 11, 2, 0, 0, 0, 0, time.UTC)); len(got) != 0 {
+				t.Errorf("got %d co-signers after the overlap, want 0", len(got))
+			}
+		})
+	}
+}
+
+func TestServeKeySet(t *testing.T) {
+	ctx := context.Background()
+	current, err := signer.NewMemory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pubkey, pubkeyHash, err := signerPublicKey(ctx, current)
+	if err != nil {
+		t.Fatal(err)
+	}
+	previous, err := signer.NewMemory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	previousPEM, previousHash, err := signerPublicKey(ctx, previous)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rotated := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	oldAPI := api
+	api = &API{
+		pubkey:          pubkey,
+		pubkeyHash:      pubkeyHash,
+		signerValidFrom: rotated,
+		previousKeys:    []previousKey{{pubkey: previousPEM, pubkeyHash: previousHash, validUntil: rotated, revoked: true}},
+	}
+	t.Cleanup(func() {
+		api = oldAPI
+	})
+
+	handler := ServeKeySet(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, verify.KeySetPath, nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("got status %d, want %d", rec.Code, http.StatusOK)
+	}
+	lk := verify.LogKeys{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &lk); err != nil {
+		t.Fatal(err)
+	}
+	if len(lk.Keys) != 2 || lk.Keys[0].LogID != pubkeyHash || lk.Keys[0].ValidUntil != nil || !lk.Keys[0].ValidFrom.Equal(rotated) ||
+		lk.Keys[1].LogID != previousHash || !lk.Keys[1].ValidUntil.Equal(rotated) || lk.Keys[1].ValidFrom != nil ||
+		lk.Keys[0].Revoked || !lk.Keys[1].Revoked {
+		t.Errorf("unexpected key set %+v", lk)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, verify.KeySetPath, nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("POST: got status %d, want %d", rec.Code, http.StatusMethodNotAllowed)
+	}
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/log", nil))
+	if rec.Code != http.StatusTeapot {
+		t.Errorf("other paths: got status %d, want %d", rec.Code, http.StatusTeapot)
+	}
+}
@@ -0,0 +1,232 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/pkg/errors"
+
+	"github.com/sigstore/rekor/pkg/admission"
+	"github.com/sigstore/rekor/pkg/log"
+	"github.com/sigstore/rekor/pkg/signer"
+)
+
+// LogsPath is the prefix under which the additional logs hosted by this instance are served, e.g.
+// /logs/<name>/api/v1/log/entries
+const LogsPath = "/logs/"
+
+var logNameRegex = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,62}$`)
+
+// logs holds the additional logs hosted by this instance keyed by name; the default log is kept in api
+var logs map[string]*API
+
+// logConfig describes an additional log hosted by this instance
+type logConfig struct {
+	TreeID int64                 `json:"tree_id"`
+	Signer string                `json:"signer"`
+	Policy admissionPolicyConfig `json:"policy"`
+}
+
+type logsConfig struct {
+	Logs map[string]logConfig `json:"logs"`
+}
+
+// readLogsConfig parses and validates the YAML file describing the additional logs
+func readLogsConfig(path string) (map[string]logConfig, error) {
+	contents, err := ioutil.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, err
+	}
+	j, err := yaml.YAMLToJSON(contents)
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing logs config")
+	}
+	dec := json.NewDecoder(bytes.NewReader(j))
+	dec.DisallowUnknownFields()
+	cfg := logsConfig{}
+	if err := dec.Decode(&cfg); err != nil {
+		return nil, errors.Wrap(err, "parsing logs config")
+	}
+
+	treeIDs := map[int64]string{}
+	for name, c := range cfg.Logs {
+		if !logNameRegex.MatchString(name) {
+			return nil, errors.Errorf("invalid log name %q: must be lowercase alphanumeric characters or '-' and at most 63 characters long", name)
+		}
+		if c.TreeID == 0 {
+			return nil, errors.Errorf("log %q: tree_id must be specified", name)
+		}
+		if other, ok := treeIDs[c.TreeID]; ok {
+			return nil, errors.Errorf("logs %q and %q both use tree %d", other, name, c.TreeID)
+		}
+		treeIDs[c.TreeID] = name
+		if c.Signer == "" {
+			return nil, errors.Errorf("log %q: signer must be specified", name)
+		}
+	}
+	return cfg.Logs, nil
+}
+
+// newLogs creates the additional logs described in the file at path; they share the connection to
+// trillian and the timestamping configuration of the default log
+func newLogs(ctx context.Context, base *API, path string) (map[string]*API, error) {
+	cfgs, err := readLogsConfig(path)
+	if err != nil {
+		return nil, err
+	}
+
+	result := map[string]*API{}
+	for name, c := range cfgs {
+		if c.TreeID == base.logID {
+			return nil, errors.Errorf("log %q: tree %d is already used by the default log", name, c.TreeID)
+		}
+		s, err := signer.New(ctx, c.Signer)
+		if err != nil {
+			return nil, errors.Wrapf(err, "log %q: getting new signer", name)
+		}
+		pubkey, pubkeyHash, err := signerPublicKey(ctx, s)
+		if err != nil {
+			return nil, errors.Wrapf(err, "log %q", name)
+		}
+		policy, err := c.Policy.policy()
+		if err != nil {
+			return nil, errors.Wrapf(err, "log %q: policy", name)
+		}
+		// checkpoints of the log must not be mistaken for those of the other logs of the instance
+		checkpointName := base.signerName + LogsPath + name
+		result[name] = &API{
+			logClient:  base.logClient,
+			logID:      c.TreeID,
+			rpcTimeout: base.rpcTimeout,
+			logRanges: &LogRanges{
+				Ranges: []LogRange{{TreeID: uint64(c.TreeID)}},
+			},
+			pubkey:             pubkey,
+			pubkeyHash:         pubkeyHash,
+			signer:             s,
+			checkpointOrigin:   checkpointName,
+			signerName:         checkpointName,
+			tsaSigner:          base.tsaSigner,
+			certChain:          base.certChain,
+			certChainPem:       base.certChainPem,
+			indexPrefix:        name + "/",
+			admissionPolicy:    policy,
+			indexedAnnotations: base.indexedAnnotations,
+			maxPageSize:        base.maxPageSize,
+		}
+	}
+	return result, nil
+}
+
+// logPoliciesFromConfig builds the admission policies of the additional logs in the file at path
+// which are already being served; other changes to the file are logged and ignored
+func logPoliciesFromConfig(path string) (map[string]*admission.Policy, error) {
+	if path == "" {
+		return nil, nil
+	}
+	cfgs, err := readLogsConfig(path)
+	if err != nil {
+		return nil, err
+	}
+	result := map[string]*admission.Policy{}
+	for name, c := range cfgs {
+		l, ok := logs[name]
+		if !ok || l.logID != c.TreeID {
+			log.Logger.Warnf("changes to log %q require a restart to take effect", name)
+			continue
+		}
+		if result[name], err = c.Policy.policy(); err != nil {
+			return nil, errors.Wrapf(err, "log %q: policy", name)
+		}
+	}
+	for name := range logs {
+		if _, ok := cfgs[name]; !ok {
+			log.Logger.Warnf("removal of log %q requires a restart to take effect", name)
+		}
+	}
+	return result, nil
+}
+
+type logContextKey struct{}
+
+// logContext identifies the log addressed by a request
+type logContext struct {
+	api *API
+	// path prefix removed from the request URL before routing, e.g. /logs/<name>
+	prefix string
+}
+
+// logAPI returns the log addressed by the request with the given context
+func logAPI(ctx context.Context) *API {
+	if lc, ok := ctx.Value(logContextKey{}).(*logContext); ok {
+		return lc.api
+	}
+	return api
+}
+
+// requestURL returns the URL of a request as sent by the client, i.e. including the prefix of the log it addresses
+func requestURL(r *http.Request) url.URL {
+	u := *r.URL
+	if lc, ok := r.Context().Value(logContextKey{}).(*logContext); ok {
+		u.Path = lc.prefix + u.Path
+		u.RawPath = ""
+	}
+	return u
+}
+
+// RouteLogs serves requests under /logs/<name>/ from the additional log with that name by removing
+// the prefix from the request path; other requests are passed on to handler unchanged
+func RouteLogs(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.URL.Path, LogsPath) {
+			handler.ServeHTTP(w, r)
+			return
+		}
+		rest := strings.TrimPrefix(r.URL.Path, LogsPath)
+		name := rest
+		if i := strings.Index(rest, "/"); i >= 0 {
+			name = rest[:i]
+		}
+		l, ok := logs[name]
+		if !ok {
+			writeErrorResponse(w, http.StatusNotFound, "No log with the requested name is hosted on this server")
+			return
+		}
+
+		prefix := LogsPath + name
+		u := *r.URL
+		u.Path = strings.TrimPrefix(r.URL.Path, prefix)
+		if u.Path == "" {
+			u.Path = "/"
+		}
+		u.RawPath = ""
+		ctx := context.WithValue(r.Context(), logContextKey{}, &logContext{api: l, prefix: prefix})
+		r = r.WithContext(ctx)
+		r.URL = &u
+		r.RequestURI = u.RequestURI()
+		handler.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,179 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/trillian/types"
+)
+
+func TestReadLogsConfig(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		config  string
+		wantErr bool
+	}{
+		{
+			name: "valid",
+			config: `logs:
+  team-a:
+    tree_id: 1
+    signer: memory
+  team-b:
+    tree_id: 2
+    signer: memory
+    policy:
+      allowed_types: [rekord]
+      min_rsa_key_bits: 2048
+`,
+		},
+		{
+			name:    "invalid name",
+			config:  "logs:\n  Team_A:\n    tree_id: 1\n    signer: memory\n",
+			wantErr: true,
+		},
+		{
+			name:    "missing tree",
+			config:  "logs:\n  team-a:\n    signer: memory\n",
+			wantErr: true,
+		},
+		{
+			name:    "missing signer",
+			config:  "logs:\n  team-a:\n    tree_id: 1\n",
+			wantErr: true,
+		},
+		{
+			name:    "shared tree",
+			config:  "logs:\n  team-a:\n    tree_id: 1\n    signer: memory\n  team-b:\n    tree_id: 1\n    signer: memory\n",
+			wantErr: true,
+		},
+		{
+			name:    "unknown field",
+			config:  "logs:\n  team-a:\n    tree_id: 1\n    signer: memory\n    treeid: 2\n",
+			wantErr: true,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "logs.yaml")
+			if err := ioutil.WriteFile(path, []byte(tt.config), 0600); err != nil {
+				t.Fatal(err)
+			}
+			cfgs, err := readLogsConfig(path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("readLogsConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if len(cfgs) != 2 || cfgs["team-b"].TreeID != 2 || cfgs["team-b"].Policy.MinRSAKeyBits != 2048 {
+				t.Errorf("unexpected config %+v", cfgs)
+			}
+			if _, err := cfgs["team-b"].Policy.policy(); err != nil {
+				t.Errorf("unexpected policy error: %v", err)
+			}
+		})
+	}
+}
+
+func TestNewLogsCheckpoints(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs.yaml")
+	config := "logs:\n  team-a:\n    tree_id: 2\n    signer: memory\n  team-b:\n    tree_id: 3\n    signer: memory\n"
+	if err := ioutil.WriteFile(path, []byte(config), 0600); err != nil {
+		t.Fatal(err)
+	}
+	base := &API{logID: 1, checkpointOrigin: "Rekor", signerName: "rekor.example.com"}
+	tenants, err := newLogs(context.Background(), base, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	root := &types.LogRootV1{TreeSize: 1, RootHash: []byte("bananas")}
+	origins := map[string]string{}
+	for name, l := range tenants {
+		sth, _, err := l.signCheckpoint(context.Background(), root)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := "rekor.example.com/logs/" + name; sth.Origin != want || len(sth.Signatures) != 1 || sth.Signatures[0].Name != want {
+			t.Errorf("log %v signed checkpoint with origin %q and signatures %+v, want %q", name, sth.Origin, sth.Signatures, want)
+		}
+		if other, ok := origins[sth.Origin]; ok {
+			t.Errorf("logs %v and %v sign checkpoints with the same origin %q", other, name, sth.Origin)
+		}
+		origins[sth.Origin] = name
+	}
+	if len(origins) != 2 || origins[base.checkpointOrigin] != "" {
+		t.Errorf("unexpected origins %v", origins)
+	}
+}
+
+func TestRouteLogs(t *testing.T) {
+	defaultLog := &API{logID: 1}
+	teamA := &API{logID: 2, indexPrefix: "team-a/"}
+	oldAPI, oldLogs := api, logs
+	api, logs = defaultLog, map[string]*API{"team-a": teamA}
+	t.Cleanup(func() {
+		api, logs = oldAPI, oldLogs
+	})
+
+	var gotAPI *API
+	var gotPath, gotURL string
+	handler := RouteLogs(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAPI = logAPI(r.Context())
+		gotPath = r.URL.Path
+		u := requestURL(r)
+		gotURL = u.String()
+		w.WriteHeader(http.StatusTeapot)
+	}))
+
+	for _, tt := range []struct {
+		path     string
+		wantCode int
+		wantAPI  *API
+		wantPath string
+		wantURL  string
+	}{
+		{path: "/api/v1/log", wantCode: http.StatusTeapot, wantAPI: defaultLog, wantPath: "/api/v1/log", wantURL: "/api/v1/log"},
+		{path: "/logs/team-a/api/v1/log/entries?apiKey=x", wantCode: http.StatusTeapot, wantAPI: teamA, wantPath: "/api/v1/log/entries", wantURL: "/logs/team-a/api/v1/log/entries?apiKey=x"},
+		{path: "/logs/team-a", wantCode: http.StatusTeapot, wantAPI: teamA, wantPath: "/", wantURL: "/logs/team-a/"},
+		{path: "/logs/team-b/api/v1/log", wantCode: http.StatusNotFound},
+		{path: "/logs/", wantCode: http.StatusNotFound},
+	} {
+		t.Run(tt.path, func(t *testing.T) {
+			gotAPI, gotPath, gotURL = nil, "", ""
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			if rec.Code != tt.wantCode {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantCode)
+			}
+			if gotAPI != tt.wantAPI {
+				t.Errorf("log = %+v, want %+v", gotAPI, tt.wantAPI)
+			}
+			if gotPath != tt.wantPath {
+				t.Errorf("path = %q, want %q", gotPath, tt.wantPath)
+			}
+			if gotURL != tt.wantURL {
+				t.Errorf("request URL = %q, want %q", gotURL, tt.wantURL)
+			}
+		})
+	}
+}
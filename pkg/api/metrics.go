@@ -30,4 +30,9 @@ var (
 		Name: "rekor_api_latency",
 		Help: "Api Latency on calls",
 	}, []string{"path", "code"})
+
+	metricInjectedFaults = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "rekor_injected_faults",
+		Help: "The total number of faults injected into calls to the log backend",
+	}, []string{"method", "fault"})
 )
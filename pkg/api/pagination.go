@@ -0,0 +1,158 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+// defaultMaxPageSize is the number of results served per page of a search unless configured otherwise
+const defaultMaxPageSize = 1000
+
+// paginationConfig holds the pagination.* settings
+type paginationConfig struct {
+	// largest number of results a page of a search may hold
+	MaxPageSize int `mapstructure:"max_page_size"`
+}
+
+func (c paginationConfig) validate() error {
+	if c.MaxPageSize < 0 {
+		return errors.New("max_page_size must not be negative")
+	}
+	return nil
+}
+
+func (c paginationConfig) maxPageSize() int {
+	if c.MaxPageSize == 0 {
+		return defaultMaxPageSize
+	}
+	return c.MaxPageSize
+}
+
+// pageToken records where a page of a search ended. It is handed to clients encoded as an opaque
+// string, and only continues the search it was issued for.
+type pageToken struct {
+	// digest of the query of the search
+	Query string `json:"q"`
+	// number of items of the query covered by the previous pages
+	Offset int `json:"o,omitempty"`
+	// last result of the previous page, for results served in ascending order
+	After string `json:"a,omitempty"`
+	// time the last result of the previous page was integrated, for results served in the order of time
+	AfterTime int64 `json:"t,omitempty"`
+}
+
+// queryDigest returns the digest identifying a query in the page tokens issued for it
+func queryDigest(query interface{}) (string, error) {
+	b, err := json.Marshal(query)
+	if err != nil {
+		return "", err
+	}
+	digest := sha256.Sum256(b)
+	return hex.EncodeToString(digest[:16]), nil
+}
+
+func (t pageToken) encode() string {
+	b, _ := json.Marshal(t)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// decodePageToken decodes a token given by a client, which must have been issued for the query
+// with the given digest; an empty token starts at the first page
+func decodePageToken(s *string, digest string) (pageToken, error) {
+	if s == nil || *s == "" {
+		return pageToken{Query: digest}, nil
+	}
+	b, err := base64.RawURLEncoding.DecodeString(*s)
+	if err != nil {
+		return pageToken{}, errors.New("malformed page token")
+	}
+	t := pageToken{}
+	if err := json.Unmarshal(b, &t); err != nil || t.Offset < 0 {
+		return pageToken{}, errors.New("malformed page token")
+	}
+	if t.Query != digest {
+		return pageToken{}, errors.New("page token was issued for a different query")
+	}
+	return t, nil
+}
+
+// pageSize returns the number of results to serve for the size requested by a client, which is
+// capped at the maximum page size of the server
+func (a *API) pageSize(requested *int64) int {
+	max := a.maxPageSize
+	if max <= 0 {
+		max = defaultMaxPageSize
+	}
+	if requested == nil || *requested <= 0 || *requested > int64(max) {
+		return max
+	}
+	return int(*requested)
+}
+
+// pageSorted returns the page of values following the one t ended with, in ascending order and
+// without duplicates, and the token of the next page, which is empty if this is the last one
+func pageSorted(values []string, t pageToken, size int) ([]string, string) {
+	sorted := make([]string, 0, len(values))
+	seen := map[string]bool{}
+	for _, v := range values {
+		if !seen[v] && v > t.After {
+			seen[v] = true
+			sorted = append(sorted, v)
+		}
+	}
+	sort.Strings(sorted)
+	if len(sorted) <= size {
+		return sorted, ""
+	}
+	page := sorted[:size]
+	return page, pageToken{Query: t.Query, After: page[size-1]}.encode()
+}
+
+// pageRange returns the bounds of the page of n items following the ones t covered, and the token
+// of the next page, which is empty if this is the last one
+func pageRange(n int, t pageToken, size int) (int, int, string) {
+	start := t.Offset
+	if start > n {
+		start = n
+	}
+	end := start + size
+	if end >= n {
+		return start, n, ""
+	}
+	return start, end, pageToken{Query: t.Query, Offset: end}.encode()
+}
+
+// window returns the bounds within a list of n items, which follow offset items of a query, of the
+// part of the list between the items first and last of the query
+func window(first, last, offset, n int) (int, int) {
+	clamp := func(i int) int {
+		if i < 0 {
+			return 0
+		}
+		if i > n {
+			return n
+		}
+		return i
+	}
+	return clamp(first - offset), clamp(last - offset)
+}
@@ -0,0 +1,104 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestPageSorted(t *testing.T) {
+	values := []string{"d", "b", "e", "a", "b", "c"}
+	var pages []string
+	token := pageToken{Query: "q"}
+	for i := 0; i < 5; i++ {
+		page, next := pageSorted(values, token, 2)
+		pages = append(pages, fmt.Sprint(page))
+		if next == "" {
+			break
+		}
+		var err error
+		if token, err = decodePageToken(&next, "q"); err != nil {
+			t.Fatal(err)
+		}
+		// entries added while paging do not shift later pages
+		values = append(values, "0")
+	}
+	if fmt.Sprint(pages) != "[[a b] [c d] [e]]" {
+		t.Errorf("got pages %v", pages)
+	}
+}
+
+func TestPageRange(t *testing.T) {
+	var ranges []string
+	token := pageToken{Query: "q"}
+	for i := 0; i < 5; i++ {
+		first, last, next := pageRange(5, token, 2)
+		ranges = append(ranges, fmt.Sprintf("%d-%d", first, last))
+		if next == "" {
+			break
+		}
+		var err error
+		if token, err = decodePageToken(&next, "q"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if fmt.Sprint(ranges) != "[0-2 2-4 4-5]" {
+		t.Errorf("got ranges %v", ranges)
+	}
+
+	// a query made of 2 UUIDs, 1 entry and 3 log indexes, paged from its second to its fifth item
+	for _, tt := range []struct{ offset, n, lo, hi int }{{0, 2, 1, 2}, {2, 1, 0, 1}, {3, 3, 0, 1}} {
+		if lo, hi := window(1, 4, tt.offset, tt.n); lo != tt.lo || hi != tt.hi {
+			t.Errorf("window(1, 4, %d, %d) = %d, %d, want %d, %d", tt.offset, tt.n, lo, hi, tt.lo, tt.hi)
+		}
+	}
+}
+
+func TestPageTokens(t *testing.T) {
+	digest, err := queryDigest(map[string]string{"hash": "abc"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := queryDigest(map[string]string{"hash": "def"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	token := pageToken{Query: digest, Offset: 3}.encode()
+	if got, err := decodePageToken(&token, digest); err != nil || got.Offset != 3 {
+		t.Errorf("decodePageToken() = %v, %v", got, err)
+	}
+	if _, err := decodePageToken(&token, other); err == nil {
+		t.Error("expected token of another query to be rejected")
+	}
+	for _, invalid := range []string{"not a token!", "bm90IGpzb24"} {
+		invalid := invalid
+		if _, err := decodePageToken(&invalid, digest); err == nil {
+			t.Errorf("expected %q to be rejected", invalid)
+		}
+	}
+
+	a := &API{maxPageSize: 10}
+	for requested, want := range map[int64]int{0: 10, 5: 5, 10: 10, 50: 10} {
+		requested := requested
+		if got := a.pageSize(&requested); got != want {
+			t.Errorf("pageSize(%d) = %d, want %d", requested, got, want)
+		}
+	}
+	if got := a.pageSize(nil); got != 10 {
+		t.Errorf("pageSize(nil) = %d, want 10", got)
+	}
+}
@@ -0,0 +1,117 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-openapi/errors"
+	"github.com/go-openapi/runtime"
+)
+
+// problemContentType is the media type of RFC 7807 problem details
+const problemContentType = "application/problem+json"
+
+// ServeProblems serves the error responses of the API, which are RFC 7807 problem details, with the
+// application/problem+json media type to clients which accept it. Clients generated from the API
+// definition only accept application/json and keep receiving the same documents as application/json.
+func ServeProblems(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !acceptsProblem(r) {
+			handler.ServeHTTP(w, r)
+			return
+		}
+		handler.ServeHTTP(&problemResponseWriter{ResponseWriter: w}, r)
+	})
+}
+
+// acceptsProblem returns whether the Accept header of r allows problem details to be served as
+// application/problem+json
+func acceptsProblem(r *http.Request) bool {
+	accept := strings.Join(r.Header.Values("Accept"), ",")
+	if strings.TrimSpace(accept) == "" {
+		return true
+	}
+	for _, value := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(value)
+		if err != nil {
+			continue
+		}
+		if q, err := strconv.ParseFloat(params["q"], 64); err == nil && q == 0 {
+			continue
+		}
+		switch mediaType {
+		case problemContentType, "application/*", "*/*":
+			return true
+		}
+	}
+	return false
+}
+
+// problemResponseWriter changes the media type of JSON error responses to application/problem+json
+type problemResponseWriter struct {
+	http.ResponseWriter
+}
+
+func (pw *problemResponseWriter) WriteHeader(code int) {
+	if code >= http.StatusBadRequest {
+		h := pw.Header()
+		if mediaType, _, err := mime.ParseMediaType(h.Get("Content-Type")); err == nil && mediaType == runtime.JSONMime {
+			h.Set("Content-Type", problemContentType)
+		}
+	}
+	pw.ResponseWriter.WriteHeader(code)
+}
+
+// Flush sends any buffered data to the client, e.g. for streamed responses
+func (pw *problemResponseWriter) Flush() {
+	if f, ok := pw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// ServeError serves the errors raised by the generated server, e.g. for requests which do not match
+// the API definition, as problem details
+func ServeError(w http.ResponseWriter, r *http.Request, err error) {
+	// like the generated server, only report the first of several errors
+	for {
+		ce, ok := err.(*errors.CompositeError)
+		if !ok || len(ce.Errors) == 0 {
+			break
+		}
+		err = ce.Errors[0]
+	}
+
+	code, message := http.StatusInternalServerError, ""
+	switch e := err.(type) {
+	case nil:
+	case *errors.MethodNotAllowedError:
+		w.Header().Add("Allow", strings.Join(e.Allowed, ","))
+		code, message = int(e.Code()), e.Error()
+	case errors.Error:
+		code, message = int(e.Code()), e.Error()
+	default:
+		message = err.Error()
+	}
+	// validation errors carry codes outside of the range of HTTP status codes
+	if code >= 600 {
+		code = http.StatusUnprocessableEntity
+	}
+	writeErrorResponse(w, code, message)
+}
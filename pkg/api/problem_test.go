@@ -0,0 +1,145 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-openapi/errors"
+
+	"github.com/sigstore/rekor/pkg/generated/models"
+)
+
+func TestErrorCode(t *testing.T) {
+	tests := []struct {
+		code    int
+		message string
+		want    models.ErrorCode
+	}{
+		{http.StatusBadRequest, "", models.ErrorCodeValidationFailed},
+		{http.StatusRequestEntityTooLarge, "", models.ErrorCodeValidationFailed},
+		{http.StatusUnauthorized, "", models.ErrorCodeUnauthorized},
+		{http.StatusNotFound, "", models.ErrorCodeNotFound},
+		{http.StatusConflict, "", models.ErrorCodeDuplicateEntry},
+		{http.StatusTooManyRequests, "", models.ErrorCodeRateLimited},
+		{http.StatusInternalServerError, trillianCommunicationError, models.ErrorCodeBackendUnavailable},
+		{http.StatusInternalServerError, redisUnexpectedResult, models.ErrorCodeBackendUnavailable},
+		{http.StatusInternalServerError, signingError, models.ErrorCodeInternalError},
+		{http.StatusNotImplemented, "", models.ErrorCodeNotImplemented},
+		{http.StatusServiceUnavailable, "", models.ErrorCodeBackendUnavailable},
+	}
+	for _, tt := range tests {
+		if got := errorCode(tt.code, tt.message); got != tt.want {
+			t.Errorf("errorCode(%d, %q) = %q, want %q", tt.code, tt.message, got, tt.want)
+		}
+	}
+}
+
+func TestServeProblems(t *testing.T) {
+	handler := ServeProblems(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/ok" {
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte("{}"))
+			return
+		}
+		writeErrorResponse(w, http.StatusBadRequest, "bad request")
+	}))
+
+	tests := []struct {
+		name   string
+		path   string
+		accept []string
+		want   string
+	}{
+		{name: "no accept header", path: "/error", want: problemContentType},
+		{name: "any media type", path: "/error", accept: []string{"*/*"}, want: problemContentType},
+		{name: "problem details", path: "/error", accept: []string{"application/json", problemContentType}, want: problemContentType},
+		{name: "generated client", path: "/error", accept: []string{"application/json"}, want: "application/json"},
+		{name: "problem details refused", path: "/error", accept: []string{"application/json, application/problem+json;q=0"}, want: "application/json"},
+		{name: "successful response", path: "/ok", accept: []string{"*/*"}, want: "application/json"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			for _, a := range tt.accept {
+				req.Header.Add("Accept", a)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			if got := rec.Header().Get("Content-Type"); got != tt.want {
+				t.Errorf("Content-Type = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestServeError(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantCode   models.ErrorCode
+		wantAllow  string
+	}{
+		{
+			name:       "validation",
+			err:        errors.CompositeValidationError(errors.Required("proposedEntry", "body", nil)),
+			wantStatus: http.StatusUnprocessableEntity,
+			wantCode:   models.ErrorCodeValidationFailed,
+		},
+		{
+			name:       "method not allowed",
+			err:        errors.MethodNotAllowed(http.MethodDelete, []string{http.MethodGet, http.MethodPost}),
+			wantStatus: http.StatusMethodNotAllowed,
+			wantCode:   models.ErrorCodeValidationFailed,
+			wantAllow:  "GET,POST",
+		},
+		{
+			name:       "not found",
+			err:        errors.NotFound("path %s was not found", "/api/v1/foo"),
+			wantStatus: http.StatusNotFound,
+			wantCode:   models.ErrorCodeNotFound,
+		},
+		{
+			name:       "unknown",
+			err:        nil,
+			wantStatus: http.StatusInternalServerError,
+			wantCode:   models.ErrorCodeInternalError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			ServeError(rec, httptest.NewRequest(http.MethodGet, "/", nil), tt.err)
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if got := rec.Header().Get("Allow"); got != tt.wantAllow {
+				t.Errorf("Allow = %q, want %q", got, tt.wantAllow)
+			}
+			problem := models.Error{}
+			if err := json.Unmarshal(rec.Body.Bytes(), &problem); err != nil {
+				t.Fatal(err)
+			}
+			if problem.Status != int64(tt.wantStatus) || problem.ErrorCode != tt.wantCode || problem.Detail == "" || problem.Detail != problem.Message {
+				t.Errorf("unexpected problem details %+v", problem)
+			}
+		})
+	}
+}
@@ -22,5 +22,5 @@ import (
 )
 
 func GetPublicKeyHandler(params pubkey.GetPublicKeyParams) middleware.Responder {
-	return pubkey.NewGetPublicKeyOK().WithPayload(api.pubkey)
+	return pubkey.NewGetPublicKeyOK().WithPayload(logAPI(params.HTTPRequest.Context()).pubkey)
 }
@@ -15,6 +15,12 @@
 
 package api
 
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
 type LogRanges struct {
 	Ranges []LogRange
 }
@@ -41,3 +47,44 @@ func (l *LogRanges) ResolveVirtualIndex(index int) (uint64, uint64) {
 func (l *LogRanges) ActiveIndex() uint64 {
 	return l.Ranges[len(l.Ranges)-1].TreeID
 }
+
+// Frozen returns the shards before the active one, which no longer accept entries
+func (l *LogRanges) Frozen() []LogRange {
+	if len(l.Ranges) == 0 {
+		return nil
+	}
+	return l.Ranges[:len(l.Ranges)-1]
+}
+
+// ActiveOffset returns the virtual index of the first entry of the active shard
+func (l *LogRanges) ActiveOffset() uint64 {
+	var offset uint64
+	for _, r := range l.Frozen() {
+		offset += r.TreeLength
+	}
+	return offset
+}
+
+// Offset returns the virtual index of the first entry of the shard with the given tree ID
+func (l *LogRanges) Offset(treeID uint64) (uint64, bool) {
+	var offset uint64
+	for _, r := range l.Ranges {
+		if r.TreeID == treeID {
+			return offset, true
+		}
+		offset += r.TreeLength
+	}
+	return 0, false
+}
+
+// String formats the ranges as the --trillian_log_server.log_id_ranges flag expects them
+func (l *LogRanges) String() string {
+	ranges := []string{}
+	for _, r := range l.Frozen() {
+		ranges = append(ranges, fmt.Sprintf("%d=%d", r.TreeID, r.TreeLength))
+	}
+	if len(l.Ranges) > 0 {
+		ranges = append(ranges, strconv.FormatUint(l.ActiveIndex(), 10))
+	}
+	return strings.Join(ranges, ",")
+}
@@ -56,3 +56,29 @@ func TestLogRanges_ResolveVirtualIndex(t *testing.T) {
 		}
 	}
 }
+
+func TestLogRanges_Shards(t *testing.T) {
+	lrs := LogRanges{
+		Ranges: []LogRange{
+			{TreeID: 1, TreeLength: 17},
+			{TreeID: 2, TreeLength: 1},
+			{TreeID: 3},
+		},
+	}
+	if got := lrs.ActiveOffset(); got != 18 {
+		t.Errorf("LogRanges.ActiveOffset() = %v, want 18", got)
+	}
+	if offset, ok := lrs.Offset(2); !ok || offset != 17 {
+		t.Errorf("LogRanges.Offset(2) = %v, %v, want 17", offset, ok)
+	}
+	if _, ok := lrs.Offset(4); ok {
+		t.Error("LogRanges.Offset(4) found a shard which is not in the ranges")
+	}
+	if got := len(lrs.Frozen()); got != 2 {
+		t.Errorf("LogRanges.Frozen() returned %d shards, want 2", got)
+	}
+	// formatted as the flag is parsed
+	if got := lrs.String(); got != "1=17,2=1,3" {
+		t.Errorf("LogRanges.String() = %v", got)
+	}
+}
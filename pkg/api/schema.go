@@ -0,0 +1,158 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/go-openapi/spec"
+	"github.com/go-openapi/strfmt"
+	"github.com/go-openapi/validate"
+)
+
+// EntrySchemas validates the specs of proposed entries against the schemas published for their kind
+// and version. The generated server only validates the parts of requests common to all kinds; the
+// spec of an entry is otherwise first checked when the handler unmarshals it.
+type EntrySchemas struct {
+	schemas map[string]*spec.Schema
+}
+
+// the name of the definition of the spec of each kind and version in the flattened API definition,
+// e.g. hashedrekordV001Schema
+var specDefinitionRE = regexp.MustCompile(`^([a-z0-9]+)V(\d+)Schema$`)
+
+// NewEntrySchemas loads the schemas of the specs of proposed entries from the flattened API definition
+func NewEntrySchemas(flatSpec json.RawMessage) (*EntrySchemas, error) {
+	doc := struct {
+		Definitions map[string]spec.Schema `json:"definitions"`
+	}{}
+	if err := json.Unmarshal(flatSpec, &doc); err != nil {
+		return nil, fmt.Errorf("parsing API definition: %w", err)
+	}
+	s := &EntrySchemas{schemas: map[string]*spec.Schema{}}
+	for name, schema := range doc.Definitions {
+		if !specDefinitionRE.MatchString(name) {
+			continue
+		}
+		schema := schema
+		// the published schemas are self-contained; their IDs would only be used as the base of references
+		schema.ID = ""
+		s.schemas[name] = &schema
+	}
+	if len(s.schemas) == 0 {
+		return nil, errors.New("no entry schemas found in API definition")
+	}
+	return s, nil
+}
+
+// schema returns the schema of the spec of entries of kind and apiVersion, if one is published
+func (s *EntrySchemas) schema(kind, apiVersion string) *spec.Schema {
+	return s.schemas[kind+"V"+strings.ReplaceAll(apiVersion, ".", "")+"Schema"]
+}
+
+// validateEntry returns the field-level errors of the spec of a decoded proposed entry, naming the
+// fields relative to path. Entries of kinds or versions without a schema, or without a spec, are
+// left for the generated server and the handler to reject.
+func (s *EntrySchemas) validateEntry(entry interface{}, path string) []string {
+	e, ok := entry.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	kind, _ := e["kind"].(string)
+	apiVersion, _ := e["apiVersion"].(string)
+	entrySpec, ok := e["spec"]
+	schema := s.schema(kind, apiVersion)
+	if !ok || schema == nil {
+		return nil
+	}
+	res := validate.NewSchemaValidator(schema, nil, path+"spec", strfmt.Default).Validate(entrySpec)
+	var errs []string
+	for _, err := range res.Errors {
+		errs = append(errs, err.Error())
+	}
+	return errs
+}
+
+// ValidateProposedEntry rejects requests adding a proposed entry whose spec does not match its schema
+func (s *EntrySchemas) ValidateProposedEntry(handler http.Handler) http.Handler {
+	return validateBody(handler, func(body interface{}) []string {
+		return s.validateEntry(body, "")
+	})
+}
+
+// ValidateSearchLogQuery rejects searches for proposed entries whose specs do not match their schemas
+func (s *EntrySchemas) ValidateSearchLogQuery(handler http.Handler) http.Handler {
+	return validateBody(handler, func(body interface{}) []string {
+		query, ok := body.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		entries, _ := query["entries"].([]interface{})
+		var errs []string
+		for i, e := range entries {
+			errs = append(errs, s.validateEntry(e, fmt.Sprintf("entries.%d.", i))...)
+		}
+		return errs
+	})
+}
+
+// validateBody decodes the JSON or YAML body of requests and rejects them with the errors returned by
+// check, if any. Bodies which can not be decoded are passed on for the generated server to reject.
+func validateBody(handler http.Handler, check func(body interface{}) []string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Body == nil {
+			handler.ServeHTTP(w, r)
+			return
+		}
+		// the generated server decodes the body again, copying what it keeps, so the buffer can be reused
+		// once the request has been handled
+		buf, err := readBuffer(r.Body, r.ContentLength)
+		defer putBuffer(buf)
+		_ = r.Body.Close()
+		if err != nil {
+			writeErrorResponse(w, http.StatusBadRequest, "error reading request body")
+			return
+		}
+		b := buf.Bytes()
+		r.Body = ioutil.NopCloser(bytes.NewReader(b))
+
+		if mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err == nil && mediaType == "application/yaml" {
+			if b, err = yaml.YAMLToJSON(b); err != nil {
+				handler.ServeHTTP(w, r)
+				return
+			}
+		}
+		var body interface{}
+		if err := json.Unmarshal(b, &body); err != nil {
+			handler.ServeHTTP(w, r)
+			return
+		}
+		if errs := check(body); len(errs) > 0 {
+			writeErrorResponse(w, http.StatusBadRequest, fmt.Sprintf(schemaValidationError, strings.Join(errs, "; ")))
+			return
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,173 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/sigstore/rekor/pkg/generated/models"
+)
+
+const testFlatSpec = `{
+  "definitions": {
+    "hashedrekord": {"type": "object"},
+    "hashedrekordV001Schema": {
+      "$id": "http://rekor.sigstore.dev/types/hashedrekord/hashedrekord_v0_0_1_schema.json",
+      "type": "object",
+      "required": ["signature", "data"],
+      "properties": {
+        "signature": {
+          "type": "object",
+          "properties": {"content": {"type": "string", "format": "byte"}}
+        },
+        "data": {
+          "type": "object",
+          "properties": {
+            "hash": {
+              "type": "object",
+              "required": ["algorithm", "value"],
+              "properties": {
+                "algorithm": {"type": "string", "enum": ["sha256", "sha384", "sha512"]},
+                "value": {"type": "string"}
+              }
+            }
+          }
+        }
+      }
+    }
+  }
+}`
+
+func TestEntrySchemas(t *testing.T) {
+	schemas, err := NewEntrySchemas(json.RawMessage(testFlatSpec))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewEntrySchemas(json.RawMessage(`{"definitions": {}}`)); err == nil {
+		t.Error("expected an error for an API definition without entry schemas")
+	}
+
+	const valid = `{"kind": "hashedrekord", "apiVersion": "0.0.1", "spec": {"signature": {"content": "c2ln"}, "data": {"hash": {"algorithm": "sha256", "value": "abc"}}}}`
+	const invalid = `{"kind": "hashedrekord", "apiVersion": "0.0.1", "spec": {"signature": {"content": "not base64!"}, "data": {"hash": {"algorithm": "md5"}}}}`
+	tests := []struct {
+		name       string
+		validate   func(http.Handler) http.Handler
+		body       string
+		yaml       bool
+		wantCode   int
+		wantFields []string
+	}{
+		{name: "valid entry", validate: schemas.ValidateProposedEntry, body: valid, wantCode: http.StatusOK},
+		{
+			name:       "invalid entry",
+			validate:   schemas.ValidateProposedEntry,
+			body:       invalid,
+			wantCode:   http.StatusBadRequest,
+			wantFields: []string{"spec.signature.content", "spec.data.hash.algorithm", "spec.data.hash.value"},
+		},
+		{
+			name:       "missing fields",
+			validate:   schemas.ValidateProposedEntry,
+			body:       `{"kind": "hashedrekord", "apiVersion": "0.0.1", "spec": {}}`,
+			wantCode:   http.StatusBadRequest,
+			wantFields: []string{"spec.signature", "spec.data"},
+		},
+		{
+			name:       "yaml",
+			validate:   schemas.ValidateProposedEntry,
+			body:       "kind: hashedrekord\napiVersion: 0.0.1\nspec:\n  signature: {}\n",
+			yaml:       true,
+			wantCode:   http.StatusBadRequest,
+			wantFields: []string{"spec.data"},
+		},
+		// left for the generated server and the handler to reject
+		{name: "unknown version", validate: schemas.ValidateProposedEntry, body: `{"kind": "hashedrekord", "apiVersion": "9.9.9", "spec": {}}`, wantCode: http.StatusOK},
+		{name: "no spec", validate: schemas.ValidateProposedEntry, body: `{"kind": "hashedrekord", "apiVersion": "0.0.1"}`, wantCode: http.StatusOK},
+		{name: "not JSON", validate: schemas.ValidateProposedEntry, body: `{`, wantCode: http.StatusOK},
+		{name: "valid search", validate: schemas.ValidateSearchLogQuery, body: `{"entries": [` + valid + `]}`, wantCode: http.StatusOK},
+		{
+			name:       "invalid search",
+			validate:   schemas.ValidateSearchLogQuery,
+			body:       `{"entries": [` + valid + `, ` + invalid + `]}`,
+			wantCode:   http.StatusBadRequest,
+			wantFields: []string{"entries.1.spec.signature.content"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotBody string
+			handler := tt.validate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				b, _ := ioutil.ReadAll(r.Body)
+				gotBody = string(b)
+			}))
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/log/entries", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.yaml {
+				req.Header.Set("Content-Type", "application/yaml")
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			if rec.Code != tt.wantCode {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantCode, rec.Body.String())
+			}
+			if tt.wantCode == http.StatusOK {
+				if gotBody != tt.body {
+					t.Errorf("handler received body %q, want %q", gotBody, tt.body)
+				}
+				return
+			}
+			problem := models.Error{}
+			if err := json.Unmarshal(rec.Body.Bytes(), &problem); err != nil {
+				t.Fatal(err)
+			}
+			if problem.ErrorCode != models.ErrorCodeValidationFailed {
+				t.Errorf("errorCode = %q, want %q", problem.ErrorCode, models.ErrorCodeValidationFailed)
+			}
+			for _, f := range tt.wantFields {
+				if !strings.Contains(problem.Detail, f+" ") {
+					t.Errorf("detail %q does not mention %v", problem.Detail, f)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkValidateProposedEntry(b *testing.B) {
+	schemas, err := NewEntrySchemas(json.RawMessage(testFlatSpec))
+	if err != nil {
+		b.Fatal(err)
+	}
+	const body = `{"kind": "hashedrekord", "apiVersion": "0.0.1", "spec": {"signature": {"content": "c2ln"}, "data": {"hash": {"algorithm": "sha256", "value": "abc"}}}}`
+	handler := schemas.ValidateProposedEntry(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(ioutil.Discard, r.Body)
+	}))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/log/entries", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			b.Fatalf("status = %d: %s", rec.Code, rec.Body.String())
+		}
+	}
+}
@@ -0,0 +1,304 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/merkle/rfc6962"
+	"github.com/pkg/errors"
+	"gocloud.dev/blob"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/sigstore/rekor/pkg/audit"
+)
+
+// number of leaves read from trillian at a time when exporting a shard
+const archiveBatchSize = 1000
+
+// ShardArchiveHeader is the first line of the archive of a shard. It is followed by one line for each
+// leaf of the tree of the shard, in order.
+type ShardArchiveHeader struct {
+	TreeID   int64  `json:"treeID"`
+	TreeSize int64  `json:"treeSize"`
+	RootHash string `json:"rootHash"`
+	// signed when the shard was exported
+	SignedTreeHead string `json:"signedTreeHead"`
+}
+
+// archivedLeaf is a leaf of the tree of a shard, as stored in its archive
+type archivedLeaf struct {
+	Index          int64     `json:"index"`
+	Value          []byte    `json:"value"`
+	ExtraData      []byte    `json:"extraData,omitempty"`
+	IntegratedTime time.Time `json:"integratedTime"`
+}
+
+// shardArchiveKey returns the key of the archive of a shard in its bucket
+func shardArchiveKey(treeID int64) string {
+	return fmt.Sprintf("shards/%d.ndjson", treeID)
+}
+
+// ExportShard writes the archive of a frozen shard to the bucket at bucketURL, so that the server can
+// serve the shard without Trillian once the archive is listed in shards.archives. The leaves are
+// checked against the root of the tree as they are written.
+func ExportShard(ctx context.Context, cfg *Config, treeID int64, bucketURL string) (*ShardArchiveHeader, error) {
+	if cfg.Dev {
+		return nil, errors.New("the log is kept in memory in development mode and can not be exported")
+	}
+	if cfg.Backend.embedded() {
+		return nil, errors.New("only shards kept in Trillian can be exported")
+	}
+	if err := openAuditLog(cfg); err != nil {
+		return nil, err
+	}
+	// the shard is read from trillian, even if it is already archived
+	shardCfg := *cfg
+	shardCfg.Trillian.TreeID = treeID
+	shardCfg.Shards = shardsConfig{}
+	a, err := NewAPI(&shardCfg, LogRanges{})
+	if err != nil {
+		return nil, err
+	}
+	conn, err := dial(ctx, cfg.Trillian)
+	if err != nil {
+		return nil, errors.Wrap(err, "dial")
+	}
+
+	fail := func(err error) (*ShardArchiveHeader, error) {
+		writeAudit(audit.Record{Action: audit.ActionExportShard, Outcome: audit.OutcomeError, TreeID: treeID, Detail: err.Error()})
+		return nil, err
+	}
+	tree, err := trillian.NewTrillianAdminClient(conn).GetTree(ctx, &trillian.GetTreeRequest{TreeId: treeID})
+	if err != nil {
+		return fail(errors.Wrap(err, "get tree"))
+	}
+	if tree.TreeState != trillian.TreeState_FROZEN {
+		return fail(errors.Errorf("tree %d is %v; only frozen shards can be exported", treeID, tree.TreeState))
+	}
+	tc := TrillianClient{client: a.logClient, logID: treeID, rpcTimeout: a.rpcTimeout, context: ctx}
+	root, err := tc.root()
+	if err != nil {
+		return fail(err)
+	}
+	shard, err := a.shard(ctx, treeID, 0, &root, true)
+	if err != nil {
+		return fail(err)
+	}
+	header := &ShardArchiveHeader{
+		TreeID:         treeID,
+		TreeSize:       shard.TreeSize,
+		RootHash:       shard.RootHash,
+		SignedTreeHead: shard.SignedTreeHead,
+	}
+
+	bucket, err := blob.OpenBucket(ctx, bucketURL)
+	if err != nil {
+		return fail(err)
+	}
+	defer bucket.Close()
+	// the archive is discarded rather than written if the context is canceled before the writer is closed
+	writeCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	w, err := bucket.NewWriter(writeCtx, shardArchiveKey(treeID), &blob.WriterOptions{ContentType: "application/x-ndjson"})
+	if err != nil {
+		return fail(err)
+	}
+	if err := writeShardArchive(w, tc, header); err != nil {
+		cancel()
+		_ = w.Close()
+		return fail(err)
+	}
+	if err := w.Close(); err != nil {
+		return fail(err)
+	}
+	writeAudit(audit.Record{
+		Action:  audit.ActionExportShard,
+		Outcome: audit.OutcomeSuccess,
+		TreeID:  treeID,
+		Detail:  fmt.Sprintf("exported %d entries with root hash %s to %s", header.TreeSize, header.RootHash, bucketURL),
+	})
+	return header, nil
+}
+
+// writeShardArchive writes the archive of the tree of a shard, failing if its leaves do not hash to
+// the root in header
+func writeShardArchive(w io.Writer, tc TrillianClient, header *ShardArchiveHeader) error {
+	enc := json.NewEncoder(w)
+	if err := enc.Encode(header); err != nil {
+		return err
+	}
+	hashes := make([][]byte, 0, header.TreeSize)
+	for next := int64(0); next < header.TreeSize; {
+		count := header.TreeSize - next
+		if count > archiveBatchSize {
+			count = archiveBatchSize
+		}
+		resp := tc.getLeavesByRange(next, count)
+		if resp.err != nil {
+			return resp.err
+		}
+		leaves := resp.getLeavesByRangeResult.GetLeaves()
+		if len(leaves) == 0 {
+			return errors.Errorf("trillian returned no leaves from %d", next)
+		}
+		for _, leaf := range leaves {
+			if leaf.LeafIndex != next {
+				return errors.Errorf("trillian returned leaf %d instead of %d", leaf.LeafIndex, next)
+			}
+			hashes = append(hashes, rfc6962.DefaultHasher.HashLeaf(leaf.LeafValue))
+			if err := enc.Encode(archivedLeaf{
+				Index:          leaf.LeafIndex,
+				Value:          leaf.LeafValue,
+				ExtraData:      leaf.ExtraData,
+				IntegratedTime: leaf.IntegrateTimestamp.AsTime(),
+			}); err != nil {
+				return err
+			}
+			next++
+		}
+	}
+	if rootHash := hex.EncodeToString(merkleRoot(hashes)); rootHash != header.RootHash {
+		return errors.Errorf("the leaves of tree %d hash to %s rather than its root hash %s", header.TreeID, rootHash, header.RootHash)
+	}
+	return nil
+}
+
+// readShardArchive reads the archive of the tree of a shard, failing unless its leaves hash to the root
+// recorded in its header
+func readShardArchive(r io.Reader, treeID int64) (*memoryTree, error) {
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	header := ShardArchiveHeader{}
+	if err := dec.Decode(&header); err != nil {
+		return nil, errors.Wrap(err, "reading header")
+	}
+	if header.TreeID != treeID {
+		return nil, errors.Errorf("the archive is of tree %d", header.TreeID)
+	}
+
+	t := newMemoryTree()
+	t.revision = 1
+	for {
+		l := archivedLeaf{}
+		if err := dec.Decode(&l); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, errors.Wrapf(err, "reading leaf %d", len(t.leaves))
+		}
+		index := int64(len(t.leaves))
+		if l.Index != index {
+			return nil, errors.Errorf("found leaf %d instead of %d", l.Index, index)
+		}
+		t.appendLeaf(l.Value, l.ExtraData, timestamppb.New(l.IntegratedTime))
+	}
+	if int64(len(t.leaves)) != header.TreeSize {
+		return nil, errors.Errorf("found %d leaves rather than %d", len(t.leaves), header.TreeSize)
+	}
+	if rootHash := hex.EncodeToString(merkleRoot(t.hashes)); rootHash != header.RootHash {
+		return nil, errors.Errorf("the leaves hash to %s rather than the root hash %s", rootHash, header.RootHash)
+	}
+	return t, nil
+}
+
+// shardLogClient serves the trees of archived shards from memory and passes requests for other trees
+// on to Trillian
+type shardLogClient struct {
+	trillian.TrillianLogClient
+	archives *memoryLogClient
+}
+
+// newShardLogClient loads the archives of shards into memory; each archived shard must be frozen in ranges
+func newShardLogClient(ctx context.Context, next trillian.TrillianLogClient, cfg shardsConfig, ranges LogRanges) (*shardLogClient, error) {
+	archives, err := cfg.archives()
+	if err != nil {
+		return nil, err
+	}
+	frozen := map[int64]bool{}
+	for _, r := range ranges.Frozen() {
+		frozen[int64(r.TreeID)] = true
+	}
+	c := &shardLogClient{TrillianLogClient: next, archives: newMemoryLogClient()}
+	for treeID, bucketURL := range archives {
+		if !frozen[treeID] {
+			return nil, errors.Errorf("tree %d is not a frozen shard in trillian_log_server.log_id_ranges", treeID)
+		}
+		t, err := loadShardArchive(ctx, treeID, bucketURL)
+		if err != nil {
+			return nil, errors.Wrapf(err, "archive of tree %d", treeID)
+		}
+		c.archives.addFrozenTree(treeID, t)
+	}
+	return c, nil
+}
+
+// loadShardArchive reads the archive of a shard from the bucket at bucketURL
+func loadShardArchive(ctx context.Context, treeID int64, bucketURL string) (*memoryTree, error) {
+	bucket, err := blob.OpenBucket(ctx, bucketURL)
+	if err != nil {
+		return nil, err
+	}
+	defer bucket.Close()
+	r, err := bucket.NewReader(ctx, shardArchiveKey(treeID), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return readShardArchive(r, treeID)
+}
+
+// route returns the client serving a tree
+func (c *shardLogClient) route(treeID int64) trillian.TrillianLogClient {
+	if c.archives.frozen[treeID] {
+		return c.archives
+	}
+	return c.TrillianLogClient
+}
+
+func (c *shardLogClient) QueueLeaf(ctx context.Context, in *trillian.QueueLeafRequest, opts ...grpc.CallOption) (*trillian.QueueLeafResponse, error) {
+	return c.route(in.LogId).QueueLeaf(ctx, in, opts...)
+}
+
+func (c *shardLogClient) GetLatestSignedLogRoot(ctx context.Context, in *trillian.GetLatestSignedLogRootRequest, opts ...grpc.CallOption) (*trillian.GetLatestSignedLogRootResponse, error) {
+	return c.route(in.LogId).GetLatestSignedLogRoot(ctx, in, opts...)
+}
+
+func (c *shardLogClient) GetInclusionProofByHash(ctx context.Context, in *trillian.GetInclusionProofByHashRequest, opts ...grpc.CallOption) (*trillian.GetInclusionProofByHashResponse, error) {
+	return c.route(in.LogId).GetInclusionProofByHash(ctx, in, opts...)
+}
+
+func (c *shardLogClient) GetInclusionProof(ctx context.Context, in *trillian.GetInclusionProofRequest, opts ...grpc.CallOption) (*trillian.GetInclusionProofResponse, error) {
+	return c.route(in.LogId).GetInclusionProof(ctx, in, opts...)
+}
+
+func (c *shardLogClient) GetEntryAndProof(ctx context.Context, in *trillian.GetEntryAndProofRequest, opts ...grpc.CallOption) (*trillian.GetEntryAndProofResponse, error) {
+	return c.route(in.LogId).GetEntryAndProof(ctx, in, opts...)
+}
+
+func (c *shardLogClient) GetConsistencyProof(ctx context.Context, in *trillian.GetConsistencyProofRequest, opts ...grpc.CallOption) (*trillian.GetConsistencyProofResponse, error) {
+	return c.route(in.LogId).GetConsistencyProof(ctx, in, opts...)
+}
+
+func (c *shardLogClient) GetLeavesByRange(ctx context.Context, in *trillian.GetLeavesByRangeRequest, opts ...grpc.CallOption) (*trillian.GetLeavesByRangeResponse, error) {
+	return c.route(in.LogId).GetLeavesByRange(ctx, in, opts...)
+}
@@ -0,0 +1,423 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/types"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+
+	"github.com/sigstore/rekor/pkg/audit"
+	"github.com/sigstore/rekor/pkg/log"
+)
+
+// ShardsPath is where the shards of the log are listed
+const ShardsPath = "/api/v1/log/shards"
+
+// The log is split into shards, each a Trillian tree, listed in order by trillian_log_server.log_id_ranges.
+// Only the last, active, shard accepts entries; the others are frozen. Entries are addressed by their
+// virtual index, which counts on from the end of the previous shard, and their inclusion proofs are
+// against the tree of the shard holding them.
+
+// shardsConfig holds the shards.* settings
+type shardsConfig struct {
+	// frozen shards served from the archives written by rekor-server shard export rather than from
+	// Trillian, as tree_id=bucket URL
+	Archives []string `mapstructure:"archives"`
+}
+
+func (c shardsConfig) validate() error {
+	_, err := c.archives()
+	return err
+}
+
+// archives returns the URL of the bucket holding the archive of each archived shard, by tree ID
+func (c shardsConfig) archives() (map[int64]string, error) {
+	archives := map[int64]string{}
+	for _, a := range c.Archives {
+		split := strings.SplitN(a, "=", 2)
+		if len(split) != 2 || split[1] == "" {
+			return nil, errors.Errorf("invalid archive %q, expected tree_id=bucket URL", a)
+		}
+		treeID, err := strconv.ParseInt(split[0], 10, 64)
+		if err != nil || treeID <= 0 {
+			return nil, errors.Errorf("invalid tree ID in %q", a)
+		}
+		if _, ok := archives[treeID]; ok {
+			return nil, errors.Errorf("tree %d is archived more than once", treeID)
+		}
+		archives[treeID] = split[1]
+	}
+	return archives, nil
+}
+
+// shards returns the shards of the log, the last of which is the active one
+func (t *TrillianClient) shards() []LogRange {
+	if t.ranges == nil || len(t.ranges.Ranges) == 0 {
+		return []LogRange{{TreeID: uint64(t.logID)}}
+	}
+	return t.ranges.Ranges
+}
+
+// forShard returns a client for the tree of a shard of the log
+func (t *TrillianClient) forShard(treeID int64) TrillianClient {
+	c := *t
+	c.logID = treeID
+	return c
+}
+
+// activeOffset returns the virtual index of the first entry of the active shard
+func (t *TrillianClient) activeOffset() int64 {
+	ranges := LogRanges{Ranges: t.shards()}
+	return int64(ranges.ActiveOffset())
+}
+
+// getEntryByIndex returns the entry at a virtual index of the log with its inclusion proof in the
+// tree of the shard holding it; the index of the leaf returned is the virtual index
+func (t *TrillianClient) getEntryByIndex(index int64) *Response {
+	if index < 0 {
+		return &Response{status: codes.OutOfRange, err: status.Errorf(codes.OutOfRange, "invalid index %d", index)}
+	}
+	ranges := LogRanges{Ranges: t.shards()}
+	treeID, local := ranges.ResolveVirtualIndex(int(index))
+	offset, _ := ranges.Offset(treeID)
+	shard := t.forShard(int64(treeID))
+	return shiftEntry(shard.getLeafAndProofByIndex(int64(local)), int64(offset))
+}
+
+// getEntryByHash looks the leaf with a Merkle leaf hash up in the shards of the log, newest first, and
+// returns it as getEntryByIndex does
+func (t *TrillianClient) getEntryByHash(hash []byte) *Response {
+	shards := t.shards()
+	offsets := shardOffsets(shards)
+	var resp *Response
+	for i := len(shards) - 1; i >= 0; i-- {
+		shard := t.forShard(int64(shards[i].TreeID))
+		if resp = shard.getLeafAndProofByHash(hash); resp.status != codes.NotFound {
+			return shiftEntry(resp, offsets[i])
+		}
+	}
+	return resp
+}
+
+// findShard looks the leaf with a Merkle leaf hash up in the shards of the log, newest first, and returns a
+// client for the shard holding it along with the inclusion proof of the leaf in the current tree of the shard
+func (t *TrillianClient) findShard(hash []byte) (TrillianClient, *Response) {
+	shards := t.shards()
+	var resp *Response
+	for i := len(shards) - 1; i >= 0; i-- {
+		shard := t.forShard(int64(shards[i].TreeID))
+		if resp = shard.getProofByHash(hash); resp.status != codes.NotFound {
+			return shard, resp
+		}
+	}
+	return *t, resp
+}
+
+// inFrozenShard reports whether a frozen shard of the log holds the leaf with a Merkle leaf hash
+func (t *TrillianClient) inFrozenShard(hash []byte) (bool, error) {
+	shards := t.shards()
+	for _, r := range shards[:len(shards)-1] {
+		shard := t.forShard(int64(r.TreeID))
+		resp := shard.getProofByHash(hash)
+		switch resp.status {
+		case codes.OK:
+			if len(resp.getProofResult.GetProof()) > 0 {
+				return true, nil
+			}
+		case codes.NotFound:
+		default:
+			return false, resp.err
+		}
+	}
+	return false, nil
+}
+
+// shardOffsets returns the virtual index of the first entry of each shard
+func shardOffsets(shards []LogRange) []int64 {
+	offsets := make([]int64, len(shards))
+	var offset int64
+	for i, r := range shards {
+		offsets[i] = offset
+		offset += int64(r.TreeLength)
+	}
+	return offsets
+}
+
+// shiftEntry moves the leaf of a response of getLeafAndProofByIndex from its index in the tree of a
+// shard to its virtual index
+func shiftEntry(resp *Response, offset int64) *Response {
+	result := resp.getLeafAndProofResult
+	if offset == 0 || result == nil || result.Leaf == nil {
+		return resp
+	}
+	resp.getLeafAndProofResult = &trillian.GetEntryAndProofResponse{
+		Proof:         result.Proof,
+		Leaf:          shiftLeaf(result.Leaf, offset),
+		SignedLogRoot: result.SignedLogRoot,
+	}
+	return resp
+}
+
+// shiftLeaf returns a leaf of the tree of a shard with its virtual index; the leaf is copied, as
+// leaves may be shared with other requests
+func shiftLeaf(leaf *trillian.LogLeaf, offset int64) *trillian.LogLeaf {
+	if offset == 0 {
+		return leaf
+	}
+	shifted := proto.Clone(leaf).(*trillian.LogLeaf)
+	shifted.LeafIndex += offset
+	return shifted
+}
+
+// checkShards checks that the trees of the frozen shards of the log are as large as their ranges, as
+// the virtual indices of all later entries depend on them
+func (a *API) checkShards(ctx context.Context) error {
+	tc := TrillianClient{client: a.logClient, rpcTimeout: a.rpcTimeout, context: ctx}
+	for _, r := range a.logRanges.Frozen() {
+		tc.logID = int64(r.TreeID)
+		root, err := tc.root()
+		if err != nil {
+			return errors.Wrapf(err, "shard %d", r.TreeID)
+		}
+		if root.TreeSize != r.TreeLength {
+			return errors.Errorf("shard %d holds %d entries, but trillian_log_server.log_id_ranges gives it %d", r.TreeID, root.TreeSize, r.TreeLength)
+		}
+	}
+	return nil
+}
+
+// Shard describes a shard of the log, as listed at ShardsPath
+type Shard struct {
+	TreeID int64 `json:"treeID"`
+	// virtual index of the first entry of the shard
+	FirstIndex     int64  `json:"firstIndex"`
+	TreeSize       int64  `json:"treeSize"`
+	RootHash       string `json:"rootHash"`
+	SignedTreeHead string `json:"signedTreeHead"`
+	// frozen shards no longer accept entries
+	Frozen bool `json:"frozen"`
+}
+
+// shard describes a shard of the log given the root of its tree
+func (a *API) shard(ctx context.Context, treeID, firstIndex int64, root *types.LogRootV1, frozen bool) (*Shard, error) {
+	sth, _, err := a.signCheckpoint(ctx, root)
+	if err != nil {
+		return nil, err
+	}
+	b, err := sth.SignedNote.MarshalText()
+	if err != nil {
+		return nil, fmt.Errorf("marshalling error: %w", err)
+	}
+	return &Shard{
+		TreeID:         treeID,
+		FirstIndex:     firstIndex,
+		TreeSize:       int64(root.TreeSize),
+		RootHash:       hex.EncodeToString(root.RootHash),
+		SignedTreeHead: string(b),
+		Frozen:         frozen,
+	}, nil
+}
+
+// listShards describes the shards of the log addressed by a request, oldest first
+func listShards(ctx context.Context) ([]*Shard, error) {
+	a, tc := logAPI(ctx), NewTrillianClient(ctx)
+	shards := tc.shards()
+	offsets := shardOffsets(shards)
+	result := []*Shard{}
+	for i, r := range shards {
+		shard := tc.forShard(int64(r.TreeID))
+		root, err := shard.root()
+		if err != nil {
+			return nil, errors.Wrapf(err, "shard %d", r.TreeID)
+		}
+		s, err := a.shard(ctx, int64(r.TreeID), offsets[i], &root, i < len(shards)-1)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, s)
+	}
+	return result, nil
+}
+
+// ServeShards lists the shards of the log at ShardsPath, with a signed tree head of each, as JSON.
+// Other requests are passed on to handler.
+func ServeShards(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != ShardsPath {
+			handler.ServeHTTP(w, r)
+			return
+		}
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			writeErrorResponse(w, http.StatusMethodNotAllowed, "")
+			return
+		}
+		shards, err := listShards(r.Context())
+		if err != nil {
+			log.RequestIDLogger(r).Errorf("error listing shards: %v", err)
+			writeErrorResponse(w, http.StatusInternalServerError, trillianCommunicationError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", cacheRevalidate)
+		w.WriteHeader(http.StatusOK)
+		if r.Method == http.MethodGet {
+			_ = json.NewEncoder(w).Encode(shards)
+		}
+	})
+}
+
+// FreezeOptions controls how FreezeShard freezes the active shard
+type FreezeOptions struct {
+	// how long the tree must stay the same size while it is drained before it is frozen
+	Settle time.Duration
+	// tree of the next active shard; a tree is created if 0
+	NextTreeID int64
+}
+
+// FreezeShard stops writes to the active shard of the log: its tree is drained until the entries
+// already queued are integrated, then frozen, and its final tree head is signed and recorded in the
+// audit log. The ranges returned add the next shard, which the server must be restarted with; until
+// then, entries are rejected.
+func FreezeShard(ctx context.Context, cfg *Config, ranges LogRanges, opts FreezeOptions) (*Shard, LogRanges, error) {
+	if cfg.Dev {
+		return nil, LogRanges{}, errors.New("the log is kept in memory in development mode and can not be frozen")
+	}
+	if cfg.Backend.embedded() {
+		return nil, LogRanges{}, errors.New("only shards kept in Trillian can be frozen")
+	}
+	if cfg.Trillian.TreeID == 0 && len(ranges.Ranges) == 0 {
+		return nil, LogRanges{}, errors.New("trillian_log_server.tlog_id or trillian_log_server.log_id_ranges must give the active shard")
+	}
+	if err := openAuditLog(cfg); err != nil {
+		return nil, LogRanges{}, err
+	}
+	a, err := NewAPI(cfg, ranges)
+	if err != nil {
+		return nil, LogRanges{}, err
+	}
+	conn, err := dial(ctx, cfg.Trillian)
+	if err != nil {
+		return nil, LogRanges{}, errors.Wrap(err, "dial")
+	}
+	adminClient := trillian.NewTrillianAdminClient(conn)
+
+	fail := func(err error) (*Shard, LogRanges, error) {
+		writeAudit(audit.Record{Action: audit.ActionFreezeShard, Outcome: audit.OutcomeError, TreeID: a.logID, Detail: err.Error()})
+		return nil, LogRanges{}, err
+	}
+	if err := setTreeState(ctx, adminClient, a.logID, trillian.TreeState_DRAINING); err != nil {
+		return fail(errors.Wrap(err, "draining tree"))
+	}
+	tc := TrillianClient{client: a.logClient, logID: a.logID, rpcTimeout: a.rpcTimeout, context: ctx}
+	root, err := waitForDrain(ctx, tc, opts.Settle)
+	if err != nil {
+		return fail(errors.Wrap(err, "draining tree"))
+	}
+	if err := setTreeState(ctx, adminClient, a.logID, trillian.TreeState_FROZEN); err != nil {
+		return fail(errors.Wrap(err, "freezing tree"))
+	}
+	// the tree may have grown between the last check and being frozen
+	if root, err = tc.root(); err != nil {
+		return fail(err)
+	}
+	shard, err := a.shard(ctx, a.logID, int64(a.logRanges.ActiveOffset()), &root, true)
+	if err != nil {
+		return fail(err)
+	}
+
+	nextTreeID := opts.NextTreeID
+	if nextTreeID == 0 {
+		// the tree is initialized through Trillian itself rather than the log backend of the API
+		t, err := createTree(ctx, adminClient, trillian.NewTrillianLogClient(conn))
+		if err != nil {
+			return fail(err)
+		}
+		nextTreeID = t.TreeId
+	}
+	next := LogRanges{Ranges: append([]LogRange{}, a.logRanges.Frozen()...)}
+	next.Ranges = append(next.Ranges, LogRange{TreeID: uint64(a.logID), TreeLength: root.TreeSize}, LogRange{TreeID: uint64(nextTreeID)})
+
+	writeAudit(audit.Record{
+		Action:  audit.ActionFreezeShard,
+		Outcome: audit.OutcomeSuccess,
+		TreeID:  a.logID,
+		Detail:  fmt.Sprintf("frozen at size %d with root hash %s; the next shard is tree %d", shard.TreeSize, shard.RootHash, nextTreeID),
+	})
+	return shard, next, nil
+}
+
+// setTreeState changes the state of a tree in Trillian
+func setTreeState(ctx context.Context, adminClient trillian.TrillianAdminClient, treeID int64, state trillian.TreeState) error {
+	_, err := adminClient.UpdateTree(ctx, &trillian.UpdateTreeRequest{
+		Tree:       &trillian.Tree{TreeId: treeID, TreeState: state},
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"tree_state"}},
+	})
+	return err
+}
+
+// waitForDrain waits until the tree of a draining shard has stayed the same size for settle, as the
+// entries queued before it was drained are integrated
+func waitForDrain(ctx context.Context, tc TrillianClient, settle time.Duration) (types.LogRootV1, error) {
+	root, err := tc.root()
+	if err != nil {
+		return types.LogRootV1{}, err
+	}
+	stableSince := time.Now()
+	for time.Since(stableSince) < settle {
+		select {
+		case <-ctx.Done():
+			return types.LogRootV1{}, ctx.Err()
+		case <-time.After(time.Second):
+		}
+		latest, err := tc.root()
+		if err != nil {
+			return types.LogRootV1{}, err
+		}
+		if latest.TreeSize != root.TreeSize {
+			root, stableSince = latest, time.Now()
+		}
+	}
+	return root, nil
+}
+
+// openAuditLog opens the audit log for the administrative commands, which run without ConfigureAPI.
+// They may append to the audit file of a running server, as audit.OpenFile allows.
+func openAuditLog(cfg *Config) error {
+	if auditLog != nil {
+		return nil
+	}
+	l, err := newAuditLogger(cfg.Audit)
+	if err != nil {
+		return err
+	}
+	auditLog = l
+	return nil
+}
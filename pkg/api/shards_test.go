@@ -0,0 +1,245 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/merkle/rfc6962"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/sigstore/rekor/pkg/generated/models"
+	"github.com/sigstore/rekor/pkg/signer"
+)
+
+// shardedClient returns a client for a log whose frozen shard, tree 1, holds 3 leaves and whose
+// active shard, tree 2, holds 2
+func shardedClient(t *testing.T, m *memoryLogClient) TrillianClient {
+	t.Helper()
+	for treeID, n := range map[int64]int{1: 3, 2: 2} {
+		for i := 0; i < n; i++ {
+			leaf := &trillian.LogLeaf{LeafValue: []byte(fmt.Sprintf(`{"tree":%d,"entry":%d}`, treeID, i))}
+			if _, err := m.QueueLeaf(context.Background(), &trillian.QueueLeafRequest{LogId: treeID, Leaf: leaf}); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	m.frozen[1] = true
+	return TrillianClient{
+		client:  m,
+		logID:   2,
+		context: context.Background(),
+		ranges:  &LogRanges{Ranges: []LogRange{{TreeID: 1, TreeLength: 3}, {TreeID: 2}}},
+	}
+}
+
+func TestShardedReads(t *testing.T) {
+	m := newMemoryLogClient()
+	tc := shardedClient(t, m)
+
+	for index, want := range map[int64]string{
+		0: `{"tree":1,"entry":0}`,
+		2: `{"tree":1,"entry":2}`,
+		3: `{"tree":2,"entry":0}`,
+		4: `{"tree":2,"entry":1}`,
+	} {
+		resp := tc.getEntryByIndex(index)
+		if resp.err != nil {
+			t.Fatalf("index %d: %v", index, resp.err)
+		}
+		result := resp.getLeafAndProofResult
+		if string(result.Leaf.LeafValue) != want || result.Leaf.LeafIndex != index {
+			t.Errorf("index %d: got leaf %d %s, want %s", index, result.Leaf.LeafIndex, result.Leaf.LeafValue, want)
+		}
+		// the proof is in the tree of the shard
+		if local := index % 3; result.Proof.LeafIndex != local {
+			t.Errorf("index %d: proof of leaf %d, want %d", index, result.Proof.LeafIndex, local)
+		}
+
+		byHash := tc.getEntryByHash(rfc6962.DefaultHasher.HashLeaf([]byte(want)))
+		if byHash.err != nil || byHash.getLeafAndProofResult.Leaf.LeafIndex != index {
+			t.Errorf("looking up %s by hash: %v %v", want, byHash.err, byHash.getLeafAndProofResult)
+		}
+	}
+	// leaves are shifted without modifying those kept by the log
+	if got := m.trees[2].leaves[1].LeafIndex; got != 1 {
+		t.Errorf("leaf of the active shard modified to index %d", got)
+	}
+
+	if resp := tc.getEntryByIndex(5); status.Code(resp.err) != codes.OutOfRange {
+		t.Errorf("expected index beyond the log to be out of range, got %v", resp.err)
+	}
+	if resp := tc.getEntryByHash(rfc6962.DefaultHasher.HashLeaf([]byte("missing"))); resp.status != codes.NotFound {
+		t.Errorf("expected missing leaf not to be found, got %v", resp.status)
+	}
+
+	if found, err := tc.inFrozenShard(rfc6962.DefaultHasher.HashLeaf([]byte(`{"tree":1,"entry":1}`))); err != nil || !found {
+		t.Errorf("expected leaf to be found in the frozen shard: %v %v", found, err)
+	}
+	if found, err := tc.inFrozenShard(rfc6962.DefaultHasher.HashLeaf([]byte(`{"tree":2,"entry":1}`))); err != nil || found {
+		t.Errorf("expected leaf of the active shard not to be found in the frozen shard: %v %v", found, err)
+	}
+	if offset := tc.activeOffset(); offset != 3 {
+		t.Errorf("active offset %d, want 3", offset)
+	}
+
+	if _, err := m.QueueLeaf(context.Background(), &trillian.QueueLeafRequest{LogId: 1, Leaf: &trillian.LogLeaf{LeafValue: []byte("new")}}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("expected frozen tree to reject leaves, got %v", err)
+	}
+}
+
+func TestStreamShards(t *testing.T) {
+	s, err := signer.NewMemory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := newMemoryLogClient()
+	tc := shardedClient(t, m)
+	oldAPI := api
+	t.Cleanup(func() {
+		api = oldAPI
+	})
+	api = &API{logClient: m, logID: 2, logRanges: tc.ranges, signer: s, pubkeyHash: "abc"}
+
+	rec := streamEntries("?start=1&proofs=true")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("unexpected response %d: %s", rec.Code, rec.Body)
+	}
+	var indexes []int64
+	scanner := bufio.NewScanner(rec.Body)
+	for scanner.Scan() {
+		entry := models.LogEntry{}
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			t.Fatalf("invalid line %q: %v", scanner.Text(), err)
+		}
+		for _, e := range entry {
+			proof := e.Verification.InclusionProof
+			if proof == nil {
+				t.Fatalf("entry %d has no inclusion proof", *e.LogIndex)
+			}
+			// entries are proven in the tree of their shard
+			wantSize := int64(3)
+			if *e.LogIndex >= 3 {
+				wantSize = 2
+			}
+			if *proof.TreeSize != wantSize {
+				t.Errorf("entry %d proven in tree of size %d, want %d", *e.LogIndex, *proof.TreeSize, wantSize)
+			}
+			indexes = append(indexes, *e.LogIndex)
+		}
+	}
+	if fmt.Sprint(indexes) != "[1 2 3 4]" {
+		t.Errorf("streamed entries %v, want [1 2 3 4]", indexes)
+	}
+}
+
+func TestShardArchive(t *testing.T) {
+	m := newMemoryLogClient()
+	sc := shardedClient(t, m)
+	tc := sc.forShard(1)
+	root, err := tc.root()
+	if err != nil {
+		t.Fatal(err)
+	}
+	header := &ShardArchiveHeader{TreeID: 1, TreeSize: int64(root.TreeSize), RootHash: hex.EncodeToString(root.RootHash)}
+
+	archive := &bytes.Buffer{}
+	if err := writeShardArchive(archive, tc, header); err != nil {
+		t.Fatal(err)
+	}
+	tree, err := readShardArchive(bytes.NewReader(archive.Bytes()), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tree.leaves) != 3 {
+		t.Fatalf("read %d leaves, want 3", len(tree.leaves))
+	}
+	for i, leaf := range tree.leaves {
+		orig := m.trees[1].leaves[i]
+		if !bytes.Equal(leaf.LeafValue, orig.LeafValue) || !bytes.Equal(leaf.MerkleLeafHash, orig.MerkleLeafHash) ||
+			!leaf.IntegrateTimestamp.AsTime().Equal(orig.IntegrateTimestamp.AsTime()) {
+			t.Errorf("leaf %d read as %v, want %v", i, leaf, orig)
+		}
+	}
+
+	// the archived shard is served read-only in place of its tree
+	archived := &shardLogClient{TrillianLogClient: newMemoryLogClient(), archives: newMemoryLogClient()}
+	archived.archives.addFrozenTree(1, tree)
+	served := TrillianClient{client: archived, logID: 1, context: context.Background()}
+	if resp := served.getLeafAndProofByIndex(2); resp.err != nil || !bytes.Equal(resp.getLeafAndProofResult.Leaf.LeafValue, m.trees[1].leaves[2].LeafValue) {
+		t.Errorf("unexpected leaf served from the archive: %v", resp.err)
+	}
+	if resp := served.addLeaf([]byte("new"), nil); resp.status != codes.FailedPrecondition {
+		t.Errorf("expected archived shard to reject leaves, got %v", resp.status)
+	}
+
+	if _, err := readShardArchive(bytes.NewReader(archive.Bytes()), 2); err == nil {
+		t.Error("expected archive of another tree to be rejected")
+	}
+	lines := strings.SplitAfter(archive.String(), "\n")
+	tampered := strings.Join(append(lines[:2:2], lines[3:]...), "")
+	if _, err := readShardArchive(strings.NewReader(tampered), 1); err == nil {
+		t.Error("expected archive missing a leaf to be rejected")
+	}
+	leaf := archivedLeaf{}
+	if err := json.Unmarshal([]byte(lines[2]), &leaf); err != nil {
+		t.Fatal(err)
+	}
+	leaf.Value = []byte(`{"tree":1,"entry":42}`)
+	modified, err := json.Marshal(leaf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tampered = strings.Join(append(lines[:2:2], append([]string{string(modified) + "\n"}, lines[3:]...)...), "")
+	if _, err := readShardArchive(strings.NewReader(tampered), 1); err == nil {
+		t.Error("expected archive with a modified leaf to be rejected")
+	}
+
+	header.RootHash = hex.EncodeToString(make([]byte, 32))
+	if err := writeShardArchive(&bytes.Buffer{}, tc, header); err == nil {
+		t.Error("expected export of leaves not matching the root to fail")
+	}
+}
+
+func TestShardsConfig(t *testing.T) {
+	archives, err := shardsConfig{Archives: []string{"1=gs://rekor-shards", "2=file:///var/lib/rekor/shards"}}.archives()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(archives) != 2 || archives[1] != "gs://rekor-shards" || archives[2] != "file:///var/lib/rekor/shards" {
+		t.Errorf("unexpected archives %v", archives)
+	}
+	for _, invalid := range [][]string{
+		{"gs://rekor-shards"},
+		{"0=gs://rekor-shards"},
+		{"1="},
+		{"1=gs://a", "1=gs://b"},
+	} {
+		if err := (shardsConfig{Archives: invalid}).validate(); err == nil {
+			t.Errorf("expected error for %v", invalid)
+		}
+	}
+}
@@ -0,0 +1,179 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/go-openapi/runtime"
+	"github.com/go-openapi/runtime/middleware"
+	"github.com/go-openapi/swag"
+	"github.com/google/trillian"
+	ttypes "github.com/google/trillian/types"
+
+	"github.com/sigstore/rekor/pkg/generated/models"
+	"github.com/sigstore/rekor/pkg/generated/restapi/operations/entries"
+	"github.com/sigstore/rekor/pkg/log"
+)
+
+// StreamPath is where a range of entries is served as newline-delimited JSON, one LogEntry per line
+const StreamPath = "/api/v1/log/entries/stream"
+
+// number of leaves requested from trillian at a time; the next batch is only requested once the
+// previous one has been written to the client, so slow clients slow down the stream
+const streamBatchSize = 100
+
+// StreamLogEntriesHandler streams the entries from index start up to, but excluding, end (by default the
+// size of the log, across its shards, when the request is received) as newline-delimited JSON.
+// Inclusion proofs against the tree of the shard holding each entry are included if proofs is true. If
+// an error occurs after the response has started, the stream ends with an Error object rather than an
+// entry; streams are also cut off by the write timeout of the server, so clients should resume after
+// the last entry they received.
+func StreamLogEntriesHandler(params entries.StreamLogEntriesParams) middleware.Responder {
+	start := swag.Int64Value(params.Start)
+	end := int64(-1)
+	if params.End != nil {
+		end = *params.End
+		if start > end {
+			return handleRekorAPIError(params, http.StatusBadRequest, nil, fmt.Sprintf("start(%d) must not be greater than end(%d)", start, end))
+		}
+	}
+
+	r := params.HTTPRequest
+	ctx := r.Context()
+	tc := NewTrillianClient(ctx)
+	root, err := tc.root()
+	if err != nil {
+		return handleRekorAPIError(params, http.StatusInternalServerError, err, trillianCommunicationError)
+	}
+	// entries are addressed by their virtual index, running through the frozen shards of the log
+	if size := tc.activeOffset() + int64(root.TreeSize); end < 0 || end > size {
+		end = size
+	}
+
+	return middleware.ResponderFunc(func(w http.ResponseWriter, _ runtime.Producer) {
+		w.WriteHeader(http.StatusOK)
+
+		s := &entryStream{r: r, tc: tc, roots: map[int64]*ttypes.LogRootV1{tc.logID: &root}, proofs: swag.BoolValue(params.Proofs), enc: json.NewEncoder(w)}
+		flusher, _ := w.(http.Flusher)
+		for next := start; next < end; {
+			n, err := s.writeBatch(next, end)
+			if err != nil {
+				if ctx.Err() == nil {
+					log.RequestIDLogger(r).Errorf("error streaming entries from %d: %v", next, err)
+					_ = s.enc.Encode(errorMsg(trillianUnexpectedResult, http.StatusInternalServerError))
+				}
+				return
+			}
+			next += n
+			if flusher != nil {
+				flusher.Flush()
+			}
+		}
+	})
+}
+
+type entryStream struct {
+	r  *http.Request
+	tc TrillianClient
+	// the roots of the trees of the shards streamed from, which proofs are against
+	roots  map[int64]*ttypes.LogRootV1
+	proofs bool
+	enc    *json.Encoder
+}
+
+// writeBatch writes the next leaves starting at virtual index start, from the shard holding it, and
+// returns how many were written
+func (s *entryStream) writeBatch(start, end int64) (int64, error) {
+	shards := s.tc.shards()
+	offsets := shardOffsets(shards)
+	n := len(shards) - 1
+	for n > 0 && start < offsets[n] {
+		n--
+	}
+	if n < len(shards)-1 && end > offsets[n+1] {
+		end = offsets[n+1]
+	}
+	shard := s.tc.forShard(int64(shards[n].TreeID))
+	root, err := s.root(shard)
+	if err != nil {
+		return 0, err
+	}
+
+	count := end - start
+	if count > streamBatchSize {
+		count = streamBatchSize
+	}
+	local := start - offsets[n]
+	resp := shard.getLeavesByRange(local, count)
+	if resp.err != nil {
+		return 0, resp.err
+	}
+	leaves := resp.getLeavesByRangeResult.GetLeaves()
+	if len(leaves) == 0 {
+		return 0, errors.New("trillian returned no leaves")
+	}
+
+	for i, leaf := range leaves {
+		if leaf.LeafIndex != local+int64(i) {
+			return int64(i), fmt.Errorf("trillian returned leaf %d instead of %d", leaf.LeafIndex, local+int64(i))
+		}
+		entry, err := s.entry(shard, root, leaf, start-local)
+		if err != nil {
+			return int64(i), err
+		}
+		if err := s.enc.Encode(entry); err != nil {
+			return int64(i), err
+		}
+	}
+	return int64(len(leaves)), nil
+}
+
+// root returns the root of the tree of a shard as of the start of the stream
+func (s *entryStream) root(shard TrillianClient) (*ttypes.LogRootV1, error) {
+	if root, ok := s.roots[shard.logID]; ok {
+		return root, nil
+	}
+	root, err := shard.root()
+	if err != nil {
+		return nil, err
+	}
+	s.roots[shard.logID] = &root
+	return &root, nil
+}
+
+// entry returns the entry of a leaf of the tree of a shard whose first entry is at virtual index offset
+func (s *entryStream) entry(shard TrillianClient, root *ttypes.LogRootV1, leaf *trillian.LogLeaf, offset int64) (models.LogEntry, error) {
+	ctx := s.r.Context()
+	uuid, entry, err := signedEntryFromLeaf(ctx, logAPI(ctx).signer, shiftLeaf(leaf, offset))
+	if err != nil {
+		return nil, err
+	}
+	if s.proofs {
+		resp := shard.getInclusionProof(leaf.LeafIndex, leaf.MerkleLeafHash, root)
+		if resp.err != nil {
+			return nil, resp.err
+		}
+		if resp.getInclusionProofResult.GetProof() == nil {
+			return nil, fmt.Errorf("trillian returned no inclusion proof for leaf %d of tree %d", leaf.LeafIndex, shard.logID)
+		}
+		entry.Verification.InclusionProof = inclusionProofFromTrillian(root, resp.getInclusionProofResult.Proof)
+	}
+	return models.LogEntry{uuid: entry}, nil
+}
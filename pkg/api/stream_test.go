@@ -0,0 +1,151 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-openapi/runtime"
+	"github.com/go-openapi/runtime/middleware"
+	"github.com/google/trillian"
+	"github.com/google/trillian/merkle/rfc6962"
+	ttypes "github.com/google/trillian/types"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/sigstore/rekor/pkg/generated/models"
+	"github.com/sigstore/rekor/pkg/generated/restapi/operations/entries"
+	"github.com/sigstore/rekor/pkg/signer"
+)
+
+// fakeLogClient serves a fixed set of leaves; other methods of the interface are not implemented
+type fakeLogClient struct {
+	trillian.TrillianLogClient
+	leaves []*trillian.LogLeaf
+}
+
+func (f *fakeLogClient) GetLatestSignedLogRoot(ctx context.Context, in *trillian.GetLatestSignedLogRootRequest, opts ...grpc.CallOption) (*trillian.GetLatestSignedLogRootResponse, error) {
+	root, err := (&ttypes.LogRootV1{TreeSize: uint64(len(f.leaves)), RootHash: make([]byte, 32)}).MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	return &trillian.GetLatestSignedLogRootResponse{SignedLogRoot: &trillian.SignedLogRoot{LogRoot: root}}, nil
+}
+
+func (f *fakeLogClient) GetLeavesByRange(ctx context.Context, in *trillian.GetLeavesByRangeRequest, opts ...grpc.CallOption) (*trillian.GetLeavesByRangeResponse, error) {
+	end := in.StartIndex + in.Count
+	if end > int64(len(f.leaves)) {
+		end = int64(len(f.leaves))
+	}
+	return &trillian.GetLeavesByRangeResponse{Leaves: f.leaves[in.StartIndex:end]}, nil
+}
+
+// streamEntries serves a request for the stream of entries, with its query bound as by the generated API
+func streamEntries(query string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	params := entries.NewStreamLogEntriesParams()
+	if err := params.BindRequest(httptest.NewRequest(http.MethodGet, StreamPath+query, nil), &middleware.MatchedRoute{}); err != nil {
+		ServeError(rec, params.HTTPRequest, err)
+		return rec
+	}
+	StreamLogEntriesHandler(params).WriteResponse(rec, runtime.JSONProducer())
+	return rec
+}
+
+func TestStreamEntries(t *testing.T) {
+	s, err := signer.NewMemory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fake := &fakeLogClient{}
+	for i := 0; i < 2*streamBatchSize+5; i++ {
+		value := []byte(fmt.Sprintf(`{"entry":%d}`, i))
+		fake.leaves = append(fake.leaves, &trillian.LogLeaf{
+			LeafIndex:          int64(i),
+			LeafValue:          value,
+			MerkleLeafHash:     rfc6962.DefaultHasher.HashLeaf(value),
+			IntegrateTimestamp: timestamppb.Now(),
+		})
+	}
+	oldAPI := api
+	t.Cleanup(func() {
+		api = oldAPI
+	})
+	api = &API{logClient: fake, signer: s, pubkeyHash: "abc"}
+
+	stream := func(query string) (*httptest.ResponseRecorder, []int64) {
+		rec := streamEntries(query)
+		if rec.Code != http.StatusOK {
+			return rec, nil
+		}
+		var indexes []int64
+		scanner := bufio.NewScanner(rec.Body)
+		for scanner.Scan() {
+			entry := models.LogEntry{}
+			if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+				t.Fatalf("invalid line %q: %v", scanner.Text(), err)
+			}
+			for _, e := range entry {
+				if e.Verification == nil || len(e.Verification.SignedEntryTimestamp) == 0 {
+					t.Errorf("entry %d has no signed entry timestamp", *e.LogIndex)
+				}
+				indexes = append(indexes, *e.LogIndex)
+			}
+		}
+		return rec, indexes
+	}
+
+	rec, indexes := stream("")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("unexpected response %d %v", rec.Code, rec.Header())
+	}
+	if len(indexes) != len(fake.leaves) {
+		t.Fatalf("expected %d entries, got %d", len(fake.leaves), len(indexes))
+	}
+	for i, index := range indexes {
+		if index != int64(i) {
+			t.Fatalf("expected entry %d at position %d, got %d", i, i, index)
+		}
+	}
+
+	if _, indexes := stream("?start=99&end=102"); len(indexes) != 3 || indexes[0] != 99 || indexes[2] != 101 {
+		t.Errorf("unexpected entries for range: %v", indexes)
+	}
+	if _, indexes := stream("?start=1000"); len(indexes) != 0 {
+		t.Errorf("expected no entries beyond the tree size, got %v", indexes)
+	}
+
+	for _, tt := range []struct {
+		query string
+		code  int
+	}{
+		{query: "?start=-1", code: http.StatusUnprocessableEntity},
+		{query: "?start=x", code: http.StatusUnprocessableEntity},
+		{query: "?end=-1", code: http.StatusUnprocessableEntity},
+		{query: "?end=x", code: http.StatusUnprocessableEntity},
+		{query: "?start=5&end=4", code: http.StatusBadRequest},
+	} {
+		if rec, _ := stream(tt.query); rec.Code != tt.code {
+			t.Errorf("expected %d for %s, got %d", tt.code, tt.query, rec.Code)
+		}
+	}
+}
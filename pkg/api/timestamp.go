@@ -32,7 +32,8 @@ import (
 )
 
 func RequestFromRekor(ctx context.Context, req pkcs9.TimeStampReq) ([]byte, error) {
-	resp, err := util.CreateRfc3161Response(ctx, req, api.certChain, api.tsaSigner)
+	a := logAPI(ctx)
+	resp, err := util.CreateRfc3161Response(ctx, req, a.certChain, a.tsaSigner)
 	if err != nil {
 		return nil, err
 	}
@@ -86,9 +87,13 @@ func TimestampResponseHandler(params timestamp.GetTimestampResponseParams) middl
 		newIndex = *entry.LogIndex
 	}
 
-	return timestamp.NewGetTimestampResponseCreated().WithPayload(ioutil.NopCloser(bytes.NewReader(resp))).WithLocation(getEntryURL(*cleReq.URL, uuid)).WithETag(uuid).WithIndex(newIndex)
+	return timestamp.NewGetTimestampResponseCreated().WithPayload(ioutil.NopCloser(bytes.NewReader(resp))).WithLocation(getEntryURL(requestURL(&cleReq), uuid)).WithETag(uuid).WithIndex(newIndex)
+}
+
+func TimestampResponseNotImplementedHandler(params timestamp.GetTimestampResponseParams) middleware.Responder {
+	return timestamp.NewGetTimestampResponseNotImplemented()
 }
 
 func GetTimestampCertChainHandler(params timestamp.GetTimestampCertChainParams) middleware.Responder {
-	return timestamp.NewGetTimestampCertChainOK().WithPayload(api.certChainPem)
+	return timestamp.NewGetTimestampCertChainOK().WithPayload(logAPI(params.HTTPRequest.Context()).certChainPem)
 }
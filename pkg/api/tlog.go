@@ -16,6 +16,7 @@
 package api
 
 import (
+	"context"
 	"encoding/hex"
 	"fmt"
 	"net/http"
@@ -23,61 +24,83 @@ import (
 
 	"github.com/go-openapi/runtime/middleware"
 	"github.com/google/trillian/types"
-	"github.com/spf13/viper"
 	"google.golang.org/grpc/codes"
 
 	"github.com/sigstore/rekor/pkg/generated/models"
 	"github.com/sigstore/rekor/pkg/generated/restapi/operations/tlog"
 	"github.com/sigstore/rekor/pkg/util"
+	"github.com/sigstore/sigstore/pkg/signature"
 	"github.com/sigstore/sigstore/pkg/signature/options"
 )
 
 // GetLogInfoHandler returns the current size of the tree and the STH
 func GetLogInfoHandler(params tlog.GetLogInfoParams) middleware.Responder {
-	tc := NewTrillianClient(params.HTTPRequest.Context())
+	root, sth, message, err := signedTreeHead(params.HTTPRequest.Context())
+	if err != nil {
+		return handleRekorAPIError(params, http.StatusInternalServerError, err, message)
+	}
+
+	hashString := hex.EncodeToString(root.RootHash)
+	treeSize := int64(root.TreeSize)
+
+	scBytes, err := sth.SignedNote.MarshalText()
+	if err != nil {
+		return handleRekorAPIError(params, http.StatusInternalServerError, fmt.Errorf("marshalling error: %w", err), sthGenerateError)
+	}
+	scString := string(scBytes)
+
+	logInfo := models.LogInfo{
+		RootHash:       &hashString,
+		TreeSize:       &treeSize,
+		SignedTreeHead: &scString,
+	}
+	return tlog.NewGetLogInfoOK().WithPayload(&logInfo)
+}
+
+// signedTreeHead fetches the latest root of the tree and signs a checkpoint for it; on error, the
+// message to return to the client is returned as well
+func signedTreeHead(ctx context.Context) (*types.LogRootV1, *util.SignedCheckpoint, string, error) {
+	tc := NewTrillianClient(ctx)
 
 	resp := tc.getLatest(0)
 	if resp.status != codes.OK {
-		return handleRekorAPIError(params, http.StatusInternalServerError, fmt.Errorf("grpc error: %w", resp.err), trillianCommunicationError)
+		return nil, nil, trillianCommunicationError, fmt.Errorf("grpc error: %w", resp.err)
 	}
 	result := resp.getLatestResult
 
 	root := &types.LogRootV1{}
 	if err := root.UnmarshalBinary(result.SignedLogRoot.LogRoot); err != nil {
-		return handleRekorAPIError(params, http.StatusInternalServerError, err, trillianUnexpectedResult)
+		return nil, nil, trillianUnexpectedResult, err
 	}
 
-	hashString := hex.EncodeToString(root.RootHash)
-	treeSize := int64(root.TreeSize)
+	sth, message, err := logAPI(ctx).signCheckpoint(ctx, root)
+	if err != nil {
+		return nil, nil, message, err
+	}
+	return root, sth, "", nil
+}
 
+// signCheckpoint signs a checkpoint for the root of one of the trees of the log; on error, the
+// message to return to the client is returned as well
+func (a *API) signCheckpoint(ctx context.Context, root *types.LogRootV1) (*util.SignedCheckpoint, string, error) {
 	sth, err := util.CreateSignedCheckpoint(util.Checkpoint{
-		Origin: "Rekor",
+		Origin: a.checkpointOrigin,
 		Size:   root.TreeSize,
 		Hash:   root.RootHash,
 	})
 	if err != nil {
-		return handleRekorAPIError(params, http.StatusInternalServerError, fmt.Errorf("marshalling error: %w", err), sthGenerateError)
+		return nil, sthGenerateError, fmt.Errorf("marshalling error: %w", err)
 	}
 	sth.SetTimestamp(uint64(time.Now().UnixNano()))
 
-	// sign the log root ourselves to get the log root signature
-	_, err = sth.Sign(viper.GetString("rekor_server.hostname"), api.signer, options.WithContext(params.HTTPRequest.Context()))
-	if err != nil {
-		return handleRekorAPIError(params, http.StatusInternalServerError, fmt.Errorf("signing error: %w", err), signingError)
-	}
-
-	scBytes, err := sth.SignedNote.MarshalText()
-	if err != nil {
-		return handleRekorAPIError(params, http.StatusInternalServerError, fmt.Errorf("marshalling error: %w", err), sthGenerateError)
-	}
-	scString := string(scBytes)
-
-	logInfo := models.LogInfo{
-		RootHash:       &hashString,
-		TreeSize:       &treeSize,
-		SignedTreeHead: &scString,
+	// sign the log root ourselves to get the log root signature; while a key is being rotated, the
+	// previous key signs as well so that clients which do not know the new key yet can verify it
+	for _, s := range append([]signature.Signer{a.signer}, a.cosigners(time.Now())...) {
+		if _, err := sth.Sign(a.signerName, s, options.WithContext(ctx)); err != nil {
+			return nil, signingError, fmt.Errorf("signing error: %w", err)
+		}
 	}
-	return tlog.NewGetLogInfoOK().WithPayload(&logInfo)
+	return sth, "", nil
 }
 
 // GetLogProofHandler returns information required to compute a consistency proof between two snapshots of log
@@ -117,5 +140,12 @@ func GetLogProofHandler(params tlog.GetLogProofParams) middleware.Responder {
 		Hashes:   proofHashes,
 	}
 
-	return tlog.NewGetLogProofOK().WithPayload(&consistencyProof)
+	// the proof is only immutable once the tree is exactly as large as requested, as the root hash
+	// returned alongside it is that of the current tree
+	cacheControl := cacheRevalidate
+	if root.TreeSize == uint64(params.LastSize) {
+		cacheControl = cacheImmutable
+	}
+	etag := fmt.Sprintf(`"%d-%d-%s"`, *params.FirstSize, params.LastSize, hashString)
+	return withCacheHeaders(params.HTTPRequest, etag, cacheControl, tlog.NewGetLogProofOK().WithPayload(&consistencyProof))
 }
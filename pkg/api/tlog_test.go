@@ -0,0 +1,98 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"context"
+	"crypto"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-openapi/runtime"
+	"github.com/google/trillian"
+	"github.com/sigstore/sigstore/pkg/signature"
+
+	"github.com/sigstore/rekor/pkg/generated/models"
+	"github.com/sigstore/rekor/pkg/generated/restapi/operations/tlog"
+	"github.com/sigstore/rekor/pkg/signer"
+	"github.com/sigstore/rekor/pkg/util"
+)
+
+func TestGetLogCheckpointHandler(t *testing.T) {
+	s, err := signer.NewMemory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub, err := s.PublicKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	verifier, err := signature.LoadVerifier(pub, crypto.SHA256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := newMemoryLogClient()
+	for i := 0; i < 3; i++ {
+		leaf := &trillian.LogLeaf{LeafValue: []byte(fmt.Sprintf(`{"entry":%d}`, i))}
+		if _, err := m.QueueLeaf(context.Background(), &trillian.QueueLeafRequest{LogId: 1, Leaf: leaf}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	oldAPI := api
+	t.Cleanup(func() {
+		api = oldAPI
+	})
+	checkpoint := func() *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		params := tlog.GetLogCheckpointParams{HTTPRequest: httptest.NewRequest(http.MethodGet, "/api/v1/log/checkpoint", nil)}
+		GetLogCheckpointHandler(params).WriteResponse(rec, runtime.TextProducer())
+		return rec
+	}
+
+	api = &API{logClient: m, logID: 1, signer: s, checkpointOrigin: "Rekor", signerName: "rekor.example.com", pubkeyHash: "abc"}
+	rec := checkpoint()
+	if rec.Code != http.StatusOK {
+		t.Fatalf("got response %d: %s", rec.Code, rec.Body)
+	}
+	sth := util.SignedCheckpoint{}
+	if err := sth.UnmarshalText(rec.Body.Bytes()); err != nil {
+		t.Fatalf("checkpoint is not a signed note: %v\n%s", err, rec.Body)
+	}
+	if sth.Size != 3 || len(sth.Hash) == 0 {
+		t.Errorf("got checkpoint of tree size %d with hash %x", sth.Size, sth.Hash)
+	}
+	if !sth.Verify(verifier) {
+		t.Error("checkpoint not signed by the key of the log")
+	}
+
+	// errors of the log backend are returned as errors of the API rather than as a checkpoint
+	rule, err := parseFaultRule("GetLatestSignedLogRoot=unavailable")
+	if err != nil {
+		t.Fatal(err)
+	}
+	api = &API{logClient: newFaultLogClient(m, []faultRule{rule}, 0), logID: 1, signer: s, checkpointOrigin: "Rekor", signerName: "rekor.example.com", pubkeyHash: "abc"}
+	rec = checkpoint()
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("got response %d: %s", rec.Code, rec.Body)
+	}
+	e := models.Error{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &e); err != nil || e.Message != trillianCommunicationError {
+		t.Errorf("got error %s, want %q", rec.Body, trillianCommunicationError)
+	}
+}
@@ -32,20 +32,36 @@ import (
 	"github.com/google/trillian"
 	"github.com/google/trillian/client"
 	"github.com/google/trillian/types"
+
+	"github.com/sigstore/rekor/pkg/audit"
 )
 
 type TrillianClient struct {
-	client  trillian.TrillianLogClient
-	logID   int64
-	context context.Context
+	client     trillian.TrillianLogClient
+	logID      int64
+	rpcTimeout time.Duration
+	context    context.Context
+	// the shards of the log; logID is the active one
+	ranges *LogRanges
 }
 
 func NewTrillianClient(ctx context.Context) TrillianClient {
+	a := logAPI(ctx)
 	return TrillianClient{
-		client:  api.logClient,
-		logID:   api.logID,
-		context: ctx,
+		client:     a.logClient,
+		logID:      a.logID,
+		rpcTimeout: a.rpcTimeout,
+		context:    ctx,
+		ranges:     a.logRanges,
+	}
+}
+
+// rpcContext derives the context used for a single request to trillian, bounded by the configured deadline
+func (t *TrillianClient) rpcContext() (context.Context, context.CancelFunc) {
+	if t.rpcTimeout <= 0 {
+		return context.WithCancel(t.context)
 	}
+	return context.WithTimeout(t.context, t.rpcTimeout)
 }
 
 type Response struct {
@@ -56,13 +72,18 @@ type Response struct {
 	getLeafAndProofResult     *trillian.GetEntryAndProofResponse
 	getLatestResult           *trillian.GetLatestSignedLogRootResponse
 	getConsistencyProofResult *trillian.GetConsistencyProofResponse
+	getLeavesByRangeResult    *trillian.GetLeavesByRangeResponse
+	getInclusionProofResult   *trillian.GetInclusionProofResponse
 }
 
 func (t *TrillianClient) root() (types.LogRootV1, error) {
+	ctx, cancel := t.rpcContext()
+	defer cancel()
+
 	rqst := &trillian.GetLatestSignedLogRootRequest{
 		LogId: t.logID,
 	}
-	resp, err := t.client.GetLatestSignedLogRoot(t.context, rqst)
+	resp, err := t.client.GetLatestSignedLogRoot(ctx, rqst)
 	if err != nil {
 		return types.LogRootV1{}, err
 	}
@@ -73,15 +94,20 @@ func (t *TrillianClient) root() (types.LogRootV1, error) {
 	return root, nil
 }
 
-func (t *TrillianClient) addLeaf(byteValue []byte) *Response {
+// addLeaf adds a leaf to the log and waits for it to be integrated; extraData is stored with the
+// leaf but is not part of its Merkle leaf hash
+func (t *TrillianClient) addLeaf(byteValue, extraData []byte) *Response {
 	leaf := &trillian.LogLeaf{
 		LeafValue: byteValue,
+		ExtraData: extraData,
 	}
 	rqst := &trillian.QueueLeafRequest{
 		LogId: t.logID,
 		Leaf:  leaf,
 	}
-	resp, err := t.client.QueueLeaf(t.context, rqst)
+	queueCtx, cancel := t.rpcContext()
+	defer cancel()
+	resp, err := t.client.QueueLeaf(queueCtx, rqst)
 
 	// check for error
 	if err != nil || (resp.QueuedLeaf.Status != nil && resp.QueuedLeaf.Status.Code != int32(codes.OK)) {
@@ -196,7 +222,7 @@ func (t *TrillianClient) getLeafAndProofByHash(hash []byte) *Response {
 }
 
 func (t *TrillianClient) getLeafAndProofByIndex(index int64) *Response {
-	ctx, cancel := context.WithTimeout(t.context, 20*time.Second)
+	ctx, cancel := t.rpcContext()
 	defer cancel()
 
 	root, err := t.root()
@@ -232,7 +258,7 @@ func (t *TrillianClient) getLeafAndProofByIndex(index int64) *Response {
 }
 
 func (t *TrillianClient) getProofByHash(hashValue []byte) *Response {
-	ctx, cancel := context.WithTimeout(t.context, 20*time.Second)
+	ctx, cancel := t.rpcContext()
 	defer cancel()
 
 	root, err := t.root()
@@ -272,7 +298,7 @@ func (t *TrillianClient) getProofByHash(hashValue []byte) *Response {
 
 func (t *TrillianClient) getLatest(leafSizeInt int64) *Response {
 
-	ctx, cancel := context.WithTimeout(t.context, 20*time.Second)
+	ctx, cancel := t.rpcContext()
 	defer cancel()
 
 	resp, err := t.client.GetLatestSignedLogRoot(ctx,
@@ -290,7 +316,7 @@ func (t *TrillianClient) getLatest(leafSizeInt int64) *Response {
 
 func (t *TrillianClient) getConsistencyProof(firstSize, lastSize int64) *Response {
 
-	ctx, cancel := context.WithTimeout(t.context, 20*time.Second)
+	ctx, cancel := t.rpcContext()
 	defer cancel()
 
 	resp, err := t.client.GetConsistencyProof(ctx,
@@ -307,6 +333,74 @@ func (t *TrillianClient) getConsistencyProof(firstSize, lastSize int64) *Respons
 	}
 }
 
+// getLeavesByRange returns up to count consecutive leaves starting at index start
+func (t *TrillianClient) getLeavesByRange(start, count int64) *Response {
+	ctx, cancel := t.rpcContext()
+	defer cancel()
+
+	resp, err := t.client.GetLeavesByRange(ctx,
+		&trillian.GetLeavesByRangeRequest{
+			LogId:      t.logID,
+			StartIndex: start,
+			Count:      count,
+		})
+
+	return &Response{
+		status:                 status.Code(err),
+		err:                    err,
+		getLeavesByRangeResult: resp,
+	}
+}
+
+// getInclusionProof returns the inclusion proof of the leaf with the given hash and index in the tree with the given root
+func (t *TrillianClient) getInclusionProof(index int64, leafHash []byte, root *types.LogRootV1) *Response {
+	ctx, cancel := t.rpcContext()
+	defer cancel()
+
+	resp, err := t.client.GetInclusionProof(ctx,
+		&trillian.GetInclusionProofRequest{
+			LogId:     t.logID,
+			LeafIndex: index,
+			TreeSize:  int64(root.TreeSize),
+		})
+
+	if resp != nil && resp.Proof != nil {
+		logVerifier := logverifier.New(rfc6962.DefaultHasher)
+		if err := logVerifier.VerifyInclusionProof(index, int64(root.TreeSize), resp.Proof.Hashes, root.RootHash, leafHash); err != nil {
+			return &Response{
+				status: status.Code(err),
+				err:    err,
+			}
+		}
+	}
+
+	return &Response{
+		status:                  status.Code(err),
+		err:                     err,
+		getInclusionProofResult: resp,
+	}
+}
+
+// getInclusionProofAtSize returns the inclusion proof of the leaf at index in the tree of an earlier size, whose
+// root the log does not keep; it is up to the caller to check the root the proof leads to
+func (t *TrillianClient) getInclusionProofAtSize(index, treeSize int64) *Response {
+	ctx, cancel := t.rpcContext()
+	defer cancel()
+
+	resp, err := t.client.GetInclusionProof(ctx,
+		&trillian.GetInclusionProofRequest{
+			LogId:     t.logID,
+			LeafIndex: index,
+			TreeSize:  treeSize,
+		})
+
+	return &Response{
+		status:                  status.Code(err),
+		err:                     err,
+		getInclusionProofResult: resp,
+	}
+}
+
 func createAndInitTree(ctx context.Context, adminClient trillian.TrillianAdminClient, logClient trillian.TrillianLogClient) (*trillian.Tree, error) {
 	// First look for and use an existing tree
 	trees, err := adminClient.ListTrees(ctx, &trillian.ListTreesRequest{})
@@ -321,6 +415,11 @@ func createAndInitTree(ctx context.Context, adminClient trillian.TrillianAdminCl
 	}
 
 	// Otherwise create and initialize one
+	return createTree(ctx, adminClient, logClient)
+}
+
+// createTree creates and initializes a new log tree
+func createTree(ctx context.Context, adminClient trillian.TrillianAdminClient, logClient trillian.TrillianLogClient) (*trillian.Tree, error) {
 	t, err := adminClient.CreateTree(ctx, &trillian.CreateTreeRequest{
 		Tree: &trillian.Tree{
 			TreeType:        trillian.TreeType_LOG,
@@ -329,11 +428,14 @@ func createAndInitTree(ctx context.Context, adminClient trillian.TrillianAdminCl
 		},
 	})
 	if err != nil {
+		writeAudit(audit.Record{Action: audit.ActionCreateTree, Outcome: audit.OutcomeError, Detail: err.Error()})
 		return nil, errors.Wrap(err, "create tree")
 	}
 
 	if err := client.InitLog(ctx, t, logClient); err != nil {
+		writeAudit(audit.Record{Action: audit.ActionCreateTree, Outcome: audit.OutcomeError, TreeID: t.TreeId, Detail: err.Error()})
 		return nil, errors.Wrap(err, "init log")
 	}
+	writeAudit(audit.Record{Action: audit.ActionCreateTree, Outcome: audit.OutcomeSuccess, TreeID: t.TreeId})
 	return t, nil
 }
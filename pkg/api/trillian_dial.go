@@ -0,0 +1,216 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/keepalive"
+)
+
+// grpc-go silently caps maxAttempts in a retry policy at this value
+const maxGRPCAttempts = 5
+
+// trillianConnConfig holds the trillian_log_server.* settings used to connect to the Trillian log server
+type trillianConnConfig struct {
+	Address string `mapstructure:"address"`
+	Port    uint   `mapstructure:"port"`
+	// tree of the log; if unset the first existing tree is used, or a new one is created
+	TreeID int64 `mapstructure:"tlog_id"`
+
+	TLS           bool   `mapstructure:"tls"`
+	TLSCACert     string `mapstructure:"tls_ca_cert"`
+	TLSClientCert string `mapstructure:"tls_client_cert"`
+	TLSClientKey  string `mapstructure:"tls_client_key"`
+	TLSServerName string `mapstructure:"tls_server_name"`
+
+	RPCTimeout time.Duration `mapstructure:"rpc_timeout"`
+
+	MaxRetries          uint          `mapstructure:"max_retries"`
+	RetryInitialBackoff time.Duration `mapstructure:"retry_initial_backoff"`
+	RetryMaxBackoff     time.Duration `mapstructure:"retry_max_backoff"`
+
+	KeepaliveTime       time.Duration `mapstructure:"keepalive_time"`
+	KeepaliveTimeout    time.Duration `mapstructure:"keepalive_timeout"`
+	LoadBalancingPolicy string        `mapstructure:"load_balancing_policy"`
+}
+
+func (c trillianConnConfig) validate() error {
+	if c.Address == "" || c.Port == 0 {
+		return errors.New("address and port must be set")
+	}
+	if (c.TLSClientCert == "") != (c.TLSClientKey == "") {
+		return errors.New("both a client certificate and key must be specified for mutual TLS")
+	}
+	_, err := c.serviceConfig()
+	return err
+}
+
+func (c trillianConnConfig) target() string {
+	return fmt.Sprintf("%s:%d", c.Address, c.Port)
+}
+
+// transportCredentials returns insecure credentials unless TLS has been requested, in which
+// case the optional CA certificate and client keypair (for mutual TLS) are loaded from disk
+func (c trillianConnConfig) transportCredentials() (credentials.TransportCredentials, error) {
+	if !c.TLS {
+		return insecure.NewCredentials(), nil
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ServerName: c.TLSServerName,
+	}
+	if c.TLSCACert != "" {
+		caBytes, err := ioutil.ReadFile(filepath.Clean(c.TLSCACert))
+		if err != nil {
+			return nil, errors.Wrap(err, "reading trillian CA certificate")
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caBytes) {
+			return nil, errors.New("no valid certificates found in trillian CA certificate file")
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if c.TLSClientCert != "" || c.TLSClientKey != "" {
+		if c.TLSClientCert == "" || c.TLSClientKey == "" {
+			return nil, errors.New("both a client certificate and key must be specified for mutual TLS")
+		}
+		keyPair, err := tls.LoadX509KeyPair(c.TLSClientCert, c.TLSClientKey)
+		if err != nil {
+			return nil, errors.Wrap(err, "loading trillian client keypair")
+		}
+		tlsConfig.Certificates = []tls.Certificate{keyPair}
+	}
+	return credentials.NewTLS(tlsConfig), nil
+}
+
+type grpcServiceName struct {
+	Service string `json:"service"`
+}
+
+type grpcRetryPolicy struct {
+	MaxAttempts          uint     `json:"maxAttempts"`
+	InitialBackoff       string   `json:"initialBackoff"`
+	MaxBackoff           string   `json:"maxBackoff"`
+	BackoffMultiplier    float64  `json:"backoffMultiplier"`
+	RetryableStatusCodes []string `json:"retryableStatusCodes"`
+}
+
+type grpcMethodConfig struct {
+	Name        []grpcServiceName `json:"name"`
+	RetryPolicy *grpcRetryPolicy  `json:"retryPolicy,omitempty"`
+}
+
+type grpcServiceConfig struct {
+	LoadBalancingConfig []map[string]struct{} `json:"loadBalancingConfig,omitempty"`
+	MethodConfig        []grpcMethodConfig    `json:"methodConfig,omitempty"`
+}
+
+// grpcDuration formats a duration in the form expected by the gRPC service config
+func grpcDuration(d time.Duration) string {
+	return fmt.Sprintf("%gs", d.Seconds())
+}
+
+// serviceConfig renders the gRPC service config JSON which carries the load balancing and retry policies
+func (c trillianConnConfig) serviceConfig() (string, error) {
+	sc := grpcServiceConfig{}
+
+	switch c.LoadBalancingPolicy {
+	case "", "pick_first":
+	case "round_robin":
+		sc.LoadBalancingConfig = []map[string]struct{}{{c.LoadBalancingPolicy: {}}}
+	default:
+		return "", fmt.Errorf("unsupported load balancing policy %q", c.LoadBalancingPolicy)
+	}
+
+	if c.MaxRetries > 0 {
+		attempts := c.MaxRetries + 1
+		if attempts > maxGRPCAttempts {
+			attempts = maxGRPCAttempts
+		}
+		if c.RetryInitialBackoff <= 0 || c.RetryMaxBackoff < c.RetryInitialBackoff {
+			return "", fmt.Errorf("invalid retry backoff: initial %v, max %v", c.RetryInitialBackoff, c.RetryMaxBackoff)
+		}
+		sc.MethodConfig = []grpcMethodConfig{
+			{
+				Name: []grpcServiceName{
+					{Service: "trillian.TrillianLog"},
+					{Service: "trillian.TrillianAdmin"},
+				},
+				RetryPolicy: &grpcRetryPolicy{
+					MaxAttempts:          attempts,
+					InitialBackoff:       grpcDuration(c.RetryInitialBackoff),
+					MaxBackoff:           grpcDuration(c.RetryMaxBackoff),
+					BackoffMultiplier:    2,
+					RetryableStatusCodes: []string{"UNAVAILABLE", "RESOURCE_EXHAUSTED"},
+				},
+			},
+		}
+	}
+
+	b, err := json.Marshal(sc)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+func (c trillianConnConfig) dialOptions() ([]grpc.DialOption, error) {
+	creds, err := c.transportCredentials()
+	if err != nil {
+		return nil, err
+	}
+	sc, err := c.serviceConfig()
+	if err != nil {
+		return nil, err
+	}
+	opts := []grpc.DialOption{
+		grpc.WithTransportCredentials(creds),
+		grpc.WithDefaultServiceConfig(sc),
+	}
+	if c.KeepaliveTime > 0 {
+		opts = append(opts, grpc.WithKeepaliveParams(keepalive.ClientParameters{
+			Time:                c.KeepaliveTime,
+			Timeout:             c.KeepaliveTimeout,
+			PermitWithoutStream: true,
+		}))
+	}
+	return opts, nil
+}
+
+func dial(ctx context.Context, cfg trillianConnConfig) (*grpc.ClientConn, error) {
+	opts, err := cfg.dialOptions()
+	if err != nil {
+		return nil, errors.Wrap(err, "configuring trillian connection")
+	}
+	conn, err := grpc.DialContext(ctx, cfg.target(), opts...)
+	if err != nil {
+		return nil, errors.Wrapf(err, "connecting to trillian at %s", cfg.target())
+	}
+	return conn, nil
+}
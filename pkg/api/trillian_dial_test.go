@@ -0,0 +1,108 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTrillianConnConfig_ServiceConfig(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		cfg     trillianConnConfig
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "defaults",
+			cfg:  trillianConnConfig{LoadBalancingPolicy: "pick_first"},
+			want: `{}`,
+		},
+		{
+			name: "round robin",
+			cfg:  trillianConnConfig{LoadBalancingPolicy: "round_robin"},
+			want: `{"loadBalancingConfig":[{"round_robin":{}}]}`,
+		},
+		{
+			name:    "unknown policy",
+			cfg:     trillianConnConfig{LoadBalancingPolicy: "random"},
+			wantErr: true,
+		},
+		{
+			name: "retries",
+			cfg: trillianConnConfig{
+				MaxRetries:          2,
+				RetryInitialBackoff: 100 * time.Millisecond,
+				RetryMaxBackoff:     2 * time.Second,
+			},
+			want: `{"methodConfig":[{"name":[{"service":"trillian.TrillianLog"},{"service":"trillian.TrillianAdmin"}],"retryPolicy":{"maxAttempts":3,"initialBackoff":"0.1s","maxBackoff":"2s","backoffMultiplier":2,"retryableStatusCodes":["UNAVAILABLE","RESOURCE_EXHAUSTED"]}}]}`,
+		},
+		{
+			name: "retries are capped",
+			cfg: trillianConnConfig{
+				MaxRetries:          10,
+				RetryInitialBackoff: time.Second,
+				RetryMaxBackoff:     time.Second,
+			},
+			want: `{"methodConfig":[{"name":[{"service":"trillian.TrillianLog"},{"service":"trillian.TrillianAdmin"}],"retryPolicy":{"maxAttempts":5,"initialBackoff":"1s","maxBackoff":"1s","backoffMultiplier":2,"retryableStatusCodes":["UNAVAILABLE","RESOURCE_EXHAUSTED"]}}]}`,
+		},
+		{
+			name: "invalid backoff",
+			cfg: trillianConnConfig{
+				MaxRetries:          1,
+				RetryInitialBackoff: time.Second,
+				RetryMaxBackoff:     time.Millisecond,
+			},
+			wantErr: true,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.cfg.serviceConfig()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("serviceConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("serviceConfig() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTrillianConnConfig_TransportCredentials(t *testing.T) {
+	creds, err := trillianConnConfig{}.transportCredentials()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if creds.Info().SecurityProtocol != "insecure" {
+		t.Errorf("expected insecure credentials without TLS, got %v", creds.Info().SecurityProtocol)
+	}
+
+	creds, err = trillianConnConfig{TLS: true}.transportCredentials()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if creds.Info().SecurityProtocol != "tls" {
+		t.Errorf("expected tls credentials, got %v", creds.Info().SecurityProtocol)
+	}
+
+	if _, err := (trillianConnConfig{TLS: true, TLSClientCert: "cert.pem"}).transportCredentials(); err == nil {
+		t.Error("expected error when client key is missing")
+	}
+	if _, err := (trillianConnConfig{TLS: true, TLSCACert: "/does/not/exist"}).transportCredentials(); err == nil {
+		t.Error("expected error when CA file is missing")
+	}
+}
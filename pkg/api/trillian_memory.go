@@ -0,0 +1,329 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"context"
+	"crypto/sha256"
+	"math/bits"
+	"sync"
+	"time"
+
+	"github.com/google/trillian"
+	"github.com/google/trillian/merkle/rfc6962"
+	ttypes "github.com/google/trillian/types"
+	"google.golang.org/genproto/googleapis/rpc/code"
+	rpcstatus "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// memoryTreeID is the tree used by the default log in development mode
+const memoryTreeID = 1
+
+// memoryLogClient is a Trillian log kept in memory for development and testing. Leaves are integrated
+// as soon as they are queued and are lost when the process exits. Only the requests made by rekor are
+// implemented.
+type memoryLogClient struct {
+	trillian.TrillianLogClient
+
+	mu    sync.RWMutex
+	trees map[int64]*memoryTree
+	// trees which no longer accept leaves, such as the archives of frozen shards
+	frozen map[int64]bool
+}
+
+type memoryTree struct {
+	leaves   []*trillian.LogLeaf
+	hashes   [][]byte         // Merkle leaf hashes, by index
+	byHash   map[string]int64 // index of each leaf by its Merkle leaf hash
+	byValue  map[string]int64 // index of each leaf by the hash of its value, to reject duplicates
+	revision uint64
+}
+
+func newMemoryTree() *memoryTree {
+	return &memoryTree{byHash: map[string]int64{}, byValue: map[string]int64{}}
+}
+
+func newMemoryLogClient() *memoryLogClient {
+	return &memoryLogClient{trees: map[int64]*memoryTree{}, frozen: map[int64]bool{}}
+}
+
+// addFrozenTree adds a tree whose leaves are all known and to which no leaves can be added
+func (m *memoryLogClient) addFrozenTree(id int64, t *memoryTree) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.trees[id] = t
+	m.frozen[id] = true
+}
+
+// tree returns the tree with the given ID; trees are empty until a leaf is first added to them
+func (m *memoryLogClient) tree(id int64) *memoryTree {
+	if t, ok := m.trees[id]; ok {
+		return t
+	}
+	return &memoryTree{}
+}
+
+func (m *memoryLogClient) QueueLeaf(ctx context.Context, in *trillian.QueueLeafRequest, opts ...grpc.CallOption) (*trillian.QueueLeafResponse, error) {
+	if in.Leaf == nil {
+		return nil, status.Error(codes.InvalidArgument, "missing leaf")
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.frozen[in.LogId] {
+		return nil, status.Errorf(codes.FailedPrecondition, "tree %d is frozen", in.LogId)
+	}
+	t, ok := m.trees[in.LogId]
+	if !ok {
+		t = newMemoryTree()
+		m.trees[in.LogId] = t
+	}
+
+	identity := sha256.Sum256(in.Leaf.LeafValue)
+	if index, ok := t.byValue[string(identity[:])]; ok {
+		return &trillian.QueueLeafResponse{QueuedLeaf: &trillian.QueuedLogLeaf{
+			Leaf:   t.leaves[index],
+			Status: &rpcstatus.Status{Code: int32(code.Code_ALREADY_EXISTS), Message: "leaf already exists"},
+		}}, nil
+	}
+
+	leaf := t.appendLeaf(in.Leaf.LeafValue, in.Leaf.ExtraData, timestamppb.Now())
+	t.revision++
+	return &trillian.QueueLeafResponse{QueuedLeaf: &trillian.QueuedLogLeaf{Leaf: leaf}}, nil
+}
+
+// appendLeaf adds a leaf integrated at the given time to the end of the tree; the caller checks that
+// no leaf with the same value is already in it
+func (t *memoryTree) appendLeaf(value, extraData []byte, integrated *timestamppb.Timestamp) *trillian.LogLeaf {
+	identity := sha256.Sum256(value)
+	leaf := &trillian.LogLeaf{
+		MerkleLeafHash:     rfc6962.DefaultHasher.HashLeaf(value),
+		LeafValue:          value,
+		ExtraData:          extraData,
+		LeafIndex:          int64(len(t.leaves)),
+		LeafIdentityHash:   identity[:],
+		QueueTimestamp:     integrated,
+		IntegrateTimestamp: integrated,
+	}
+	t.leaves = append(t.leaves, leaf)
+	t.hashes = append(t.hashes, leaf.MerkleLeafHash)
+	t.byValue[string(identity[:])] = leaf.LeafIndex
+	if _, ok := t.byHash[string(leaf.MerkleLeafHash)]; !ok {
+		t.byHash[string(leaf.MerkleLeafHash)] = leaf.LeafIndex
+	}
+	return leaf
+}
+
+// signedRoot returns the root of the first size leaves of the tree
+func (t *memoryTree) signedRoot(size int64) (*trillian.SignedLogRoot, error) {
+	root, err := (&ttypes.LogRootV1{
+		TreeSize:       uint64(size),
+		RootHash:       merkleRoot(t.hashes[:size]),
+		TimestampNanos: uint64(time.Now().UnixNano()),
+		Revision:       t.revision,
+	}).MarshalBinary()
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &trillian.SignedLogRoot{LogRoot: root}, nil
+}
+
+// checkSize checks that a tree size requested is no larger than the tree
+func (t *memoryTree) checkSize(size int64) error {
+	if size < 0 || size > int64(len(t.leaves)) {
+		return status.Errorf(codes.OutOfRange, "tree size %d is out of range [0, %d]", size, len(t.leaves))
+	}
+	return nil
+}
+
+func (m *memoryLogClient) GetLatestSignedLogRoot(ctx context.Context, in *trillian.GetLatestSignedLogRootRequest, opts ...grpc.CallOption) (*trillian.GetLatestSignedLogRootResponse, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	t := m.tree(in.LogId)
+
+	size := int64(len(t.leaves))
+	root, err := t.signedRoot(size)
+	if err != nil {
+		return nil, err
+	}
+	resp := &trillian.GetLatestSignedLogRootResponse{SignedLogRoot: root}
+	if in.FirstTreeSize > 0 && in.FirstTreeSize < size {
+		resp.Proof = &trillian.Proof{Hashes: consistencyProof(in.FirstTreeSize, t.hashes)}
+	}
+	return resp, nil
+}
+
+func (m *memoryLogClient) GetInclusionProofByHash(ctx context.Context, in *trillian.GetInclusionProofByHashRequest, opts ...grpc.CallOption) (*trillian.GetInclusionProofByHashResponse, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	t := m.tree(in.LogId)
+
+	if err := t.checkSize(in.TreeSize); err != nil {
+		return nil, err
+	}
+	index, ok := t.byHash[string(in.LeafHash)]
+	if !ok || index >= in.TreeSize {
+		return nil, status.Error(codes.NotFound, "leaf not found")
+	}
+	root, err := t.signedRoot(in.TreeSize)
+	if err != nil {
+		return nil, err
+	}
+	return &trillian.GetInclusionProofByHashResponse{
+		Proof:         []*trillian.Proof{{LeafIndex: index, Hashes: inclusionProof(index, t.hashes[:in.TreeSize])}},
+		SignedLogRoot: root,
+	}, nil
+}
+
+func (m *memoryLogClient) GetInclusionProof(ctx context.Context, in *trillian.GetInclusionProofRequest, opts ...grpc.CallOption) (*trillian.GetInclusionProofResponse, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	t := m.tree(in.LogId)
+
+	if err := t.checkSize(in.TreeSize); err != nil {
+		return nil, err
+	}
+	if in.LeafIndex < 0 || in.LeafIndex >= in.TreeSize {
+		return nil, status.Errorf(codes.OutOfRange, "leaf index %d is out of range for tree size %d", in.LeafIndex, in.TreeSize)
+	}
+	root, err := t.signedRoot(in.TreeSize)
+	if err != nil {
+		return nil, err
+	}
+	return &trillian.GetInclusionProofResponse{
+		Proof:         &trillian.Proof{LeafIndex: in.LeafIndex, Hashes: inclusionProof(in.LeafIndex, t.hashes[:in.TreeSize])},
+		SignedLogRoot: root,
+	}, nil
+}
+
+func (m *memoryLogClient) GetEntryAndProof(ctx context.Context, in *trillian.GetEntryAndProofRequest, opts ...grpc.CallOption) (*trillian.GetEntryAndProofResponse, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	t := m.tree(in.LogId)
+
+	if err := t.checkSize(in.TreeSize); err != nil {
+		return nil, err
+	}
+	if in.LeafIndex < 0 || in.LeafIndex >= in.TreeSize {
+		return nil, status.Errorf(codes.OutOfRange, "leaf index %d is out of range for tree size %d", in.LeafIndex, in.TreeSize)
+	}
+	root, err := t.signedRoot(in.TreeSize)
+	if err != nil {
+		return nil, err
+	}
+	return &trillian.GetEntryAndProofResponse{
+		Proof:         &trillian.Proof{LeafIndex: in.LeafIndex, Hashes: inclusionProof(in.LeafIndex, t.hashes[:in.TreeSize])},
+		Leaf:          t.leaves[in.LeafIndex],
+		SignedLogRoot: root,
+	}, nil
+}
+
+func (m *memoryLogClient) GetConsistencyProof(ctx context.Context, in *trillian.GetConsistencyProofRequest, opts ...grpc.CallOption) (*trillian.GetConsistencyProofResponse, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	t := m.tree(in.LogId)
+
+	if in.FirstTreeSize <= 0 || in.FirstTreeSize > in.SecondTreeSize {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid tree sizes %d and %d", in.FirstTreeSize, in.SecondTreeSize)
+	}
+	root, err := t.signedRoot(int64(len(t.leaves)))
+	if err != nil {
+		return nil, err
+	}
+	// as trillian does, no proof is returned if the second tree is larger than the log
+	resp := &trillian.GetConsistencyProofResponse{SignedLogRoot: root}
+	if in.SecondTreeSize <= int64(len(t.leaves)) {
+		resp.Proof = &trillian.Proof{Hashes: consistencyProof(in.FirstTreeSize, t.hashes[:in.SecondTreeSize])}
+	}
+	return resp, nil
+}
+
+func (m *memoryLogClient) GetLeavesByRange(ctx context.Context, in *trillian.GetLeavesByRangeRequest, opts ...grpc.CallOption) (*trillian.GetLeavesByRangeResponse, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	t := m.tree(in.LogId)
+
+	size := int64(len(t.leaves))
+	if in.StartIndex < 0 || in.Count <= 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid range of %d leaves from %d", in.Count, in.StartIndex)
+	}
+	if in.StartIndex >= size {
+		return nil, status.Errorf(codes.OutOfRange, "start index %d is beyond the tree size %d", in.StartIndex, size)
+	}
+	end := in.StartIndex + in.Count
+	if end > size {
+		end = size
+	}
+	root, err := t.signedRoot(size)
+	if err != nil {
+		return nil, err
+	}
+	return &trillian.GetLeavesByRangeResponse{Leaves: t.leaves[in.StartIndex:end], SignedLogRoot: root}, nil
+}
+
+// split returns the largest power of two smaller than n, for n > 1, where RFC 6962 splits a tree
+func split(n int64) int64 {
+	return int64(1) << (bits.Len64(uint64(n-1)) - 1)
+}
+
+// merkleRoot computes the RFC 6962 Merkle tree hash of a list of leaf hashes
+func merkleRoot(leaves [][]byte) []byte {
+	switch n := int64(len(leaves)); n {
+	case 0:
+		return rfc6962.DefaultHasher.EmptyRoot()
+	case 1:
+		return leaves[0]
+	default:
+		k := split(n)
+		return rfc6962.DefaultHasher.HashChildren(merkleRoot(leaves[:k]), merkleRoot(leaves[k:]))
+	}
+}
+
+// inclusionProof computes the RFC 6962 audit path of the leaf at index m, ordered from the leaf to the root
+func inclusionProof(m int64, leaves [][]byte) [][]byte {
+	n := int64(len(leaves))
+	if n <= 1 {
+		return [][]byte{}
+	}
+	k := split(n)
+	if m < k {
+		return append(inclusionProof(m, leaves[:k]), merkleRoot(leaves[k:]))
+	}
+	return append(inclusionProof(m-k, leaves[k:]), merkleRoot(leaves[:k]))
+}
+
+// consistencyProof computes the RFC 6962 consistency proof between the first m leaves and all of them
+func consistencyProof(m int64, leaves [][]byte) [][]byte {
+	return subproof(m, leaves, true)
+}
+
+func subproof(m int64, leaves [][]byte, complete bool) [][]byte {
+	n := int64(len(leaves))
+	if m == n {
+		if complete {
+			return [][]byte{}
+		}
+		return [][]byte{merkleRoot(leaves)}
+	}
+	k := split(n)
+	if m <= k {
+		return append(subproof(m, leaves[:k], complete), merkleRoot(leaves[k:]))
+	}
+	return append(subproof(m-k, leaves[k:], false), merkleRoot(leaves[:k]))
+}
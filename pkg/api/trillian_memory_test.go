@@ -0,0 +1,113 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/google/trillian/merkle/logverifier"
+	"github.com/google/trillian/merkle/rfc6962"
+	"google.golang.org/genproto/googleapis/rpc/code"
+	"google.golang.org/grpc/codes"
+)
+
+func TestMemoryLogClient(t *testing.T) {
+	tc := TrillianClient{client: newMemoryLogClient(), logID: memoryTreeID, context: context.Background()}
+
+	const n = 13
+	for i := 0; i < n; i++ {
+		resp := tc.addLeaf([]byte(fmt.Sprintf("leaf %d", i)), nil)
+		if resp.err != nil {
+			t.Fatalf("adding leaf %d: %v", i, resp.err)
+		}
+		if got := resp.getAddResult.QueuedLeaf.Leaf.LeafIndex; got != int64(i) {
+			t.Fatalf("leaf %d was added at index %d", i, got)
+		}
+	}
+	resp := tc.addLeaf([]byte("leaf 3"), nil)
+	if resp.status != codes.OK || resp.getAddResult.QueuedLeaf.Status.GetCode() != int32(code.Code_ALREADY_EXISTS) {
+		t.Fatalf("expected duplicate leaf to be rejected, got %v %v", resp.err, resp.getAddResult.QueuedLeaf.Status)
+	}
+
+	root, err := tc.root()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if root.TreeSize != n {
+		t.Fatalf("tree size %d, want %d", root.TreeSize, n)
+	}
+
+	// inclusion proofs are checked against the root by the trillian client
+	for i := int64(0); i < n; i++ {
+		resp := tc.getLeafAndProofByIndex(i)
+		if resp.err != nil {
+			t.Fatalf("getting leaf %d: %v", i, resp.err)
+		}
+		if got := string(resp.getLeafAndProofResult.Leaf.LeafValue); got != fmt.Sprintf("leaf %d", i) {
+			t.Fatalf("leaf %d has value %q", i, got)
+		}
+	}
+	if resp := tc.getLeafAndProofByHash(rfc6962.DefaultHasher.HashLeaf([]byte("leaf 7"))); resp.err != nil || resp.getLeafAndProofResult.Leaf.LeafIndex != 7 {
+		t.Fatalf("unexpected result getting leaf by hash: %v", resp.err)
+	}
+	if resp := tc.getProofByHash(rfc6962.DefaultHasher.HashLeaf([]byte("missing"))); resp.status != codes.NotFound {
+		t.Fatalf("expected missing leaf not to be found, got %v", resp.err)
+	}
+
+	v := logverifier.New(rfc6962.DefaultHasher)
+	for first := int64(1); first < n; first++ {
+		old := merkleRoot(tc.client.(*memoryLogClient).trees[memoryTreeID].hashes[:first])
+		resp := tc.getConsistencyProof(first, n)
+		if resp.err != nil {
+			t.Fatal(resp.err)
+		}
+		if err := v.VerifyConsistencyProof(first, n, old, root.RootHash, resp.getConsistencyProofResult.Proof.Hashes); err != nil {
+			t.Fatalf("consistency proof from %d: %v", first, err)
+		}
+	}
+
+	leaves := tc.getLeavesByRange(10, 5)
+	if leaves.err != nil || len(leaves.getLeavesByRangeResult.Leaves) != 3 {
+		t.Fatalf("unexpected leaves returned from 10: %v", leaves.err)
+	}
+
+	// other trees are independent
+	other := TrillianClient{client: tc.client, logID: memoryTreeID + 1, context: context.Background()}
+	if root, err := other.root(); err != nil || root.TreeSize != 0 {
+		t.Fatalf("unexpected root of empty tree: %v %v", root, err)
+	}
+}
+
+func TestMemoryIndex(t *testing.T) {
+	ctx := context.Background()
+	index := newMemoryIndex()
+	for _, uuid := range []string{"a", "b", "c"} {
+		if err := index.add(ctx, "key", uuid); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// as with redis, the most recently added values come first
+	got, err := index.lookup(ctx, "key")
+	if err != nil || !reflect.DeepEqual(got, []string{"c", "b", "a"}) {
+		t.Fatalf("lookup returned %v %v", got, err)
+	}
+	if got, err := index.lookup(ctx, "missing"); err != nil || len(got) != 0 {
+		t.Fatalf("lookup of missing key returned %v %v", got, err)
+	}
+}
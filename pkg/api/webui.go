@@ -0,0 +1,69 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+	"strings"
+)
+
+// WebUIPath is the prefix under which the web interface for browsing and verifying entries is served
+const WebUIPath = "/ui/"
+
+//go:embed webui
+var webUIFiles embed.FS
+
+// the page only talks to the API of the server it is served from
+const webUIContentSecurityPolicy = "default-src 'self'; frame-ancestors 'none'; base-uri 'none'; form-action 'none'"
+
+// ServeWebUI serves the web interface under WebUIPath if enable_web_ui is set; other requests are
+// passed on to handler. The interface queries the API relative to its own location, so it is also
+// available for each additional log under /logs/<name>/ui/.
+func ServeWebUI(handler http.Handler) http.Handler {
+	if startupConfig == nil || !startupConfig.EnableWebUI {
+		return handler
+	}
+	files, err := fs.Sub(webUIFiles, "webui")
+	if err != nil {
+		panic(err)
+	}
+	fileServer := http.StripPrefix(strings.TrimSuffix(WebUIPath, "/"), http.FileServer(http.FS(files)))
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.URL.Path+"/", WebUIPath) {
+			handler.ServeHTTP(w, r)
+			return
+		}
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			writeErrorResponse(w, http.StatusMethodNotAllowed, "")
+			return
+		}
+		if r.URL.Path+"/" == WebUIPath {
+			// the page refers to its scripts and the API by relative URLs
+			u := requestURL(r)
+			u.Path += "/"
+			http.Redirect(w, r, u.RequestURI(), http.StatusMovedPermanently)
+			return
+		}
+		w.Header().Set("Content-Security-Policy", webUIContentSecurityPolicy)
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		w.Header().Set("Cache-Control", cacheRevalidate)
+		fileServer.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,362 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+'use strict';
+
+// the API is resolved relative to the page so that the logs served under /logs/<name>/ work as well
+const apiBase = new URL('../api/v1/', document.baseURI);
+
+class APIError extends Error {
+  constructor(status, message) {
+    super(message);
+    this.status = status;
+  }
+}
+
+async function request(path, init = {}) {
+  const headers = Object.assign({ 'Accept': 'application/json' }, init.headers);
+  const resp = await fetch(new URL(path, apiBase), Object.assign({}, init, { headers }));
+  const text = await resp.text();
+  let payload = text;
+  if ((resp.headers.get('Content-Type') || '').startsWith('application/json')) {
+    payload = JSON.parse(text);
+  }
+  if (!resp.ok) {
+    throw new APIError(resp.status, (payload && payload.message) || resp.statusText);
+  }
+  return payload;
+}
+
+function fromHex(s) {
+  if (s.length % 2 !== 0 || !/^[0-9a-fA-F]*$/.test(s)) {
+    throw new Error('invalid hex string');
+  }
+  const b = new Uint8Array(s.length / 2);
+  for (let i = 0; i < b.length; i++) {
+    b[i] = parseInt(s.substr(2 * i, 2), 16);
+  }
+  return b;
+}
+
+function toHex(b) {
+  return Array.from(b, (x) => x.toString(16).padStart(2, '0')).join('');
+}
+
+function fromBase64(s) {
+  return Uint8Array.from(atob(s), (c) => c.charCodeAt(0));
+}
+
+function equal(a, b) {
+  return a.length === b.length && a.every((x, i) => x === b[i]);
+}
+
+async function sha256(...parts) {
+  const data = new Uint8Array(parts.reduce((n, p) => n + p.length, 0));
+  let offset = 0;
+  for (const p of parts) {
+    data.set(p, offset);
+    offset += p.length;
+  }
+  return new Uint8Array(await crypto.subtle.digest('SHA-256', data));
+}
+
+// RFC 6962 section 2.1 hashes of leaves and interior nodes of the Merkle tree
+const leafHash = (leaf) => sha256(Uint8Array.of(0), leaf);
+const nodeHash = (left, right) => sha256(Uint8Array.of(1), left, right);
+
+// rootFromInclusionProof computes the root of a tree of treeSize leaves from the hash of the leaf
+// at index and its inclusion proof, following RFC 9162 section 2.1.3.2
+async function rootFromInclusionProof(index, treeSize, hash, proof) {
+  if (index < 0 || index >= treeSize) {
+    throw new Error(`leaf index ${index} is not in a tree of size ${treeSize}`);
+  }
+  let fn = index;
+  let sn = treeSize - 1;
+  let r = hash;
+  for (const p of proof) {
+    if (sn === 0) {
+      throw new Error('inclusion proof has too many hashes');
+    }
+    if (fn % 2 === 1 || fn === sn) {
+      r = await nodeHash(p, r);
+      while (fn % 2 === 0 && fn !== 0) {
+        fn = Math.floor(fn / 2);
+        sn = Math.floor(sn / 2);
+      }
+    } else {
+      r = await nodeHash(r, p);
+    }
+    fn = Math.floor(fn / 2);
+    sn = Math.floor(sn / 2);
+  }
+  if (sn !== 0) {
+    throw new Error('inclusion proof has too few hashes');
+  }
+  return r;
+}
+
+// derToP1363 converts an ASN.1 DER encoded ECDSA signature to the r || s form expected by WebCrypto
+function derToP1363(der, size) {
+  let pos = 0;
+  const read = (tag) => {
+    if (der[pos++] !== tag) {
+      throw new Error('malformed signature');
+    }
+    let len = der[pos++];
+    if (len & 0x80) {
+      const n = len & 0x7f;
+      len = 0;
+      for (let i = 0; i < n; i++) {
+        len = (len << 8) | der[pos++];
+      }
+    }
+    const value = der.subarray(pos, pos + len);
+    pos += len;
+    return value;
+  };
+  const seq = read(0x30);
+  der = seq;
+  pos = 0;
+  const out = new Uint8Array(2 * size);
+  [read(0x02), read(0x02)].forEach((n, i) => {
+    while (n.length > size && n[0] === 0) {
+      n = n.subarray(1);
+    }
+    if (n.length > size) {
+      throw new Error('malformed signature');
+    }
+    out.set(n, (i + 1) * size - n.length);
+  });
+  return out;
+}
+
+let logPublicKey;
+
+// publicKey imports the public key of the log, which WebCrypto only supports for ECDSA P-256
+async function publicKey() {
+  if (!logPublicKey) {
+    const pem = await request('log/publicKey', { headers: { 'Accept': 'application/x-pem-file' } });
+    const b64 = pem.replace(/-----(BEGIN|END) PUBLIC KEY-----/g, '').replace(/\s+/g, '');
+    logPublicKey = crypto.subtle.importKey('spki', fromBase64(b64), { name: 'ECDSA', namedCurve: 'P-256' }, false, ['verify']);
+  }
+  return logPublicKey;
+}
+
+// verifySET checks the signed entry timestamp, which signs the canonical JSON of the entry
+async function verifySET(entry, set) {
+  let key;
+  try {
+    key = await publicKey();
+  } catch (err) {
+    logPublicKey = undefined;
+    return null;
+  }
+  // keys are listed in canonical (sorted) order
+  const payload = JSON.stringify({
+    body: entry.body,
+    integratedTime: entry.integratedTime,
+    logID: entry.logID,
+    logIndex: entry.logIndex,
+  });
+  return crypto.subtle.verify({ name: 'ECDSA', hash: 'SHA-256' }, key,
+    derToP1363(fromBase64(set), 32), new TextEncoder().encode(payload));
+}
+
+const $ = (id) => document.getElementById(id);
+
+function setStatus(message, isError = false) {
+  $('status').textContent = message;
+  $('status').className = isError ? 'error' : '';
+}
+
+function clear() {
+  setStatus('');
+  $('uuids').hidden = true;
+  $('uuids').replaceChildren();
+  $('entry').hidden = true;
+  $('checks').replaceChildren();
+  $('proof-hashes').replaceChildren();
+}
+
+function addCheck(result, description) {
+  const li = document.createElement('li');
+  li.className = result === null ? 'skip' : result ? 'pass' : 'fail';
+  li.textContent = description;
+  $('checks').appendChild(li);
+}
+
+function decodeBody(body) {
+  const text = new TextDecoder().decode(fromBase64(body));
+  try {
+    return JSON.stringify(JSON.parse(text), null, 2);
+  } catch (err) {
+    return text;
+  }
+}
+
+async function showEntry(uuid, entry) {
+  $('entry-uuid').textContent = uuid;
+  $('entry-index').textContent = entry.logIndex;
+  $('entry-time').textContent = new Date(entry.integratedTime * 1000).toISOString();
+  $('entry-log-id').textContent = entry.logID;
+  $('entry-body').textContent = decodeBody(entry.body);
+
+  const verification = entry.verification || {};
+  const proof = verification.inclusionProof;
+  $('proof-tree-size').textContent = proof ? proof.treeSize : '';
+  $('proof-root-hash').textContent = proof ? proof.rootHash : '';
+  $('proof-index').textContent = proof ? proof.logIndex : '';
+  for (const h of (proof && proof.hashes) || []) {
+    const li = document.createElement('li');
+    li.textContent = h;
+    $('proof-hashes').appendChild(li);
+  }
+  $('entry').hidden = false;
+
+  const leaf = await leafHash(fromBase64(entry.body));
+  addCheck(toHex(leaf) === uuid.toLowerCase(), 'UUID is the Merkle leaf hash of the entry body');
+
+  if (!proof) {
+    addCheck(false, 'The log did not return an inclusion proof');
+  } else {
+    try {
+      const root = await rootFromInclusionProof(proof.logIndex, proof.treeSize, leaf, proof.hashes.map(fromHex));
+      addCheck(equal(root, fromHex(proof.rootHash)),
+        `Inclusion proof verifies against root hash ${proof.rootHash} of a tree of ${proof.treeSize} entries`);
+    } catch (err) {
+      addCheck(false, `Inclusion proof is invalid: ${err.message}`);
+    }
+  }
+
+  if (!verification.signedEntryTimestamp) {
+    addCheck(null, 'The log did not return a signed entry timestamp');
+  } else {
+    try {
+      const valid = await verifySET(entry, verification.signedEntryTimestamp);
+      if (valid === null) {
+        addCheck(null, 'Signed entry timestamp can not be verified in the browser for the key type of this log');
+      } else {
+        addCheck(valid, 'Signed entry timestamp is signed by the public key of the log');
+      }
+    } catch (err) {
+      addCheck(false, `Signed entry timestamp is invalid: ${err.message}`);
+    }
+  }
+}
+
+async function showEntries(entries) {
+  const [uuid] = Object.keys(entries);
+  if (!uuid) {
+    setStatus('No entry found');
+    return;
+  }
+  await showEntry(uuid, entries[uuid]);
+}
+
+function showUUIDs(uuids) {
+  setStatus(`${uuids.length} entries found`);
+  for (const uuid of uuids) {
+    const li = document.createElement('li');
+    const button = document.createElement('button');
+    button.type = 'button';
+    button.className = 'link hex';
+    button.textContent = uuid;
+    button.addEventListener('click', () => navigate(uuid));
+    li.appendChild(button);
+    $('uuids').appendChild(li);
+  }
+  $('uuids').hidden = false;
+}
+
+const hashLengths = { sha256: 64, sha1: 40 };
+
+async function search(query) {
+  clear();
+  const q = query.trim().toLowerCase();
+  if (q === '') {
+    return;
+  }
+  setStatus('Searching…');
+  try {
+    if (/^[0-9]+$/.test(q)) {
+      await showEntries(await request(`log/entries?logIndex=${q}`));
+      setStatus('');
+      return;
+    }
+
+    const m = /^(?:(sha256|sha1):)?([0-9a-f]+)$/.exec(q);
+    if (!m) {
+      throw new Error('Enter an entry UUID, a log index or the SHA-256 or SHA-1 hash of an artifact');
+    }
+    const [, algorithm, digest] = m;
+    // a bare SHA-256 digest may be an entry UUID or the hash of an artifact
+    if (!algorithm && digest.length === 64) {
+      try {
+        await showEntries(await request(`log/entries/${digest}`));
+        setStatus('');
+        return;
+      } catch (err) {
+        if (!(err instanceof APIError) || err.status !== 404) {
+          throw err;
+        }
+      }
+    }
+    const alg = algorithm || (digest.length === hashLengths.sha1 ? 'sha1' : 'sha256');
+    if (digest.length !== hashLengths[alg]) {
+      throw new Error(`A ${alg} hash has ${hashLengths[alg]} hexadecimal digits`);
+    }
+
+    const uuids = await request('index/retrieve', {
+      method: 'POST',
+      headers: { 'Content-Type': 'application/json' },
+      body: JSON.stringify({ hash: `${alg}:${digest}` }),
+    });
+    if (!uuids || uuids.length === 0) {
+      setStatus('No entries found');
+    } else if (uuids.length === 1) {
+      await showEntries(await request(`log/entries/${uuids[0]}`));
+      setStatus('');
+    } else {
+      showUUIDs(uuids);
+    }
+  } catch (err) {
+    setStatus(err.message, true);
+  }
+}
+
+// searches are kept in the fragment of the URL so that they can be linked to
+function navigate(query) {
+  location.hash = encodeURIComponent(query.trim());
+}
+
+function onHashChange() {
+  const query = decodeURIComponent(location.hash.slice(1));
+  $('query').value = query;
+  search(query);
+}
+
+$('search').addEventListener('submit', (e) => {
+  e.preventDefault();
+  const query = $('query').value;
+  if (decodeURIComponent(location.hash.slice(1)) === query.trim()) {
+    search(query);
+  } else {
+    navigate(query);
+  }
+});
+window.addEventListener('hashchange', onHashChange);
+if (location.hash.length > 1) {
+  onHashChange();
+}
@@ -0,0 +1,77 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestServeWebUI(t *testing.T) {
+	oldConfig := startupConfig
+	t.Cleanup(func() {
+		startupConfig = oldConfig
+	})
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+	request := func(handler http.Handler, r *http.Request) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, r)
+		return rec
+	}
+
+	startupConfig = &Config{}
+	if rec := request(ServeWebUI(next), httptest.NewRequest(http.MethodGet, "/ui/", nil)); rec.Code != http.StatusTeapot {
+		t.Errorf("expected web interface to be disabled by default, got %d", rec.Code)
+	}
+
+	startupConfig = &Config{EnableWebUI: true}
+	handler := ServeWebUI(next)
+
+	rec := request(handler, httptest.NewRequest(http.MethodGet, "/ui/", nil))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `<script src="app.js">`) {
+		t.Fatalf("unexpected response for index page: %d %s", rec.Code, rec.Body.String())
+	}
+	if rec.Header().Get("Content-Security-Policy") == "" {
+		t.Error("expected a content security policy")
+	}
+	for _, file := range []string{"app.js", "style.css"} {
+		if rec := request(handler, httptest.NewRequest(http.MethodGet, "/ui/"+file, nil)); rec.Code != http.StatusOK {
+			t.Errorf("unexpected status for %s: %d", file, rec.Code)
+		}
+	}
+	if rec := request(handler, httptest.NewRequest(http.MethodPost, "/ui/", nil)); rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected POST to be rejected, got %d", rec.Code)
+	}
+	if rec := request(handler, httptest.NewRequest(http.MethodGet, "/uix", nil)); rec.Code != http.StatusTeapot {
+		t.Errorf("expected other paths to be passed on, got %d", rec.Code)
+	}
+
+	rec = request(handler, httptest.NewRequest(http.MethodGet, "/ui", nil))
+	if rec.Code != http.StatusMovedPermanently || rec.Header().Get("Location") != "/ui/" {
+		t.Errorf("expected redirect to /ui/, got %d %q", rec.Code, rec.Header().Get("Location"))
+	}
+	r := httptest.NewRequest(http.MethodGet, "/ui", nil)
+	r = r.WithContext(context.WithValue(r.Context(), logContextKey{}, &logContext{prefix: "/logs/staging"}))
+	rec = request(handler, r)
+	if rec.Header().Get("Location") != "/logs/staging/ui/" {
+		t.Errorf("expected redirect within the log, got %q", rec.Header().Get("Location"))
+	}
+}
@@ -0,0 +1,322 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package audit keeps an append-only record of the writes to the log and of administrative actions.
+// Each record holds the hash of the one preceding it, so that records which are modified, removed
+// or reordered after being written are detected by Verify.
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/sigstore/rekor/pkg/util"
+)
+
+// Actions recorded in the audit log
+const (
+	ActionAddEntry = "add_entry"
+	// an entry of another log was replayed into the log by rekor-server import
+	ActionImportEntry = "import_entry"
+	ActionCreateTree  = "create_tree"
+	ActionRotateKey   = "rotate_key"
+	// a shard of the log was frozen by rekor-server shard freeze, or archived by rekor-server shard export
+	ActionFreezeShard = "freeze_shard"
+	ActionExportShard = "export_shard"
+)
+
+// Outcomes of recorded actions
+const (
+	OutcomeSuccess = "success"
+	// the entry was already in the log
+	OutcomeDuplicate = "duplicate"
+	// the request was refused, e.g. as it was unauthenticated, invalid or violated the admission policy
+	OutcomeRejected = "rejected"
+	OutcomeError    = "error"
+)
+
+// Record describes an action taken on the log
+type Record struct {
+	// position of the record in the chain, starting at 0
+	Seq     uint64    `json:"seq"`
+	Time    time.Time `json:"time"`
+	Action  string    `json:"action"`
+	Outcome string    `json:"outcome"`
+	// identity of the authenticated client
+	Identity string `json:"identity,omitempty"`
+	SourceIP string `json:"sourceIP,omitempty"`
+	// the X-Forwarded-For header of the request, as sent by proxies in front of the server
+	ForwardedFor string `json:"forwardedFor,omitempty"`
+	TreeID       int64  `json:"treeID,omitempty"`
+	EntryUUID    string `json:"entryUUID,omitempty"`
+	Detail       string `json:"detail,omitempty"`
+	// hex encoded hash of the previous record; empty for the first record of a chain
+	PrevHash string `json:"prevHash"`
+	// hex encoded SHA256 hash of the JSON encoding of the record without its hash
+	Hash string `json:"hash,omitempty"`
+}
+
+// hash computes the hash of r, ignoring r.Hash
+func (r Record) hash() (string, error) {
+	r.Hash = ""
+	b, err := json.Marshal(r)
+	if err != nil {
+		return "", err
+	}
+	h := sha256.Sum256(b)
+	return hex.EncodeToString(h[:]), nil
+}
+
+// Logger appends hash chained records, one JSON document per line, to a writer. A nil Logger
+// discards records, so that callers need not check whether auditing is enabled.
+type Logger struct {
+	mu   sync.Mutex
+	w    io.Writer
+	seq  uint64
+	prev string
+	// now returns the time records are stamped with
+	now func() time.Time
+	// the file opened by OpenFile, locked while each record is written, and its size after the last
+	// record this logger read or wrote; whatever else changes its size is read back before writing
+	file *os.File
+	size int64
+}
+
+// New returns a logger starting a new chain of records on w. Each record is written with a single
+// call to w.Write.
+func New(w io.Writer) *Logger {
+	return &Logger{w: w, now: time.Now}
+}
+
+// syncWriter is implemented by writers which can flush written data to stable storage
+type syncWriter interface {
+	Sync() error
+}
+
+// OpenFile returns a logger appending to the file at path, which is created if it does not exist.
+// The chain of records already in the file is verified and continued. Other processes may append to
+// the same file, such as the administrative commands of rekor-server while the server is running: the
+// file is locked while each record is written, which continues the chain from the last record in the
+// file even if another process wrote it.
+func OpenFile(path string) (*Logger, error) {
+	f, err := os.OpenFile(filepath.Clean(path), os.O_RDWR|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, err
+	}
+	l, err := openFile(f)
+	if err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("audit log %s: %w", path, err)
+	}
+	return l, nil
+}
+
+func openFile(f *os.File) (*Logger, error) {
+	if err := util.LockFile(f); err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = util.UnlockFile(f)
+	}()
+	last, _, err := verify(f)
+	if err != nil {
+		return nil, err
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	l := New(f)
+	l.file, l.size = f, fi.Size()
+	if last != nil {
+		l.seq, l.prev = last.Seq+1, last.Hash
+	}
+	return l, nil
+}
+
+// resume continues the chain from the last record of the file if it changed since this logger last
+// read or wrote it; the file must be locked
+func (l *Logger) resume() error {
+	fi, err := l.file.Stat()
+	if err != nil {
+		return err
+	}
+	if fi.Size() == l.size {
+		return nil
+	}
+	last, err := lastRecord(l.file, fi.Size())
+	if err != nil {
+		return err
+	}
+	// a file which was emptied, e.g. by rotating it, starts a new chain
+	l.seq, l.prev = 0, ""
+	if last != nil {
+		l.seq, l.prev = last.Seq+1, last.Hash
+	}
+	l.size = fi.Size()
+	return nil
+}
+
+// lastRecord parses the last record within the first size bytes of f, if there is one
+func lastRecord(f *os.File, size int64) (*Record, error) {
+	const chunkSize = 4096
+	var tail []byte
+	for offset := size; offset > 0; {
+		n := int64(chunkSize)
+		if n > offset {
+			n = offset
+		}
+		offset -= n
+		chunk := make([]byte, n)
+		if _, err := f.ReadAt(chunk, offset); err != nil {
+			return nil, err
+		}
+		tail = append(chunk, tail...)
+		trimmed := bytes.TrimRight(tail, " \t\r\n")
+		i := bytes.LastIndexByte(trimmed, '\n')
+		if i < 0 && offset > 0 {
+			// the record starts in an earlier chunk
+			continue
+		}
+		if len(trimmed[i+1:]) == 0 {
+			return nil, nil
+		}
+		rec := &Record{}
+		if err := json.Unmarshal(trimmed[i+1:], rec); err != nil {
+			return nil, fmt.Errorf("last record: %w", err)
+		}
+		return rec, nil
+	}
+	return nil, nil
+}
+
+// Log stamps r with the current time and its position in the chain and writes it
+func (l *Logger) Log(r Record) error {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.file != nil {
+		if err := util.LockFile(l.file); err != nil {
+			return fmt.Errorf("locking audit log: %w", err)
+		}
+		defer func() {
+			_ = util.UnlockFile(l.file)
+		}()
+		if err := l.resume(); err != nil {
+			return fmt.Errorf("reading audit log: %w", err)
+		}
+	}
+
+	r.Seq, r.PrevHash = l.seq, l.prev
+	r.Time = l.now().UTC()
+	hash, err := r.hash()
+	if err != nil {
+		return err
+	}
+	r.Hash = hash
+	b, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	if _, err := l.w.Write(append(b, '\n')); err != nil {
+		return fmt.Errorf("writing audit record: %w", err)
+	}
+	if s, ok := l.w.(syncWriter); ok {
+		if err := s.Sync(); err != nil {
+			return fmt.Errorf("writing audit record: %w", err)
+		}
+	}
+	l.seq, l.prev = r.Seq+1, hash
+	if l.file != nil {
+		fi, err := l.file.Stat()
+		if err != nil {
+			return fmt.Errorf("writing audit record: %w", err)
+		}
+		l.size = fi.Size()
+	}
+	return nil
+}
+
+// Close closes the underlying writer if it is an io.Closer
+func (l *Logger) Close() error {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if c, ok := l.w.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// Verify checks that the records read from r, one per line, form an unbroken chain and returns
+// their number. A record with sequence number 0 starts a new chain, as written after the audit log
+// was rotated, or by a server logging to syslog after it restarted.
+func Verify(r io.Reader) (int, error) {
+	_, n, err := verify(r)
+	return n, err
+}
+
+func verify(r io.Reader) (*Record, int, error) {
+	var last *Record
+	n := 0
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1<<20)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		n++
+		rec := Record{}
+		dec := json.NewDecoder(bytes.NewReader(line))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&rec); err != nil {
+			return nil, n, fmt.Errorf("record %d: %w", n, err)
+		}
+		hash, err := rec.hash()
+		if err != nil {
+			return nil, n, fmt.Errorf("record %d: %w", n, err)
+		}
+		if hash != rec.Hash {
+			return nil, n, fmt.Errorf("record %d (seq %d): hash does not match its contents", n, rec.Seq)
+		}
+		if rec.Seq == 0 {
+			if rec.PrevHash != "" {
+				return nil, n, fmt.Errorf("record %d (seq 0): first record of a chain has a previous hash", n)
+			}
+		} else if last == nil || rec.Seq != last.Seq+1 || rec.PrevHash != last.Hash {
+			return nil, n, fmt.Errorf("record %d (seq %d): does not follow the preceding record", n, rec.Seq)
+		}
+		last = &rec
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, n, err
+	}
+	return last, n, nil
+}
@@ -0,0 +1,149 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLogAndVerify(t *testing.T) {
+	buf := &bytes.Buffer{}
+	l := New(buf)
+	l.now = func() time.Time { return time.Date(2021, 12, 1, 10, 0, 0, 0, time.FixedZone("CET", 3600)) }
+	records := []Record{
+		{Action: ActionCreateTree, Outcome: OutcomeSuccess, TreeID: 42},
+		{Action: ActionAddEntry, Outcome: OutcomeSuccess, Identity: "ci@example.com", SourceIP: "192.0.2.1", EntryUUID: "abc"},
+		{Action: ActionAddEntry, Outcome: OutcomeRejected, SourceIP: "192.0.2.2", Detail: "Unauthorized"},
+	}
+	for _, r := range records {
+		if err := l.Log(r); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n, err := Verify(bytes.NewReader(buf.Bytes())); err != nil || n != 3 {
+		t.Fatalf("Verify() = %d, %v; want 3 records", n, err)
+	}
+
+	lines := strings.SplitAfter(buf.String(), "\n")
+	tampered := map[string]string{
+		"modified":    lines[0] + strings.Replace(lines[1], "ci@example.com", "other@example.com", 1) + lines[2],
+		"removed":     lines[0] + lines[2],
+		"reordered":   lines[0] + lines[2] + lines[1],
+		"added field": lines[0] + lines[1] + strings.Replace(lines[2], `"seq"`, `"extra":1,"seq"`, 1),
+		"truncated":   lines[1] + lines[2],
+	}
+	for name, contents := range tampered {
+		if _, err := Verify(strings.NewReader(contents)); err == nil {
+			t.Errorf("%s: expected Verify() to fail", name)
+		}
+	}
+
+	// a new chain may follow, e.g. in syslog after a restart
+	next := &bytes.Buffer{}
+	if err := New(next).Log(records[0]); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := Verify(strings.NewReader(buf.String() + next.String())); err != nil || n != 4 {
+		t.Errorf("Verify() = %d, %v; want 4 records", n, err)
+	}
+
+	var nilLogger *Logger
+	if err := nilLogger.Log(records[0]); err != nil {
+		t.Errorf("nil logger returned %v", err)
+	}
+}
+
+func TestOpenFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	for i := 0; i < 2; i++ {
+		l, err := OpenFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := l.Log(Record{Action: ActionAddEntry, Outcome: OutcomeSuccess}); err != nil {
+			t.Fatal(err)
+		}
+		if err := l.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the chain is continued rather than restarted
+	if n, err := Verify(bytes.NewReader(contents)); err != nil || n != 2 || strings.Count(string(contents), `"seq":0,`) != 1 {
+		t.Fatalf("Verify() = %d, %v: %s", n, err, contents)
+	}
+
+	if err := ioutil.WriteFile(path, append(contents, []byte("{\"seq\":7}\n")...), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := OpenFile(path); err == nil {
+		t.Error("expected OpenFile() to refuse a tampered audit log")
+	}
+	if _, err := OpenFile(filepath.Join(path, "missing", "audit.log")); err == nil {
+		t.Error("expected an error opening a file in a missing directory")
+	}
+}
+
+func TestOpenFileShared(t *testing.T) {
+	// such as rekor-server and rekor-server import, each of which opened the file before the other wrote
+	path := filepath.Join(t.TempDir(), "audit.log")
+	server, err := OpenFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+	admin, err := OpenFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer admin.Close()
+	for i, l := range []*Logger{server, admin, admin, server, admin} {
+		// records longer than the chunks the last record is read back in
+		if err := l.Log(Record{Action: ActionAddEntry, Outcome: OutcomeSuccess, Detail: strings.Repeat("detail ", 1000*i)}); err != nil {
+			t.Fatalf("record %d: %v", i, err)
+		}
+	}
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, err := Verify(bytes.NewReader(contents)); err != nil || n != 5 || strings.Count(string(contents), `"seq":0,`) != 1 {
+		t.Fatalf("Verify() = %d, %v: %s", n, err, contents)
+	}
+
+	// a file emptied as it was rotated starts a new chain
+	if err := os.Truncate(path, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := server.Log(Record{Action: ActionAddEntry, Outcome: OutcomeSuccess}); err != nil {
+		t.Fatal(err)
+	}
+	if contents, err = ioutil.ReadFile(path); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := Verify(bytes.NewReader(contents)); err != nil || n != 1 || !strings.Contains(string(contents), `"seq":0,`) {
+		t.Fatalf("Verify() = %d, %v: %s", n, err, contents)
+	}
+}
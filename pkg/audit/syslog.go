@@ -0,0 +1,34 @@
+//go:build !windows && !plan9
+// +build !windows,!plan9
+
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"log/syslog"
+)
+
+// DialSyslog returns a logger sending records to the syslog daemon at addr over network ("udp"
+// or "tcp"), or to the local daemon if network is empty. As earlier records can not be read back,
+// every logger starts a new chain.
+func DialSyslog(network, addr string) (*Logger, error) {
+	w, err := syslog.Dial(network, addr, syslog.LOG_INFO|syslog.LOG_AUTH, "rekor-server")
+	if err != nil {
+		return nil, err
+	}
+	return New(w), nil
+}
@@ -0,0 +1,76 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+var (
+	// ErrNoCredentials is returned when a request does not carry a token
+	ErrNoCredentials = errors.New("no credentials presented")
+	// ErrInvalidCredentials is returned when a token is not accepted by any authenticator
+	ErrInvalidCredentials = errors.New("invalid credentials")
+)
+
+// Authenticator maps a token presented by a client to the identity it belongs to. ErrInvalidCredentials
+// is returned if the token is not one this authenticator is responsible for.
+type Authenticator interface {
+	Authenticate(ctx context.Context, token string) (string, error)
+}
+
+// Chain tries each authenticator in turn, returning the identity from the first to accept the token
+type Chain []Authenticator
+
+// Authenticate implements Authenticator
+func (c Chain) Authenticate(ctx context.Context, token string) (string, error) {
+	for _, a := range c {
+		identity, err := a.Authenticate(ctx, token)
+		if err == nil {
+			return identity, nil
+		}
+		if !errors.Is(err, ErrInvalidCredentials) {
+			return "", err
+		}
+	}
+	return "", ErrInvalidCredentials
+}
+
+// TokenFromRequest returns the bearer token from the Authorization header of the request or, as sent
+// by rekor-cli --api-key, from the apiKey query parameter
+func TokenFromRequest(r *http.Request) (string, error) {
+	if h := r.Header.Get("Authorization"); h != "" {
+		scheme, token, found := cut(h, " ")
+		if !found || !strings.EqualFold(scheme, "Bearer") || strings.TrimSpace(token) == "" {
+			return "", ErrInvalidCredentials
+		}
+		return strings.TrimSpace(token), nil
+	}
+	if token := r.URL.Query().Get("apiKey"); token != "" {
+		return token, nil
+	}
+	return "", ErrNoCredentials
+}
+
+func cut(s, sep string) (before, after string, found bool) {
+	if i := strings.Index(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):], true
+	}
+	return s, "", false
+}
@@ -0,0 +1,119 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+)
+
+func TestTokenFromRequest(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		target  string
+		header  string
+		want    string
+		wantErr error
+	}{
+		{name: "none", target: "/api/v1/log/entries", wantErr: ErrNoCredentials},
+		{name: "bearer", target: "/api/v1/log/entries", header: "Bearer abc", want: "abc"},
+		{name: "bearer case insensitive", target: "/api/v1/log/entries", header: "bearer  abc ", want: "abc"},
+		{name: "basic", target: "/api/v1/log/entries", header: "Basic dXNlcjpwYXNz", wantErr: ErrInvalidCredentials},
+		{name: "empty bearer", target: "/api/v1/log/entries", header: "Bearer ", wantErr: ErrInvalidCredentials},
+		{name: "api key", target: "/api/v1/log/entries?apiKey=xyz", want: "xyz"},
+		{name: "header takes precedence", target: "/api/v1/log/entries?apiKey=xyz", header: "Bearer abc", want: "abc"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("POST", tt.target, nil)
+			if tt.header != "" {
+				r.Header.Set("Authorization", tt.header)
+			}
+			got, err := TokenFromRequest(r)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("TokenFromRequest() error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("TokenFromRequest() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStaticTokens(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "tokens")
+	if err := ioutil.WriteFile(path, []byte("# CI pipelines\nci s3cr3t\n\nrelease-bot  0th3r\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	st, err := LoadStaticTokens(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	if id, err := st.Authenticate(ctx, "s3cr3t"); err != nil || id != "ci" {
+		t.Errorf("Authenticate() = %q, %v", id, err)
+	}
+	if id, err := st.Authenticate(ctx, "0th3r"); err != nil || id != "release-bot" {
+		t.Errorf("Authenticate() = %q, %v", id, err)
+	}
+	if _, err := st.Authenticate(ctx, "guess"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("Authenticate() of unknown token error = %v", err)
+	}
+
+	for name, content := range map[string]string{
+		"missing token":   "ci\n",
+		"duplicate token": "ci s3cr3t\nother s3cr3t\n",
+	} {
+		p := filepath.Join(dir, "bad")
+		if err := ioutil.WriteFile(p, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadStaticTokens(p); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+type fixedAuthenticator struct {
+	identity string
+	err      error
+}
+
+func (f fixedAuthenticator) Authenticate(context.Context, string) (string, error) {
+	return f.identity, f.err
+}
+
+func TestChain(t *testing.T) {
+	ctx := context.Background()
+	unavailable := errors.New("issuer unavailable")
+
+	c := Chain{fixedAuthenticator{err: ErrInvalidCredentials}, fixedAuthenticator{identity: "second"}}
+	if id, err := c.Authenticate(ctx, "t"); err != nil || id != "second" {
+		t.Errorf("Authenticate() = %q, %v", id, err)
+	}
+	c = Chain{fixedAuthenticator{err: ErrInvalidCredentials}}
+	if _, err := c.Authenticate(ctx, "t"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("Authenticate() error = %v", err)
+	}
+	c = Chain{fixedAuthenticator{err: unavailable}, fixedAuthenticator{identity: "second"}}
+	if _, err := c.Authenticate(ctx, "t"); !errors.Is(err, unavailable) {
+		t.Errorf("Authenticate() error = %v, want %v", err, unavailable)
+	}
+}
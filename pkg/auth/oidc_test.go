@@ -0,0 +1,191 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testIssuer struct {
+	server *httptest.Server
+	ecKey  *ecdsa.PrivateKey
+	rsaKey *rsa.PrivateKey
+}
+
+func b64(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func newTestIssuer(t *testing.T) *testIssuer {
+	t.Helper()
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ti := &testIssuer{ecKey: ecKey, rsaKey: rsaKey}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"issuer":   ti.server.URL,
+			"jwks_uri": ti.server.URL + "/keys",
+		})
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{
+				{
+					"kty": "EC", "kid": "ec", "use": "sig", "crv": "P-256",
+					"x": b64(ecKey.X.FillBytes(make([]byte, 32))),
+					"y": b64(ecKey.Y.FillBytes(make([]byte, 32))),
+				},
+				{
+					"kty": "RSA", "kid": "rsa",
+					"n": b64(rsaKey.N.Bytes()),
+					"e": b64(big.NewInt(int64(rsaKey.E)).Bytes()),
+				},
+				{"kty": "oct", "kid": "hmac", "k": "c2VjcmV0"},
+			},
+		})
+	})
+	ti.server = httptest.NewServer(mux)
+	t.Cleanup(ti.server.Close)
+	return ti
+}
+
+func (ti *testIssuer) sign(t *testing.T, alg, kid string, claims map[string]interface{}) string {
+	t.Helper()
+	header, _ := json.Marshal(map[string]string{"alg": alg, "kid": kid, "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+	signed := b64(header) + "." + b64(payload)
+	digest := sha256.Sum256([]byte(signed))
+
+	var sig []byte
+	switch alg {
+	case "ES256":
+		r, s, err := ecdsa.Sign(rand.Reader, ti.ecKey, digest[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		sig = append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	case "RS256":
+		var err error
+		if sig, err = rsa.SignPKCS1v15(rand.Reader, ti.rsaKey, crypto.SHA256, digest[:]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return signed + "." + b64(sig)
+}
+
+func TestOIDCVerifier(t *testing.T) {
+	ti := newTestIssuer(t)
+	ctx := context.Background()
+	v, err := NewOIDCVerifier(ctx, ti.server.Client(), ti.server.URL, "rekor")
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+
+	claims := func(overrides map[string]interface{}) map[string]interface{} {
+		c := map[string]interface{}{
+			"iss": ti.server.URL,
+			"aud": "rekor",
+			"sub": "1234",
+			"exp": now.Add(time.Hour).Unix(),
+		}
+		for k, v := range overrides {
+			if v == nil {
+				delete(c, k)
+			} else {
+				c[k] = v
+			}
+		}
+		return c
+	}
+
+	for _, tt := range []struct {
+		name    string
+		token   string
+		want    string
+		wantErr bool
+	}{
+		{name: "es256", token: ti.sign(t, "ES256", "ec", claims(nil)), want: "1234"},
+		{name: "rs256", token: ti.sign(t, "RS256", "rsa", claims(nil)), want: "1234"},
+		{name: "audience list", token: ti.sign(t, "ES256", "ec", claims(map[string]interface{}{"aud": []string{"other", "rekor"}})), want: "1234"},
+		{name: "verified email", token: ti.sign(t, "ES256", "ec", claims(map[string]interface{}{"email": "jdoe@example.com", "email_verified": true})), want: "jdoe@example.com"},
+		{name: "unverified email", token: ti.sign(t, "ES256", "ec", claims(map[string]interface{}{"email": "jdoe@example.com", "email_verified": false})), want: "1234"},
+		{name: "expired", token: ti.sign(t, "ES256", "ec", claims(map[string]interface{}{"exp": now.Add(-time.Hour).Unix()})), wantErr: true},
+		{name: "no expiry", token: ti.sign(t, "ES256", "ec", claims(map[string]interface{}{"exp": nil})), wantErr: true},
+		{name: "not yet valid", token: ti.sign(t, "ES256", "ec", claims(map[string]interface{}{"nbf": now.Add(time.Hour).Unix()})), wantErr: true},
+		{name: "wrong audience", token: ti.sign(t, "ES256", "ec", claims(map[string]interface{}{"aud": "other"})), wantErr: true},
+		{name: "wrong issuer", token: ti.sign(t, "ES256", "ec", claims(map[string]interface{}{"iss": "https://evil.example.com"})), wantErr: true},
+		{name: "algorithm mismatch", token: ti.sign(t, "RS256", "ec", claims(nil)), wantErr: true},
+		{name: "unknown key", token: ti.sign(t, "ES256", "missing", claims(nil)), wantErr: true},
+		{name: "not a jwt", token: "s3cr3t", wantErr: true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := v.Authenticate(ctx, tt.token)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidCredentials) {
+					t.Fatalf("Authenticate() error = %v, want ErrInvalidCredentials", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("Authenticate() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	// tampering with the claims invalidates the signature
+	parts := strings.Split(ti.sign(t, "ES256", "ec", claims(nil)), ".")
+	forged, _ := json.Marshal(claims(map[string]interface{}{"sub": "admin"}))
+	if _, err := v.Authenticate(ctx, parts[0]+"."+b64(forged)+"."+parts[2]); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("Authenticate() of forged token error = %v", err)
+	}
+}
+
+func TestNewOIDCVerifierIssuerMismatch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{"issuer": "https://other.example.com", "jwks_uri": "https://other.example.com/keys"})
+	}))
+	defer server.Close()
+	if _, err := NewOIDCVerifier(context.Background(), server.Client(), server.URL, "rekor"); err == nil {
+		t.Error("expected error when discovery document is for a different issuer")
+	}
+}
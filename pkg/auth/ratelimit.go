@@ -0,0 +1,111 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// once this many identities are tracked, buckets which have refilled completely are discarded
+const maxIdleBuckets = 10000
+
+// Limiter enforces a per-identity token bucket rate limit on writes
+type Limiter struct {
+	// PerMinute is the sustained number of writes allowed per minute; zero or less disables the default limit
+	PerMinute float64
+	// Burst is the number of writes an identity may make at once
+	Burst int
+	// Overrides holds per-identity limits (writes per minute) replacing PerMinute; zero or less means unlimited
+	Overrides map[string]float64
+
+	now func() time.Time
+
+	mu      sync.Mutex
+	buckets map[string]*bucket
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// Allow consumes a token for the identity, returning false and the time until the next token is
+// available if the identity has exhausted its limit
+func (l *Limiter) Allow(identity string) (bool, time.Duration) {
+	rate := l.PerMinute
+	if r, ok := l.Overrides[identity]; ok {
+		rate = r
+	}
+	if rate <= 0 {
+		return true, 0
+	}
+	perSecond := rate / 60
+	burst := float64(l.Burst)
+	if burst < 1 {
+		burst = 1
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := time.Now()
+	if l.now != nil {
+		now = l.now()
+	}
+	if l.buckets == nil {
+		l.buckets = map[string]*bucket{}
+	}
+	if len(l.buckets) >= maxIdleBuckets {
+		l.prune(now, perSecond, burst)
+	}
+
+	b, ok := l.buckets[identity]
+	if !ok {
+		b = &bucket{tokens: burst, last: now}
+		l.buckets[identity] = b
+	}
+	b.tokens = math.Min(burst, b.tokens+now.Sub(b.last).Seconds()*perSecond)
+	b.last = now
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+	wait := time.Duration((1 - b.tokens) / perSecond * float64(time.Second))
+	return false, wait
+}
+
+// SameLimits reports whether the limiter enforces the same limits as o, so that the budgets used up by
+// the identities it tracks can be kept when o would replace it
+func (l *Limiter) SameLimits(o *Limiter) bool {
+	if l.PerMinute != o.PerMinute || l.Burst != o.Burst || len(l.Overrides) != len(o.Overrides) {
+		return false
+	}
+	for identity, rate := range l.Overrides {
+		if r, ok := o.Overrides[identity]; !ok || r != rate {
+			return false
+		}
+	}
+	return true
+}
+
+func (l *Limiter) prune(now time.Time, perSecond, burst float64) {
+	for id, b := range l.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*perSecond >= burst {
+			delete(l.buckets, id)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLimiter(t *testing.T) {
+	now := time.Unix(1600000000, 0)
+	l := &Limiter{
+		PerMinute: 60,
+		Burst:     2,
+		Overrides: map[string]float64{"unlimited": 0, "slow": 1},
+		now:       func() time.Time { return now },
+	}
+
+	for i := 0; i < 2; i++ {
+		if ok, _ := l.Allow("ci"); !ok {
+			t.Fatalf("write %d within burst was rejected", i)
+		}
+	}
+	ok, wait := l.Allow("ci")
+	if ok {
+		t.Fatal("write beyond burst was allowed")
+	}
+	if wait != time.Second {
+		t.Errorf("wait = %v, want 1s", wait)
+	}
+	// identities are limited independently
+	if ok, _ := l.Allow("other"); !ok {
+		t.Error("write by other identity was rejected")
+	}
+
+	now = now.Add(time.Second)
+	if ok, _ := l.Allow("ci"); !ok {
+		t.Error("write after refill was rejected")
+	}
+
+	for i := 0; i < 100; i++ {
+		if ok, _ := l.Allow("unlimited"); !ok {
+			t.Fatal("write by unlimited identity was rejected")
+		}
+	}
+
+	l.Allow("slow")
+	l.Allow("slow")
+	if ok, wait := l.Allow("slow"); ok || wait != time.Minute {
+		t.Errorf("Allow(slow) = %v, %v; want false, 1m", ok, wait)
+	}
+}
+
+func TestLimiterDisabled(t *testing.T) {
+	l := &Limiter{}
+	for i := 0; i < 100; i++ {
+		if ok, _ := l.Allow("ci"); !ok {
+			t.Fatal("write rejected without a limit")
+		}
+	}
+}
+
+func TestLimiterSameLimits(t *testing.T) {
+	l := &Limiter{PerMinute: 60, Burst: 2, Overrides: map[string]float64{"ci": 120}}
+	for _, tt := range []struct {
+		name string
+		o    *Limiter
+		want bool
+	}{
+		{name: "same", o: &Limiter{PerMinute: 60, Burst: 2, Overrides: map[string]float64{"ci": 120}}, want: true},
+		{name: "rate", o: &Limiter{PerMinute: 30, Burst: 2, Overrides: map[string]float64{"ci": 120}}},
+		{name: "burst", o: &Limiter{PerMinute: 60, Burst: 3, Overrides: map[string]float64{"ci": 120}}},
+		{name: "override changed", o: &Limiter{PerMinute: 60, Burst: 2, Overrides: map[string]float64{"ci": 60}}},
+		{name: "override removed", o: &Limiter{PerMinute: 60, Burst: 2}},
+		{name: "override replaced", o: &Limiter{PerMinute: 60, Burst: 2, Overrides: map[string]float64{"bot": 120}}},
+	} {
+		if got := l.SameLimits(tt.o); got != tt.want {
+			t.Errorf("%s: SameLimits = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
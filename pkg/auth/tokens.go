@@ -0,0 +1,73 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// StaticTokens authenticates clients presenting one of a fixed set of API tokens
+type StaticTokens struct {
+	// identities keyed by the SHA256 digest of their token
+	identities map[[sha256.Size]byte]string
+}
+
+// LoadStaticTokens reads API tokens from a file holding one "<identity> <token>" pair per line; empty
+// lines and lines starting with # are ignored
+func LoadStaticTokens(path string) (*StaticTokens, error) {
+	f, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	st := &StaticTokens{identities: map[[sha256.Size]byte]string{}}
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("%s:%d: expected \"<identity> <token>\"", path, n)
+		}
+		digest := sha256.Sum256([]byte(fields[1]))
+		if other, ok := st.identities[digest]; ok {
+			return nil, fmt.Errorf("%s:%d: token of %s is already assigned to %s", path, n, fields[0], other)
+		}
+		st.identities[digest] = fields[0]
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return st, nil
+}
+
+// Authenticate implements Authenticator
+func (st *StaticTokens) Authenticate(_ context.Context, token string) (string, error) {
+	// tokens are looked up by digest so that the comparison does not leak the stored tokens through timing
+	if identity, ok := st.identities[sha256.Sum256([]byte(token))]; ok {
+		return identity, nil
+	}
+	return "", ErrInvalidCredentials
+}
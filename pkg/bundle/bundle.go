@@ -0,0 +1,350 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bundle
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/go-openapi/runtime"
+	"github.com/go-openapi/strfmt"
+	"github.com/go-openapi/swag"
+	"github.com/pkg/errors"
+
+	"github.com/sigstore/rekor/pkg/generated/models"
+	"github.com/sigstore/rekor/pkg/pki"
+	"github.com/sigstore/rekor/pkg/types"
+)
+
+const (
+	// MediaType identifies the version of the bundle format which is produced
+	MediaType = "application/vnd.dev.sigstore.bundle+json;version=0.1"
+
+	mediaTypePrefix = "application/vnd.dev.sigstore.bundle"
+
+	// HashAlgorithmSHA256 is the name used by the bundle format for SHA-256 message digests
+	HashAlgorithmSHA256 = "SHA2_256"
+)
+
+// Bundle is the JSON encoding of a Sigstore bundle, which carries the transparency log entry, its
+// signed entry timestamp, inclusion proof and the material needed to verify the signature in a
+// single document. Exactly one of MessageSignature and DSSEEnvelope
+// describes the signed content; entry types which carry neither still produce a bundle containing
+// the log entry and verification material.
+type Bundle struct {
+	MediaType            string                `json:"mediaType"`
+	VerificationMaterial *VerificationMaterial `json:"verificationMaterial"`
+	MessageSignature     *MessageSignature     `json:"messageSignature,omitempty"`
+	DSSEEnvelope         json.RawMessage       `json:"dsseEnvelope,omitempty"`
+}
+
+type VerificationMaterial struct {
+	PublicKey            *PublicKeyIdentifier   `json:"publicKey,omitempty"`
+	X509CertificateChain *X509CertificateChain  `json:"x509CertificateChain,omitempty"`
+	TlogEntries          []TransparencyLogEntry `json:"tlogEntries"`
+}
+
+// PublicKeyIdentifier refers to a public key which is distributed out of band
+type PublicKeyIdentifier struct {
+	Hint string `json:"hint"`
+}
+
+type X509CertificateChain struct {
+	Certificates []X509Certificate `json:"certificates"`
+}
+
+type X509Certificate struct {
+	RawBytes []byte `json:"rawBytes"`
+}
+
+type TransparencyLogEntry struct {
+	LogIndex          int64             `json:"logIndex,string"`
+	LogID             LogID             `json:"logId"`
+	KindVersion       KindVersion       `json:"kindVersion"`
+	IntegratedTime    int64             `json:"integratedTime,string"`
+	InclusionPromise  *InclusionPromise `json:"inclusionPromise,omitempty"`
+	InclusionProof    *InclusionProof   `json:"inclusionProof,omitempty"`
+	CanonicalizedBody []byte            `json:"canonicalizedBody"`
+}
+
+type LogID struct {
+	KeyID []byte `json:"keyId"`
+}
+
+type KindVersion struct {
+	Kind    string `json:"kind"`
+	Version string `json:"version"`
+}
+
+type InclusionPromise struct {
+	SignedEntryTimestamp []byte `json:"signedEntryTimestamp"`
+}
+
+type InclusionProof struct {
+	LogIndex int64    `json:"logIndex,string"`
+	RootHash []byte   `json:"rootHash"`
+	TreeSize int64    `json:"treeSize,string"`
+	Hashes   [][]byte `json:"hashes"`
+}
+
+type MessageSignature struct {
+	MessageDigest *HashOutput `json:"messageDigest,omitempty"`
+	Signature     []byte      `json:"signature"`
+}
+
+type HashOutput struct {
+	Algorithm string `json:"algorithm"`
+	Digest    []byte `json:"digest"`
+}
+
+// Parse decodes a bundle and checks that it is a version of the format which is understood
+func Parse(raw []byte) (*Bundle, error) {
+	b := &Bundle{}
+	if err := json.Unmarshal(raw, b); err != nil {
+		return nil, errors.Wrap(err, "parsing bundle")
+	}
+	if !strings.HasPrefix(b.MediaType, mediaTypePrefix) {
+		return nil, fmt.Errorf("unsupported bundle media type %q", b.MediaType)
+	}
+	if b.VerificationMaterial == nil || len(b.VerificationMaterial.TlogEntries) == 0 {
+		return nil, errors.New("bundle does not contain a transparency log entry")
+	}
+	if b.MessageSignature != nil && len(b.DSSEEnvelope) != 0 {
+		return nil, errors.New("bundle must not contain both a message signature and a DSSE envelope")
+	}
+	return b, nil
+}
+
+// FromLogEntry builds a bundle for an entry returned by the log. The verification material and
+// message signature are taken from the canonicalized entry body, so the bundle only ever contains
+// what the log has attested to.
+func FromLogEntry(logEntry models.LogEntryAnon) (*Bundle, error) {
+	tle, err := tlogEntry(logEntry)
+	if err != nil {
+		return nil, err
+	}
+
+	pe, err := models.UnmarshalProposedEntry(bytes.NewReader(tle.CanonicalizedBody), runtime.JSONConsumer())
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing entry body")
+	}
+	entry, err := types.NewEntry(pe)
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing entry body")
+	}
+	tle.KindVersion = KindVersion{Kind: pe.Kind(), Version: entry.APIVersion()}
+
+	b := &Bundle{
+		MediaType:            MediaType,
+		VerificationMaterial: &VerificationMaterial{TlogEntries: []TransparencyLogEntry{*tle}},
+	}
+	if sp, ok := entry.(types.SignerProvider); ok {
+		signers, err := sp.Signers()
+		if err != nil {
+			return nil, err
+		}
+		if len(signers) > 0 {
+			if err := b.setVerificationMaterial(signers[0]); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if hr, ok := pe.(*models.Hashedrekord); ok {
+		if b.MessageSignature, err = messageSignature(hr); err != nil {
+			return nil, err
+		}
+	}
+	return b, nil
+}
+
+// Merge adds the log entries of another bundle to b, such as those of the same entry in a different log
+func (b *Bundle) Merge(other *Bundle) error {
+	if len(b.VerificationMaterial.TlogEntries) == 0 || len(other.VerificationMaterial.TlogEntries) == 0 {
+		return errors.New("bundle does not contain a transparency log entry")
+	}
+	body := b.VerificationMaterial.TlogEntries[0].CanonicalizedBody
+	for _, tle := range other.VerificationMaterial.TlogEntries {
+		if !bytes.Equal(tle.CanonicalizedBody, body) {
+			return errors.New("bundles contain different entries")
+		}
+		b.VerificationMaterial.TlogEntries = append(b.VerificationMaterial.TlogEntries, tle)
+	}
+	return nil
+}
+
+// setVerificationMaterial records the certificate chain of the signer, or a hint naming its key
+func (b *Bundle) setVerificationMaterial(signer pki.PublicKey) error {
+	if cp, ok := signer.(pki.CertificateProvider); ok && len(cp.Certificates()) > 0 {
+		chain := &X509CertificateChain{}
+		for _, c := range cp.Certificates() {
+			chain.Certificates = append(chain.Certificates, X509Certificate{RawBytes: c.Raw})
+		}
+		b.VerificationMaterial.X509CertificateChain = chain
+		return nil
+	}
+	keyBytes, err := signer.CanonicalValue()
+	if err != nil {
+		return err
+	}
+	b.VerificationMaterial.PublicKey = &PublicKeyIdentifier{Hint: keyHint(keyBytes)}
+	return nil
+}
+
+func messageSignature(hr *models.Hashedrekord) (*MessageSignature, error) {
+	spec := models.HashedrekordV001Schema{}
+	if err := types.DecodeEntry(hr.Spec, &spec); err != nil {
+		return nil, err
+	}
+	if spec.Signature == nil || spec.Data == nil || spec.Data.Hash == nil {
+		return nil, errors.New("hashedrekord entry is missing its signature or hash")
+	}
+	if swag.StringValue(spec.Data.Hash.Algorithm) != models.HashedrekordV001SchemaDataHashAlgorithmSha256 {
+		return nil, fmt.Errorf("unsupported hash algorithm %q", swag.StringValue(spec.Data.Hash.Algorithm))
+	}
+	digest, err := hex.DecodeString(swag.StringValue(spec.Data.Hash.Value))
+	if err != nil {
+		return nil, err
+	}
+	return &MessageSignature{
+		MessageDigest: &HashOutput{Algorithm: HashAlgorithmSHA256, Digest: digest},
+		Signature:     spec.Signature.Content,
+	}, nil
+}
+
+func tlogEntry(logEntry models.LogEntryAnon) (*TransparencyLogEntry, error) {
+	bodyStr, ok := logEntry.Body.(string)
+	if !ok {
+		return nil, errors.New("entry body is not a base64 encoded string")
+	}
+	body, err := base64.StdEncoding.DecodeString(bodyStr)
+	if err != nil {
+		return nil, errors.Wrap(err, "decoding entry body")
+	}
+	logID, err := hex.DecodeString(swag.StringValue(logEntry.LogID))
+	if err != nil {
+		return nil, errors.Wrap(err, "decoding log ID")
+	}
+	tle := &TransparencyLogEntry{
+		LogIndex:          swag.Int64Value(logEntry.LogIndex),
+		LogID:             LogID{KeyID: logID},
+		IntegratedTime:    swag.Int64Value(logEntry.IntegratedTime),
+		CanonicalizedBody: body,
+	}
+	if v := logEntry.Verification; v != nil {
+		if len(v.SignedEntryTimestamp) != 0 {
+			tle.InclusionPromise = &InclusionPromise{SignedEntryTimestamp: v.SignedEntryTimestamp}
+		}
+		if p := v.InclusionProof; p != nil {
+			rootHash, err := hex.DecodeString(swag.StringValue(p.RootHash))
+			if err != nil {
+				return nil, errors.Wrap(err, "decoding root hash")
+			}
+			proof := &InclusionProof{
+				LogIndex: swag.Int64Value(p.LogIndex),
+				RootHash: rootHash,
+				TreeSize: swag.Int64Value(p.TreeSize),
+				Hashes:   [][]byte{},
+			}
+			for _, h := range p.Hashes {
+				hb, err := hex.DecodeString(h)
+				if err != nil {
+					return nil, errors.Wrap(err, "decoding inclusion proof")
+				}
+				proof.Hashes = append(proof.Hashes, hb)
+			}
+			tle.InclusionProof = proof
+		}
+	}
+	return tle, nil
+}
+
+// LogEntry converts a transparency log entry from a bundle back into the form returned by the API,
+// so that it can be checked with the same code used for entries fetched from the log
+func (t TransparencyLogEntry) LogEntry() models.LogEntryAnon {
+	le := models.LogEntryAnon{
+		Body:           base64.StdEncoding.EncodeToString(t.CanonicalizedBody),
+		IntegratedTime: swag.Int64(t.IntegratedTime),
+		LogID:          swag.String(hex.EncodeToString(t.LogID.KeyID)),
+		LogIndex:       swag.Int64(t.LogIndex),
+	}
+	if t.InclusionPromise != nil || t.InclusionProof != nil {
+		le.Verification = &models.LogEntryAnonVerification{}
+	}
+	if t.InclusionPromise != nil {
+		le.Verification.SignedEntryTimestamp = strfmt.Base64(t.InclusionPromise.SignedEntryTimestamp)
+	}
+	if p := t.InclusionProof; p != nil {
+		proof := &models.InclusionProof{
+			LogIndex: swag.Int64(p.LogIndex),
+			RootHash: swag.String(hex.EncodeToString(p.RootHash)),
+			TreeSize: swag.Int64(p.TreeSize),
+			Hashes:   []string{},
+		}
+		for _, h := range p.Hashes {
+			proof.Hashes = append(proof.Hashes, hex.EncodeToString(h))
+		}
+		le.Verification.InclusionProof = proof
+	}
+	return le
+}
+
+// VerifyContent checks that the message signature carried by the bundle is the one recorded in the
+// log entry body, and that it covers the given artifact digest if one is supplied
+func (b *Bundle) VerifyContent(artifactDigest []byte) error {
+	if b.MessageSignature == nil {
+		if artifactDigest != nil {
+			return errors.New("bundle does not contain a message signature to compare the artifact against")
+		}
+		return nil
+	}
+	ms := b.MessageSignature
+	if ms.MessageDigest == nil || ms.MessageDigest.Algorithm != HashAlgorithmSHA256 {
+		return errors.New("bundle message signature must carry a SHA2_256 digest")
+	}
+	if artifactDigest != nil && !bytes.Equal(artifactDigest, ms.MessageDigest.Digest) {
+		return errors.New("artifact digest does not match the digest in the bundle")
+	}
+
+	for _, tle := range b.VerificationMaterial.TlogEntries {
+		pe, err := models.UnmarshalProposedEntry(bytes.NewReader(tle.CanonicalizedBody), runtime.JSONConsumer())
+		if err != nil {
+			return errors.Wrap(err, "parsing entry body")
+		}
+		hr, ok := pe.(*models.Hashedrekord)
+		if !ok {
+			return fmt.Errorf("bundle with a message signature contains a %s entry", pe.Kind())
+		}
+		logged, err := messageSignature(hr)
+		if err != nil {
+			return err
+		}
+		if !bytes.Equal(logged.Signature, ms.Signature) || !bytes.Equal(logged.MessageDigest.Digest, ms.MessageDigest.Digest) {
+			return errors.New("message signature in bundle does not match the log entry")
+		}
+	}
+	return nil
+}
+
+// keyHint identifies a public key by the hex encoded SHA-256 digest of its canonical encoding
+func keyHint(keyBytes []byte) string {
+	h := sha256.Sum256(keyBytes)
+	return hex.EncodeToString(h[:])
+}
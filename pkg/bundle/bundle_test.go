@@ -0,0 +1,223 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bundle
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-openapi/swag"
+	"github.com/sigstore/rekor/pkg/generated/models"
+	"github.com/sigstore/rekor/pkg/types"
+	_ "github.com/sigstore/rekor/pkg/types/hashedrekord/v0.0.1"
+	"github.com/sigstore/sigstore/pkg/signature"
+)
+
+func testLogEntry(t *testing.T) (models.LogEntryAnon, []byte, []byte) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyBytes := pem.EncodeToMemory(&pem.Block{Bytes: der, Type: "PUBLIC KEY"})
+
+	artifact := []byte("my artifact")
+	digest := sha256.Sum256(artifact)
+	signer, err := signature.LoadSigner(key, crypto.SHA256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := signer.SignMessage(bytes.NewReader(artifact))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	pe, err := types.NewProposedEntry(ctx, "hashedrekord", "0.0.1", types.ArtifactProperties{
+		ArtifactHash:   hex.EncodeToString(digest[:]),
+		SignatureBytes: sig,
+		PublicKeyBytes: keyBytes,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry, err := types.NewEntry(pe)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := types.CanonicalizeEntry(ctx, entry, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	logID := sha256.Sum256([]byte("log key"))
+	return models.LogEntryAnon{
+		Body:           base64.StdEncoding.EncodeToString(body),
+		IntegratedTime: swag.Int64(1640000000),
+		LogID:          swag.String(hex.EncodeToString(logID[:])),
+		LogIndex:       swag.Int64(5),
+		Verification: &models.LogEntryAnonVerification{
+			SignedEntryTimestamp: []byte("signed entry timestamp"),
+			InclusionProof: &models.InclusionProof{
+				LogIndex: swag.Int64(5),
+				RootHash: swag.String(hex.EncodeToString(logID[:])),
+				TreeSize: swag.Int64(10),
+				Hashes:   []string{hex.EncodeToString(digest[:])},
+			},
+		},
+	}, digest[:], sig
+}
+
+func TestFromLogEntry(t *testing.T) {
+	logEntry, digest, sig := testLogEntry(t)
+
+	b, err := FromLogEntry(logEntry)
+	if err != nil {
+		t.Fatalf("FromLogEntry() error = %v", err)
+	}
+	if b.MediaType != MediaType {
+		t.Errorf("unexpected media type %s", b.MediaType)
+	}
+	tle := b.VerificationMaterial.TlogEntries[0]
+	if tle.KindVersion != (KindVersion{Kind: "hashedrekord", Version: "0.0.1"}) {
+		t.Errorf("unexpected kind and version %+v", tle.KindVersion)
+	}
+	if b.VerificationMaterial.PublicKey == nil || b.VerificationMaterial.X509CertificateChain != nil {
+		t.Errorf("expected a public key hint, got %+v", b.VerificationMaterial)
+	}
+	if b.MessageSignature == nil || !bytes.Equal(b.MessageSignature.MessageDigest.Digest, digest) || !bytes.Equal(b.MessageSignature.Signature, sig) {
+		t.Errorf("unexpected message signature %+v", b.MessageSignature)
+	}
+
+	// the entry must survive a round trip through the bundle unchanged
+	raw, err := json.Marshal(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(raw), `"logIndex":"5"`) || !strings.Contains(string(raw), `"treeSize":"10"`) {
+		t.Errorf("expected 64 bit integers to be encoded as strings: %s", raw)
+	}
+	parsed, err := Parse(raw)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if got := parsed.VerificationMaterial.TlogEntries[0].LogEntry(); !reflect.DeepEqual(got, logEntry) {
+		t.Errorf("LogEntry() = %+v, want %+v", got, logEntry)
+	}
+
+	if err := parsed.VerifyContent(digest); err != nil {
+		t.Errorf("VerifyContent() error = %v", err)
+	}
+	if err := parsed.VerifyContent(make([]byte, sha256.Size)); err == nil {
+		t.Error("expected error verifying a different artifact")
+	}
+	parsed.MessageSignature.Signature = []byte("not the logged signature")
+	if err := parsed.VerifyContent(nil); err == nil {
+		t.Error("expected error when message signature does not match the log entry")
+	}
+}
+
+func TestMerge(t *testing.T) {
+	logEntry, digest, _ := testLogEntry(t)
+	b, err := FromLogEntry(logEntry)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the same entry in a second log
+	mirrorID := sha256.Sum256([]byte("mirror key"))
+	mirrorEntry := logEntry
+	mirrorEntry.LogID = swag.String(hex.EncodeToString(mirrorID[:]))
+	mirrorEntry.LogIndex = swag.Int64(42)
+	mirror, err := FromLogEntry(mirrorEntry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Merge(mirror); err != nil {
+		t.Fatalf("Merge() error = %v", err)
+	}
+	if len(b.VerificationMaterial.TlogEntries) != 2 || b.VerificationMaterial.TlogEntries[1].LogIndex != 42 {
+		t.Errorf("unexpected log entries after merge: %+v", b.VerificationMaterial.TlogEntries)
+	}
+	if err := b.VerifyContent(digest); err != nil {
+		t.Errorf("VerifyContent() error = %v", err)
+	}
+
+	other, _, _ := testLogEntry(t)
+	otherBundle, err := FromLogEntry(other)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Merge(otherBundle); err == nil {
+		t.Error("expected error merging a bundle for a different entry")
+	}
+}
+
+func TestParse(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		raw     string
+		wantErr bool
+	}{
+		{
+			name: "valid",
+			raw:  `{"mediaType":"application/vnd.dev.sigstore.bundle+json;version=0.1","verificationMaterial":{"tlogEntries":[{"logIndex":"1","logId":{"keyId":""},"kindVersion":{"kind":"hashedrekord","version":"0.0.1"},"integratedTime":"1","canonicalizedBody":""}]}}`,
+		},
+		{
+			name:    "wrong media type",
+			raw:     `{"mediaType":"application/json","verificationMaterial":{"tlogEntries":[{}]}}`,
+			wantErr: true,
+		},
+		{
+			name:    "no log entries",
+			raw:     `{"mediaType":"application/vnd.dev.sigstore.bundle+json;version=0.1","verificationMaterial":{"tlogEntries":[]}}`,
+			wantErr: true,
+		},
+		{
+			name:    "signature and envelope",
+			raw:     `{"mediaType":"application/vnd.dev.sigstore.bundle+json;version=0.1","verificationMaterial":{"tlogEntries":[{}]},"messageSignature":{"signature":""},"dsseEnvelope":{}}`,
+			wantErr: true,
+		},
+		{
+			name:    "not json",
+			raw:     `bundle`,
+			wantErr: true,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Parse([]byte(tt.raw)); (err != nil) != tt.wantErr {
+				t.Errorf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
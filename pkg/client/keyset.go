@@ -0,0 +1,61 @@
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/sigstore/rekor/pkg/verify"
+)
+
+// ErrNoKeySet is returned by GetLogKeys for servers which do not publish the keys of their log
+var ErrNoKeySet = errors.New("the server does not publish the keys of its log")
+
+// GetLogKeys fetches the current and previous keys of the log at rekorServerURL. The keys are only as
+// trustworthy as the connection to the server; clients which pin the keys of a log should not use them.
+func GetLogKeys(ctx context.Context, rekorServerURL string, opts ...Option) (*verify.LogKeys, error) {
+	httpClient, err := NewHTTPClient(opts...)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(rekorServerURL, "/")+verify.KeySetPath, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, ErrNoKeySet
+	default:
+		return nil, fmt.Errorf("fetching the keys of the log: %s", resp.Status)
+	}
+	lk := &verify.LogKeys{}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(lk); err != nil {
+		return nil, fmt.Errorf("parsing the keys of the log: %w", err)
+	}
+	return lk, nil
+}
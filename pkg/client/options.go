@@ -14,13 +14,25 @@
 
 package client
 
-import "net/http"
+import (
+	"bytes"
+	"crypto/tls"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/sigstore/rekor/pkg/compression"
+)
 
 // Option is a functional option for customizing static signatures.
 type Option func(*options)
 
 type options struct {
-	UserAgent string
+	UserAgent       string
+	RequestEncoding string
+	Transport       http.RoundTripper
+	TLSConfig       *tls.Config
 }
 
 func makeOptions(opts ...Option) *options {
@@ -42,6 +54,31 @@ func WithUserAgent(userAgent string) Option {
 	}
 }
 
+// WithTransport sets the transport which requests are sent with, e.g. to add tracing or custom
+// dialing; it takes precedence over WithTLSConfig.
+func WithTransport(transport http.RoundTripper) Option {
+	return func(o *options) {
+		o.Transport = transport
+	}
+}
+
+// WithTLSConfig sets the TLS configuration of the default transport, e.g. to trust the CA which
+// signed the certificate of an internal server.
+func WithTLSConfig(config *tls.Config) Option {
+	return func(o *options) {
+		o.TLSConfig = config
+	}
+}
+
+// transport returns the transport requests are sent with; unless one was given, this is the transport
+// shared by every client with the same TLS configuration (see newTransport)
+func (o *options) transport() http.RoundTripper {
+	if o.Transport != nil {
+		return o.Transport
+	}
+	return sharedTransport(o.TLSConfig)
+}
+
 type roundTripper struct {
 	http.RoundTripper
 	UserAgent string
@@ -53,10 +90,111 @@ func (rt *roundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
 	return rt.RoundTripper.RoundTrip(req)
 }
 
+// WithRequestCompression compresses request bodies with the given content coding (gzip or zstd);
+// only use this with servers which accept compressed requests.
+func WithRequestCompression(encoding string) Option {
+	return func(o *options) {
+		o.RequestEncoding = encoding
+	}
+}
+
+const (
+	// request bodies smaller than this are sent uncompressed
+	minCompressedRequestSize = 1024
+	// limit on the size of response bodies once decompressed
+	maxDecompressedResponseSize = 256 << 20
+)
+
+// compressionRoundTripper compresses request bodies if requested and decompresses zstd and gzip
+// response bodies, which net/http only does for gzip
+type compressionRoundTripper struct {
+	http.RoundTripper
+	RequestEncoding string
+}
+
+// RoundTrip implements `http.RoundTripper`
+func (rt *compressionRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	if req.Header.Get("Accept-Encoding") == "" {
+		req.Header.Set("Accept-Encoding", compression.Supported)
+	}
+	if rt.RequestEncoding != "" && req.Body != nil && req.Header.Get("Content-Encoding") == "" {
+		if err := compressRequest(req, rt.RequestEncoding); err != nil {
+			return nil, err
+		}
+	}
+
+	resp, err := rt.RoundTripper.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	encoding := resp.Header.Get("Content-Encoding")
+	if encoding == "" || strings.EqualFold(encoding, "identity") {
+		return resp, nil
+	}
+	body, err := compression.NewReader(encoding, resp.Body, maxDecompressedResponseSize)
+	if err != nil {
+		_ = resp.Body.Close()
+		return nil, err
+	}
+	resp.Body = &decompressedBody{ReadCloser: body, compressed: resp.Body}
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = -1
+	resp.Uncompressed = true
+	return resp, nil
+}
+
+// compressRequest replaces the body of req with its compressed form unless it is small
+func compressRequest(req *http.Request, encoding string) error {
+	body, err := ioutil.ReadAll(req.Body)
+	_ = req.Body.Close()
+	if err != nil {
+		return err
+	}
+	if len(body) >= minCompressedRequestSize {
+		buf := &bytes.Buffer{}
+		w, err := compression.NewWriter(encoding, buf)
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write(body); err != nil {
+			return err
+		}
+		if err := w.Close(); err != nil {
+			return err
+		}
+		body = buf.Bytes()
+		req.Header.Set("Content-Encoding", encoding)
+	}
+	req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return ioutil.NopCloser(bytes.NewReader(body)), nil
+	}
+	req.ContentLength = int64(len(body))
+	return nil
+}
+
+type decompressedBody struct {
+	io.ReadCloser
+	compressed io.ReadCloser
+}
+
+// Close releases the decompressor and closes the underlying response body
+func (b *decompressedBody) Close() error {
+	_ = b.ReadCloser.Close()
+	return b.compressed.Close()
+}
+
 func createRoundTripper(inner http.RoundTripper, o *options) http.RoundTripper {
 	if inner == nil {
-		inner = http.DefaultTransport
+		inner = sharedTransport(nil)
+	}
+	inner = &compressionRoundTripper{
+		RoundTripper:    inner,
+		RequestEncoding: o.RequestEncoding,
 	}
+	inner = &problemRoundTripper{RoundTripper: inner}
 	if o.UserAgent == "" {
 		// There's nothing to do...
 		return inner
@@ -15,10 +15,16 @@
 package client
 
 import (
+	"bytes"
+	"io/ioutil"
 	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+
+	"github.com/sigstore/rekor/pkg/compression"
 )
 
 func TestMakeOptions(t *testing.T) {
@@ -34,6 +40,10 @@ func TestMakeOptions(t *testing.T) {
 		desc: "WithUserAgent",
 		opts: []Option{WithUserAgent("test user agent")},
 		want: &options{UserAgent: "test user agent"},
+	}, {
+		desc: "WithRequestCompression",
+		opts: []Option{WithRequestCompression("zstd")},
+		want: &options{RequestEncoding: "zstd"},
 	}}
 	for _, tc := range tests {
 		t.Run(tc.desc, func(t *testing.T) {
@@ -102,3 +112,58 @@ func TestCreateRoundTripper(t *testing.T) {
 		t.Errorf("roundTripper.RoundTrip() should have returned exactly the response of the inner RoundTripper. Wanted %v, got %v", testResp, gotResp)
 	}
 }
+
+func TestCompressionRoundTripper(t *testing.T) {
+	payload := strings.Repeat("entry ", 1000)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Accept-Encoding") != compression.Supported {
+			t.Errorf("unexpected Accept-Encoding %q", r.Header.Get("Accept-Encoding"))
+		}
+		body := r.Body
+		if encoding := r.Header.Get("Content-Encoding"); encoding != "" {
+			var err error
+			if body, err = compression.NewReader(encoding, r.Body, 1<<20); err != nil {
+				t.Fatal(err)
+			}
+		}
+		b, _ := ioutil.ReadAll(body)
+		if string(b) != payload {
+			t.Errorf("server received %q", b)
+		}
+
+		encoding := r.URL.Query().Get("encoding")
+		w.Header().Set("Content-Encoding", encoding)
+		cw, err := compression.NewWriter(encoding, w)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, _ = cw.Write(b)
+		_ = cw.Close()
+	}))
+	defer server.Close()
+
+	for _, encoding := range []string{compression.Gzip, compression.Zstd} {
+		t.Run(encoding, func(t *testing.T) {
+			rt := createRoundTripper(nil, &options{RequestEncoding: encoding})
+			req, err := http.NewRequest(http.MethodPost, server.URL+"?encoding="+encoding, bytes.NewBufferString(payload))
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := rt.RoundTrip(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			if resp.Header.Get("Content-Encoding") != "" || !resp.Uncompressed {
+				t.Errorf("expected response to be decompressed, got headers %v", resp.Header)
+			}
+			b, err := ioutil.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(b) != payload {
+				t.Errorf("unexpected response body %q", b)
+			}
+		})
+	}
+}
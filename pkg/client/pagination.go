@@ -0,0 +1,76 @@
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"github.com/sigstore/rekor/pkg/generated/client"
+	"github.com/sigstore/rekor/pkg/generated/client/entries"
+	"github.com/sigstore/rekor/pkg/generated/client/index"
+	"github.com/sigstore/rekor/pkg/generated/models"
+)
+
+// remaining returns the page size to request once n results have been fetched, or nil to leave it
+// to the server if there is no limit
+func remaining(limit, n int) *int64 {
+	if limit <= 0 {
+		return nil
+	}
+	r := int64(limit - n)
+	return &r
+}
+
+// SearchIndex searches the index of the log, following the page tokens returned by the server until
+// all results, or limit of them if it is positive, have been fetched
+func SearchIndex(c *client.Rekor, params *index.SearchIndexParams, limit int) ([]string, error) {
+	var uuids []string
+	for {
+		params.SetPageSize(remaining(limit, len(uuids)))
+		resp, err := c.Index.SearchIndex(params)
+		if err != nil {
+			return nil, err
+		}
+		uuids = append(uuids, resp.Payload...)
+		if limit > 0 && len(uuids) >= limit {
+			return uuids[:limit], nil
+		}
+		if resp.XRekorNextPageToken == "" {
+			return uuids, nil
+		}
+		token := resp.XRekorNextPageToken
+		params.SetPageToken(&token)
+	}
+}
+
+// SearchLogQuery searches the log for entries, following the page tokens returned by the server
+// until all results, or limit of them if it is positive, have been fetched
+func SearchLogQuery(c *client.Rekor, params *entries.SearchLogQueryParams, limit int) ([]models.LogEntry, error) {
+	var logEntries []models.LogEntry
+	for {
+		params.SetPageSize(remaining(limit, len(logEntries)))
+		resp, err := c.Entries.SearchLogQuery(params)
+		if err != nil {
+			return nil, err
+		}
+		logEntries = append(logEntries, resp.Payload...)
+		if limit > 0 && len(logEntries) >= limit {
+			return logEntries[:limit], nil
+		}
+		if resp.XRekorNextPageToken == "" {
+			return logEntries, nil
+		}
+		token := resp.XRekorNextPageToken
+		params.SetPageToken(&token)
+	}
+}
@@ -0,0 +1,69 @@
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/sigstore/rekor/pkg/generated/client/index"
+	"github.com/sigstore/rekor/pkg/generated/models"
+)
+
+func TestSearchIndexPages(t *testing.T) {
+	results := []string{"a", "b", "c", "d", "e"}
+	var requests []string
+	testServer := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			requests = append(requests, r.URL.RawQuery)
+			start, _ := strconv.Atoi(r.URL.Query().Get("pageToken"))
+			end := start + 2
+			if size, err := strconv.Atoi(r.URL.Query().Get("pageSize")); err == nil && size < 2 {
+				end = start + size
+			}
+			if end < len(results) {
+				w.Header().Set("X-Rekor-Next-Page-Token", strconv.Itoa(end))
+			} else {
+				end = len(results)
+			}
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(results[start:end])
+		}))
+	defer testServer.Close()
+
+	c, err := GetRekorClient(testServer.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for limit, want := range map[int]string{0: "[a b c d e]", 3: "[a b c]", 10: "[a b c d e]"} {
+		requests = nil
+		params := index.NewSearchIndexParams()
+		params.Query = &models.SearchIndex{Email: "jdoe@example.com"}
+		uuids, err := SearchIndex(c, params, limit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if fmt.Sprint(uuids) != want {
+			t.Errorf("limit %d: got %v, want %s", limit, uuids, want)
+		}
+		if limit == 3 && fmt.Sprint(requests) != "[pageSize=3 pageSize=1&pageToken=2]" {
+			t.Errorf("limit %d: unexpected requests %v", limit, requests)
+		}
+	}
+}
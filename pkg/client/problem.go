@@ -0,0 +1,116 @@
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/sigstore/rekor/pkg/generated/models"
+)
+
+// problemContentType is the media type servers send the problem details of failed requests with
+const problemContentType = "application/problem+json"
+
+// problemRoundTripper tells the server that problem details may be sent as application/problem+json
+type problemRoundTripper struct {
+	http.RoundTripper
+}
+
+// RoundTrip implements `http.RoundTripper`
+func (rt *problemRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if accept := req.Header.Values("Accept"); len(accept) > 0 && !strings.Contains(strings.Join(accept, ","), problemContentType) {
+		req = req.Clone(req.Context())
+		req.Header.Add("Accept", problemContentType)
+	}
+	return rt.RoundTripper.RoundTrip(req)
+}
+
+// problemResponse is implemented by the error responses of the generated client which hold the
+// problem details sent by the server
+type problemResponse interface {
+	error
+	GetPayload() *models.Error
+}
+
+// Problem returns the problem details the server sent for a failed request, if err or any error it
+// wraps is an error response of the generated client holding them
+func Problem(err error) (*models.Error, bool) {
+	var resp problemResponse
+	if !errors.As(err, &resp) || resp.GetPayload() == nil {
+		return nil, false
+	}
+	return resp.GetPayload(), true
+}
+
+// ErrorCode returns the machine-readable code of the problem the server sent for a failed request,
+// or "" if err does not hold one
+func ErrorCode(err error) models.ErrorCode {
+	if p, ok := Problem(err); ok {
+		return p.ErrorCode
+	}
+	return ""
+}
+
+// DescribeError replaces the description of an error response of the generated client in the
+// message of err, which dumps the whole response, with the problem details sent by the server
+func DescribeError(err error) error {
+	var resp problemResponse
+	if !errors.As(err, &resp) || resp.GetPayload() == nil {
+		return err
+	}
+	return &problemError{
+		error:   err,
+		message: strings.Replace(err.Error(), resp.Error(), describeProblem(resp.GetPayload()), 1),
+	}
+}
+
+// problemError is an error whose message describes the problem details sent by the server
+type problemError struct {
+	error
+	message string
+}
+
+func (e *problemError) Error() string {
+	return e.message
+}
+
+func (e *problemError) Unwrap() error {
+	return e.error
+}
+
+// describeProblem formats problem details as "<detail> (<errorCode>)"; servers preceding problem
+// details only set code and message
+func describeProblem(p *models.Error) string {
+	detail, status := p.Detail, p.Status
+	if detail == "" {
+		detail = p.Message
+	}
+	if status == 0 {
+		status = p.Code
+	}
+	if detail == "" {
+		detail = p.Title
+	}
+	if detail == "" {
+		detail = http.StatusText(int(status))
+	}
+	if p.ErrorCode == "" {
+		return fmt.Sprintf("%s (status %d)", detail, status)
+	}
+	return fmt.Sprintf("%s (%s)", detail, p.ErrorCode)
+}
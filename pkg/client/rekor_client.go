@@ -15,11 +15,15 @@
 package client
 
 import (
+	"fmt"
+	"net/http"
 	"net/url"
+	"path"
 
 	"github.com/go-openapi/runtime"
 	httptransport "github.com/go-openapi/runtime/client"
 	"github.com/go-openapi/strfmt"
+	"github.com/sigstore/rekor/pkg/compression"
 	"github.com/sigstore/rekor/pkg/generated/client"
 	"github.com/sigstore/rekor/pkg/util"
 	"github.com/spf13/viper"
@@ -30,23 +34,72 @@ func GetRekorClient(rekorServerURL string, opts ...Option) (*client.Rekor, error
 	if err != nil {
 		return nil, err
 	}
-	o := makeOptions(opts...)
+	o, err := resolveOptions(opts...)
+	if err != nil {
+		return nil, err
+	}
 
-	rt := httptransport.New(url.Host, client.DefaultBasePath, []string{url.Scheme})
+	// a path in the server URL addresses one of several logs hosted by the server, e.g. https://host/logs/<name>
+	rt := httptransport.New(url.Host, path.Join(client.DefaultBasePath, url.Path), []string{url.Scheme})
 	rt.Consumers["application/yaml"] = YamlConsumer()
+	rt.Consumers[problemContentType] = runtime.JSONConsumer()
 	rt.Consumers["application/x-pem-file"] = runtime.TextConsumer()
 	rt.Consumers["application/pem-certificate-chain"] = runtime.TextConsumer()
 	rt.Producers["application/yaml"] = YamlProducer()
 	rt.Producers["application/timestamp-query"] = runtime.ByteStreamProducer()
 	rt.Consumers["application/timestamp-reply"] = runtime.ByteStreamConsumer()
+	rt.Consumers["application/x-ndjson"] = runtime.ByteStreamConsumer()
 
+	var auths []runtime.ClientAuthInfoWriter
 	if viper.GetString("api-key") != "" {
-		rt.DefaultAuthentication = httptransport.APIKeyAuth("apiKey", "query", viper.GetString("api-key"))
+		auths = append(auths, httptransport.APIKeyAuth("apiKey", "query", viper.GetString("api-key")))
+	}
+	if viper.GetString("auth-token") != "" {
+		auths = append(auths, httptransport.BearerToken(viper.GetString("auth-token")))
+	}
+	if len(auths) > 0 {
+		rt.DefaultAuthentication = httptransport.Compose(auths...)
 	}
 
-	rt.Transport = createRoundTripper(rt.Transport, o)
+	rt.Transport = createRoundTripper(o.transport(), o)
 
 	registry := strfmt.Default
 	registry.Add("signedCheckpoint", &util.SignedNote{}, util.SignedCheckpointValidator)
 	return client.New(rt, registry), nil
 }
+
+// NewHTTPClient returns a client for requests made alongside those to a rekor server, such as fetching
+// artifacts, which uses the same transport, proxy and TLS configuration as GetRekorClient
+func NewHTTPClient(opts ...Option) (*http.Client, error) {
+	o, err := resolveOptions(opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &http.Client{Transport: o.transport()}, nil
+}
+
+// NewFetchClient returns a client for fetching content from third parties, such as artifacts,
+// signatures and public keys given by URL. It shares the proxy, timeouts and connection pooling of
+// the transport of NewHTTPClient, but not the TLS configuration set for the rekor server with
+// --cacert and --insecure-skip-verify, so certificates are always verified against the system roots.
+func NewFetchClient() *http.Client {
+	return &http.Client{Transport: sharedTransport(nil)}
+}
+
+// resolveOptions applies opts, falling back to the flags of the CLI for anything they leave unset
+func resolveOptions(opts ...Option) (*options, error) {
+	o := makeOptions(opts...)
+	if o.RequestEncoding == "" {
+		o.RequestEncoding = viper.GetString("request-compression")
+	}
+	if o.RequestEncoding != "" && !compression.IsSupported(o.RequestEncoding) {
+		return nil, fmt.Errorf("unsupported request compression %q, expected one of %s", o.RequestEncoding, compression.Supported)
+	}
+	if o.Transport == nil && o.TLSConfig == nil {
+		var err error
+		if o.TLSConfig, err = tlsConfigFromFlags(); err != nil {
+			return nil, err
+		}
+	}
+	return o, nil
+}
@@ -16,12 +16,22 @@
 package client
 
 import (
+	"encoding/pem"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strings"
+	"sync/atomic"
 	"testing"
 
 	"github.com/spf13/viper"
+
+	"github.com/sigstore/rekor/pkg/generated/models"
 )
 
 func TestAPIKey(t *testing.T) {
@@ -78,6 +88,46 @@ func TestAPIKey(t *testing.T) {
 
 }
 
+func TestAuthToken(t *testing.T) {
+	var authorization string
+	testServer := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			authorization = r.Header.Get("Authorization")
+			w.WriteHeader(http.StatusOK)
+		}))
+	defer testServer.Close()
+
+	viper.Set("auth-token", "thisIsAToken")
+	defer viper.Set("auth-token", "")
+	client, err := GetRekorClient(testServer.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = client.Tlog.GetLogInfo(nil)
+	if authorization != "Bearer thisIsAToken" {
+		t.Errorf("Authorization header = %q, want bearer token", authorization)
+	}
+}
+
+func TestServerURLPath(t *testing.T) {
+	var requestPath string
+	testServer := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			requestPath = r.URL.Path
+			w.WriteHeader(http.StatusOK)
+		}))
+	defer testServer.Close()
+
+	client, err := GetRekorClient(testServer.URL + "/logs/team-a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = client.Tlog.GetLogInfo(nil)
+	if requestPath != "/logs/team-a/api/v1/log" {
+		t.Errorf("request path = %q, want /logs/team-a/api/v1/log", requestPath)
+	}
+}
+
 func TestGetRekorClientWithOptions(t *testing.T) {
 	t.Parallel()
 	expectedUserAgent := "test User-Agent"
@@ -105,3 +155,167 @@ func TestGetRekorClientWithOptions(t *testing.T) {
 		t.Fatal("no requests were received")
 	}
 }
+
+func TestTLSFlags(t *testing.T) {
+	testServer := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}))
+	defer testServer.Close()
+
+	caPath := filepath.Join(t.TempDir(), "ca.pem")
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: testServer.Certificate().Raw})
+	if err := ioutil.WriteFile(caPath, caPEM, 0600); err != nil {
+		t.Fatal(err)
+	}
+	invalidPath := filepath.Join(t.TempDir(), "invalid.pem")
+	if err := ioutil.WriteFile(invalidPath, []byte("not a certificate"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		cacert    string
+		insecure  bool
+		wantErr   bool
+		wantFetch bool
+	}{
+		{name: "untrusted certificate"},
+		{name: "cacert", cacert: caPath, wantFetch: true},
+		{name: "insecure-skip-verify", insecure: true, wantFetch: true},
+		{name: "invalid cacert", cacert: invalidPath, wantErr: true},
+		{name: "missing cacert", cacert: filepath.Join(t.TempDir(), "missing.pem"), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			viper.Set("cacert", tt.cacert)
+			viper.Set("insecure-skip-verify", tt.insecure)
+			defer viper.Set("cacert", "")
+			defer viper.Set("insecure-skip-verify", false)
+
+			httpClient, err := NewHTTPClient()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewHTTPClient() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			resp, err := httpClient.Get(testServer.URL)
+			if err == nil {
+				resp.Body.Close()
+			}
+			if (err == nil) != tt.wantFetch {
+				t.Errorf("Get() error = %v, want success %v", err, tt.wantFetch)
+			}
+
+			rekorClient, err := GetRekorClient(testServer.URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := rekorClient.Tlog.GetLogInfo(nil); err != nil && strings.Contains(err.Error(), "certificate") == tt.wantFetch {
+				t.Errorf("GetLogInfo() error = %v, want TLS verification to succeed %v", err, tt.wantFetch)
+			}
+
+			// content of third parties is fetched without the TLS settings meant for the server
+			if resp, err := NewFetchClient().Get(testServer.URL); err == nil {
+				resp.Body.Close()
+				t.Error("NewFetchClient() fetched from a server with an untrusted certificate")
+			}
+		})
+	}
+}
+
+func TestWithTransport(t *testing.T) {
+	m := &mockRoundTripper{err: http.ErrHandlerTimeout}
+	client, err := GetRekorClient("https://rekor.example.com", WithTransport(m))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = client.Tlog.GetLogInfo(nil)
+	if len(m.gotReqs) != 1 || m.gotReqs[0].URL.Host != "rekor.example.com" {
+		t.Errorf("custom transport received requests %v", m.gotReqs)
+	}
+}
+
+func TestProblem(t *testing.T) {
+	t.Parallel()
+	testServer := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			if !strings.Contains(strings.Join(r.Header.Values("Accept"), ","), problemContentType) {
+				t.Errorf("Accept header %q does not include %s", r.Header.Values("Accept"), problemContentType)
+			}
+			w.Header().Set("Content-Type", problemContentType)
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte(`{"code":503,"message":"Unexpected error communicating with transparency log","status":503,"title":"Service Unavailable","detail":"Unexpected error communicating with transparency log","errorCode":"backend_unavailable"}`))
+		}))
+	defer testServer.Close()
+
+	client, err := GetRekorClient(testServer.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = client.Tlog.GetLogInfo(nil)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if code := ErrorCode(fmt.Errorf("getting log info: %w", err)); code != models.ErrorCodeBackendUnavailable {
+		t.Errorf("ErrorCode() = %q, want %q", code, models.ErrorCodeBackendUnavailable)
+	}
+	want := "getting log info: Unexpected error communicating with transparency log (backend_unavailable)"
+	if got := DescribeError(fmt.Errorf("getting log info: %w", err)).Error(); got != want {
+		t.Errorf("DescribeError() = %q, want %q", got, want)
+	}
+	if got := DescribeError(os.ErrNotExist); got != os.ErrNotExist {
+		t.Errorf("DescribeError() changed an error without problem details to %v", got)
+	}
+}
+
+func TestSharedTransport(t *testing.T) {
+	var conns int32
+	testServer := httptest.NewUnstartedServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			if r.ProtoMajor != 2 {
+				t.Errorf("request sent over %s, want HTTP/2", r.Proto)
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+	testServer.EnableHTTP2 = true
+	testServer.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			atomic.AddInt32(&conns, 1)
+		}
+	}
+	testServer.StartTLS()
+	defer testServer.Close()
+
+	caPath := filepath.Join(t.TempDir(), "ca.pem")
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: testServer.Certificate().Raw})
+	if err := ioutil.WriteFile(caPath, caPEM, 0600); err != nil {
+		t.Fatal(err)
+	}
+	viper.Set("cacert", caPath)
+	defer viper.Set("cacert", "")
+
+	// clients created separately, as by each upload of a batch, share their connections
+	for i := 0; i < 3; i++ {
+		httpClient, err := NewHTTPClient()
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := httpClient.Get(testServer.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, _ = io.Copy(ioutil.Discard, resp.Body)
+		resp.Body.Close()
+
+		rekorClient, err := GetRekorClient(testServer.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, _ = rekorClient.Tlog.GetLogInfo(nil)
+	}
+	if n := atomic.LoadInt32(&conns); n != 1 {
+		t.Errorf("opened %d connections, want 1", n)
+	}
+}
@@ -0,0 +1,85 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sync"
+
+	"github.com/spf13/viper"
+
+	"github.com/sigstore/rekor/pkg/log"
+)
+
+var insecureWarning sync.Once
+
+type tlsFlags struct {
+	caPath   string
+	insecure bool
+}
+
+// flagsTLSConfigs holds the configuration built for each combination of flags, so that the clients
+// created by a command share a transport and its connections
+var flagsTLSConfigs sync.Map
+
+// tlsConfigFromFlags returns the TLS configuration requested with the --cacert and --insecure-skip-verify
+// flags of the CLI, or nil if neither was given
+func tlsConfigFromFlags() (*tls.Config, error) {
+	flags := tlsFlags{caPath: viper.GetString("cacert"), insecure: viper.GetBool("insecure-skip-verify")}
+	if flags.caPath == "" && !flags.insecure {
+		return nil, nil
+	}
+	if config, ok := flagsTLSConfigs.Load(flags); ok {
+		return config.(*tls.Config), nil
+	}
+	config, err := newTLSConfig(flags.caPath, flags.insecure)
+	if err != nil {
+		return nil, err
+	}
+	stored, _ := flagsTLSConfigs.LoadOrStore(flags, config)
+	return stored.(*tls.Config), nil
+}
+
+func newTLSConfig(caPath string, insecure bool) (*tls.Config, error) {
+	config := &tls.Config{MinVersion: tls.VersionTLS12}
+	if caPath != "" {
+		caBytes, err := ioutil.ReadFile(filepath.Clean(caPath))
+		if err != nil {
+			return nil, fmt.Errorf("reading CA certificates: %w", err)
+		}
+		// the certificates are trusted in addition to those of the system
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(caBytes) {
+			return nil, fmt.Errorf("no valid certificates found in %s", caPath)
+		}
+		config.RootCAs = pool
+	}
+	if insecure {
+		insecureWarning.Do(func() {
+			log.CliLogger.Warn("WARNING: TLS certificates are not verified (--insecure-skip-verify); anyone able to intercept " +
+				"the connection can impersonate the server. Only use this for testing.")
+		})
+		config.InsecureSkipVerify = true // #nosec G402
+	}
+	return config, nil
+}
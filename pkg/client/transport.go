@@ -0,0 +1,77 @@
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"crypto/tls"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// idle connections kept open to each host, so that commands sending many requests, such as
+	// watch, bench and uploads of checksums files, reuse connections rather than exhausting ports
+	maxIdleConnsPerHost = 32
+	maxIdleConns        = 128
+	// limit on the connections opened to each host, beyond which requests wait for a free one
+	maxConnsPerHost = 64
+	// TLS sessions cached for resumption, which saves a full handshake when a connection is reopened
+	tlsSessionCacheSize = 64
+)
+
+// sharedTransports holds the transport of each TLS configuration, nil for the default one, so that
+// every client in the process shares its connections
+var sharedTransports sync.Map
+
+// sharedTransport returns the transport shared by the clients using config
+func sharedTransport(config *tls.Config) *http.Transport {
+	if t, ok := sharedTransports.Load(config); ok {
+		return t.(*http.Transport)
+	}
+	t, _ := sharedTransports.LoadOrStore(config, newTransport(config))
+	return t.(*http.Transport)
+}
+
+// newTransport returns a transport which keeps connections alive, negotiates HTTP/2 with servers
+// which support it and resumes TLS sessions; like http.DefaultTransport it uses the proxy set in
+// HTTPS_PROXY, HTTP_PROXY and NO_PROXY
+func newTransport(config *tls.Config) *http.Transport {
+	if config == nil {
+		config = &tls.Config{MinVersion: tls.VersionTLS12}
+	} else {
+		config = config.Clone()
+	}
+	if config.ClientSessionCache == nil {
+		config.ClientSessionCache = tls.NewLRUClientSessionCache(tlsSessionCacheSize)
+	}
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+	}
+	return &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		DialContext:           dialer.DialContext,
+		TLSClientConfig:       config,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          maxIdleConns,
+		MaxIdleConnsPerHost:   maxIdleConnsPerHost,
+		MaxConnsPerHost:       maxConnsPerHost,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: time.Second,
+	}
+}
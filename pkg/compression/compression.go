@@ -0,0 +1,193 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compression
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// Content codings supported for request and response bodies, in order of preference
+const (
+	Zstd = "zstd"
+	Gzip = "gzip"
+)
+
+// Supported lists the supported content codings in the form of an Accept-Encoding header
+const Supported = Zstd + ", " + Gzip
+
+var (
+	// ErrUnsupported is returned for content codings other than those in Supported
+	ErrUnsupported = errors.New("unsupported content encoding")
+	// ErrTooLarge is returned by readers which exceed their limit once decompressed
+	ErrTooLarge = errors.New("decompressed content exceeds size limit")
+)
+
+// IsSupported reports whether encoding is one of the content codings in Supported
+func IsSupported(encoding string) bool {
+	switch strings.ToLower(encoding) {
+	case Zstd, Gzip:
+		return true
+	}
+	return false
+}
+
+// Writer compresses the data written to it; Close must be called to complete the stream
+type Writer interface {
+	io.WriteCloser
+	// Flush writes any pending compressed data to the underlying writer
+	Flush() error
+}
+
+var (
+	gzipWriters = sync.Pool{New: func() interface{} {
+		return gzip.NewWriter(nil)
+	}}
+	zstdEncoders = sync.Pool{New: func() interface{} {
+		// options are valid, so no error can occur
+		e, _ := zstd.NewWriter(nil, zstd.WithEncoderConcurrency(1))
+		return e
+	}}
+)
+
+type pooledWriter struct {
+	Writer
+	release func()
+}
+
+// Close completes the stream and returns the compressor to its pool
+func (p *pooledWriter) Close() error {
+	if p.release == nil {
+		return nil
+	}
+	err := p.Writer.Close()
+	p.release()
+	p.release = nil
+	return err
+}
+
+// NewWriter returns a writer which compresses data written to it with the given content coding to w
+func NewWriter(encoding string, w io.Writer) (Writer, error) {
+	switch strings.ToLower(encoding) {
+	case Gzip:
+		gw := gzipWriters.Get().(*gzip.Writer)
+		gw.Reset(w)
+		return &pooledWriter{Writer: gw, release: func() { gzipWriters.Put(gw) }}, nil
+	case Zstd:
+		zw := zstdEncoders.Get().(*zstd.Encoder)
+		zw.Reset(w)
+		return &pooledWriter{Writer: zw, release: func() { zstdEncoders.Put(zw) }}, nil
+	default:
+		return nil, fmt.Errorf("%w %q", ErrUnsupported, encoding)
+	}
+}
+
+// NewReader returns a reader which decompresses r according to the given content coding, failing
+// with ErrTooLarge once more than limit bytes have been decompressed
+func NewReader(encoding string, r io.Reader, limit int64) (io.ReadCloser, error) {
+	var rc io.ReadCloser
+	switch strings.ToLower(encoding) {
+	case Gzip:
+		gr, err := gzip.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		rc = gr
+	case Zstd:
+		zr, err := zstd.NewReader(r, zstd.WithDecoderConcurrency(1), zstd.WithDecoderMaxMemory(uint64(limit)))
+		if err != nil {
+			return nil, err
+		}
+		rc = zr.IOReadCloser()
+	default:
+		return nil, fmt.Errorf("%w %q", ErrUnsupported, encoding)
+	}
+	return &limitedReader{ReadCloser: rc, remaining: limit}, nil
+}
+
+// limitedReader reads up to remaining bytes, unlike io.LimitedReader returning an error rather than
+// io.EOF when there is more data
+type limitedReader struct {
+	io.ReadCloser
+	remaining int64
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	if l.remaining < 0 {
+		return 0, ErrTooLarge
+	}
+	// read one byte more than allowed to detect content beyond the limit
+	if int64(len(p)) > l.remaining+1 {
+		p = p[:l.remaining+1]
+	}
+	n, err := l.ReadCloser.Read(p)
+	l.remaining -= int64(n)
+	if l.remaining < 0 {
+		return n + int(l.remaining), ErrTooLarge
+	}
+	// the zstd decoder refuses frames which need more memory than the limit before decompressing them
+	if errors.Is(err, zstd.ErrWindowSizeExceeded) || errors.Is(err, zstd.ErrDecoderSizeExceeded) {
+		return n, ErrTooLarge
+	}
+	return n, err
+}
+
+// Negotiate returns the preferred content coding which is acceptable according to an Accept-Encoding
+// header, or "" if the content should not be compressed
+func Negotiate(acceptEncoding string) string {
+	weights := map[string]float64{}
+	wildcard := -1.0
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		params := strings.Split(part, ";")
+		coding := strings.ToLower(strings.TrimSpace(params[0]))
+		if coding == "" {
+			continue
+		}
+		q := 1.0
+		for _, param := range params[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if v, err := strconv.ParseFloat(param[2:], 64); err == nil {
+					q = v
+				}
+			}
+		}
+		if coding == "*" {
+			wildcard = q
+		} else {
+			weights[coding] = q
+		}
+	}
+
+	best, bestQ := "", 0.0
+	for _, coding := range []string{Zstd, Gzip} {
+		q, ok := weights[coding]
+		if !ok {
+			q = wildcard
+		}
+		if q > bestQ {
+			best, bestQ = coding, q
+		}
+	}
+	return best
+}
@@ -0,0 +1,103 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compression
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func TestNegotiate(t *testing.T) {
+	for _, tt := range []struct {
+		acceptEncoding string
+		want           string
+	}{
+		{"", ""},
+		{"identity", ""},
+		{"gzip", Gzip},
+		{"gzip, deflate, br", Gzip},
+		{"gzip, zstd", Zstd},
+		{"zstd;q=0.5, gzip", Gzip},
+		{"ZSTD", Zstd},
+		{"zstd;q=0, gzip;q=0", ""},
+		{"*", Zstd},
+		{"*;q=0.1, zstd;q=0", Gzip},
+	} {
+		if got := Negotiate(tt.acceptEncoding); got != tt.want {
+			t.Errorf("Negotiate(%q) = %q, want %q", tt.acceptEncoding, got, tt.want)
+		}
+	}
+}
+
+func compress(t *testing.T, encoding string, data []byte) []byte {
+	t.Helper()
+	buf := &bytes.Buffer{}
+	w, err := NewWriter(encoding, buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestRoundTrip(t *testing.T) {
+	data := []byte(strings.Repeat("rekor transparency log ", 1000))
+	for _, encoding := range []string{Gzip, Zstd} {
+		t.Run(encoding, func(t *testing.T) {
+			compressed := compress(t, encoding, data)
+			if len(compressed) >= len(data) {
+				t.Errorf("expected data to be compressed, got %d bytes from %d", len(compressed), len(data))
+			}
+
+			r, err := NewReader(encoding, bytes.NewReader(compressed), int64(len(data)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := ioutil.ReadAll(r)
+			if err != nil {
+				t.Fatalf("unexpected error at the limit: %v", err)
+			}
+			if !bytes.Equal(got, data) {
+				t.Error("decompressed data differs")
+			}
+
+			r, err = NewReader(encoding, bytes.NewReader(compressed), int64(len(data))-1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := ioutil.ReadAll(r); !errors.Is(err, ErrTooLarge) {
+				t.Errorf("expected ErrTooLarge, got %v", err)
+			}
+		})
+	}
+}
+
+func TestUnsupported(t *testing.T) {
+	if _, err := NewWriter("br", &bytes.Buffer{}); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("expected ErrUnsupported, got %v", err)
+	}
+	if _, err := NewReader("deflate", &bytes.Buffer{}, 1); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("expected ErrUnsupported, got %v", err)
+	}
+}
@@ -0,0 +1,85 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+const (
+	// TypeEntryCreated is the CloudEvents type of events emitted after an entry is integrated into the log
+	TypeEntryCreated = "dev.sigstore.rekor.entry.created"
+
+	// CloudEventsSpecVersion is the version of the CloudEvents specification the events conform to
+	CloudEventsSpecVersion = "1.0"
+
+	// ContentTypeCloudEvents is the media type of a CloudEvent in structured JSON mode
+	ContentTypeCloudEvents = "application/cloudevents+json"
+)
+
+// Entry describes a new entry in the log
+type Entry struct {
+	UUID           string `json:"uuid"`
+	LogIndex       int64  `json:"logIndex"`
+	LogID          string `json:"logID"`
+	IntegratedTime int64  `json:"integratedTime"`
+	Kind           string `json:"kind"`
+	APIVersion     string `json:"apiVersion"`
+	// digests of the artifacts the entry refers to, in algorithm:hex form
+	ArtifactHashes []string `json:"artifactHashes,omitempty"`
+	// identities (e.g. e-mail addresses) found in the signer's key or certificate
+	SignerIdentities []string `json:"signerIdentities,omitempty"`
+}
+
+// Sink receives the events describing new entries. Implementations must not block the caller on
+// slow or unavailable downstream systems for longer than the context allows.
+type Sink interface {
+	Publish(ctx context.Context, e Entry) error
+	Close(ctx context.Context) error
+}
+
+// CloudEvent is the structured JSON mode encoding of a CloudEvents v1.0 event
+type CloudEvent struct {
+	SpecVersion     string          `json:"specversion"`
+	ID              string          `json:"id"`
+	Source          string          `json:"source"`
+	Type            string          `json:"type"`
+	Subject         string          `json:"subject,omitempty"`
+	Time            string          `json:"time,omitempty"`
+	DataContentType string          `json:"datacontenttype,omitempty"`
+	Data            json.RawMessage `json:"data"`
+}
+
+// NewCloudEvent wraps an entry in a CloudEvent. The entry UUID identifies the event, so that
+// consumers can deduplicate deliveries; source is typically the URL of the log.
+func NewCloudEvent(source string, e Entry) (*CloudEvent, error) {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return nil, err
+	}
+	return &CloudEvent{
+		SpecVersion:     CloudEventsSpecVersion,
+		ID:              e.UUID,
+		Source:          source,
+		Type:            TypeEntryCreated,
+		Subject:         e.UUID,
+		Time:            time.Unix(e.IntegratedTime, 0).UTC().Format(time.RFC3339),
+		DataContentType: "application/json",
+		Data:            data,
+	}, nil
+}
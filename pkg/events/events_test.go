@@ -0,0 +1,90 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"gocloud.dev/pubsub"
+)
+
+func TestNewCloudEvent(t *testing.T) {
+	e := Entry{
+		UUID:           "362f8ecba72f4326972bc321d658ba3c9197b29bb8015967e755a97e1fa4758c",
+		LogIndex:       3,
+		IntegratedTime: 1600000000,
+		Kind:           "hashedrekord",
+		ArtifactHashes: []string{"sha256:e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"},
+	}
+	ce, err := NewCloudEvent("https://rekor.example.com", e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ce.SpecVersion != "1.0" || ce.Type != TypeEntryCreated || ce.ID != e.UUID || ce.Source != "https://rekor.example.com" {
+		t.Errorf("unexpected attributes: %+v", ce)
+	}
+	if ce.Time != "2020-09-13T12:26:40Z" {
+		t.Errorf("unexpected time %s", ce.Time)
+	}
+	var got Entry
+	if err := json.Unmarshal(ce.Data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.UUID != e.UUID || got.LogIndex != e.LogIndex || len(got.ArtifactHashes) != 1 {
+		t.Errorf("unexpected data: %+v", got)
+	}
+}
+
+func TestTopicSink(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	sink, err := OpenTopicSink(ctx, "mem://entries", "https://rekor.example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sub, err := pubsub.OpenSubscription(ctx, "mem://entries")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Shutdown(ctx)
+
+	if err := sink.Publish(ctx, Entry{UUID: "abc", LogIndex: 1}); err != nil {
+		t.Fatal(err)
+	}
+	msg, err := sub.Receive(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg.Ack()
+
+	if msg.Metadata["content-type"] != ContentTypeCloudEvents || msg.Metadata["ce_id"] != "abc" {
+		t.Errorf("unexpected metadata: %v", msg.Metadata)
+	}
+	var ce CloudEvent
+	if err := json.Unmarshal(msg.Body, &ce); err != nil {
+		t.Fatal(err)
+	}
+	if ce.ID != "abc" || ce.Type != TypeEntryCreated {
+		t.Errorf("unexpected event: %+v", ce)
+	}
+	if err := sink.Close(ctx); err != nil {
+		t.Fatal(err)
+	}
+}
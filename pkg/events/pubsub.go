@@ -0,0 +1,84 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/pkg/errors"
+	"gocloud.dev/pubsub"
+
+	// Blank imports to register the supported message buses. Drivers which live in their own modules
+	// or depend on client libraries not required by go.mod, such as those for Kafka, NATS and Cloud
+	// Pub/Sub, have to be added there before they can be registered here.
+	_ "gocloud.dev/pubsub/mempubsub"
+)
+
+// TopicSink publishes CloudEvents to a message bus topic opened by URL, e.g. mem://topic
+type TopicSink struct {
+	url    string
+	source string
+	topic  *pubsub.Topic
+}
+
+// OpenTopicSink opens the topic at url; events are published with the given CloudEvents source
+func OpenTopicSink(ctx context.Context, url, source string) (*TopicSink, error) {
+	topic, err := pubsub.OpenTopic(ctx, url)
+	if err != nil {
+		return nil, errors.Wrapf(err, "opening topic %s", url)
+	}
+	return &TopicSink{
+		url:    url,
+		source: source,
+		topic:  topic,
+	}, nil
+}
+
+// Publish sends the entry as a CloudEvent in structured mode, with the CloudEvents attributes
+// duplicated into the message metadata so that brokers can route on them
+func (t *TopicSink) Publish(ctx context.Context, e Entry) error {
+	ce, err := NewCloudEvent(t.source, e)
+	if err != nil {
+		return err
+	}
+	body, err := json.Marshal(ce)
+	if err != nil {
+		return err
+	}
+	msg := &pubsub.Message{
+		Body: body,
+		Metadata: map[string]string{
+			"content-type":   ContentTypeCloudEvents,
+			"ce_specversion": ce.SpecVersion,
+			"ce_id":          ce.ID,
+			"ce_source":      ce.Source,
+			"ce_type":        ce.Type,
+			"ce_subject":     ce.Subject,
+			"ce_time":        ce.Time,
+		},
+	}
+	if err := t.topic.Send(ctx, msg); err != nil {
+		return fmt.Errorf("publishing entry %s to %s: %w", e.UUID, t.url, err)
+	}
+	return nil
+}
+
+// Close flushes pending messages and closes the topic
+func (t *TopicSink) Close(ctx context.Context) error {
+	return t.topic.Shutdown(ctx)
+}
@@ -58,6 +58,12 @@ func (o *CreateLogEntryReader) ReadResponse(response runtime.ClientResponse, con
 			return nil, err
 		}
 		return nil, result
+	case 422:
+		result := NewCreateLogEntryUnprocessableEntity()
+		if err := result.readResponse(response, consumer, o.formats); err != nil {
+			return nil, err
+		}
+		return nil, result
 	default:
 		result := NewCreateLogEntryDefault(response.Code())
 		if err := result.readResponse(response, consumer, o.formats); err != nil {
@@ -206,6 +212,38 @@ func (o *CreateLogEntryConflict) readResponse(response runtime.ClientResponse, c
 	return nil
 }
 
+// NewCreateLogEntryUnprocessableEntity creates a CreateLogEntryUnprocessableEntity with default headers values
+func NewCreateLogEntryUnprocessableEntity() *CreateLogEntryUnprocessableEntity {
+	return &CreateLogEntryUnprocessableEntity{}
+}
+
+/* CreateLogEntryUnprocessableEntity describes a response with status code 422, with default header values.
+
+The entry was rejected by the admission policy of the server
+*/
+type CreateLogEntryUnprocessableEntity struct {
+	Payload *models.AdmissionError
+}
+
+func (o *CreateLogEntryUnprocessableEntity) Error() string {
+	return fmt.Sprintf("[POST /api/v1/log/entries][%d] createLogEntryUnprocessableEntity  %+v", 422, o.Payload)
+}
+func (o *CreateLogEntryUnprocessableEntity) GetPayload() *models.AdmissionError {
+	return o.Payload
+}
+
+func (o *CreateLogEntryUnprocessableEntity) readResponse(response runtime.ClientResponse, consumer runtime.Consumer, formats strfmt.Registry) error {
+
+	o.Payload = new(models.AdmissionError)
+
+	// response payload
+	if err := consumer.Consume(response.Body(), o.Payload); err != nil && err != io.EOF {
+		return err
+	}
+
+	return nil
+}
+
 // NewCreateLogEntryDefault creates a CreateLogEntryDefault with default headers values
 func NewCreateLogEntryDefault(code int) *CreateLogEntryDefault {
 	return &CreateLogEntryDefault{
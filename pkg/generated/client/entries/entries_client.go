@@ -22,6 +22,8 @@ package entries
 // Editing this file might prove futile when you re-run the swagger generate command
 
 import (
+	"io"
+
 	"github.com/go-openapi/runtime"
 	"github.com/go-openapi/strfmt"
 )
@@ -50,8 +52,14 @@ type ClientService interface {
 
 	GetLogEntryByUUID(params *GetLogEntryByUUIDParams, opts ...ClientOption) (*GetLogEntryByUUIDOK, error)
 
+	GetLogEntryProof(params *GetLogEntryProofParams, opts ...ClientOption) (*GetLogEntryProofOK, error)
+
 	SearchLogQuery(params *SearchLogQueryParams, opts ...ClientOption) (*SearchLogQueryOK, error)
 
+	StreamLogEntries(params *StreamLogEntriesParams, writer io.Writer, opts ...ClientOption) (*StreamLogEntriesOK, error)
+
+	ValidateLogEntry(params *ValidateLogEntryParams, opts ...ClientOption) (*ValidateLogEntryOK, error)
+
 	SetTransport(transport runtime.ClientTransport)
 }
 
@@ -171,6 +179,46 @@ func (a *Client) GetLogEntryByUUID(params *GetLogEntryByUUIDParams, opts ...Clie
 	return nil, runtime.NewAPIError("unexpected success response: content available as default response in error", unexpectedSuccess, unexpectedSuccess.Code())
 }
 
+/*
+  GetLogEntryProof gets an inclusion proof of an entry at an earlier tree size and a consistency proof from that size to the current tree
+
+  Returns the proof that the entry was included in the tree of the requested size, so that verifiers holding an old bundle can re-verify it, together with the proof that the current tree is consistent with that tree and the signed tree head of the current tree, which confirm that the log has only grown since
+
+*/
+func (a *Client) GetLogEntryProof(params *GetLogEntryProofParams, opts ...ClientOption) (*GetLogEntryProofOK, error) {
+	// TODO: Validate the params before sending
+	if params == nil {
+		params = NewGetLogEntryProofParams()
+	}
+	op := &runtime.ClientOperation{
+		ID:                 "getLogEntryProof",
+		Method:             "GET",
+		PathPattern:        "/api/v1/log/entries/{entryUUID}/proof",
+		ProducesMediaTypes: []string{"application/json;q=1", "application/yaml"},
+		ConsumesMediaTypes: []string{"application/json", "application/yaml"},
+		Schemes:            []string{"http"},
+		Params:             params,
+		Reader:             &GetLogEntryProofReader{formats: a.formats},
+		Context:            params.Context,
+		Client:             params.HTTPClient,
+	}
+	for _, opt := range opts {
+		opt(op)
+	}
+
+	result, err := a.transport.Submit(op)
+	if err != nil {
+		return nil, err
+	}
+	success, ok := result.(*GetLogEntryProofOK)
+	if ok {
+		return success, nil
+	}
+	// unexpected success response
+	unexpectedSuccess := result.(*GetLogEntryProofDefault)
+	return nil, runtime.NewAPIError("unexpected success response: content available as default response in error", unexpectedSuccess, unexpectedSuccess.Code())
+}
+
 /*
   SearchLogQuery searches transparency log for one or more log entries
 */
@@ -208,6 +256,86 @@ func (a *Client) SearchLogQuery(params *SearchLogQueryParams, opts ...ClientOpti
 	return nil, runtime.NewAPIError("unexpected success response: content available as default response in error", unexpectedSuccess, unexpectedSuccess.Code())
 }
 
+/*
+  StreamLogEntries streams a range of entries from the transparency log as newline-delimited JSON
+
+  Returns the entries from index start up to, but excluding, end as newline-delimited JSON, one LogEntry per line, so that monitors and mirrors do not have to page through the log. The next entries are only read from the log once the previous ones have been written to the client. If an error occurs after the response has started, the stream ends with an Error object rather than an entry; streams are also cut off by the write timeout of the server, so clients should resume after the last entry they received.
+
+*/
+func (a *Client) StreamLogEntries(params *StreamLogEntriesParams, writer io.Writer, opts ...ClientOption) (*StreamLogEntriesOK, error) {
+	// TODO: Validate the params before sending
+	if params == nil {
+		params = NewStreamLogEntriesParams()
+	}
+	op := &runtime.ClientOperation{
+		ID:                 "streamLogEntries",
+		Method:             "GET",
+		PathPattern:        "/api/v1/log/entries/stream",
+		ProducesMediaTypes: []string{"application/x-ndjson"},
+		ConsumesMediaTypes: []string{"application/json", "application/yaml"},
+		Schemes:            []string{"http"},
+		Params:             params,
+		Reader:             &StreamLogEntriesReader{formats: a.formats, writer: writer},
+		Context:            params.Context,
+		Client:             params.HTTPClient,
+	}
+	for _, opt := range opts {
+		opt(op)
+	}
+
+	result, err := a.transport.Submit(op)
+	if err != nil {
+		return nil, err
+	}
+	success, ok := result.(*StreamLogEntriesOK)
+	if ok {
+		return success, nil
+	}
+	// unexpected success response
+	unexpectedSuccess := result.(*StreamLogEntriesDefault)
+	return nil, runtime.NewAPIError("unexpected success response: content available as default response in error", unexpectedSuccess, unexpectedSuccess.Code())
+}
+
+/*
+  ValidateLogEntry validates an entry without adding it to the transparency log
+
+  Performs every check that creating the entry would, including canonicalization and the admission policy of the server, without adding the entry to the transparency log.
+
+*/
+func (a *Client) ValidateLogEntry(params *ValidateLogEntryParams, opts ...ClientOption) (*ValidateLogEntryOK, error) {
+	// TODO: Validate the params before sending
+	if params == nil {
+		params = NewValidateLogEntryParams()
+	}
+	op := &runtime.ClientOperation{
+		ID:                 "validateLogEntry",
+		Method:             "POST",
+		PathPattern:        "/api/v1/log/entries/validate",
+		ProducesMediaTypes: []string{"application/json;q=1", "application/yaml"},
+		ConsumesMediaTypes: []string{"application/json", "application/yaml"},
+		Schemes:            []string{"http"},
+		Params:             params,
+		Reader:             &ValidateLogEntryReader{formats: a.formats},
+		Context:            params.Context,
+		Client:             params.HTTPClient,
+	}
+	for _, opt := range opts {
+		opt(op)
+	}
+
+	result, err := a.transport.Submit(op)
+	if err != nil {
+		return nil, err
+	}
+	success, ok := result.(*ValidateLogEntryOK)
+	if ok {
+		return success, nil
+	}
+	// unexpected success response
+	unexpectedSuccess := result.(*ValidateLogEntryDefault)
+	return nil, runtime.NewAPIError("unexpected success response: content available as default response in error", unexpectedSuccess, unexpectedSuccess.Code())
+}
+
 // SetTransport changes the transport on the client
 func (a *Client) SetTransport(transport runtime.ClientTransport) {
 	a.transport = transport
@@ -30,6 +30,7 @@ import (
 	"github.com/go-openapi/runtime"
 	cr "github.com/go-openapi/runtime/client"
 	"github.com/go-openapi/strfmt"
+	"github.com/go-openapi/swag"
 
 	"github.com/sigstore/rekor/pkg/generated/models"
 )
@@ -80,6 +81,18 @@ type SearchLogQueryParams struct {
 	// Entry.
 	Entry *models.SearchLogQuery
 
+	/* PageSize.
+
+	   Maximum number of results to return; the server returns no more than its maximum page size
+	*/
+	PageSize *int64
+
+	/* PageToken.
+
+	   Token from the X-Rekor-Next-Page-Token header of the previous page, which must be requested with the same query
+	*/
+	PageToken *string
+
 	timeout    time.Duration
 	Context    context.Context
 	HTTPClient *http.Client
@@ -144,6 +157,28 @@ func (o *SearchLogQueryParams) SetEntry(entry *models.SearchLogQuery) {
 	o.Entry = entry
 }
 
+// WithPageSize adds the pageSize to the search log query params
+func (o *SearchLogQueryParams) WithPageSize(pageSize *int64) *SearchLogQueryParams {
+	o.SetPageSize(pageSize)
+	return o
+}
+
+// SetPageSize adds the pageSize to the search log query params
+func (o *SearchLogQueryParams) SetPageSize(pageSize *int64) {
+	o.PageSize = pageSize
+}
+
+// WithPageToken adds the pageToken to the search log query params
+func (o *SearchLogQueryParams) WithPageToken(pageToken *string) *SearchLogQueryParams {
+	o.SetPageToken(pageToken)
+	return o
+}
+
+// SetPageToken adds the pageToken to the search log query params
+func (o *SearchLogQueryParams) SetPageToken(pageToken *string) {
+	o.PageToken = pageToken
+}
+
 // WriteToRequest writes these params to a swagger request
 func (o *SearchLogQueryParams) WriteToRequest(r runtime.ClientRequest, reg strfmt.Registry) error {
 
@@ -157,6 +192,40 @@ func (o *SearchLogQueryParams) WriteToRequest(r runtime.ClientRequest, reg strfm
 		}
 	}
 
+	if o.PageSize != nil {
+
+		// query param pageSize
+		var qrPageSize int64
+
+		if o.PageSize != nil {
+			qrPageSize = *o.PageSize
+		}
+		qPageSize := swag.FormatInt64(qrPageSize)
+		if qPageSize != "" {
+
+			if err := r.SetQueryParam("pageSize", qPageSize); err != nil {
+				return err
+			}
+		}
+	}
+
+	if o.PageToken != nil {
+
+		// query param pageToken
+		var qrPageToken string
+
+		if o.PageToken != nil {
+			qrPageToken = *o.PageToken
+		}
+		qPageToken := qrPageToken
+		if qPageToken != "" {
+
+			if err := r.SetQueryParam("pageToken", qPageToken); err != nil {
+				return err
+			}
+		}
+	}
+
 	if len(res) > 0 {
 		return errors.CompositeValidationError(res...)
 	}
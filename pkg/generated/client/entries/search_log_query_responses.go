@@ -70,9 +70,15 @@ func NewSearchLogQueryOK() *SearchLogQueryOK {
 
 /* SearchLogQueryOK describes a response with status code 200, with default header values.
 
-Returns zero or more entries from the transparency log, according to how many were included in request query
+Returns zero or more entries from the transparency log, according to how many were included in request query;
+each page covers at most pageSize of the UUIDs, entries and log indexes of the query, in that order
 */
 type SearchLogQueryOK struct {
+
+	/* Token to pass as pageToken to fetch the next page of results; absent on the last page
+	 */
+	XRekorNextPageToken string
+
 	Payload []models.LogEntry
 }
 
@@ -85,6 +91,13 @@ func (o *SearchLogQueryOK) GetPayload() []models.LogEntry {
 
 func (o *SearchLogQueryOK) readResponse(response runtime.ClientResponse, consumer runtime.Consumer, formats strfmt.Registry) error {
 
+	// hydrates response header X-Rekor-Next-Page-Token
+	hdrXRekorNextPageToken := response.GetHeader("X-Rekor-Next-Page-Token")
+
+	if hdrXRekorNextPageToken != "" {
+		o.XRekorNextPageToken = hdrXRekorNextPageToken
+	}
+
 	// response payload
 	if err := consumer.Consume(response.Body(), &o.Payload); err != nil && err != io.EOF {
 		return err
@@ -70,9 +70,15 @@ func NewSearchIndexOK() *SearchIndexOK {
 
 /* SearchIndexOK describes a response with status code 200, with default header values.
 
-Returns zero or more entry UUIDs from the transparency log based on search query
+Returns zero or more entry UUIDs from the transparency log based on search query, in ascending order and each
+at most once, one page at a time
 */
 type SearchIndexOK struct {
+
+	/* Token to pass as pageToken to fetch the next page of results; absent on the last page
+	 */
+	XRekorNextPageToken string
+
 	Payload []string
 }
 
@@ -85,6 +91,13 @@ func (o *SearchIndexOK) GetPayload() []string {
 
 func (o *SearchIndexOK) readResponse(response runtime.ClientResponse, consumer runtime.Consumer, formats strfmt.Registry) error {
 
+	// hydrates response header X-Rekor-Next-Page-Token
+	hdrXRekorNextPageToken := response.GetHeader("X-Rekor-Next-Page-Token")
+
+	if hdrXRekorNextPageToken != "" {
+		o.XRekorNextPageToken = hdrXRekorNextPageToken
+	}
+
 	// response payload
 	if err := consumer.Consume(response.Body(), &o.Payload); err != nil && err != io.EOF {
 		return err
@@ -44,6 +44,8 @@ type ClientOption func(*runtime.ClientOperation)
 
 // ClientService is the interface for Client methods
 type ClientService interface {
+	GetLogCheckpoint(params *GetLogCheckpointParams, opts ...ClientOption) (*GetLogCheckpointOK, error)
+
 	GetLogInfo(params *GetLogInfoParams, opts ...ClientOption) (*GetLogInfoOK, error)
 
 	GetLogProof(params *GetLogProofParams, opts ...ClientOption) (*GetLogProofOK, error)
@@ -51,6 +53,45 @@ type ClientService interface {
 	SetTransport(transport runtime.ClientTransport)
 }
 
+/*
+  GetLogCheckpoint gets the signed tree head of the transparency log as a checkpoint
+
+  Returns the current signed tree head as a signed note, the text format of checkpoints used by witnesses and other transparency logs.
+*/
+func (a *Client) GetLogCheckpoint(params *GetLogCheckpointParams, opts ...ClientOption) (*GetLogCheckpointOK, error) {
+	// TODO: Validate the params before sending
+	if params == nil {
+		params = NewGetLogCheckpointParams()
+	}
+	op := &runtime.ClientOperation{
+		ID:                 "getLogCheckpoint",
+		Method:             "GET",
+		PathPattern:        "/api/v1/log/checkpoint",
+		ProducesMediaTypes: []string{"text/plain"},
+		ConsumesMediaTypes: []string{"application/json", "application/yaml"},
+		Schemes:            []string{"http"},
+		Params:             params,
+		Reader:             &GetLogCheckpointReader{formats: a.formats},
+		Context:            params.Context,
+		Client:             params.HTTPClient,
+	}
+	for _, opt := range opts {
+		opt(op)
+	}
+
+	result, err := a.transport.Submit(op)
+	if err != nil {
+		return nil, err
+	}
+	success, ok := result.(*GetLogCheckpointOK)
+	if ok {
+		return success, nil
+	}
+	// unexpected success response
+	unexpectedSuccess := result.(*GetLogCheckpointDefault)
+	return nil, runtime.NewAPIError("unexpected success response: content available as default response in error", unexpectedSuccess, unexpectedSuccess.Code())
+}
+
 /*
   GetLogInfo gets information about the current state of the transparency log
 
@@ -24,29 +24,91 @@ package models
 import (
 	"context"
 
+	"github.com/go-openapi/errors"
 	"github.com/go-openapi/strfmt"
 	"github.com/go-openapi/swag"
 )
 
-// Error error
+// Error Problem details of a failed request as described in RFC 7807, served as application/problem+json to clients accepting it
 //
 // swagger:model Error
 type Error struct {
 
-	// code
+	// HTTP status code of the response; superseded by status
 	Code int64 `json:"code,omitempty"`
 
-	// message
+	// Human-readable explanation specific to this occurrence of the problem
+	Detail string `json:"detail,omitempty"`
+
+	// error code
+	ErrorCode ErrorCode `json:"errorCode,omitempty"`
+
+	// Human-readable explanation of the problem; superseded by detail
 	Message string `json:"message,omitempty"`
+
+	// HTTP status code of the response
+	Status int64 `json:"status,omitempty"`
+
+	// Short summary of the kind of problem
+	Title string `json:"title,omitempty"`
 }
 
 // Validate validates this error
 func (m *Error) Validate(formats strfmt.Registry) error {
+	var res []error
+
+	if err := m.validateErrorCode(formats); err != nil {
+		res = append(res, err)
+	}
+
+	if len(res) > 0 {
+		return errors.CompositeValidationError(res...)
+	}
 	return nil
 }
 
-// ContextValidate validates this error based on context it is used
+func (m *Error) validateErrorCode(formats strfmt.Registry) error {
+	if swag.IsZero(m.ErrorCode) { // not required
+		return nil
+	}
+
+	if err := m.ErrorCode.Validate(formats); err != nil {
+		if ve, ok := err.(*errors.Validation); ok {
+			return ve.ValidateName("errorCode")
+		} else if ce, ok := err.(*errors.CompositeError); ok {
+			return ce.ValidateName("errorCode")
+		}
+		return err
+	}
+
+	return nil
+}
+
+// ContextValidate validate this error based on the context it is used
 func (m *Error) ContextValidate(ctx context.Context, formats strfmt.Registry) error {
+	var res []error
+
+	if err := m.contextValidateErrorCode(ctx, formats); err != nil {
+		res = append(res, err)
+	}
+
+	if len(res) > 0 {
+		return errors.CompositeValidationError(res...)
+	}
+	return nil
+}
+
+func (m *Error) contextValidateErrorCode(ctx context.Context, formats strfmt.Registry) error {
+
+	if err := m.ErrorCode.ContextValidate(ctx, formats); err != nil {
+		if ve, ok := err.(*errors.Validation); ok {
+			return ve.ValidateName("errorCode")
+		} else if ce, ok := err.(*errors.CompositeError); ok {
+			return ce.ValidateName("errorCode")
+		}
+		return err
+	}
+
 	return nil
 }
 
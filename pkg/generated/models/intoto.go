@@ -36,6 +36,7 @@ import (
 //
 // swagger:model intoto
 type Intoto struct {
+	annotationsField Annotations
 
 	// api version
 	// Required: true
@@ -56,6 +57,16 @@ func (m *Intoto) Kind() string {
 func (m *Intoto) SetKind(val string) {
 }
 
+// Annotations gets the annotations of this subtype
+func (m *Intoto) Annotations() Annotations {
+	return m.annotationsField
+}
+
+// SetAnnotations sets the annotations of this subtype
+func (m *Intoto) SetAnnotations(val Annotations) {
+	m.annotationsField = val
+}
+
 // UnmarshalJSON unmarshals this object with a polymorphic type from a JSON structure
 func (m *Intoto) UnmarshalJSON(raw []byte) error {
 	var data struct {
@@ -80,6 +91,8 @@ func (m *Intoto) UnmarshalJSON(raw []byte) error {
 	var base struct {
 		/* Just the base type fields. Used for unmashalling polymorphic types.*/
 
+		Annotations Annotations `json:"annotations,omitempty"`
+
 		Kind string `json:"kind"`
 	}
 	buf = bytes.NewBuffer(raw)
@@ -98,6 +111,8 @@ func (m *Intoto) UnmarshalJSON(raw []byte) error {
 	}
 
 	result.APIVersion = data.APIVersion
+	result.annotationsField = base.Annotations
+
 	result.Spec = data.Spec
 
 	*m = result
@@ -129,9 +144,13 @@ func (m Intoto) MarshalJSON() ([]byte, error) {
 		return nil, err
 	}
 	b2, err = json.Marshal(struct {
+		Annotations Annotations `json:"annotations,omitempty"`
+
 		Kind string `json:"kind"`
 	}{
 
+		Annotations: m.Annotations(),
+
 		Kind: m.Kind(),
 	})
 	if err != nil {
@@ -145,6 +164,10 @@ func (m Intoto) MarshalJSON() ([]byte, error) {
 func (m *Intoto) Validate(formats strfmt.Registry) error {
 	var res []error
 
+	if err := m.validateAnnotations(formats); err != nil {
+		res = append(res, err)
+	}
+
 	if err := m.validateAPIVersion(formats); err != nil {
 		res = append(res, err)
 	}
@@ -159,6 +182,25 @@ func (m *Intoto) Validate(formats strfmt.Registry) error {
 	return nil
 }
 
+func (m *Intoto) validateAnnotations(formats strfmt.Registry) error {
+	if swag.IsZero(m.Annotations()) { // not required
+		return nil
+	}
+
+	if m.Annotations() != nil {
+		if err := m.Annotations().Validate(formats); err != nil {
+			if ve, ok := err.(*errors.Validation); ok {
+				return ve.ValidateName("annotations")
+			} else if ce, ok := err.(*errors.CompositeError); ok {
+				return ce.ValidateName("annotations")
+			}
+			return err
+		}
+	}
+
+	return nil
+}
+
 func (m *Intoto) validateAPIVersion(formats strfmt.Registry) error {
 
 	if err := validate.Required("apiVersion", "body", m.APIVersion); err != nil {
@@ -185,12 +227,30 @@ func (m *Intoto) validateSpec(formats strfmt.Registry) error {
 func (m *Intoto) ContextValidate(ctx context.Context, formats strfmt.Registry) error {
 	var res []error
 
+	if err := m.contextValidateAnnotations(ctx, formats); err != nil {
+		res = append(res, err)
+	}
+
 	if len(res) > 0 {
 		return errors.CompositeValidationError(res...)
 	}
 	return nil
 }
 
+func (m *Intoto) contextValidateAnnotations(ctx context.Context, formats strfmt.Registry) error {
+
+	if err := m.Annotations().ContextValidate(ctx, formats); err != nil {
+		if ve, ok := err.(*errors.Validation); ok {
+			return ve.ValidateName("annotations")
+		} else if ce, ok := err.(*errors.CompositeError); ok {
+			return ce.ValidateName("annotations")
+		}
+		return err
+	}
+
+	return nil
+}
+
 // MarshalBinary interface implementation
 func (m *Intoto) MarshalBinary() ([]byte, error) {
 	if m == nil {
@@ -46,11 +46,17 @@ type ProposedEntry interface {
 	Kind() string
 	SetKind(string)
 
+	// annotations
+	Annotations() Annotations
+	SetAnnotations(Annotations)
+
 	// AdditionalProperties in base type shoud be handled just like regular properties
 	// At this moment, the base type property is pushed down to the subtype
 }
 
 type proposedEntry struct {
+	annotationsField Annotations
+
 	kindField string
 }
 
@@ -63,6 +69,16 @@ func (m *proposedEntry) Kind() string {
 func (m *proposedEntry) SetKind(val string) {
 }
 
+// Annotations gets the annotations of this polymorphic type
+func (m *proposedEntry) Annotations() Annotations {
+	return m.annotationsField
+}
+
+// SetAnnotations sets the annotations of this polymorphic type
+func (m *proposedEntry) SetAnnotations(val Annotations) {
+	m.annotationsField = val
+}
+
 // UnmarshalProposedEntrySlice unmarshals polymorphic slices of ProposedEntry
 func UnmarshalProposedEntrySlice(reader io.Reader, consumer runtime.Consumer) ([]ProposedEntry, error) {
 	var elements []json.RawMessage
@@ -121,6 +137,12 @@ func unmarshalProposedEntry(data []byte, consumer runtime.Consumer) (ProposedEnt
 			return nil, err
 		}
 		return &result, nil
+	case "checksums":
+		var result Checksums
+		if err := consumer.Consume(buf2, &result); err != nil {
+			return nil, err
+		}
+		return &result, nil
 	case "hashedrekord":
 		var result Hashedrekord
 		if err := consumer.Consume(buf2, &result); err != nil {
@@ -145,6 +167,12 @@ func unmarshalProposedEntry(data []byte, consumer runtime.Consumer) (ProposedEnt
 			return nil, err
 		}
 		return &result, nil
+	case "oci":
+		var result OCI
+		if err := consumer.Consume(buf2, &result); err != nil {
+			return nil, err
+		}
+		return &result, nil
 	case "rekord":
 		var result Rekord
 		if err := consumer.Consume(buf2, &result); err != nil {
@@ -163,6 +191,12 @@ func unmarshalProposedEntry(data []byte, consumer runtime.Consumer) (ProposedEnt
 			return nil, err
 		}
 		return &result, nil
+	case "sbom":
+		var result Sbom
+		if err := consumer.Consume(buf2, &result); err != nil {
+			return nil, err
+		}
+		return &result, nil
 	case "tuf":
 		var result TUF
 		if err := consumer.Consume(buf2, &result); err != nil {
@@ -36,22 +36,49 @@ import (
 // swagger:model SearchIndex
 type SearchIndex struct {
 
+	// annotations
+	Annotations Annotations `json:"annotations,omitempty"`
+
+	// URL an artifact was downloaded from when its entry was uploaded; the scheme and host are matched case insensitively and default ports are ignored
+	ArtifactURL string `json:"artifactUrl,omitempty"`
+
+	// ID of a git commit which artifacts were built from, as recorded by the git.commit annotation or SLSA provenance
+	// Pattern: ^[0-9a-fA-F]{40}$|^[0-9a-fA-F]{64}$
+	Commit string `json:"commit,omitempty"`
+
 	// email
 	// Format: email
 	Email strfmt.Email `json:"email,omitempty"`
 
 	// hash
-	// Pattern: ^(sha256:)?[0-9a-fA-F]{64}$|^(sha1:)?[0-9a-fA-F]{40}$
+	// Pattern: ^(sha512:)?[0-9a-fA-F]{128}$|^(sha384:)?[0-9a-fA-F]{96}$|^(sha256:)?[0-9a-fA-F]{64}$|^(sha1:)?[0-9a-fA-F]{40}$
 	Hash string `json:"hash,omitempty"`
 
+	// Unix time in seconds from which entries were integrated into the log, inclusive; combined with other criteria, only their matches integrated in the time range are returned
+	IntegratedSince int64 `json:"integratedSince,omitempty"`
+
+	// Unix time in seconds until which entries were integrated into the log, inclusive; combined with other criteria, only their matches integrated in the time range are returned
+	IntegratedUntil int64 `json:"integratedUntil,omitempty"`
+
 	// public key
 	PublicKey *SearchIndexPublicKey `json:"publicKey,omitempty"`
+
+	// URL of a source repository which artifacts were built from, as recorded by the git.repository annotation or SLSA provenance
+	Repository string `json:"repository,omitempty"`
 }
 
 // Validate validates this search index
 func (m *SearchIndex) Validate(formats strfmt.Registry) error {
 	var res []error
 
+	if err := m.validateAnnotations(formats); err != nil {
+		res = append(res, err)
+	}
+
+	if err := m.validateCommit(formats); err != nil {
+		res = append(res, err)
+	}
+
 	if err := m.validateEmail(formats); err != nil {
 		res = append(res, err)
 	}
@@ -70,6 +97,37 @@ func (m *SearchIndex) Validate(formats strfmt.Registry) error {
 	return nil
 }
 
+func (m *SearchIndex) validateAnnotations(formats strfmt.Registry) error {
+	if swag.IsZero(m.Annotations) { // not required
+		return nil
+	}
+
+	if m.Annotations != nil {
+		if err := m.Annotations.Validate(formats); err != nil {
+			if ve, ok := err.(*errors.Validation); ok {
+				return ve.ValidateName("annotations")
+			} else if ce, ok := err.(*errors.CompositeError); ok {
+				return ce.ValidateName("annotations")
+			}
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m *SearchIndex) validateCommit(formats strfmt.Registry) error {
+	if swag.IsZero(m.Commit) { // not required
+		return nil
+	}
+
+	if err := validate.Pattern("commit", "body", m.Commit, `^[0-9a-fA-F]{40}$|^[0-9a-fA-F]{64}$`); err != nil {
+		return err
+	}
+
+	return nil
+}
+
 func (m *SearchIndex) validateEmail(formats strfmt.Registry) error {
 	if swag.IsZero(m.Email) { // not required
 		return nil
@@ -87,7 +145,7 @@ func (m *SearchIndex) validateHash(formats strfmt.Registry) error {
 		return nil
 	}
 
-	if err := validate.Pattern("hash", "body", m.Hash, `^(sha256:)?[0-9a-fA-F]{64}$|^(sha1:)?[0-9a-fA-F]{40}$`); err != nil {
+	if err := validate.Pattern("hash", "body", m.Hash, `^(sha512:)?[0-9a-fA-F]{128}$|^(sha384:)?[0-9a-fA-F]{96}$|^(sha256:)?[0-9a-fA-F]{64}$|^(sha1:)?[0-9a-fA-F]{40}$`); err != nil {
 		return err
 	}
 
@@ -117,6 +175,10 @@ func (m *SearchIndex) validatePublicKey(formats strfmt.Registry) error {
 func (m *SearchIndex) ContextValidate(ctx context.Context, formats strfmt.Registry) error {
 	var res []error
 
+	if err := m.contextValidateAnnotations(ctx, formats); err != nil {
+		res = append(res, err)
+	}
+
 	if err := m.contextValidatePublicKey(ctx, formats); err != nil {
 		res = append(res, err)
 	}
@@ -127,6 +189,20 @@ func (m *SearchIndex) ContextValidate(ctx context.Context, formats strfmt.Regist
 	return nil
 }
 
+func (m *SearchIndex) contextValidateAnnotations(ctx context.Context, formats strfmt.Registry) error {
+
+	if err := m.Annotations.ContextValidate(ctx, formats); err != nil {
+		if ve, ok := err.(*errors.Validation); ok {
+			return ve.ValidateName("annotations")
+		} else if ce, ok := err.(*errors.CompositeError); ok {
+			return ce.ValidateName("annotations")
+		}
+		return err
+	}
+
+	return nil
+}
+
 func (m *SearchIndex) contextValidatePublicKey(ctx context.Context, formats strfmt.Registry) error {
 
 	if m.PublicKey != nil {
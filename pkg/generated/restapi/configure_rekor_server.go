@@ -30,7 +30,6 @@ import (
 	"github.com/go-openapi/errors"
 	"github.com/go-openapi/runtime"
 	"github.com/mitchellh/mapstructure"
-	"github.com/rs/cors"
 	"github.com/spf13/viper"
 
 	pkgapi "github.com/sigstore/rekor/pkg/api"
@@ -75,18 +74,25 @@ func configureAPI(api *operations.RekorServerAPI) http.Handler {
 	api.YamlConsumer = client.YamlConsumer()
 	api.YamlProducer = client.YamlProducer()
 
+	api.TxtProducer = runtime.TextProducer()
 	api.ApplicationXPemFileProducer = runtime.TextProducer()
 	api.ApplicationPemCertificateChainProducer = runtime.TextProducer()
 	api.ApplicationTimestampQueryConsumer = runtime.ByteStreamConsumer()
 	api.ApplicationTimestampReplyProducer = runtime.ByteStreamProducer()
+	// each line of a stream of entries is a JSON document, and so are errors returned before it starts
+	api.ApplicationXNdjsonProducer = runtime.JSONProducer()
 
 	api.EntriesCreateLogEntryHandler = entries.CreateLogEntryHandlerFunc(pkgapi.CreateLogEntryHandler)
 	api.EntriesGetLogEntryByIndexHandler = entries.GetLogEntryByIndexHandlerFunc(pkgapi.GetLogEntryByIndexHandler)
 	api.EntriesGetLogEntryByUUIDHandler = entries.GetLogEntryByUUIDHandlerFunc(pkgapi.GetLogEntryByUUIDHandler)
+	api.EntriesGetLogEntryProofHandler = entries.GetLogEntryProofHandlerFunc(pkgapi.GetLogEntryProofHandler)
 	api.EntriesSearchLogQueryHandler = entries.SearchLogQueryHandlerFunc(pkgapi.SearchLogQueryHandler)
+	api.EntriesStreamLogEntriesHandler = entries.StreamLogEntriesHandlerFunc(pkgapi.StreamLogEntriesHandler)
+	api.EntriesValidateLogEntryHandler = entries.ValidateLogEntryHandlerFunc(pkgapi.ValidateLogEntryHandler)
 
 	api.PubkeyGetPublicKeyHandler = pubkey.GetPublicKeyHandlerFunc(pkgapi.GetPublicKeyHandler)
 
+	api.TlogGetLogCheckpointHandler = tlog.GetLogCheckpointHandlerFunc(pkgapi.GetLogCheckpointHandler)
 	api.TlogGetLogInfoHandler = tlog.GetLogInfoHandlerFunc(pkgapi.GetLogInfoHandler)
 	api.TlogGetLogProofHandler = tlog.GetLogProofHandlerFunc(pkgapi.GetLogProofHandler)
 
@@ -98,7 +104,11 @@ func configureAPI(api *operations.RekorServerAPI) http.Handler {
 		api.IndexSearchIndexHandler = index.SearchIndexHandlerFunc(pkgapi.SearchIndexNotImplementedHandler)
 	}
 
-	api.TimestampGetTimestampResponseHandler = timestamp.GetTimestampResponseHandlerFunc(pkgapi.TimestampResponseHandler)
+	if viper.GetBool("enable_timestamp_api") {
+		api.TimestampGetTimestampResponseHandler = timestamp.GetTimestampResponseHandlerFunc(pkgapi.TimestampResponseHandler)
+	} else {
+		api.TimestampGetTimestampResponseHandler = timestamp.GetTimestampResponseHandlerFunc(pkgapi.TimestampResponseNotImplementedHandler)
+	}
 	api.TimestampGetTimestampCertChainHandler = timestamp.GetTimestampCertChainHandlerFunc(pkgapi.GetTimestampCertChainHandler)
 
 	api.RegisterFormat("signedCheckpoint", &util.SignedNote{}, util.SignedCheckpointValidator)
@@ -107,13 +117,33 @@ func configureAPI(api *operations.RekorServerAPI) http.Handler {
 
 	api.ServerShutdown = func() {}
 
+	// the specs of proposed entries are checked against the schemas of their kinds before they are parsed
+	entrySchemas, err := pkgapi.NewEntrySchemas(FlatSwaggerJSON)
+	if err != nil {
+		log.Logger.Panic(err)
+	}
+	api.AddMiddlewareFor("POST", "/api/v1/log/entries", entrySchemas.ValidateProposedEntry)
+	api.AddMiddlewareFor("POST", "/api/v1/log/entries/validate", entrySchemas.ValidateProposedEntry)
+	api.AddMiddlewareFor("POST", "/api/v1/log/entries/retrieve", entrySchemas.ValidateSearchLogQuery)
+
+	// writes, and validating them, may require authentication
+	api.AddMiddlewareFor("POST", "/api/v1/log/entries", pkgapi.AuthenticateWrites)
+	api.AddMiddlewareFor("POST", "/api/v1/log/entries/validate", pkgapi.AuthenticateWrites)
+	// every attempt to add an entry is audited, including those rejected by the middleware above
+	api.AddMiddlewareFor("POST", "/api/v1/log/entries", pkgapi.AuditWrites)
+
 	// not cacheable
 	api.AddMiddlewareFor("GET", "/api/v1/log", middleware.NoCache)
-	api.AddMiddlewareFor("GET", "/api/v1/log/proof", middleware.NoCache)
-	api.AddMiddlewareFor("GET", "/api/v1/log/entries", middleware.NoCache)
-	api.AddMiddlewareFor("GET", "/api/v1/log/entries/{entryUUID}", middleware.NoCache)
+	api.AddMiddlewareFor("GET", "/api/v1/log/checkpoint", middleware.NoCache)
+	api.AddMiddlewareFor("GET", "/api/v1/log/entries/{entryUUID}/proof", middleware.NoCache)
+	api.AddMiddlewareFor("GET", "/api/v1/log/entries/stream", middleware.NoCache)
 	api.AddMiddlewareFor("GET", "/api/v1/timestamp", middleware.NoCache)
 
+	// cacheability of successful responses is decided by the handler, which sets an ETag
+	api.AddMiddlewareFor("GET", "/api/v1/log/proof", noStoreErrors)
+	api.AddMiddlewareFor("GET", "/api/v1/log/entries", noStoreErrors)
+	api.AddMiddlewareFor("GET", "/api/v1/log/entries/{entryUUID}", noStoreErrors)
+
 	// cache forever
 	api.AddMiddlewareFor("GET", "/api/v1/log/publicKey", cacheForever)
 	api.AddMiddlewareFor("GET", "/api/v1/log/timestamp/certchain", cacheForever)
@@ -156,9 +186,15 @@ func setupGlobalMiddleware(handler http.Handler) http.Handler {
 	returnHandler = middleware.Recoverer(returnHandler)
 	returnHandler = middleware.Heartbeat("/ping")(returnHandler)
 	returnHandler = serveStaticContent(returnHandler)
+	returnHandler = pkgapi.ServeAttachments(returnHandler)
+	returnHandler = pkgapi.ServeKeySet(returnHandler)
+	returnHandler = pkgapi.ServeShards(returnHandler)
+	returnHandler = pkgapi.ServeWebUI(returnHandler)
+	returnHandler = pkgapi.RouteLogs(returnHandler)
+	returnHandler = pkgapi.Compress(returnHandler)
+	returnHandler = pkgapi.ServeProblems(returnHandler)
 
-	handleCORS := cors.Default().Handler
-	returnHandler = handleCORS(returnHandler)
+	returnHandler = pkgapi.CORS(returnHandler)
 
 	returnHandler = wrapMetrics(returnHandler)
 
@@ -202,6 +238,19 @@ func cacheForever(handler http.Handler) http.Handler {
 	})
 }
 
+// noStoreErrors prevents error responses from being cached, e.g. a 404 for an entry which is yet to be added
+func noStoreErrors(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ww := negroni.NewResponseWriter(w)
+		ww.Before(func(w negroni.ResponseWriter) {
+			if (w.Status() < 200 || w.Status() > 299) && w.Status() != http.StatusNotModified {
+				w.Header().Set("Cache-Control", "no-store")
+			}
+		})
+		handler.ServeHTTP(ww, r)
+	})
+}
+
 func logAndServeError(w http.ResponseWriter, r *http.Request, err error) {
 	if apiErr, ok := err.(errors.Error); ok && apiErr.Code() == http.StatusNotFound {
 		log.RequestIDLogger(r).Warn(err)
@@ -212,7 +261,7 @@ func logAndServeError(w http.ResponseWriter, r *http.Request, err error) {
 	if err := mapstructure.Decode(r, &requestFields); err == nil {
 		log.RequestIDLogger(r).Debug(requestFields)
 	}
-	errors.ServeError(w, r, err)
+	pkgapi.ServeError(w, r, err)
 }
 
 //go:embed rekorHomePage.html
@@ -68,11 +68,24 @@ func init() {
             "schema": {
               "$ref": "#/definitions/SearchIndex"
             }
+          },
+          {
+            "minimum": 1,
+            "type": "integer",
+            "description": "Maximum number of results to return; the server returns no more than its maximum page size",
+            "name": "pageSize",
+            "in": "query"
+          },
+          {
+            "type": "string",
+            "description": "Token from the X-Rekor-Next-Page-Token header of the previous page, which must be requested with the same query",
+            "name": "pageToken",
+            "in": "query"
           }
         ],
         "responses": {
           "200": {
-            "description": "Returns zero or more entry UUIDs from the transparency log based on search query",
+            "description": "Returns zero or more entry UUIDs from the transparency log based on search query, in ascending order and each at most once, one page at a time\n",
             "schema": {
               "type": "array",
               "items": {
@@ -80,6 +93,12 @@ func init() {
                 "type": "string",
                 "pattern": "^[0-9a-fA-F]{64}$"
               }
+            },
+            "headers": {
+              "X-Rekor-Next-Page-Token": {
+                "type": "string",
+                "description": "Token to pass as pageToken to fetch the next page of results; absent on the last page"
+              }
             }
           },
           "400": {
@@ -112,6 +131,30 @@ func init() {
         }
       }
     },
+    "/api/v1/log/checkpoint": {
+      "get": {
+        "description": "Returns the current signed tree head as a signed note, the text format of checkpoints used by witnesses and other transparency logs.",
+        "produces": [
+          "text/plain"
+        ],
+        "tags": [
+          "tlog"
+        ],
+        "summary": "Get the signed tree head of the transparency log as a checkpoint",
+        "operationId": "getLogCheckpoint",
+        "responses": {
+          "200": {
+            "description": "The signed tree head as a signed note",
+            "schema": {
+              "type": "string"
+            }
+          },
+          "default": {
+            "$ref": "#/responses/InternalServerError"
+          }
+        }
+      }
+    },
     "/api/v1/log/entries": {
       "get": {
         "tags": [
@@ -184,6 +227,9 @@ func init() {
           "409": {
             "$ref": "#/responses/Conflict"
           },
+          "422": {
+            "$ref": "#/responses/UnprocessableEntity"
+          },
           "default": {
             "$ref": "#/responses/InternalServerError"
           }
@@ -205,21 +251,132 @@ func init() {
             "schema": {
               "$ref": "#/definitions/SearchLogQuery"
             }
+          },
+          {
+            "minimum": 1,
+            "type": "integer",
+            "description": "Maximum number of results to return; the server returns no more than its maximum page size",
+            "name": "pageSize",
+            "in": "query"
+          },
+          {
+            "type": "string",
+            "description": "Token from the X-Rekor-Next-Page-Token header of the previous page, which must be requested with the same query",
+            "name": "pageToken",
+            "in": "query"
           }
         ],
         "responses": {
           "200": {
-            "description": "Returns zero or more entries from the transparency log, according to how many were included in request query",
+            "description": "Returns zero or more entries from the transparency log, according to how many were included in request query; each page covers at most pageSize of the UUIDs, entries and log indexes of the query, in that order\n",
             "schema": {
               "type": "array",
               "items": {
                 "$ref": "#/definitions/LogEntry"
               }
+            },
+            "headers": {
+              "X-Rekor-Next-Page-Token": {
+                "type": "string",
+                "description": "Token to pass as pageToken to fetch the next page of results; absent on the last page"
+              }
+            }
+          },
+          "400": {
+            "$ref": "#/responses/BadContent"
+          },
+          "default": {
+            "$ref": "#/responses/InternalServerError"
+          }
+        }
+      }
+    },
+    "/api/v1/log/entries/stream": {
+      "get": {
+        "description": "Returns the entries from index start up to, but excluding, end as newline-delimited JSON, one LogEntry per line, so that monitors and mirrors do not have to page through the log. The next entries are only read from the log once the previous ones have been written to the client. If an error occurs after the response has started, the stream ends with an Error object rather than an entry; streams are also cut off by the write timeout of the server, so clients should resume after the last entry they received.\n",
+        "produces": [
+          "application/x-ndjson"
+        ],
+        "tags": [
+          "entries"
+        ],
+        "summary": "Streams a range of entries from the transparency log as newline-delimited JSON",
+        "operationId": "streamLogEntries",
+        "parameters": [
+          {
+            "minimum": 0,
+            "type": "integer",
+            "default": 0,
+            "description": "The index of the first entry to return",
+            "name": "start",
+            "in": "query"
+          },
+          {
+            "minimum": 0,
+            "type": "integer",
+            "description": "The index after the last entry to return; defaults to the size of the log when the request is received",
+            "name": "end",
+            "in": "query"
+          },
+          {
+            "type": "boolean",
+            "default": false,
+            "description": "Whether to include an inclusion proof against the tree of the shard holding each entry",
+            "name": "proofs",
+            "in": "query"
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "The entries of the range, one LogEntry per line",
+            "schema": {
+              "type": "string",
+              "format": "binary"
+            }
+          },
+          "400": {
+            "$ref": "#/responses/BadContent"
+          },
+          "default": {
+            "$ref": "#/responses/InternalServerError"
+          }
+        }
+      }
+    },
+    "/api/v1/log/entries/validate": {
+      "post": {
+        "description": "Performs every check that creating the entry would, including canonicalization and the admission policy of the server, without adding the entry to the transparency log.\n",
+        "tags": [
+          "entries"
+        ],
+        "summary": "Validates an entry without adding it to the transparency log",
+        "operationId": "validateLogEntry",
+        "parameters": [
+          {
+            "name": "proposedEntry",
+            "in": "body",
+            "required": true,
+            "schema": {
+              "$ref": "#/definitions/ProposedEntry"
+            }
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "Returns the canonicalized entry as it would be added to the transparency log, keyed by the UUID it would have",
+            "schema": {
+              "$ref": "#/definitions/LogEntry"
             }
           },
           "400": {
             "$ref": "#/responses/BadContent"
           },
+          "409": {
+            "$ref": "#/responses/Conflict"
+          },
+          "422": {
+            "$ref": "#/responses/UnprocessableEntity"
+          },
           "default": {
             "$ref": "#/responses/InternalServerError"
           }
@@ -260,6 +417,51 @@ func init() {
         }
       }
     },
+    "/api/v1/log/entries/{entryUUID}/proof": {
+      "get": {
+        "description": "Returns the proof that the entry was included in the tree of the requested size, so that verifiers holding an old bundle can re-verify it, together with the proof that the current tree is consistent with that tree and the signed tree head of the current tree, which confirm that the log has only grown since\n",
+        "tags": [
+          "entries"
+        ],
+        "summary": "Get an inclusion proof of an entry at an earlier tree size, and a consistency proof from that size to the current tree",
+        "operationId": "getLogEntryProof",
+        "parameters": [
+          {
+            "pattern": "^[0-9a-fA-F]{64}$",
+            "type": "string",
+            "description": "the UUID of the entry for which the proofs should be returned",
+            "name": "entryUUID",
+            "in": "path",
+            "required": true
+          },
+          {
+            "minimum": 1,
+            "type": "integer",
+            "description": "The size of the tree that the inclusion proof is computed for, which the consistency proof starts from",
+            "name": "treeSize",
+            "in": "query",
+            "required": true
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "The inclusion proof at the requested tree size and the consistency proof to the current tree",
+            "schema": {
+              "$ref": "#/definitions/HistoricalProof"
+            }
+          },
+          "400": {
+            "$ref": "#/responses/BadContent"
+          },
+          "404": {
+            "$ref": "#/responses/NotFound"
+          },
+          "default": {
+            "$ref": "#/responses/InternalServerError"
+          }
+        }
+      }
+    },
     "/api/v1/log/proof": {
       "get": {
         "description": "Returns a list of hashes for specified tree sizes that can be used to confirm the consistency of the transparency log",
@@ -434,6 +636,44 @@ func init() {
     }
   },
   "definitions": {
+    "AdmissionError": {
+      "type": "object",
+      "properties": {
+        "code": {
+          "type": "integer"
+        },
+        "detail": {
+          "type": "string"
+        },
+        "errorCode": {
+          "$ref": "#/definitions/ErrorCode"
+        },
+        "message": {
+          "type": "string"
+        },
+        "status": {
+          "type": "integer"
+        },
+        "title": {
+          "type": "string"
+        },
+        "violations": {
+          "type": "array",
+          "items": {
+            "$ref": "#/definitions/PolicyViolation"
+          }
+        }
+      }
+    },
+    "Annotations": {
+      "description": "Key/value pairs describing the artifact, such as the build or commit it came from; they are covered by the entry's leaf hash",
+      "type": "object",
+      "maxProperties": 16,
+      "additionalProperties": {
+        "type": "string",
+        "maxLength": 256
+      }
+    },
     "ConsistencyProof": {
       "type": "object",
       "required": [
@@ -457,16 +697,69 @@ func init() {
       }
     },
     "Error": {
+      "description": "Problem details of a failed request as described in RFC 7807, served as application/problem+json to clients accepting it",
       "type": "object",
       "properties": {
         "code": {
+          "description": "HTTP status code of the response; superseded by status",
           "type": "integer"
         },
+        "detail": {
+          "description": "Human-readable explanation specific to this occurrence of the problem",
+          "type": "string"
+        },
+        "errorCode": {
+          "$ref": "#/definitions/ErrorCode"
+        },
         "message": {
+          "description": "Human-readable explanation of the problem; superseded by detail",
+          "type": "string"
+        },
+        "status": {
+          "description": "HTTP status code of the response",
+          "type": "integer"
+        },
+        "title": {
+          "description": "Short summary of the kind of problem",
           "type": "string"
         }
       }
     },
+    "ErrorCode": {
+      "description": "Machine-readable code identifying the kind of problem",
+      "type": "string",
+      "enum": [
+        "validation_failed",
+        "duplicate_entry",
+        "policy_rejected",
+        "backend_unavailable",
+        "not_found",
+        "unauthorized",
+        "rate_limited",
+        "not_implemented",
+        "internal_error"
+      ]
+    },
+    "HistoricalProof": {
+      "description": "Proof that an entry was included in an earlier tree of the log and that the current tree of the log is consistent with it",
+      "type": "object",
+      "required": [
+        "inclusionProof",
+        "consistencyProof",
+        "logInfo"
+      ],
+      "properties": {
+        "consistencyProof": {
+          "$ref": "#/definitions/ConsistencyProof"
+        },
+        "inclusionProof": {
+          "$ref": "#/definitions/InclusionProof"
+        },
+        "logInfo": {
+          "$ref": "#/definitions/LogInfo"
+        }
+      }
+    },
     "InclusionProof": {
       "type": "object",
       "required": [
@@ -577,12 +870,27 @@ func init() {
         }
       }
     },
+    "PolicyViolation": {
+      "type": "object",
+      "properties": {
+        "message": {
+          "type": "string"
+        },
+        "policy": {
+          "description": "Name of the admission policy which rejected the entry",
+          "type": "string"
+        }
+      }
+    },
     "ProposedEntry": {
       "type": "object",
       "required": [
         "kind"
       ],
       "properties": {
+        "annotations": {
+          "$ref": "#/definitions/Annotations"
+        },
         "kind": {
           "type": "string"
         }
@@ -615,13 +923,35 @@ func init() {
     "SearchIndex": {
       "type": "object",
       "properties": {
+        "annotations": {
+          "$ref": "#/definitions/Annotations"
+        },
+        "artifactUrl": {
+          "description": "URL an artifact was downloaded from when its entry was uploaded; the scheme and host are matched case insensitively and default ports are ignored",
+          "type": "string"
+        },
+        "commit": {
+          "description": "ID of a git commit which artifacts were built from, as recorded by the git.commit annotation or SLSA provenance",
+          "type": "string",
+          "pattern": "^[0-9a-fA-F]{40}$|^[0-9a-fA-F]{64}$"
+        },
         "email": {
           "type": "string",
           "format": "email"
         },
         "hash": {
           "type": "string",
-          "pattern": "^(sha256:)?[0-9a-fA-F]{64}$|^(sha1:)?[0-9a-fA-F]{40}$"
+          "pattern": "^(sha512:)?[0-9a-fA-F]{128}$|^(sha384:)?[0-9a-fA-F]{96}$|^(sha256:)?[0-9a-fA-F]{64}$|^(sha1:)?[0-9a-fA-F]{40}$"
+        },
+        "integratedSince": {
+          "description": "Unix time in seconds from which entries were integrated into the log, inclusive; combined with other criteria, only their matches integrated in the time range are returned",
+          "type": "integer",
+          "format": "int64"
+        },
+        "integratedUntil": {
+          "description": "Unix time in seconds until which entries were integrated into the log, inclusive; combined with other criteria, only their matches integrated in the time range are returned",
+          "type": "integer",
+          "format": "int64"
         },
         "publicKey": {
           "type": "object",
@@ -648,6 +978,10 @@ func init() {
               "format": "uri"
             }
           }
+        },
+        "repository": {
+          "description": "URL of a source repository which artifacts were built from, as recorded by the git.repository annotation or SLSA provenance",
+          "type": "string"
         }
       }
     },
@@ -704,6 +1038,32 @@ func init() {
         }
       ]
     },
+    "checksums": {
+      "description": "Artifact listed in a signed checksums file",
+      "type": "object",
+      "allOf": [
+        {
+          "$ref": "#/definitions/ProposedEntry"
+        },
+        {
+          "required": [
+            "apiVersion",
+            "spec"
+          ],
+          "properties": {
+            "apiVersion": {
+              "type": "string",
+              "pattern": "^(0|[1-9]\\d*)\\.(0|[1-9]\\d*)\\.(0|[1-9]\\d*)(?:-((?:0|[1-9]\\d*|\\d*[a-zA-Z-][0-9a-zA-Z-]*)(?:\\.(?:0|[1-9]\\d*|\\d*[a-zA-Z-][0-9a-zA-Z-]*))*))?(?:\\+([0-9a-zA-Z-]+(?:\\.[0-9a-zA-Z-]+)*))?$"
+            },
+            "spec": {
+              "type": "object",
+              "$ref": "pkg/types/checksums/checksums_schema.json"
+            }
+          },
+          "additionalProperties": false
+        }
+      ]
+    },
     "hashedrekord": {
       "description": "Hashed Rekord object",
       "type": "object",
@@ -807,8 +1167,8 @@ func init() {
         }
       ]
     },
-    "rekord": {
-      "description": "Rekord object",
+    "oci": {
+      "description": "OCI container image",
       "type": "object",
       "allOf": [
         {
@@ -826,15 +1186,15 @@ func init() {
             },
             "spec": {
               "type": "object",
-              "$ref": "pkg/types/rekord/rekord_schema.json"
+              "$ref": "pkg/types/oci/oci_schema.json"
             }
           },
           "additionalProperties": false
         }
       ]
     },
-    "rfc3161": {
-      "description": "RFC3161 Timestamp",
+    "rekord": {
+      "description": "Rekord object",
       "type": "object",
       "allOf": [
         {
@@ -852,15 +1212,15 @@ func init() {
             },
             "spec": {
               "type": "object",
-              "$ref": "pkg/types/rfc3161/rfc3161_schema.json"
+              "$ref": "pkg/types/rekord/rekord_schema.json"
             }
           },
           "additionalProperties": false
         }
       ]
     },
-    "rpm": {
-      "description": "RPM package",
+    "rfc3161": {
+      "description": "RFC3161 Timestamp",
       "type": "object",
       "allOf": [
         {
@@ -878,7 +1238,59 @@ func init() {
             },
             "spec": {
               "type": "object",
-              "$ref": "pkg/types/rpm/rpm_schema.json"
+              "$ref": "pkg/types/rfc3161/rfc3161_schema.json"
+            }
+          },
+          "additionalProperties": false
+        }
+      ]
+    },
+    "rpm": {
+      "description": "RPM package",
+      "type": "object",
+      "allOf": [
+        {
+          "$ref": "#/definitions/ProposedEntry"
+        },
+        {
+          "required": [
+            "apiVersion",
+            "spec"
+          ],
+          "properties": {
+            "apiVersion": {
+              "type": "string",
+              "pattern": "^(0|[1-9]\\d*)\\.(0|[1-9]\\d*)\\.(0|[1-9]\\d*)(?:-((?:0|[1-9]\\d*|\\d*[a-zA-Z-][0-9a-zA-Z-]*)(?:\\.(?:0|[1-9]\\d*|\\d*[a-zA-Z-][0-9a-zA-Z-]*))*))?(?:\\+([0-9a-zA-Z-]+(?:\\.[0-9a-zA-Z-]+)*))?$"
+            },
+            "spec": {
+              "type": "object",
+              "$ref": "pkg/types/rpm/rpm_schema.json"
+            }
+          },
+          "additionalProperties": false
+        }
+      ]
+    },
+    "sbom": {
+      "description": "Software bill of materials",
+      "type": "object",
+      "allOf": [
+        {
+          "$ref": "#/definitions/ProposedEntry"
+        },
+        {
+          "required": [
+            "apiVersion",
+            "spec"
+          ],
+          "properties": {
+            "apiVersion": {
+              "type": "string",
+              "pattern": "^(0|[1-9]\\d*)\\.(0|[1-9]\\d*)\\.(0|[1-9]\\d*)(?:-((?:0|[1-9]\\d*|\\d*[a-zA-Z-][0-9a-zA-Z-]*)(?:\\.(?:0|[1-9]\\d*|\\d*[a-zA-Z-][0-9a-zA-Z-]*))*))?(?:\\+([0-9a-zA-Z-]+(?:\\.[0-9a-zA-Z-]+)*))?$"
+            },
+            "spec": {
+              "type": "object",
+              "$ref": "pkg/types/sbom/sbom_schema.json"
             }
           },
           "additionalProperties": false
@@ -942,6 +1354,12 @@ func init() {
     },
     "NotImplemented": {
       "description": "The content requested is not implemented"
+    },
+    "UnprocessableEntity": {
+      "description": "The entry was rejected by the admission policy of the server",
+      "schema": {
+        "$ref": "#/definitions/AdmissionError"
+      }
     }
   }
 }`))
@@ -980,11 +1398,24 @@ func init() {
             "schema": {
               "$ref": "#/definitions/SearchIndex"
             }
+          },
+          {
+            "minimum": 1,
+            "type": "integer",
+            "description": "Maximum number of results to return; the server returns no more than its maximum page size",
+            "name": "pageSize",
+            "in": "query"
+          },
+          {
+            "type": "string",
+            "description": "Token from the X-Rekor-Next-Page-Token header of the previous page, which must be requested with the same query",
+            "name": "pageToken",
+            "in": "query"
           }
         ],
         "responses": {
           "200": {
-            "description": "Returns zero or more entry UUIDs from the transparency log based on search query",
+            "description": "Returns zero or more entry UUIDs from the transparency log based on search query, in ascending order and each at most once, one page at a time\n",
             "schema": {
               "type": "array",
               "items": {
@@ -992,6 +1423,12 @@ func init() {
                 "type": "string",
                 "pattern": "^[0-9a-fA-F]{64}$"
               }
+            },
+            "headers": {
+              "X-Rekor-Next-Page-Token": {
+                "type": "string",
+                "description": "Token to pass as pageToken to fetch the next page of results; absent on the last page"
+              }
             }
           },
           "400": {
@@ -1033,6 +1470,33 @@ func init() {
         }
       }
     },
+    "/api/v1/log/checkpoint": {
+      "get": {
+        "description": "Returns the current signed tree head as a signed note, the text format of checkpoints used by witnesses and other transparency logs.",
+        "produces": [
+          "text/plain"
+        ],
+        "tags": [
+          "tlog"
+        ],
+        "summary": "Get the signed tree head of the transparency log as a checkpoint",
+        "operationId": "getLogCheckpoint",
+        "responses": {
+          "200": {
+            "description": "The signed tree head as a signed note",
+            "schema": {
+              "type": "string"
+            }
+          },
+          "default": {
+            "description": "There was an internal error in the server while processing the request",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          }
+        }
+      }
+    },
     "/api/v1/log/entries": {
       "get": {
         "tags": [
@@ -1121,6 +1585,12 @@ func init() {
               }
             }
           },
+          "422": {
+            "description": "The entry was rejected by the admission policy of the server",
+            "schema": {
+              "$ref": "#/definitions/AdmissionError"
+            }
+          },
           "default": {
             "description": "There was an internal error in the server while processing the request",
             "schema": {
@@ -1145,16 +1615,133 @@ func init() {
             "schema": {
               "$ref": "#/definitions/SearchLogQuery"
             }
+          },
+          {
+            "minimum": 1,
+            "type": "integer",
+            "description": "Maximum number of results to return; the server returns no more than its maximum page size",
+            "name": "pageSize",
+            "in": "query"
+          },
+          {
+            "type": "string",
+            "description": "Token from the X-Rekor-Next-Page-Token header of the previous page, which must be requested with the same query",
+            "name": "pageToken",
+            "in": "query"
           }
         ],
         "responses": {
           "200": {
-            "description": "Returns zero or more entries from the transparency log, according to how many were included in request query",
+            "description": "Returns zero or more entries from the transparency log, according to how many were included in request query; each page covers at most pageSize of the UUIDs, entries and log indexes of the query, in that order\n",
             "schema": {
               "type": "array",
               "items": {
                 "$ref": "#/definitions/LogEntry"
               }
+            },
+            "headers": {
+              "X-Rekor-Next-Page-Token": {
+                "type": "string",
+                "description": "Token to pass as pageToken to fetch the next page of results; absent on the last page"
+              }
+            }
+          },
+          "400": {
+            "description": "The content supplied to the server was invalid",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          },
+          "default": {
+            "description": "There was an internal error in the server while processing the request",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          }
+        }
+      }
+    },
+    "/api/v1/log/entries/stream": {
+      "get": {
+        "description": "Returns the entries from index start up to, but excluding, end as newline-delimited JSON, one LogEntry per line, so that monitors and mirrors do not have to page through the log. The next entries are only read from the log once the previous ones have been written to the client. If an error occurs after the response has started, the stream ends with an Error object rather than an entry; streams are also cut off by the write timeout of the server, so clients should resume after the last entry they received.\n",
+        "produces": [
+          "application/x-ndjson"
+        ],
+        "tags": [
+          "entries"
+        ],
+        "summary": "Streams a range of entries from the transparency log as newline-delimited JSON",
+        "operationId": "streamLogEntries",
+        "parameters": [
+          {
+            "minimum": 0,
+            "type": "integer",
+            "default": 0,
+            "description": "The index of the first entry to return",
+            "name": "start",
+            "in": "query"
+          },
+          {
+            "minimum": 0,
+            "type": "integer",
+            "description": "The index after the last entry to return; defaults to the size of the log when the request is received",
+            "name": "end",
+            "in": "query"
+          },
+          {
+            "type": "boolean",
+            "default": false,
+            "description": "Whether to include an inclusion proof against the tree of the shard holding each entry",
+            "name": "proofs",
+            "in": "query"
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "The entries of the range, one LogEntry per line",
+            "schema": {
+              "type": "string",
+              "format": "binary"
+            }
+          },
+          "400": {
+            "description": "The content supplied to the server was invalid",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          },
+          "default": {
+            "description": "There was an internal error in the server while processing the request",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          }
+        }
+      }
+    },
+    "/api/v1/log/entries/validate": {
+      "post": {
+        "description": "Performs every check that creating the entry would, including canonicalization and the admission policy of the server, without adding the entry to the transparency log.\n",
+        "tags": [
+          "entries"
+        ],
+        "summary": "Validates an entry without adding it to the transparency log",
+        "operationId": "validateLogEntry",
+        "parameters": [
+          {
+            "name": "proposedEntry",
+            "in": "body",
+            "required": true,
+            "schema": {
+              "$ref": "#/definitions/ProposedEntry"
+            }
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "Returns the canonicalized entry as it would be added to the transparency log, keyed by the UUID it would have",
+            "schema": {
+              "$ref": "#/definitions/LogEntry"
             }
           },
           "400": {
@@ -1163,6 +1750,24 @@ func init() {
               "$ref": "#/definitions/Error"
             }
           },
+          "409": {
+            "description": "The request conflicts with the current state of the transparency log",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            },
+            "headers": {
+              "Location": {
+                "type": "string",
+                "format": "uri"
+              }
+            }
+          },
+          "422": {
+            "description": "The entry was rejected by the admission policy of the server",
+            "schema": {
+              "$ref": "#/definitions/AdmissionError"
+            }
+          },
           "default": {
             "description": "There was an internal error in the server while processing the request",
             "schema": {
@@ -1209,6 +1814,57 @@ func init() {
         }
       }
     },
+    "/api/v1/log/entries/{entryUUID}/proof": {
+      "get": {
+        "description": "Returns the proof that the entry was included in the tree of the requested size, so that verifiers holding an old bundle can re-verify it, together with the proof that the current tree is consistent with that tree and the signed tree head of the current tree, which confirm that the log has only grown since\n",
+        "tags": [
+          "entries"
+        ],
+        "summary": "Get an inclusion proof of an entry at an earlier tree size, and a consistency proof from that size to the current tree",
+        "operationId": "getLogEntryProof",
+        "parameters": [
+          {
+            "pattern": "^[0-9a-fA-F]{64}$",
+            "type": "string",
+            "description": "the UUID of the entry for which the proofs should be returned",
+            "name": "entryUUID",
+            "in": "path",
+            "required": true
+          },
+          {
+            "minimum": 1,
+            "type": "integer",
+            "description": "The size of the tree that the inclusion proof is computed for, which the consistency proof starts from",
+            "name": "treeSize",
+            "in": "query",
+            "required": true
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "The inclusion proof at the requested tree size and the consistency proof to the current tree",
+            "schema": {
+              "$ref": "#/definitions/HistoricalProof"
+            }
+          },
+          "400": {
+            "description": "The content supplied to the server was invalid",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          },
+          "404": {
+            "description": "The content requested could not be found"
+          },
+          "default": {
+            "description": "There was an internal error in the server while processing the request",
+            "schema": {
+              "$ref": "#/definitions/Error"
+            }
+          }
+        }
+      }
+    },
     "/api/v1/log/proof": {
       "get": {
         "description": "Returns a list of hashes for specified tree sizes that can be used to confirm the consistency of the transparency log",
@@ -1404,27 +2060,65 @@ func init() {
     }
   },
   "definitions": {
-    "AlpineV001SchemaPackage": {
-      "description": "Information about the package associated with the entry",
+    "AdmissionError": {
       "type": "object",
-      "oneOf": [
-        {
-          "required": [
-            "url"
-          ]
-        },
-        {
-          "required": [
-            "content"
-          ]
-        }
-      ],
       "properties": {
-        "content": {
-          "description": "Specifies the package inline within the document",
-          "type": "string",
-          "format": "byte",
-          "writeOnly": true
+        "code": {
+          "type": "integer"
+        },
+        "detail": {
+          "type": "string"
+        },
+        "errorCode": {
+          "$ref": "#/definitions/ErrorCode"
+        },
+        "message": {
+          "type": "string"
+        },
+        "status": {
+          "type": "integer"
+        },
+        "title": {
+          "type": "string"
+        },
+        "violations": {
+          "type": "array",
+          "items": {
+            "$ref": "#/definitions/PolicyViolation"
+          }
+        }
+      }
+    },
+    "Annotations": {
+      "description": "Key/value pairs describing the artifact, such as the build or commit it came from; they are covered by the entry's leaf hash",
+      "type": "object",
+      "maxProperties": 16,
+      "additionalProperties": {
+        "type": "string",
+        "maxLength": 256
+      }
+    },
+    "AlpineV001SchemaPackage": {
+      "description": "Information about the package associated with the entry",
+      "type": "object",
+      "oneOf": [
+        {
+          "required": [
+            "url"
+          ]
+        },
+        {
+          "required": [
+            "content"
+          ]
+        }
+      ],
+      "properties": {
+        "content": {
+          "description": "Specifies the package inline within the document",
+          "type": "string",
+          "format": "byte",
+          "writeOnly": true
         },
         "hash": {
           "description": "Specifies the hash algorithm and value for the package",
@@ -1513,6 +2207,159 @@ func init() {
         }
       }
     },
+    "ChecksumsV001SchemaArtifact": {
+      "description": "The artifact this entry is about",
+      "type": "object",
+      "properties": {
+        "hash": {
+          "description": "Specifies the hash algorithm and value of the artifact, as listed in the checksums file",
+          "type": "object",
+          "required": [
+            "algorithm",
+            "value"
+          ],
+          "properties": {
+            "algorithm": {
+              "description": "The hashing function used to compute the hash value",
+              "type": "string",
+              "enum": [
+                "sha256",
+                "sha384",
+                "sha512"
+              ]
+            },
+            "value": {
+              "description": "The hash value of the artifact",
+              "type": "string"
+            }
+          }
+        },
+        "name": {
+          "description": "The name of the artifact as listed in the checksums file",
+          "type": "string"
+        }
+      }
+    },
+    "ChecksumsV001SchemaArtifactHash": {
+      "description": "Specifies the hash algorithm and value of the artifact, as listed in the checksums file",
+      "type": "object",
+      "required": [
+        "algorithm",
+        "value"
+      ],
+      "properties": {
+        "algorithm": {
+          "description": "The hashing function used to compute the hash value",
+          "type": "string",
+          "enum": [
+            "sha256",
+            "sha384",
+            "sha512"
+          ]
+        },
+        "value": {
+          "description": "The hash value of the artifact",
+          "type": "string"
+        }
+      }
+    },
+    "ChecksumsV001SchemaChecksums": {
+      "description": "Information about the signed checksums file listing the artifact",
+      "type": "object",
+      "properties": {
+        "content": {
+          "description": "Specifies the checksums file inline within the entry",
+          "type": "string",
+          "format": "byte",
+          "writeOnly": true
+        },
+        "hash": {
+          "description": "Specifies the hash algorithm and value for the checksums file",
+          "type": "object",
+          "required": [
+            "algorithm",
+            "value"
+          ],
+          "properties": {
+            "algorithm": {
+              "description": "The hashing function used to compute the hash value",
+              "type": "string",
+              "enum": [
+                "sha256"
+              ]
+            },
+            "value": {
+              "description": "The hash value for the checksums file",
+              "type": "string"
+            }
+          }
+        }
+      }
+    },
+    "ChecksumsV001SchemaChecksumsHash": {
+      "description": "Specifies the hash algorithm and value for the checksums file",
+      "type": "object",
+      "required": [
+        "algorithm",
+        "value"
+      ],
+      "properties": {
+        "algorithm": {
+          "description": "The hashing function used to compute the hash value",
+          "type": "string",
+          "enum": [
+            "sha256"
+          ]
+        },
+        "value": {
+          "description": "The hash value for the checksums file",
+          "type": "string"
+        }
+      }
+    },
+    "ChecksumsV001SchemaSignature": {
+      "description": "Information about the detached signature over the checksums file",
+      "type": "object",
+      "properties": {
+        "content": {
+          "description": "Specifies the content of the signature inline within the document",
+          "type": "string",
+          "format": "byte"
+        },
+        "format": {
+          "description": "Specifies the format of the signature",
+          "type": "string",
+          "enum": [
+            "pgp",
+            "minisign",
+            "x509",
+            "ssh"
+          ]
+        },
+        "publicKey": {
+          "description": "The public key that can verify the signature",
+          "type": "object",
+          "properties": {
+            "content": {
+              "description": "Specifies the content of the public key inline within the document",
+              "type": "string",
+              "format": "byte"
+            }
+          }
+        }
+      }
+    },
+    "ChecksumsV001SchemaSignaturePublicKey": {
+      "description": "The public key that can verify the signature",
+      "type": "object",
+      "properties": {
+        "content": {
+          "description": "Specifies the content of the public key inline within the document",
+          "type": "string",
+          "format": "byte"
+        }
+      }
+    },
     "ConsistencyProof": {
       "type": "object",
       "required": [
@@ -1536,16 +2383,49 @@ func init() {
       }
     },
     "Error": {
+      "description": "Problem details of a failed request as described in RFC 7807, served as application/problem+json to clients accepting it",
       "type": "object",
       "properties": {
         "code": {
+          "description": "HTTP status code of the response; superseded by status",
           "type": "integer"
         },
+        "detail": {
+          "description": "Human-readable explanation specific to this occurrence of the problem",
+          "type": "string"
+        },
+        "errorCode": {
+          "$ref": "#/definitions/ErrorCode"
+        },
         "message": {
+          "description": "Human-readable explanation of the problem; superseded by detail",
+          "type": "string"
+        },
+        "status": {
+          "description": "HTTP status code of the response",
+          "type": "integer"
+        },
+        "title": {
+          "description": "Short summary of the kind of problem",
           "type": "string"
         }
       }
     },
+    "ErrorCode": {
+      "description": "Machine-readable code identifying the kind of problem",
+      "type": "string",
+      "enum": [
+        "validation_failed",
+        "duplicate_entry",
+        "policy_rejected",
+        "backend_unavailable",
+        "not_found",
+        "unauthorized",
+        "rate_limited",
+        "not_implemented",
+        "internal_error"
+      ]
+    },
     "HashedrekordV001SchemaData": {
       "description": "Information about the content associated with the entry",
       "type": "object",
@@ -1562,7 +2442,9 @@ func init() {
               "description": "The hashing function used to compute the hash value",
               "type": "string",
               "enum": [
-                "sha256"
+                "sha256",
+                "sha384",
+                "sha512"
               ]
             },
             "value": {
@@ -1585,7 +2467,9 @@ func init() {
           "description": "The hashing function used to compute the hash value",
           "type": "string",
           "enum": [
-            "sha256"
+            "sha256",
+            "sha384",
+            "sha512"
           ]
         },
         "value": {
@@ -1817,6 +2701,26 @@ func init() {
         }
       }
     },
+    "HistoricalProof": {
+      "description": "Proof that an entry was included in an earlier tree of the log and that the current tree of the log is consistent with it",
+      "type": "object",
+      "required": [
+        "inclusionProof",
+        "consistencyProof",
+        "logInfo"
+      ],
+      "properties": {
+        "consistencyProof": {
+          "$ref": "#/definitions/ConsistencyProof"
+        },
+        "inclusionProof": {
+          "$ref": "#/definitions/InclusionProof"
+        },
+        "logInfo": {
+          "$ref": "#/definitions/LogInfo"
+        }
+      }
+    },
     "InclusionProof": {
       "type": "object",
       "required": [
@@ -2126,12 +3030,74 @@ func init() {
         }
       }
     },
+    "OCIV001SchemaImage": {
+      "description": "The container image the signature was made over",
+      "type": "object",
+      "required": [
+        "digest"
+      ],
+      "properties": {
+        "digest": {
+          "description": "The digest of the image manifest",
+          "type": "string",
+          "pattern": "^sha256:[0-9a-f]{64}$"
+        }
+      }
+    },
+    "OCIV001SchemaSignature": {
+      "description": "Information about the detached signature over the payload",
+      "type": "object",
+      "properties": {
+        "content": {
+          "description": "Specifies the content of the signature inline within the document",
+          "type": "string",
+          "format": "byte"
+        },
+        "publicKey": {
+          "description": "The public key or certificate that can verify the signature",
+          "type": "object",
+          "properties": {
+            "content": {
+              "description": "Specifies the content of the public key inline within the document",
+              "type": "string",
+              "format": "byte"
+            }
+          }
+        }
+      }
+    },
+    "OCIV001SchemaSignaturePublicKey": {
+      "description": "The public key or certificate that can verify the signature",
+      "type": "object",
+      "properties": {
+        "content": {
+          "description": "Specifies the content of the public key inline within the document",
+          "type": "string",
+          "format": "byte"
+        }
+      }
+    },
+    "PolicyViolation": {
+      "type": "object",
+      "properties": {
+        "message": {
+          "type": "string"
+        },
+        "policy": {
+          "description": "Name of the admission policy which rejected the entry",
+          "type": "string"
+        }
+      }
+    },
     "ProposedEntry": {
       "type": "object",
       "required": [
         "kind"
       ],
       "properties": {
+        "annotations": {
+          "$ref": "#/definitions/Annotations"
+        },
         "kind": {
           "type": "string"
         }
@@ -2334,22 +3300,195 @@ func init() {
         }
       }
     },
-    "Rfc3161V001SchemaTsr": {
-      "description": "Information about the tsr file associated with the entry",
-      "type": "object",
-      "required": [
-        "content"
-      ],
-      "properties": {
-        "content": {
-          "description": "Specifies the tsr file content inline within the document",
-          "type": "string",
-          "format": "byte"
-        }
-      }
-    },
-    "RpmV001SchemaPackage": {
-      "description": "Information about the package associated with the entry",
+    "RekordV002SchemaData": {
+      "description": "Information about the content associated with the entry",
+      "type": "object",
+      "oneOf": [
+        {
+          "required": [
+            "url"
+          ]
+        },
+        {
+          "required": [
+            "content"
+          ]
+        }
+      ],
+      "properties": {
+        "content": {
+          "description": "Specifies the content inline within the document",
+          "type": "string",
+          "format": "byte",
+          "writeOnly": true
+        },
+        "hash": {
+          "description": "Specifies the hash algorithm and value for the content",
+          "type": "object",
+          "required": [
+            "algorithm",
+            "value"
+          ],
+          "properties": {
+            "algorithm": {
+              "description": "The hashing function used to compute the hash value",
+              "type": "string",
+              "enum": [
+                "sha256"
+              ]
+            },
+            "value": {
+              "description": "The hash value for the content",
+              "type": "string"
+            }
+          }
+        },
+        "url": {
+          "description": "Specifies the location of the content",
+          "type": "string",
+          "format": "uri",
+          "writeOnly": true
+        }
+      }
+    },
+    "RekordV002SchemaDataHash": {
+      "description": "Specifies the hash algorithm and value for the content",
+      "type": "object",
+      "required": [
+        "algorithm",
+        "value"
+      ],
+      "properties": {
+        "algorithm": {
+          "description": "The hashing function used to compute the hash value",
+          "type": "string",
+          "enum": [
+            "sha256"
+          ]
+        },
+        "value": {
+          "description": "The hash value for the content",
+          "type": "string"
+        }
+      }
+    },
+    "RekordV002SchemaSignaturesItems0": {
+      "description": "A detached signature over the content and the public key that verifies it",
+      "type": "object",
+      "oneOf": [
+        {
+          "required": [
+            "format",
+            "publicKey",
+            "url"
+          ]
+        },
+        {
+          "required": [
+            "format",
+            "publicKey",
+            "content"
+          ]
+        }
+      ],
+      "properties": {
+        "content": {
+          "description": "Specifies the content of the signature inline within the document",
+          "type": "string",
+          "format": "byte"
+        },
+        "format": {
+          "description": "Specifies the format of the signature",
+          "type": "string",
+          "enum": [
+            "pgp",
+            "minisign",
+            "x509",
+            "ssh"
+          ]
+        },
+        "publicKey": {
+          "description": "The public key that can verify the signature",
+          "type": "object",
+          "oneOf": [
+            {
+              "required": [
+                "url"
+              ]
+            },
+            {
+              "required": [
+                "content"
+              ]
+            }
+          ],
+          "properties": {
+            "content": {
+              "description": "Specifies the content of the public key inline within the document",
+              "type": "string",
+              "format": "byte"
+            },
+            "url": {
+              "description": "Specifies the location of the public key",
+              "type": "string",
+              "format": "uri",
+              "writeOnly": true
+            }
+          }
+        },
+        "url": {
+          "description": "Specifies the location of the signature",
+          "type": "string",
+          "format": "uri",
+          "writeOnly": true
+        }
+      }
+    },
+    "RekordV002SchemaSignaturesItems0PublicKey": {
+      "description": "The public key that can verify the signature",
+      "type": "object",
+      "oneOf": [
+        {
+          "required": [
+            "url"
+          ]
+        },
+        {
+          "required": [
+            "content"
+          ]
+        }
+      ],
+      "properties": {
+        "content": {
+          "description": "Specifies the content of the public key inline within the document",
+          "type": "string",
+          "format": "byte"
+        },
+        "url": {
+          "description": "Specifies the location of the public key",
+          "type": "string",
+          "format": "uri",
+          "writeOnly": true
+        }
+      }
+    },
+    "Rfc3161V001SchemaTsr": {
+      "description": "Information about the tsr file associated with the entry",
+      "type": "object",
+      "required": [
+        "content"
+      ],
+      "properties": {
+        "content": {
+          "description": "Specifies the tsr file content inline within the document",
+          "type": "string",
+          "format": "byte"
+        }
+      }
+    },
+    "RpmV001SchemaPackage": {
+      "description": "Information about the package associated with the entry",
       "type": "object",
       "oneOf": [
         {
@@ -2378,58 +3517,245 @@ func init() {
             "value"
           ],
           "properties": {
-            "algorithm": {
-              "description": "The hashing function used to compute the hash value",
+            "algorithm": {
+              "description": "The hashing function used to compute the hash value",
+              "type": "string",
+              "enum": [
+                "sha256"
+              ]
+            },
+            "value": {
+              "description": "The hash value for the package",
+              "type": "string"
+            }
+          }
+        },
+        "headers": {
+          "description": "Values of the RPM headers",
+          "type": "object",
+          "additionalProperties": {
+            "type": "string"
+          },
+          "readOnly": true
+        },
+        "url": {
+          "description": "Specifies the location of the package; if this is specified, a hash value must also be provided",
+          "type": "string",
+          "format": "uri",
+          "writeOnly": true
+        }
+      }
+    },
+    "RpmV001SchemaPackageHash": {
+      "description": "Specifies the hash algorithm and value for the package",
+      "type": "object",
+      "required": [
+        "algorithm",
+        "value"
+      ],
+      "properties": {
+        "algorithm": {
+          "description": "The hashing function used to compute the hash value",
+          "type": "string",
+          "enum": [
+            "sha256"
+          ]
+        },
+        "value": {
+          "description": "The hash value for the package",
+          "type": "string"
+        }
+      }
+    },
+    "RpmV001SchemaPublicKey": {
+      "description": "The PGP public key that can verify the RPM signature",
+      "type": "object",
+      "oneOf": [
+        {
+          "required": [
+            "url"
+          ]
+        },
+        {
+          "required": [
+            "content"
+          ]
+        }
+      ],
+      "properties": {
+        "content": {
+          "description": "Specifies the content of the public key inline within the document",
+          "type": "string",
+          "format": "byte"
+        },
+        "url": {
+          "description": "Specifies the location of the public key",
+          "type": "string",
+          "format": "uri",
+          "writeOnly": true
+        }
+      }
+    },
+    "SbomV001SchemaDocument": {
+      "description": "Information about the SBOM document associated with the entry",
+      "type": "object",
+      "oneOf": [
+        {
+          "required": [
+            "url"
+          ]
+        },
+        {
+          "required": [
+            "content"
+          ]
+        }
+      ],
+      "properties": {
+        "content": {
+          "description": "Specifies the document inline within the entry",
+          "type": "string",
+          "format": "byte",
+          "writeOnly": true
+        },
+        "format": {
+          "description": "The SBOM format the document is written in",
+          "type": "string",
+          "enum": [
+            "spdx",
+            "cyclonedx"
+          ],
+          "readOnly": true
+        },
+        "hash": {
+          "description": "Specifies the hash algorithm and value for the document",
+          "type": "object",
+          "required": [
+            "algorithm",
+            "value"
+          ],
+          "properties": {
+            "algorithm": {
+              "description": "The hashing function used to compute the hash value",
+              "type": "string",
+              "enum": [
+                "sha256"
+              ]
+            },
+            "value": {
+              "description": "The hash value for the document",
+              "type": "string"
+            }
+          }
+        },
+        "specVersion": {
+          "description": "The version of the SBOM specification the document conforms to",
+          "type": "string",
+          "readOnly": true
+        },
+        "url": {
+          "description": "Specifies the location of the document",
+          "type": "string",
+          "format": "uri",
+          "writeOnly": true
+        }
+      }
+    },
+    "SbomV001SchemaDocumentHash": {
+      "description": "Specifies the hash algorithm and value for the document",
+      "type": "object",
+      "required": [
+        "algorithm",
+        "value"
+      ],
+      "properties": {
+        "algorithm": {
+          "description": "The hashing function used to compute the hash value",
+          "type": "string",
+          "enum": [
+            "sha256"
+          ]
+        },
+        "value": {
+          "description": "The hash value for the document",
+          "type": "string"
+        }
+      }
+    },
+    "SbomV001SchemaSignature": {
+      "description": "Information about the detached signature over the document",
+      "type": "object",
+      "oneOf": [
+        {
+          "required": [
+            "format",
+            "publicKey",
+            "url"
+          ]
+        },
+        {
+          "required": [
+            "format",
+            "publicKey",
+            "content"
+          ]
+        }
+      ],
+      "properties": {
+        "content": {
+          "description": "Specifies the content of the signature inline within the document",
+          "type": "string",
+          "format": "byte"
+        },
+        "format": {
+          "description": "Specifies the format of the signature",
+          "type": "string",
+          "enum": [
+            "pgp",
+            "minisign",
+            "x509",
+            "ssh"
+          ]
+        },
+        "publicKey": {
+          "description": "The public key that can verify the signature",
+          "type": "object",
+          "oneOf": [
+            {
+              "required": [
+                "url"
+              ]
+            },
+            {
+              "required": [
+                "content"
+              ]
+            }
+          ],
+          "properties": {
+            "content": {
+              "description": "Specifies the content of the public key inline within the document",
               "type": "string",
-              "enum": [
-                "sha256"
-              ]
+              "format": "byte"
             },
-            "value": {
-              "description": "The hash value for the package",
-              "type": "string"
+            "url": {
+              "description": "Specifies the location of the public key",
+              "type": "string",
+              "format": "uri",
+              "writeOnly": true
             }
           }
         },
-        "headers": {
-          "description": "Values of the RPM headers",
-          "type": "object",
-          "additionalProperties": {
-            "type": "string"
-          },
-          "readOnly": true
-        },
         "url": {
-          "description": "Specifies the location of the package; if this is specified, a hash value must also be provided",
+          "description": "Specifies the location of the signature",
           "type": "string",
           "format": "uri",
           "writeOnly": true
         }
       }
     },
-    "RpmV001SchemaPackageHash": {
-      "description": "Specifies the hash algorithm and value for the package",
-      "type": "object",
-      "required": [
-        "algorithm",
-        "value"
-      ],
-      "properties": {
-        "algorithm": {
-          "description": "The hashing function used to compute the hash value",
-          "type": "string",
-          "enum": [
-            "sha256"
-          ]
-        },
-        "value": {
-          "description": "The hash value for the package",
-          "type": "string"
-        }
-      }
-    },
-    "RpmV001SchemaPublicKey": {
-      "description": "The PGP public key that can verify the RPM signature",
+    "SbomV001SchemaSignaturePublicKey": {
+      "description": "The public key that can verify the signature",
       "type": "object",
       "oneOf": [
         {
@@ -2460,13 +3786,35 @@ func init() {
     "SearchIndex": {
       "type": "object",
       "properties": {
+        "annotations": {
+          "$ref": "#/definitions/Annotations"
+        },
+        "artifactUrl": {
+          "description": "URL an artifact was downloaded from when its entry was uploaded; the scheme and host are matched case insensitively and default ports are ignored",
+          "type": "string"
+        },
+        "commit": {
+          "description": "ID of a git commit which artifacts were built from, as recorded by the git.commit annotation or SLSA provenance",
+          "type": "string",
+          "pattern": "^[0-9a-fA-F]{40}$|^[0-9a-fA-F]{64}$"
+        },
         "email": {
           "type": "string",
           "format": "email"
         },
         "hash": {
           "type": "string",
-          "pattern": "^(sha256:)?[0-9a-fA-F]{64}$|^(sha1:)?[0-9a-fA-F]{40}$"
+          "pattern": "^(sha512:)?[0-9a-fA-F]{128}$|^(sha384:)?[0-9a-fA-F]{96}$|^(sha256:)?[0-9a-fA-F]{64}$|^(sha1:)?[0-9a-fA-F]{40}$"
+        },
+        "integratedSince": {
+          "description": "Unix time in seconds from which entries were integrated into the log, inclusive; combined with other criteria, only their matches integrated in the time range are returned",
+          "type": "integer",
+          "format": "int64"
+        },
+        "integratedUntil": {
+          "description": "Unix time in seconds until which entries were integrated into the log, inclusive; combined with other criteria, only their matches integrated in the time range are returned",
+          "type": "integer",
+          "format": "int64"
         },
         "publicKey": {
           "type": "object",
@@ -2493,6 +3841,10 @@ func init() {
               "format": "uri"
             }
           }
+        },
+        "repository": {
+          "description": "URL of a source repository which artifacts were built from, as recorded by the git.repository annotation or SLSA provenance",
+          "type": "string"
         }
       }
     },
@@ -2722,6 +4074,155 @@ func init() {
       "$schema": "http://json-schema.org/draft-07/schema",
       "$id": "http://rekor.sigstore.dev/types/alpine/alpine_v0_0_1_schema.json"
     },
+    "checksums": {
+      "description": "Artifact listed in a signed checksums file",
+      "type": "object",
+      "allOf": [
+        {
+          "$ref": "#/definitions/ProposedEntry"
+        },
+        {
+          "required": [
+            "apiVersion",
+            "spec"
+          ],
+          "properties": {
+            "apiVersion": {
+              "type": "string",
+              "pattern": "^(0|[1-9]\\d*)\\.(0|[1-9]\\d*)\\.(0|[1-9]\\d*)(?:-((?:0|[1-9]\\d*|\\d*[a-zA-Z-][0-9a-zA-Z-]*)(?:\\.(?:0|[1-9]\\d*|\\d*[a-zA-Z-][0-9a-zA-Z-]*))*))?(?:\\+([0-9a-zA-Z-]+(?:\\.[0-9a-zA-Z-]+)*))?$"
+            },
+            "spec": {
+              "$ref": "#/definitions/checksumsSchema"
+            }
+          },
+          "additionalProperties": false
+        }
+      ]
+    },
+    "checksumsSchema": {
+      "description": "Schema for artifacts listed in signed checksums files",
+      "type": "object",
+      "title": "Checksums Schema",
+      "oneOf": [
+        {
+          "$ref": "#/definitions/checksumsV001Schema"
+        }
+      ],
+      "$schema": "http://json-schema.org/draft-07/schema",
+      "$id": "http://rekor.sigstore.dev/types/checksums/checksums_schema.json"
+    },
+    "checksumsV001Schema": {
+      "description": "Schema for an artifact listed in a checksums file with a detached signature",
+      "type": "object",
+      "title": "Checksums v0.0.1 Schema",
+      "required": [
+        "signature",
+        "checksums",
+        "artifact"
+      ],
+      "properties": {
+        "artifact": {
+          "description": "The artifact this entry is about",
+          "type": "object",
+          "properties": {
+            "hash": {
+              "description": "Specifies the hash algorithm and value of the artifact, as listed in the checksums file",
+              "type": "object",
+              "required": [
+                "algorithm",
+                "value"
+              ],
+              "properties": {
+                "algorithm": {
+                  "description": "The hashing function used to compute the hash value",
+                  "type": "string",
+                  "enum": [
+                    "sha256",
+                    "sha384",
+                    "sha512"
+                  ]
+                },
+                "value": {
+                  "description": "The hash value of the artifact",
+                  "type": "string"
+                }
+              }
+            },
+            "name": {
+              "description": "The name of the artifact as listed in the checksums file",
+              "type": "string"
+            }
+          }
+        },
+        "checksums": {
+          "description": "Information about the signed checksums file listing the artifact",
+          "type": "object",
+          "properties": {
+            "content": {
+              "description": "Specifies the checksums file inline within the entry",
+              "type": "string",
+              "format": "byte",
+              "writeOnly": true
+            },
+            "hash": {
+              "description": "Specifies the hash algorithm and value for the checksums file",
+              "type": "object",
+              "required": [
+                "algorithm",
+                "value"
+              ],
+              "properties": {
+                "algorithm": {
+                  "description": "The hashing function used to compute the hash value",
+                  "type": "string",
+                  "enum": [
+                    "sha256"
+                  ]
+                },
+                "value": {
+                  "description": "The hash value for the checksums file",
+                  "type": "string"
+                }
+              }
+            }
+          }
+        },
+        "signature": {
+          "description": "Information about the detached signature over the checksums file",
+          "type": "object",
+          "properties": {
+            "content": {
+              "description": "Specifies the content of the signature inline within the document",
+              "type": "string",
+              "format": "byte"
+            },
+            "format": {
+              "description": "Specifies the format of the signature",
+              "type": "string",
+              "enum": [
+                "pgp",
+                "minisign",
+                "x509",
+                "ssh"
+              ]
+            },
+            "publicKey": {
+              "description": "The public key that can verify the signature",
+              "type": "object",
+              "properties": {
+                "content": {
+                  "description": "Specifies the content of the public key inline within the document",
+                  "type": "string",
+                  "format": "byte"
+                }
+              }
+            }
+          }
+        }
+      },
+      "$schema": "http://json-schema.org/draft-07/schema",
+      "$id": "http://rekor.sigstore.dev/types/checksums/checksums_v0_0_1_schema.json"
+    },
     "hashedrekord": {
       "description": "Hashed Rekord object",
       "type": "object",
@@ -2784,7 +4285,9 @@ func init() {
                   "description": "The hashing function used to compute the hash value",
                   "type": "string",
                   "enum": [
-                    "sha256"
+                    "sha256",
+                    "sha384",
+                    "sha512"
                   ]
                 },
                 "value": {
@@ -3181,18 +4684,110 @@ func init() {
               ],
               "properties": {
                 "content": {
-                  "description": "Specifies the content of the X509 certificate containing the public key used to verify the signature",
+                  "description": "Specifies the content of the X509 certificate containing the public key used to verify the signature",
+                  "type": "string",
+                  "format": "byte"
+                }
+              },
+              "readOnly": true
+            }
+          }
+        }
+      },
+      "$schema": "http://json-schema.org/draft-07/schema",
+      "$id": "http://rekor.sigstore.dev/types/jar/jar_v0_0_1_schema.json"
+    },
+    "oci": {
+      "description": "OCI container image",
+      "type": "object",
+      "allOf": [
+        {
+          "$ref": "#/definitions/ProposedEntry"
+        },
+        {
+          "required": [
+            "apiVersion",
+            "spec"
+          ],
+          "properties": {
+            "apiVersion": {
+              "type": "string",
+              "pattern": "^(0|[1-9]\\d*)\\.(0|[1-9]\\d*)\\.(0|[1-9]\\d*)(?:-((?:0|[1-9]\\d*|\\d*[a-zA-Z-][0-9a-zA-Z-]*)(?:\\.(?:0|[1-9]\\d*|\\d*[a-zA-Z-][0-9a-zA-Z-]*))*))?(?:\\+([0-9a-zA-Z-]+(?:\\.[0-9a-zA-Z-]+)*))?$"
+            },
+            "spec": {
+              "$ref": "#/definitions/ociSchema"
+            }
+          },
+          "additionalProperties": false
+        }
+      ]
+    },
+    "ociSchema": {
+      "description": "Schema for signed OCI container image objects",
+      "type": "object",
+      "title": "OCI Image Schema",
+      "oneOf": [
+        {
+          "$ref": "#/definitions/ociV001Schema"
+        }
+      ],
+      "$schema": "http://json-schema.org/draft-07/schema",
+      "$id": "http://rekor.sigstore.dev/types/oci/oci_schema.json"
+    },
+    "ociV001Schema": {
+      "description": "Schema for signed OCI container image entries",
+      "type": "object",
+      "title": "OCI v0.0.1 Schema",
+      "required": [
+        "image",
+        "payload",
+        "signature"
+      ],
+      "properties": {
+        "image": {
+          "description": "The container image the signature was made over",
+          "type": "object",
+          "required": [
+            "digest"
+          ],
+          "properties": {
+            "digest": {
+              "description": "The digest of the image manifest",
+              "type": "string",
+              "pattern": "^sha256:[0-9a-f]{64}$"
+            }
+          }
+        },
+        "payload": {
+          "description": "The signed payload, in the cosign simple signing format",
+          "type": "string",
+          "format": "byte"
+        },
+        "signature": {
+          "description": "Information about the detached signature over the payload",
+          "type": "object",
+          "properties": {
+            "content": {
+              "description": "Specifies the content of the signature inline within the document",
+              "type": "string",
+              "format": "byte"
+            },
+            "publicKey": {
+              "description": "The public key or certificate that can verify the signature",
+              "type": "object",
+              "properties": {
+                "content": {
+                  "description": "Specifies the content of the public key inline within the document",
                   "type": "string",
                   "format": "byte"
                 }
-              },
-              "readOnly": true
+              }
             }
           }
         }
       },
       "$schema": "http://json-schema.org/draft-07/schema",
-      "$id": "http://rekor.sigstore.dev/types/jar/jar_v0_0_1_schema.json"
+      "$id": "http://rekor.sigstore.dev/types/oci/oci_v0_0_1_schema.json"
     },
     "rekord": {
       "description": "Rekord object",
@@ -3226,6 +4821,9 @@ func init() {
       "oneOf": [
         {
           "$ref": "#/definitions/rekordV001Schema"
+        },
+        {
+          "$ref": "#/definitions/rekordV002Schema"
         }
       ],
       "$schema": "http://json-schema.org/draft-07/schema",
@@ -3367,6 +4965,147 @@ func init() {
       "$schema": "http://json-schema.org/draft-07/schema",
       "$id": "http://rekor.sigstore.dev/types/rekord/rekord_v0_0_1_schema.json"
     },
+    "rekordV002Schema": {
+      "description": "Schema for Rekord object with multiple signatures",
+      "type": "object",
+      "title": "Rekor v0.0.2 Schema",
+      "required": [
+        "signatures",
+        "data"
+      ],
+      "properties": {
+        "data": {
+          "description": "Information about the content associated with the entry",
+          "type": "object",
+          "oneOf": [
+            {
+              "required": [
+                "url"
+              ]
+            },
+            {
+              "required": [
+                "content"
+              ]
+            }
+          ],
+          "properties": {
+            "content": {
+              "description": "Specifies the content inline within the document",
+              "type": "string",
+              "format": "byte",
+              "writeOnly": true
+            },
+            "hash": {
+              "description": "Specifies the hash algorithm and value for the content",
+              "type": "object",
+              "required": [
+                "algorithm",
+                "value"
+              ],
+              "properties": {
+                "algorithm": {
+                  "description": "The hashing function used to compute the hash value",
+                  "type": "string",
+                  "enum": [
+                    "sha256"
+                  ]
+                },
+                "value": {
+                  "description": "The hash value for the content",
+                  "type": "string"
+                }
+              }
+            },
+            "url": {
+              "description": "Specifies the location of the content",
+              "type": "string",
+              "format": "uri",
+              "writeOnly": true
+            }
+          }
+        },
+        "signatures": {
+          "description": "Information about the detached signatures associated with the entry; every signature must verify over the content",
+          "type": "array",
+          "minItems": 1,
+          "items": {
+            "description": "A detached signature over the content and the public key that verifies it",
+            "type": "object",
+            "oneOf": [
+              {
+                "required": [
+                  "format",
+                  "publicKey",
+                  "url"
+                ]
+              },
+              {
+                "required": [
+                  "format",
+                  "publicKey",
+                  "content"
+                ]
+              }
+            ],
+            "properties": {
+              "content": {
+                "description": "Specifies the content of the signature inline within the document",
+                "type": "string",
+                "format": "byte"
+              },
+              "format": {
+                "description": "Specifies the format of the signature",
+                "type": "string",
+                "enum": [
+                  "pgp",
+                  "minisign",
+                  "x509",
+                  "ssh"
+                ]
+              },
+              "publicKey": {
+                "description": "The public key that can verify the signature",
+                "type": "object",
+                "oneOf": [
+                  {
+                    "required": [
+                      "url"
+                    ]
+                  },
+                  {
+                    "required": [
+                      "content"
+                    ]
+                  }
+                ],
+                "properties": {
+                  "content": {
+                    "description": "Specifies the content of the public key inline within the document",
+                    "type": "string",
+                    "format": "byte"
+                  },
+                  "url": {
+                    "description": "Specifies the location of the public key",
+                    "type": "string",
+                    "format": "uri",
+                    "writeOnly": true
+                  }
+                }
+              },
+              "url": {
+                "description": "Specifies the location of the signature",
+                "type": "string",
+                "format": "uri",
+                "writeOnly": true
+              }
+            }
+          }
+        }
+      },
+      "$schema": "http://json-schema.org/draft-07/schema",
+      "$id": "http://rekor.sigstore.dev/types/rekord/rekord_v0_0_2_schema.json"
+    },
     "rfc3161": {
       "description": "RFC3161 Timestamp",
       "type": "object",
@@ -3568,6 +5307,193 @@ func init() {
       "$schema": "http://json-schema.org/draft-07/schema",
       "$id": "http://rekor.sigstore.dev/types/rpm/rpm_v0_0_1_schema.json"
     },
+    "sbom": {
+      "description": "Software bill of materials",
+      "type": "object",
+      "allOf": [
+        {
+          "$ref": "#/definitions/ProposedEntry"
+        },
+        {
+          "required": [
+            "apiVersion",
+            "spec"
+          ],
+          "properties": {
+            "apiVersion": {
+              "type": "string",
+              "pattern": "^(0|[1-9]\\d*)\\.(0|[1-9]\\d*)\\.(0|[1-9]\\d*)(?:-((?:0|[1-9]\\d*|\\d*[a-zA-Z-][0-9a-zA-Z-]*)(?:\\.(?:0|[1-9]\\d*|\\d*[a-zA-Z-][0-9a-zA-Z-]*))*))?(?:\\+([0-9a-zA-Z-]+(?:\\.[0-9a-zA-Z-]+)*))?$"
+            },
+            "spec": {
+              "$ref": "#/definitions/sbomSchema"
+            }
+          },
+          "additionalProperties": false
+        }
+      ]
+    },
+    "sbomSchema": {
+      "description": "Schema for signed software bill of materials objects",
+      "type": "object",
+      "title": "SBOM Schema",
+      "oneOf": [
+        {
+          "$ref": "#/definitions/sbomV001Schema"
+        }
+      ],
+      "$schema": "http://json-schema.org/draft-07/schema",
+      "$id": "http://rekor.sigstore.dev/types/sbom/sbom_schema.json"
+    },
+    "sbomV001Schema": {
+      "description": "Schema for signed SPDX or CycloneDX documents",
+      "type": "object",
+      "title": "SBOM v0.0.1 Schema",
+      "required": [
+        "signature",
+        "document"
+      ],
+      "properties": {
+        "document": {
+          "description": "Information about the SBOM document associated with the entry",
+          "type": "object",
+          "oneOf": [
+            {
+              "required": [
+                "url"
+              ]
+            },
+            {
+              "required": [
+                "content"
+              ]
+            }
+          ],
+          "properties": {
+            "content": {
+              "description": "Specifies the document inline within the entry",
+              "type": "string",
+              "format": "byte",
+              "writeOnly": true
+            },
+            "format": {
+              "description": "The SBOM format the document is written in",
+              "type": "string",
+              "enum": [
+                "spdx",
+                "cyclonedx"
+              ],
+              "readOnly": true
+            },
+            "hash": {
+              "description": "Specifies the hash algorithm and value for the document",
+              "type": "object",
+              "required": [
+                "algorithm",
+                "value"
+              ],
+              "properties": {
+                "algorithm": {
+                  "description": "The hashing function used to compute the hash value",
+                  "type": "string",
+                  "enum": [
+                    "sha256"
+                  ]
+                },
+                "value": {
+                  "description": "The hash value for the document",
+                  "type": "string"
+                }
+              }
+            },
+            "specVersion": {
+              "description": "The version of the SBOM specification the document conforms to",
+              "type": "string",
+              "readOnly": true
+            },
+            "url": {
+              "description": "Specifies the location of the document",
+              "type": "string",
+              "format": "uri",
+              "writeOnly": true
+            }
+          }
+        },
+        "signature": {
+          "description": "Information about the detached signature over the document",
+          "type": "object",
+          "oneOf": [
+            {
+              "required": [
+                "format",
+                "publicKey",
+                "url"
+              ]
+            },
+            {
+              "required": [
+                "format",
+                "publicKey",
+                "content"
+              ]
+            }
+          ],
+          "properties": {
+            "content": {
+              "description": "Specifies the content of the signature inline within the document",
+              "type": "string",
+              "format": "byte"
+            },
+            "format": {
+              "description": "Specifies the format of the signature",
+              "type": "string",
+              "enum": [
+                "pgp",
+                "minisign",
+                "x509",
+                "ssh"
+              ]
+            },
+            "publicKey": {
+              "description": "The public key that can verify the signature",
+              "type": "object",
+              "oneOf": [
+                {
+                  "required": [
+                    "url"
+                  ]
+                },
+                {
+                  "required": [
+                    "content"
+                  ]
+                }
+              ],
+              "properties": {
+                "content": {
+                  "description": "Specifies the content of the public key inline within the document",
+                  "type": "string",
+                  "format": "byte"
+                },
+                "url": {
+                  "description": "Specifies the location of the public key",
+                  "type": "string",
+                  "format": "uri",
+                  "writeOnly": true
+                }
+              }
+            },
+            "url": {
+              "description": "Specifies the location of the signature",
+              "type": "string",
+              "format": "uri",
+              "writeOnly": true
+            }
+          }
+        }
+      },
+      "$schema": "http://json-schema.org/draft-07/schema",
+      "$id": "http://rekor.sigstore.dev/types/sbom/sbom_v0_0_1_schema.json"
+    },
     "tuf": {
       "description": "TUF metadata",
       "type": "object",
@@ -3690,6 +5616,12 @@ func init() {
     },
     "NotImplemented": {
       "description": "The content requested is not implemented"
+    },
+    "UnprocessableEntity": {
+      "description": "The entry was rejected by the admission policy of the server",
+      "schema": {
+        "$ref": "#/definitions/AdmissionError"
+      }
     }
   }
 }`))
@@ -231,6 +231,50 @@ func (o *CreateLogEntryConflict) WriteResponse(rw http.ResponseWriter, producer
 	}
 }
 
+// CreateLogEntryUnprocessableEntityCode is the HTTP code returned for type CreateLogEntryUnprocessableEntity
+const CreateLogEntryUnprocessableEntityCode int = 422
+
+/*CreateLogEntryUnprocessableEntity The entry was rejected by the admission policy of the server
+
+swagger:response createLogEntryUnprocessableEntity
+*/
+type CreateLogEntryUnprocessableEntity struct {
+
+	/*
+	  In: Body
+	*/
+	Payload *models.AdmissionError `json:"body,omitempty"`
+}
+
+// NewCreateLogEntryUnprocessableEntity creates CreateLogEntryUnprocessableEntity with default headers values
+func NewCreateLogEntryUnprocessableEntity() *CreateLogEntryUnprocessableEntity {
+
+	return &CreateLogEntryUnprocessableEntity{}
+}
+
+// WithPayload adds the payload to the create log entry unprocessable entity response
+func (o *CreateLogEntryUnprocessableEntity) WithPayload(payload *models.AdmissionError) *CreateLogEntryUnprocessableEntity {
+	o.Payload = payload
+	return o
+}
+
+// SetPayload sets the payload to the create log entry unprocessable entity response
+func (o *CreateLogEntryUnprocessableEntity) SetPayload(payload *models.AdmissionError) {
+	o.Payload = payload
+}
+
+// WriteResponse to the client
+func (o *CreateLogEntryUnprocessableEntity) WriteResponse(rw http.ResponseWriter, producer runtime.Producer) {
+
+	rw.WriteHeader(422)
+	if o.Payload != nil {
+		payload := o.Payload
+		if err := producer.Produce(rw, payload); err != nil {
+			panic(err) // let the recovery middleware deal with this
+		}
+	}
+}
+
 /*CreateLogEntryDefault There was an internal error in the server while processing the request
 
 swagger:response createLogEntryDefault
@@ -29,6 +29,8 @@ import (
 	"github.com/go-openapi/errors"
 	"github.com/go-openapi/runtime"
 	"github.com/go-openapi/runtime/middleware"
+	"github.com/go-openapi/strfmt"
+	"github.com/go-openapi/swag"
 	"github.com/go-openapi/validate"
 
 	"github.com/sigstore/rekor/pkg/generated/models"
@@ -56,6 +58,15 @@ type SearchLogQueryParams struct {
 	  In: body
 	*/
 	Entry *models.SearchLogQuery
+	/*Maximum number of results to return; the server returns no more than its maximum page size
+	  Minimum: 1
+	  In: query
+	*/
+	PageSize *int64
+	/*Token from the X-Rekor-Next-Page-Token header of the previous page, which must be requested with the same query
+	  In: query
+	*/
+	PageToken *string
 }
 
 // BindRequest both binds and validates a request, it assumes that complex things implement a Validatable(strfmt.Registry) error interface
@@ -67,6 +78,8 @@ func (o *SearchLogQueryParams) BindRequest(r *http.Request, route *middleware.Ma
 
 	o.HTTPRequest = r
 
+	qs := runtime.Values(r.URL.Query())
+
 	if runtime.HasBody(r) {
 		defer r.Body.Close()
 		var body models.SearchLogQuery
@@ -94,8 +107,73 @@ func (o *SearchLogQueryParams) BindRequest(r *http.Request, route *middleware.Ma
 	} else {
 		res = append(res, errors.Required("entry", "body", ""))
 	}
+
+	qPageSize, qhkPageSize, _ := qs.GetOK("pageSize")
+	if err := o.bindPageSize(qPageSize, qhkPageSize, route.Formats); err != nil {
+		res = append(res, err)
+	}
+
+	qPageToken, qhkPageToken, _ := qs.GetOK("pageToken")
+	if err := o.bindPageToken(qPageToken, qhkPageToken, route.Formats); err != nil {
+		res = append(res, err)
+	}
 	if len(res) > 0 {
 		return errors.CompositeValidationError(res...)
 	}
 	return nil
 }
+
+// bindPageSize binds and validates parameter PageSize from query.
+func (o *SearchLogQueryParams) bindPageSize(rawData []string, hasKey bool, formats strfmt.Registry) error {
+	var raw string
+	if len(rawData) > 0 {
+		raw = rawData[len(rawData)-1]
+	}
+
+	// Required: false
+	// AllowEmptyValue: false
+
+	if raw == "" { // empty values pass all other validations
+		return nil
+	}
+
+	value, err := swag.ConvertInt64(raw)
+	if err != nil {
+		return errors.InvalidType("pageSize", "query", "int64", raw)
+	}
+	o.PageSize = &value
+
+	if err := o.validatePageSize(formats); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// validatePageSize carries on validations for parameter PageSize
+func (o *SearchLogQueryParams) validatePageSize(formats strfmt.Registry) error {
+
+	if err := validate.MinimumInt("pageSize", "query", *o.PageSize, 1, false); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// bindPageToken binds and validates parameter PageToken from query.
+func (o *SearchLogQueryParams) bindPageToken(rawData []string, hasKey bool, formats strfmt.Registry) error {
+	var raw string
+	if len(rawData) > 0 {
+		raw = rawData[len(rawData)-1]
+	}
+
+	// Required: false
+	// AllowEmptyValue: false
+
+	if raw == "" { // empty values pass all other validations
+		return nil
+	}
+	o.PageToken = &raw
+
+	return nil
+}
@@ -32,11 +32,16 @@ import (
 // SearchLogQueryOKCode is the HTTP code returned for type SearchLogQueryOK
 const SearchLogQueryOKCode int = 200
 
-/*SearchLogQueryOK Returns zero or more entries from the transparency log, according to how many were included in request query
+/*SearchLogQueryOK Returns zero or more entries from the transparency log, according to how many were included in request query;
+each page covers at most pageSize of the UUIDs, entries and log indexes of the query, in that order
 
 swagger:response searchLogQueryOK
 */
 type SearchLogQueryOK struct {
+	/*Token to pass as pageToken to fetch the next page of results; absent on the last page
+
+	 */
+	XRekorNextPageToken string `json:"X-Rekor-Next-Page-Token"`
 
 	/*
 	  In: Body
@@ -50,6 +55,17 @@ func NewSearchLogQueryOK() *SearchLogQueryOK {
 	return &SearchLogQueryOK{}
 }
 
+// WithXRekorNextPageToken adds the xRekorNextPageToken to the search log query o k response
+func (o *SearchLogQueryOK) WithXRekorNextPageToken(xRekorNextPageToken string) *SearchLogQueryOK {
+	o.XRekorNextPageToken = xRekorNextPageToken
+	return o
+}
+
+// SetXRekorNextPageToken sets the xRekorNextPageToken to the search log query o k response
+func (o *SearchLogQueryOK) SetXRekorNextPageToken(xRekorNextPageToken string) {
+	o.XRekorNextPageToken = xRekorNextPageToken
+}
+
 // WithPayload adds the payload to the search log query o k response
 func (o *SearchLogQueryOK) WithPayload(payload []models.LogEntry) *SearchLogQueryOK {
 	o.Payload = payload
@@ -64,6 +80,13 @@ func (o *SearchLogQueryOK) SetPayload(payload []models.LogEntry) {
 // WriteResponse to the client
 func (o *SearchLogQueryOK) WriteResponse(rw http.ResponseWriter, producer runtime.Producer) {
 
+	// response header X-Rekor-Next-Page-Token
+
+	xRekorNextPageToken := o.XRekorNextPageToken
+	if xRekorNextPageToken != "" {
+		rw.Header().Set("X-Rekor-Next-Page-Token", xRekorNextPageToken)
+	}
+
 	rw.WriteHeader(200)
 	payload := o.Payload
 	if payload == nil {
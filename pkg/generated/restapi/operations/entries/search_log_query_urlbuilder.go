@@ -25,11 +25,18 @@ import (
 	"errors"
 	"net/url"
 	golangswaggerpaths "path"
+
+	"github.com/go-openapi/swag"
 )
 
 // SearchLogQueryURL generates an URL for the search log query operation
 type SearchLogQueryURL struct {
+	PageSize  *int64
+	PageToken *string
+
 	_basePath string
+	// avoid unkeyed usage
+	_ struct{}
 }
 
 // WithBasePath sets the base path for this url builder, only required when it's different from the
@@ -56,6 +63,26 @@ func (o *SearchLogQueryURL) Build() (*url.URL, error) {
 	_basePath := o._basePath
 	_result.Path = golangswaggerpaths.Join(_basePath, _path)
 
+	qs := make(url.Values)
+
+	var pageSizeQ string
+	if o.PageSize != nil {
+		pageSizeQ = swag.FormatInt64(*o.PageSize)
+	}
+	if pageSizeQ != "" {
+		qs.Set("pageSize", pageSizeQ)
+	}
+
+	var pageTokenQ string
+	if o.PageToken != nil {
+		pageTokenQ = *o.PageToken
+	}
+	if pageTokenQ != "" {
+		qs.Set("pageToken", pageTokenQ)
+	}
+
+	_result.RawQuery = qs.Encode()
+
 	return &_result, nil
 }
 
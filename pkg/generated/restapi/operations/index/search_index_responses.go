@@ -32,11 +32,16 @@ import (
 // SearchIndexOKCode is the HTTP code returned for type SearchIndexOK
 const SearchIndexOKCode int = 200
 
-/*SearchIndexOK Returns zero or more entry UUIDs from the transparency log based on search query
+/*SearchIndexOK Returns zero or more entry UUIDs from the transparency log based on search query, in ascending order and each
+at most once, one page at a time
 
 swagger:response searchIndexOK
 */
 type SearchIndexOK struct {
+	/*Token to pass as pageToken to fetch the next page of results; absent on the last page
+
+	 */
+	XRekorNextPageToken string `json:"X-Rekor-Next-Page-Token"`
 
 	/*
 	  In: Body
@@ -50,6 +55,17 @@ func NewSearchIndexOK() *SearchIndexOK {
 	return &SearchIndexOK{}
 }
 
+// WithXRekorNextPageToken adds the xRekorNextPageToken to the search index o k response
+func (o *SearchIndexOK) WithXRekorNextPageToken(xRekorNextPageToken string) *SearchIndexOK {
+	o.XRekorNextPageToken = xRekorNextPageToken
+	return o
+}
+
+// SetXRekorNextPageToken sets the xRekorNextPageToken to the search index o k response
+func (o *SearchIndexOK) SetXRekorNextPageToken(xRekorNextPageToken string) {
+	o.XRekorNextPageToken = xRekorNextPageToken
+}
+
 // WithPayload adds the payload to the search index o k response
 func (o *SearchIndexOK) WithPayload(payload []string) *SearchIndexOK {
 	o.Payload = payload
@@ -64,6 +80,13 @@ func (o *SearchIndexOK) SetPayload(payload []string) {
 // WriteResponse to the client
 func (o *SearchIndexOK) WriteResponse(rw http.ResponseWriter, producer runtime.Producer) {
 
+	// response header X-Rekor-Next-Page-Token
+
+	xRekorNextPageToken := o.XRekorNextPageToken
+	if xRekorNextPageToken != "" {
+		rw.Header().Set("X-Rekor-Next-Page-Token", xRekorNextPageToken)
+	}
+
 	rw.WriteHeader(200)
 	payload := o.Payload
 	if payload == nil {
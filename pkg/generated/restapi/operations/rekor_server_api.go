@@ -75,21 +75,31 @@ func NewRekorServerAPI(spec *loads.Document) *RekorServerAPI {
 		ApplicationTimestampReplyProducer: runtime.ProducerFunc(func(w io.Writer, data interface{}) error {
 			return errors.NotImplemented("applicationTimestampReply producer has not yet been implemented")
 		}),
+		ApplicationXNdjsonProducer: runtime.ProducerFunc(func(w io.Writer, data interface{}) error {
+			return errors.NotImplemented("applicationXNdjson producer has not yet been implemented")
+		}),
 		ApplicationXPemFileProducer: runtime.ProducerFunc(func(w io.Writer, data interface{}) error {
 			return errors.NotImplemented("applicationXPemFile producer has not yet been implemented")
 		}),
 		JSONProducer: runtime.JSONProducer(),
+		TxtProducer:  runtime.TextProducer(),
 		YamlProducer: yamlpc.YAMLProducer(),
 
 		EntriesCreateLogEntryHandler: entries.CreateLogEntryHandlerFunc(func(params entries.CreateLogEntryParams) middleware.Responder {
 			return middleware.NotImplemented("operation entries.CreateLogEntry has not yet been implemented")
 		}),
+		TlogGetLogCheckpointHandler: tlog.GetLogCheckpointHandlerFunc(func(params tlog.GetLogCheckpointParams) middleware.Responder {
+			return middleware.NotImplemented("operation tlog.GetLogCheckpoint has not yet been implemented")
+		}),
 		EntriesGetLogEntryByIndexHandler: entries.GetLogEntryByIndexHandlerFunc(func(params entries.GetLogEntryByIndexParams) middleware.Responder {
 			return middleware.NotImplemented("operation entries.GetLogEntryByIndex has not yet been implemented")
 		}),
 		EntriesGetLogEntryByUUIDHandler: entries.GetLogEntryByUUIDHandlerFunc(func(params entries.GetLogEntryByUUIDParams) middleware.Responder {
 			return middleware.NotImplemented("operation entries.GetLogEntryByUUID has not yet been implemented")
 		}),
+		EntriesGetLogEntryProofHandler: entries.GetLogEntryProofHandlerFunc(func(params entries.GetLogEntryProofParams) middleware.Responder {
+			return middleware.NotImplemented("operation entries.GetLogEntryProof has not yet been implemented")
+		}),
 		TlogGetLogInfoHandler: tlog.GetLogInfoHandlerFunc(func(params tlog.GetLogInfoParams) middleware.Responder {
 			return middleware.NotImplemented("operation tlog.GetLogInfo has not yet been implemented")
 		}),
@@ -114,6 +124,12 @@ func NewRekorServerAPI(spec *loads.Document) *RekorServerAPI {
 		EntriesSearchLogQueryHandler: entries.SearchLogQueryHandlerFunc(func(params entries.SearchLogQueryParams) middleware.Responder {
 			return middleware.NotImplemented("operation entries.SearchLogQuery has not yet been implemented")
 		}),
+		EntriesStreamLogEntriesHandler: entries.StreamLogEntriesHandlerFunc(func(params entries.StreamLogEntriesParams) middleware.Responder {
+			return middleware.NotImplemented("operation entries.StreamLogEntries has not yet been implemented")
+		}),
+		EntriesValidateLogEntryHandler: entries.ValidateLogEntryHandlerFunc(func(params entries.ValidateLogEntryParams) middleware.Responder {
+			return middleware.NotImplemented("operation entries.ValidateLogEntry has not yet been implemented")
+		}),
 	}
 }
 
@@ -158,22 +174,32 @@ type RekorServerAPI struct {
 	// ApplicationTimestampReplyProducer registers a producer for the following mime types:
 	//   - application/timestamp-reply
 	ApplicationTimestampReplyProducer runtime.Producer
+	// ApplicationXNdjsonProducer registers a producer for the following mime types:
+	//   - application/x-ndjson
+	ApplicationXNdjsonProducer runtime.Producer
 	// ApplicationXPemFileProducer registers a producer for the following mime types:
 	//   - application/x-pem-file
 	ApplicationXPemFileProducer runtime.Producer
 	// JSONProducer registers a producer for the following mime types:
 	//   - application/json
 	JSONProducer runtime.Producer
+	// TxtProducer registers a producer for the following mime types:
+	//   - text/plain
+	TxtProducer runtime.Producer
 	// YamlProducer registers a producer for the following mime types:
 	//   - application/yaml
 	YamlProducer runtime.Producer
 
 	// EntriesCreateLogEntryHandler sets the operation handler for the create log entry operation
 	EntriesCreateLogEntryHandler entries.CreateLogEntryHandler
+	// TlogGetLogCheckpointHandler sets the operation handler for the get log checkpoint operation
+	TlogGetLogCheckpointHandler tlog.GetLogCheckpointHandler
 	// EntriesGetLogEntryByIndexHandler sets the operation handler for the get log entry by index operation
 	EntriesGetLogEntryByIndexHandler entries.GetLogEntryByIndexHandler
 	// EntriesGetLogEntryByUUIDHandler sets the operation handler for the get log entry by UUID operation
 	EntriesGetLogEntryByUUIDHandler entries.GetLogEntryByUUIDHandler
+	// EntriesGetLogEntryProofHandler sets the operation handler for the get log entry proof operation
+	EntriesGetLogEntryProofHandler entries.GetLogEntryProofHandler
 	// TlogGetLogInfoHandler sets the operation handler for the get log info operation
 	TlogGetLogInfoHandler tlog.GetLogInfoHandler
 	// TlogGetLogProofHandler sets the operation handler for the get log proof operation
@@ -190,6 +216,10 @@ type RekorServerAPI struct {
 	IndexSearchIndexHandler index.SearchIndexHandler
 	// EntriesSearchLogQueryHandler sets the operation handler for the search log query operation
 	EntriesSearchLogQueryHandler entries.SearchLogQueryHandler
+	// EntriesStreamLogEntriesHandler sets the operation handler for the stream log entries operation
+	EntriesStreamLogEntriesHandler entries.StreamLogEntriesHandler
+	// EntriesValidateLogEntryHandler sets the operation handler for the validate log entry operation
+	EntriesValidateLogEntryHandler entries.ValidateLogEntryHandler
 
 	// ServeError is called when an error is received, there is a default handler
 	// but you can set your own with this
@@ -275,12 +305,18 @@ func (o *RekorServerAPI) Validate() error {
 	if o.ApplicationTimestampReplyProducer == nil {
 		unregistered = append(unregistered, "ApplicationTimestampReplyProducer")
 	}
+	if o.ApplicationXNdjsonProducer == nil {
+		unregistered = append(unregistered, "ApplicationXNdjsonProducer")
+	}
 	if o.ApplicationXPemFileProducer == nil {
 		unregistered = append(unregistered, "ApplicationXPemFileProducer")
 	}
 	if o.JSONProducer == nil {
 		unregistered = append(unregistered, "JSONProducer")
 	}
+	if o.TxtProducer == nil {
+		unregistered = append(unregistered, "TxtProducer")
+	}
 	if o.YamlProducer == nil {
 		unregistered = append(unregistered, "YamlProducer")
 	}
@@ -288,12 +324,18 @@ func (o *RekorServerAPI) Validate() error {
 	if o.EntriesCreateLogEntryHandler == nil {
 		unregistered = append(unregistered, "entries.CreateLogEntryHandler")
 	}
+	if o.TlogGetLogCheckpointHandler == nil {
+		unregistered = append(unregistered, "tlog.GetLogCheckpointHandler")
+	}
 	if o.EntriesGetLogEntryByIndexHandler == nil {
 		unregistered = append(unregistered, "entries.GetLogEntryByIndexHandler")
 	}
 	if o.EntriesGetLogEntryByUUIDHandler == nil {
 		unregistered = append(unregistered, "entries.GetLogEntryByUUIDHandler")
 	}
+	if o.EntriesGetLogEntryProofHandler == nil {
+		unregistered = append(unregistered, "entries.GetLogEntryProofHandler")
+	}
 	if o.TlogGetLogInfoHandler == nil {
 		unregistered = append(unregistered, "tlog.GetLogInfoHandler")
 	}
@@ -318,6 +360,12 @@ func (o *RekorServerAPI) Validate() error {
 	if o.EntriesSearchLogQueryHandler == nil {
 		unregistered = append(unregistered, "entries.SearchLogQueryHandler")
 	}
+	if o.EntriesStreamLogEntriesHandler == nil {
+		unregistered = append(unregistered, "entries.StreamLogEntriesHandler")
+	}
+	if o.EntriesValidateLogEntryHandler == nil {
+		unregistered = append(unregistered, "entries.ValidateLogEntryHandler")
+	}
 
 	if len(unregistered) > 0 {
 		return fmt.Errorf("missing registration: %s", strings.Join(unregistered, ", "))
@@ -372,10 +420,14 @@ func (o *RekorServerAPI) ProducersFor(mediaTypes []string) map[string]runtime.Pr
 			result["application/pem-certificate-chain"] = o.ApplicationPemCertificateChainProducer
 		case "application/timestamp-reply":
 			result["application/timestamp-reply"] = o.ApplicationTimestampReplyProducer
+		case "application/x-ndjson":
+			result["application/x-ndjson"] = o.ApplicationXNdjsonProducer
 		case "application/x-pem-file":
 			result["application/x-pem-file"] = o.ApplicationXPemFileProducer
 		case "application/json":
 			result["application/json"] = o.JSONProducer
+		case "text/plain":
+			result["text/plain"] = o.TxtProducer
 		case "application/yaml":
 			result["application/yaml"] = o.YamlProducer
 		}
@@ -425,6 +477,10 @@ func (o *RekorServerAPI) initHandlerCache() {
 	if o.handlers["GET"] == nil {
 		o.handlers["GET"] = make(map[string]http.Handler)
 	}
+	o.handlers["GET"]["/api/v1/log/checkpoint"] = tlog.NewGetLogCheckpoint(o.context, o.TlogGetLogCheckpointHandler)
+	if o.handlers["GET"] == nil {
+		o.handlers["GET"] = make(map[string]http.Handler)
+	}
 	o.handlers["GET"]["/api/v1/log/entries"] = entries.NewGetLogEntryByIndex(o.context, o.EntriesGetLogEntryByIndexHandler)
 	if o.handlers["GET"] == nil {
 		o.handlers["GET"] = make(map[string]http.Handler)
@@ -433,6 +489,10 @@ func (o *RekorServerAPI) initHandlerCache() {
 	if o.handlers["GET"] == nil {
 		o.handlers["GET"] = make(map[string]http.Handler)
 	}
+	o.handlers["GET"]["/api/v1/log/entries/{entryUUID}/proof"] = entries.NewGetLogEntryProof(o.context, o.EntriesGetLogEntryProofHandler)
+	if o.handlers["GET"] == nil {
+		o.handlers["GET"] = make(map[string]http.Handler)
+	}
 	o.handlers["GET"]["/api/v1/log"] = tlog.NewGetLogInfo(o.context, o.TlogGetLogInfoHandler)
 	if o.handlers["GET"] == nil {
 		o.handlers["GET"] = make(map[string]http.Handler)
@@ -462,6 +522,14 @@ func (o *RekorServerAPI) initHandlerCache() {
 		o.handlers["POST"] = make(map[string]http.Handler)
 	}
 	o.handlers["POST"]["/api/v1/log/entries/retrieve"] = entries.NewSearchLogQuery(o.context, o.EntriesSearchLogQueryHandler)
+	if o.handlers["GET"] == nil {
+		o.handlers["GET"] = make(map[string]http.Handler)
+	}
+	o.handlers["GET"]["/api/v1/log/entries/stream"] = entries.NewStreamLogEntries(o.context, o.EntriesStreamLogEntriesHandler)
+	if o.handlers["POST"] == nil {
+		o.handlers["POST"] = make(map[string]http.Handler)
+	}
+	o.handlers["POST"]["/api/v1/log/entries/validate"] = entries.NewValidateLogEntry(o.context, o.EntriesValidateLogEntryHandler)
 }
 
 // Serve creates a http handler to serve the API over HTTP
@@ -117,6 +117,25 @@ func SupportedFormats() []string {
 	return formats
 }
 
+// FormatOf returns the format of a public key created by one of the supported PKI implementations
+func FormatOf(k PublicKey) (Format, bool) {
+	switch k.(type) {
+	case *pgp.PublicKey, pgp.PublicKey:
+		return PGP, true
+	case *minisign.PublicKey, minisign.PublicKey:
+		return Minisign, true
+	case *ssh.PublicKey, ssh.PublicKey:
+		return SSH, true
+	case *x509.PublicKey, x509.PublicKey:
+		return X509, true
+	case *pkcs7.PublicKey, pkcs7.PublicKey:
+		return PKCS7, true
+	case *tuf.PublicKey, tuf.PublicKey:
+		return Tuf, true
+	}
+	return "", false
+}
+
 func (a ArtifactFactory) NewPublicKey(r io.Reader) (PublicKey, error) {
 	return a.impl.newPubKey(r)
 }
@@ -101,7 +101,12 @@ func TestFactoryNewKey(t *testing.T) {
 			}
 			if factory != nil {
 				keyFile, _ := os.Open(tc.keyFile)
-				_, newKeyErr := factory.NewPublicKey(keyFile)
+				key, newKeyErr := factory.NewPublicKey(keyFile)
+				if newKeyErr == nil {
+					if format, ok := FormatOf(key); !ok || format != Format(tc.format) {
+						t.Errorf("FormatOf() = %v, %v; want %v", format, ok, tc.format)
+					}
+				}
 
 				sigFile, _ := os.Open(tc.sigFile)
 				_, newSigErr := factory.NewSignature(sigFile)
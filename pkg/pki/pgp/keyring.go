@@ -0,0 +1,215 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgp
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/openpgp"
+	"golang.org/x/crypto/openpgp/packet"
+)
+
+// KeyPolicy controls which keys of a keyring may verify a signature. Keys which expired before the
+// signature was made, or which have been revoked, are rejected unless allowed here; keys revoked as
+// compromised or without a reason are always rejected.
+type KeyPolicy struct {
+	// AllowExpired accepts signatures made after the signing key (or its primary key) expired
+	AllowExpired bool
+	// AllowRetired accepts signatures made before the signing key was revoked as superseded or no
+	// longer used
+	AllowRetired bool
+}
+
+var (
+	keyPolicyMu sync.RWMutex
+	keyPolicy   KeyPolicy
+)
+
+// SetKeyPolicy configures the policy applied to keys when verifying PGP signatures
+func SetKeyPolicy(p KeyPolicy) {
+	keyPolicyMu.Lock()
+	defer keyPolicyMu.Unlock()
+	keyPolicy = p
+}
+
+func currentKeyPolicy() KeyPolicy {
+	keyPolicyMu.RLock()
+	defer keyPolicyMu.RUnlock()
+	return keyPolicy
+}
+
+// reasons for revocation which do not imply that the key was compromised, see RFC 4880, section 5.2.3.23
+const (
+	reasonSuperseded uint8 = 1
+	reasonRetired    uint8 = 3
+)
+
+// policyKeyRing is a keyring of one or more entities whose primary keys and subkeys are only returned
+// if they are accepted by the key policy
+type policyKeyRing struct {
+	entities openpgp.EntityList
+	policy   KeyPolicy
+	// creation times of the signatures being verified by the ID of the key which made them; keys are
+	// judged as of now for signatures not listed
+	sigTimes map[uint64]time.Time
+	// skips the expiry check, for keyrings verifying signatures whose creation times are not known;
+	// judging those keys as of now would reject signatures made while they were valid
+	ignoreExpiry bool
+	// why keys matching a requested ID were rejected
+	rejected []string
+}
+
+// KeysById implements the openpgp.KeyRing interface
+func (r *policyKeyRing) KeysById(id uint64) []openpgp.Key {
+	return r.KeysByIdUsage(id, 0)
+}
+
+// KeysByIdUsage implements the openpgp.KeyRing interface
+func (r *policyKeyRing) KeysByIdUsage(id uint64, requiredUsage byte) []openpgp.Key {
+	var keys []openpgp.Key
+	for _, key := range r.entities.KeysById(id) {
+		if err := r.check(key, requiredUsage); err != nil {
+			r.rejected = append(r.rejected, fmt.Sprintf("key %X: %v", id, err))
+			continue
+		}
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// DecryptionKeys implements the openpgp.KeyRing interface
+func (r *policyKeyRing) DecryptionKeys() []openpgp.Key {
+	return r.entities.DecryptionKeys()
+}
+
+// rejectionError describes why no key of the keyring could verify the signature, if any were rejected
+func (r *policyKeyRing) rejectionError() error {
+	if len(r.rejected) == 0 {
+		return nil
+	}
+	return fmt.Errorf("no acceptable key to verify the PGP signature: %s", strings.Join(r.rejected, "; "))
+}
+
+func (r *policyKeyRing) check(key openpgp.Key, requiredUsage byte) error {
+	sigTime, known := r.sigTimes[key.PublicKey.KeyId]
+	if !known {
+		sigTime = time.Now()
+	}
+	if known && sigTime.Before(key.PublicKey.CreationTime) {
+		return errors.New("signature was made before the key was created")
+	}
+
+	revocations := append([]*packet.Signature{}, key.Entity.Revocations...)
+	// a revoked subkey carries its revocation in place of the binding signature
+	subkeyRevoked := key.PublicKey != key.Entity.PrimaryKey && key.SelfSignature != nil &&
+		(key.SelfSignature.SigType == packet.SigTypeSubkeyRevocation || key.SelfSignature.RevocationReason != nil)
+	if subkeyRevoked {
+		revocations = append(revocations, key.SelfSignature)
+	}
+	for _, rev := range revocations {
+		if !r.policy.AllowRetired || !isRetirement(rev) {
+			return fmt.Errorf("key has been revoked%s", revocationReason(rev))
+		}
+		if known && !sigTime.Before(rev.CreationTime) {
+			return fmt.Errorf("signature was made after the key was retired on %s", rev.CreationTime.UTC().Format(time.RFC3339))
+		}
+	}
+
+	if !subkeyRevoked && key.SelfSignature != nil && requiredUsage != 0 && key.SelfSignature.FlagsValid &&
+		keyUsage(key.SelfSignature)&requiredUsage != requiredUsage {
+		return errors.New("key is not allowed to be used for this purpose")
+	}
+
+	if r.policy.AllowExpired || r.ignoreExpiry {
+		return nil
+	}
+	expiries := []time.Time{}
+	if expiry, ok := keyExpiry(key.Entity.PrimaryKey, primarySelfSignature(key.Entity)); ok {
+		expiries = append(expiries, expiry)
+	}
+	if key.PublicKey != key.Entity.PrimaryKey && !subkeyRevoked {
+		if expiry, ok := keyExpiry(key.PublicKey, key.SelfSignature); ok {
+			expiries = append(expiries, expiry)
+		}
+	}
+	for _, expiry := range expiries {
+		if sigTime.After(expiry) {
+			if known {
+				return fmt.Errorf("key expired on %s, before the signature was made", expiry.UTC().Format(time.RFC3339))
+			}
+			return fmt.Errorf("key expired on %s", expiry.UTC().Format(time.RFC3339))
+		}
+	}
+	return nil
+}
+
+func isRetirement(rev *packet.Signature) bool {
+	return rev.RevocationReason != nil && (*rev.RevocationReason == reasonSuperseded || *rev.RevocationReason == reasonRetired)
+}
+
+func revocationReason(rev *packet.Signature) string {
+	if rev.RevocationReasonText != "" {
+		return fmt.Sprintf(" (%s)", rev.RevocationReasonText)
+	}
+	return ""
+}
+
+// keyExpiry returns when a key expires according to its self-signature; the lifetime counts from
+// the creation of the key
+func keyExpiry(pub *packet.PublicKey, selfSig *packet.Signature) (time.Time, bool) {
+	if pub == nil || selfSig == nil || selfSig.KeyLifetimeSecs == nil || *selfSig.KeyLifetimeSecs == 0 {
+		return time.Time{}, false
+	}
+	return pub.CreationTime.Add(time.Duration(*selfSig.KeyLifetimeSecs) * time.Second), true
+}
+
+// primarySelfSignature returns the self-signature of the primary user ID, which carries the
+// expiry and flags of the primary key
+func primarySelfSignature(e *openpgp.Entity) *packet.Signature {
+	var selfSig *packet.Signature
+	for _, ident := range e.Identities {
+		if ident.SelfSignature == nil {
+			continue
+		}
+		if selfSig == nil {
+			selfSig = ident.SelfSignature
+		} else if ident.SelfSignature.IsPrimaryId != nil && *ident.SelfSignature.IsPrimaryId {
+			return ident.SelfSignature
+		}
+	}
+	return selfSig
+}
+
+func keyUsage(sig *packet.Signature) byte {
+	var usage byte
+	if sig.FlagCertify {
+		usage |= packet.KeyFlagCertify
+	}
+	if sig.FlagSign {
+		usage |= packet.KeyFlagSign
+	}
+	if sig.FlagEncryptCommunications {
+		usage |= packet.KeyFlagEncryptCommunications
+	}
+	if sig.FlagEncryptStorage {
+		usage |= packet.KeyFlagEncryptStorage
+	}
+	return usage
+}
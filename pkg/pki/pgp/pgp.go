@@ -19,17 +19,22 @@ import (
 	"bufio"
 	"bytes"
 	"context"
+	"crypto"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"time"
 
 	validator "github.com/go-playground/validator/v10"
 	"golang.org/x/crypto/openpgp"
 	"golang.org/x/crypto/openpgp/armor"
+	pgperrors "golang.org/x/crypto/openpgp/errors"
 	"golang.org/x/crypto/openpgp/packet"
 
 	sigsig "github.com/sigstore/sigstore/pkg/signature"
+
+	"github.com/sigstore/rekor/pkg/util"
 )
 
 // Signature Signature that follows the PGP standard; supports both armored & binary detached signatures
@@ -87,8 +92,7 @@ func FetchSignature(ctx context.Context, url string) (*Signature, error) {
 	if err != nil {
 		return nil, fmt.Errorf("error initializing fetch for PGP signature: %w", err)
 	}
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := util.FetchClient.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("error fetching PGP signature: %w", err)
 	}
@@ -150,13 +154,48 @@ func (s Signature) Verify(r io.Reader, k interface{}, opts ...sigsig.VerifyOptio
 		verifyFn = openpgp.CheckArmoredDetachedSignature
 	}
 
-	if _, err := verifyFn(key.key, r, bytes.NewReader(s.signature)); err != nil {
+	// any primary key or subkey of the entities in the keyring may have made the signature
+	keyring := &policyKeyRing{entities: key.key, policy: currentKeyPolicy(), sigTimes: s.creationTimes()}
+	if _, err := verifyFn(keyring, r, bytes.NewReader(s.signature)); err != nil {
+		if errors.Is(err, pgperrors.ErrUnknownIssuer) {
+			if rejected := keyring.rejectionError(); rejected != nil {
+				return rejected
+			}
+		}
 		return err
 	}
 
 	return nil
 }
 
+// creationTimes returns when each signature packet was made, by the ID of the key which made it
+func (s Signature) creationTimes() map[uint64]time.Time {
+	times := map[uint64]time.Time{}
+	var sigReader io.Reader = bytes.NewReader(s.signature)
+	if s.isArmored {
+		sigBlock, err := armor.Decode(sigReader)
+		if err != nil {
+			return times
+		}
+		sigReader = sigBlock.Body
+	}
+	packets := packet.NewReader(sigReader)
+	for {
+		p, err := packets.Next()
+		if err != nil {
+			return times
+		}
+		switch sig := p.(type) {
+		case *packet.Signature:
+			if sig.IssuerKeyId != nil {
+				times[*sig.IssuerKeyId] = sig.CreationTime
+			}
+		case *packet.SignatureV3:
+			times[sig.IssuerKeyId] = sig.CreationTime
+		}
+	}
+}
+
 // PublicKey Public Key that follows the PGP standard; supports both armored & binary detached signatures
 type PublicKey struct {
 	key openpgp.EntityList
@@ -229,8 +268,7 @@ func FetchPublicKey(ctx context.Context, url string) (*PublicKey, error) {
 	if err != nil {
 		return nil, fmt.Errorf("error fetching PGP public key: %w", err)
 	}
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := util.FetchClient.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("error fetching PGP public key: %w", err)
 	}
@@ -273,12 +311,15 @@ func (k PublicKey) CanonicalValue() ([]byte, error) {
 	return canonicalBuffer.Bytes(), nil
 }
 
+// KeyRing returns the entities of the key as a keyring for verifying signatures outside of this package,
+// subject to the key policy. As the time of those signatures is not known, keys are not checked for
+// expiry, and retired keys are accepted if the policy allows them at all; revoked keys are rejected.
 func (k PublicKey) KeyRing() (openpgp.KeyRing, error) {
 	if k.key == nil {
 		return nil, errors.New("PGP public key has not been initialized")
 	}
 
-	return k.key, nil
+	return &policyKeyRing{entities: k.key, policy: currentKeyPolicy(), ignoreExpiry: true}, nil
 }
 
 // EmailAddresses implements the pki.PublicKey interface
@@ -296,3 +337,25 @@ func (k PublicKey) EmailAddresses() []string {
 	}
 	return names
 }
+
+// Subjects implements the pki.PublicKey interface
+func (k PublicKey) Subjects() []string {
+	return k.EmailAddresses()
+}
+
+// CryptoPublicKeys implements the pki.CryptoKeyProvider interface; the primary keys of all entities
+// are returned along with their subkeys
+func (k PublicKey) CryptoPublicKeys() []crypto.PublicKey {
+	var keys []crypto.PublicKey
+	for _, entity := range k.key {
+		if entity.PrimaryKey != nil {
+			keys = append(keys, entity.PrimaryKey.PublicKey)
+		}
+		for _, subkey := range entity.Subkeys {
+			if subkey.PublicKey != nil {
+				keys = append(keys, subkey.PublicKey.PublicKey)
+			}
+		}
+	}
+	return keys
+}
@@ -29,6 +29,7 @@ import (
 	"testing"
 
 	"go.uber.org/goleak"
+	"golang.org/x/crypto/openpgp"
 )
 
 func TestMain(m *testing.M) {
@@ -456,3 +457,110 @@ func TestVerifySignature(t *testing.T) {
 		t.Errorf("expected error when using empty key to verify")
 	}
 }
+
+func TestVerifySignatureKeyPolicy(t *testing.T) {
+	type test struct {
+		caseDesc string
+		sigFile  string
+		keyFile  string
+		policy   KeyPolicy
+		verified bool
+	}
+
+	tests := []test{
+		{caseDesc: "Signature by subkey, keyring with multiple keys", sigFile: "testdata/hello_world.txt.subkey.asc.sig", keyFile: "testdata/keyring_public.pgp", verified: true},
+		{caseDesc: "Signature by second key of keyring", sigFile: "testdata/hello_world.txt.superseded.asc.sig", keyFile: "testdata/keyring_public.pgp", verified: true},
+		{caseDesc: "Signature made while key was valid, key expired since", sigFile: "testdata/hello_world.txt.asc.sig", keyFile: "testdata/valid_armored_public.pgp", verified: true},
+		{caseDesc: "Signature made after key expired", sigFile: "testdata/hello_world.txt.expired.asc.sig", keyFile: "testdata/expired_public.pgp", verified: false},
+		{caseDesc: "Signature made after key expired, expired keys allowed", sigFile: "testdata/hello_world.txt.expired.asc.sig", keyFile: "testdata/expired_public.pgp", policy: KeyPolicy{AllowExpired: true}, verified: true},
+		{caseDesc: "Key superseded after signing", sigFile: "testdata/hello_world.txt.superseded.asc.sig", keyFile: "testdata/superseded_public.pgp", verified: false},
+		{caseDesc: "Key superseded after signing, retired keys allowed", sigFile: "testdata/hello_world.txt.superseded.asc.sig", keyFile: "testdata/superseded_public.pgp", policy: KeyPolicy{AllowRetired: true}, verified: true},
+		{caseDesc: "Key compromised after signing", sigFile: "testdata/hello_world.txt.compromised.asc.sig", keyFile: "testdata/compromised_public.pgp", verified: false},
+		{caseDesc: "Key compromised after signing, retired keys allowed", sigFile: "testdata/hello_world.txt.compromised.asc.sig", keyFile: "testdata/compromised_public.pgp", policy: KeyPolicy{AllowExpired: true, AllowRetired: true}, verified: false},
+	}
+
+	defer SetKeyPolicy(KeyPolicy{})
+	for _, tc := range tests {
+		SetKeyPolicy(tc.policy)
+
+		keyFile, err := os.Open(tc.keyFile)
+		if err != nil {
+			t.Fatalf("%v: error reading keyfile '%v': %v", tc.caseDesc, tc.keyFile, err)
+		}
+		k, err := NewPublicKey(keyFile)
+		keyFile.Close()
+		if err != nil {
+			t.Fatalf("%v: error reading keyfile '%v': %v", tc.caseDesc, tc.keyFile, err)
+		}
+
+		sigFile, err := os.Open(tc.sigFile)
+		if err != nil {
+			t.Fatalf("%v: error reading sigfile '%v': %v", tc.caseDesc, tc.sigFile, err)
+		}
+		s, err := NewSignature(sigFile)
+		sigFile.Close()
+		if err != nil {
+			t.Fatalf("%v: error reading sigfile '%v': %v", tc.caseDesc, tc.sigFile, err)
+		}
+
+		data, err := ioutil.ReadFile("testdata/hello_world.txt")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := s.Verify(bytes.NewReader(data), k); (err == nil) != tc.verified {
+			t.Errorf("%v: unexpected result in verifying signature: %v", tc.caseDesc, err)
+		}
+	}
+}
+
+func TestKeyRingKeyPolicy(t *testing.T) {
+	type test struct {
+		caseDesc string
+		sigFile  string
+		keyFile  string
+		policy   KeyPolicy
+		verified bool
+	}
+
+	// the keyring does not know when signatures were made, so expiry is not checked
+	tests := []test{
+		{caseDesc: "Signature made while key was valid, key expired since", sigFile: "testdata/hello_world.txt.asc.sig", keyFile: "testdata/valid_armored_public.pgp", verified: true},
+		{caseDesc: "Key superseded after signing", sigFile: "testdata/hello_world.txt.superseded.asc.sig", keyFile: "testdata/superseded_public.pgp", verified: false},
+		{caseDesc: "Key superseded after signing, retired keys allowed", sigFile: "testdata/hello_world.txt.superseded.asc.sig", keyFile: "testdata/superseded_public.pgp", policy: KeyPolicy{AllowRetired: true}, verified: true},
+		{caseDesc: "Key compromised after signing, retired keys allowed", sigFile: "testdata/hello_world.txt.compromised.asc.sig", keyFile: "testdata/compromised_public.pgp", policy: KeyPolicy{AllowRetired: true}, verified: false},
+	}
+
+	defer SetKeyPolicy(KeyPolicy{})
+	for _, tc := range tests {
+		SetKeyPolicy(tc.policy)
+
+		keyFile, err := os.Open(tc.keyFile)
+		if err != nil {
+			t.Fatalf("%v: error reading keyfile '%v': %v", tc.caseDesc, tc.keyFile, err)
+		}
+		k, err := NewPublicKey(keyFile)
+		keyFile.Close()
+		if err != nil {
+			t.Fatalf("%v: error reading keyfile '%v': %v", tc.caseDesc, tc.keyFile, err)
+		}
+		keyring, err := k.KeyRing()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		sig, err := os.Open(tc.sigFile)
+		if err != nil {
+			t.Fatalf("%v: error reading sigfile '%v': %v", tc.caseDesc, tc.sigFile, err)
+		}
+		data, err := os.Open("testdata/hello_world.txt")
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = openpgp.CheckArmoredDetachedSignature(keyring, data, sig)
+		sig.Close()
+		data.Close()
+		if (err == nil) != tc.verified {
+			t.Errorf("%v: unexpected result in verifying signature: %v", tc.caseDesc, err)
+		}
+	}
+}
@@ -0,0 +1,196 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgp
+
+import (
+	"context"
+	"crypto/sha1" // #nosec G505 -- required by the Web Key Directory to hash local parts
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	validator "github.com/go-playground/validator/v10"
+	"golang.org/x/crypto/openpgp"
+)
+
+// maxRemoteKeySize bounds the size of a key fetched from a keyserver or Web Key Directory
+const maxRemoteKeySize = 4 << 20
+
+// zbase32Alphabet is the encoding of the hashed local part of an address in Web Key Directory URLs
+const zbase32Alphabet = "ybndrfg8ejkmcpqxot1uwisza345h769"
+
+// IsRemoteKeyReference reports whether v refers to a key held by an HKPS keyserver, given as
+// hkps://host[:port][/query], or is an email address whose key is looked up in the Web Key Directory
+// of its domain
+func IsRemoteKeyReference(v string) bool {
+	return strings.HasPrefix(strings.ToLower(v), "hkps://") || isEmailAddress(v)
+}
+
+func isEmailAddress(v string) bool {
+	return !strings.ContainsAny(v, `/\`) && validator.New().Var(v, "required,email") == nil
+}
+
+// FetchPinnedPublicKey fetches the key that ref refers to, see IsRemoteKeyReference. Keyservers and
+// Web Key Directories are not trusted, so only entities whose primary key has one of the pinned
+// fingerprints are kept, and it is an error if there are none. Subkeys can not be pinned, as anyone
+// can attach a copy of someone else's subkey to their own primary key.
+func FetchPinnedPublicKey(ctx context.Context, client *http.Client, ref string, fingerprints []string) (*PublicKey, error) {
+	pins, err := ParseFingerprints(fingerprints)
+	if err != nil {
+		return nil, err
+	}
+	if len(pins) == 0 {
+		return nil, fmt.Errorf("a key fingerprint must be pinned to fetch the PGP public key for %v", ref)
+	}
+
+	var urls []string
+	if isEmailAddress(ref) {
+		urls, err = wkdURLs(ref)
+	} else {
+		var lookup string
+		lookup, err = hkpsLookupURL(ref, pins)
+		urls = []string{lookup}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var errs []string
+	for _, u := range urls {
+		key, err := fetchPinned(ctx, client, u, pins)
+		if err == nil {
+			return key, nil
+		}
+		errs = append(errs, err.Error())
+	}
+	return nil, fmt.Errorf("error fetching PGP public key for %v: %s", ref, strings.Join(errs, "; "))
+}
+
+// ParseFingerprints normalizes V4 key fingerprints, given in hex with an optional 0x prefix, to
+// upper case hex
+func ParseFingerprints(fingerprints []string) ([]string, error) {
+	pins := make([]string, 0, len(fingerprints))
+	for _, f := range fingerprints {
+		hexFpr := strings.ReplaceAll(f, " ", "")
+		hexFpr = strings.TrimPrefix(strings.TrimPrefix(hexFpr, "0x"), "0X")
+		b, err := hex.DecodeString(hexFpr)
+		if err != nil || len(b) != 20 {
+			return nil, fmt.Errorf("invalid key fingerprint %q: expected 40 hexadecimal characters", f)
+		}
+		pins = append(pins, strings.ToUpper(hexFpr))
+	}
+	return pins, nil
+}
+
+// hkpsLookupURL returns the HKP lookup for hkps://host[:port][/query]; without a query, the key with
+// the only pinned fingerprint is looked up
+func hkpsLookupURL(ref string, pins []string) (string, error) {
+	u, err := url.Parse(ref)
+	if err != nil || u.Host == "" {
+		return "", fmt.Errorf("invalid keyserver reference %v", ref)
+	}
+	search := strings.TrimPrefix(u.Path, "/")
+	if search == "" {
+		if len(pins) != 1 {
+			return "", fmt.Errorf("keyserver reference %v must name the key to look up when more than one fingerprint is pinned", ref)
+		}
+		search = "0x" + pins[0]
+	}
+	lookup := url.URL{
+		Scheme:   "https",
+		Host:     u.Host,
+		Path:     "/pks/lookup",
+		RawQuery: url.Values{"op": {"get"}, "options": {"mr"}, "search": {search}}.Encode(),
+	}
+	return lookup.String(), nil
+}
+
+// wkdURLs returns the advanced and direct Web Key Directory URLs for an email address, in the order
+// they are to be tried
+func wkdURLs(email string) ([]string, error) {
+	at := strings.LastIndex(email, "@")
+	if at <= 0 || at == len(email)-1 {
+		return nil, fmt.Errorf("invalid email address %v", email)
+	}
+	local, domain := email[:at], strings.ToLower(email[at+1:])
+	digest := sha1.Sum([]byte(strings.ToLower(local))) // #nosec G401
+	hu := zbase32(digest[:])
+	query := url.Values{"l": {local}}.Encode()
+	return []string{
+		fmt.Sprintf("https://openpgpkey.%s/.well-known/openpgpkey/%s/hu/%s?%s", domain, domain, hu, query),
+		fmt.Sprintf("https://%s/.well-known/openpgpkey/hu/%s?%s", domain, hu, query),
+	}, nil
+}
+
+func zbase32(b []byte) string {
+	var sb strings.Builder
+	var buf, bits uint
+	for _, c := range b {
+		buf = buf<<8 | uint(c)
+		bits += 8
+		for bits >= 5 {
+			bits -= 5
+			sb.WriteByte(zbase32Alphabet[(buf>>bits)&0x1f])
+		}
+	}
+	if bits > 0 {
+		sb.WriteByte(zbase32Alphabet[(buf<<(5-bits))&0x1f])
+	}
+	return sb.String()
+}
+
+func fetchPinned(ctx context.Context, client *http.Client, u string, pins []string) (*PublicKey, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%v: %v", u, resp.Status)
+	}
+
+	key, err := NewPublicKey(io.LimitReader(resp.Body, maxRemoteKeySize))
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", u, err)
+	}
+	var pinned openpgp.EntityList
+	for _, entity := range key.key {
+		if hasFingerprint(entity, pins) {
+			pinned = append(pinned, entity)
+		}
+	}
+	if len(pinned) == 0 {
+		return nil, fmt.Errorf("%v: no key with a pinned fingerprint was returned", u)
+	}
+	return &PublicKey{key: pinned}, nil
+}
+
+func hasFingerprint(entity *openpgp.Entity, pins []string) bool {
+	fpr := strings.ToUpper(hex.EncodeToString(entity.PrimaryKey.Fingerprint[:]))
+	for _, pin := range pins {
+		if fpr == pin {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,118 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgp
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+const (
+	subkeyTestPrimary    = "F0A4D262D862ABEF93F96F2417B1B3672BD1CBC4"
+	subkeyTestSubkey     = "7B4C2A06A3F4D8B89BA7BE7983B70E9AEAA96207"
+	supersededTestFpr    = "B4E62867FD126022D9CDD74C9BB17C9BD566D169"
+	unknownFingerprint   = "0000000000000000000000000000000000000000"
+	keyringPublicKeyFile = "testdata/keyring_public.pgp"
+)
+
+func TestIsRemoteKeyReference(t *testing.T) {
+	tests := map[string]bool{
+		"hkps://keys.openpgp.org":                        true,
+		"HKPS://keys.openpgp.org/0x" + subkeyTestPrimary: true,
+		"maintainer@example.com":                         true,
+		"https://example.com/key.asc":                    false,
+		"testdata/keyring_public.pgp":                    false,
+		"dir/maintainer@example.com":                     false,
+	}
+	for ref, want := range tests {
+		if got := IsRemoteKeyReference(ref); got != want {
+			t.Errorf("IsRemoteKeyReference(%q) = %v, want %v", ref, got, want)
+		}
+	}
+}
+
+func TestWKDURLs(t *testing.T) {
+	// example from the Web Key Directory draft
+	got, err := wkdURLs("Joe.Doe@Example.ORG")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"https://openpgpkey.example.org/.well-known/openpgpkey/example.org/hu/iy9q119eutrkn8s1mk4r39qejnbu3n5q?l=Joe.Doe",
+		"https://example.org/.well-known/openpgpkey/hu/iy9q119eutrkn8s1mk4r39qejnbu3n5q?l=Joe.Doe",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("wkdURLs() = %v, want %v", got, want)
+	}
+}
+
+func TestFetchPinnedPublicKey(t *testing.T) {
+	keyring, err := ioutil.ReadFile(keyringPublicKeyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var searches []string
+	testServer := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/pks/lookup" || r.URL.Query().Get("op") != "get" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		searches = append(searches, r.URL.Query().Get("search"))
+		_, _ = w.Write(keyring)
+	}))
+	defer testServer.Close()
+	host := (&url.URL{Scheme: "hkps", Host: testServer.Listener.Addr().String()}).String()
+
+	tests := []struct {
+		caseDesc     string
+		ref          string
+		fingerprints []string
+		wantSearch   string
+		wantEntities int
+		errorFound   bool
+	}{
+		{caseDesc: "Pinned primary key", ref: host, fingerprints: []string{subkeyTestPrimary}, wantSearch: "0x" + subkeyTestPrimary, wantEntities: 1},
+		{caseDesc: "Pinned primary key, lower case with prefix", ref: host + "/subkey@example.com", fingerprints: []string{"0xf0a4d262d862abef93f96f2417b1b3672bd1cbc4"}, wantSearch: "subkey@example.com", wantEntities: 1},
+		// anyone can attach a copy of a subkey to their own primary key
+		{caseDesc: "Pinned subkey", ref: host + "/subkey@example.com", fingerprints: []string{subkeyTestSubkey}, wantSearch: "subkey@example.com", errorFound: true},
+		{caseDesc: "Both keys of the keyring pinned", ref: host + "/example.com", fingerprints: []string{subkeyTestPrimary, supersededTestFpr}, wantSearch: "example.com", wantEntities: 2},
+		{caseDesc: "One key of the keyring pinned", ref: host + "/example.com", fingerprints: []string{subkeyTestSubkey, supersededTestFpr}, wantSearch: "example.com", wantEntities: 1},
+		{caseDesc: "No key with pinned fingerprint", ref: host, fingerprints: []string{unknownFingerprint}, wantSearch: "0x" + unknownFingerprint, errorFound: true},
+		{caseDesc: "No fingerprint pinned", ref: host + "/subkey@example.com", errorFound: true},
+		{caseDesc: "Invalid fingerprint", ref: host, fingerprints: []string{"17B1B3672BD1CBC4"}, errorFound: true},
+		{caseDesc: "Query required with several pins", ref: host, fingerprints: []string{subkeyTestSubkey, supersededTestFpr}, errorFound: true},
+	}
+
+	for _, tc := range tests {
+		searches = nil
+		key, err := FetchPinnedPublicKey(context.TODO(), testServer.Client(), tc.ref, tc.fingerprints)
+		if (err != nil) != tc.errorFound {
+			t.Errorf("%v: unexpected error: %v", tc.caseDesc, err)
+			continue
+		}
+		if tc.wantSearch != "" && !reflect.DeepEqual(searches, []string{tc.wantSearch}) {
+			t.Errorf("%v: searched keyserver for %v, want %v", tc.caseDesc, searches, tc.wantSearch)
+		}
+		if err == nil && len(key.key) != tc.wantEntities {
+			t.Errorf("%v: got %d keys, want %d", tc.caseDesc, len(key.key), tc.wantEntities)
+		}
+	}
+}
@@ -209,3 +209,41 @@ func (k PublicKey) EmailAddresses() []string {
 
 	return names
 }
+
+// Subjects implements the pki.PublicKey interface; in addition to the e-mail addresses in the subject
+// name these include the e-mail address and URI SANs of the signing certificate
+func (k PublicKey) Subjects() []string {
+	names := k.EmailAddresses()
+	cert, err := x509.ParseCertificate(k.rawCert)
+	if err != nil {
+		return names
+	}
+	seen := map[string]bool{}
+	for _, name := range names {
+		seen[name] = true
+	}
+	for _, email := range cert.EmailAddresses {
+		email = strings.ToLower(email)
+		if !seen[email] {
+			seen[email] = true
+			names = append(names, email)
+		}
+	}
+	for _, uri := range cert.URIs {
+		names = append(names, uri.String())
+	}
+	return names
+}
+
+// Certificates implements the pki.CertificateProvider interface
+func (k PublicKey) Certificates() []*x509.Certificate {
+	return k.certs
+}
+
+// CryptoPublicKeys implements the pki.CryptoKeyProvider interface
+func (k PublicKey) CryptoPublicKeys() []crypto.PublicKey {
+	if k.key == nil {
+		return nil
+	}
+	return []crypto.PublicKey{k.key}
+}
@@ -310,3 +310,21 @@ func TestEmailAddresses(t *testing.T) {
 	}
 
 }
+
+func TestSubjects(t *testing.T) {
+	pub, err := NewPublicKey(strings.NewReader(pkcsPEMEmail))
+	if err != nil {
+		t.Fatal(err)
+	}
+	subjects := pub.Subjects()
+	if len(subjects) == 0 || subjects[0] != "test@rekor.dev" {
+		t.Errorf("expected subject name e-mail address to be included in subjects, got %v", subjects)
+	}
+	seen := map[string]bool{}
+	for _, s := range subjects {
+		if seen[s] {
+			t.Errorf("duplicate subject %s in %v", s, subjects)
+		}
+		seen[s] = true
+	}
+}
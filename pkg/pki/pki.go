@@ -16,6 +16,8 @@
 package pki
 
 import (
+	"crypto"
+	"crypto/x509"
 	"io"
 
 	sigsig "github.com/sigstore/sigstore/pkg/signature"
@@ -25,6 +27,19 @@ import (
 type PublicKey interface {
 	CanonicalValue() ([]byte, error)
 	EmailAddresses() []string
+	Subjects() []string
+}
+
+// CertificateProvider is implemented by public keys which are backed by X.509 certificates
+type CertificateProvider interface {
+	// Certificates returns the signing certificate followed by any other certificates supplied with it
+	Certificates() []*x509.Certificate
+}
+
+// CryptoKeyProvider is implemented by public keys whose underlying keys can be inspected, e.g. to enforce minimum key sizes
+type CryptoKeyProvider interface {
+	// CryptoPublicKeys returns the keys held by this public key, including any subkeys
+	CryptoPublicKeys() []crypto.PublicKey
 }
 
 // Signature Generic object representing a signature (regardless of format & algorithm)
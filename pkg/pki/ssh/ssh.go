@@ -16,6 +16,7 @@
 package ssh
 
 import (
+	"crypto"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -102,3 +103,16 @@ func (k PublicKey) CanonicalValue() ([]byte, error) {
 func (k PublicKey) EmailAddresses() []string {
 	return nil
 }
+
+// Subjects implements the pki.PublicKey interface
+func (k PublicKey) Subjects() []string {
+	return k.EmailAddresses()
+}
+
+// CryptoPublicKeys implements the pki.CryptoKeyProvider interface
+func (k PublicKey) CryptoPublicKeys() []crypto.PublicKey {
+	if cpk, ok := k.key.(ssh.CryptoPublicKey); ok {
+		return []crypto.PublicKey{cpk.CryptoPublicKey()}
+	}
+	return nil
+}
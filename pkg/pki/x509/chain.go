@@ -0,0 +1,177 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package x509
+
+import (
+	"bytes"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// RevocationPolicy controls how certificate revocation lists are applied when verifying a chain
+type RevocationPolicy string
+
+const (
+	// RevocationNone ignores revocation entirely
+	RevocationNone RevocationPolicy = "none"
+	// RevocationSoft rejects revoked certificates when a CRL is available for the issuer
+	RevocationSoft RevocationPolicy = "soft"
+	// RevocationHard additionally requires a current CRL for every issuer in the chain
+	RevocationHard RevocationPolicy = "hard"
+)
+
+// ChainPolicy describes which certificates are accepted when entries are admitted to the log
+type ChainPolicy struct {
+	Roots         *x509.CertPool
+	Intermediates []*x509.Certificate
+	// KeyUsages lists the extended key usages of which the leaf must carry at least one; if empty any usage is accepted
+	KeyUsages  []x509.ExtKeyUsage
+	CRLs       []*pkix.CertificateList
+	Revocation RevocationPolicy
+}
+
+var extKeyUsages = map[string]x509.ExtKeyUsage{
+	"any":             x509.ExtKeyUsageAny,
+	"codesigning":     x509.ExtKeyUsageCodeSigning,
+	"emailprotection": x509.ExtKeyUsageEmailProtection,
+	"timestamping":    x509.ExtKeyUsageTimeStamping,
+	"serverauth":      x509.ExtKeyUsageServerAuth,
+	"clientauth":      x509.ExtKeyUsageClientAuth,
+}
+
+// ParseExtKeyUsages maps names such as "codesigning" or "timestamping" to extended key usages
+func ParseExtKeyUsages(names []string) ([]x509.ExtKeyUsage, error) {
+	ekus := []x509.ExtKeyUsage{}
+	for _, name := range names {
+		eku, ok := extKeyUsages[strings.ToLower(strings.TrimSpace(name))]
+		if !ok {
+			return nil, fmt.Errorf("unknown extended key usage %q", name)
+		}
+		ekus = append(ekus, eku)
+	}
+	return ekus, nil
+}
+
+// ParseCRLs parses all PEM or DER encoded certificate revocation lists in crlBytes
+func ParseCRLs(crlBytes []byte) ([]*pkix.CertificateList, error) {
+	if !bytes.Contains(crlBytes, []byte("-----BEGIN")) {
+		crl, err := x509.ParseDERCRL(crlBytes)
+		if err != nil {
+			return nil, err
+		}
+		return []*pkix.CertificateList{crl}, nil
+	}
+	crls := []*pkix.CertificateList{}
+	for block, rest := pem.Decode(crlBytes); block != nil; block, rest = pem.Decode(rest) {
+		if block.Type != "X509 CRL" {
+			return nil, fmt.Errorf("invalid block type %s", block.Type)
+		}
+		crl, err := x509.ParseDERCRL(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		crls = append(crls, crl)
+	}
+	if len(crls) == 0 {
+		return nil, errors.New("no certificate revocation lists found")
+	}
+	return crls, nil
+}
+
+// Verify checks that the first certificate in certs chains up to one of the policy's roots at time t,
+// using the remaining certificates and the policy's intermediates to build the chain, and that no
+// certificate in the chain has been revoked
+func (p ChainPolicy) Verify(certs []*x509.Certificate, t time.Time) error {
+	if len(certs) == 0 {
+		return errors.New("no certificate to verify")
+	}
+	if p.Roots == nil {
+		return errors.New("no trusted roots configured")
+	}
+	intermediates := x509.NewCertPool()
+	for _, c := range p.Intermediates {
+		intermediates.AddCert(c)
+	}
+	for _, c := range certs[1:] {
+		intermediates.AddCert(c)
+	}
+	keyUsages := p.KeyUsages
+	if len(keyUsages) == 0 {
+		keyUsages = []x509.ExtKeyUsage{x509.ExtKeyUsageAny}
+	}
+
+	chains, err := certs[0].Verify(x509.VerifyOptions{
+		Roots:         p.Roots,
+		Intermediates: intermediates,
+		CurrentTime:   t,
+		KeyUsages:     keyUsages,
+	})
+	if err != nil {
+		return fmt.Errorf("certificate does not chain to a trusted root: %w", err)
+	}
+	if p.Revocation == RevocationNone {
+		return nil
+	}
+
+	// any chain without revoked certificates is sufficient
+	for _, chain := range chains {
+		if err = p.checkRevocation(chain, t); err == nil {
+			return nil
+		}
+	}
+	return err
+}
+
+// checkRevocation checks every non-root certificate of a verified chain against its issuer's CRL
+func (p ChainPolicy) checkRevocation(chain []*x509.Certificate, t time.Time) error {
+	for i := 0; i < len(chain)-1; i++ {
+		c, issuer := chain[i], chain[i+1]
+		crl := p.findCRL(issuer, t)
+		if crl == nil {
+			if p.Revocation == RevocationHard {
+				return fmt.Errorf("no current CRL available for issuer %q", issuer.Subject.String())
+			}
+			continue
+		}
+		for _, revoked := range crl.TBSCertList.RevokedCertificates {
+			if revoked.SerialNumber.Cmp(c.SerialNumber) == 0 && !revoked.RevocationTime.After(t) {
+				return fmt.Errorf("certificate %q with serial number %s has been revoked", c.Subject.String(), c.SerialNumber.String())
+			}
+		}
+	}
+	return nil
+}
+
+// findCRL returns a CRL signed by issuer that is current at time t, if one has been configured
+func (p ChainPolicy) findCRL(issuer *x509.Certificate, t time.Time) *pkix.CertificateList {
+	for _, crl := range p.CRLs {
+		if crl.TBSCertList.Issuer.String() != issuer.Subject.ToRDNSequence().String() {
+			continue
+		}
+		if crl.HasExpired(t) {
+			continue
+		}
+		if issuer.CheckCRLSignature(crl) == nil {
+			return crl
+		}
+	}
+	return nil
+}
@@ -0,0 +1,154 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package x509
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+)
+
+func createCert(t *testing.T, tmpl, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey) {
+	t.Helper()
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parent == nil {
+		parent, parentKey = tmpl, priv
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, parent, priv.Public(), parentKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c, priv
+}
+
+func caTemplate(serial int64, name string) *x509.Certificate {
+	return &x509.Certificate{
+		SerialNumber:          big.NewInt(serial),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+}
+
+func leafTemplate(serial int64, eku x509.ExtKeyUsage) *x509.Certificate {
+	return &x509.Certificate{
+		SerialNumber:   big.NewInt(serial),
+		Subject:        pkix.Name{CommonName: "signer"},
+		NotBefore:      time.Now().Add(-time.Hour),
+		NotAfter:       time.Now().Add(time.Hour),
+		KeyUsage:       x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:    []x509.ExtKeyUsage{eku},
+		EmailAddresses: []string{"foo@example.com"},
+	}
+}
+
+func TestChainPolicy_Verify(t *testing.T) {
+	root, rootKey := createCert(t, caTemplate(1, "root"), nil, nil)
+	intermediate, intermediateKey := createCert(t, caTemplate(2, "intermediate"), root, rootKey)
+	untrusted, untrustedKey := createCert(t, caTemplate(3, "untrusted"), nil, nil)
+
+	codeSigning, _ := createCert(t, leafTemplate(10, x509.ExtKeyUsageCodeSigning), intermediate, intermediateKey)
+	revoked, _ := createCert(t, leafTemplate(11, x509.ExtKeyUsageCodeSigning), intermediate, intermediateKey)
+	serverAuth, _ := createCert(t, leafTemplate(12, x509.ExtKeyUsageServerAuth), intermediate, intermediateKey)
+	unchained, _ := createCert(t, leafTemplate(13, x509.ExtKeyUsageCodeSigning), untrusted, untrustedKey)
+
+	crlDER, err := intermediate.CreateCRL(rand.Reader, intermediateKey, []pkix.RevokedCertificate{
+		{SerialNumber: revoked.SerialNumber, RevocationTime: time.Now().Add(-time.Minute)},
+	}, time.Now().Add(-time.Minute), time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	crls, err := ParseCRLs(pem.EncodeToMemory(&pem.Block{Type: "X509 CRL", Bytes: crlDER}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	roots := x509.NewCertPool()
+	roots.AddCert(root)
+	ekus, err := ParseExtKeyUsages([]string{"codesigning"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	base := ChainPolicy{Roots: roots, Intermediates: []*x509.Certificate{intermediate}, KeyUsages: ekus, Revocation: RevocationSoft}
+	withCRL := base
+	withCRL.CRLs = crls
+	hard := base
+	hard.Revocation = RevocationHard
+	hardWithCRL := withCRL
+	hardWithCRL.Revocation = RevocationHard
+	ignoreCRL := withCRL
+	ignoreCRL.Revocation = RevocationNone
+	noIntermediates := base
+	noIntermediates.Intermediates = nil
+
+	tests := []struct {
+		name    string
+		policy  ChainPolicy
+		certs   []*x509.Certificate
+		wantErr bool
+	}{
+		{name: "chains to root", policy: base, certs: []*x509.Certificate{codeSigning}},
+		{name: "intermediate supplied with signer", policy: noIntermediates, certs: []*x509.Certificate{codeSigning, intermediate}},
+		{name: "missing intermediate", policy: noIntermediates, certs: []*x509.Certificate{codeSigning}, wantErr: true},
+		{name: "untrusted root", policy: base, certs: []*x509.Certificate{unchained}, wantErr: true},
+		{name: "wrong extended key usage", policy: base, certs: []*x509.Certificate{serverAuth}, wantErr: true},
+		{name: "revoked without CRL", policy: base, certs: []*x509.Certificate{revoked}},
+		{name: "revoked", policy: withCRL, certs: []*x509.Certificate{revoked}, wantErr: true},
+		{name: "not revoked", policy: withCRL, certs: []*x509.Certificate{codeSigning}},
+		{name: "revocation ignored", policy: ignoreCRL, certs: []*x509.Certificate{revoked}},
+		{name: "hard policy without CRL", policy: hard, certs: []*x509.Certificate{codeSigning}, wantErr: true},
+		// the root does not publish a CRL for the intermediate
+		{name: "hard policy with partial CRLs", policy: hardWithCRL, certs: []*x509.Certificate{codeSigning}, wantErr: true},
+		{name: "no certificates", policy: base, wantErr: true},
+		{name: "no roots", policy: ChainPolicy{}, certs: []*x509.Certificate{codeSigning}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.policy.Verify(tt.certs, time.Now()); (err != nil) != tt.wantErr {
+				t.Errorf("Verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseExtKeyUsages(t *testing.T) {
+	ekus, err := ParseExtKeyUsages([]string{"CodeSigning", " timestamping"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ekus) != 2 || ekus[0] != x509.ExtKeyUsageCodeSigning || ekus[1] != x509.ExtKeyUsageTimeStamping {
+		t.Errorf("unexpected key usages %v", ekus)
+	}
+	if _, err := ParseExtKeyUsages([]string{"signing"}); err == nil {
+		t.Error("expected error for unknown key usage")
+	}
+}
@@ -0,0 +1,127 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package x509
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Fulcio issues short-lived certificates bound to an OIDC identity, which are used for keyless
+// signing. When Fulcio roots are configured, certificates issued by them are only accepted while
+// they are valid, which binds the signature to the certificate's validity window.
+var (
+	fulcioMu            sync.RWMutex
+	fulcioRoots         *x509.CertPool
+	fulcioIntermediates *x509.CertPool
+)
+
+// ParseCertificates parses all PEM encoded certificates in pemBytes
+func ParseCertificates(pemBytes []byte) ([]*x509.Certificate, error) {
+	certs := []*x509.Certificate{}
+	for block, rest := pem.Decode(pemBytes); block != nil; block, rest = pem.Decode(rest) {
+		if block.Type != "CERTIFICATE" {
+			return nil, fmt.Errorf("invalid block type %s", block.Type)
+		}
+		c, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		certs = append(certs, c)
+	}
+	if len(certs) == 0 {
+		return nil, errors.New("no certificates found")
+	}
+	return certs, nil
+}
+
+// SetFulcioRoots configures the certificates of the Fulcio instance(s) whose certificates are
+// accepted for keyless signing. Self-signed certificates are treated as roots and all others as
+// intermediates. Passing no certificates disables keyless verification.
+func SetFulcioRoots(certs []*x509.Certificate) {
+	fulcioMu.Lock()
+	defer fulcioMu.Unlock()
+	if len(certs) == 0 {
+		fulcioRoots, fulcioIntermediates = nil, nil
+		return
+	}
+	fulcioRoots, fulcioIntermediates = x509.NewCertPool(), x509.NewCertPool()
+	roots, intermediates := SplitCertificates(certs)
+	for _, c := range roots {
+		fulcioRoots.AddCert(c)
+	}
+	for _, c := range intermediates {
+		fulcioIntermediates.AddCert(c)
+	}
+}
+
+// SplitCertificates separates self-signed root certificates from intermediate certificates
+func SplitCertificates(certs []*x509.Certificate) (roots, intermediates []*x509.Certificate) {
+	for _, c := range certs {
+		if c.CheckSignatureFrom(c) == nil {
+			roots = append(roots, c)
+		} else {
+			intermediates = append(intermediates, c)
+		}
+	}
+	return roots, intermediates
+}
+
+// isFulcioCertificate reports whether c chains up to a configured Fulcio root, regardless of
+// whether it is currently valid
+func isFulcioCertificate(c *x509.Certificate) bool {
+	fulcioMu.RLock()
+	defer fulcioMu.RUnlock()
+	if fulcioRoots == nil {
+		return false
+	}
+	_, err := c.Verify(x509.VerifyOptions{
+		Roots:         fulcioRoots,
+		Intermediates: fulcioIntermediates,
+		CurrentTime:   c.NotBefore,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+	})
+	return err == nil
+}
+
+// VerifyKeyless checks that, if the key is a certificate issued by a configured Fulcio instance,
+// it was valid at signingTime and is bound to an identity. This is only meaningful when an entry
+// is admitted to the log; verifying entries that are already in the log must not depend on it.
+func (k PublicKey) VerifyKeyless(signingTime time.Time) error {
+	if k.cert == nil {
+		return nil
+	}
+	return verifyKeyless(k.cert.c, signingTime)
+}
+
+// verifyKeyless checks that a certificate issued by Fulcio is valid at the time of signing and is
+// bound to an identity; certificates from other issuers are not subject to these checks
+func verifyKeyless(c *x509.Certificate, signingTime time.Time) error {
+	if !isFulcioCertificate(c) {
+		return nil
+	}
+	if signingTime.Before(c.NotBefore) || signingTime.After(c.NotAfter) {
+		return fmt.Errorf("signing certificate is only valid between %s and %s", c.NotBefore.UTC().Format(time.RFC3339), c.NotAfter.UTC().Format(time.RFC3339))
+	}
+	if len(c.EmailAddresses) == 0 && len(c.URIs) == 0 {
+		return errors.New("signing certificate issued by Fulcio does not contain an identity")
+	}
+	return nil
+}
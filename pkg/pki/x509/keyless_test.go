@@ -0,0 +1,172 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package x509
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/url"
+	"testing"
+	"time"
+)
+
+type testCA struct {
+	cert *x509.Certificate
+	priv *ecdsa.PrivateKey
+}
+
+func newTestCA(t *testing.T) testCA {
+	t.Helper()
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "fulcio"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, priv.Public(), priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return testCA{cert: cert, priv: priv}
+}
+
+func (ca testCA) issue(t *testing.T, notBefore time.Time, emails []string, uris []*url.URL) *x509.Certificate {
+	t.Helper()
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:   big.NewInt(2),
+		NotBefore:      notBefore,
+		NotAfter:       notBefore.Add(10 * time.Minute),
+		KeyUsage:       x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:    []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+		EmailAddresses: emails,
+		URIs:           uris,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.cert, priv.Public(), ca.priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert
+}
+
+func TestVerifyKeyless(t *testing.T) {
+	fulcio := newTestCA(t)
+	other := newTestCA(t)
+
+	pemBytes := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: fulcio.cert.Raw})
+	roots, err := ParseCertificates(pemBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	SetFulcioRoots(roots)
+	defer SetFulcioRoots(nil)
+
+	now := time.Now()
+	workflow, _ := url.Parse("https://github.com/sigstore/rekor/.github/workflows/release.yml@refs/heads/main")
+
+	tests := []struct {
+		name    string
+		cert    *x509.Certificate
+		wantErr bool
+	}{
+		{
+			name: "valid email identity",
+			cert: fulcio.issue(t, now.Add(-time.Minute), []string{"foo@example.com"}, nil),
+		},
+		{
+			name: "valid URI identity",
+			cert: fulcio.issue(t, now.Add(-time.Minute), nil, []*url.URL{workflow}),
+		},
+		{
+			name:    "expired",
+			cert:    fulcio.issue(t, now.Add(-time.Hour+time.Minute), []string{"foo@example.com"}, nil),
+			wantErr: true,
+		},
+		{
+			name:    "not yet valid",
+			cert:    fulcio.issue(t, now.Add(time.Minute), []string{"foo@example.com"}, nil),
+			wantErr: true,
+		},
+		{
+			name:    "no identity",
+			cert:    fulcio.issue(t, now.Add(-time.Minute), nil, nil),
+			wantErr: true,
+		},
+		{
+			name: "not issued by fulcio",
+			cert: other.issue(t, now.Add(-time.Hour+time.Minute), nil, nil),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			k := PublicKey{cert: &cert{c: tt.cert}}
+			if err := k.VerifyKeyless(now); (err != nil) != tt.wantErr {
+				t.Errorf("VerifyKeyless() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	// without configured roots certificates are never treated as keyless
+	SetFulcioRoots(nil)
+	expired := PublicKey{cert: &cert{c: fulcio.issue(t, now.Add(-time.Hour+time.Minute), nil, nil)}}
+	if err := expired.VerifyKeyless(now); err != nil {
+		t.Errorf("unexpected error without fulcio roots: %v", err)
+	}
+}
+
+func TestPublicKey_Subjects(t *testing.T) {
+	ca := newTestCA(t)
+	workflow, _ := url.Parse("https://github.com/sigstore/rekor/.github/workflows/release.yml@refs/heads/main")
+	c := ca.issue(t, time.Now(), []string{"foo@example.com"}, []*url.URL{workflow})
+
+	k := PublicKey{cert: &cert{c: c}}
+	got := k.Subjects()
+	if len(got) != 2 || got[0] != "foo@example.com" || got[1] != workflow.String() {
+		t.Errorf("unexpected subjects %v", got)
+	}
+}
+
+func TestParseCertificates(t *testing.T) {
+	if _, err := ParseCertificates([]byte("not a certificate")); err == nil {
+		t.Error("expected error parsing empty bundle")
+	}
+	if _, err := ParseCertificates(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: []byte{1}})); err == nil {
+		t.Error("expected error parsing non-certificate block")
+	}
+}
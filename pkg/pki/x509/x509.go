@@ -160,6 +160,34 @@ func (k PublicKey) EmailAddresses() []string {
 	return names
 }
 
+// Subjects implements the pki.PublicKey interface; for certificates these are the e-mail address and
+// URI SANs, the latter being used by Fulcio for workload identities
+func (k PublicKey) Subjects() []string {
+	names := k.EmailAddresses()
+	if k.cert != nil {
+		for _, uri := range k.cert.c.URIs {
+			names = append(names, uri.String())
+		}
+	}
+	return names
+}
+
+// Certificates implements the pki.CertificateProvider interface
+func (k PublicKey) Certificates() []*x509.Certificate {
+	if k.cert == nil {
+		return nil
+	}
+	return []*x509.Certificate{k.cert.c}
+}
+
+// CryptoPublicKeys implements the pki.CryptoKeyProvider interface
+func (k PublicKey) CryptoPublicKeys() []crypto.PublicKey {
+	if pub := k.CryptoPubKey(); pub != nil {
+		return []crypto.PublicKey{pub}
+	}
+	return nil
+}
+
 func CertChainToPEM(certChain []*x509.Certificate) ([]byte, error) {
 	var pemBytes bytes.Buffer
 	for _, cert := range certChain {
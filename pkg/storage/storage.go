@@ -17,27 +17,63 @@ package storage
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
+	"fmt"
+	"regexp"
 
 	"github.com/sigstore/rekor/pkg/log"
 
-	"github.com/spf13/viper"
 	"gocloud.dev/blob"
 
 	// Blank imports to register storage
 	_ "gocloud.dev/blob/fileblob"
 	_ "gocloud.dev/blob/gcsblob"
 	_ "gocloud.dev/blob/memblob"
+	_ "gocloud.dev/blob/s3blob"
 )
 
+// AttestationStorage holds payloads attached to log entries. Payloads are stored content-addressed
+// by their SHA256 digest, so that the log only needs to record the digest; the attestation stored
+// for an entry is a reference from the entry UUID to that digest.
 type AttestationStorage interface {
 	StoreAttestation(ctx context.Context, key string, attestation []byte) error
 	FetchAttestation(ctx context.Context, key string) ([]byte, error)
+	StoreAttachment(ctx context.Context, attachment []byte) (string, error)
+	FetchAttachment(ctx context.Context, digest string) ([]byte, error)
 }
 
-func NewAttestationStorage() (AttestationStorage, error) {
-	if url := viper.GetString("attestation_storage_bucket"); url != "" {
+const (
+	attachmentPrefix = "sha256/"
+	referencePrefix  = "entries/"
+)
+
+var digestRegex = regexp.MustCompile("^sha256:([0-9a-f]{64})$")
+
+// ErrInvalidDigest is returned when an attachment is requested by a malformed digest
+var ErrInvalidDigest = errors.New("invalid attachment digest")
+
+// Digest returns the digest under which an attachment is stored
+func Digest(attachment []byte) string {
+	h := sha256.Sum256(attachment)
+	return "sha256:" + hex.EncodeToString(h[:])
+}
+
+// attachmentKey validates a digest of the form sha256:<hex> and returns the key of its blob
+func attachmentKey(digest string) (string, error) {
+	m := digestRegex.FindStringSubmatch(digest)
+	if m == nil {
+		return "", fmt.Errorf("%w %q, expected sha256:<lowercase hex>", ErrInvalidDigest, digest)
+	}
+	return attachmentPrefix + m[1], nil
+}
+
+// NewAttestationStorage opens the bucket at url, which is a file://, gs://, s3:// or mem:// URL
+func NewAttestationStorage(url string) (AttestationStorage, error) {
+	if url != "" {
 		log.Logger.Infof("Configuring attestation storage at %s", url)
+		// file://, gs://, s3:// and mem:// URLs are supported
 		bucket, err := blob.OpenBucket(context.Background(), url)
 		if err != nil {
 			return nil, err
@@ -53,31 +89,83 @@ type Blob struct {
 	bucket *blob.Bucket
 }
 
+// StoreAttestation stores the attestation content-addressed and records it as the attestation for key
 func (b *Blob) StoreAttestation(ctx context.Context, key string, attestation []byte) error {
 	log.Logger.Infof("storing attestation at %s", key)
-	w, err := b.bucket.NewWriter(ctx, key, nil)
+	digest, err := b.StoreAttachment(ctx, attestation)
 	if err != nil {
 		return err
 	}
-	if _, err := w.Write(attestation); err != nil {
-		return err
-	}
-	return w.Close()
+	return b.write(ctx, referencePrefix+key, []byte(digest))
 }
 
+// FetchAttestation returns the attestation recorded for key, or nil if there is none
 func (b *Blob) FetchAttestation(ctx context.Context, key string) ([]byte, error) {
 	log.Logger.Infof("fetching attestation %s", key)
-	exists, err := b.bucket.Exists(ctx, key)
+	digest, err := b.read(ctx, referencePrefix+key)
 	if err != nil {
 		return nil, err
 	}
+	if digest == nil {
+		// attestations stored before content addressing was introduced live directly under the key
+		return b.read(ctx, key)
+	}
+	return b.FetchAttachment(ctx, string(digest))
+}
+
+// StoreAttachment stores the attachment under its digest, which is returned
+func (b *Blob) StoreAttachment(ctx context.Context, attachment []byte) (string, error) {
+	digest := Digest(attachment)
+	key, err := attachmentKey(digest)
+	if err != nil {
+		return "", err
+	}
+	exists, err := b.bucket.Exists(ctx, key)
+	if err != nil {
+		return "", err
+	}
 	if !exists {
-		return nil, nil
+		if err := b.write(ctx, key, attachment); err != nil {
+			return "", err
+		}
 	}
+	return digest, nil
+}
 
-	data, err := b.bucket.ReadAll(ctx, key)
+// FetchAttachment returns the attachment stored under digest, or nil if there is none
+func (b *Blob) FetchAttachment(ctx context.Context, digest string) ([]byte, error) {
+	key, err := attachmentKey(digest)
 	if err != nil {
 		return nil, err
 	}
+	data, err := b.read(ctx, key)
+	if err != nil || data == nil {
+		return nil, err
+	}
+	if got := Digest(data); got != digest {
+		return nil, fmt.Errorf("attachment stored under %s has digest %s", digest, got)
+	}
 	return data, nil
 }
+
+func (b *Blob) write(ctx context.Context, key string, data []byte) error {
+	w, err := b.bucket.NewWriter(ctx, key, nil)
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(data); err != nil {
+		return err
+	}
+	return w.Close()
+}
+
+func (b *Blob) read(ctx context.Context, key string) ([]byte, error) {
+	exists, err := b.bucket.Exists(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, nil
+	}
+	return b.bucket.ReadAll(ctx, key)
+}
@@ -0,0 +1,122 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"gocloud.dev/blob"
+)
+
+func newMemBlob(t *testing.T) *Blob {
+	t.Helper()
+	bucket, err := blob.OpenBucket(context.Background(), "mem://")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = bucket.Close() })
+	return &Blob{bucket: bucket}
+}
+
+func TestAttachments(t *testing.T) {
+	ctx := context.Background()
+	b := newMemBlob(t)
+	payload := []byte("hello attachment")
+
+	digest, err := b.StoreAttachment(ctx, payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if digest != Digest(payload) {
+		t.Errorf("StoreAttachment() = %s, want %s", digest, Digest(payload))
+	}
+	// storing the same content again must be idempotent
+	if d, err := b.StoreAttachment(ctx, payload); err != nil || d != digest {
+		t.Errorf("StoreAttachment() again = %s, %v", d, err)
+	}
+
+	got, err := b.FetchAttachment(ctx, digest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, payload) {
+		t.Errorf("FetchAttachment() = %q, want %q", got, payload)
+	}
+
+	missing, err := b.FetchAttachment(ctx, Digest([]byte("other")))
+	if err != nil || missing != nil {
+		t.Errorf("FetchAttachment() of missing digest = %q, %v", missing, err)
+	}
+
+	for _, d := range []string{"", "sha256:abc", "sha1:da39a3ee5e6b4b0d3255bfef95601890afd80709", "SHA256:" + digest[7:], "sha256:../../etc/passwd"} {
+		if _, err := b.FetchAttachment(ctx, d); !errors.Is(err, ErrInvalidDigest) {
+			t.Errorf("FetchAttachment(%q) error = %v, want ErrInvalidDigest", d, err)
+		}
+	}
+}
+
+func TestFetchAttachmentDetectsTampering(t *testing.T) {
+	ctx := context.Background()
+	b := newMemBlob(t)
+	digest, err := b.StoreAttachment(ctx, []byte("original"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := b.write(ctx, attachmentPrefix+digest[len("sha256:"):], []byte("modified")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.FetchAttachment(ctx, digest); err == nil {
+		t.Error("expected error fetching attachment whose content does not match its digest")
+	}
+}
+
+func TestAttestations(t *testing.T) {
+	ctx := context.Background()
+	b := newMemBlob(t)
+	uuid := "c7e5a2f8b2c3b0d6a0e0b3e6c5f0e5b1a8d3c8e5f4a4d0b7c9e2f1a0b3c4d5e6"
+	attestation := []byte(`{"_type":"https://in-toto.io/Statement/v0.1"}`)
+
+	if err := b.StoreAttestation(ctx, uuid, attestation); err != nil {
+		t.Fatal(err)
+	}
+	got, err := b.FetchAttestation(ctx, uuid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, attestation) {
+		t.Errorf("FetchAttestation() = %q, want %q", got, attestation)
+	}
+	// the attestation is also available by its digest
+	if got, err := b.FetchAttachment(ctx, Digest(attestation)); err != nil || !bytes.Equal(got, attestation) {
+		t.Errorf("FetchAttachment() = %q, %v", got, err)
+	}
+
+	if got, err := b.FetchAttestation(ctx, "unknown"); err != nil || got != nil {
+		t.Errorf("FetchAttestation() of unknown key = %q, %v", got, err)
+	}
+
+	// attestations written by older servers are stored directly under the UUID
+	legacy := []byte("legacy attestation")
+	if err := b.write(ctx, "legacy-uuid", legacy); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := b.FetchAttestation(ctx, "legacy-uuid"); err != nil || !bytes.Equal(got, legacy) {
+		t.Errorf("FetchAttestation() of legacy key = %q, %v", got, err)
+	}
+}
@@ -35,6 +35,7 @@ import (
 
 	"github.com/sigstore/rekor/pkg/generated/models"
 	"github.com/sigstore/rekor/pkg/log"
+	"github.com/sigstore/rekor/pkg/pki"
 	"github.com/sigstore/rekor/pkg/pki/x509"
 	"github.com/sigstore/rekor/pkg/types"
 	"github.com/sigstore/rekor/pkg/types/alpine"
@@ -77,16 +78,26 @@ func (v V001Entry) IndexKeys() ([]string, error) {
 	keyHash := sha256.Sum256(key)
 	result = append(result, strings.ToLower(hex.EncodeToString(keyHash[:])))
 
-	result = append(result, keyObj.EmailAddresses()...)
+	result = append(result, keyObj.Subjects()...)
 
 	if v.AlpineModel.Package.Hash != nil {
 		hashKey := strings.ToLower(fmt.Sprintf("%s:%s", *v.AlpineModel.Package.Hash.Algorithm, *v.AlpineModel.Package.Hash.Value))
 		result = append(result, hashKey)
 	}
+	result = append(result, types.ArtifactURLIndexKeys(v.AlpineModel.Package.URL)...)
 
 	return result, nil
 }
 
+// Signers implements types.SignerProvider
+func (v V001Entry) Signers() ([]pki.PublicKey, error) {
+	keyObj, err := x509.NewPublicKey(bytes.NewReader(v.AlpineModel.PublicKey.Content))
+	if err != nil {
+		return nil, err
+	}
+	return []pki.PublicKey{keyObj}, nil
+}
+
 func (v *V001Entry) Unmarshal(pe models.ProposedEntry) error {
 	apk, ok := pe.(*models.Alpine)
 	if !ok {
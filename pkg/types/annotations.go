@@ -0,0 +1,158 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/sigstore/rekor/pkg/generated/models"
+	"github.com/sigstore/rekor/pkg/util"
+)
+
+// annotationKeyRE matches the keys annotations may have; the number of annotations and the length of
+// their values are limited by the schema of proposed entries
+var annotationKeyRE = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9._/-]{0,62}$`)
+
+// Annotations with these keys record the source an artifact was built from; entries are always indexed
+// by them
+const (
+	// CommitAnnotation holds the SHA1 or SHA256 ID of the git commit the artifact was built from
+	CommitAnnotation = "git.commit"
+	// RepositoryAnnotation holds the URL of the repository the artifact was built from
+	RepositoryAnnotation = "git.repository"
+)
+
+// ValidateAnnotations checks that the keys of annotations are well formed, as are the values of the
+// annotations recording the source of the artifact
+func ValidateAnnotations(annotations models.Annotations) error {
+	for _, k := range AnnotationKeys(annotations) {
+		if !annotationKeyRE.MatchString(k) {
+			return ValidationError(fmt.Errorf("invalid annotation key %q: keys must start with a letter or digit and contain at most 63 letters, digits, '.', '_', '/' or '-'", k))
+		}
+	}
+	if commit, ok := annotations[CommitAnnotation]; ok {
+		if _, err := util.NormalizeGitCommit(commit); err != nil {
+			return ValidationError(fmt.Errorf("annotation %v: %w", CommitAnnotation, err))
+		}
+	}
+	if repo, ok := annotations[RepositoryAnnotation]; ok {
+		if _, err := util.NormalizeRepositoryURL(repo); err != nil {
+			return ValidationError(fmt.Errorf("annotation %v: %w", RepositoryAnnotation, err))
+		}
+	}
+	return nil
+}
+
+// AnnotationKeys returns the keys of annotations in sorted order
+func AnnotationKeys(annotations models.Annotations) []string {
+	keys := make([]string, 0, len(annotations))
+	for k := range annotations {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// AnnotationIndexKey returns the key under which entries with the annotation key=value are indexed
+func AnnotationIndexKey(key, value string) string {
+	return fmt.Sprintf("annotation:%s=%s", key, value)
+}
+
+// CommitIndexKey returns the key under which entries built from a git commit are indexed
+func CommitIndexKey(commit string) (string, error) {
+	c, err := util.NormalizeGitCommit(commit)
+	if err != nil {
+		return "", err
+	}
+	return "commit:" + c, nil
+}
+
+// RepositoryIndexKey returns the key under which entries built from a source repository are indexed
+func RepositoryIndexKey(repo string) (string, error) {
+	u, err := util.NormalizeRepositoryURL(repo)
+	if err != nil {
+		return "", err
+	}
+	return "repo:" + u, nil
+}
+
+// ArtifactURLIndexKeys returns the key under which entries for an artifact downloaded from artifactURL
+// are indexed, or none if the URL is not set or is not an http or https URL
+func ArtifactURLIndexKeys(artifactURL strfmt.URI) []string {
+	u, err := util.NormalizeArtifactURL(artifactURL.String())
+	if artifactURL == "" || err != nil {
+		return nil
+	}
+	return []string{ArtifactURLIndexKey(u)}
+}
+
+// ArtifactURLIndexKey returns the key under which entries are indexed by a normalized artifact URL
+func ArtifactURLIndexKey(normalizedURL string) string {
+	return "url:" + normalizedURL
+}
+
+// SourceIndexKeys returns the index keys of the source recorded by the annotations of an entry
+func SourceIndexKeys(annotations models.Annotations) []string {
+	var keys []string
+	if commit, ok := annotations[CommitAnnotation]; ok {
+		if key, err := CommitIndexKey(commit); err == nil {
+			keys = append(keys, key)
+		}
+	}
+	if repo, ok := annotations[RepositoryAnnotation]; ok {
+		if key, err := RepositoryIndexKey(repo); err == nil {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// addAnnotations adds annotations to a canonicalized entry, so that they are covered by its leaf hash
+func addAnnotations(canonicalEntry []byte, annotations models.Annotations) ([]byte, error) {
+	if len(annotations) == 0 {
+		return canonicalEntry, nil
+	}
+	if err := ValidateAnnotations(annotations); err != nil {
+		return nil, err
+	}
+	// canonicalized entries never have annotations of their own, so they are appended as the last field
+	// rather than decoding and encoding the entry again; CanonicalizeEntry validates the result and
+	// restores the order of fields
+	fields := bytes.TrimSpace(canonicalEntry)
+	if len(fields) < 2 || fields[0] != '{' || fields[len(fields)-1] != '}' {
+		return nil, errors.New("parsing canonicalized entry: not a JSON object")
+	}
+	fields = bytes.TrimSpace(fields[1 : len(fields)-1])
+	b, err := json.Marshal(annotations)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]byte, 0, len(fields)+len(annotationsField)+len(b)+3)
+	out = append(out, '{')
+	if len(fields) > 0 {
+		out = append(append(out, fields...), ',')
+	}
+	out = append(append(out, annotationsField...), b...)
+	return append(out, '}'), nil
+}
+
+const annotationsField = `"annotations":`
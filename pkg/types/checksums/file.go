@@ -0,0 +1,111 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package checksums
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/sigstore/rekor/pkg/util"
+)
+
+// Artifact is a file listed in a checksums file
+type Artifact struct {
+	Name string
+	// Algorithm is one of sha256, sha384 or sha512
+	Algorithm string
+	// Digest is the lower case hex encoded digest of the file
+	Digest string
+}
+
+var (
+	// <hex>  name or <hex> *name, as written by sha256sum and friends
+	gnuLine = regexp.MustCompile(`^([0-9a-fA-F]+) [ *](.+)$`)
+	// SHA256 (name) = <hex>, as written by sha256sum --tag and BSD sha256
+	bsdLine = regexp.MustCompile(`^(SHA256|SHA384|SHA512) \((.+)\) = ([0-9a-fA-F]+)$`)
+)
+
+// Parse reads the artifacts listed in a checksums file such as SHA256SUMS, in either the GNU
+// coreutils format or the BSD tagged format. Blank lines and comments starting with # are skipped.
+func Parse(raw []byte) ([]Artifact, error) {
+	var artifacts []Artifact
+	seen := map[string]int{}
+
+	scanner := bufio.NewScanner(bytes.NewReader(raw))
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		var digest, name string
+		// coreutils prefixes lines whose file name needs escaping with a backslash
+		escaped := strings.HasPrefix(line, `\`)
+		if escaped {
+			line = line[1:]
+		}
+		if m := bsdLine.FindStringSubmatch(line); m != nil {
+			digest, name = strings.ToLower(m[1])+":"+m[3], m[2]
+		} else if m := gnuLine.FindStringSubmatch(line); m != nil {
+			digest, name = m[1], m[2]
+		} else {
+			return nil, fmt.Errorf("line %d is not a checksum listing", n)
+		}
+		if escaped {
+			name = strings.NewReplacer(`\\`, `\`, `\n`, "\n", `\r`, "\r").Replace(name)
+		}
+
+		algorithm, value, err := util.ParseDigest(digest)
+		if err != nil {
+			return nil, errors.Wrapf(err, "line %d", n)
+		}
+		if algorithm != util.SHA256 && algorithm != util.SHA384 && algorithm != util.SHA512 {
+			return nil, fmt.Errorf("line %d: %s digests are not supported", n, algorithm)
+		}
+
+		artifact := Artifact{Name: name, Algorithm: algorithm, Digest: value}
+		if i, ok := seen[name]; ok {
+			if artifacts[i] != artifact {
+				return nil, fmt.Errorf("line %d: %s is listed with conflicting digests", n, name)
+			}
+			continue
+		}
+		seen[name] = len(artifacts)
+		artifacts = append(artifacts, artifact)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(artifacts) == 0 {
+		return nil, errors.New("no artifacts are listed in the checksums file")
+	}
+	return artifacts, nil
+}
+
+// Find returns the artifact listed with the given name
+func Find(artifacts []Artifact, name string) (Artifact, bool) {
+	for _, a := range artifacts {
+		if a.Name == name {
+			return a, true
+		}
+	}
+	return Artifact{}, false
+}
@@ -0,0 +1,92 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package checksums
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	sha256 := strings.Repeat("a", 64)
+	sha512 := strings.Repeat("b", 128)
+	tests := []struct {
+		caseDesc string
+		file     string
+		want     []Artifact
+		wantErr  bool
+	}{
+		{
+			caseDesc: "gnu text and binary modes",
+			file:     sha256 + "  app_linux_amd64.tar.gz\n" + strings.ToUpper(sha512) + " *app_darwin_arm64.zip\n",
+			want: []Artifact{
+				{Name: "app_linux_amd64.tar.gz", Algorithm: "sha256", Digest: sha256},
+				{Name: "app_darwin_arm64.zip", Algorithm: "sha512", Digest: sha512},
+			},
+		},
+		{
+			caseDesc: "bsd tagged",
+			file:     "SHA256 (app 1.0.tar.gz) = " + sha256 + "\r\n",
+			want:     []Artifact{{Name: "app 1.0.tar.gz", Algorithm: "sha256", Digest: sha256}},
+		},
+		{
+			caseDesc: "comments, blank lines and duplicates",
+			file:     "# release 1.0\n\n" + sha256 + "  app\n" + sha256 + "  app\n",
+			want:     []Artifact{{Name: "app", Algorithm: "sha256", Digest: sha256}},
+		},
+		{
+			caseDesc: "escaped file name",
+			file:     `\` + sha256 + `  dir\\app\nname` + "\n",
+			want:     []Artifact{{Name: "dir\\app\nname", Algorithm: "sha256", Digest: sha256}},
+		},
+		{
+			caseDesc: "conflicting digests",
+			file:     sha256 + "  app\n" + strings.Repeat("c", 64) + "  app\n",
+			wantErr:  true,
+		},
+		{
+			caseDesc: "sha1 digests",
+			file:     strings.Repeat("a", 40) + "  app\n",
+			wantErr:  true,
+		},
+		{
+			caseDesc: "tag mismatching digest length",
+			file:     "SHA512 (app) = " + sha256 + "\n",
+			wantErr:  true,
+		},
+		{
+			caseDesc: "not a checksums file",
+			file:     "-----BEGIN PGP SIGNATURE-----\n",
+			wantErr:  true,
+		},
+		{
+			caseDesc: "empty",
+			file:     "# nothing here\n",
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		got, err := Parse([]byte(tt.file))
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%v: unexpected error %v", tt.caseDesc, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%v: got %v, want %v", tt.caseDesc, got, tt.want)
+		}
+	}
+}
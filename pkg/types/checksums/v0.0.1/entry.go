@@ -0,0 +1,399 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package checksums
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"github.com/asaskevich/govalidator"
+	"github.com/go-openapi/strfmt"
+	"github.com/go-openapi/swag"
+	"github.com/spf13/viper"
+
+	"github.com/sigstore/rekor/pkg/generated/models"
+	"github.com/sigstore/rekor/pkg/log"
+	"github.com/sigstore/rekor/pkg/pki"
+	"github.com/sigstore/rekor/pkg/types"
+	"github.com/sigstore/rekor/pkg/types/checksums"
+	"github.com/sigstore/rekor/pkg/util"
+)
+
+const (
+	APIVERSION = "0.0.1"
+)
+
+func init() {
+	if err := checksums.VersionMap.SetEntryFactory(APIVERSION, NewEntry); err != nil {
+		log.Logger.Panic(err)
+	}
+}
+
+type V001Entry struct {
+	ChecksumsObj models.ChecksumsV001Schema
+	// raw is only populated once the checksums file has been verified
+	raw []byte
+}
+
+func (v V001Entry) APIVersion() string {
+	return APIVERSION
+}
+
+func NewEntry() types.EntryImpl {
+	return &V001Entry{}
+}
+
+func (v V001Entry) IndexKeys() ([]string, error) {
+	var result []string
+
+	keyObj, err := v.publicKey()
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := keyObj.CanonicalValue()
+	if err != nil {
+		log.Logger.Error(err)
+	} else {
+		keyHash := sha256.Sum256(key)
+		result = append(result, strings.ToLower(hex.EncodeToString(keyHash[:])))
+	}
+
+	result = append(result, keyObj.Subjects()...)
+
+	if v.ChecksumsObj.Artifact.Hash != nil {
+		hashKey := strings.ToLower(fmt.Sprintf("%s:%s", *v.ChecksumsObj.Artifact.Hash.Algorithm, *v.ChecksumsObj.Artifact.Hash.Value))
+		result = append(result, hashKey)
+	}
+
+	// the digest of the checksums file finds every artifact released with it
+	if v.ChecksumsObj.Checksums.Hash != nil {
+		hashKey := strings.ToLower(fmt.Sprintf("%s:%s", *v.ChecksumsObj.Checksums.Hash.Algorithm, *v.ChecksumsObj.Checksums.Hash.Value))
+		result = append(result, hashKey)
+	}
+
+	return result, nil
+}
+
+func (v V001Entry) publicKey() (pki.PublicKey, error) {
+	af, err := pki.NewArtifactFactory(pki.Format(v.ChecksumsObj.Signature.Format))
+	if err != nil {
+		return nil, err
+	}
+	return af.NewPublicKey(bytes.NewReader(v.ChecksumsObj.Signature.PublicKey.Content))
+}
+
+// Signers implements types.SignerProvider
+func (v V001Entry) Signers() ([]pki.PublicKey, error) {
+	keyObj, err := v.publicKey()
+	if err != nil {
+		return nil, err
+	}
+	return []pki.PublicKey{keyObj}, nil
+}
+
+func (v *V001Entry) Unmarshal(pe models.ProposedEntry) error {
+	c, ok := pe.(*models.Checksums)
+	if !ok {
+		return errors.New("cannot unmarshal non checksums v0.0.1 type")
+	}
+
+	if err := types.DecodeEntry(c.Spec, &v.ChecksumsObj); err != nil {
+		return err
+	}
+
+	// field validation
+	if err := v.ChecksumsObj.Validate(strfmt.Default); err != nil {
+		return err
+	}
+
+	// cross field validation
+	return v.validate()
+}
+
+// verify checks the signature over the checksums file and that the file lists the artifact with
+// the digest given in the entry
+func (v *V001Entry) verify() (pki.PublicKey, pki.Signature, error) {
+	if err := v.validate(); err != nil {
+		return nil, nil, types.ValidationError(err)
+	}
+
+	raw := []byte(v.ChecksumsObj.Checksums.Content)
+	if len(raw) == 0 {
+		return nil, nil, types.ValidationError(errors.New("the checksums file must be provided"))
+	}
+
+	h := sha256.Sum256(raw)
+	computedSHA := hex.EncodeToString(h[:])
+	if hash := v.ChecksumsObj.Checksums.Hash; hash != nil && swag.StringValue(hash.Value) != computedSHA {
+		return nil, nil, types.ValidationError(fmt.Errorf("SHA mismatch: %s != %s", computedSHA, swag.StringValue(hash.Value)))
+	}
+
+	artifacts, err := checksums.Parse(raw)
+	if err != nil {
+		return nil, nil, types.ValidationError(err)
+	}
+	name := v.ChecksumsObj.Artifact.Name
+	listed, ok := checksums.Find(artifacts, name)
+	if !ok {
+		return nil, nil, types.ValidationError(fmt.Errorf("%s is not listed in the checksums file", name))
+	}
+	if hash := v.ChecksumsObj.Artifact.Hash; hash != nil {
+		if swag.StringValue(hash.Algorithm) != listed.Algorithm || strings.ToLower(swag.StringValue(hash.Value)) != listed.Digest {
+			return nil, nil, types.ValidationError(fmt.Errorf("%s is listed in the checksums file as %s:%s", name, listed.Algorithm, listed.Digest))
+		}
+	}
+
+	af, err := pki.NewArtifactFactory(pki.Format(v.ChecksumsObj.Signature.Format))
+	if err != nil {
+		return nil, nil, err
+	}
+	sigObj, err := af.NewSignature(bytes.NewReader(v.ChecksumsObj.Signature.Content))
+	if err != nil {
+		return nil, nil, types.ValidationError(err)
+	}
+	keyObj, err := af.NewPublicKey(bytes.NewReader(v.ChecksumsObj.Signature.PublicKey.Content))
+	if err != nil {
+		return nil, nil, types.ValidationError(err)
+	}
+	if err := sigObj.Verify(bytes.NewReader(raw), keyObj); err != nil {
+		return nil, nil, types.ValidationError(err)
+	}
+
+	// if we get here, the checksums file is signed and lists the artifact
+	v.ChecksumsObj.Checksums.Hash = &models.ChecksumsV001SchemaChecksumsHash{
+		Algorithm: swag.String(models.ChecksumsV001SchemaChecksumsHashAlgorithmSha256),
+		Value:     swag.String(computedSHA),
+	}
+	v.ChecksumsObj.Artifact.Hash = &models.ChecksumsV001SchemaArtifactHash{
+		Algorithm: swag.String(listed.Algorithm),
+		Value:     swag.String(listed.Digest),
+	}
+	v.raw = raw
+
+	return keyObj, sigObj, nil
+}
+
+func (v *V001Entry) Canonicalize(ctx context.Context) ([]byte, error) {
+	keyObj, sigObj, err := v.verify()
+	if err != nil {
+		return nil, err
+	}
+
+	canonicalEntry := models.ChecksumsV001Schema{}
+
+	// need to canonicalize signature & key content
+	canonicalEntry.Signature = &models.ChecksumsV001SchemaSignature{}
+	canonicalEntry.Signature.Format = v.ChecksumsObj.Signature.Format
+	canonicalEntry.Signature.Content, err = sigObj.CanonicalValue()
+	if err != nil {
+		return nil, err
+	}
+
+	canonicalEntry.Signature.PublicKey = &models.ChecksumsV001SchemaSignaturePublicKey{}
+	canonicalEntry.Signature.PublicKey.Content, err = keyObj.CanonicalValue()
+	if err != nil {
+		return nil, err
+	}
+
+	canonicalEntry.Checksums = &models.ChecksumsV001SchemaChecksums{}
+	canonicalEntry.Checksums.Hash = v.ChecksumsObj.Checksums.Hash
+	// checksums file content is not set deliberately; it is kept in attestation storage when that is enabled
+
+	canonicalEntry.Artifact = &models.ChecksumsV001SchemaArtifact{}
+	canonicalEntry.Artifact.Name = v.ChecksumsObj.Artifact.Name
+	canonicalEntry.Artifact.Hash = v.ChecksumsObj.Artifact.Hash
+
+	// wrap in valid object with kind and apiVersion set
+	c := models.Checksums{}
+	c.APIVersion = swag.String(APIVERSION)
+	c.Spec = &canonicalEntry
+
+	v.ChecksumsObj = canonicalEntry
+
+	return json.Marshal(&c)
+}
+
+// validate performs cross-field validation for fields in object
+func (v V001Entry) validate() error {
+	sig := v.ChecksumsObj.Signature
+	if sig == nil {
+		return errors.New("missing signature")
+	}
+	if len(sig.Content) == 0 {
+		return errors.New("'content' must be specified for signature")
+	}
+
+	key := sig.PublicKey
+	if key == nil {
+		return errors.New("missing public key")
+	}
+	if len(key.Content) == 0 {
+		return errors.New("'content' must be specified for publicKey")
+	}
+
+	file := v.ChecksumsObj.Checksums
+	if file == nil {
+		return errors.New("missing checksums file")
+	}
+	if hash := file.Hash; hash != nil {
+		if !govalidator.IsHash(swag.StringValue(hash.Value), swag.StringValue(hash.Algorithm)) {
+			return errors.New("invalid value for checksums file hash")
+		}
+	} else if len(file.Content) == 0 {
+		return errors.New("one of 'content' or 'hash' must be specified for checksums")
+	}
+
+	artifact := v.ChecksumsObj.Artifact
+	if artifact == nil {
+		return errors.New("missing artifact")
+	}
+	if artifact.Name == "" {
+		return errors.New("'name' must be specified for artifact")
+	}
+	if hash := artifact.Hash; hash != nil {
+		if !govalidator.IsHash(swag.StringValue(hash.Value), swag.StringValue(hash.Algorithm)) {
+			return errors.New("invalid value for artifact hash")
+		}
+	}
+
+	return nil
+}
+
+// Attestation returns the checksums file so that it can be retrieved alongside the entry
+func (v V001Entry) Attestation() []byte {
+	if len(v.raw) > viper.GetInt("max_attestation_size") {
+		log.Logger.Infof("Skipping attestation storage, size %d is greater than max %d", len(v.raw), viper.GetInt("max_attestation_size"))
+		return nil
+	}
+	return v.raw
+}
+
+// readFileOrURL reads one of the files named in the artifact properties
+func readFileOrURL(ctx context.Context, u *url.URL) ([]byte, error) {
+	if !u.IsAbs() {
+		return ioutil.ReadFile(filepath.Clean(u.Path))
+	}
+	rc, err := util.FileOrURLReadCloser(ctx, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return ioutil.ReadAll(rc)
+}
+
+func (v V001Entry) CreateFromArtifactProperties(ctx context.Context, props types.ArtifactProperties) (models.ProposedEntry, error) {
+	returnVal := models.Checksums{}
+	re := V001Entry{}
+
+	var err error
+	checksumsBytes := props.ArtifactBytes
+	if checksumsBytes == nil {
+		if props.ArtifactPath == nil {
+			return nil, errors.New("path to checksums file (file or URL) must be specified")
+		}
+		checksumsBytes, err = readFileOrURL(ctx, props.ArtifactPath)
+		if err != nil {
+			return nil, fmt.Errorf("error reading checksums file: %w", err)
+		}
+	}
+	re.ChecksumsObj.Checksums = &models.ChecksumsV001SchemaChecksums{
+		Content: strfmt.Base64(checksumsBytes),
+	}
+
+	if props.ArtifactName == "" {
+		return nil, errors.New("the name of an artifact listed in the checksums file must be specified")
+	}
+	artifacts, err := checksums.Parse(checksumsBytes)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing checksums file: %w", err)
+	}
+	listed, ok := checksums.Find(artifacts, props.ArtifactName)
+	if !ok {
+		return nil, fmt.Errorf("%s is not listed in the checksums file", props.ArtifactName)
+	}
+	if props.ArtifactHash != "" {
+		algorithm, value, err := util.ParseDigest(props.ArtifactHash)
+		if err != nil {
+			return nil, err
+		}
+		if algorithm != listed.Algorithm || value != listed.Digest {
+			return nil, fmt.Errorf("%s is listed in the checksums file as %s:%s", listed.Name, listed.Algorithm, listed.Digest)
+		}
+	}
+	re.ChecksumsObj.Artifact = &models.ChecksumsV001SchemaArtifact{
+		Name: listed.Name,
+		Hash: &models.ChecksumsV001SchemaArtifactHash{
+			Algorithm: swag.String(listed.Algorithm),
+			Value:     swag.String(listed.Digest),
+		},
+	}
+
+	re.ChecksumsObj.Signature = &models.ChecksumsV001SchemaSignature{}
+	switch props.PKIFormat {
+	case "pgp":
+		re.ChecksumsObj.Signature.Format = models.ChecksumsV001SchemaSignatureFormatPgp
+	case "minisign":
+		re.ChecksumsObj.Signature.Format = models.ChecksumsV001SchemaSignatureFormatMinisign
+	case "x509":
+		re.ChecksumsObj.Signature.Format = models.ChecksumsV001SchemaSignatureFormatX509
+	case "ssh":
+		re.ChecksumsObj.Signature.Format = models.ChecksumsV001SchemaSignatureFormatSSH
+	}
+	sigBytes := props.SignatureBytes
+	if sigBytes == nil {
+		if props.SignaturePath == nil {
+			return nil, errors.New("a detached signature over the checksums file must be provided")
+		}
+		sigBytes, err = readFileOrURL(ctx, props.SignaturePath)
+		if err != nil {
+			return nil, fmt.Errorf("error reading signature file: %w", err)
+		}
+	}
+	re.ChecksumsObj.Signature.Content = strfmt.Base64(sigBytes)
+
+	publicKeyBytes := props.PublicKeyBytes
+	if publicKeyBytes == nil {
+		if props.PublicKeyPath == nil {
+			return nil, errors.New("a public key must be provided to verify the detached signature")
+		}
+		publicKeyBytes, err = readFileOrURL(ctx, props.PublicKeyPath)
+		if err != nil {
+			return nil, fmt.Errorf("error reading public key file: %w", err)
+		}
+	}
+	re.ChecksumsObj.Signature.PublicKey = &models.ChecksumsV001SchemaSignaturePublicKey{
+		Content: strfmt.Base64(publicKeyBytes),
+	}
+
+	if err := re.validate(); err != nil {
+		return nil, err
+	}
+
+	returnVal.APIVersion = swag.String(re.APIVersion())
+	returnVal.Spec = re.ChecksumsObj
+
+	return &returnVal, nil
+}
@@ -0,0 +1,340 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package checksums
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-openapi/runtime"
+	"github.com/go-openapi/strfmt"
+	"github.com/go-openapi/swag"
+	"github.com/spf13/viper"
+	"go.uber.org/goleak"
+
+	"github.com/sigstore/rekor/pkg/generated/models"
+	"github.com/sigstore/rekor/pkg/types"
+)
+
+func TestMain(m *testing.M) {
+	goleak.VerifyTestMain(m)
+}
+
+func TestNewEntryReturnType(t *testing.T) {
+	entry := NewEntry()
+	if reflect.TypeOf(entry) != reflect.ValueOf(&V001Entry{}).Type() {
+		t.Errorf("invalid type returned from NewEntry: %T", entry)
+	}
+}
+
+var (
+	linuxDigest   = strings.Repeat("a", 64)
+	darwinDigest  = strings.Repeat("b", 128)
+	checksumsFile = []byte(linuxDigest + "  app_linux_amd64.tar.gz\n" + darwinDigest + "  app_darwin_arm64.tar.gz\n")
+)
+
+// sign returns a PEM encoded public key and a signature over each of the files made with its private key
+func sign(t *testing.T, files ...[]byte) ([]byte, [][]byte) {
+	t.Helper()
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(priv.Public())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sigs [][]byte
+	for _, file := range files {
+		h := sha256.Sum256(file)
+		sig, err := ecdsa.SignASN1(rand.Reader, priv, h[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		sigs = append(sigs, sig)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), sigs
+}
+
+func TestCrossFieldValidation(t *testing.T) {
+	type TestCase struct {
+		caseDesc                  string
+		entry                     V001Entry
+		expectUnmarshalSuccess    bool
+		expectCanonicalizeSuccess bool
+	}
+
+	otherFile := []byte(linuxDigest + "  other.tar.gz\n")
+	keyBytes, sigs := sign(t, checksumsFile, otherFile)
+	sigBytes, otherSig := sigs[0], sigs[1]
+
+	h := sha256.Sum256(checksumsFile)
+	fileSHA := hex.EncodeToString(h[:])
+
+	signature := func(sig []byte) *models.ChecksumsV001SchemaSignature {
+		return &models.ChecksumsV001SchemaSignature{
+			Format:  "x509",
+			Content: strfmt.Base64(sig),
+			PublicKey: &models.ChecksumsV001SchemaSignaturePublicKey{
+				Content: strfmt.Base64(keyBytes),
+			},
+		}
+	}
+	artifactHash := func(algorithm, value string) *models.ChecksumsV001SchemaArtifactHash {
+		return &models.ChecksumsV001SchemaArtifactHash{
+			Algorithm: swag.String(algorithm),
+			Value:     swag.String(value),
+		}
+	}
+
+	testCases := []TestCase{
+		{
+			caseDesc:               "empty obj",
+			entry:                  V001Entry{},
+			expectUnmarshalSuccess: false,
+		},
+		{
+			caseDesc: "checksums file without signature",
+			entry: V001Entry{
+				ChecksumsObj: models.ChecksumsV001Schema{
+					Checksums: &models.ChecksumsV001SchemaChecksums{Content: strfmt.Base64(checksumsFile)},
+					Artifact:  &models.ChecksumsV001SchemaArtifact{Name: "app_linux_amd64.tar.gz"},
+				},
+			},
+			expectUnmarshalSuccess: false,
+		},
+		{
+			caseDesc: "signed checksums file without artifact",
+			entry: V001Entry{
+				ChecksumsObj: models.ChecksumsV001Schema{
+					Signature: signature(sigBytes),
+					Checksums: &models.ChecksumsV001SchemaChecksums{Content: strfmt.Base64(checksumsFile)},
+				},
+			},
+			expectUnmarshalSuccess: false,
+		},
+		{
+			caseDesc: "listed artifact",
+			entry: V001Entry{
+				ChecksumsObj: models.ChecksumsV001Schema{
+					Signature: signature(sigBytes),
+					Checksums: &models.ChecksumsV001SchemaChecksums{Content: strfmt.Base64(checksumsFile)},
+					Artifact:  &models.ChecksumsV001SchemaArtifact{Name: "app_linux_amd64.tar.gz"},
+				},
+			},
+			expectUnmarshalSuccess:    true,
+			expectCanonicalizeSuccess: true,
+		},
+		{
+			caseDesc: "listed artifact with sha512 hash and checksums file hash",
+			entry: V001Entry{
+				ChecksumsObj: models.ChecksumsV001Schema{
+					Signature: signature(sigBytes),
+					Checksums: &models.ChecksumsV001SchemaChecksums{
+						Content: strfmt.Base64(checksumsFile),
+						Hash: &models.ChecksumsV001SchemaChecksumsHash{
+							Algorithm: swag.String(models.ChecksumsV001SchemaChecksumsHashAlgorithmSha256),
+							Value:     swag.String(fileSHA),
+						},
+					},
+					Artifact: &models.ChecksumsV001SchemaArtifact{
+						Name: "app_darwin_arm64.tar.gz",
+						Hash: artifactHash(models.ChecksumsV001SchemaArtifactHashAlgorithmSha512, darwinDigest),
+					},
+				},
+			},
+			expectUnmarshalSuccess:    true,
+			expectCanonicalizeSuccess: true,
+		},
+		{
+			caseDesc: "artifact hash differing from the listing",
+			entry: V001Entry{
+				ChecksumsObj: models.ChecksumsV001Schema{
+					Signature: signature(sigBytes),
+					Checksums: &models.ChecksumsV001SchemaChecksums{Content: strfmt.Base64(checksumsFile)},
+					Artifact: &models.ChecksumsV001SchemaArtifact{
+						Name: "app_linux_amd64.tar.gz",
+						Hash: artifactHash(models.ChecksumsV001SchemaArtifactHashAlgorithmSha256, strings.Repeat("c", 64)),
+					},
+				},
+			},
+			expectUnmarshalSuccess:    true,
+			expectCanonicalizeSuccess: false,
+		},
+		{
+			caseDesc: "artifact not listed",
+			entry: V001Entry{
+				ChecksumsObj: models.ChecksumsV001Schema{
+					Signature: signature(sigBytes),
+					Checksums: &models.ChecksumsV001SchemaChecksums{Content: strfmt.Base64(checksumsFile)},
+					Artifact:  &models.ChecksumsV001SchemaArtifact{Name: "other.tar.gz"},
+				},
+			},
+			expectUnmarshalSuccess:    true,
+			expectCanonicalizeSuccess: false,
+		},
+		{
+			caseDesc: "checksums file hash mismatch",
+			entry: V001Entry{
+				ChecksumsObj: models.ChecksumsV001Schema{
+					Signature: signature(sigBytes),
+					Checksums: &models.ChecksumsV001SchemaChecksums{
+						Content: strfmt.Base64(checksumsFile),
+						Hash: &models.ChecksumsV001SchemaChecksumsHash{
+							Algorithm: swag.String(models.ChecksumsV001SchemaChecksumsHashAlgorithmSha256),
+							Value:     swag.String(linuxDigest),
+						},
+					},
+					Artifact: &models.ChecksumsV001SchemaArtifact{Name: "app_linux_amd64.tar.gz"},
+				},
+			},
+			expectUnmarshalSuccess:    true,
+			expectCanonicalizeSuccess: false,
+		},
+		{
+			caseDesc: "signature over a different checksums file",
+			entry: V001Entry{
+				ChecksumsObj: models.ChecksumsV001Schema{
+					Signature: signature(otherSig),
+					Checksums: &models.ChecksumsV001SchemaChecksums{Content: strfmt.Base64(checksumsFile)},
+					Artifact:  &models.ChecksumsV001SchemaArtifact{Name: "app_linux_amd64.tar.gz"},
+				},
+			},
+			expectUnmarshalSuccess:    true,
+			expectCanonicalizeSuccess: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		v := &V001Entry{}
+		r := models.Checksums{
+			APIVersion: swag.String(tc.entry.APIVersion()),
+			Spec:       tc.entry.ChecksumsObj,
+		}
+
+		if err := v.Unmarshal(&r); (err == nil) != tc.expectUnmarshalSuccess {
+			t.Fatalf("unexpected result in '%v': %v", tc.caseDesc, err)
+		}
+		// No need to continue here if we didn't unmarshal
+		if !tc.expectUnmarshalSuccess {
+			continue
+		}
+
+		b, err := v.Canonicalize(context.TODO())
+		if (err == nil) != tc.expectCanonicalizeSuccess {
+			t.Errorf("unexpected result from Canonicalize for '%v': %v", tc.caseDesc, err)
+		} else if err != nil {
+			if _, ok := err.(types.ValidationError); !ok {
+				t.Errorf("canonicalize returned an unexpected error that isn't of type types.ValidationError: %v", err)
+			}
+		}
+		if b != nil {
+			if v.ChecksumsObj.Checksums.Content != nil {
+				t.Errorf("checksums file content should not be part of the canonical entry for '%v'", tc.caseDesc)
+			}
+			pe, err := models.UnmarshalProposedEntry(bytes.NewReader(b), runtime.JSONConsumer())
+			if err != nil {
+				t.Errorf("unexpected err from Unmarshalling canonicalized entry for '%v': %v", tc.caseDesc, err)
+			}
+			if _, err := types.NewEntry(pe); err != nil {
+				t.Errorf("unexpected err from type-specific unmarshalling for '%v': %v", tc.caseDesc, err)
+			}
+		}
+	}
+}
+
+func TestIndexKeysAndAttestation(t *testing.T) {
+	keyBytes, sigs := sign(t, checksumsFile)
+	v := &V001Entry{
+		ChecksumsObj: models.ChecksumsV001Schema{
+			Signature: &models.ChecksumsV001SchemaSignature{
+				Format:    "x509",
+				Content:   strfmt.Base64(sigs[0]),
+				PublicKey: &models.ChecksumsV001SchemaSignaturePublicKey{Content: strfmt.Base64(keyBytes)},
+			},
+			Checksums: &models.ChecksumsV001SchemaChecksums{Content: strfmt.Base64(checksumsFile)},
+			Artifact:  &models.ChecksumsV001SchemaArtifact{Name: "app_darwin_arm64.tar.gz"},
+		},
+	}
+	if _, err := v.Canonicalize(context.TODO()); err != nil {
+		t.Fatal(err)
+	}
+
+	keys, err := v.IndexKeys()
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := sha256.Sum256(checksumsFile)
+	for _, want := range []string{"sha256:" + hex.EncodeToString(h[:]), "sha512:" + darwinDigest} {
+		found := false
+		for _, k := range keys {
+			found = found || k == want
+		}
+		if !found {
+			t.Errorf("expected index key %s in %v", want, keys)
+		}
+	}
+
+	viper.Set("max_attestation_size", len(checksumsFile))
+	if !bytes.Equal(v.Attestation(), checksumsFile) {
+		t.Error("expected checksums file to be returned for attestation storage")
+	}
+	viper.Set("max_attestation_size", len(checksumsFile)-1)
+	if v.Attestation() != nil {
+		t.Error("expected checksums file larger than the maximum attestation size to be skipped")
+	}
+}
+
+func TestCreateFromArtifactProperties(t *testing.T) {
+	keyBytes, sigs := sign(t, checksumsFile)
+	props := types.ArtifactProperties{
+		ArtifactBytes:  checksumsFile,
+		ArtifactName:   "app_linux_amd64.tar.gz",
+		SignatureBytes: sigs[0],
+		PublicKeyBytes: keyBytes,
+		PKIFormat:      "x509",
+	}
+
+	pe, err := V001Entry{}.CreateFromArtifactProperties(context.TODO(), props)
+	if err != nil {
+		t.Fatal(err)
+	}
+	spec := pe.(*models.Checksums).Spec.(models.ChecksumsV001Schema)
+	if hash := spec.Artifact.Hash; swag.StringValue(hash.Algorithm) != "sha256" || swag.StringValue(hash.Value) != linuxDigest {
+		t.Errorf("unexpected artifact hash %s:%s", swag.StringValue(hash.Algorithm), swag.StringValue(hash.Value))
+	}
+
+	props.ArtifactName = "missing.tar.gz"
+	if _, err := (V001Entry{}).CreateFromArtifactProperties(context.TODO(), props); err == nil {
+		t.Error("expected an error for an artifact that is not listed")
+	}
+
+	props.ArtifactName = "app_linux_amd64.tar.gz"
+	props.ArtifactHash = "sha256:" + strings.Repeat("c", 64)
+	if _, err := (V001Entry{}).CreateFromArtifactProperties(context.TODO(), props); err == nil {
+		t.Error("expected an error for an artifact hash differing from the listing")
+	}
+}
@@ -27,6 +27,7 @@ import (
 	"github.com/go-openapi/strfmt"
 	"github.com/mitchellh/mapstructure"
 	"github.com/sigstore/rekor/pkg/generated/models"
+	"github.com/sigstore/rekor/pkg/pki"
 )
 
 // EntryImpl specifies the behavior of a versioned type
@@ -39,6 +40,12 @@ type EntryImpl interface {
 	CreateFromArtifactProperties(context.Context, ArtifactProperties) (models.ProposedEntry, error)
 }
 
+// SignerProvider is implemented by entry types which can return the public keys that signed them;
+// it is only valid to call Signers after the entry has been canonicalized
+type SignerProvider interface {
+	Signers() ([]pki.PublicKey, error)
+}
+
 // EntryFactory describes a factory function that can generate structs for a specific versioned type
 type EntryFactory func() EntryImpl
 
@@ -106,14 +113,18 @@ func DecodeEntry(input, output interface{}) error {
 	return dec.Decode(input)
 }
 
-// CanonicalizeEntry returns the entry marshalled in JSON according to the
-// canonicalization rules of RFC8785 to protect against any changes in golang's JSON
-// marshalling logic that may reorder elements
-func CanonicalizeEntry(ctx context.Context, entry EntryImpl) ([]byte, error) {
+// CanonicalizeEntry returns the entry, along with the annotations of the proposed entry it was
+// created from, marshalled in JSON according to the canonicalization rules of RFC8785 to protect
+// against any changes in golang's JSON marshalling logic that may reorder elements
+func CanonicalizeEntry(ctx context.Context, entry EntryImpl, annotations models.Annotations) ([]byte, error) {
 	canonicalEntry, err := entry.Canonicalize(ctx)
 	if err != nil {
 		return nil, err
 	}
+	canonicalEntry, err = addAnnotations(canonicalEntry, annotations)
+	if err != nil {
+		return nil, err
+	}
 	return jsoncanonicalizer.Transform(canonicalEntry)
 }
 
@@ -128,4 +139,12 @@ type ArtifactProperties struct {
 	PublicKeyPath  *url.URL
 	PublicKeyBytes []byte
 	PKIFormat      string
+	// SignaturePaths and PublicKeyPaths hold every signature and public key that was given, paired by
+	// position; SignaturePath and PublicKeyPath are the first of each
+	SignaturePaths []*url.URL
+	PublicKeyPaths []*url.URL
+	// ImageReference names a container image, e.g. ghcr.io/org/app:v1, for types that resolve it from a registry
+	ImageReference string
+	// ArtifactName is the name under which the artifact is listed in a checksums file given as ArtifactPath
+	ArtifactName string
 }
@@ -37,6 +37,7 @@ import (
 	"github.com/sigstore/rekor/pkg/pki/x509"
 	"github.com/sigstore/rekor/pkg/types"
 	hashedrekord "github.com/sigstore/rekor/pkg/types/hashedrekord"
+	"github.com/sigstore/rekor/pkg/util"
 	"github.com/sigstore/sigstore/pkg/signature/options"
 )
 
@@ -73,7 +74,7 @@ func (v V001Entry) IndexKeys() ([]string, error) {
 	if err != nil {
 		return nil, err
 	}
-	result = append(result, pub.EmailAddresses()...)
+	result = append(result, pub.Subjects()...)
 
 	if v.HashedRekordObj.Data.Hash != nil {
 		hashKey := strings.ToLower(fmt.Sprintf("%s:%s", *v.HashedRekordObj.Data.Hash.Algorithm, *v.HashedRekordObj.Data.Hash.Value))
@@ -83,6 +84,15 @@ func (v V001Entry) IndexKeys() ([]string, error) {
 	return result, nil
 }
 
+// Signers implements types.SignerProvider
+func (v V001Entry) Signers() ([]pki.PublicKey, error) {
+	pub, err := x509.NewPublicKey(bytes.NewReader(v.HashedRekordObj.Signature.PublicKey.Content))
+	if err != nil {
+		return nil, err
+	}
+	return []pki.PublicKey{pub}, nil
+}
+
 func (v *V001Entry) Unmarshal(pe models.ProposedEntry) error {
 	rekord, ok := pe.(*models.Hashedrekord)
 	if !ok {
@@ -172,11 +182,15 @@ func (v *V001Entry) validate() (pki.Signature, pki.PublicKey, error) {
 		return nil, nil, types.ValidationError(errors.New("invalid value for hash"))
 	}
 
+	hashFunc, err := util.HashFunc(swag.StringValue(hash.Algorithm))
+	if err != nil {
+		return nil, nil, types.ValidationError(err)
+	}
 	decoded, err := hex.DecodeString(*hash.Value)
 	if err != nil {
 		return nil, nil, err
 	}
-	if err := sigObj.Verify(nil, keyObj, options.WithDigest(decoded)); err != nil {
+	if err := sigObj.Verify(nil, keyObj, options.WithDigest(decoded), options.WithCryptoSignerOpts(hashFunc)); err != nil {
 		return nil, nil, types.ValidationError(errors.Wrap(err, "verifying signature"))
 	}
 
@@ -222,9 +236,14 @@ func (v V001Entry) CreateFromArtifactProperties(ctx context.Context, props types
 	}
 	re.HashedRekordObj.Signature.PublicKey.Content = strfmt.Base64(publicKeyBytes)
 
+	// the digest may be tagged with its algorithm, as in sha512:<hex>; untagged ones are told apart by length
+	algorithm, value, err := util.ParseDigest(props.ArtifactHash)
+	if err != nil {
+		return nil, err
+	}
 	re.HashedRekordObj.Data.Hash = &models.HashedrekordV001SchemaDataHash{
-		Algorithm: swag.String(models.HashedrekordV001SchemaDataHashAlgorithmSha256),
-		Value:     swag.String(props.ArtifactHash),
+		Algorithm: swag.String(algorithm),
+		Value:     swag.String(value),
 	}
 
 	if _, _, err := re.validate(); err != nil {
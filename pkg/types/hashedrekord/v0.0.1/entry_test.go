@@ -23,6 +23,7 @@ import (
 	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/sha256"
+	"crypto/sha512"
 	"crypto/x509"
 	"crypto/x509/pkix"
 	"encoding/hex"
@@ -80,6 +81,11 @@ func TestCrossFieldValidation(t *testing.T) {
 	signer, _ := signature.LoadSigner(key, crypto.SHA256)
 	sigBytes, _ := signer.SignMessage(bytes.NewReader(dataBytes))
 
+	h512 := sha512.Sum512(dataBytes)
+	dataSHA512 := hex.EncodeToString(h512[:])
+	signer512, _ := signature.LoadSigner(key, crypto.SHA512)
+	sig512Bytes, _ := signer512.SignMessage(bytes.NewReader(dataBytes))
+
 	incorrectLengthHash := sha256.Sum224(dataBytes)
 	incorrectLengthSHA := hex.EncodeToString(incorrectLengthHash[:])
 
@@ -174,6 +180,48 @@ func TestCrossFieldValidation(t *testing.T) {
 			expectUnmarshalSuccess:    true,
 			expectCanonicalizeSuccess: true,
 		},
+		{
+			caseDesc: "signature with sha512 hash",
+			entry: V001Entry{
+				HashedRekordObj: models.HashedrekordV001Schema{
+					Signature: &models.HashedrekordV001SchemaSignature{
+						Content: sig512Bytes,
+						PublicKey: &models.HashedrekordV001SchemaSignaturePublicKey{
+							Content: keyBytes,
+						},
+					},
+					Data: &models.HashedrekordV001SchemaData{
+						Hash: &models.HashedrekordV001SchemaDataHash{
+							Value:     swag.String(dataSHA512),
+							Algorithm: swag.String(models.HashedrekordV001SchemaDataHashAlgorithmSha512),
+						},
+					},
+				},
+			},
+			expectUnmarshalSuccess:    true,
+			expectCanonicalizeSuccess: true,
+		},
+		{
+			caseDesc: "sha512 hash tagged with another algorithm",
+			entry: V001Entry{
+				HashedRekordObj: models.HashedrekordV001Schema{
+					Signature: &models.HashedrekordV001SchemaSignature{
+						Content: sig512Bytes,
+						PublicKey: &models.HashedrekordV001SchemaSignaturePublicKey{
+							Content: keyBytes,
+						},
+					},
+					Data: &models.HashedrekordV001SchemaData{
+						Hash: &models.HashedrekordV001SchemaDataHash{
+							Value:     swag.String(dataSHA512),
+							Algorithm: swag.String(models.HashedrekordV001SchemaDataHashAlgorithmSha384),
+						},
+					},
+				},
+			},
+			expectUnmarshalSuccess:    false,
+			expectCanonicalizeSuccess: false,
+		},
 		{
 			caseDesc: "signature with invalid sha length",
 			entry: V001Entry{
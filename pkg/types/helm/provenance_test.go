@@ -17,7 +17,9 @@ package helm
 
 import (
 	"bytes"
+	"io/ioutil"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/sigstore/rekor/pkg/generated/models"
@@ -73,3 +75,33 @@ func TestProvenance(t *testing.T) {
 	}
 
 }
+
+func TestVerifyChart(t *testing.T) {
+	inputProvenance, err := os.Open("../../../tests/test-0.1.0.tgz.prov")
+	if err != nil {
+		t.Fatalf("could not open provenance file %v", err)
+	}
+	defer inputProvenance.Close()
+
+	provenance := Provenance{}
+	if err := provenance.Unmarshal(inputProvenance); err != nil {
+		t.Fatalf("unmarshal error: %v", err)
+	}
+	if provenance.ChartMetadata["name"] != "test" || provenance.ChartMetadata["version"] != "0.1.0" {
+		t.Errorf("unexpected chart metadata %v", provenance.ChartMetadata)
+	}
+
+	chart, err := ioutil.ReadFile("../../../tests/test-0.1.0.tgz")
+	if err != nil {
+		t.Fatalf("could not read chart %v", err)
+	}
+	if err := provenance.VerifyChart("test-0.1.0.tgz", bytes.NewReader(chart)); err != nil {
+		t.Errorf("unexpected error verifying chart: %v", err)
+	}
+	if err := provenance.VerifyChart("renamed.tgz", bytes.NewReader(chart)); err != nil {
+		t.Errorf("unexpected error verifying renamed chart: %v", err)
+	}
+	if err := provenance.VerifyChart("test-0.1.0.tgz", strings.NewReader("not the chart")); err == nil {
+		t.Error("expected error verifying a different chart")
+	}
+}
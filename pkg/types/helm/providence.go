@@ -17,6 +17,9 @@ package helm
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
 	"io"
 	"strings"
 
@@ -81,6 +84,15 @@ func (p *Provenance) parseMessageBlock(data []byte) error {
 		return errors.New("message block must have at least two parts")
 	}
 
+	metadata := map[string]interface{}{}
+	if err := yaml.Unmarshal(parts[0], &metadata); err != nil {
+		return errors.Wrap(err, "Error occurred parsing chart metadata")
+	}
+	p.ChartMetadata = map[string]string{}
+	for k, v := range metadata {
+		p.ChartMetadata[k] = fmt.Sprint(v)
+	}
+
 	sc := &SumCollection{}
 
 	err := yaml.Unmarshal(parts[1], sc)
@@ -118,3 +130,36 @@ func (p *Provenance) GetChartAlgorithmHash() (string, string, error) {
 	return "", "", errors.New("No checksums found")
 
 }
+
+// VerifyChart checks that the chart archive read from r has the digest recorded for it in the
+// provenance file. Helm records the digest under the chart's file name; if there is no entry for
+// that name but exactly one file is listed, that digest is used instead so that renamed charts can
+// still be checked.
+func (p *Provenance) VerifyChart(name string, r io.Reader) error {
+	if p.SumCollection == nil || len(p.SumCollection.Files) == 0 {
+		return errors.New("Unable to locate chart hash")
+	}
+	expected, ok := p.SumCollection.Files[name]
+	if !ok {
+		if len(p.SumCollection.Files) != 1 {
+			return fmt.Errorf("provenance file does not contain a checksum for %s", name)
+		}
+		for _, v := range p.SumCollection.Files {
+			expected = v
+		}
+	}
+
+	parts := strings.Split(expected, ":")
+	if len(parts) != 2 || parts[0] != "sha256" {
+		return errors.New("Invalid hash found in Provenance file")
+	}
+
+	hasher := sha256.New()
+	if _, err := io.Copy(hasher, r); err != nil {
+		return errors.Wrap(err, "reading chart")
+	}
+	if computed := hex.EncodeToString(hasher.Sum(nil)); !strings.EqualFold(computed, parts[1]) {
+		return fmt.Errorf("chart digest sha256:%s does not match provenance file digest %s", computed, expected)
+	}
+	return nil
+}
@@ -24,6 +24,7 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"path"
 	"path/filepath"
 	"strings"
 
@@ -82,7 +83,7 @@ func (v V001Entry) IndexKeys() ([]string, error) {
 	keyHash := sha256.Sum256(key)
 	result = append(result, strings.ToLower(hex.EncodeToString(keyHash[:])))
 
-	result = append(result, keyObj.EmailAddresses()...)
+	result = append(result, keyObj.Subjects()...)
 
 	algorithm, chartHash, err := provenance.GetChartAlgorithmHash()
 
@@ -317,6 +318,9 @@ func (v V001Entry) CreateFromArtifactProperties(ctx context.Context, props types
 	var err error
 	artifactBytes := props.ArtifactBytes
 	if artifactBytes == nil {
+		if props.ArtifactPath == nil {
+			return nil, errors.New("path to chart or provenance file (file or URL) must be specified")
+		}
 		if props.ArtifactPath.IsAbs() {
 			re.HelmObj.Chart.Provenance.URL = strfmt.URI(props.ArtifactPath.String())
 		} else {
@@ -324,15 +328,26 @@ func (v V001Entry) CreateFromArtifactProperties(ctx context.Context, props types
 			if err != nil {
 				return nil, fmt.Errorf("error reading artifact file: %w", err)
 			}
-			re.HelmObj.Chart.Provenance.Content = strfmt.Base64(artifactBytes)
 		}
-	} else {
+	}
+	if artifactBytes != nil {
+		if isChartArchive(artifactBytes) {
+			// the chart itself was given; its provenance file is the detached signature, which helm
+			// places alongside the chart by default
+			artifactBytes, err = readChartProvenance(artifactBytes, props)
+			if err != nil {
+				return nil, err
+			}
+		}
 		re.HelmObj.Chart.Provenance.Content = strfmt.Base64(artifactBytes)
 	}
 
 	re.HelmObj.PublicKey = &models.HelmV001SchemaPublicKey{}
 	publicKeyBytes := props.PublicKeyBytes
 	if publicKeyBytes == nil {
+		if props.PublicKeyPath == nil {
+			return nil, errors.New("public key must be provided to verify provenance file")
+		}
 		if props.PublicKeyPath.IsAbs() {
 			re.HelmObj.PublicKey.URL = strfmt.URI(props.PublicKeyPath.String())
 		} else {
@@ -361,3 +376,43 @@ func (v V001Entry) CreateFromArtifactProperties(ctx context.Context, props types
 
 	return &returnVal, nil
 }
+
+// isChartArchive reports whether the content is a gzip compressed chart rather than a provenance file
+func isChartArchive(b []byte) bool {
+	return len(b) > 2 && b[0] == 0x1f && b[1] == 0x8b
+}
+
+// readChartProvenance returns the provenance file for a chart, taken from the signature property or
+// from the file next to the chart, after checking that it records the digest of the chart
+func readChartProvenance(chart []byte, props types.ArtifactProperties) ([]byte, error) {
+	var err error
+	provenanceBytes := props.SignatureBytes
+	if provenanceBytes == nil {
+		var provenancePath string
+		switch {
+		case props.SignaturePath != nil && !props.SignaturePath.IsAbs():
+			provenancePath = props.SignaturePath.Path
+		case props.SignaturePath == nil && props.ArtifactPath != nil && !props.ArtifactPath.IsAbs():
+			provenancePath = props.ArtifactPath.Path + ".prov"
+		default:
+			return nil, errors.New("a local provenance file must be provided with a chart")
+		}
+		provenanceBytes, err = ioutil.ReadFile(filepath.Clean(provenancePath))
+		if err != nil {
+			return nil, fmt.Errorf("error reading provenance file: %w", err)
+		}
+	}
+
+	provenance := helm.Provenance{}
+	if err := provenance.Unmarshal(bytes.NewReader(provenanceBytes)); err != nil {
+		return nil, err
+	}
+	chartName := ""
+	if props.ArtifactPath != nil {
+		chartName = path.Base(props.ArtifactPath.Path)
+	}
+	if err := provenance.VerifyChart(chartName, bytes.NewReader(chart)); err != nil {
+		return nil, err
+	}
+	return provenanceBytes, nil
+}
@@ -22,6 +22,9 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
 
@@ -265,3 +268,50 @@ func TestCrossFieldValidation(t *testing.T) {
 		})
 	}
 }
+
+func TestCreateFromArtifactPropertiesWithChart(t *testing.T) {
+	publicKey := &url.URL{Path: "../../../../tests/test_helm_armor.pub"}
+
+	// the provenance file is found next to the chart
+	props := types.ArtifactProperties{
+		ArtifactPath:  &url.URL{Path: "../../../../tests/test-0.1.0.tgz"},
+		PublicKeyPath: publicKey,
+	}
+	pe, err := V001Entry{}.CreateFromArtifactProperties(context.TODO(), props)
+	if err != nil {
+		t.Fatalf("unexpected error creating entry from chart: %v", err)
+	}
+	provenance, _ := ioutil.ReadFile("../../../../tests/test-0.1.0.tgz.prov")
+	spec := pe.(*models.Helm).Spec.(models.HelmV001Schema)
+	if !bytes.Equal(spec.Chart.Provenance.Content, provenance) {
+		t.Error("expected provenance file to be used as the entry content")
+	}
+
+	// a chart which does not match the digest in the provenance file is rejected
+	dir := t.TempDir()
+	chart := filepath.Join(dir, "test-0.1.0.tgz")
+	if err := ioutil.WriteFile(chart, []byte{0x1f, 0x8b, 0x08, 0x00, 0x00}, 0600); err != nil {
+		t.Fatal(err)
+	}
+	props = types.ArtifactProperties{
+		ArtifactPath:  &url.URL{Path: chart},
+		SignaturePath: &url.URL{Path: "../../../../tests/test-0.1.0.tgz.prov"},
+		PublicKeyPath: publicKey,
+	}
+	if _, err := (V001Entry{}).CreateFromArtifactProperties(context.TODO(), props); err == nil {
+		t.Error("expected error creating entry from chart with mismatched digest")
+	}
+
+	// a chart without a provenance file is rejected
+	if err := os.Remove(chart); err != nil {
+		t.Fatal(err)
+	}
+	chartBytes, _ := ioutil.ReadFile("../../../../tests/test-0.1.0.tgz")
+	if err := ioutil.WriteFile(chart, chartBytes, 0600); err != nil {
+		t.Fatal(err)
+	}
+	props.SignaturePath = nil
+	if _, err := (V001Entry{}).CreateFromArtifactProperties(context.TODO(), props); err == nil {
+		t.Error("expected error creating entry from chart without provenance file")
+	}
+}
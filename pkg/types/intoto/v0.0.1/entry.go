@@ -27,6 +27,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"path/filepath"
+	"strings"
 
 	"github.com/in-toto/in-toto-golang/in_toto"
 	"github.com/secure-systems-lab/go-securesystemslib/dsse"
@@ -87,12 +88,70 @@ func (v V001Entry) IndexKeys() ([]string, error) {
 				result = append(result, alg+":"+ds)
 			}
 		}
+		if strings.HasPrefix(statement.PredicateType, slsaProvenancePredicatePrefix) {
+			result = append(result, provenanceSourceKeys(v.env.Payload)...)
+		}
 	default:
 		log.Logger.Infof("Unknown in_toto Statement Type: %s", v.env.PayloadType)
 	}
 	return result, nil
 }
 
+// slsaProvenancePredicatePrefix prefixes the predicate types of every version of SLSA provenance
+const slsaProvenancePredicatePrefix = "https://slsa.dev/provenance/"
+
+// provenanceResource is a material of SLSA provenance v0.x or a resolved dependency of v1
+type provenanceResource struct {
+	URI    string            `json:"uri"`
+	Digest map[string]string `json:"digest"`
+}
+
+// provenanceSourceKeys returns the index keys of the git commits and repositories the subjects of SLSA
+// provenance were built from, i.e. those of its git+ materials, config source or resolved dependencies
+func provenanceSourceKeys(p string) []string {
+	payload, err := base64.StdEncoding.DecodeString(p)
+	if err != nil {
+		return nil
+	}
+	var provenance struct {
+		Predicate struct {
+			Materials  []provenanceResource `json:"materials"`
+			Invocation struct {
+				ConfigSource provenanceResource `json:"configSource"`
+			} `json:"invocation"`
+			BuildDefinition struct {
+				ResolvedDependencies []provenanceResource `json:"resolvedDependencies"`
+			} `json:"buildDefinition"`
+		} `json:"predicate"`
+	}
+	if err := json.Unmarshal(payload, &provenance); err != nil {
+		return nil
+	}
+	resources := append([]provenanceResource{provenance.Predicate.Invocation.ConfigSource}, provenance.Predicate.Materials...)
+	resources = append(resources, provenance.Predicate.BuildDefinition.ResolvedDependencies...)
+
+	var keys []string
+	seen := map[string]bool{}
+	add := func(key string, err error) {
+		if err == nil && !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+	for _, r := range resources {
+		if !strings.HasPrefix(r.URI, "git+") {
+			continue
+		}
+		add(types.RepositoryIndexKey(r.URI))
+		for _, alg := range []string{"gitCommit", "sha1", "sha256"} {
+			if commit, ok := r.Digest[alg]; ok {
+				add(types.CommitIndexKey(commit))
+			}
+		}
+	}
+	return keys
+}
+
 func parseStatement(p string) (*in_toto.Statement, error) {
 	ps := in_toto.Statement{}
 	payload, err := base64.StdEncoding.DecodeString(p)
@@ -129,6 +188,14 @@ func (v *V001Entry) Unmarshal(pe models.ProposedEntry) error {
 	return v.validate()
 }
 
+// Signers implements types.SignerProvider
+func (v V001Entry) Signers() ([]pki.PublicKey, error) {
+	if v.keyObj == nil {
+		return nil, errors.New("missing public key")
+	}
+	return []pki.PublicKey{v.keyObj}, nil
+}
+
 func (v *V001Entry) Canonicalize(ctx context.Context) ([]byte, error) {
 	if v.keyObj == nil {
 		return nil, errors.New("cannot canonicalze empty key")
@@ -29,6 +29,7 @@ import (
 	"fmt"
 	"math/big"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/go-openapi/strfmt"
@@ -250,6 +251,41 @@ func TestV001Entry_IndexKeys(t *testing.T) {
 				Predicate: "hello",
 			},
 		},
+		{
+			name: "slsa provenance",
+			want: []string{
+				"repo:https://github.com/sigstore/rekor",
+				"commit:" + strings.Repeat("a", 40),
+				"repo:https://github.com/sigstore/cosign",
+			},
+			statement: in_toto.Statement{
+				StatementHeader: in_toto.StatementHeader{
+					PredicateType: "https://slsa.dev/provenance/v0.2",
+				},
+				Predicate: map[string]interface{}{
+					"invocation": map[string]interface{}{
+						"configSource": map[string]interface{}{
+							"uri":    "git+https://github.com/sigstore/rekor@refs/heads/main",
+							"digest": map[string]string{"sha1": strings.Repeat("A", 40)},
+						},
+					},
+					"materials": []interface{}{
+						map[string]interface{}{
+							"uri":    "git+https://github.com/sigstore/rekor.git",
+							"digest": map[string]string{"sha1": strings.Repeat("a", 40)},
+						},
+						map[string]interface{}{
+							"uri":    "git+https://github.com/sigstore/cosign",
+							"digest": map[string]string{"sha1": "not a commit"},
+						},
+						map[string]interface{}{
+							"uri":    "https://proxy.golang.org/github.com/spf13/cobra/@v/v1.3.0.zip",
+							"digest": map[string]string{"sha256": strings.Repeat("b", 64)},
+						},
+					},
+				},
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -31,6 +31,7 @@ import (
 	"strings"
 
 	"github.com/sigstore/rekor/pkg/log"
+	"github.com/sigstore/rekor/pkg/pki"
 	"github.com/sigstore/rekor/pkg/pki/pkcs7"
 	"github.com/sigstore/rekor/pkg/types"
 	"github.com/sigstore/rekor/pkg/types/jar"
@@ -81,14 +82,36 @@ func (v *V001Entry) IndexKeys() ([]string, error) {
 	keyHash := sha256.Sum256(key)
 	result = append(result, strings.ToLower(hex.EncodeToString(keyHash[:])))
 
+	// the signing certificate is indexed the same way as keys of other types, so that searching by the
+	// certificate or by its subject finds the archives it signed
+	if v.JARModel.Signature.PublicKey != nil && v.JARModel.Signature.PublicKey.Content != nil {
+		certHash := sha256.Sum256(*v.JARModel.Signature.PublicKey.Content)
+		result = append(result, strings.ToLower(hex.EncodeToString(certHash[:])))
+	}
+	pub, err := pkcs7.NewPublicKey(bytes.NewReader(v.JARModel.Signature.Content))
+	if err != nil {
+		return nil, err
+	}
+	result = append(result, pub.Subjects()...)
+
 	if v.JARModel.Archive.Hash != nil {
 		hashKey := strings.ToLower(fmt.Sprintf("%s:%s", *v.JARModel.Archive.Hash.Algorithm, *v.JARModel.Archive.Hash.Value))
 		result = append(result, hashKey)
 	}
+	result = append(result, types.ArtifactURLIndexKeys(v.JARModel.Archive.URL)...)
 
 	return result, nil
 }
 
+// Signers implements types.SignerProvider; the chain embedded in the PKCS7 signature is retained
+func (v *V001Entry) Signers() ([]pki.PublicKey, error) {
+	keyObj, err := pkcs7.NewPublicKey(bytes.NewReader(v.JARModel.Signature.Content))
+	if err != nil {
+		return nil, err
+	}
+	return []pki.PublicKey{keyObj}, nil
+}
+
 func (v *V001Entry) Unmarshal(pe models.ProposedEntry) error {
 	jar, ok := pe.(*models.Jar)
 	if !ok {
@@ -283,6 +306,21 @@ func extractPKCS7SignatureFromJAR(inz *zip.Reader) ([]byte, error) {
 	return nil, errors.New("unable to locate signature in JAR file")
 }
 
+// checkJavaArchive ensures the content is a zip archive with a manifest, as JAR, WAR and EAR files are,
+// so that uploading an unrelated file fails with a clear error before it is sent to the server
+func checkJavaArchive(b []byte) error {
+	zipReader, err := zip.NewReader(bytes.NewReader(b), int64(len(b)))
+	if err != nil {
+		return fmt.Errorf("not a JAR or WAR archive: %w", err)
+	}
+	for _, f := range zipReader.File {
+		if strings.EqualFold(f.Name, "META-INF/MANIFEST.MF") {
+			return nil
+		}
+	}
+	return errors.New("not a JAR or WAR archive: META-INF/MANIFEST.MF not found")
+}
+
 func (v *V001Entry) Attestation() []byte {
 	return nil
 }
@@ -314,12 +352,16 @@ func (v *V001Entry) CreateFromArtifactProperties(ctx context.Context, props type
 			if err != nil {
 				return nil, fmt.Errorf("error reading JAR file: %w", err)
 			}
-			//TODO: ensure this is a valid JAR file; look for META-INF/MANIFEST.MF?
 			re.JARModel.Archive.Content = strfmt.Base64(artifactBytes)
 		}
 	} else {
 		re.JARModel.Archive.Content = strfmt.Base64(artifactBytes)
 	}
+	if artifactBytes != nil {
+		if err := checkJavaArchive(artifactBytes); err != nil {
+			return nil, err
+		}
+	}
 
 	if err := re.validate(); err != nil {
 		return nil, err
@@ -16,6 +16,7 @@
 package jar
 
 import (
+	"archive/zip"
 	"bytes"
 	"context"
 	"crypto/sha256"
@@ -222,3 +223,59 @@ func TestCrossFieldValidation(t *testing.T) {
 		}
 	}
 }
+
+func TestIndexKeys(t *testing.T) {
+	jarBytes, _ := ioutil.ReadFile("../../../../tests/test.jar")
+	h := sha256.Sum256(jarBytes)
+
+	v := &V001Entry{
+		JARModel: models.JarV001Schema{
+			Archive: &models.JarV001SchemaArchive{
+				Content: strfmt.Base64(jarBytes),
+			},
+		},
+	}
+	if _, err := v.Canonicalize(context.TODO()); err != nil {
+		t.Fatalf("unexpected error canonicalizing JAR: %v", err)
+	}
+	keys, err := v.IndexKeys()
+	if err != nil {
+		t.Fatalf("unexpected error getting index keys: %v", err)
+	}
+
+	certHash := sha256.Sum256(*v.JARModel.Signature.PublicKey.Content)
+	for _, want := range []string{"sha256:" + hex.EncodeToString(h[:]), hex.EncodeToString(certHash[:])} {
+		found := false
+		for _, k := range keys {
+			if k == want {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("missing index key %s, got %v", want, keys)
+		}
+	}
+}
+
+func TestCreateFromArtifactProperties(t *testing.T) {
+	jarBytes, _ := ioutil.ReadFile("../../../../tests/test.jar")
+	if _, err := (&V001Entry{}).CreateFromArtifactProperties(context.TODO(), types.ArtifactProperties{ArtifactBytes: jarBytes}); err != nil {
+		t.Errorf("unexpected error creating entry from JAR: %v", err)
+	}
+
+	buf := &bytes.Buffer{}
+	zw := zip.NewWriter(buf)
+	w, err := zw.Create("README.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = w.Write([]byte("not a java archive"))
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	for name, b := range map[string][]byte{"zip without manifest": buf.Bytes(), "not a zip": []byte("hello")} {
+		if _, err := (&V001Entry{}).CreateFromArtifactProperties(context.TODO(), types.ArtifactProperties{ArtifactBytes: b}); err == nil {
+			t.Errorf("expected error creating entry from %s", name)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oci
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// CosignSignatureType is the type recorded in payloads produced by cosign
+const CosignSignatureType = "cosign container image signature"
+
+// Payload is the "simple signing" document that cosign signs for a container image
+type Payload struct {
+	Critical Critical               `json:"critical"`
+	Optional map[string]interface{} `json:"optional"`
+}
+
+type Critical struct {
+	Identity Identity `json:"identity"`
+	Image    Image    `json:"image"`
+	Type     string   `json:"type"`
+}
+
+type Identity struct {
+	DockerReference string `json:"docker-reference"`
+}
+
+type Image struct {
+	DockerManifestDigest string `json:"docker-manifest-digest"`
+}
+
+// NewPayload returns the payload cosign would sign for the reference at the given digest
+func NewPayload(ref *Reference, digest string) ([]byte, error) {
+	p := Payload{
+		Critical: Critical{
+			Identity: Identity{DockerReference: ref.Name()},
+			Image:    Image{DockerManifestDigest: digest},
+			Type:     CosignSignatureType,
+		},
+	}
+	return json.Marshal(p)
+}
+
+// ParsePayload decodes a signed payload and checks that it describes a container image signature
+func ParsePayload(raw []byte) (*Payload, error) {
+	p := &Payload{}
+	if err := json.Unmarshal(raw, p); err != nil {
+		return nil, fmt.Errorf("parsing signature payload: %w", err)
+	}
+	if p.Critical.Type != CosignSignatureType {
+		return nil, fmt.Errorf("unsupported signature payload type %q", p.Critical.Type)
+	}
+	if !digestRegex.MatchString(p.Critical.Image.DockerManifestDigest) {
+		return nil, fmt.Errorf("invalid manifest digest %q in signature payload", p.Critical.Image.DockerManifestDigest)
+	}
+	return p, nil
+}
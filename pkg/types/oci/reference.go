@@ -0,0 +1,132 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oci
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	defaultRegistry = "index.docker.io"
+	defaultTag      = "latest"
+)
+
+var (
+	repositoryRegex = regexp.MustCompile(`^[a-z0-9]+(?:(?:[._]|__|[-]*)[a-z0-9]+)*(?:/[a-z0-9]+(?:(?:[._]|__|[-]*)[a-z0-9]+)*)*$`)
+	tagRegex        = regexp.MustCompile(`^[\w][\w.-]{0,127}$`)
+	digestRegex     = regexp.MustCompile(`^sha256:[0-9a-f]{64}$`)
+)
+
+// Reference is a parsed container image reference of the form [registry/]repository[:tag][@digest]
+type Reference struct {
+	Registry   string
+	Repository string
+	Tag        string
+	Digest     string
+}
+
+// ParseReference parses an image reference using the same defaulting rules as the docker CLI:
+// references without a registry resolve to Docker Hub, and references without a tag or digest
+// resolve to the "latest" tag
+func ParseReference(ref string) (*Reference, error) {
+	if ref == "" {
+		return nil, fmt.Errorf("image reference must not be empty")
+	}
+	r := &Reference{}
+
+	remainder := ref
+	if i := strings.Index(remainder, "@"); i != -1 {
+		r.Digest = remainder[i+1:]
+		remainder = remainder[:i]
+		if !digestRegex.MatchString(r.Digest) {
+			return nil, fmt.Errorf("invalid digest %q in image reference", r.Digest)
+		}
+	}
+
+	// a colon after the last slash separates the tag; one before it is a registry port
+	if i := strings.LastIndex(remainder, ":"); i != -1 && i > strings.LastIndex(remainder, "/") {
+		r.Tag = remainder[i+1:]
+		remainder = remainder[:i]
+		if !tagRegex.MatchString(r.Tag) {
+			return nil, fmt.Errorf("invalid tag %q in image reference", r.Tag)
+		}
+	}
+
+	// the first path component is a registry host if it looks like one
+	parts := strings.SplitN(remainder, "/", 2)
+	if len(parts) == 2 && (strings.ContainsAny(parts[0], ".:") || parts[0] == "localhost") {
+		r.Registry = parts[0]
+		r.Repository = parts[1]
+	} else {
+		r.Registry = defaultRegistry
+		r.Repository = remainder
+	}
+	if r.Registry == "docker.io" {
+		r.Registry = defaultRegistry
+	}
+	if r.Registry == defaultRegistry && !strings.Contains(r.Repository, "/") {
+		r.Repository = "library/" + r.Repository
+	}
+	if !repositoryRegex.MatchString(r.Repository) {
+		return nil, fmt.Errorf("invalid repository %q in image reference", r.Repository)
+	}
+
+	if r.Tag == "" && r.Digest == "" {
+		r.Tag = defaultTag
+	}
+	return r, nil
+}
+
+// Name returns the fully qualified repository name, which cosign records as the docker-reference
+// of a signature payload
+func (r Reference) Name() string {
+	return r.Registry + "/" + r.Repository
+}
+
+// String returns the fully qualified form of the reference
+func (r Reference) String() string {
+	s := r.Name()
+	if r.Tag != "" {
+		s += ":" + r.Tag
+	}
+	if r.Digest != "" {
+		s += "@" + r.Digest
+	}
+	return s
+}
+
+// identifier returns the digest if one is known, otherwise the tag, for use in manifest URLs
+func (r Reference) identifier() string {
+	if r.Digest != "" {
+		return r.Digest
+	}
+	return r.Tag
+}
+
+// scheme returns the URL scheme used to reach the registry; loopback registries are assumed to be
+// serving plain HTTP as is conventional for local development
+func (r Reference) scheme() string {
+	host := r.Registry
+	if i := strings.LastIndex(host, ":"); i != -1 {
+		host = host[:i]
+	}
+	if host == "localhost" || host == "127.0.0.1" {
+		return "http"
+	}
+	return "https"
+}
@@ -0,0 +1,99 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oci
+
+import (
+	"testing"
+)
+
+const testDigest = "sha256:e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+func TestParseReference(t *testing.T) {
+	tests := []struct {
+		ref     string
+		want    Reference
+		wantErr bool
+	}{
+		{ref: "busybox", want: Reference{Registry: "index.docker.io", Repository: "library/busybox", Tag: "latest"}},
+		{ref: "docker.io/busybox:1.34", want: Reference{Registry: "index.docker.io", Repository: "library/busybox", Tag: "1.34"}},
+		{ref: "sigstore/rekor-cli", want: Reference{Registry: "index.docker.io", Repository: "sigstore/rekor-cli", Tag: "latest"}},
+		{ref: "gcr.io/projectsigstore/rekor-server:v0.4.0", want: Reference{Registry: "gcr.io", Repository: "projectsigstore/rekor-server", Tag: "v0.4.0"}},
+		{ref: "localhost:5000/app", want: Reference{Registry: "localhost:5000", Repository: "app", Tag: "latest"}},
+		{ref: "ghcr.io/org/app@" + testDigest, want: Reference{Registry: "ghcr.io", Repository: "org/app", Digest: testDigest}},
+		{ref: "ghcr.io/org/app:v1@" + testDigest, want: Reference{Registry: "ghcr.io", Repository: "org/app", Tag: "v1", Digest: testDigest}},
+		{ref: "", wantErr: true},
+		{ref: "ghcr.io/Org/app", wantErr: true},
+		{ref: "ghcr.io/org/app:", wantErr: true},
+		{ref: "ghcr.io/org/app@sha256:abc", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			got, err := ParseReference(tt.ref)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseReference() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && *got != tt.want {
+				t.Errorf("ParseReference() = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReferenceString(t *testing.T) {
+	ref, err := ParseReference("busybox")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ref.Name() != "index.docker.io/library/busybox" {
+		t.Errorf("unexpected name %s", ref.Name())
+	}
+	if ref.String() != "index.docker.io/library/busybox:latest" {
+		t.Errorf("unexpected string %s", ref.String())
+	}
+	if ref.scheme() != "https" {
+		t.Errorf("unexpected scheme %s", ref.scheme())
+	}
+	local, _ := ParseReference("127.0.0.1:5000/app")
+	if local.scheme() != "http" {
+		t.Errorf("unexpected scheme for loopback registry %s", local.scheme())
+	}
+}
+
+func TestPayloadRoundTrip(t *testing.T) {
+	ref, _ := ParseReference("ghcr.io/org/app:v1")
+	raw, err := NewPayload(ref, testDigest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"critical":{"identity":{"docker-reference":"ghcr.io/org/app"},"image":{"docker-manifest-digest":"` + testDigest + `"},"type":"cosign container image signature"},"optional":null}`
+	if string(raw) != want {
+		t.Errorf("NewPayload() = %s, want %s", raw, want)
+	}
+	p, err := ParsePayload(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Critical.Image.DockerManifestDigest != testDigest {
+		t.Errorf("unexpected digest %s", p.Critical.Image.DockerManifestDigest)
+	}
+
+	if _, err := ParsePayload([]byte(`{"critical":{"type":"something else"}}`)); err == nil {
+		t.Error("expected error for unknown payload type")
+	}
+	if _, err := ParsePayload([]byte(`{"critical":{"type":"cosign container image signature","image":{"docker-manifest-digest":"sha256:abc"}}}`)); err == nil {
+		t.Error("expected error for invalid digest")
+	}
+}
@@ -0,0 +1,215 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oci
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// manifests larger than this are rejected when the registry does not report a digest
+const maxManifestSize = 4 * 1024 * 1024
+
+var manifestMediaTypes = []string{
+	"application/vnd.oci.image.manifest.v1+json",
+	"application/vnd.oci.image.index.v1+json",
+	"application/vnd.docker.distribution.manifest.v2+json",
+	"application/vnd.docker.distribution.manifest.list.v2+json",
+}
+
+// ResolveDigest returns the manifest digest the registry currently serves for the reference. If the
+// reference already pins a digest it is returned without contacting the registry. Anonymous bearer
+// tokens are requested from the registry's token service when it asks for one.
+func ResolveDigest(ctx context.Context, client *http.Client, ref *Reference) (string, error) {
+	if ref.Digest != "" {
+		return ref.Digest, nil
+	}
+	if client == nil {
+		client = http.DefaultClient
+	}
+	manifestURL := fmt.Sprintf("%s://%s/v2/%s/manifests/%s", ref.scheme(), ref.Registry, ref.Repository, ref.identifier())
+
+	token := ""
+	resolve := func(method string) (string, error) {
+		resp, err := fetchManifest(ctx, client, method, manifestURL, token)
+		if err != nil {
+			return "", err
+		}
+		if resp.StatusCode == http.StatusUnauthorized && token == "" {
+			challenge := resp.Header.Get("WWW-Authenticate")
+			resp.Body.Close()
+			if token, err = fetchAnonymousToken(ctx, client, challenge); err != nil {
+				return "", err
+			}
+			if resp, err = fetchManifest(ctx, client, method, manifestURL, token); err != nil {
+				return "", err
+			}
+		}
+		defer resp.Body.Close()
+		return digestFromResponse(resp)
+	}
+
+	digest, err := resolve(http.MethodHead)
+	if err != nil {
+		return "", err
+	}
+	if digest == "" {
+		// some registries omit Docker-Content-Digest on HEAD; fall back to hashing the manifest
+		if digest, err = resolve(http.MethodGet); err != nil {
+			return "", err
+		}
+	}
+	return digest, nil
+}
+
+func fetchManifest(ctx context.Context, client *http.Client, method, manifestURL, token string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, manifestURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", strings.Join(manifestMediaTypes, ","))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetching manifest: %w", err)
+	}
+	return resp, nil
+}
+
+// digestFromResponse returns the digest of the manifest in the response, preferring the header
+// supplied by the registry and otherwise hashing the body. An empty digest with no error means the
+// response did not carry enough information to determine one.
+func digestFromResponse(resp *http.Response) (string, error) {
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusUnauthorized:
+		return "", fmt.Errorf("registry denied access to manifest")
+	case http.StatusNotFound:
+		return "", fmt.Errorf("manifest not found in registry")
+	default:
+		return "", fmt.Errorf("unexpected status fetching manifest: %s", resp.Status)
+	}
+
+	if d := resp.Header.Get("Docker-Content-Digest"); d != "" {
+		if !digestRegex.MatchString(d) {
+			return "", fmt.Errorf("registry returned unsupported digest %q", d)
+		}
+		return d, nil
+	}
+	if resp.Request != nil && resp.Request.Method == http.MethodHead {
+		return "", nil
+	}
+
+	hasher := sha256.New()
+	n, err := io.Copy(hasher, io.LimitReader(resp.Body, maxManifestSize+1))
+	if err != nil {
+		return "", fmt.Errorf("reading manifest: %w", err)
+	}
+	if n > maxManifestSize {
+		return "", fmt.Errorf("manifest exceeds maximum size of %d bytes", maxManifestSize)
+	}
+	return "sha256:" + hex.EncodeToString(hasher.Sum(nil)), nil
+}
+
+// fetchAnonymousToken follows a bearer challenge of the form
+// Bearer realm="https://auth.example.com/token",service="registry.example.com",scope="repository:foo:pull"
+func fetchAnonymousToken(ctx context.Context, client *http.Client, challenge string) (string, error) {
+	if !strings.HasPrefix(strings.ToLower(challenge), "bearer ") {
+		return "", fmt.Errorf("registry requires unsupported authentication %q", challenge)
+	}
+	params := parseChallenge(challenge[len("bearer "):])
+	realm, err := url.Parse(params["realm"])
+	if err != nil || params["realm"] == "" {
+		return "", fmt.Errorf("invalid realm in registry authentication challenge")
+	}
+	q := realm.Query()
+	for _, k := range []string{"service", "scope"} {
+		if v, ok := params[k]; ok {
+			q.Set(k, v)
+		}
+	}
+	realm.RawQuery = q.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, realm.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("fetching registry token: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status fetching registry token: %s", resp.Status)
+	}
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxManifestSize))
+	if err != nil {
+		return "", err
+	}
+	tokenResp := struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}{}
+	if err := json.Unmarshal(body, &tokenResp); err != nil {
+		return "", fmt.Errorf("parsing registry token: %w", err)
+	}
+	if tokenResp.Token != "" {
+		return tokenResp.Token, nil
+	}
+	if tokenResp.AccessToken != "" {
+		return tokenResp.AccessToken, nil
+	}
+	return "", fmt.Errorf("registry token response did not contain a token")
+}
+
+// parseChallenge splits the comma separated key="value" pairs of an authentication challenge
+func parseChallenge(s string) map[string]string {
+	params := map[string]string{}
+	for s != "" {
+		s = strings.TrimLeft(s, " ,")
+		eq := strings.Index(s, "=")
+		if eq == -1 {
+			break
+		}
+		key := strings.ToLower(strings.TrimSpace(s[:eq]))
+		s = s[eq+1:]
+		var value string
+		if strings.HasPrefix(s, `"`) {
+			end := strings.Index(s[1:], `"`)
+			if end == -1 {
+				value, s = s[1:], ""
+			} else {
+				value, s = s[1:end+1], s[end+2:]
+			}
+		} else if comma := strings.Index(s, ","); comma != -1 {
+			value, s = s[:comma], s[comma:]
+		} else {
+			value, s = s, ""
+		}
+		params[key] = value
+	}
+	return params
+}
@@ -0,0 +1,121 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oci
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const testManifest = `{"schemaVersion":2,"mediaType":"application/vnd.oci.image.manifest.v1+json"}`
+
+func testRegistry(t *testing.T, sendDigest, requireAuth bool) *httptest.Server {
+	t.Helper()
+	sum := sha256.Sum256([]byte(testManifest))
+	digest := "sha256:" + hex.EncodeToString(sum[:])
+
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/token":
+			if r.URL.Query().Get("scope") != "repository:org/app:pull" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			fmt.Fprint(w, `{"token":"anonymous"}`)
+		case r.URL.Path == "/v2/org/app/manifests/v1":
+			if requireAuth && r.Header.Get("Authorization") != "Bearer anonymous" {
+				w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="test",scope="repository:org/app:pull"`, srv.URL))
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			if !strings.Contains(r.Header.Get("Accept"), "application/vnd.oci.image.manifest.v1+json") {
+				w.WriteHeader(http.StatusNotAcceptable)
+				return
+			}
+			if sendDigest {
+				w.Header().Set("Docker-Content-Digest", digest)
+			}
+			fmt.Fprint(w, testManifest)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestResolveDigest(t *testing.T) {
+	sum := sha256.Sum256([]byte(testManifest))
+	want := "sha256:" + hex.EncodeToString(sum[:])
+
+	for _, tt := range []struct {
+		name        string
+		sendDigest  bool
+		requireAuth bool
+	}{
+		{name: "digest header", sendDigest: true},
+		{name: "hashed manifest", sendDigest: false},
+		{name: "anonymous token", sendDigest: true, requireAuth: true},
+		{name: "anonymous token without digest header", requireAuth: true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := testRegistry(t, tt.sendDigest, tt.requireAuth)
+			ref, err := ParseReference(strings.TrimPrefix(srv.URL, "http://") + "/org/app:v1")
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := ResolveDigest(context.Background(), srv.Client(), ref)
+			if err != nil {
+				t.Fatalf("ResolveDigest() error = %v", err)
+			}
+			if got != want {
+				t.Errorf("ResolveDigest() = %s, want %s", got, want)
+			}
+		})
+	}
+
+	srv := testRegistry(t, true, false)
+	ref, _ := ParseReference(strings.TrimPrefix(srv.URL, "http://") + "/org/missing:v1")
+	if _, err := ResolveDigest(context.Background(), srv.Client(), ref); err == nil {
+		t.Error("expected error for missing manifest")
+	}
+
+	pinned, _ := ParseReference("registry.invalid/org/app@" + testDigest)
+	if got, err := ResolveDigest(context.Background(), nil, pinned); err != nil || got != testDigest {
+		t.Errorf("expected pinned digest without contacting registry, got %s, %v", got, err)
+	}
+}
+
+func TestParseChallenge(t *testing.T) {
+	got := parseChallenge(`realm="https://auth.docker.io/token",service="registry.docker.io",scope="repository:library/busybox:pull"`)
+	want := map[string]string{
+		"realm":   "https://auth.docker.io/token",
+		"service": "registry.docker.io",
+		"scope":   "repository:library/busybox:pull",
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("parseChallenge()[%s] = %q, want %q", k, got[k], v)
+		}
+	}
+}
@@ -0,0 +1,278 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oci
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/go-openapi/swag"
+	"github.com/pkg/errors"
+
+	"github.com/sigstore/rekor/pkg/generated/models"
+	"github.com/sigstore/rekor/pkg/log"
+	"github.com/sigstore/rekor/pkg/pki"
+	"github.com/sigstore/rekor/pkg/pki/x509"
+	"github.com/sigstore/rekor/pkg/types"
+	"github.com/sigstore/rekor/pkg/types/oci"
+)
+
+const (
+	APIVERSION = "0.0.1"
+)
+
+func init() {
+	if err := oci.VersionMap.SetEntryFactory(APIVERSION, NewEntry); err != nil {
+		log.Logger.Panic(err)
+	}
+}
+
+type V001Entry struct {
+	OCIObj models.OCIV001Schema
+}
+
+func (v V001Entry) APIVersion() string {
+	return APIVERSION
+}
+
+func NewEntry() types.EntryImpl {
+	return &V001Entry{}
+}
+
+func (v V001Entry) IndexKeys() ([]string, error) {
+	var result []string
+
+	result = append(result, strings.ToLower(swag.StringValue(v.OCIObj.Image.Digest)))
+
+	key := v.OCIObj.Signature.PublicKey.Content
+	keyHash := sha256.Sum256(key)
+	result = append(result, strings.ToLower(hex.EncodeToString(keyHash[:])))
+
+	pub, err := x509.NewPublicKey(bytes.NewReader(key))
+	if err != nil {
+		return nil, err
+	}
+	result = append(result, pub.Subjects()...)
+
+	if v.OCIObj.Payload != nil {
+		payloadHash := sha256.Sum256(*v.OCIObj.Payload)
+		result = append(result, "sha256:"+hex.EncodeToString(payloadHash[:]))
+	}
+
+	return result, nil
+}
+
+// Signers implements types.SignerProvider
+func (v V001Entry) Signers() ([]pki.PublicKey, error) {
+	pub, err := x509.NewPublicKey(bytes.NewReader(v.OCIObj.Signature.PublicKey.Content))
+	if err != nil {
+		return nil, err
+	}
+	return []pki.PublicKey{pub}, nil
+}
+
+func (v *V001Entry) Unmarshal(pe models.ProposedEntry) error {
+	img, ok := pe.(*models.OCI)
+	if !ok {
+		return errors.New("cannot unmarshal non OCI v0.0.1 type")
+	}
+
+	if err := types.DecodeEntry(img.Spec, &v.OCIObj); err != nil {
+		return err
+	}
+
+	// field validation
+	if err := v.OCIObj.Validate(strfmt.Default); err != nil {
+		return err
+	}
+
+	// cross field validation
+	_, _, err := v.validate()
+	return err
+}
+
+func (v *V001Entry) Canonicalize(ctx context.Context) ([]byte, error) {
+	sigObj, keyObj, err := v.validate()
+	if err != nil {
+		return nil, types.ValidationError(err)
+	}
+
+	canonicalEntry := models.OCIV001Schema{}
+
+	canonicalEntry.Signature = &models.OCIV001SchemaSignature{}
+	canonicalEntry.Signature.Content, err = sigObj.CanonicalValue()
+	if err != nil {
+		return nil, err
+	}
+
+	canonicalEntry.Signature.PublicKey = &models.OCIV001SchemaSignaturePublicKey{}
+	canonicalEntry.Signature.PublicKey.Content, err = keyObj.CanonicalValue()
+	if err != nil {
+		return nil, err
+	}
+
+	// the payload is small and is what the signature covers, so it is kept in the log
+	canonicalEntry.Payload = v.OCIObj.Payload
+	canonicalEntry.Image = &models.OCIV001SchemaImage{
+		Digest: swag.String(strings.ToLower(swag.StringValue(v.OCIObj.Image.Digest))),
+	}
+
+	v.OCIObj = canonicalEntry
+	// wrap in valid object with kind and apiVersion set
+	ociObj := models.OCI{}
+	ociObj.APIVersion = swag.String(APIVERSION)
+	ociObj.Spec = &canonicalEntry
+
+	return json.Marshal(&ociObj)
+}
+
+// validate performs cross-field validation for fields in object
+func (v *V001Entry) validate() (pki.Signature, pki.PublicKey, error) {
+	sig := v.OCIObj.Signature
+	if sig == nil {
+		return nil, nil, types.ValidationError(errors.New("missing signature"))
+	}
+	// cosign signatures are always x509 signature types
+	sigObj, err := x509.NewSignature(bytes.NewReader(sig.Content))
+	if err != nil {
+		return nil, nil, types.ValidationError(err)
+	}
+
+	key := sig.PublicKey
+	if key == nil {
+		return nil, nil, types.ValidationError(errors.New("missing public key"))
+	}
+	keyObj, err := x509.NewPublicKey(bytes.NewReader(key.Content))
+	if err != nil {
+		return nil, nil, types.ValidationError(err)
+	}
+
+	if v.OCIObj.Image == nil || v.OCIObj.Image.Digest == nil {
+		return nil, nil, types.ValidationError(errors.New("missing image digest"))
+	}
+	if v.OCIObj.Payload == nil || len(*v.OCIObj.Payload) == 0 {
+		return nil, nil, types.ValidationError(errors.New("missing payload"))
+	}
+
+	if err := sigObj.Verify(bytes.NewReader(*v.OCIObj.Payload), keyObj); err != nil {
+		return nil, nil, types.ValidationError(errors.Wrap(err, "verifying signature"))
+	}
+
+	payload, err := oci.ParsePayload(*v.OCIObj.Payload)
+	if err != nil {
+		return nil, nil, types.ValidationError(err)
+	}
+	if !strings.EqualFold(payload.Critical.Image.DockerManifestDigest, *v.OCIObj.Image.Digest) {
+		return nil, nil, types.ValidationError(fmt.Errorf("payload is for manifest %s, not %s", payload.Critical.Image.DockerManifestDigest, *v.OCIObj.Image.Digest))
+	}
+
+	return sigObj, keyObj, nil
+}
+
+func (v V001Entry) Attestation() []byte {
+	return nil
+}
+
+func (v V001Entry) CreateFromArtifactProperties(ctx context.Context, props types.ArtifactProperties) (models.ProposedEntry, error) {
+	returnVal := models.OCI{}
+	re := V001Entry{}
+
+	var err error
+	var ref *oci.Reference
+	digest := ""
+	if props.ImageReference != "" {
+		ref, err = oci.ParseReference(props.ImageReference)
+		if err != nil {
+			return nil, err
+		}
+		digest, err = oci.ResolveDigest(ctx, nil, ref)
+		if err != nil {
+			return nil, fmt.Errorf("resolving image digest: %w", err)
+		}
+	}
+
+	// the payload is the artifact that was signed; if one isn't given we construct the payload that
+	// cosign would have signed for the image
+	payloadBytes := props.ArtifactBytes
+	if payloadBytes == nil && props.ArtifactPath != nil {
+		payloadBytes, err = ioutil.ReadFile(filepath.Clean(props.ArtifactPath.Path))
+		if err != nil {
+			return nil, fmt.Errorf("error reading signature payload: %w", err)
+		}
+	}
+	if payloadBytes == nil {
+		if ref == nil {
+			return nil, errors.New("an image reference or a signature payload must be provided")
+		}
+		payloadBytes, err = oci.NewPayload(ref, digest)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if digest == "" {
+		payload, err := oci.ParsePayload(payloadBytes)
+		if err != nil {
+			return nil, err
+		}
+		digest = payload.Critical.Image.DockerManifestDigest
+	}
+	payloadB64 := strfmt.Base64(payloadBytes)
+	re.OCIObj.Payload = &payloadB64
+	re.OCIObj.Image = &models.OCIV001SchemaImage{Digest: swag.String(digest)}
+
+	re.OCIObj.Signature = &models.OCIV001SchemaSignature{}
+	sigBytes := props.SignatureBytes
+	if sigBytes == nil {
+		if props.SignaturePath == nil {
+			return nil, errors.New("a detached signature must be provided")
+		}
+		sigBytes, err = ioutil.ReadFile(filepath.Clean(props.SignaturePath.Path))
+		if err != nil {
+			return nil, fmt.Errorf("error reading signature file: %w", err)
+		}
+	}
+	re.OCIObj.Signature.Content = strfmt.Base64(sigBytes)
+
+	re.OCIObj.Signature.PublicKey = &models.OCIV001SchemaSignaturePublicKey{}
+	publicKeyBytes := props.PublicKeyBytes
+	if publicKeyBytes == nil {
+		if props.PublicKeyPath == nil {
+			return nil, errors.New("public key must be provided to verify detached signature")
+		}
+		publicKeyBytes, err = ioutil.ReadFile(filepath.Clean(props.PublicKeyPath.Path))
+		if err != nil {
+			return nil, fmt.Errorf("error reading public key file: %w", err)
+		}
+	}
+	re.OCIObj.Signature.PublicKey.Content = strfmt.Base64(publicKeyBytes)
+
+	if _, _, err := re.validate(); err != nil {
+		return nil, err
+	}
+
+	returnVal.APIVersion = swag.String(re.APIVersion())
+	returnVal.Spec = re.OCIObj
+
+	return &returnVal, nil
+}
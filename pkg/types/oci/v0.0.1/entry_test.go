@@ -0,0 +1,325 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oci
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"reflect"
+	"testing"
+
+	"github.com/go-openapi/runtime"
+	"github.com/go-openapi/strfmt"
+	"github.com/go-openapi/swag"
+	"github.com/sigstore/rekor/pkg/generated/models"
+	"github.com/sigstore/rekor/pkg/types"
+	"github.com/sigstore/rekor/pkg/types/oci"
+	"github.com/sigstore/sigstore/pkg/signature"
+	"go.uber.org/goleak"
+)
+
+const (
+	testDigest  = "sha256:e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+	otherDigest = "sha256:a3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+)
+
+func TestMain(m *testing.M) {
+	goleak.VerifyTestMain(m)
+}
+
+func TestNewEntryReturnType(t *testing.T) {
+	entry := NewEntry()
+	if reflect.TypeOf(entry) != reflect.ValueOf(&V001Entry{}).Type() {
+		t.Errorf("invalid type returned from NewEntry: %T", entry)
+	}
+}
+
+func testKey(t *testing.T) (signature.Signer, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyBytes := pem.EncodeToMemory(&pem.Block{
+		Bytes: der,
+		Type:  "PUBLIC KEY",
+	})
+	signer, err := signature.LoadSigner(key, crypto.SHA256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signer, keyBytes
+}
+
+func testPayload(t *testing.T, digest string) strfmt.Base64 {
+	ref, err := oci.ParseReference("ghcr.io/org/app@" + digest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := oci.NewPayload(ref, digest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strfmt.Base64(p)
+}
+
+func TestCrossFieldValidation(t *testing.T) {
+	type TestCase struct {
+		caseDesc                  string
+		entry                     V001Entry
+		expectUnmarshalSuccess    bool
+		expectCanonicalizeSuccess bool
+	}
+
+	signer, keyBytes := testKey(t)
+
+	payload := testPayload(t, testDigest)
+	sigBytes, _ := signer.SignMessage(bytes.NewReader(payload))
+
+	otherPayload := testPayload(t, otherDigest)
+	otherSigBytes, _ := signer.SignMessage(bytes.NewReader(otherPayload))
+
+	notCosign := strfmt.Base64(`{"critical":{"type":"something else"}}`)
+	notCosignSig, _ := signer.SignMessage(bytes.NewReader(notCosign))
+
+	testCases := []TestCase{
+		{
+			caseDesc:               "empty obj",
+			entry:                  V001Entry{},
+			expectUnmarshalSuccess: false,
+		},
+		{
+			caseDesc: "signature without public key",
+			entry: V001Entry{
+				OCIObj: models.OCIV001Schema{
+					Image:   &models.OCIV001SchemaImage{Digest: swag.String(testDigest)},
+					Payload: &payload,
+					Signature: &models.OCIV001SchemaSignature{
+						Content: sigBytes,
+					},
+				},
+			},
+			expectUnmarshalSuccess: false,
+		},
+		{
+			caseDesc: "signature without payload",
+			entry: V001Entry{
+				OCIObj: models.OCIV001Schema{
+					Image: &models.OCIV001SchemaImage{Digest: swag.String(testDigest)},
+					Signature: &models.OCIV001SchemaSignature{
+						Content:   sigBytes,
+						PublicKey: &models.OCIV001SchemaSignaturePublicKey{Content: keyBytes},
+					},
+				},
+			},
+			expectUnmarshalSuccess: false,
+		},
+		{
+			caseDesc: "valid signature over payload",
+			entry: V001Entry{
+				OCIObj: models.OCIV001Schema{
+					Image:   &models.OCIV001SchemaImage{Digest: swag.String(testDigest)},
+					Payload: &payload,
+					Signature: &models.OCIV001SchemaSignature{
+						Content:   sigBytes,
+						PublicKey: &models.OCIV001SchemaSignaturePublicKey{Content: keyBytes},
+					},
+				},
+			},
+			expectUnmarshalSuccess:    true,
+			expectCanonicalizeSuccess: true,
+		},
+		{
+			caseDesc: "payload for a different image",
+			entry: V001Entry{
+				OCIObj: models.OCIV001Schema{
+					Image:   &models.OCIV001SchemaImage{Digest: swag.String(testDigest)},
+					Payload: &otherPayload,
+					Signature: &models.OCIV001SchemaSignature{
+						Content:   otherSigBytes,
+						PublicKey: &models.OCIV001SchemaSignaturePublicKey{Content: keyBytes},
+					},
+				},
+			},
+			expectUnmarshalSuccess:    false,
+			expectCanonicalizeSuccess: false,
+		},
+		{
+			caseDesc: "signature does not match payload",
+			entry: V001Entry{
+				OCIObj: models.OCIV001Schema{
+					Image:   &models.OCIV001SchemaImage{Digest: swag.String(testDigest)},
+					Payload: &payload,
+					Signature: &models.OCIV001SchemaSignature{
+						Content:   otherSigBytes,
+						PublicKey: &models.OCIV001SchemaSignaturePublicKey{Content: keyBytes},
+					},
+				},
+			},
+			expectUnmarshalSuccess:    false,
+			expectCanonicalizeSuccess: false,
+		},
+		{
+			caseDesc: "payload is not a cosign signature payload",
+			entry: V001Entry{
+				OCIObj: models.OCIV001Schema{
+					Image:   &models.OCIV001SchemaImage{Digest: swag.String(testDigest)},
+					Payload: &notCosign,
+					Signature: &models.OCIV001SchemaSignature{
+						Content:   notCosignSig,
+						PublicKey: &models.OCIV001SchemaSignaturePublicKey{Content: keyBytes},
+					},
+				},
+			},
+			expectUnmarshalSuccess:    false,
+			expectCanonicalizeSuccess: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		if _, _, err := tc.entry.validate(); (err == nil) != tc.expectUnmarshalSuccess {
+			t.Errorf("unexpected result in '%v': %v", tc.caseDesc, err)
+		}
+
+		v := &V001Entry{}
+		r := models.OCI{
+			APIVersion: swag.String(tc.entry.APIVersion()),
+			Spec:       tc.entry.OCIObj,
+		}
+
+		if err := v.Unmarshal(&r); (err == nil) != tc.expectUnmarshalSuccess {
+			t.Errorf("unexpected result in '%v': %v", tc.caseDesc, err)
+		}
+
+		b, err := v.Canonicalize(context.TODO())
+		if (err == nil) != tc.expectCanonicalizeSuccess {
+			t.Errorf("unexpected result from Canonicalize for '%v': %v", tc.caseDesc, err)
+		} else if err != nil {
+			if _, ok := err.(types.ValidationError); !ok {
+				t.Errorf("canonicalize returned an unexpected error that isn't of type types.ValidationError: %v", err)
+			}
+		}
+		if b != nil {
+			pe, err := models.UnmarshalProposedEntry(bytes.NewReader(b), runtime.JSONConsumer())
+			if err != nil {
+				t.Errorf("unexpected err from Unmarshalling canonicalized entry for '%v': %v", tc.caseDesc, err)
+			}
+			if _, err := types.NewEntry(pe); err != nil {
+				t.Errorf("unexpected err from type-specific unmarshalling for '%v': %v", tc.caseDesc, err)
+			}
+		}
+	}
+}
+
+func TestV001Entry_IndexKeys(t *testing.T) {
+	signer, keyBytes := testKey(t)
+	payload := testPayload(t, testDigest)
+	sigBytes, _ := signer.SignMessage(bytes.NewReader(payload))
+
+	v := V001Entry{
+		OCIObj: models.OCIV001Schema{
+			Image:   &models.OCIV001SchemaImage{Digest: swag.String(testDigest)},
+			Payload: &payload,
+			Signature: &models.OCIV001SchemaSignature{
+				Content:   sigBytes,
+				PublicKey: &models.OCIV001SchemaSignaturePublicKey{Content: keyBytes},
+			},
+		},
+	}
+	k, err := v.IndexKeys()
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := map[string]struct{}{}
+	for _, key := range k {
+		keys[key] = struct{}{}
+	}
+
+	keyHash := sha256.Sum256(keyBytes)
+	payloadHash := sha256.Sum256(payload)
+	for _, want := range []string{
+		testDigest,
+		hex.EncodeToString(keyHash[:]),
+		"sha256:" + hex.EncodeToString(payloadHash[:]),
+	} {
+		if _, ok := keys[want]; !ok {
+			t.Errorf("missing index entry %s, got %v", want, keys)
+		}
+	}
+}
+
+func TestCreateFromArtifactProperties(t *testing.T) {
+	signer, keyBytes := testKey(t)
+
+	// an image reference pinned by digest does not require contacting a registry
+	ref, err := oci.ParseReference("ghcr.io/org/app@" + testDigest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload, err := oci.NewPayload(ref, testDigest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sigBytes, _ := signer.SignMessage(bytes.NewReader(payload))
+
+	props := types.ArtifactProperties{
+		ImageReference: "ghcr.io/org/app@" + testDigest,
+		SignatureBytes: sigBytes,
+		PublicKeyBytes: keyBytes,
+	}
+	pe, err := V001Entry{}.CreateFromArtifactProperties(context.Background(), props)
+	if err != nil {
+		t.Fatalf("unexpected error creating entry from image reference: %v", err)
+	}
+	spec := pe.(*models.OCI).Spec.(models.OCIV001Schema)
+	if swag.StringValue(spec.Image.Digest) != testDigest {
+		t.Errorf("unexpected digest %s", swag.StringValue(spec.Image.Digest))
+	}
+	if !bytes.Equal(*spec.Payload, payload) {
+		t.Errorf("expected generated payload %s, got %s", payload, *spec.Payload)
+	}
+
+	// the digest is taken from the payload when no image reference is given
+	props = types.ArtifactProperties{
+		ArtifactBytes:  payload,
+		SignatureBytes: sigBytes,
+		PublicKeyBytes: keyBytes,
+	}
+	if _, err := (V001Entry{}).CreateFromArtifactProperties(context.Background(), props); err != nil {
+		t.Errorf("unexpected error creating entry from payload: %v", err)
+	}
+
+	// the payload must describe the image that was named
+	props.ImageReference = "ghcr.io/org/app@" + otherDigest
+	if _, err := (V001Entry{}).CreateFromArtifactProperties(context.Background(), props); err == nil {
+		t.Error("expected error when payload does not match image reference")
+	}
+
+	if _, err := (V001Entry{}).CreateFromArtifactProperties(context.Background(), types.ArtifactProperties{SignatureBytes: sigBytes, PublicKeyBytes: keyBytes}); err == nil {
+		t.Error("expected error without image reference or payload")
+	}
+}
@@ -60,6 +60,10 @@ func (rt BaseRekordType) UnmarshalEntry(pe models.ProposedEntry) (types.EntryImp
 func (rt *BaseRekordType) CreateProposedEntry(ctx context.Context, version string, props types.ArtifactProperties) (models.ProposedEntry, error) {
 	if version == "" {
 		version = rt.DefaultVersion()
+		// only v0.0.2 can carry more than one signature
+		if len(props.SignaturePaths) > 1 || len(props.PublicKeyPaths) > 1 {
+			version = "0.0.2"
+		}
 	}
 	ei, err := rt.VersionedUnmarshal(nil, version)
 	if err != nil {
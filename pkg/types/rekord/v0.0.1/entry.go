@@ -83,16 +83,30 @@ func (v V001Entry) IndexKeys() ([]string, error) {
 		result = append(result, strings.ToLower(hex.EncodeToString(keyHash[:])))
 	}
 
-	result = append(result, keyObj.EmailAddresses()...)
+	result = append(result, keyObj.Subjects()...)
 
 	if v.RekordObj.Data.Hash != nil {
 		hashKey := strings.ToLower(fmt.Sprintf("%s:%s", *v.RekordObj.Data.Hash.Algorithm, *v.RekordObj.Data.Hash.Value))
 		result = append(result, hashKey)
 	}
+	result = append(result, types.ArtifactURLIndexKeys(v.RekordObj.Data.URL)...)
 
 	return result, nil
 }
 
+// Signers implements types.SignerProvider
+func (v V001Entry) Signers() ([]pki.PublicKey, error) {
+	af, err := pki.NewArtifactFactory(pki.Format(v.RekordObj.Signature.Format))
+	if err != nil {
+		return nil, err
+	}
+	keyObj, err := af.NewPublicKey(bytes.NewReader(v.RekordObj.Signature.PublicKey.Content))
+	if err != nil {
+		return nil, err
+	}
+	return []pki.PublicKey{keyObj}, nil
+}
+
 func (v *V001Entry) Unmarshal(pe models.ProposedEntry) error {
 	rekord, ok := pe.(*models.Rekord)
 	if !ok {
@@ -363,6 +377,10 @@ func (v V001Entry) CreateFromArtifactProperties(ctx context.Context, props types
 	returnVal := models.Rekord{}
 	re := V001Entry{}
 
+	if len(props.SignaturePaths) > 1 || len(props.PublicKeyPaths) > 1 {
+		return nil, errors.New("rekord v0.0.1 entries carry a single signature; use v0.0.2 for multiple signatures")
+	}
+
 	// we will need artifact, public-key, signature
 	re.RekordObj.Data = &models.RekordV001SchemaData{}
 
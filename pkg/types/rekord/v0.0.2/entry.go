@@ -0,0 +1,508 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rekord
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/asaskevich/govalidator"
+	"github.com/go-openapi/strfmt"
+	"github.com/go-openapi/swag"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/sigstore/rekor/pkg/generated/models"
+	"github.com/sigstore/rekor/pkg/log"
+	"github.com/sigstore/rekor/pkg/pki"
+	"github.com/sigstore/rekor/pkg/types"
+	"github.com/sigstore/rekor/pkg/types/rekord"
+	"github.com/sigstore/rekor/pkg/util"
+)
+
+const (
+	APIVERSION = "0.0.2"
+)
+
+func init() {
+	if err := rekord.VersionMap.SetEntryFactory(APIVERSION, NewEntry); err != nil {
+		log.Logger.Panic(err)
+	}
+}
+
+type V002Entry struct {
+	RekordObj models.RekordV002Schema
+}
+
+func (v V002Entry) APIVersion() string {
+	return APIVERSION
+}
+
+func NewEntry() types.EntryImpl {
+	return &V002Entry{}
+}
+
+func (v V002Entry) IndexKeys() ([]string, error) {
+	var result []string
+
+	keyObjs, err := v.Signers()
+	if err != nil {
+		return nil, err
+	}
+	for _, keyObj := range keyObjs {
+		key, err := keyObj.CanonicalValue()
+		if err != nil {
+			log.Logger.Error(err)
+		} else {
+			keyHash := sha256.Sum256(key)
+			result = append(result, strings.ToLower(hex.EncodeToString(keyHash[:])))
+		}
+
+		result = append(result, keyObj.Subjects()...)
+	}
+
+	if v.RekordObj.Data.Hash != nil {
+		hashKey := strings.ToLower(fmt.Sprintf("%s:%s", *v.RekordObj.Data.Hash.Algorithm, *v.RekordObj.Data.Hash.Value))
+		result = append(result, hashKey)
+	}
+	result = append(result, types.ArtifactURLIndexKeys(v.RekordObj.Data.URL)...)
+
+	return result, nil
+}
+
+// Signers implements types.SignerProvider
+func (v V002Entry) Signers() ([]pki.PublicKey, error) {
+	keyObjs := make([]pki.PublicKey, 0, len(v.RekordObj.Signatures))
+	for _, sig := range v.RekordObj.Signatures {
+		af, err := pki.NewArtifactFactory(pki.Format(sig.Format))
+		if err != nil {
+			return nil, err
+		}
+		keyObj, err := af.NewPublicKey(bytes.NewReader(sig.PublicKey.Content))
+		if err != nil {
+			return nil, err
+		}
+		keyObjs = append(keyObjs, keyObj)
+	}
+	return keyObjs, nil
+}
+
+func (v *V002Entry) Unmarshal(pe models.ProposedEntry) error {
+	rekord, ok := pe.(*models.Rekord)
+	if !ok {
+		return errors.New("cannot unmarshal non Rekord v0.0.2 type")
+	}
+
+	if err := types.DecodeEntry(rekord.Spec, &v.RekordObj); err != nil {
+		return err
+	}
+
+	// field validation
+	if err := v.RekordObj.Validate(strfmt.Default); err != nil {
+		return err
+	}
+
+	// cross field validation
+	return v.validate()
+
+}
+
+func (v *V002Entry) hasExternalEntities() bool {
+	if v.RekordObj.Data != nil && v.RekordObj.Data.URL.String() != "" {
+		return true
+	}
+	for _, sig := range v.RekordObj.Signatures {
+		if sig.URL.String() != "" || sig.PublicKey.URL.String() != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// verifiedSignature is a signature from the entry along with the key that verified it
+type verifiedSignature struct {
+	format string
+	keyObj pki.PublicKey
+	sigObj pki.Signature
+}
+
+// fetchExternalEntities reads the data once, streaming it to the hasher and to a verifier for each
+// signature; every signature must verify over the data with its public key
+func (v *V002Entry) fetchExternalEntities(ctx context.Context) ([]verifiedSignature, error) {
+	g, ctx := errgroup.WithContext(ctx)
+
+	hashR, hashW := io.Pipe()
+	defer hashR.Close()
+
+	closers := []io.Closer{}
+	errClosers := []interface {
+		CloseWithError(error) error
+	}{hashR, hashW}
+	writers := []io.Writer{hashW}
+	sigRs := make([]*io.PipeReader, len(v.RekordObj.Signatures))
+	for i := range v.RekordObj.Signatures {
+		sigR, sigW := io.Pipe()
+		defer sigR.Close()
+		sigRs[i] = sigR
+		closers = append(closers, sigW)
+		errClosers = append(errClosers, sigR, sigW)
+		writers = append(writers, sigW)
+	}
+	closePipesOnError := func(err error) error {
+		for _, p := range errClosers {
+			if err := p.CloseWithError(err); err != nil {
+				log.Logger.Error(fmt.Errorf("error closing pipe: %w", err))
+			}
+		}
+		return err
+	}
+
+	oldSHA := ""
+	if v.RekordObj.Data.Hash != nil && v.RekordObj.Data.Hash.Value != nil {
+		oldSHA = swag.StringValue(v.RekordObj.Data.Hash.Value)
+	}
+
+	g.Go(func() error {
+		defer hashW.Close()
+		for _, c := range closers {
+			defer c.Close()
+		}
+
+		dataReadCloser, err := util.FileOrURLReadCloser(ctx, v.RekordObj.Data.URL.String(), v.RekordObj.Data.Content)
+		if err != nil {
+			return closePipesOnError(err)
+		}
+		defer dataReadCloser.Close()
+
+		/* #nosec G110 */
+		if _, err := io.Copy(io.MultiWriter(writers...), dataReadCloser); err != nil {
+			return closePipesOnError(err)
+		}
+		return nil
+	})
+
+	hashResult := make(chan string)
+
+	g.Go(func() error {
+		defer close(hashResult)
+		hasher := sha256.New()
+
+		if _, err := io.Copy(hasher, hashR); err != nil {
+			return closePipesOnError(err)
+		}
+
+		computedSHA := hex.EncodeToString(hasher.Sum(nil))
+		if oldSHA != "" && computedSHA != oldSHA {
+			return closePipesOnError(types.ValidationError(fmt.Errorf("SHA mismatch: %s != %s", computedSHA, oldSHA)))
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case hashResult <- computedSHA:
+			return nil
+		}
+	})
+
+	verified := make([]verifiedSignature, len(v.RekordObj.Signatures))
+	for i, sig := range v.RekordObj.Signatures {
+		i, sig := i, sig // https://golang.org/doc/faq#closures_and_goroutines
+		g.Go(func() error {
+			af, err := pki.NewArtifactFactory(pki.Format(sig.Format))
+			if err != nil {
+				return closePipesOnError(types.ValidationError(err))
+			}
+
+			sigReadCloser, err := util.FileOrURLReadCloser(ctx, sig.URL.String(), sig.Content)
+			if err != nil {
+				return closePipesOnError(err)
+			}
+			defer sigReadCloser.Close()
+
+			sigObj, err := af.NewSignature(sigReadCloser)
+			if err != nil {
+				return closePipesOnError(types.ValidationError(err))
+			}
+
+			keyReadCloser, err := util.FileOrURLReadCloser(ctx, sig.PublicKey.URL.String(), sig.PublicKey.Content)
+			if err != nil {
+				return closePipesOnError(err)
+			}
+			defer keyReadCloser.Close()
+
+			keyObj, err := af.NewPublicKey(keyReadCloser)
+			if err != nil {
+				return closePipesOnError(types.ValidationError(err))
+			}
+
+			if err := sigObj.Verify(sigRs[i], keyObj); err != nil {
+				return closePipesOnError(types.ValidationError(fmt.Errorf("signature %d: %w", i, err)))
+			}
+			// the data is written to all verifiers together, so the rest of it must be consumed
+			if _, err := io.Copy(ioutil.Discard, sigRs[i]); err != nil {
+				return closePipesOnError(err)
+			}
+			verified[i] = verifiedSignature{format: sig.Format, keyObj: keyObj, sigObj: sigObj}
+			return nil
+		})
+	}
+
+	computedSHA := <-hashResult
+
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	// if we get here, all goroutines succeeded without error
+	if oldSHA == "" {
+		v.RekordObj.Data.Hash = &models.RekordV002SchemaDataHash{}
+		v.RekordObj.Data.Hash.Algorithm = swag.String(models.RekordV002SchemaDataHashAlgorithmSha256)
+		v.RekordObj.Data.Hash.Value = swag.String(computedSHA)
+	}
+
+	return verified, nil
+}
+
+func (v *V002Entry) Canonicalize(ctx context.Context) ([]byte, error) {
+	verified, err := v.fetchExternalEntities(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	canonicalEntry := models.RekordV002Schema{}
+
+	// need to canonicalize signature & key content
+	seen := map[string]bool{}
+	for _, vs := range verified {
+		canonicalSig := &models.RekordV002SchemaSignaturesItems0{}
+		// signature URL (if known) is not set deliberately
+		canonicalSig.Format = vs.format
+
+		canonicalSig.Content, err = vs.sigObj.CanonicalValue()
+		if err != nil {
+			return nil, err
+		}
+
+		// key URL (if known) is not set deliberately
+		canonicalSig.PublicKey = &models.RekordV002SchemaSignaturesItems0PublicKey{}
+		canonicalSig.PublicKey.Content, err = vs.keyObj.CanonicalValue()
+		if err != nil {
+			return nil, err
+		}
+
+		if seen[string(canonicalSig.PublicKey.Content)] {
+			return nil, types.ValidationError(errors.New("each signature must be made with a different public key"))
+		}
+		seen[string(canonicalSig.PublicKey.Content)] = true
+
+		canonicalEntry.Signatures = append(canonicalEntry.Signatures, canonicalSig)
+	}
+	// the same set of signatures always results in the same entry, regardless of the order they were supplied in
+	sort.Slice(canonicalEntry.Signatures, func(i, j int) bool {
+		return bytes.Compare(canonicalEntry.Signatures[i].PublicKey.Content, canonicalEntry.Signatures[j].PublicKey.Content) < 0
+	})
+
+	canonicalEntry.Data = &models.RekordV002SchemaData{}
+	canonicalEntry.Data.Hash = v.RekordObj.Data.Hash
+	// data content is not set deliberately
+
+	// wrap in valid object with kind and apiVersion set
+	rekordObj := models.Rekord{}
+	rekordObj.APIVersion = swag.String(APIVERSION)
+	rekordObj.Spec = &canonicalEntry
+
+	v.RekordObj = canonicalEntry
+
+	bytes, err := json.Marshal(&rekordObj)
+	if err != nil {
+		return nil, err
+	}
+
+	return bytes, nil
+}
+
+// validate performs cross-field validation for fields in object
+func (v V002Entry) validate() error {
+	if len(v.RekordObj.Signatures) == 0 {
+		return errors.New("missing signature")
+	}
+	for i, sig := range v.RekordObj.Signatures {
+		if sig == nil {
+			return fmt.Errorf("missing signature %d", i)
+		}
+		if len(sig.Content) == 0 && sig.URL.String() == "" {
+			return fmt.Errorf("one of 'content' or 'url' must be specified for signature %d", i)
+		}
+
+		key := sig.PublicKey
+		if key == nil {
+			return fmt.Errorf("missing public key for signature %d", i)
+		}
+		if len(key.Content) == 0 && key.URL.String() == "" {
+			return fmt.Errorf("one of 'content' or 'url' must be specified for publicKey of signature %d", i)
+		}
+	}
+
+	data := v.RekordObj.Data
+	if data == nil {
+		return errors.New("missing data")
+	}
+
+	hash := data.Hash
+	if hash != nil {
+		if !govalidator.IsHash(swag.StringValue(hash.Value), swag.StringValue(hash.Algorithm)) {
+			return errors.New("invalid value for hash")
+		}
+	} else if len(data.Content) == 0 && data.URL.String() == "" {
+		return errors.New("one of 'content' or 'url' must be specified for data")
+	}
+
+	return nil
+}
+
+func (v V002Entry) Attestation() []byte {
+	return nil
+}
+
+// signatureSources pairs up the signatures and public keys in props by position
+func signatureSources(props types.ArtifactProperties) (sigPaths, keyPaths []*url.URL, err error) {
+	sigPaths, keyPaths = props.SignaturePaths, props.PublicKeyPaths
+	if len(sigPaths) == 0 && props.SignaturePath != nil {
+		sigPaths = []*url.URL{props.SignaturePath}
+	}
+	if len(keyPaths) == 0 && props.PublicKeyPath != nil {
+		keyPaths = []*url.URL{props.PublicKeyPath}
+	}
+	if len(sigPaths) != len(keyPaths) {
+		return nil, nil, fmt.Errorf("%d signatures were given with %d public keys; each signature must be paired with the public key that verifies it", len(sigPaths), len(keyPaths))
+	}
+	return sigPaths, keyPaths, nil
+}
+
+func (v V002Entry) CreateFromArtifactProperties(ctx context.Context, props types.ArtifactProperties) (models.ProposedEntry, error) {
+	returnVal := models.Rekord{}
+	re := V002Entry{}
+
+	// we will need artifact, public-keys, signatures
+	re.RekordObj.Data = &models.RekordV002SchemaData{}
+
+	artifactBytes := props.ArtifactBytes
+	if artifactBytes == nil {
+		if props.ArtifactPath == nil {
+			return nil, errors.New("path to artifact (file or URL) must be specified")
+		}
+		if props.ArtifactPath.IsAbs() {
+			re.RekordObj.Data.URL = strfmt.URI(props.ArtifactPath.String())
+			if props.ArtifactHash != "" {
+				re.RekordObj.Data.Hash = &models.RekordV002SchemaDataHash{
+					Algorithm: swag.String(models.RekordV002SchemaDataHashAlgorithmSha256),
+					Value:     swag.String(props.ArtifactHash),
+				}
+			}
+		} else {
+			artifactBytes, err := ioutil.ReadFile(filepath.Clean(props.ArtifactPath.Path))
+			if err != nil {
+				return nil, fmt.Errorf("error reading artifact file: %w", err)
+			}
+			re.RekordObj.Data.Content = strfmt.Base64(artifactBytes)
+		}
+	} else {
+		re.RekordObj.Data.Content = strfmt.Base64(artifactBytes)
+	}
+
+	var format string
+	switch props.PKIFormat {
+	case "pgp":
+		format = models.RekordV002SchemaSignaturesItems0FormatPgp
+	case "minisign":
+		format = models.RekordV002SchemaSignaturesItems0FormatMinisign
+	case "x509":
+		format = models.RekordV002SchemaSignaturesItems0FormatX509
+	case "ssh":
+		format = models.RekordV002SchemaSignaturesItems0FormatSSH
+	}
+
+	if props.SignatureBytes != nil || props.PublicKeyBytes != nil {
+		if props.SignatureBytes == nil || props.PublicKeyBytes == nil {
+			return nil, errors.New("a detached signature and the public key to verify it must be provided")
+		}
+		re.RekordObj.Signatures = append(re.RekordObj.Signatures, &models.RekordV002SchemaSignaturesItems0{
+			Format:  format,
+			Content: strfmt.Base64(props.SignatureBytes),
+			PublicKey: &models.RekordV002SchemaSignaturesItems0PublicKey{
+				Content: strfmt.Base64(props.PublicKeyBytes),
+			},
+		})
+	} else {
+		sigPaths, keyPaths, err := signatureSources(props)
+		if err != nil {
+			return nil, err
+		}
+		if len(sigPaths) == 0 {
+			return nil, errors.New("a detached signature must be provided")
+		}
+		for i := range sigPaths {
+			sig := &models.RekordV002SchemaSignaturesItems0{
+				Format:    format,
+				PublicKey: &models.RekordV002SchemaSignaturesItems0PublicKey{},
+			}
+			if sigPaths[i].IsAbs() {
+				sig.URL = strfmt.URI(sigPaths[i].String())
+			} else {
+				sigBytes, err := ioutil.ReadFile(filepath.Clean(sigPaths[i].Path))
+				if err != nil {
+					return nil, fmt.Errorf("error reading signature file: %w", err)
+				}
+				sig.Content = strfmt.Base64(sigBytes)
+			}
+			if keyPaths[i].IsAbs() {
+				sig.PublicKey.URL = strfmt.URI(keyPaths[i].String())
+			} else {
+				publicKeyBytes, err := ioutil.ReadFile(filepath.Clean(keyPaths[i].Path))
+				if err != nil {
+					return nil, fmt.Errorf("error reading public key file: %w", err)
+				}
+				sig.PublicKey.Content = strfmt.Base64(publicKeyBytes)
+			}
+			re.RekordObj.Signatures = append(re.RekordObj.Signatures, sig)
+		}
+	}
+
+	if err := re.validate(); err != nil {
+		return nil, err
+	}
+
+	if re.hasExternalEntities() {
+		if _, err := re.fetchExternalEntities(ctx); err != nil {
+			return nil, fmt.Errorf("error retrieving external entities: %v", err)
+		}
+	}
+
+	returnVal.APIVersion = swag.String(re.APIVersion())
+	returnVal.Spec = re.RekordObj
+
+	return &returnVal, nil
+}
@@ -0,0 +1,391 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rekord
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/go-openapi/runtime"
+	"github.com/go-openapi/strfmt"
+	"github.com/go-openapi/swag"
+	"go.uber.org/goleak"
+
+	"github.com/sigstore/rekor/pkg/generated/models"
+	"github.com/sigstore/rekor/pkg/types"
+)
+
+func TestMain(m *testing.M) {
+	goleak.VerifyTestMain(m)
+}
+
+func TestNewEntryReturnType(t *testing.T) {
+	entry := NewEntry()
+	if reflect.TypeOf(entry) != reflect.ValueOf(&V002Entry{}).Type() {
+		t.Errorf("invalid type returned from NewEntry: %T", entry)
+	}
+}
+
+// x509Signature returns a PEM encoded public key and a signature over data made with its private key
+func x509Signature(t *testing.T, data []byte) ([]byte, []byte) {
+	t.Helper()
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := sha256.Sum256(data)
+	sig, err := ecdsa.SignASN1(rand.Reader, priv, h[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(priv.Public())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), sig
+}
+
+func TestCrossFieldValidation(t *testing.T) {
+	type TestCase struct {
+		caseDesc                  string
+		entry                     V002Entry
+		hasExtEntities            bool
+		expectUnmarshalSuccess    bool
+		expectCanonicalizeSuccess bool
+	}
+
+	sigBytes, _ := ioutil.ReadFile("../../../../tests/test_file.sig")
+	keyBytes, _ := ioutil.ReadFile("../../../../tests/test_public_key.key")
+	dataBytes, _ := ioutil.ReadFile("../../../../tests/test_file.txt")
+
+	h := sha256.Sum256(dataBytes)
+	dataSHA := hex.EncodeToString(h[:])
+
+	x509Key, x509Sig := x509Signature(t, dataBytes)
+	otherKey, otherSig := x509Signature(t, []byte("some other artifact"))
+
+	testServer := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			file := &sigBytes
+			var err error
+
+			switch r.URL.Path {
+			case "/signature":
+				file = &sigBytes
+			case "/key":
+				file = &keyBytes
+			case "/data":
+				file = &dataBytes
+			default:
+				err = errors.New("unknown URL")
+			}
+			if err != nil {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write(*file)
+		}))
+	defer testServer.Close()
+
+	pgpSignature := func() *models.RekordV002SchemaSignaturesItems0 {
+		return &models.RekordV002SchemaSignaturesItems0{
+			Format:  "pgp",
+			Content: strfmt.Base64(sigBytes),
+			PublicKey: &models.RekordV002SchemaSignaturesItems0PublicKey{
+				Content: strfmt.Base64(keyBytes),
+			},
+		}
+	}
+	x509Signature := &models.RekordV002SchemaSignaturesItems0{
+		Format:  "x509",
+		Content: strfmt.Base64(x509Sig),
+		PublicKey: &models.RekordV002SchemaSignaturesItems0PublicKey{
+			Content: strfmt.Base64(x509Key),
+		},
+	}
+	contentData := &models.RekordV002SchemaData{
+		Content: strfmt.Base64(dataBytes),
+	}
+
+	testCases := []TestCase{
+		{
+			caseDesc:               "empty obj",
+			entry:                  V002Entry{},
+			expectUnmarshalSuccess: false,
+		},
+		{
+			caseDesc: "no signatures",
+			entry: V002Entry{
+				RekordObj: models.RekordV002Schema{
+					Signatures: []*models.RekordV002SchemaSignaturesItems0{},
+					Data:       contentData,
+				},
+			},
+			expectUnmarshalSuccess: false,
+		},
+		{
+			caseDesc: "signature without public key",
+			entry: V002Entry{
+				RekordObj: models.RekordV002Schema{
+					Signatures: []*models.RekordV002SchemaSignaturesItems0{
+						pgpSignature(),
+						{
+							Format:  "x509",
+							Content: strfmt.Base64(x509Sig),
+						},
+					},
+					Data: contentData,
+				},
+			},
+			expectUnmarshalSuccess: false,
+		},
+		{
+			caseDesc: "signatures without data",
+			entry: V002Entry{
+				RekordObj: models.RekordV002Schema{
+					Signatures: []*models.RekordV002SchemaSignaturesItems0{pgpSignature(), x509Signature},
+				},
+			},
+			expectUnmarshalSuccess: false,
+		},
+		{
+			caseDesc: "single signature",
+			entry: V002Entry{
+				RekordObj: models.RekordV002Schema{
+					Signatures: []*models.RekordV002SchemaSignaturesItems0{pgpSignature()},
+					Data:       contentData,
+				},
+			},
+			expectUnmarshalSuccess:    true,
+			expectCanonicalizeSuccess: true,
+		},
+		{
+			caseDesc: "signatures in different formats",
+			entry: V002Entry{
+				RekordObj: models.RekordV002Schema{
+					Signatures: []*models.RekordV002SchemaSignaturesItems0{pgpSignature(), x509Signature},
+					Data:       contentData,
+				},
+			},
+			expectUnmarshalSuccess:    true,
+			expectCanonicalizeSuccess: true,
+		},
+		{
+			caseDesc: "signatures and data fetched from URLs",
+			entry: V002Entry{
+				RekordObj: models.RekordV002Schema{
+					Signatures: []*models.RekordV002SchemaSignaturesItems0{
+						{
+							Format: "pgp",
+							URL:    strfmt.URI(testServer.URL + "/signature"),
+							PublicKey: &models.RekordV002SchemaSignaturesItems0PublicKey{
+								URL: strfmt.URI(testServer.URL + "/key"),
+							},
+						},
+						x509Signature,
+					},
+					Data: &models.RekordV002SchemaData{
+						Hash: &models.RekordV002SchemaDataHash{
+							Algorithm: swag.String(models.RekordV002SchemaDataHashAlgorithmSha256),
+							Value:     swag.String(dataSHA),
+						},
+						URL: strfmt.URI(testServer.URL + "/data"),
+					},
+				},
+			},
+			hasExtEntities:            true,
+			expectUnmarshalSuccess:    true,
+			expectCanonicalizeSuccess: true,
+		},
+		{
+			caseDesc: "one signature does not verify",
+			entry: V002Entry{
+				RekordObj: models.RekordV002Schema{
+					Signatures: []*models.RekordV002SchemaSignaturesItems0{
+						pgpSignature(),
+						{
+							Format:  "x509",
+							Content: strfmt.Base64(otherSig),
+							PublicKey: &models.RekordV002SchemaSignaturesItems0PublicKey{
+								Content: strfmt.Base64(otherKey),
+							},
+						},
+					},
+					Data: contentData,
+				},
+			},
+			expectUnmarshalSuccess:    true,
+			expectCanonicalizeSuccess: false,
+		},
+		{
+			caseDesc: "same key used twice",
+			entry: V002Entry{
+				RekordObj: models.RekordV002Schema{
+					Signatures: []*models.RekordV002SchemaSignaturesItems0{pgpSignature(), pgpSignature()},
+					Data:       contentData,
+				},
+			},
+			expectUnmarshalSuccess:    true,
+			expectCanonicalizeSuccess: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		v := &V002Entry{}
+		r := models.Rekord{
+			APIVersion: swag.String(tc.entry.APIVersion()),
+			Spec:       tc.entry.RekordObj,
+		}
+
+		if err := v.Unmarshal(&r); (err == nil) != tc.expectUnmarshalSuccess {
+			t.Fatalf("unexpected result in '%v': %v", tc.caseDesc, err)
+		}
+		// No need to continue here if we didn't unmarshal
+		if !tc.expectUnmarshalSuccess {
+			continue
+		}
+
+		if tc.entry.hasExternalEntities() != tc.hasExtEntities {
+			t.Errorf("unexpected result from HasExternalEntities for '%v'", tc.caseDesc)
+		}
+
+		b, err := v.Canonicalize(context.TODO())
+		if (err == nil) != tc.expectCanonicalizeSuccess {
+			t.Errorf("unexpected result from Canonicalize for '%v': %v", tc.caseDesc, err)
+		} else if err != nil {
+			if _, ok := err.(types.ValidationError); !ok {
+				t.Errorf("canonicalize returned an unexpected error that isn't of type types.ValidationError: %v", err)
+			}
+		}
+		if b != nil {
+			pe, err := models.UnmarshalProposedEntry(bytes.NewReader(b), runtime.JSONConsumer())
+			if err != nil {
+				t.Errorf("unexpected err from Unmarshalling canonicalized entry for '%v': %v", tc.caseDesc, err)
+			}
+			entry, err := types.NewEntry(pe)
+			if err != nil {
+				t.Errorf("unexpected err from type-specific unmarshalling for '%v': %v", tc.caseDesc, err)
+			}
+			if keys, err := entry.IndexKeys(); err != nil || len(keys) == 0 {
+				t.Errorf("unexpected index keys for '%v': %v, %v", tc.caseDesc, keys, err)
+			}
+		}
+	}
+}
+
+func TestCanonicalizeSignatureOrder(t *testing.T) {
+	sigBytes, _ := ioutil.ReadFile("../../../../tests/test_file.sig")
+	keyBytes, _ := ioutil.ReadFile("../../../../tests/test_public_key.key")
+	dataBytes, _ := ioutil.ReadFile("../../../../tests/test_file.txt")
+	x509Key, x509Sig := x509Signature(t, dataBytes)
+
+	pgpSig := &models.RekordV002SchemaSignaturesItems0{
+		Format:    "pgp",
+		Content:   strfmt.Base64(sigBytes),
+		PublicKey: &models.RekordV002SchemaSignaturesItems0PublicKey{Content: strfmt.Base64(keyBytes)},
+	}
+	x509Sig2 := &models.RekordV002SchemaSignaturesItems0{
+		Format:    "x509",
+		Content:   strfmt.Base64(x509Sig),
+		PublicKey: &models.RekordV002SchemaSignaturesItems0PublicKey{Content: strfmt.Base64(x509Key)},
+	}
+
+	canonicalize := func(sigs ...*models.RekordV002SchemaSignaturesItems0) []byte {
+		v := &V002Entry{
+			RekordObj: models.RekordV002Schema{
+				Signatures: sigs,
+				Data:       &models.RekordV002SchemaData{Content: strfmt.Base64(dataBytes)},
+			},
+		}
+		b, err := v.Canonicalize(context.TODO())
+		if err != nil {
+			t.Fatal(err)
+		}
+		return b
+	}
+
+	if !bytes.Equal(canonicalize(pgpSig, x509Sig2), canonicalize(x509Sig2, pgpSig)) {
+		t.Error("canonical entry depends on the order of signatures")
+	}
+}
+
+func TestCreateFromArtifactProperties(t *testing.T) {
+	dataBytes, _ := ioutil.ReadFile("../../../../tests/test_file.txt")
+	dir := t.TempDir()
+	key1, sig1 := x509Signature(t, dataBytes)
+	key2, sig2 := x509Signature(t, dataBytes)
+	paths := map[string][]byte{"key1": key1, "sig1": sig1, "key2": key2, "sig2": sig2}
+	for name, b := range paths {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), b, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	path := func(name string) *url.URL {
+		return &url.URL{Path: filepath.Join(dir, name)}
+	}
+
+	props := types.ArtifactProperties{
+		ArtifactBytes:  dataBytes,
+		PKIFormat:      "x509",
+		SignaturePaths: []*url.URL{path("sig1"), path("sig2")},
+		PublicKeyPaths: []*url.URL{path("key1"), path("key2")},
+	}
+	pe, err := V002Entry{}.CreateFromArtifactProperties(context.TODO(), props)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry, err := types.NewEntry(pe)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := entry.Canonicalize(context.TODO()); err != nil {
+		t.Fatalf("unexpected error canonicalizing entry: %v", err)
+	}
+
+	// signatures are paired with the key in the same position
+	props.PublicKeyPaths = []*url.URL{path("key2"), path("key1")}
+	pe, err = V002Entry{}.CreateFromArtifactProperties(context.TODO(), props)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry, err = types.NewEntry(pe); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := entry.Canonicalize(context.TODO()); err == nil {
+		t.Error("expected error when signatures are paired with the wrong keys")
+	}
+
+	props.PublicKeyPaths = []*url.URL{path("key1")}
+	if _, err := (V002Entry{}).CreateFromArtifactProperties(context.TODO(), props); err == nil {
+		t.Error("expected error when the number of signatures and public keys differ")
+	}
+}
@@ -0,0 +1,239 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sbom
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/asaskevich/govalidator"
+	"github.com/pkg/errors"
+)
+
+const (
+	FormatSPDX      = "spdx"
+	FormatCycloneDX = "cyclonedx"
+)
+
+// Document holds the parts of an SBOM that are recorded in and indexed by the log
+type Document struct {
+	Format      string
+	SpecVersion string
+	// Digests of the packages, files and components described by the document, as "algorithm:value"
+	Digests []string
+}
+
+// digestAlgorithms maps the names used for hash algorithms by SPDX and CycloneDX
+// to the names used in index keys; digests using other algorithms are not indexed
+var digestAlgorithms = map[string]string{
+	"SHA1":    "sha1",
+	"SHA-1":   "sha1",
+	"SHA256":  "sha256",
+	"SHA-256": "sha256",
+	"SHA384":  "sha384",
+	"SHA-384": "sha384",
+	"SHA512":  "sha512",
+	"SHA-512": "sha512",
+}
+
+var (
+	spdxVersionRegex = regexp.MustCompile(`^SPDX-[0-9]+\.[0-9]+$`)
+	cdxVersionRegex  = regexp.MustCompile(`^[0-9]+\.[0-9]+$`)
+)
+
+// Parse detects whether raw is a JSON encoded SPDX or CycloneDX document and validates
+// the structure needed to record it in the log
+func Parse(raw []byte) (*Document, error) {
+	var probe struct {
+		SPDXVersion string `json:"spdxVersion"`
+		BOMFormat   string `json:"bomFormat"`
+	}
+	if err := json.Unmarshal(raw, &probe); err != nil {
+		return nil, errors.Wrap(err, "SBOM must be a JSON document")
+	}
+
+	switch {
+	case probe.SPDXVersion != "":
+		return parseSPDX(raw)
+	case probe.BOMFormat != "":
+		return parseCycloneDX(raw)
+	}
+	return nil, errors.New("document is neither an SPDX nor a CycloneDX SBOM")
+}
+
+type digestSet map[string]struct{}
+
+// add records the digest if the algorithm is one that is indexed
+func (d digestSet) add(alg, value string) error {
+	name, ok := digestAlgorithms[strings.ToUpper(alg)]
+	if !ok {
+		return nil
+	}
+	if !govalidator.IsHash(value, name) {
+		return fmt.Errorf("invalid %s digest %q", alg, value)
+	}
+	d[name+":"+strings.ToLower(value)] = struct{}{}
+	return nil
+}
+
+func (d digestSet) sorted() []string {
+	result := make([]string, 0, len(d))
+	for digest := range d {
+		result = append(result, digest)
+	}
+	sort.Strings(result)
+	return result
+}
+
+type spdxChecksum struct {
+	Algorithm     string `json:"algorithm"`
+	ChecksumValue string `json:"checksumValue"`
+}
+
+type spdxDocument struct {
+	SPDXVersion       string `json:"spdxVersion"`
+	SPDXID            string `json:"SPDXID"`
+	Name              string `json:"name"`
+	DocumentNamespace string `json:"documentNamespace"`
+	Packages          []struct {
+		SPDXID    string         `json:"SPDXID"`
+		Name      string         `json:"name"`
+		Checksums []spdxChecksum `json:"checksums"`
+	} `json:"packages"`
+	Files []struct {
+		SPDXID    string         `json:"SPDXID"`
+		FileName  string         `json:"fileName"`
+		Checksums []spdxChecksum `json:"checksums"`
+	} `json:"files"`
+}
+
+func parseSPDX(raw []byte) (*Document, error) {
+	doc := spdxDocument{}
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return nil, errors.Wrap(err, "parsing SPDX document")
+	}
+
+	if !spdxVersionRegex.MatchString(doc.SPDXVersion) {
+		return nil, fmt.Errorf("invalid spdxVersion %q", doc.SPDXVersion)
+	}
+	if doc.SPDXID != "SPDXRef-DOCUMENT" {
+		return nil, fmt.Errorf("invalid SPDXID %q for document", doc.SPDXID)
+	}
+	if doc.Name == "" {
+		return nil, errors.New("SPDX document is missing a name")
+	}
+	if doc.DocumentNamespace == "" {
+		return nil, errors.New("SPDX document is missing a documentNamespace")
+	}
+
+	digests := digestSet{}
+	for i, pkg := range doc.Packages {
+		if !strings.HasPrefix(pkg.SPDXID, "SPDXRef-") || pkg.Name == "" {
+			return nil, fmt.Errorf("package %d must have an SPDXID and a name", i)
+		}
+		for _, c := range pkg.Checksums {
+			if err := digests.add(c.Algorithm, c.ChecksumValue); err != nil {
+				return nil, errors.Wrapf(err, "package %s", pkg.SPDXID)
+			}
+		}
+	}
+	for i, file := range doc.Files {
+		if !strings.HasPrefix(file.SPDXID, "SPDXRef-") || file.FileName == "" {
+			return nil, fmt.Errorf("file %d must have an SPDXID and a fileName", i)
+		}
+		for _, c := range file.Checksums {
+			if err := digests.add(c.Algorithm, c.ChecksumValue); err != nil {
+				return nil, errors.Wrapf(err, "file %s", file.SPDXID)
+			}
+		}
+	}
+
+	return &Document{
+		Format:      FormatSPDX,
+		SpecVersion: strings.TrimPrefix(doc.SPDXVersion, "SPDX-"),
+		Digests:     digests.sorted(),
+	}, nil
+}
+
+type cdxComponent struct {
+	Type   string `json:"type"`
+	Name   string `json:"name"`
+	Hashes []struct {
+		Alg     string `json:"alg"`
+		Content string `json:"content"`
+	} `json:"hashes"`
+	Components []cdxComponent `json:"components"`
+}
+
+type cdxDocument struct {
+	BOMFormat   string `json:"bomFormat"`
+	SpecVersion string `json:"specVersion"`
+	Metadata    *struct {
+		Component *cdxComponent `json:"component"`
+	} `json:"metadata"`
+	Components []cdxComponent `json:"components"`
+}
+
+// addComponents walks the (possibly nested) components, validating each and collecting their digests
+func (d digestSet) addComponents(components []cdxComponent) error {
+	for i, c := range components {
+		if c.Type == "" || c.Name == "" {
+			return fmt.Errorf("component %d must have a type and a name", i)
+		}
+		for _, h := range c.Hashes {
+			if err := d.add(h.Alg, h.Content); err != nil {
+				return errors.Wrapf(err, "component %s", c.Name)
+			}
+		}
+		if err := d.addComponents(c.Components); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func parseCycloneDX(raw []byte) (*Document, error) {
+	doc := cdxDocument{}
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return nil, errors.Wrap(err, "parsing CycloneDX document")
+	}
+
+	if doc.BOMFormat != "CycloneDX" {
+		return nil, fmt.Errorf("invalid bomFormat %q", doc.BOMFormat)
+	}
+	if !cdxVersionRegex.MatchString(doc.SpecVersion) {
+		return nil, fmt.Errorf("invalid specVersion %q", doc.SpecVersion)
+	}
+
+	digests := digestSet{}
+	if doc.Metadata != nil && doc.Metadata.Component != nil {
+		if err := digests.addComponents([]cdxComponent{*doc.Metadata.Component}); err != nil {
+			return nil, errors.Wrap(err, "metadata")
+		}
+	}
+	if err := digests.addComponents(doc.Components); err != nil {
+		return nil, err
+	}
+
+	return &Document{
+		Format:      FormatCycloneDX,
+		SpecVersion: doc.SpecVersion,
+		Digests:     digests.sorted(),
+	}, nil
+}
@@ -0,0 +1,146 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sbom
+
+import (
+	"reflect"
+	"testing"
+)
+
+const (
+	sha1Digest   = "da39a3ee5e6b4b0d3255bfef95601890afd80709"
+	sha256Digest = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name        string
+		doc         string
+		wantFormat  string
+		wantVersion string
+		wantDigests []string
+		wantErr     bool
+	}{
+		{
+			name: "spdx",
+			doc: `{
+				"spdxVersion": "SPDX-2.2",
+				"SPDXID": "SPDXRef-DOCUMENT",
+				"name": "example",
+				"documentNamespace": "https://example.com/spdx/example",
+				"packages": [{
+					"SPDXID": "SPDXRef-Package-foo",
+					"name": "foo",
+					"checksums": [
+						{"algorithm": "SHA256", "checksumValue": "` + sha256Digest + `"},
+						{"algorithm": "MD5", "checksumValue": "d41d8cd98f00b204e9800998ecf8427e"}
+					]
+				}],
+				"files": [{
+					"SPDXID": "SPDXRef-File-bar",
+					"fileName": "./bar",
+					"checksums": [{"algorithm": "SHA1", "checksumValue": "` + sha1Digest + `"}]
+				}]
+			}`,
+			wantFormat:  FormatSPDX,
+			wantVersion: "2.2",
+			wantDigests: []string{"sha1:" + sha1Digest, "sha256:" + sha256Digest},
+		},
+		{
+			name:    "spdx without namespace",
+			doc:     `{"spdxVersion": "SPDX-2.2", "SPDXID": "SPDXRef-DOCUMENT", "name": "example"}`,
+			wantErr: true,
+		},
+		{
+			name:    "spdx with invalid version",
+			doc:     `{"spdxVersion": "2.2", "SPDXID": "SPDXRef-DOCUMENT", "name": "example", "documentNamespace": "https://example.com"}`,
+			wantErr: true,
+		},
+		{
+			name: "spdx with invalid checksum",
+			doc: `{
+				"spdxVersion": "SPDX-2.3",
+				"SPDXID": "SPDXRef-DOCUMENT",
+				"name": "example",
+				"documentNamespace": "https://example.com/spdx/example",
+				"packages": [{"SPDXID": "SPDXRef-foo", "name": "foo", "checksums": [{"algorithm": "SHA256", "checksumValue": "abcd"}]}]
+			}`,
+			wantErr: true,
+		},
+		{
+			name: "cyclonedx with nested components",
+			doc: `{
+				"bomFormat": "CycloneDX",
+				"specVersion": "1.4",
+				"metadata": {"component": {"type": "application", "name": "app"}},
+				"components": [{
+					"type": "library",
+					"name": "foo",
+					"hashes": [{"alg": "SHA-256", "content": "` + sha256Digest + `"}],
+					"components": [{
+						"type": "library",
+						"name": "bar",
+						"hashes": [{"alg": "SHA-1", "content": "` + sha1Digest + `"}, {"alg": "BLAKE3", "content": "ignored"}]
+					}]
+				}, {
+					"type": "library",
+					"name": "foo-copy",
+					"hashes": [{"alg": "SHA-256", "content": "` + sha256Digest + `"}]
+				}]
+			}`,
+			wantFormat:  FormatCycloneDX,
+			wantVersion: "1.4",
+			wantDigests: []string{"sha1:" + sha1Digest, "sha256:" + sha256Digest},
+		},
+		{
+			name:    "cyclonedx component without name",
+			doc:     `{"bomFormat": "CycloneDX", "specVersion": "1.4", "components": [{"type": "library", "components": []}]}`,
+			wantErr: true,
+		},
+		{
+			name:    "cyclonedx with wrong bomFormat",
+			doc:     `{"bomFormat": "SPDX", "specVersion": "1.4"}`,
+			wantErr: true,
+		},
+		{
+			name:    "unknown document",
+			doc:     `{"name": "example"}`,
+			wantErr: true,
+		},
+		{
+			name:    "not json",
+			doc:     `SPDXVersion: SPDX-2.2`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse([]byte(tt.doc))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got.Format != tt.wantFormat || got.SpecVersion != tt.wantVersion {
+				t.Errorf("Parse() = %s %s, want %s %s", got.Format, got.SpecVersion, tt.wantFormat, tt.wantVersion)
+			}
+			if !reflect.DeepEqual(got.Digests, tt.wantDigests) {
+				t.Errorf("Parse() digests = %v, want %v", got.Digests, tt.wantDigests)
+			}
+		})
+	}
+}
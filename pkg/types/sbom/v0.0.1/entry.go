@@ -0,0 +1,428 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sbom
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/asaskevich/govalidator"
+	"github.com/go-openapi/strfmt"
+	"github.com/go-openapi/swag"
+	"github.com/spf13/viper"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/sigstore/rekor/pkg/generated/models"
+	"github.com/sigstore/rekor/pkg/log"
+	"github.com/sigstore/rekor/pkg/pki"
+	"github.com/sigstore/rekor/pkg/types"
+	"github.com/sigstore/rekor/pkg/types/sbom"
+	"github.com/sigstore/rekor/pkg/util"
+)
+
+const (
+	APIVERSION = "0.0.1"
+)
+
+func init() {
+	if err := sbom.VersionMap.SetEntryFactory(APIVERSION, NewEntry); err != nil {
+		log.Logger.Panic(err)
+	}
+}
+
+type V001Entry struct {
+	SBOMObj models.SbomV001Schema
+	// raw and doc are only populated once the document has been fetched and parsed
+	raw []byte
+	doc *sbom.Document
+}
+
+func (v V001Entry) APIVersion() string {
+	return APIVERSION
+}
+
+func NewEntry() types.EntryImpl {
+	return &V001Entry{}
+}
+
+func (v V001Entry) IndexKeys() ([]string, error) {
+	var result []string
+
+	keyObj, err := v.publicKey()
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := keyObj.CanonicalValue()
+	if err != nil {
+		log.Logger.Error(err)
+	} else {
+		keyHash := sha256.Sum256(key)
+		result = append(result, strings.ToLower(hex.EncodeToString(keyHash[:])))
+	}
+
+	result = append(result, keyObj.Subjects()...)
+
+	if v.SBOMObj.Document.Hash != nil {
+		hashKey := strings.ToLower(fmt.Sprintf("%s:%s", *v.SBOMObj.Document.Hash.Algorithm, *v.SBOMObj.Document.Hash.Value))
+		result = append(result, hashKey)
+	}
+	result = append(result, types.ArtifactURLIndexKeys(v.SBOMObj.Document.URL)...)
+
+	// the digests of the components described by the document allow searching
+	// for every SBOM which references a given artifact
+	if v.doc != nil {
+		result = append(result, v.doc.Digests...)
+	}
+
+	return result, nil
+}
+
+func (v V001Entry) publicKey() (pki.PublicKey, error) {
+	af, err := pki.NewArtifactFactory(pki.Format(v.SBOMObj.Signature.Format))
+	if err != nil {
+		return nil, err
+	}
+	return af.NewPublicKey(bytes.NewReader(v.SBOMObj.Signature.PublicKey.Content))
+}
+
+// Signers implements types.SignerProvider
+func (v V001Entry) Signers() ([]pki.PublicKey, error) {
+	keyObj, err := v.publicKey()
+	if err != nil {
+		return nil, err
+	}
+	return []pki.PublicKey{keyObj}, nil
+}
+
+func (v *V001Entry) Unmarshal(pe models.ProposedEntry) error {
+	s, ok := pe.(*models.Sbom)
+	if !ok {
+		return errors.New("cannot unmarshal non SBOM v0.0.1 type")
+	}
+
+	if err := types.DecodeEntry(s.Spec, &v.SBOMObj); err != nil {
+		return err
+	}
+
+	// field validation
+	if err := v.SBOMObj.Validate(strfmt.Default); err != nil {
+		return err
+	}
+
+	// cross field validation
+	return v.validate()
+}
+
+func (v *V001Entry) hasExternalEntities() bool {
+	if v.SBOMObj.Document != nil && v.SBOMObj.Document.URL.String() != "" {
+		return true
+	}
+	if v.SBOMObj.Signature != nil && v.SBOMObj.Signature.URL.String() != "" {
+		return true
+	}
+	if v.SBOMObj.Signature != nil && v.SBOMObj.Signature.PublicKey != nil && v.SBOMObj.Signature.PublicKey.URL.String() != "" {
+		return true
+	}
+	return false
+}
+
+// fetchExternalEntities retrieves the document, signature and public key, verifies the signature over the
+// document and parses it; the whole document is held in memory as it must be parsed to be indexed
+func (v *V001Entry) fetchExternalEntities(ctx context.Context) (pki.PublicKey, pki.Signature, error) {
+	if err := v.validate(); err != nil {
+		return nil, nil, types.ValidationError(err)
+	}
+
+	af, err := pki.NewArtifactFactory(pki.Format(v.SBOMObj.Signature.Format))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	oldSHA := ""
+	if v.SBOMObj.Document.Hash != nil && v.SBOMObj.Document.Hash.Value != nil {
+		oldSHA = swag.StringValue(v.SBOMObj.Document.Hash.Value)
+	}
+
+	g, ctx := errgroup.WithContext(ctx)
+
+	var raw []byte
+	var doc *sbom.Document
+	var computedSHA string
+	g.Go(func() error {
+		docReadCloser, err := util.FileOrURLReadCloser(ctx, v.SBOMObj.Document.URL.String(), v.SBOMObj.Document.Content)
+		if err != nil {
+			return err
+		}
+		defer docReadCloser.Close()
+
+		raw, err = ioutil.ReadAll(docReadCloser)
+		if err != nil {
+			return err
+		}
+
+		h := sha256.Sum256(raw)
+		computedSHA = hex.EncodeToString(h[:])
+		if oldSHA != "" && computedSHA != oldSHA {
+			return types.ValidationError(fmt.Errorf("SHA mismatch: %s != %s", computedSHA, oldSHA))
+		}
+
+		doc, err = sbom.Parse(raw)
+		if err != nil {
+			return types.ValidationError(err)
+		}
+		return nil
+	})
+
+	var sigObj pki.Signature
+	g.Go(func() error {
+		sigReadCloser, err := util.FileOrURLReadCloser(ctx, v.SBOMObj.Signature.URL.String(), v.SBOMObj.Signature.Content)
+		if err != nil {
+			return err
+		}
+		defer sigReadCloser.Close()
+
+		sigObj, err = af.NewSignature(sigReadCloser)
+		if err != nil {
+			return types.ValidationError(err)
+		}
+		return nil
+	})
+
+	var keyObj pki.PublicKey
+	g.Go(func() error {
+		keyReadCloser, err := util.FileOrURLReadCloser(ctx, v.SBOMObj.Signature.PublicKey.URL.String(), v.SBOMObj.Signature.PublicKey.Content)
+		if err != nil {
+			return err
+		}
+		defer keyReadCloser.Close()
+
+		keyObj, err = af.NewPublicKey(keyReadCloser)
+		if err != nil {
+			return types.ValidationError(err)
+		}
+		return nil
+	})
+
+	if err := g.Wait(); err != nil {
+		return nil, nil, err
+	}
+
+	if err := sigObj.Verify(bytes.NewReader(raw), keyObj); err != nil {
+		return nil, nil, types.ValidationError(err)
+	}
+
+	// if we get here, all the entities were fetched and the signature verified
+	if oldSHA == "" {
+		v.SBOMObj.Document.Hash = &models.SbomV001SchemaDocumentHash{}
+		v.SBOMObj.Document.Hash.Algorithm = swag.String(models.SbomV001SchemaDocumentHashAlgorithmSha256)
+		v.SBOMObj.Document.Hash.Value = swag.String(computedSHA)
+	}
+	v.SBOMObj.Document.Format = doc.Format
+	v.SBOMObj.Document.SpecVersion = doc.SpecVersion
+	v.raw = raw
+	v.doc = doc
+
+	return keyObj, sigObj, nil
+}
+
+func (v *V001Entry) Canonicalize(ctx context.Context) ([]byte, error) {
+	keyObj, sigObj, err := v.fetchExternalEntities(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	canonicalEntry := models.SbomV001Schema{}
+
+	// need to canonicalize signature & key content
+	canonicalEntry.Signature = &models.SbomV001SchemaSignature{}
+	// signature URL (if known) is not set deliberately
+	canonicalEntry.Signature.Format = v.SBOMObj.Signature.Format
+	canonicalEntry.Signature.Content, err = sigObj.CanonicalValue()
+	if err != nil {
+		return nil, err
+	}
+
+	// key URL (if known) is not set deliberately
+	canonicalEntry.Signature.PublicKey = &models.SbomV001SchemaSignaturePublicKey{}
+	canonicalEntry.Signature.PublicKey.Content, err = keyObj.CanonicalValue()
+	if err != nil {
+		return nil, err
+	}
+
+	canonicalEntry.Document = &models.SbomV001SchemaDocument{}
+	canonicalEntry.Document.Format = v.SBOMObj.Document.Format
+	canonicalEntry.Document.SpecVersion = v.SBOMObj.Document.SpecVersion
+	canonicalEntry.Document.Hash = v.SBOMObj.Document.Hash
+	// document content is not set deliberately; it is kept in attestation storage when that is enabled
+
+	// wrap in valid object with kind and apiVersion set
+	s := models.Sbom{}
+	s.APIVersion = swag.String(APIVERSION)
+	s.Spec = &canonicalEntry
+
+	v.SBOMObj = canonicalEntry
+
+	return json.Marshal(&s)
+}
+
+// validate performs cross-field validation for fields in object
+func (v V001Entry) validate() error {
+	sig := v.SBOMObj.Signature
+	if sig == nil {
+		return errors.New("missing signature")
+	}
+	if len(sig.Content) == 0 && sig.URL.String() == "" {
+		return errors.New("one of 'content' or 'url' must be specified for signature")
+	}
+
+	key := sig.PublicKey
+	if key == nil {
+		return errors.New("missing public key")
+	}
+	if len(key.Content) == 0 && key.URL.String() == "" {
+		return errors.New("one of 'content' or 'url' must be specified for publicKey")
+	}
+
+	doc := v.SBOMObj.Document
+	if doc == nil {
+		return errors.New("missing document")
+	}
+
+	hash := doc.Hash
+	if hash != nil {
+		if !govalidator.IsHash(swag.StringValue(hash.Value), swag.StringValue(hash.Algorithm)) {
+			return errors.New("invalid value for hash")
+		}
+	} else if len(doc.Content) == 0 && doc.URL.String() == "" {
+		return errors.New("one of 'content' or 'url' must be specified for document")
+	}
+
+	return nil
+}
+
+// Attestation returns the SBOM document so that it can be retrieved alongside the entry
+func (v V001Entry) Attestation() []byte {
+	if len(v.raw) > viper.GetInt("max_attestation_size") {
+		log.Logger.Infof("Skipping attestation storage, size %d is greater than max %d", len(v.raw), viper.GetInt("max_attestation_size"))
+		return nil
+	}
+	return v.raw
+}
+
+func (v V001Entry) CreateFromArtifactProperties(ctx context.Context, props types.ArtifactProperties) (models.ProposedEntry, error) {
+	returnVal := models.Sbom{}
+	re := V001Entry{}
+
+	// we will need the document, public-key, signature
+	re.SBOMObj.Document = &models.SbomV001SchemaDocument{}
+
+	var err error
+	artifactBytes := props.ArtifactBytes
+	if artifactBytes == nil {
+		if props.ArtifactPath == nil {
+			return nil, errors.New("path to SBOM document (file or URL) must be specified")
+		}
+		if props.ArtifactPath.IsAbs() {
+			re.SBOMObj.Document.URL = strfmt.URI(props.ArtifactPath.String())
+			if props.ArtifactHash != "" {
+				re.SBOMObj.Document.Hash = &models.SbomV001SchemaDocumentHash{
+					Algorithm: swag.String(models.SbomV001SchemaDocumentHashAlgorithmSha256),
+					Value:     swag.String(props.ArtifactHash),
+				}
+			}
+		} else {
+			artifactBytes, err = ioutil.ReadFile(filepath.Clean(props.ArtifactPath.Path))
+			if err != nil {
+				return nil, fmt.Errorf("error reading SBOM document: %w", err)
+			}
+			re.SBOMObj.Document.Content = strfmt.Base64(artifactBytes)
+		}
+	} else {
+		re.SBOMObj.Document.Content = strfmt.Base64(artifactBytes)
+	}
+
+	re.SBOMObj.Signature = &models.SbomV001SchemaSignature{}
+	switch props.PKIFormat {
+	case "pgp":
+		re.SBOMObj.Signature.Format = models.SbomV001SchemaSignatureFormatPgp
+	case "minisign":
+		re.SBOMObj.Signature.Format = models.SbomV001SchemaSignatureFormatMinisign
+	case "x509":
+		re.SBOMObj.Signature.Format = models.SbomV001SchemaSignatureFormatX509
+	case "ssh":
+		re.SBOMObj.Signature.Format = models.SbomV001SchemaSignatureFormatSSH
+	}
+	sigBytes := props.SignatureBytes
+	if sigBytes == nil {
+		if props.SignaturePath == nil {
+			return nil, errors.New("a detached signature must be provided")
+		}
+		if props.SignaturePath.IsAbs() {
+			re.SBOMObj.Signature.URL = strfmt.URI(props.SignaturePath.String())
+		} else {
+			sigBytes, err = ioutil.ReadFile(filepath.Clean(props.SignaturePath.Path))
+			if err != nil {
+				return nil, fmt.Errorf("error reading signature file: %w", err)
+			}
+			re.SBOMObj.Signature.Content = strfmt.Base64(sigBytes)
+		}
+	} else {
+		re.SBOMObj.Signature.Content = strfmt.Base64(sigBytes)
+	}
+
+	re.SBOMObj.Signature.PublicKey = &models.SbomV001SchemaSignaturePublicKey{}
+	publicKeyBytes := props.PublicKeyBytes
+	if publicKeyBytes == nil {
+		if props.PublicKeyPath == nil {
+			return nil, errors.New("a public key must be provided to verify the detached signature")
+		}
+		if props.PublicKeyPath.IsAbs() {
+			re.SBOMObj.Signature.PublicKey.URL = strfmt.URI(props.PublicKeyPath.String())
+		} else {
+			publicKeyBytes, err = ioutil.ReadFile(filepath.Clean(props.PublicKeyPath.Path))
+			if err != nil {
+				return nil, fmt.Errorf("error reading public key file: %w", err)
+			}
+			re.SBOMObj.Signature.PublicKey.Content = strfmt.Base64(publicKeyBytes)
+		}
+	} else {
+		re.SBOMObj.Signature.PublicKey.Content = strfmt.Base64(publicKeyBytes)
+	}
+
+	if err := re.validate(); err != nil {
+		return nil, err
+	}
+
+	if re.hasExternalEntities() {
+		if _, _, err := re.fetchExternalEntities(ctx); err != nil {
+			return nil, fmt.Errorf("error retrieving external entities: %v", err)
+		}
+	}
+
+	returnVal.APIVersion = swag.String(re.APIVersion())
+	returnVal.Spec = re.SBOMObj
+
+	return &returnVal, nil
+}
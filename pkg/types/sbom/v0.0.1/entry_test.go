@@ -0,0 +1,324 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sbom
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/go-openapi/runtime"
+	"github.com/go-openapi/strfmt"
+	"github.com/go-openapi/swag"
+	"github.com/spf13/viper"
+	"go.uber.org/goleak"
+
+	"github.com/sigstore/rekor/pkg/generated/models"
+	"github.com/sigstore/rekor/pkg/types"
+)
+
+func TestMain(m *testing.M) {
+	goleak.VerifyTestMain(m)
+}
+
+func TestNewEntryReturnType(t *testing.T) {
+	entry := NewEntry()
+	if reflect.TypeOf(entry) != reflect.ValueOf(&V001Entry{}).Type() {
+		t.Errorf("invalid type returned from NewEntry: %T", entry)
+	}
+}
+
+const componentDigest = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+var cycloneDXDocument = []byte(`{
+	"bomFormat": "CycloneDX",
+	"specVersion": "1.4",
+	"components": [{
+		"type": "library",
+		"name": "foo",
+		"hashes": [{"alg": "SHA-256", "content": "` + componentDigest + `"}]
+	}]
+}`)
+
+// sign returns a PEM encoded public key and a signature over each of the documents made with its private key
+func sign(t *testing.T, docs ...[]byte) ([]byte, [][]byte) {
+	t.Helper()
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(priv.Public())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sigs [][]byte
+	for _, doc := range docs {
+		h := sha256.Sum256(doc)
+		sig, err := ecdsa.SignASN1(rand.Reader, priv, h[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		sigs = append(sigs, sig)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), sigs
+}
+
+func TestCrossFieldValidation(t *testing.T) {
+	type TestCase struct {
+		caseDesc                  string
+		entry                     V001Entry
+		hasExtEntities            bool
+		expectUnmarshalSuccess    bool
+		expectCanonicalizeSuccess bool
+	}
+
+	notAnSBOM := []byte(`{"name": "not an sbom"}`)
+	keyBytes, sigs := sign(t, cycloneDXDocument, notAnSBOM)
+	sigBytes, notAnSBOMSig := sigs[0], sigs[1]
+
+	h := sha256.Sum256(cycloneDXDocument)
+	dataSHA := hex.EncodeToString(h[:])
+
+	testServer := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			var file []byte
+			switch r.URL.Path {
+			case "/signature":
+				file = sigBytes
+			case "/key":
+				file = keyBytes
+			case "/sbom":
+				file = cycloneDXDocument
+			default:
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write(file)
+		}))
+	defer testServer.Close()
+
+	signature := func(sig []byte) *models.SbomV001SchemaSignature {
+		return &models.SbomV001SchemaSignature{
+			Format:  "x509",
+			Content: strfmt.Base64(sig),
+			PublicKey: &models.SbomV001SchemaSignaturePublicKey{
+				Content: strfmt.Base64(keyBytes),
+			},
+		}
+	}
+
+	testCases := []TestCase{
+		{
+			caseDesc:               "empty obj",
+			entry:                  V001Entry{},
+			expectUnmarshalSuccess: false,
+		},
+		{
+			caseDesc: "document without signature",
+			entry: V001Entry{
+				SBOMObj: models.SbomV001Schema{
+					Document: &models.SbomV001SchemaDocument{
+						Content: strfmt.Base64(cycloneDXDocument),
+					},
+				},
+			},
+			expectUnmarshalSuccess: false,
+		},
+		{
+			caseDesc: "signature without document",
+			entry: V001Entry{
+				SBOMObj: models.SbomV001Schema{
+					Signature: signature(sigBytes),
+				},
+			},
+			expectUnmarshalSuccess: false,
+		},
+		{
+			caseDesc: "signed document",
+			entry: V001Entry{
+				SBOMObj: models.SbomV001Schema{
+					Signature: signature(sigBytes),
+					Document: &models.SbomV001SchemaDocument{
+						Content: strfmt.Base64(cycloneDXDocument),
+					},
+				},
+			},
+			expectUnmarshalSuccess:    true,
+			expectCanonicalizeSuccess: true,
+		},
+		{
+			caseDesc: "signed document fetched from URLs",
+			entry: V001Entry{
+				SBOMObj: models.SbomV001Schema{
+					Signature: &models.SbomV001SchemaSignature{
+						Format: "x509",
+						URL:    strfmt.URI(testServer.URL + "/signature"),
+						PublicKey: &models.SbomV001SchemaSignaturePublicKey{
+							URL: strfmt.URI(testServer.URL + "/key"),
+						},
+					},
+					Document: &models.SbomV001SchemaDocument{
+						Hash: &models.SbomV001SchemaDocumentHash{
+							Algorithm: swag.String(models.SbomV001SchemaDocumentHashAlgorithmSha256),
+							Value:     swag.String(dataSHA),
+						},
+						URL: strfmt.URI(testServer.URL + "/sbom"),
+					},
+				},
+			},
+			hasExtEntities:            true,
+			expectUnmarshalSuccess:    true,
+			expectCanonicalizeSuccess: true,
+		},
+		{
+			caseDesc: "document hash mismatch",
+			entry: V001Entry{
+				SBOMObj: models.SbomV001Schema{
+					Signature: signature(sigBytes),
+					Document: &models.SbomV001SchemaDocument{
+						Hash: &models.SbomV001SchemaDocumentHash{
+							Algorithm: swag.String(models.SbomV001SchemaDocumentHashAlgorithmSha256),
+							Value:     swag.String(componentDigest),
+						},
+						URL: strfmt.URI(testServer.URL + "/sbom"),
+					},
+				},
+			},
+			hasExtEntities:            true,
+			expectUnmarshalSuccess:    true,
+			expectCanonicalizeSuccess: false,
+		},
+		{
+			caseDesc: "signature over a different document",
+			entry: V001Entry{
+				SBOMObj: models.SbomV001Schema{
+					Signature: signature(notAnSBOMSig),
+					Document: &models.SbomV001SchemaDocument{
+						Content: strfmt.Base64(cycloneDXDocument),
+					},
+				},
+			},
+			expectUnmarshalSuccess:    true,
+			expectCanonicalizeSuccess: false,
+		},
+		{
+			caseDesc: "signed document which is not an SBOM",
+			entry: V001Entry{
+				SBOMObj: models.SbomV001Schema{
+					Signature: signature(notAnSBOMSig),
+					Document: &models.SbomV001SchemaDocument{
+						Content: strfmt.Base64(notAnSBOM),
+					},
+				},
+			},
+			expectUnmarshalSuccess:    true,
+			expectCanonicalizeSuccess: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		v := &V001Entry{}
+		r := models.Sbom{
+			APIVersion: swag.String(tc.entry.APIVersion()),
+			Spec:       tc.entry.SBOMObj,
+		}
+
+		if err := v.Unmarshal(&r); (err == nil) != tc.expectUnmarshalSuccess {
+			t.Fatalf("unexpected result in '%v': %v", tc.caseDesc, err)
+		}
+		// No need to continue here if we didn't unmarshal
+		if !tc.expectUnmarshalSuccess {
+			continue
+		}
+
+		if tc.entry.hasExternalEntities() != tc.hasExtEntities {
+			t.Errorf("unexpected result from HasExternalEntities for '%v'", tc.caseDesc)
+		}
+
+		b, err := v.Canonicalize(context.TODO())
+		if (err == nil) != tc.expectCanonicalizeSuccess {
+			t.Errorf("unexpected result from Canonicalize for '%v': %v", tc.caseDesc, err)
+		} else if err != nil {
+			if _, ok := err.(types.ValidationError); !ok {
+				t.Errorf("canonicalize returned an unexpected error that isn't of type types.ValidationError: %v", err)
+			}
+		}
+		if b != nil {
+			if v.SBOMObj.Document.Format != models.SbomV001SchemaDocumentFormatCyclonedx || v.SBOMObj.Document.SpecVersion != "1.4" {
+				t.Errorf("unexpected document format for '%v': %s %s", tc.caseDesc, v.SBOMObj.Document.Format, v.SBOMObj.Document.SpecVersion)
+			}
+			pe, err := models.UnmarshalProposedEntry(bytes.NewReader(b), runtime.JSONConsumer())
+			if err != nil {
+				t.Errorf("unexpected err from Unmarshalling canonicalized entry for '%v': %v", tc.caseDesc, err)
+			}
+			if _, err := types.NewEntry(pe); err != nil {
+				t.Errorf("unexpected err from type-specific unmarshalling for '%v': %v", tc.caseDesc, err)
+			}
+		}
+	}
+}
+
+func TestIndexKeysAndAttestation(t *testing.T) {
+	keyBytes, sigs := sign(t, cycloneDXDocument)
+	v := &V001Entry{
+		SBOMObj: models.SbomV001Schema{
+			Signature: &models.SbomV001SchemaSignature{
+				Format:    "x509",
+				Content:   strfmt.Base64(sigs[0]),
+				PublicKey: &models.SbomV001SchemaSignaturePublicKey{Content: strfmt.Base64(keyBytes)},
+			},
+			Document: &models.SbomV001SchemaDocument{Content: strfmt.Base64(cycloneDXDocument)},
+		},
+	}
+	if _, err := v.Canonicalize(context.TODO()); err != nil {
+		t.Fatal(err)
+	}
+
+	keys, err := v.IndexKeys()
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := sha256.Sum256(cycloneDXDocument)
+	for _, want := range []string{"sha256:" + hex.EncodeToString(h[:]), "sha256:" + componentDigest} {
+		found := false
+		for _, k := range keys {
+			found = found || k == want
+		}
+		if !found {
+			t.Errorf("expected index key %s in %v", want, keys)
+		}
+	}
+
+	viper.Set("max_attestation_size", len(cycloneDXDocument))
+	if !bytes.Equal(v.Attestation(), cycloneDXDocument) {
+		t.Error("expected SBOM document to be returned for attestation storage")
+	}
+	viper.Set("max_attestation_size", len(cycloneDXDocument)-1)
+	if v.Attestation() != nil {
+		t.Error("expected SBOM document larger than the maximum attestation size to be skipped")
+	}
+}
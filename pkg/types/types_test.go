@@ -18,6 +18,7 @@ package types
 import (
 	"context"
 	"errors"
+	"strings"
 	"testing"
 
 	"github.com/go-openapi/strfmt"
@@ -38,6 +39,12 @@ func (e InvalidEntry) Kind() string {
 
 func (e InvalidEntry) SetKind(string) {}
 
+func (e InvalidEntry) Annotations() models.Annotations {
+	return nil
+}
+
+func (e InvalidEntry) SetAnnotations(models.Annotations) {}
+
 func (e InvalidEntry) Validate(formats strfmt.Registry) error {
 	return nil
 }
@@ -57,6 +64,12 @@ func (e UnmarshalErrorValidEntry) Kind() string {
 
 func (e UnmarshalErrorValidEntry) SetKind(string) {}
 
+func (e UnmarshalErrorValidEntry) Annotations() models.Annotations {
+	return nil
+}
+
+func (e UnmarshalErrorValidEntry) SetAnnotations(models.Annotations) {}
+
 func (e UnmarshalErrorValidEntry) Validate(formats strfmt.Registry) error {
 	return errors.New("invalid content")
 }
@@ -88,3 +101,76 @@ func TestNewEntry(t *testing.T) {
 		}
 	}
 }
+
+// canonicalEntry is an EntryImpl which canonicalizes to a fixed body
+type canonicalEntry struct {
+	EntryImpl
+	body string
+}
+
+func (e canonicalEntry) Canonicalize(ctx context.Context) ([]byte, error) {
+	return []byte(e.body), nil
+}
+
+func TestCanonicalizeEntry(t *testing.T) {
+	entry := canonicalEntry{body: `{"spec":{"b":1,"a":2},"kind":"test","apiVersion":"0.0.1"}`}
+	tests := []struct {
+		name        string
+		annotations models.Annotations
+		want        string
+		wantErr     string
+	}{
+		{
+			name: "no annotations",
+			want: `{"apiVersion":"0.0.1","kind":"test","spec":{"a":2,"b":1}}`,
+		},
+		{
+			name:        "annotations",
+			annotations: models.Annotations{"release": "v1.2.0", "build/id": "1234"},
+			want:        `{"annotations":{"build/id":"1234","release":"v1.2.0"},"apiVersion":"0.0.1","kind":"test","spec":{"a":2,"b":1}}`,
+		},
+		{
+			name:        "source annotations",
+			annotations: models.Annotations{CommitAnnotation: "4b825dc642cb6eb9a060e54bf8d69288fbee4904", RepositoryAnnotation: "https://github.com/sigstore/rekor"},
+			want:        `{"annotations":{"git.commit":"4b825dc642cb6eb9a060e54bf8d69288fbee4904","git.repository":"https://github.com/sigstore/rekor"},"apiVersion":"0.0.1","kind":"test","spec":{"a":2,"b":1}}`,
+		},
+		{
+			name:        "abbreviated commit",
+			annotations: models.Annotations{CommitAnnotation: "4b825dc6"},
+			wantErr:     "git.commit",
+		},
+		{
+			name:        "invalid key",
+			annotations: models.Annotations{"-build id": "1234"},
+			wantErr:     "invalid annotation key",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := CanonicalizeEntry(context.Background(), entry, tt.annotations)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func BenchmarkCanonicalizeEntry(b *testing.B) {
+	entry := canonicalEntry{body: `{"spec":{"b":1,"a":2},"kind":"test","apiVersion":"0.0.1"}`}
+	annotations := models.Annotations{"release": "v1.2.0", "build/id": "1234"}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := CanonicalizeEntry(context.Background(), entry, annotations); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -16,6 +16,7 @@
 package util
 
 import (
+	"context"
 	"crypto"
 	"crypto/ecdsa"
 	"crypto/ed25519"
@@ -442,3 +443,77 @@ func TestUnmarshalSignedCheckpoint(t *testing.T) {
 		})
 	}
 }
+
+func TestSignedCheckpointNoteCompatibility(t *testing.T) {
+	ecdsaKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	signer, _ := signature.LoadSigner(ecdsaKey, crypto.SHA256)
+	verifier, _ := signature.LoadVerifier(ecdsaKey.Public(), crypto.SHA256)
+	_, witnessKey, _ := ed25519.GenerateKey(rand.Reader)
+	witness, _ := signature.LoadSigner(witnessKey, crypto.Hash(0))
+
+	sth, err := CreateSignedCheckpoint(Checkpoint{Origin: "Rekor", Size: 123, Hash: []byte("bananas")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sth.SetTimestamp(uint64(time.Now().UnixNano()))
+	if _, err := sth.Sign("rekor.example.com", signer, options.WithContext(context.Background())); err != nil {
+		t.Fatal(err)
+	}
+	// a witness cosigning the checkpoint must not prevent it from being verified with the log's key
+	if _, err := sth.Cosign("witness.example.com", witness, options.WithContext(context.Background())); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sth.Cosign("witness.example.com", witness, options.WithContext(context.Background())); err == nil {
+		t.Fatal("expected error cosigning the checkpoint twice")
+	}
+	if !sth.Verify(verifier) {
+		t.Fatal("checkpoint with cosignature failed verification")
+	}
+	witnessVerifier, _ := signature.LoadVerifier(witnessKey.Public(), crypto.Hash(0))
+	if !sth.VerifyCosignature("witness.example.com", witnessVerifier) {
+		t.Fatal("cosignature failed verification")
+	}
+	if sth.VerifyCosignature("other.example.com", witnessVerifier) {
+		t.Fatal("cosignature verified under the name of another witness")
+	}
+	if sth.VerifyCosignature("witness.example.com", verifier) {
+		t.Fatal("cosignature verified with the key of the log")
+	}
+
+	nv, err := NewNoteVerifier("rekor.example.com", verifier)
+	if err != nil {
+		t.Fatal(err)
+	}
+	n, err := note.Open([]byte(sth.SignedNote.String()), note.VerifierList(nv))
+	if err != nil {
+		t.Fatalf("note.Open: %v", err)
+	}
+	if n.Text != sth.Note || len(n.Sigs) != 1 || len(n.UnverifiedSigs) != 1 {
+		t.Fatalf("unexpected note %+v", n)
+	}
+
+	other, _ := NewNoteVerifier("rekor.example.com", func() signature.Verifier {
+		k, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		v, _ := signature.LoadVerifier(k.Public(), crypto.SHA256)
+		return v
+	}())
+	if _, err := note.Open([]byte(sth.SignedNote.String()), note.VerifierList(other)); err == nil {
+		t.Fatal("expected error opening note without the log's key")
+	}
+
+	// a cosignature which does not verify is rejected, while the signature of the log still verifies
+	forged := *sth
+	forged.Signatures = append([]note.Signature{}, sth.Signatures...)
+	forged.Signatures[1].Base64 = forged.Signatures[0].Base64
+	if forged.VerifyCosignature("witness.example.com", witnessVerifier) {
+		t.Fatal("forged cosignature passed verification")
+	}
+	if !forged.Verify(verifier) {
+		t.Fatal("checkpoint with a forged cosignature failed verification with the log's key")
+	}
+
+	unsigned, _ := CreateSignedCheckpoint(Checkpoint{Origin: "Rekor", Size: 1, Hash: []byte("bananas")})
+	if _, err := unsigned.Cosign("witness.example.com", witness, options.WithContext(context.Background())); err == nil {
+		t.Fatal("expected error cosigning a checkpoint the log has not signed")
+	}
+}
@@ -0,0 +1,92 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"crypto"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	// register the hash functions returned by HashFunc
+	_ "crypto/sha1" // #nosec G505
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+)
+
+// Names of the hash algorithms a digest may be tagged with, as in sha256:<hex>
+const (
+	SHA1   = "sha1"
+	SHA256 = "sha256"
+	SHA384 = "sha384"
+	SHA512 = "sha512"
+)
+
+var hashFuncs = map[string]crypto.Hash{
+	SHA1:   crypto.SHA1,
+	SHA256: crypto.SHA256,
+	SHA384: crypto.SHA384,
+	SHA512: crypto.SHA512,
+}
+
+// ParseDigest splits a digest of the form [algorithm:]<hex> into its algorithm and lower case hex value.
+// Without a tag, the algorithm is inferred from the length of the value.
+func ParseDigest(v string) (algorithm, value string, err error) {
+	value = v
+	if i := strings.Index(v, ":"); i >= 0 {
+		algorithm, value = strings.ToLower(v[:i]), v[i+1:]
+		if _, ok := hashFuncs[algorithm]; !ok {
+			return "", "", fmt.Errorf("unsupported hash algorithm %q", algorithm)
+		}
+	}
+	value = strings.ToLower(value)
+	b, err := hex.DecodeString(value)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid digest %q: %w", v, err)
+	}
+	if algorithm == "" {
+		for name, hf := range hashFuncs {
+			if hf.Size() == len(b) {
+				algorithm = name
+			}
+		}
+		if algorithm == "" {
+			return "", "", fmt.Errorf("invalid digest %q: no supported hash algorithm produces %d bytes", v, len(b))
+		}
+	} else if len(b) != hashFuncs[algorithm].Size() {
+		return "", "", fmt.Errorf("invalid %s digest %q: expected %d hexadecimal characters", algorithm, v, 2*hashFuncs[algorithm].Size())
+	}
+	return algorithm, value, nil
+}
+
+// NormalizeDigest returns a digest accepted by ParseDigest in the form algorithm:<lower case hex>, as
+// digests are indexed
+func NormalizeDigest(v string) (string, error) {
+	algorithm, value, err := ParseDigest(v)
+	if err != nil {
+		return "", err
+	}
+	return algorithm + ":" + value, nil
+}
+
+// HashFunc returns the hash function of one of the named algorithms
+func HashFunc(algorithm string) (crypto.Hash, error) {
+	hf, ok := hashFuncs[strings.ToLower(algorithm)]
+	if !ok {
+		return 0, fmt.Errorf("unsupported hash algorithm %q", algorithm)
+	}
+	return hf, nil
+}
@@ -0,0 +1,71 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNormalizeDigest(t *testing.T) {
+	sha1 := strings.Repeat("a", 40)
+	sha256 := strings.Repeat("b", 64)
+	sha384 := strings.Repeat("c", 96)
+	sha512 := strings.Repeat("d", 128)
+	tests := []struct {
+		digest  string
+		want    string
+		wantErr bool
+	}{
+		{digest: sha1, want: "sha1:" + sha1},
+		{digest: sha256, want: "sha256:" + sha256},
+		{digest: "SHA256:" + strings.ToUpper(sha256), want: "sha256:" + sha256},
+		{digest: sha384, want: "sha384:" + sha384},
+		{digest: "sha384:" + sha384, want: "sha384:" + sha384},
+		{digest: sha512, want: "sha512:" + sha512},
+		{digest: "sha512:" + sha512, want: "sha512:" + sha512},
+		{digest: "sha512:" + sha256, wantErr: true},
+		{digest: "md5:" + strings.Repeat("e", 32), wantErr: true},
+		{digest: strings.Repeat("e", 32), wantErr: true},
+		{digest: "sha256:" + strings.Repeat("g", 64), wantErr: true},
+		{digest: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := NormalizeDigest(tt.digest)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("NormalizeDigest(%q) error = %v, wantErr %v", tt.digest, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("NormalizeDigest(%q) = %q, want %q", tt.digest, got, tt.want)
+		}
+	}
+}
+
+func TestHashFunc(t *testing.T) {
+	for _, alg := range []string{SHA1, SHA256, SHA384, SHA512} {
+		hf, err := HashFunc(alg)
+		if err != nil {
+			t.Fatalf("HashFunc(%v): %v", alg, err)
+		}
+		if !hf.Available() {
+			t.Errorf("hash function for %v is not available", alg)
+		}
+	}
+	if _, err := HashFunc("md5"); err == nil {
+		t.Error("expected an error for an unsupported algorithm")
+	}
+}
@@ -24,17 +24,19 @@ import (
 	"net/http"
 )
 
+// FetchClient is used to fetch the content of entries given by URL; the CLI replaces it with a client
+// sharing the transport of its rekor client
+var FetchClient = http.DefaultClient
+
 // FileOrURLReadCloser Note: caller is responsible for closing ReadCloser returned from method!
 func FileOrURLReadCloser(ctx context.Context, url string, content []byte) (io.ReadCloser, error) {
 	var dataReader io.ReadCloser
 	if url != "" {
-		//TODO: set timeout here, SSL settings?
-		client := &http.Client{}
 		req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 		if err != nil {
 			return nil, err
 		}
-		resp, err := client.Do(req)
+		resp, err := FetchClient.Do(req)
 		if err != nil {
 			return nil, err
 		}
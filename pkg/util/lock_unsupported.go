@@ -0,0 +1,42 @@
+//go:build !linux && !darwin && !dragonfly && !freebsd && !netbsd && !openbsd
+// +build !linux,!darwin,!dragonfly,!freebsd,!netbsd,!openbsd
+
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"errors"
+	"os"
+)
+
+// ErrLocked is returned by TryLockFile if another process holds the lock
+var ErrLocked = errors.New("file is locked by another process")
+
+// LockFile does nothing, as files are not locked on this platform
+func LockFile(f *os.File) error {
+	return nil
+}
+
+// TryLockFile does nothing, as files are not locked on this platform
+func TryLockFile(f *os.File) error {
+	return nil
+}
+
+// UnlockFile does nothing, as files are not locked on this platform
+func UnlockFile(f *os.File) error {
+	return nil
+}
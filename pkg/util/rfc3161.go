@@ -23,6 +23,7 @@ import (
 	"crypto/x509"
 	"crypto/x509/pkix"
 	"encoding/asn1"
+	"errors"
 	"fmt"
 	"math/big"
 	"time"
@@ -140,23 +141,86 @@ func ParseTimestampRequest(data []byte) (*pkcs9.TimeStampReq, error) {
 }
 
 func GetSigningTime(psd *pkcs7.ContentInfoSignedData) (time.Time, error) {
+	info, err := getTSTInfo(psd)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return pkcs7.ParseTime(info.GenTime)
+}
+
+func getTSTInfo(psd *pkcs7.ContentInfoSignedData) (*pkcs9.TSTInfo, error) {
 	// See sassoftware pkcs9 package for this code extracting TSTInfo
 	infobytes, err := psd.Content.ContentInfo.Bytes()
 	if err != nil {
-		return time.Time{}, fmt.Errorf("unpack TSTInfo: %w", err)
+		return nil, fmt.Errorf("unpack TSTInfo: %w", err)
 	} else if infobytes[0] == 0x04 {
 		// unwrap dummy OCTET STRING
 		_, err = asn1.Unmarshal(infobytes, &infobytes)
 		if err != nil {
-			return time.Time{}, fmt.Errorf("unpack TSTInfo: %w", err)
+			return nil, fmt.Errorf("unpack TSTInfo: %w", err)
 		}
 	}
 	info := new(pkcs9.TSTInfo)
 	if _, err := asn1.Unmarshal(infobytes, info); err != nil {
-		return time.Time{}, fmt.Errorf("unpack TSTInfo: %w", err)
+		return nil, fmt.Errorf("unpack TSTInfo: %w", err)
 	}
+	return info, nil
+}
 
-	return pkcs7.ParseTime(info.GenTime)
+// VerifyTimestampResponse checks that a DER encoded timestamp response was granted, covers the given
+// digest and is signed by a timestamping certificate which chains up to the last certificate in
+// certChain at the time of signing. The signing time is returned.
+func VerifyTimestampResponse(tsrBytes []byte, digest []byte, certChain []*x509.Certificate) (time.Time, error) {
+	if len(certChain) == 0 {
+		return time.Time{}, errors.New("no certificate chain to verify the timestamp against")
+	}
+
+	var tsr pkcs9.TimeStampResp
+	if rest, err := asn1.Unmarshal(tsrBytes, &tsr); err != nil {
+		return time.Time{}, fmt.Errorf("unmarshalling timestamp response: %w", err)
+	} else if len(rest) != 0 {
+		return time.Time{}, errors.New("unmarshalling timestamp response: trailing bytes")
+	}
+	if tsr.Status.Status != pkcs9.StatusGranted && tsr.Status.Status != pkcs9.StatusGrantedWithMods {
+		return time.Time{}, fmt.Errorf("timestamp response status not granted: %v", tsr.Status.Status)
+	}
+
+	info, err := getTSTInfo(&tsr.TimeStampToken)
+	if err != nil {
+		return time.Time{}, err
+	}
+	hash, ok := x509tools.PkixDigestToHash(info.MessageImprint.HashAlgorithm)
+	if !ok || hash.Size() != len(digest) {
+		return time.Time{}, errors.New("timestamp message imprint uses an unexpected hash algorithm")
+	}
+	if !bytes.Equal(info.MessageImprint.HashedMessage, digest) {
+		return time.Time{}, errors.New("timestamp was not issued for the given digest")
+	}
+	signingTime, err := pkcs7.ParseTime(info.GenTime)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	sig, err := tsr.TimeStampToken.Content.Verify(nil, false)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("verifying timestamp signature: %w", err)
+	}
+
+	roots := x509.NewCertPool()
+	roots.AddCert(certChain[len(certChain)-1])
+	intermediates := x509.NewCertPool()
+	for _, c := range certChain[:len(certChain)-1] {
+		intermediates.AddCert(c)
+	}
+	if _, err := sig.Certificate.Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		CurrentTime:   signingTime,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageTimeStamping},
+	}); err != nil {
+		return time.Time{}, fmt.Errorf("verifying timestamping certificate: %w", err)
+	}
+	return signingTime, nil
 }
 
 func CreateRfc3161Response(ctx context.Context, req pkcs9.TimeStampReq, certChain []*x509.Certificate, signer signature.Signer) (*pkcs9.TimeStampResp, error) {
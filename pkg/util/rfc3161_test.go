@@ -19,6 +19,7 @@ import (
 	"bytes"
 	"context"
 	"crypto"
+	"crypto/sha256"
 	"encoding/asn1"
 	"fmt"
 	"io/ioutil"
@@ -180,3 +181,62 @@ func TestCreateRFC3161Response(t *testing.T) {
 	}
 
 }
+
+func TestVerifyTimestampResponse(t *testing.T) {
+	ctx := context.Background()
+	mem, err := signer.NewMemory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tsa, err := signer.NewMemory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pk, err := tsa.PublicKey(options.WithContext(ctx))
+	if err != nil {
+		t.Fatal(err)
+	}
+	certChain, err := signer.NewTimestampingCertWithChain(ctx, pk, mem, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	digest := sha256.Sum256([]byte("hello"))
+	req, err := TimestampRequestFromDigest(digest[:], TimestampRequestOptions{Hash: crypto.SHA256})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := CreateRfc3161Response(ctx, *req, certChain, tsa)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tsrBytes, err := asn1.Marshal(*resp)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := VerifyTimestampResponse(tsrBytes, digest[:], certChain); err != nil {
+		t.Errorf("unexpected error verifying valid response: %v", err)
+	}
+
+	otherDigest := sha256.Sum256([]byte("goodbye"))
+	if _, err := VerifyTimestampResponse(tsrBytes, otherDigest[:], certChain); err == nil {
+		t.Error("expected error verifying response against a different digest")
+	}
+
+	otherCA, err := signer.NewMemory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherChain, err := signer.NewTimestampingCertWithChain(ctx, pk, otherCA, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := VerifyTimestampResponse(tsrBytes, digest[:], otherChain); err == nil {
+		t.Error("expected error verifying response against an untrusted chain")
+	}
+
+	if _, err := VerifyTimestampResponse([]byte("garbage"), digest[:], certChain); err == nil {
+		t.Error("expected error verifying malformed response")
+	}
+}
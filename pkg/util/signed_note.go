@@ -18,6 +18,7 @@ package util
 import (
 	"bufio"
 	"bytes"
+	"crypto"
 	"crypto/ecdsa"
 	"crypto/ed25519"
 	"crypto/rsa"
@@ -53,16 +54,14 @@ func (s *SignedNote) Sign(identity string, signer signature.Signer, opts signatu
 	if err != nil {
 		return nil, errors.Wrap(err, "retrieving public key")
 	}
-	pubKeyBytes, err := x509.MarshalPKIXPublicKey(pk)
+	hash, err := noteKeyHash(pk)
 	if err != nil {
-		return nil, errors.Wrap(err, "marshalling public key")
+		return nil, err
 	}
 
-	pkSha := sha256.Sum256(pubKeyBytes)
-
 	signature := note.Signature{
 		Name:   identity,
-		Hash:   binary.BigEndian.Uint32(pkSha[:]),
+		Hash:   hash,
 		Base64: base64.StdEncoding.EncodeToString(sig),
 	}
 
@@ -70,39 +69,122 @@ func (s *SignedNote) Sign(identity string, signer signature.Signer, opts signatu
 	return &signature, nil
 }
 
-// Verify checks that one of the signatures can be successfully verified using
-// the supplied public key
-func (s SignedNote) Verify(verifier signature.Verifier) bool {
+// Cosign adds the cosignature of a witness named name, which has checked the note signed by the log,
+// to a SignedNote object. The note must already be signed, and each witness may only cosign it once.
+func (s *SignedNote) Cosign(name string, signer signature.Signer, opts signature.SignOption) (*note.Signature, error) {
 	if len(s.Signatures) == 0 {
+		return nil, errors.New("cosigning a note which has not been signed")
+	}
+	pk, err := signer.PublicKey()
+	if err != nil {
+		return nil, errors.Wrap(err, "retrieving public key")
+	}
+	hash, err := noteKeyHash(pk)
+	if err != nil {
+		return nil, err
+	}
+	for _, sig := range s.Signatures {
+		if sig.Name == name && sig.Hash == hash {
+			return nil, errors.Errorf("note already cosigned by %s", name)
+		}
+	}
+	return s.Sign(name, signer, opts)
+}
+
+// Verify checks that the note carries a signature made with the supplied public key and that every
+// signature made with it verifies. Signatures made with other keys, such as the cosignatures of
+// witnesses, are not checked; use VerifyCosignature to require them.
+func (s SignedNote) Verify(verifier signature.Verifier) bool {
+	return s.verifySignatures(verifier, func(note.Signature) bool { return true })
+}
+
+// VerifyCosignature checks that the note carries a cosignature by the witness named name made with
+// the supplied public key, and that every such cosignature verifies
+func (s SignedNote) VerifyCosignature(name string, verifier signature.Verifier) bool {
+	return s.verifySignatures(verifier, func(sig note.Signature) bool { return sig.Name == name })
+}
+
+// verifySignatures verifies the signatures made with the key of verifier which are selected by match,
+// of which there must be at least one
+func (s SignedNote) verifySignatures(verifier signature.Verifier, match func(note.Signature) bool) bool {
+	pk, err := verifier.PublicKey()
+	if err != nil {
+		return false
+	}
+	hash, err := noteKeyHash(pk)
+	if err != nil {
 		return false
 	}
 
 	msg := []byte(s.Note)
-	digest := sha256.Sum256(msg)
-
+	verified := false
 	for _, s := range s.Signatures {
-		sigBytes, err := base64.StdEncoding.DecodeString(s.Base64)
-		if err != nil {
-			return false
+		if s.Hash != hash || !match(s) {
+			continue
 		}
-		pk, err := verifier.PublicKey()
+		sigBytes, err := base64.StdEncoding.DecodeString(s.Base64)
 		if err != nil {
 			return false
 		}
-		opts := []signature.VerifyOption{}
-		switch pk.(type) {
-		case *rsa.PublicKey, *ecdsa.PublicKey:
-			opts = append(opts, options.WithDigest(digest[:]))
-		case ed25519.PublicKey:
-			break
-		default:
-			return false
-		}
-		if err := verifier.VerifySignature(bytes.NewReader(sigBytes), bytes.NewReader(msg), opts...); err != nil {
+		if !verifyNoteSignature(verifier, pk, msg, sigBytes) {
 			return false
 		}
+		verified = true
+	}
+	return verified
+}
+
+// noteKeyHash returns the hint identifying the key of a signature, the first 4 bytes of the SHA256
+// hash of its DER encoding
+func noteKeyHash(pk crypto.PublicKey) (uint32, error) {
+	pubKeyBytes, err := x509.MarshalPKIXPublicKey(pk)
+	if err != nil {
+		return 0, errors.Wrap(err, "marshalling public key")
+	}
+	pkSha := sha256.Sum256(pubKeyBytes)
+	return binary.BigEndian.Uint32(pkSha[:]), nil
+}
+
+func verifyNoteSignature(verifier signature.Verifier, pk crypto.PublicKey, msg, sig []byte) bool {
+	opts := []signature.VerifyOption{}
+	switch pk.(type) {
+	case *rsa.PublicKey, *ecdsa.PublicKey:
+		digest := sha256.Sum256(msg)
+		opts = append(opts, options.WithDigest(digest[:]))
+	case ed25519.PublicKey:
+	default:
+		return false
 	}
-	return true
+	return verifier.VerifySignature(bytes.NewReader(sig), bytes.NewReader(msg), opts...) == nil
+}
+
+// noteVerifier verifies the signatures of a key on signed notes
+type noteVerifier struct {
+	name     string
+	hash     uint32
+	pk       crypto.PublicKey
+	verifier signature.Verifier
+}
+
+// NewNoteVerifier returns a verifier of the signatures made by the log named name, i.e. its
+// rekor_server.hostname, or <hostname>/logs/<name> for the additional logs it hosts, for use with
+// note.Open
+func NewNoteVerifier(name string, verifier signature.Verifier) (note.Verifier, error) {
+	pk, err := verifier.PublicKey()
+	if err != nil {
+		return nil, errors.Wrap(err, "retrieving public key")
+	}
+	hash, err := noteKeyHash(pk)
+	if err != nil {
+		return nil, err
+	}
+	return &noteVerifier{name: name, hash: hash, pk: pk, verifier: verifier}, nil
+}
+
+func (v *noteVerifier) Name() string    { return v.name }
+func (v *noteVerifier) KeyHash() uint32 { return v.hash }
+func (v *noteVerifier) Verify(msg, sig []byte) bool {
+	return verifyNoteSignature(v.verifier, v.pk, msg, sig)
 }
 
 // MarshalText returns the common format representation of this SignedNote.
@@ -0,0 +1,97 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+)
+
+// NormalizeGitCommit returns the lower case hex of a SHA1 or SHA256 git commit ID
+func NormalizeGitCommit(v string) (string, error) {
+	commit := strings.ToLower(v)
+	if _, err := hex.DecodeString(commit); err != nil || (len(commit) != 40 && len(commit) != 64) {
+		return "", fmt.Errorf("invalid commit %q: expected 40 or 64 hexadecimal characters", v)
+	}
+	return commit, nil
+}
+
+// defaultPorts are dropped from normalized URLs
+var defaultPorts = map[string]string{
+	"http":  "80",
+	"https": "443",
+	"ssh":   "22",
+	"git":   "9418",
+}
+
+// NormalizeRepositoryURL returns the URL of a source repository in the form it is indexed by, so that
+// the different ways of referring to a repository match: the git+ prefix and @revision suffix of SPDX
+// and SLSA locators, user info, default ports, trailing slashes and the .git suffix are removed, and
+// the scheme and host are lower cased. git+https://github.com/Org/Repo.git@refs/heads/main becomes
+// https://github.com/Org/Repo.
+func NormalizeRepositoryURL(v string) (string, error) {
+	u, err := url.Parse(strings.TrimPrefix(v, "git+"))
+	if err != nil {
+		return "", fmt.Errorf("invalid repository URL %q: %w", v, err)
+	}
+	if !u.IsAbs() || u.Host == "" {
+		return "", fmt.Errorf("invalid repository URL %q: expected an absolute URL", v)
+	}
+	scheme := strings.ToLower(u.Scheme)
+	host := normalizeHost(scheme, u)
+	path := u.Path
+	if i := strings.Index(path, "@"); i >= 0 {
+		path = path[:i]
+	}
+	path = strings.TrimSuffix(strings.TrimRight(path, "/"), ".git")
+	if path == "" {
+		return "", fmt.Errorf("invalid repository URL %q: no repository path", v)
+	}
+	return (&url.URL{Scheme: scheme, Host: host, Path: path}).String(), nil
+}
+
+// NormalizeArtifactURL returns the URL an artifact was downloaded from in the form it is indexed by:
+// the scheme and host are lower cased, and user info, default ports and the fragment are removed. The
+// path and query are kept as they are, as servers may tell them apart by case.
+func NormalizeArtifactURL(v string) (string, error) {
+	u, err := url.Parse(v)
+	if err != nil {
+		return "", fmt.Errorf("invalid artifact URL %q: %w", v, err)
+	}
+	scheme := strings.ToLower(u.Scheme)
+	if (scheme != "http" && scheme != "https") || u.Host == "" {
+		return "", fmt.Errorf("invalid artifact URL %q: expected an absolute http or https URL", v)
+	}
+	path, rawPath := u.Path, u.RawPath
+	if path == "" {
+		path = "/"
+	}
+	return (&url.URL{Scheme: scheme, Host: normalizeHost(scheme, u), Path: path, RawPath: rawPath, RawQuery: u.RawQuery}).String(), nil
+}
+
+// normalizeHost returns the lower cased host of a URL, with its port unless it is the default one of scheme
+func normalizeHost(scheme string, u *url.URL) string {
+	host := strings.ToLower(u.Hostname())
+	if port := u.Port(); port != "" && port != defaultPorts[scheme] {
+		return net.JoinHostPort(host, port)
+	} else if strings.Contains(host, ":") {
+		return "[" + host + "]"
+	}
+	return host
+}
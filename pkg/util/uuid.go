@@ -0,0 +1,59 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/google/trillian/merkle/rfc6962"
+)
+
+// EntryUUIDLength is the length of a hex-encoded entry UUID
+const EntryUUIDLength = 2 * sha256.Size
+
+// EntryUUID returns the UUID of a log entry, which is defined as the hex-encoded RFC 6962
+// Merkle leaf hash of the canonicalized entry body stored in the log
+func EntryUUID(leaf []byte) string {
+	return hex.EncodeToString(rfc6962.DefaultHasher.HashLeaf(leaf))
+}
+
+// EntryUUIDFromLeafHash returns the UUID for a Merkle leaf hash as returned by Trillian
+func EntryUUIDFromLeafHash(leafHash []byte) string {
+	return hex.EncodeToString(leafHash)
+}
+
+// LeafHashFromEntryUUID validates an entry UUID and returns the Merkle leaf hash it represents
+func LeafHashFromEntryUUID(uuid string) ([]byte, error) {
+	if len(uuid) != EntryUUIDLength {
+		return nil, fmt.Errorf("invalid entry UUID %q: expected %d hexadecimal characters", uuid, EntryUUIDLength)
+	}
+	leafHash, err := hex.DecodeString(uuid)
+	if err != nil {
+		return nil, fmt.Errorf("invalid entry UUID %q: %w", uuid, err)
+	}
+	return leafHash, nil
+}
+
+// NormalizeEntryUUID returns the canonical (lowercase) form of an entry UUID
+func NormalizeEntryUUID(uuid string) (string, error) {
+	if _, err := LeafHashFromEntryUUID(uuid); err != nil {
+		return "", err
+	}
+	return strings.ToLower(uuid), nil
+}
@@ -0,0 +1,67 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+func TestEntryUUID(t *testing.T) {
+	leaf := []byte(`{"apiVersion":"0.0.1","kind":"rekord","spec":{}}`)
+
+	// RFC 6962 leaf hash: SHA256(0x00 || leaf)
+	want := sha256.Sum256(append([]byte{0}, leaf...))
+	got := EntryUUID(leaf)
+	if got != hex.EncodeToString(want[:]) {
+		t.Fatalf("EntryUUID() = %v, want %v", got, hex.EncodeToString(want[:]))
+	}
+	if EntryUUIDFromLeafHash(want[:]) != got {
+		t.Errorf("EntryUUIDFromLeafHash() did not match EntryUUID()")
+	}
+
+	leafHash, err := LeafHashFromEntryUUID(got)
+	if err != nil {
+		t.Fatalf("LeafHashFromEntryUUID() unexpected error: %v", err)
+	}
+	if !bytes.Equal(leafHash, want[:]) {
+		t.Errorf("LeafHashFromEntryUUID() = %x, want %x", leafHash, want)
+	}
+
+	normalized, err := NormalizeEntryUUID(strings.ToUpper(got))
+	if err != nil {
+		t.Fatalf("NormalizeEntryUUID() unexpected error: %v", err)
+	}
+	if normalized != got {
+		t.Errorf("NormalizeEntryUUID() = %v, want %v", normalized, got)
+	}
+}
+
+func TestLeafHashFromEntryUUIDInvalid(t *testing.T) {
+	for _, uuid := range []string{
+		"",
+		"abcd",
+		strings.Repeat("z", EntryUUIDLength),
+		strings.Repeat("a", EntryUUIDLength+2),
+	} {
+		if _, err := LeafHashFromEntryUUID(uuid); err == nil {
+			t.Errorf("LeafHashFromEntryUUID(%q) expected error", uuid)
+		}
+	}
+}
@@ -0,0 +1,235 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verify
+
+import (
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-openapi/swag"
+	"github.com/sigstore/sigstore/pkg/signature"
+
+	"github.com/sigstore/rekor/pkg/bundle"
+	"github.com/sigstore/rekor/pkg/generated/models"
+	"github.com/sigstore/rekor/pkg/util"
+)
+
+// KeySetPath is where a log publishes the keys it signs with, as LogKeys
+const KeySetPath = "/api/v1/log/publicKeys"
+
+// LogKeys lists the current and previous keys of a log along with when each of them was in use
+type LogKeys struct {
+	Keys []LogKey `json:"keys"`
+}
+
+// LogKey is a key a log signs, or signed, checkpoints and signed entry timestamps with
+type LogKey struct {
+	// PEM encoded public key
+	PublicKey string `json:"publicKey"`
+	// hex encoded SHA256 hash of the DER encoding of the key, as found in the logID of entries
+	LogID string `json:"logID"`
+	// the key was not in use before ValidFrom, if set, nor after ValidUntil, if set. These are not a
+	// revocation mechanism: they are compared with times chosen by whoever signs, so that anyone holding
+	// a retired key can still sign for times within its validity window.
+	ValidFrom  *time.Time `json:"validFrom,omitempty"`
+	ValidUntil *time.Time `json:"validUntil,omitempty"`
+	// the key is not trusted to have made any signature, whenever it claims to have been made, e.g. as it
+	// was compromised
+	Revoked bool `json:"revoked,omitempty"`
+}
+
+// KeySet verifies signatures made by a log with any of the keys it signed with while they were in use,
+// so that evidence signed before a key was rotated keeps verifying, while a retired key can not be used
+// to sign for entries or tree heads after it was retired. Revoked keys verify nothing.
+type KeySet struct {
+	keys []keySetKey
+}
+
+type keySetKey struct {
+	logID      string
+	verifier   signature.Verifier
+	validFrom  time.Time // zero if unbounded
+	validUntil time.Time // zero if unbounded
+	revoked    bool
+}
+
+// validAt reports whether the key was in use at t
+func (k keySetKey) validAt(t time.Time) bool {
+	return !k.revoked && (k.validFrom.IsZero() || !t.Before(k.validFrom)) && (k.validUntil.IsZero() || !t.After(k.validUntil))
+}
+
+// NewKeySet loads the keys listed by a log, checking that each of them has the log ID it is listed with
+func NewKeySet(lk LogKeys) (*KeySet, error) {
+	if len(lk.Keys) == 0 {
+		return nil, errors.New("the key set of the log is empty")
+	}
+	ks := &KeySet{}
+	for _, k := range lk.Keys {
+		verifier, err := LoadLogVerifier([]byte(k.PublicKey))
+		if err != nil {
+			return nil, err
+		}
+		logID, err := LogID(verifier)
+		if err != nil {
+			return nil, err
+		}
+		if k.LogID != "" && !strings.EqualFold(k.LogID, logID) {
+			return nil, fmt.Errorf("key listed with log ID %v has log ID %v", k.LogID, logID)
+		}
+		key := keySetKey{logID: logID, verifier: verifier, revoked: k.Revoked}
+		if k.ValidFrom != nil {
+			key.validFrom = *k.ValidFrom
+		}
+		if k.ValidUntil != nil {
+			key.validUntil = *k.ValidUntil
+		}
+		if !key.validFrom.IsZero() && !key.validUntil.IsZero() && key.validUntil.Before(key.validFrom) {
+			return nil, fmt.Errorf("key %v is valid until %v, before it is valid from %v", logID, key.validUntil, key.validFrom)
+		}
+		ks.keys = append(ks.keys, key)
+	}
+	return ks, nil
+}
+
+// KeySetFromPEM returns the key set holding the PEM encoded public keys in publicKeysPEM, such as
+// the keys of a log pinned by a client, without restricting when each of them may have been used
+func KeySetFromPEM(publicKeysPEM []byte) (*KeySet, error) {
+	lk := LogKeys{}
+	for rest := publicKeysPEM; ; {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		lk.Keys = append(lk.Keys, LogKey{PublicKey: string(pem.EncodeToMemory(block))})
+	}
+	if len(lk.Keys) == 0 {
+		return nil, errors.New("no PEM encoded public key of the log found")
+	}
+	return NewKeySet(lk)
+}
+
+// Verifier returns the verifier of the key with the given log ID, unless the key was revoked
+func (ks *KeySet) Verifier(logID string) (signature.Verifier, error) {
+	for _, k := range ks.keys {
+		if strings.EqualFold(k.logID, logID) {
+			if k.revoked {
+				return nil, fmt.Errorf("key %v of the log has been revoked", logID)
+			}
+			return k.verifier, nil
+		}
+	}
+	return nil, fmt.Errorf("the log has no key with log ID %v", logID)
+}
+
+// entryKey returns the key an entry claims to be signed with, checking that the key was not retired
+// before the entry was integrated. Signed entry timestamps are made when an entry is served, so entries
+// integrated before a key was in use may carry its signature.
+func (ks *KeySet) entryKey(e models.LogEntryAnon) (keySetKey, error) {
+	if e.LogID == nil {
+		return keySetKey{}, errors.New("entry has no log ID")
+	}
+	for _, k := range ks.keys {
+		if !strings.EqualFold(k.logID, *e.LogID) {
+			continue
+		}
+		if k.revoked {
+			return keySetKey{}, fmt.Errorf("entry was signed with key %v, which has been revoked", k.logID)
+		}
+		integrated := time.Unix(swag.Int64Value(e.IntegratedTime), 0)
+		if !k.validUntil.IsZero() && integrated.After(k.validUntil) {
+			return keySetKey{}, fmt.Errorf("entry was integrated at %v, after key %v was retired at %v", integrated.UTC(), k.logID, k.validUntil.UTC())
+		}
+		return k, nil
+	}
+	return keySetKey{}, fmt.Errorf("entry was logged by %v, which is not a key of the log", *e.LogID)
+}
+
+// SignedEntryTimestamp checks the signed entry timestamp of an entry against the key of the set
+// identified by its log ID
+func (ks *KeySet) SignedEntryTimestamp(e models.LogEntryAnon) error {
+	k, err := ks.entryKey(e)
+	if err != nil {
+		return err
+	}
+	return SignedEntryTimestamp(k.verifier, e)
+}
+
+// Entry is like Entry, checking the entry against the key of the set identified by its log ID
+func (ks *KeySet) Entry(uuid string, e models.LogEntryAnon) ([]byte, error) {
+	if e.LogIndex == nil || e.IntegratedTime == nil || e.LogID == nil || e.Body == nil {
+		return nil, fmt.Errorf("entry %v is incomplete", uuid)
+	}
+	k, err := ks.entryKey(e)
+	if err != nil {
+		return nil, err
+	}
+	return Entry(k.verifier, uuid, e)
+}
+
+// Checkpoint is like Checkpoint, accepting a checkpoint signed by any key of the set that was in use
+// at the time of the checkpoint, or now if it has no timestamp. It also returns the verifier of the
+// key whose signature was checked.
+func (ks *KeySet) Checkpoint(signedCheckpoint string) (*util.SignedCheckpoint, signature.Verifier, error) {
+	sth := &util.SignedCheckpoint{}
+	if err := sth.UnmarshalText([]byte(signedCheckpoint)); err != nil {
+		return nil, nil, err
+	}
+	at := time.Now()
+	if ts := sth.GetTimestamp(); ts != 0 {
+		at = time.Unix(0, int64(ts))
+	}
+	for _, k := range ks.keys {
+		if k.validAt(at) && sth.Verify(k.verifier) {
+			return sth, k.verifier, nil
+		}
+	}
+	return nil, nil, fmt.Errorf("tree head of %v is not signed by a key of the log in use at that time", at.UTC())
+}
+
+// HistoricalProof is like the package-level HistoricalProof, but accepts a tree head signed by any
+// key in the set, as Checkpoint does
+func (ks *KeySet) HistoricalProof(body []byte, proof *models.HistoricalProof) (*util.SignedCheckpoint, error) {
+	if proof == nil || proof.LogInfo == nil {
+		return nil, errors.New("historical proof incomplete")
+	}
+	sth, _, err := ks.Checkpoint(swag.StringValue(proof.LogInfo.SignedTreeHead))
+	if err != nil {
+		return nil, err
+	}
+	return sth, historicalProof(body, proof, sth)
+}
+
+// Bundle is like Bundle, accepting an entry made by the log with any key of the set which was neither
+// revoked nor retired before the entry was integrated
+func (ks *KeySet) Bundle(b *bundle.Bundle, artifactDigest []byte) (*bundle.TransparencyLogEntry, error) {
+	var errs []string
+	for _, k := range ks.keys {
+		tle, err := Bundle(k.verifier, b, artifactDigest)
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		if _, err := ks.entryKey(tle.LogEntry()); err != nil {
+			return nil, err
+		}
+		return tle, nil
+	}
+	return nil, errors.New(strings.Join(errs, "; "))
+}
@@ -0,0 +1,154 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verify
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+	"time"
+
+	"github.com/go-openapi/swag"
+	"github.com/sigstore/sigstore/pkg/signature"
+	"github.com/sigstore/sigstore/pkg/signature/options"
+
+	"github.com/sigstore/rekor/pkg/util"
+)
+
+// testLogKey creates a key of a log and returns its signer and its listing in the key set of the log
+func testLogKey(t *testing.T, validFrom, validUntil *time.Time) (signature.Signer, LogKey) {
+	t.Helper()
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	signer, _ := signature.LoadSigner(key, crypto.SHA256)
+	der, err := x509.MarshalPKIXPublicKey(key.Public())
+	if err != nil {
+		t.Fatal(err)
+	}
+	verifier, _ := signature.LoadVerifier(key.Public(), crypto.SHA256)
+	logID, err := LogID(verifier)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signer, LogKey{
+		PublicKey:  string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})),
+		LogID:      logID,
+		ValidFrom:  validFrom,
+		ValidUntil: validUntil,
+	}
+}
+
+func signedCheckpoint(t *testing.T, at time.Time, signers ...signature.Signer) string {
+	t.Helper()
+	sth, err := util.CreateSignedCheckpoint(util.Checkpoint{Origin: "Rekor", Size: 2, Hash: make([]byte, 32)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sth.SetTimestamp(uint64(at.UnixNano()))
+	for _, s := range signers {
+		if _, err := sth.Sign("rekor.example.com", s, options.WithContext(context.Background())); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return sth.SignedNote.String()
+}
+
+func TestKeySet(t *testing.T) {
+	rotated := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	retired := rotated.Add(30 * 24 * time.Hour)
+	oldSigner, oldKey := testLogKey(t, nil, &retired)
+	newSigner, newKey := testLogKey(t, &rotated, nil)
+
+	ks, err := NewKeySet(LogKeys{Keys: []LogKey{newKey, oldKey}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// clients which have not learned of the new key yet only know the old one
+	oldOnly, err := KeySetFromPEM([]byte(oldKey.PublicKey))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	overlap := rotated.Add(time.Hour)
+	if _, _, err := ks.Checkpoint(signedCheckpoint(t, overlap, newSigner, oldSigner)); err != nil {
+		t.Errorf("checkpoint signed during the overlap: %v", err)
+	}
+	if _, _, err := oldOnly.Checkpoint(signedCheckpoint(t, overlap, newSigner, oldSigner)); err != nil {
+		t.Errorf("checkpoint signed during the overlap with a pinned previous key: %v", err)
+	}
+	if _, _, err := ks.Checkpoint(signedCheckpoint(t, retired.Add(time.Hour), oldSigner)); err == nil {
+		t.Error("expected error verifying a checkpoint signed with a key after it was retired")
+	}
+	if _, _, err := ks.Checkpoint(signedCheckpoint(t, rotated.Add(-time.Hour), newSigner)); err == nil {
+		t.Error("expected error verifying a checkpoint signed with a key before it was in use")
+	}
+	_, verifier, err := ks.Checkpoint(signedCheckpoint(t, retired.Add(time.Hour), newSigner))
+	if err != nil {
+		t.Fatalf("checkpoint signed with the new key: %v", err)
+	}
+	if logID, _ := LogID(verifier); logID != newKey.LogID {
+		t.Errorf("checkpoint verified by %v, want %v", logID, newKey.LogID)
+	}
+
+	body := []byte(`{"kind":"test"}`)
+	uuid, e := logEntry(t, oldSigner, oldKey.LogID, body, []byte("other"))
+	if _, err := ks.Entry(uuid, e); err != nil {
+		t.Errorf("entry signed with the previous key: %v", err)
+	}
+	late := e
+	late.IntegratedTime = swag.Int64(retired.Add(time.Hour).Unix())
+	if err := ks.SignedEntryTimestamp(late); err == nil {
+		t.Error("expected error verifying an entry integrated after its key was retired")
+	}
+	unknown := e
+	unknown.LogID = swag.String(newKey.LogID[:60] + "0000")
+	if err := ks.SignedEntryTimestamp(unknown); err == nil {
+		t.Error("expected error verifying an entry of an unknown key")
+	}
+
+	// a revoked key verifies nothing, even signatures claiming to be made while it was in use
+	revokedKey := oldKey
+	revokedKey.Revoked = true
+	revoked, err := NewKeySet(LogKeys{Keys: []LogKey{newKey, revokedKey}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := revoked.Checkpoint(signedCheckpoint(t, rotated.Add(-time.Hour), oldSigner)); err == nil {
+		t.Error("expected error verifying a checkpoint signed with a revoked key")
+	}
+	if _, err := revoked.Entry(uuid, e); err == nil {
+		t.Error("expected error verifying an entry signed with a revoked key")
+	}
+	if _, err := revoked.Verifier(oldKey.LogID); err == nil {
+		t.Error("expected error getting the verifier of a revoked key")
+	}
+	if _, _, err := revoked.Checkpoint(signedCheckpoint(t, overlap, newSigner, oldSigner)); err != nil {
+		t.Errorf("checkpoint co-signed with a revoked key: %v", err)
+	}
+
+	mislabeled := oldKey
+	mislabeled.LogID = newKey.LogID
+	if _, err := NewKeySet(LogKeys{Keys: []LogKey{mislabeled}}); err == nil {
+		t.Error("expected error loading a key listed with the log ID of another key")
+	}
+	if _, err := KeySetFromPEM([]byte("not a key")); err == nil {
+		t.Error("expected error loading a key set without keys")
+	}
+}
@@ -0,0 +1,316 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package verify checks the evidence returned by a Rekor log for its entries and tree heads. It makes
+// no requests of its own, so that tools can verify entries, checkpoints and bundles they obtained
+// earlier, or from elsewhere, given only the public key of the log.
+package verify
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/cyberphone/json-canonicalization/go/src/webpki.org/jsoncanonicalizer"
+	"github.com/go-openapi/runtime"
+	"github.com/go-openapi/swag"
+	"github.com/google/trillian/merkle/logverifier"
+	"github.com/google/trillian/merkle/rfc6962"
+	"github.com/sigstore/sigstore/pkg/cryptoutils"
+	"github.com/sigstore/sigstore/pkg/signature"
+
+	"github.com/sigstore/rekor/pkg/bundle"
+	"github.com/sigstore/rekor/pkg/generated/models"
+	"github.com/sigstore/rekor/pkg/types"
+	"github.com/sigstore/rekor/pkg/util"
+)
+
+// LoadLogVerifier returns a verifier for signatures made by a log, given its PEM encoded public key as
+// served from /api/v1/log/publicKey
+func LoadLogVerifier(publicKeyPEM []byte) (signature.Verifier, error) {
+	pub, err := cryptoutils.UnmarshalPEMToPublicKey(publicKeyPEM)
+	if err != nil {
+		return nil, fmt.Errorf("parsing public key of the log: %w", err)
+	}
+	return signature.LoadVerifier(pub, crypto.SHA256)
+}
+
+// LogID returns the ID of the log signing with the verifier's key, the hex encoded SHA256 hash of
+// the DER encoding of the key
+func LogID(verifier signature.Verifier) (string, error) {
+	pub, err := verifier.PublicKey()
+	if err != nil {
+		return "", err
+	}
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return "", err
+	}
+	h := sha256.Sum256(der)
+	return hex.EncodeToString(h[:]), nil
+}
+
+// SignedEntryTimestamp checks the log's signature over the canonicalized entry, which promises that
+// the entry was added to the log at its integrated time
+func SignedEntryTimestamp(verifier signature.Verifier, e models.LogEntryAnon) error {
+	if e.Verification == nil || len(e.Verification.SignedEntryTimestamp) == 0 {
+		return errors.New("signed entry timestamp missing")
+	}
+	payload, err := (&models.LogEntryAnon{
+		IntegratedTime: e.IntegratedTime,
+		LogIndex:       e.LogIndex,
+		Body:           e.Body,
+		LogID:          e.LogID,
+	}).MarshalBinary()
+	if err != nil {
+		return err
+	}
+	canonicalized, err := jsoncanonicalizer.Transform(payload)
+	if err != nil {
+		return err
+	}
+	if err := verifier.VerifySignature(bytes.NewReader(e.Verification.SignedEntryTimestamp), bytes.NewReader(canonicalized)); err != nil {
+		return fmt.Errorf("signed entry timestamp did not verify: %w", err)
+	}
+	return nil
+}
+
+// Body decodes the body of an entry and checks that uuid is its leaf hash
+func Body(uuid string, e models.LogEntryAnon) ([]byte, error) {
+	bodyStr, ok := e.Body.(string)
+	if !ok {
+		return nil, errors.New("entry body is not a base64 encoded string")
+	}
+	body, err := base64.StdEncoding.DecodeString(bodyStr)
+	if err != nil {
+		return nil, fmt.Errorf("decoding entry body: %w", err)
+	}
+	normalized, err := util.NormalizeEntryUUID(uuid)
+	if err != nil {
+		return nil, err
+	}
+	if computed := util.EntryUUID(body); computed != normalized {
+		return nil, fmt.Errorf("computed leaf hash %v did not match entry UUID %v", computed, uuid)
+	}
+	return body, nil
+}
+
+// InclusionProof checks that the entry body is included in the tree whose root hash is given by the proof
+func InclusionProof(body []byte, proof *models.InclusionProof) error {
+	if proof == nil {
+		return errors.New("inclusion proof missing")
+	}
+	hashes, err := decodeHashes(proof.Hashes)
+	if err != nil {
+		return fmt.Errorf("invalid hash in inclusion proof: %w", err)
+	}
+	rootHash, err := hex.DecodeString(swag.StringValue(proof.RootHash))
+	if err != nil {
+		return fmt.Errorf("invalid root hash in inclusion proof: %w", err)
+	}
+	v := logverifier.New(rfc6962.DefaultHasher)
+	if err := v.VerifyInclusionProof(swag.Int64Value(proof.LogIndex), swag.Int64Value(proof.TreeSize), hashes, rootHash, rfc6962.DefaultHasher.HashLeaf(body)); err != nil {
+		return fmt.Errorf("verifying inclusion proof: %w", err)
+	}
+	return nil
+}
+
+// Entry checks an entry as returned by the log: that it was logged by the log signing with the
+// verifier's key, its signed entry timestamp, its UUID and, if present, its inclusion proof. It returns
+// the body of the entry.
+func Entry(verifier signature.Verifier, uuid string, e models.LogEntryAnon) ([]byte, error) {
+	if e.LogIndex == nil || e.IntegratedTime == nil || e.LogID == nil || e.Body == nil {
+		return nil, fmt.Errorf("entry %v is incomplete", uuid)
+	}
+	logID, err := LogID(verifier)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.EqualFold(*e.LogID, logID) {
+		return nil, fmt.Errorf("entry was logged by %v, not by the log %v", *e.LogID, logID)
+	}
+	if err := SignedEntryTimestamp(verifier, e); err != nil {
+		return nil, err
+	}
+	body, err := Body(uuid, e)
+	if err != nil {
+		return nil, err
+	}
+	if e.Verification.InclusionProof != nil {
+		if err := InclusionProof(body, e.Verification.InclusionProof); err != nil {
+			return nil, err
+		}
+	}
+	return body, nil
+}
+
+// Checkpoint parses a signed checkpoint, as served in the signedTreeHead of /api/v1/log, and checks
+// the log's signature on it
+func Checkpoint(verifier signature.Verifier, signedCheckpoint string) (*util.SignedCheckpoint, error) {
+	sth := &util.SignedCheckpoint{}
+	if err := sth.UnmarshalText([]byte(signedCheckpoint)); err != nil {
+		return nil, err
+	}
+	if !sth.Verify(verifier) {
+		return nil, errors.New("signature on tree head did not verify")
+	}
+	return sth, nil
+}
+
+// ConsistencyProof checks that the tree of size newSize with root hash newHash extends the tree of
+// size oldSize with root hash oldHash, given the hex encoded hashes of a consistency proof between them,
+// which are not needed if either tree is empty or the sizes are equal
+func ConsistencyProof(oldSize uint64, oldHash []byte, newSize uint64, newHash []byte, proof []string) error {
+	switch {
+	case oldSize == newSize:
+		if !bytes.Equal(oldHash, newHash) {
+			return fmt.Errorf("root hashes differ for the same tree size %d", oldSize)
+		}
+		return nil
+	case oldSize > newSize:
+		return fmt.Errorf("tree size %d is less than previously observed tree size %d", newSize, oldSize)
+	case oldSize == 0:
+		// every tree is consistent with the empty tree
+		return nil
+	}
+	hashes, err := decodeHashes(proof)
+	if err != nil {
+		return fmt.Errorf("invalid hash in consistency proof: %w", err)
+	}
+	v := logverifier.New(rfc6962.DefaultHasher)
+	return v.VerifyConsistencyProof(int64(oldSize), int64(newSize), oldHash, newHash, hashes)
+}
+
+// HistoricalProof checks a proof served by /api/v1/log/entries/{entryUUID}/proof: that the entry body is
+// included in the tree of the size requested and that the tree of the signed tree head, signed by the
+// verifier's key, is consistent with that tree. It returns the signed tree head.
+func HistoricalProof(verifier signature.Verifier, body []byte, proof *models.HistoricalProof) (*util.SignedCheckpoint, error) {
+	if proof == nil || proof.LogInfo == nil {
+		return nil, errors.New("historical proof incomplete")
+	}
+	sth, err := Checkpoint(verifier, swag.StringValue(proof.LogInfo.SignedTreeHead))
+	if err != nil {
+		return nil, err
+	}
+	return sth, historicalProof(body, proof, sth)
+}
+
+// historicalProof checks the proofs of a historical proof against a signed tree head whose signature was checked
+func historicalProof(body []byte, proof *models.HistoricalProof, sth *util.SignedCheckpoint) error {
+	if proof.InclusionProof == nil || proof.ConsistencyProof == nil {
+		return errors.New("historical proof incomplete")
+	}
+	if err := InclusionProof(body, proof.InclusionProof); err != nil {
+		return err
+	}
+	if !strings.EqualFold(swag.StringValue(proof.ConsistencyProof.RootHash), hex.EncodeToString(sth.Hash)) {
+		return errors.New("consistency proof is not for the root hash of the signed tree head")
+	}
+	// the root hash was decoded by InclusionProof
+	oldHash, _ := hex.DecodeString(swag.StringValue(proof.InclusionProof.RootHash))
+	oldSize := uint64(swag.Int64Value(proof.InclusionProof.TreeSize))
+	if err := ConsistencyProof(oldSize, oldHash, sth.Size, sth.Hash, proof.ConsistencyProof.Hashes); err != nil {
+		return fmt.Errorf("verifying consistency proof: %w", err)
+	}
+	return nil
+}
+
+// CanonicalBody canonicalizes a proposed entry as the log does before adding it, so that the result can
+// be compared with the body of an entry in the log. The types of entries to be canonicalized must be
+// registered by importing their packages. Entries which refer to external content by URL are fetched
+// by their type, so such entries should carry their content inline to be canonicalized offline.
+func CanonicalBody(ctx context.Context, pe models.ProposedEntry) ([]byte, error) {
+	entry, err := types.NewEntry(pe)
+	if err != nil {
+		return nil, err
+	}
+	return types.CanonicalizeEntry(ctx, entry, pe.Annotations())
+}
+
+// ParseBody parses the body of an entry and checks it is valid for its type, which must be registered
+// by importing its package
+func ParseBody(body []byte) (types.EntryImpl, error) {
+	pe, err := models.UnmarshalProposedEntry(bytes.NewReader(body), runtime.JSONConsumer())
+	if err != nil {
+		return nil, fmt.Errorf("parsing entry body: %w", err)
+	}
+	entry, err := types.NewEntry(pe)
+	if err != nil {
+		return nil, fmt.Errorf("validating entry body: %w", err)
+	}
+	return entry, nil
+}
+
+// Bundle checks the entry of a Sigstore bundle logged by the log signing with the verifier's key and
+// that the bundle's content matches artifactDigest, the SHA256 digest of the artifact, if it is given.
+// The entry must carry its signed entry timestamp: an inclusion proof alone is checked against the root
+// hash it supplies itself, which the log has not signed. It returns the verified entry.
+func Bundle(verifier signature.Verifier, b *bundle.Bundle, artifactDigest []byte) (*bundle.TransparencyLogEntry, error) {
+	logID, err := LogID(verifier)
+	if err != nil {
+		return nil, err
+	}
+	keyID, err := hex.DecodeString(logID)
+	if err != nil {
+		return nil, err
+	}
+
+	var verified *bundle.TransparencyLogEntry
+	for i := range b.VerificationMaterial.TlogEntries {
+		tle := b.VerificationMaterial.TlogEntries[i]
+		if !bytes.Equal(tle.LogID.KeyID, keyID) {
+			continue
+		}
+		if tle.InclusionPromise == nil {
+			return nil, errors.New("bundle entry has no signed entry timestamp")
+		}
+		logEntry := tle.LogEntry()
+		if err := SignedEntryTimestamp(verifier, logEntry); err != nil {
+			return nil, err
+		}
+		if tle.InclusionProof != nil {
+			if err := InclusionProof(tle.CanonicalizedBody, logEntry.Verification.InclusionProof); err != nil {
+				return nil, err
+			}
+		}
+		verified = &tle
+	}
+	if verified == nil {
+		return nil, fmt.Errorf("bundle has no entry made by the log with ID %v", logID)
+	}
+	if err := b.VerifyContent(artifactDigest); err != nil {
+		return nil, err
+	}
+	return verified, nil
+}
+
+func decodeHashes(hexHashes []string) ([][]byte, error) {
+	hashes := make([][]byte, 0, len(hexHashes))
+	for _, h := range hexHashes {
+		b, err := hex.DecodeString(h)
+		if err != nil {
+			return nil, err
+		}
+		hashes = append(hashes, b)
+	}
+	return hashes, nil
+}
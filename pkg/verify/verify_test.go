@@ -0,0 +1,380 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verify
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cyberphone/json-canonicalization/go/src/webpki.org/jsoncanonicalizer"
+	"github.com/go-openapi/swag"
+	"github.com/google/trillian/merkle/rfc6962"
+	"github.com/sigstore/sigstore/pkg/signature"
+	"github.com/sigstore/sigstore/pkg/signature/options"
+
+	"github.com/sigstore/rekor/pkg/bundle"
+	"github.com/sigstore/rekor/pkg/generated/models"
+	"github.com/sigstore/rekor/pkg/util"
+)
+
+// logEntry creates the entry at index 0 of a log of two entries with the given bodies, as it would be
+// served by a log signing with signer
+func logEntry(t *testing.T, signer signature.Signer, logID string, body, other []byte) (string, models.LogEntryAnon) {
+	t.Helper()
+	e := models.LogEntryAnon{
+		LogID:          swag.String(logID),
+		LogIndex:       swag.Int64(0),
+		IntegratedTime: swag.Int64(1600000000),
+		Body:           base64.StdEncoding.EncodeToString(body),
+	}
+	payload, err := e.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	canonicalized, err := jsoncanonicalizer.Transform(payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := signer.SignMessage(bytes.NewReader(canonicalized))
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherHash := rfc6962.DefaultHasher.HashLeaf(other)
+	root := rfc6962.DefaultHasher.HashChildren(rfc6962.DefaultHasher.HashLeaf(body), otherHash)
+	e.Verification = &models.LogEntryAnonVerification{
+		SignedEntryTimestamp: sig,
+		InclusionProof: &models.InclusionProof{
+			LogIndex: swag.Int64(0),
+			TreeSize: swag.Int64(2),
+			RootHash: swag.String(hex.EncodeToString(root)),
+			Hashes:   []string{hex.EncodeToString(otherHash)},
+		},
+	}
+	return util.EntryUUID(body), e
+}
+
+func TestEntry(t *testing.T) {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	signer, _ := signature.LoadSigner(key, crypto.SHA256)
+	der, _ := x509.MarshalPKIXPublicKey(key.Public())
+	verifier, err := LoadLogVerifier(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	logID, err := LogID(verifier)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	body := []byte(`{"entry":0}`)
+	uuid, e := logEntry(t, signer, logID, body, []byte(`{"entry":1}`))
+	got, err := Entry(verifier, uuid, e)
+	if err != nil {
+		t.Fatalf("unexpected error verifying entry: %v", err)
+	}
+	if !bytes.Equal(got, body) {
+		t.Errorf("Entry returned body %q, want %q", got, body)
+	}
+
+	otherKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	otherSigner, _ := signature.LoadSigner(otherKey, crypto.SHA256)
+	tests := []struct {
+		desc  string
+		entry func() (string, models.LogEntryAnon)
+	}{
+		{
+			desc: "signed by another log",
+			entry: func() (string, models.LogEntryAnon) {
+				return logEntry(t, otherSigner, logID, body, nil)
+			},
+		},
+		{
+			desc: "logged by another log",
+			entry: func() (string, models.LogEntryAnon) {
+				return logEntry(t, signer, strings.Repeat("0", 64), body, nil)
+			},
+		},
+		{
+			desc: "UUID of another body",
+			entry: func() (string, models.LogEntryAnon) {
+				_, e := logEntry(t, signer, logID, body, nil)
+				return util.EntryUUID([]byte("other")), e
+			},
+		},
+		{
+			desc: "time changed",
+			entry: func() (string, models.LogEntryAnon) {
+				uuid, e := logEntry(t, signer, logID, body, nil)
+				e.IntegratedTime = swag.Int64(1700000000)
+				return uuid, e
+			},
+		},
+		{
+			desc: "signed entry timestamp missing",
+			entry: func() (string, models.LogEntryAnon) {
+				uuid, e := logEntry(t, signer, logID, body, nil)
+				e.Verification.SignedEntryTimestamp = nil
+				return uuid, e
+			},
+		},
+		{
+			desc: "inclusion proof for another root",
+			entry: func() (string, models.LogEntryAnon) {
+				uuid, e := logEntry(t, signer, logID, body, nil)
+				e.Verification.InclusionProof.RootHash = swag.String(strings.Repeat("0", 64))
+				return uuid, e
+			},
+		},
+	}
+	for _, tt := range tests {
+		uuid, e := tt.entry()
+		if _, err := Entry(verifier, uuid, e); err == nil {
+			t.Errorf("%v: expected error", tt.desc)
+		}
+	}
+}
+
+func TestCheckpoint(t *testing.T) {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	signer, _ := signature.LoadSigner(key, crypto.SHA256)
+	verifier, _ := signature.LoadVerifier(key.Public(), crypto.SHA256)
+	otherKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	otherVerifier, _ := signature.LoadVerifier(otherKey.Public(), crypto.SHA256)
+
+	sth, err := util.CreateSignedCheckpoint(util.Checkpoint{Origin: "Rekor", Size: 2, Hash: bytes.Repeat([]byte{1}, 32)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sth.SetTimestamp(uint64(time.Now().UnixNano()))
+	if _, err := sth.Sign("rekor.example.com", signer, options.WithContext(context.Background())); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := Checkpoint(verifier, sth.SignedNote.String())
+	if err != nil {
+		t.Fatalf("unexpected error verifying checkpoint: %v", err)
+	}
+	if got.Size != 2 || !bytes.Equal(got.Hash, sth.Hash) {
+		t.Errorf("unexpected checkpoint %+v", got.Checkpoint)
+	}
+	if _, err := Checkpoint(otherVerifier, sth.SignedNote.String()); err == nil {
+		t.Error("expected error verifying checkpoint with the key of another log")
+	}
+	if _, err := Checkpoint(verifier, strings.Replace(sth.SignedNote.String(), "\n2\n", "\n3\n", 1)); err == nil {
+		t.Error("expected error verifying modified checkpoint")
+	}
+}
+
+func TestConsistencyProof(t *testing.T) {
+	h := rfc6962.DefaultHasher
+	leaves := [][]byte{h.HashLeaf([]byte("a")), h.HashLeaf([]byte("b")), h.HashLeaf([]byte("c"))}
+	root2 := h.HashChildren(leaves[0], leaves[1])
+	root3 := h.HashChildren(root2, leaves[2])
+
+	tests := []struct {
+		desc             string
+		oldSize, newSize uint64
+		oldHash, newHash []byte
+		proof            []string
+		wantErr          bool
+	}{
+		{desc: "proof", oldSize: 2, oldHash: root2, newSize: 3, newHash: root3, proof: []string{hex.EncodeToString(leaves[2])}},
+		{desc: "empty tree", oldSize: 0, newSize: 3, newHash: root3},
+		{desc: "same tree", oldSize: 3, oldHash: root3, newSize: 3, newHash: root3},
+		{desc: "same size, different root", oldSize: 3, oldHash: root2, newSize: 3, newHash: root3, wantErr: true},
+		{desc: "shrunk", oldSize: 3, oldHash: root3, newSize: 2, newHash: root2, wantErr: true},
+		{desc: "wrong proof", oldSize: 2, oldHash: root2, newSize: 3, newHash: root3, proof: []string{hex.EncodeToString(leaves[1])}, wantErr: true},
+		{desc: "invalid hash", oldSize: 2, oldHash: root2, newSize: 3, newHash: root3, proof: []string{"zz"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		err := ConsistencyProof(tt.oldSize, tt.oldHash, tt.newSize, tt.newHash, tt.proof)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%v: got error %v, want error %v", tt.desc, err, tt.wantErr)
+		}
+	}
+}
+
+func TestHistoricalProof(t *testing.T) {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	signer, _ := signature.LoadSigner(key, crypto.SHA256)
+	verifier, _ := signature.LoadVerifier(key.Public(), crypto.SHA256)
+	otherKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	otherVerifier, _ := signature.LoadVerifier(otherKey.Public(), crypto.SHA256)
+
+	h := rfc6962.DefaultHasher
+	leaves := [][]byte{h.HashLeaf([]byte("a")), h.HashLeaf([]byte("b")), h.HashLeaf([]byte("c"))}
+	root2 := h.HashChildren(leaves[0], leaves[1])
+	root3 := h.HashChildren(root2, leaves[2])
+
+	sth, err := util.CreateSignedCheckpoint(util.Checkpoint{Origin: "Rekor", Size: 3, Hash: root3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sth.SetTimestamp(uint64(time.Now().UnixNano()))
+	if _, err := sth.Sign("rekor.example.com", signer, options.WithContext(context.Background())); err != nil {
+		t.Fatal(err)
+	}
+
+	// the proof of "a" in the tree of size 2 and of that tree in the tree of size 3
+	proof := func() *models.HistoricalProof {
+		return &models.HistoricalProof{
+			InclusionProof: &models.InclusionProof{
+				LogIndex: swag.Int64(0),
+				TreeSize: swag.Int64(2),
+				RootHash: swag.String(hex.EncodeToString(root2)),
+				Hashes:   []string{hex.EncodeToString(leaves[1])},
+			},
+			ConsistencyProof: &models.ConsistencyProof{
+				RootHash: swag.String(hex.EncodeToString(root3)),
+				Hashes:   []string{hex.EncodeToString(leaves[2])},
+			},
+			LogInfo: &models.LogInfo{
+				RootHash:       swag.String(hex.EncodeToString(root3)),
+				TreeSize:       swag.Int64(3),
+				SignedTreeHead: swag.String(sth.SignedNote.String()),
+			},
+		}
+	}
+
+	got, err := HistoricalProof(verifier, []byte("a"), proof())
+	if err != nil {
+		t.Fatalf("unexpected error verifying historical proof: %v", err)
+	}
+	if got.Size != 3 {
+		t.Errorf("tree head of size %d, want 3", got.Size)
+	}
+
+	if _, err := HistoricalProof(otherVerifier, []byte("a"), proof()); err == nil {
+		t.Error("expected error verifying tree head signed by another log")
+	}
+	if _, err := HistoricalProof(verifier, []byte("b"), proof()); err == nil {
+		t.Error("expected error verifying proof for another entry")
+	}
+	wrongRoot := proof()
+	wrongRoot.ConsistencyProof.RootHash = swag.String(hex.EncodeToString(root2))
+	if _, err := HistoricalProof(verifier, []byte("a"), wrongRoot); err == nil {
+		t.Error("expected error verifying consistency proof for another root")
+	}
+	wrongProof := proof()
+	wrongProof.ConsistencyProof.Hashes = []string{hex.EncodeToString(leaves[1])}
+	if _, err := HistoricalProof(verifier, []byte("a"), wrongProof); err == nil {
+		t.Error("expected error verifying wrong consistency proof")
+	}
+	incomplete := proof()
+	incomplete.ConsistencyProof = nil
+	if _, err := HistoricalProof(verifier, []byte("a"), incomplete); err == nil {
+		t.Error("expected error verifying incomplete proof")
+	}
+}
+
+func TestBundle(t *testing.T) {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	signer, _ := signature.LoadSigner(key, crypto.SHA256)
+	verifier, _ := signature.LoadVerifier(key.Public(), crypto.SHA256)
+	logID, err := LogID(verifier)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyID, _ := hex.DecodeString(logID)
+
+	body := []byte(`{"entry":0}`)
+	_, e := logEntry(t, signer, logID, body, []byte(`{"entry":1}`))
+	rootHash, _ := hex.DecodeString(swag.StringValue(e.Verification.InclusionProof.RootHash))
+	otherHash, _ := hex.DecodeString(e.Verification.InclusionProof.Hashes[0])
+	tlogEntry := func() bundle.TransparencyLogEntry {
+		return bundle.TransparencyLogEntry{
+			LogIndex:          0,
+			LogID:             bundle.LogID{KeyID: keyID},
+			IntegratedTime:    swag.Int64Value(e.IntegratedTime),
+			InclusionPromise:  &bundle.InclusionPromise{SignedEntryTimestamp: e.Verification.SignedEntryTimestamp},
+			InclusionProof:    &bundle.InclusionProof{LogIndex: 0, RootHash: rootHash, TreeSize: 2, Hashes: [][]byte{otherHash}},
+			CanonicalizedBody: body,
+		}
+	}
+	withEntry := func(tle bundle.TransparencyLogEntry) *bundle.Bundle {
+		return &bundle.Bundle{
+			MediaType:            bundle.MediaType,
+			VerificationMaterial: &bundle.VerificationMaterial{TlogEntries: []bundle.TransparencyLogEntry{tle}},
+		}
+	}
+
+	if _, err := Bundle(verifier, withEntry(tlogEntry()), nil); err != nil {
+		t.Fatalf("unexpected error verifying bundle: %v", err)
+	}
+	noProof := tlogEntry()
+	noProof.InclusionProof = nil
+	if _, err := Bundle(verifier, withEntry(noProof), nil); err != nil {
+		t.Errorf("unexpected error verifying bundle without inclusion proof: %v", err)
+	}
+
+	tests := []struct {
+		desc   string
+		modify func(*bundle.TransparencyLogEntry)
+	}{
+		{
+			desc: "signed entry timestamp missing",
+			modify: func(tle *bundle.TransparencyLogEntry) {
+				tle.InclusionPromise = nil
+			},
+		},
+		{
+			// anyone can compute a proof of any body against a root of their own
+			desc: "signed entry timestamp missing, self-consistent proof of another body",
+			modify: func(tle *bundle.TransparencyLogEntry) {
+				other := []byte(`{"entry":2}`)
+				tle.CanonicalizedBody = other
+				tle.InclusionPromise = nil
+				tle.InclusionProof = &bundle.InclusionProof{LogIndex: 0, RootHash: rfc6962.DefaultHasher.HashLeaf(other), TreeSize: 1, Hashes: [][]byte{}}
+			},
+		},
+		{
+			desc: "body changed",
+			modify: func(tle *bundle.TransparencyLogEntry) {
+				tle.CanonicalizedBody = []byte(`{"entry":2}`)
+			},
+		},
+		{
+			desc: "inclusion proof for another root",
+			modify: func(tle *bundle.TransparencyLogEntry) {
+				tle.InclusionProof.RootHash = bytes.Repeat([]byte{0}, 32)
+			},
+		},
+		{
+			desc: "logged by another log",
+			modify: func(tle *bundle.TransparencyLogEntry) {
+				tle.LogID.KeyID = bytes.Repeat([]byte{0}, 32)
+			},
+		},
+	}
+	for _, tt := range tests {
+		tle := tlogEntry()
+		tt.modify(&tle)
+		if _, err := Bundle(verifier, withEntry(tle), nil); err == nil {
+			t.Errorf("%v: expected error", tt.desc)
+		}
+	}
+}
@@ -0,0 +1,211 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/sigstore/rekor/pkg/events"
+	"github.com/sigstore/rekor/pkg/log"
+)
+
+const (
+	// EventEntryCreated is sent after a new entry has been integrated into the log
+	EventEntryCreated = "entry.created"
+
+	// EventHeader carries the type of event being delivered
+	EventHeader = "X-Rekor-Event"
+	// TimestampHeader carries the unix time at which the delivery was signed
+	TimestampHeader = "X-Rekor-Timestamp"
+	// SignatureHeader carries the HMAC-SHA256 of the timestamp and payload, as computed by Sign
+	SignatureHeader = "X-Rekor-Signature"
+
+	signaturePrefix = "sha256="
+)
+
+var metricDeliveries = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "rekor_webhook_deliveries",
+	Help: "The total number of webhook deliveries, by result",
+}, []string{"result"})
+
+// Target is an endpoint to which events are POSTed
+type Target struct {
+	URL string
+	// Secret is used to sign deliveries; they are sent unsigned if it is empty
+	Secret []byte
+}
+
+// Config holds the settings of a Dispatcher
+type Config struct {
+	Targets      []Target
+	Timeout      time.Duration
+	MaxRetries   uint
+	RetryBackoff time.Duration
+	QueueSize    int
+}
+
+// Sign computes the value of the signature header for a delivery made at timestamp
+func Sign(secret []byte, timestamp string, payload []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks the signature header of a delivery; receivers should also reject stale timestamps
+func Verify(secret []byte, timestamp string, payload []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, timestamp, payload)), []byte(signature))
+}
+
+// Dispatcher is an events.Sink which delivers events to the configured targets in the background, so that notifying
+// never delays a response to a client
+type Dispatcher struct {
+	cfg    Config
+	client *http.Client
+	queue  chan events.Entry
+	wg     sync.WaitGroup
+}
+
+// NewDispatcher creates a Dispatcher and starts its delivery worker
+func NewDispatcher(cfg Config) *Dispatcher {
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = 1
+	}
+	d := &Dispatcher{
+		cfg:    cfg,
+		client: &http.Client{Timeout: cfg.Timeout},
+		queue:  make(chan events.Entry, cfg.QueueSize),
+	}
+	d.wg.Add(1)
+	go d.work()
+	return d
+}
+
+// Notify queues an event for delivery. Events are dropped (and false returned) if the queue is full.
+func (d *Dispatcher) Notify(ev events.Entry) bool {
+	select {
+	case d.queue <- ev:
+		return true
+	default:
+		metricDeliveries.WithLabelValues("dropped").Inc()
+		log.Logger.Warnf("webhook queue full, dropping event for entry %s", ev.UUID)
+		return false
+	}
+}
+
+// Publish implements events.Sink; it never blocks on delivery
+func (d *Dispatcher) Publish(_ context.Context, e events.Entry) error {
+	if !d.Notify(e) {
+		return errors.New("webhook queue full")
+	}
+	return nil
+}
+
+// Close stops accepting events and waits for queued events to be delivered, or for ctx to expire
+func (d *Dispatcher) Close(ctx context.Context) error {
+	close(d.queue)
+	drained := make(chan struct{})
+	go func() {
+		d.wg.Wait()
+		close(drained)
+	}()
+	select {
+	case <-drained:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (d *Dispatcher) work() {
+	defer d.wg.Done()
+	for ev := range d.queue {
+		body, err := json.Marshal(ev)
+		if err != nil {
+			log.Logger.Errorf("marshalling webhook event: %v", err)
+			continue
+		}
+		for _, t := range d.cfg.Targets {
+			if err := d.deliver(t, EventEntryCreated, body); err != nil {
+				metricDeliveries.WithLabelValues("failed").Inc()
+				log.Logger.Warnf("delivering event for entry %s to %s: %v", ev.UUID, t.URL, err)
+				continue
+			}
+			metricDeliveries.WithLabelValues("delivered").Inc()
+		}
+	}
+}
+
+// deliver POSTs the payload to the target, retrying with exponential backoff on network errors
+// and server side failures
+func (d *Dispatcher) deliver(t Target, event string, body []byte) error {
+	backoff := d.cfg.RetryBackoff
+	var err error
+	for attempt := uint(0); attempt <= d.cfg.MaxRetries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+		var retry bool
+		if retry, err = d.post(t, event, body); err == nil || !retry {
+			return err
+		}
+	}
+	return err
+}
+
+func (d *Dispatcher) post(t Target, event string, body []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, t.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, event)
+	if len(t.Secret) > 0 {
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		req.Header.Set(TimestampHeader, timestamp)
+		req.Header.Set(SignatureHeader, Sign(t.Secret, timestamp, body))
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode <= 299:
+		return false, nil
+	case resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests:
+		return true, fmt.Errorf("status %d", resp.StatusCode)
+	default:
+		return false, fmt.Errorf("status %d", resp.StatusCode)
+	}
+}
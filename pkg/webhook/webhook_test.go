@@ -0,0 +1,123 @@
+//
+// Copyright 2021 The Sigstore Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sigstore/rekor/pkg/events"
+)
+
+func TestSignVerify(t *testing.T) {
+	secret := []byte("s3cret")
+	payload := []byte(`{"uuid":"abc"}`)
+	sig := Sign(secret, "1600000000", payload)
+	if !Verify(secret, "1600000000", payload, sig) {
+		t.Error("signature did not verify")
+	}
+	if Verify(secret, "1600000001", payload, sig) {
+		t.Error("signature verified with a different timestamp")
+	}
+	if Verify([]byte("other"), "1600000000", payload, sig) {
+		t.Error("signature verified with a different secret")
+	}
+	if Verify(secret, "1600000000", []byte(`{"uuid":"abd"}`), sig) {
+		t.Error("signature verified over a different payload")
+	}
+}
+
+func TestDispatcher(t *testing.T) {
+	secret := []byte("s3cret")
+	var calls int32
+	received := make(chan events.Entry, 1)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// fail the first attempt to exercise retries
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		body, _ := ioutil.ReadAll(r.Body)
+		if r.Header.Get(EventHeader) != EventEntryCreated {
+			t.Errorf("unexpected event header %q", r.Header.Get(EventHeader))
+		}
+		if !Verify(secret, r.Header.Get(TimestampHeader), body, r.Header.Get(SignatureHeader)) {
+			t.Error("delivery signature did not verify")
+		}
+		var ev events.Entry
+		if err := json.Unmarshal(body, &ev); err != nil {
+			t.Errorf("unmarshalling event: %v", err)
+		}
+		received <- ev
+	}))
+	defer ts.Close()
+
+	d := NewDispatcher(Config{
+		Targets:      []Target{{URL: ts.URL, Secret: secret}},
+		Timeout:      time.Second,
+		MaxRetries:   2,
+		RetryBackoff: time.Millisecond,
+		QueueSize:    1,
+	})
+	if !d.Notify(events.Entry{UUID: "abc", LogIndex: 7}) {
+		t.Fatal("event was not queued")
+	}
+	if err := d.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case ev := <-received:
+		if ev.UUID != "abc" || ev.LogIndex != 7 {
+			t.Errorf("unexpected event delivered: %+v", ev)
+		}
+	default:
+		t.Fatal("event was not delivered")
+	}
+	if calls != 2 {
+		t.Errorf("expected 2 attempts, got %d", calls)
+	}
+}
+
+func TestDispatcherDoesNotRetryClientErrors(t *testing.T) {
+	var calls int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer ts.Close()
+
+	d := NewDispatcher(Config{
+		Targets:      []Target{{URL: ts.URL}},
+		Timeout:      time.Second,
+		MaxRetries:   3,
+		RetryBackoff: time.Millisecond,
+		QueueSize:    1,
+	})
+	d.Notify(events.Entry{UUID: "abc"})
+	if err := d.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 1 {
+		t.Errorf("expected 1 attempt, got %d", calls)
+	}
+}
@@ -111,6 +111,34 @@ func TestDuplicates(t *testing.T) {
 	outputContains(t, out, "Created entry at")
 }
 
+func TestUploadDryRun(t *testing.T) {
+	artifactPath := filepath.Join(t.TempDir(), "artifact")
+	sigPath := filepath.Join(t.TempDir(), "signature.asc")
+
+	createdPGPSignedArtifact(t, artifactPath, sigPath)
+
+	pubPath := filepath.Join(t.TempDir(), "pubKey.asc")
+	if err := ioutil.WriteFile(pubPath, []byte(publicKey), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Nothing is added to the log by a dry run, so it can be repeated
+	out := runCli(t, "upload", "--artifact", artifactPath, "--signature", sigPath, "--public-key", pubPath, "--dry-run")
+	outputContains(t, out, "would be added with UUID")
+	out = runCli(t, "upload", "--artifact", artifactPath, "--signature", sigPath, "--public-key", pubPath, "--dry-run")
+	outputContains(t, out, "would be added with UUID")
+	tokens := strings.Fields(out)
+	uuid := tokens[len(tokens)-1]
+
+	// The entry is then created with the UUID reported by the dry run
+	out = runCli(t, "upload", "--artifact", artifactPath, "--signature", sigPath, "--public-key", pubPath)
+	outputContains(t, out, "Created entry at")
+	outputContains(t, out, "UUID: "+uuid)
+
+	out = runCli(t, "upload", "--artifact", artifactPath, "--signature", sigPath, "--public-key", pubPath, "--dry-run")
+	outputContains(t, out, "Entry already exists")
+}
+
 func TestUploadVerifyRekord(t *testing.T) {
 
 	// Create a random artifact and sign it.